@@ -24,16 +24,24 @@ const PLATFORM_MAPPINGS = {
   'win': 'win32-x64'
 };
 
+// Slim builds: comma-separated languages to embed, or a languages.yaml in the repository root
+const LANGUAGES_ENV = 'URL_DETECTOR_LANGUAGES';
+const LANGUAGES_FILE = path.join(__dirname, '../languages.yaml');
+
+// Must match EMBEDDED_GRAMMARS_FILE in src/languageManager.ts
+const EMBEDDED_GRAMMARS_FILE = 'embedded-grammars.json';
+
 // Read package.json to get dependencies
 const packageJson = JSON.parse(fs.readFileSync(path.join(__dirname, '../package.json'), 'utf8'));
 
-// Extract tree-sitter dependencies
-function getTreeSitterDependencies() {
+// Extract tree-sitter dependencies, limited to the given grammar modules for slim builds
+function getTreeSitterDependencies(grammarModules) {
   const deps = { ...packageJson.dependencies, ...packageJson.devDependencies };
   const treeSitterDeps = [];
   
   for (const [name, version] of Object.entries(deps)) {
     if (name.startsWith('tree-sitter') || name.includes('tree-sitter')) {
+      if (grammarModules && name !== 'tree-sitter' && !grammarModules.includes(name)) continue;
       treeSitterDeps.push(name);
     }
   }
@@ -41,9 +49,52 @@ function getTreeSitterDependencies() {
   return treeSitterDeps.sort();
 }
 
+// Parse the language list from languages.yaml, either a block list or a flow list:
+//   languages:            languages: [go, python]
+//     - go
+//     - python
+function parseLanguagesYaml(text) {
+  const languages = [];
+  for (const rawLine of text.split(/\r?\n/)) {
+    const line = rawLine.replace(/#.*$/, '').trim();
+    const flow = line.match(/^languages:\s*\[(.*)\]$/);
+    if (flow) {
+      languages.push(...flow[1].split(','));
+    } else if (line.startsWith('-')) {
+      languages.push(line.substring(1));
+    }
+  }
+  return languages.map(name => name.trim().replace(/^['"]|['"]$/g, '').toLowerCase()).filter(Boolean);
+}
+
+// Languages selected for a slim build, or null for the full build
+function resolveLanguageSelection(env = process.env, languagesFile = LANGUAGES_FILE) {
+  if (env[LANGUAGES_ENV]) {
+    return env[LANGUAGES_ENV].split(',').map(name => name.trim().toLowerCase()).filter(Boolean);
+  }
+  if (fs.existsSync(languagesFile)) {
+    return parseLanguagesYaml(fs.readFileSync(languagesFile, 'utf8'));
+  }
+  return null;
+}
+
+// Map language names to their grammar modules
+function selectGrammarModules(languages, languageConfigs) {
+  const modules = new Set();
+  for (const name of languages) {
+    const config = languageConfigs.find(candidate => candidate.name === name);
+    if (!config) {
+      const known = languageConfigs.map(candidate => candidate.name).join(', ');
+      throw new Error(`Unknown language "${name}" in slim build selection. Known languages: ${known}`);
+    }
+    modules.add(config.module);
+  }
+  return Array.from(modules).sort();
+}
+
 // Generate platform-specific assets for tree-sitter packages
-function generateTreeSitterAssets(platform) {
-  const deps = getTreeSitterDependencies();
+function generateTreeSitterAssets(platform, grammarModules) {
+  const deps = getTreeSitterDependencies(grammarModules);
   const platformDir = PLATFORM_MAPPINGS[platform];
   const assets = [];
   
@@ -131,8 +182,8 @@ function getBasePkgConfig(platform) {
   };
 }
 
-// Generate full pkg configuration for a platform
-function generatePkgConfig(platform) {
+// Generate full pkg configuration for a platform; grammarModules limits the embedded grammars
+function generatePkgConfig(platform, grammarModules) {
   const config = getBasePkgConfig(platform);
   
   // Add common non-tree-sitter assets
@@ -141,13 +192,27 @@ function generatePkgConfig(platform) {
     "node_modules/cli-table3/**/*"
   ];
   
-  const treeSitterAssets = generateTreeSitterAssets(platform);
+  const treeSitterAssets = generateTreeSitterAssets(platform, grammarModules);
   
   config.assets = [...commonAssets, ...treeSitterAssets];
+  if (grammarModules) {
+    // Tells the binary which grammars are missing on purpose
+    config.assets.push(`dist/${EMBEDDED_GRAMMARS_FILE}`);
+  }
   
   return config;
 }
 
+// Write the embedded grammar manifest for slim builds and remove a stale one for full builds
+function writeEmbeddedGrammars(grammarModules, distDir = path.join(__dirname, '../dist')) {
+  const manifest = path.join(distDir, EMBEDDED_GRAMMARS_FILE);
+  if (grammarModules) {
+    fs.writeFileSync(manifest, JSON.stringify(grammarModules, null, 2) + '\n');
+  } else if (fs.existsSync(manifest)) {
+    fs.unlinkSync(manifest);
+  }
+}
+
 // Main function
 function main() {
   const args = process.argv.slice(2);
@@ -158,7 +223,17 @@ function main() {
     process.exit(1);
   }
   
-  const config = generatePkgConfig(command);
+  let grammarModules = null;
+  const languages = resolveLanguageSelection();
+  if (languages) {
+    // The language table lives in the compiled code, so run `npm run build` first
+    const { LanguageManager } = require('../dist/languageManager');
+    grammarModules = selectGrammarModules(languages, LanguageManager.getDefaultLanguages());
+    console.error(`Slim build embedding: ${languages.join(', ')}`);
+  }
+  writeEmbeddedGrammars(grammarModules);
+  
+  const config = generatePkgConfig(command, grammarModules);
   console.log(JSON.stringify(config, null, 2));
 }
 
@@ -168,5 +243,9 @@ if (require.main === module) {
 
 module.exports = {
   generatePkgConfig,
-  getTreeSitterDependencies
+  getTreeSitterDependencies,
+  parseLanguagesYaml,
+  resolveLanguageSelection,
+  selectGrammarModules,
+  writeEmbeddedGrammars
 };
@@ -0,0 +1,37 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import * as path from 'path';
+
+/**
+ * Writes a file through a temporary file renamed into place, creating its directory first, so an
+ * interrupted run never leaves a truncated file and readers never see a partial one.
+ *
+ * @param filePath File to write
+ * @param data Content to write; strings are written as UTF-8
+ */
+export async function writeAtomically(filePath: string, data: string | Buffer): Promise<void> {
+    await fs.promises.mkdir(path.dirname(path.resolve(filePath)), { recursive: true });
+    // Unique per write, so concurrent writes of the same file, even from one process, never share it
+    const tempPath = `${filePath}.${crypto.randomUUID()}.tmp`;
+    try {
+        await fs.promises.writeFile(tempPath, data);
+        await fs.promises.rename(tempPath, filePath);
+    } catch (error) {
+        await fs.promises.rm(tempPath, { force: true });
+        throw error;
+    }
+}
@@ -0,0 +1,131 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+/** Default minimum length of the printable strings extracted from binaries, like `strings -n 8` */
+export const DEFAULT_MIN_STRING_LENGTH = 8;
+
+/** Attribute holding the byte offset of a finding in a binary, in hexadecimal */
+export const BINARY_OFFSET_ATTRIBUTE = 'offset';
+
+/** Executable formats recognized by their magic numbers */
+export type ExecutableFormat = 'elf' | 'pe' | 'mach-o';
+
+/** Marker of the build information section the Go linker writes into every binary */
+const GO_BUILD_INFO_MAGIC = Buffer.from('\xff Go buildinf:', 'latin1');
+
+/** Mach-O magic numbers as read big-endian: 32- and 64-bit in both byte orders */
+const MACH_O_MAGICS = [0xfeedface, 0xfeedfacf, 0xcefaedfe, 0xcffaedfe];
+
+/** Magic number of universal (fat) Mach-O binaries, shared with Java class files */
+const MACH_O_FAT_MAGIC = 0xcafebabe;
+
+/**
+ * A printable string found in a binary.
+ */
+export interface BinaryString {
+    text: string;
+    /** Byte offset of the string in the file */
+    offset: number;
+    /** Bytes per character: 1 for ASCII, 2 for UTF-16LE */
+    width: 1 | 2;
+}
+
+/**
+ * Recognizes ELF, PE, and Mach-O executables by their magic numbers.
+ *
+ * @param buffer Content of the file, or at least its first 4 KB
+ * @returns The format, or null for other files
+ */
+export function detectExecutableFormat(buffer: Buffer): ExecutableFormat | null {
+    if (buffer.length < 8) return null;
+    if (buffer.readUInt32BE(0) === 0x7f454c46) return 'elf';
+
+    if (buffer.readUInt16BE(0) === 0x4d5a && buffer.length >= 0x40) {
+        // The DOS header points to the PE signature
+        const peOffset = buffer.readUInt32LE(0x3c);
+        if (peOffset + 4 <= buffer.length && buffer.readUInt32BE(peOffset) === 0x50450000) return 'pe';
+        return null;
+    }
+
+    const magic = buffer.readUInt32BE(0);
+    if (MACH_O_MAGICS.includes(magic)) return 'mach-o';
+    // Java class files start with the same magic followed by a version of 45 or more
+    if (magic === MACH_O_FAT_MAGIC && buffer.readUInt32BE(4) < 45) return 'mach-o';
+    return null;
+}
+
+/**
+ * Whether a binary was built by the Go toolchain.
+ *
+ * @param buffer Content of the file
+ * @returns True when the Go build information marker is present
+ */
+export function isGoBinary(buffer: Buffer): boolean {
+    return buffer.includes(GO_BUILD_INFO_MAGIC);
+}
+
+/**
+ * Extracts printable strings from binary content, like the `strings` tool: runs of printable ASCII
+ * characters, and of UTF-16LE characters in the ASCII range as used by Windows resources.
+ *
+ * @param buffer Content of the file
+ * @param minLength Shortest string to keep (default: DEFAULT_MIN_STRING_LENGTH)
+ * @returns The strings in order of offset
+ */
+export function extractStrings(buffer: Buffer, minLength: number = DEFAULT_MIN_STRING_LENGTH): BinaryString[] {
+    const strings = [...extractRuns(buffer, minLength, 1), ...extractRuns(buffer, minLength, 2)];
+    return strings.sort((a, b) => a.offset - b.offset);
+}
+
+/**
+ * Byte offset in the file of a position in an extracted string.
+ *
+ * @param string The string
+ * @param column 1-based column in the string
+ * @returns The byte offset
+ */
+export function stringByteOffset(string: BinaryString, column: number): number {
+    return string.offset + (column - 1) * string.width;
+}
+
+function extractRuns(buffer: Buffer, minLength: number, width: 1 | 2): BinaryString[] {
+    const runs: BinaryString[] = [];
+    const encoding = width === 1 ? 'latin1' : 'utf16le';
+    for (let phase = 0; phase < width; phase++) {
+        let start = -1;
+        let offset = phase;
+        const endRun = () => {
+            if (start !== -1 && (offset - start) / width >= minLength) {
+                runs.push({ text: buffer.toString(encoding, start, offset), offset: start, width });
+            }
+            start = -1;
+        };
+
+        for (; offset + width <= buffer.length; offset += width) {
+            if (isPrintable(buffer[offset]) && (width === 1 || buffer[offset + 1] === 0)) {
+                // A printable byte before a UTF-16 character means it is the end of an ASCII string
+                const afterAscii = width === 2 && offset > 0 && isPrintable(buffer[offset - 1]);
+                if (start === -1 && !afterAscii) start = offset;
+            } else {
+                endRun();
+            }
+        }
+        endRun();
+    }
+    return runs;
+}
+
+function isPrintable(byte: number): boolean {
+    return (byte >= 0x20 && byte < 0x7f) || byte === 0x09;
+}
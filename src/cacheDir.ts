@@ -0,0 +1,329 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { writeAtomically } from './atomicWrite';
+
+/** Environment variable overriding the cache directory */
+export const CACHE_DIR_ENV = 'URL_DETECTOR_CACHE_DIR';
+
+/** Lock file taken while the cache directory is written */
+export const CACHE_LOCK_FILE = '.lock';
+
+/** Cache directory tag (https://bford.info/cachedir/) marking a directory url-detector may clear */
+export const CACHE_TAG_FILE = 'CACHEDIR.TAG';
+
+const CACHE_TAG =
+    'Signature: 8a477f597d28d172789f06886806bc55\n' +
+    '# This file is a cache directory tag created by url-detector.\n' +
+    '# For information about cache directory tags, see https://bford.info/cachedir/\n';
+
+/** Default time to wait for another process to release the cache lock */
+export const DEFAULT_LOCK_TIMEOUT_MS = 30000;
+
+/** Age after which a lock whose owner cannot be checked is considered abandoned */
+export const STALE_LOCK_MS = 10 * 60 * 1000;
+
+const LOCK_RETRY_MS = 50;
+
+/**
+ * An entry of the cache directory.
+ */
+export interface CacheEntry {
+    /** Name relative to the cache directory */
+    name: string;
+    /** Size in bytes, including everything below a directory */
+    size: number;
+    /** Last modification time (ISO 8601) */
+    modified: string;
+}
+
+/**
+ * What the cache directory holds.
+ */
+export interface CacheInfo {
+    root: string;
+    /** Whether the directory exists yet */
+    exists: boolean;
+    entries: CacheEntry[];
+    /** Size of all entries in bytes */
+    totalSize: number;
+}
+
+/**
+ * Resolves the cache directory: the override if given, else the URL_DETECTOR_CACHE_DIR environment
+ * variable, else `$XDG_CACHE_HOME/url-detector`, else the platform's cache location
+ * (`~/.cache/url-detector` on Linux, `~/Library/Caches/url-detector` on macOS,
+ * `%LOCALAPPDATA%\url-detector\Cache` on Windows).
+ *
+ * @param override Directory given on the command line (default: none)
+ * @param env Environment variables (default: process.env)
+ * @param platform Platform (default: process.platform)
+ * @returns Absolute path of the cache directory
+ */
+export function resolveCacheDir(
+    override?: string,
+    env: NodeJS.ProcessEnv = process.env,
+    platform: NodeJS.Platform = process.platform,
+): string {
+    if (override) return path.resolve(override);
+    if (env[CACHE_DIR_ENV]) return path.resolve(env[CACHE_DIR_ENV]!);
+    if (env.XDG_CACHE_HOME && path.isAbsolute(env.XDG_CACHE_HOME)) {
+        return path.join(env.XDG_CACHE_HOME, 'url-detector');
+    }
+
+    const home = env.HOME || env.USERPROFILE || os.homedir();
+    if (platform === 'win32') {
+        return path.join(env.LOCALAPPDATA || path.join(home, 'AppData', 'Local'), 'url-detector', 'Cache');
+    }
+    if (platform === 'darwin') {
+        return path.join(home, 'Library', 'Caches', 'url-detector');
+    }
+    return path.join(home, '.cache', 'url-detector');
+}
+
+/**
+ * The cache directory shared by every url-detector process of a user, e.g. parallel CI jobs on one
+ * runner. Writers take an exclusive lock file, so concurrent writes and clears are serialized, and
+ * files are replaced by renaming, so readers never need the lock and never see a partial file.
+ */
+export class CacheDirectory {
+    public readonly root: string;
+
+    /**
+     * @param root Cache directory (default: resolveCacheDir())
+     */
+    constructor(root: string = resolveCacheDir()) {
+        this.root = root;
+    }
+
+    /**
+     * Path of an entry of the cache directory.
+     *
+     * @param name Name relative to the cache directory
+     * @returns Absolute path of the entry
+     */
+    public path(name: string): string {
+        return path.join(this.root, name);
+    }
+
+    /**
+     * Runs a function while holding the cache lock. A lock left behind by a process on this host that is
+     * no longer running is taken over, as is one older than STALE_LOCK_MS whose owner cannot be checked,
+     * such as a process on another host or in another container. Holders touch the lock while they run,
+     * so a long write is never mistaken for an abandoned one.
+     *
+     * @param fn Function to run
+     * @param timeoutMs How long to wait for the lock (default: DEFAULT_LOCK_TIMEOUT_MS)
+     * @returns The function's result
+     * @throws {Error} When the lock is not released in time
+     */
+    public async withLock<T>(fn: () => Promise<T>, timeoutMs: number = DEFAULT_LOCK_TIMEOUT_MS): Promise<T> {
+        // Only a directory url-detector created, or found empty, is tagged, so clear() never empties another one
+        const created = await fs.promises.mkdir(this.root, { recursive: true });
+        if (created !== undefined || (await fs.promises.readdir(this.root)).length === 0) {
+            await fs.promises.writeFile(this.path(CACHE_TAG_FILE), CACHE_TAG, 'utf8');
+        }
+        const lockFile = this.path(CACHE_LOCK_FILE);
+        const owner = await acquireLock(lockFile, Date.now() + timeoutMs);
+
+        const heartbeat = setInterval(() => {
+            const now = new Date();
+            fs.promises.utimes(lockFile, now, now).catch(() => undefined);
+        }, STALE_LOCK_MS / 4);
+        heartbeat.unref();
+        try {
+            return await fn();
+        } finally {
+            clearInterval(heartbeat);
+            await releaseLock(lockFile, owner);
+        }
+    }
+
+    /**
+     * Writes an entry under the cache lock, through a temporary file renamed into place.
+     *
+     * @param name Name relative to the cache directory
+     * @param data Content to write
+     */
+    public async writeFile(name: string, data: string | Buffer): Promise<void> {
+        await this.withLock(() => writeAtomically(this.path(name), data));
+    }
+
+    /**
+     * Lists the entries of the cache directory with their sizes.
+     *
+     * @returns The entries, sorted by name, and their total size
+     */
+    public async info(): Promise<CacheInfo> {
+        let names: string[];
+        try {
+            names = await fs.promises.readdir(this.root);
+        } catch (error: any) {
+            if (error.code === 'ENOENT') return { root: this.root, exists: false, entries: [], totalSize: 0 };
+            throw error;
+        }
+
+        const entries: CacheEntry[] = [];
+        for (const name of names.filter(isCacheEntry).sort()) {
+            const stats = await fs.promises.stat(this.path(name));
+            entries.push({ name, size: await sizeOf(this.path(name)), modified: stats.mtime.toISOString() });
+        }
+        const totalSize = entries.reduce((sum, entry) => sum + entry.size, 0);
+        return { root: this.root, exists: true, entries, totalSize };
+    }
+
+    /**
+     * Removes every entry of the cache directory under the cache lock.
+     *
+     * @returns The number of entries removed
+     * @throws {Error} When the directory has no cache directory tag, as url-detector did not create it
+     */
+    public async clear(): Promise<number> {
+        return this.withLock(async () => {
+            if (!fs.existsSync(this.path(CACHE_TAG_FILE))) {
+                throw new Error(
+                    `Not clearing ${this.root}: it has no ${CACHE_TAG_FILE}, so url-detector did not create it`,
+                );
+            }
+            const names = (await fs.promises.readdir(this.root)).filter(isCacheEntry);
+            for (const name of names) {
+                await fs.promises.rm(this.path(name), { recursive: true, force: true });
+            }
+            return names.length;
+        });
+    }
+}
+
+/**
+ * Whether a name of the cache directory is an entry rather than its tag or a lock file.
+ */
+function isCacheEntry(name: string): boolean {
+    return name !== CACHE_TAG_FILE && !name.startsWith(CACHE_LOCK_FILE);
+}
+
+/**
+ * Takes a lock file, taking over a stale one.
+ *
+ * @param lockFile The lock file
+ * @param deadline When to give up waiting for a live lock (epoch milliseconds)
+ * @returns The content of the lock, which identifies this holder
+ * @throws {Error} When the lock is not released before the deadline
+ */
+async function acquireLock(lockFile: string, deadline: number): Promise<string> {
+    // The token tells this lock apart from any other, even one of a process with the same pid
+    const owner = `${process.pid} ${os.hostname()} ${crypto.randomUUID()}\n`;
+    // The lock is written in full before it is linked into place, so it is never seen empty or partial
+    const pending = `${lockFile}.${crypto.randomUUID()}`;
+    await fs.promises.writeFile(pending, owner, 'utf8');
+
+    try {
+        for (;;) {
+            try {
+                await fs.promises.link(pending, lockFile);
+                return owner;
+            } catch (error: any) {
+                if (error.code !== 'EEXIST') throw error;
+            }
+            const lock = await readLock(lockFile);
+            if (!lock) continue;
+            if (lock.stale) {
+                await breakLock(lockFile, lock.content, deadline);
+                continue;
+            }
+            if (Date.now() >= deadline) {
+                throw new Error(`Timed out waiting for the cache lock ${lockFile}; another url-detector holds it`);
+            }
+            await new Promise(resolve => setTimeout(resolve, LOCK_RETRY_MS));
+        }
+    } finally {
+        await fs.promises.rm(pending, { force: true });
+    }
+}
+
+/**
+ * Releases a lock file if it is still held with the given content.
+ */
+async function releaseLock(lockFile: string, owner: string): Promise<void> {
+    const content = await fs.promises.readFile(lockFile, 'utf8').catch(() => undefined);
+    if (content === owner) await fs.promises.rm(lockFile, { force: true });
+}
+
+/**
+ * Reads the lock file and decides whether it was abandoned.
+ *
+ * @returns The lock's content and whether it is stale, or null when it was released in the meantime
+ */
+async function readLock(lockFile: string): Promise<{ content: string; stale: boolean } | null> {
+    let content: string;
+    let stats: fs.Stats;
+    try {
+        content = await fs.promises.readFile(lockFile, 'utf8');
+        stats = await fs.promises.stat(lockFile);
+    } catch (error: any) {
+        if (error.code === 'ENOENT') return null;
+        throw error;
+    }
+
+    const [pid, host] = content.trim().split(' ');
+    const ownerPid = parseInt(pid, 10);
+    if (ownerPid > 0 && host === os.hostname()) {
+        try {
+            process.kill(ownerPid, 0);
+            return { content, stale: false };
+        } catch (error: any) {
+            // EPERM: the process exists but belongs to another user
+            return { content, stale: error.code === 'ESRCH' };
+        }
+    }
+    // The owner runs on another host or in another container, where its pid means nothing, or the file
+    // is damaged
+    return { content, stale: Date.now() - stats.mtimeMs > STALE_LOCK_MS };
+}
+
+/**
+ * Removes a stale lock. Removing it is itself guarded by a lock file named after the stale lock's
+ * content, taken with the same exclusive link, so of several processes that found the same stale lock
+ * only one removes it at a time, and only while the lock still holds that content; a lock taken in the
+ * meantime is left alone. A remover that died is taken over like any other stale lock.
+ *
+ * @param lockFile The lock file
+ * @param stale Content of the lock found to be stale
+ * @param deadline When to give up waiting for another remover (epoch milliseconds)
+ */
+async function breakLock(lockFile: string, stale: string, deadline: number): Promise<void> {
+    const digest = crypto.createHash('sha256').update(stale).digest('hex').slice(0, 16);
+    const breaker = `${lockFile}.break-${digest}`;
+    const owner = await acquireLock(breaker, deadline);
+    try {
+        const content = await fs.promises.readFile(lockFile, 'utf8').catch(() => undefined);
+        if (content === stale) await fs.promises.rm(lockFile, { force: true });
+    } finally {
+        await releaseLock(breaker, owner);
+    }
+}
+
+async function sizeOf(filePath: string): Promise<number> {
+    const stats = await fs.promises.stat(filePath);
+    if (!stats.isDirectory()) return stats.size;
+
+    let size = 0;
+    for (const name of await fs.promises.readdir(filePath)) {
+        size += await sizeOf(path.join(filePath, name));
+    }
+    return size;
+}
@@ -0,0 +1,128 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { toAsciiHost } from './idn';
+import { FileContext, Rule, Violation } from './ruleEngine';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Id of the rule flagging URLs on canary token hosts */
+export const CANARY_TOKEN_RULE = 'canary-token';
+
+/** Attribute holding the policy of a canary token URL, 'tripwire' or 'leak' */
+export const CANARY_ATTRIBUTE = 'canary';
+
+/**
+ * What a canary token URL in the code means:
+ * - tripwire: it was planted on purpose to detect intruders, and must be kept as it is
+ * - leak: it should never be in the code, such as a honeytoken copied from a decoy credential
+ */
+export type CanaryPolicy = 'tripwire' | 'leak';
+
+/** All canary policies */
+export const CANARY_POLICIES: CanaryPolicy[] = ['tripwire', 'leak'];
+
+/** Hosts of the public Canarytokens service, always known as canary token domains */
+export const DEFAULT_CANARY_DOMAINS = ['canarytokens.com', 'canarytokens.org'];
+
+/**
+ * A canary token domain and what a URL on it means. The domain covers its subdomains.
+ */
+export interface CanaryDomain {
+    domain: string;
+    policy: CanaryPolicy;
+}
+
+const AUTHORITY_HOST = /^(?:[a-z][a-z0-9+.-]*:)?\/\/(?:[^/?#@]*@)?([^/?#:]+)/i;
+
+/**
+ * Parses canary domain entries, each a domain or 'domain=policy', after the built-in Canarytokens
+ * domains. Entries without a policy get the default policy, and later entries replace the policy of
+ * an earlier one for the same domain, so 'canarytokens.com=leak' changes a built-in domain.
+ *
+ * @param entries Domains, from the config file or command line
+ * @param policy Policy of the domains that do not name one (default: 'tripwire')
+ * @returns The canary domains
+ * @throws {Error} When an entry has no domain or an unknown policy
+ */
+export function parseCanaryDomains(entries: string[], policy: CanaryPolicy = 'tripwire'): CanaryDomain[] {
+    if (!CANARY_POLICIES.includes(policy)) {
+        throw new Error(`Invalid canary policy "${policy}"; expected ${CANARY_POLICIES.join(' or ')}`);
+    }
+
+    const domains = new Map<string, CanaryPolicy>();
+    for (const domain of DEFAULT_CANARY_DOMAINS) domains.set(domain, policy);
+    for (const entry of entries) {
+        const separator = entry.indexOf('=');
+        const domain = normalizeDomain(separator >= 0 ? entry.substring(0, separator) : entry);
+        const entryPolicy = separator >= 0 ? (entry.substring(separator + 1).trim() as CanaryPolicy) : policy;
+        if (!domain || !CANARY_POLICIES.includes(entryPolicy)) {
+            throw new Error(`Invalid canary domain "${entry}"; expected a domain or domain=tripwire|leak`);
+        }
+        domains.set(domain, entryPolicy);
+    }
+    return Array.from(domains, ([domain, domainPolicy]) => ({ domain, policy: domainPolicy }));
+}
+
+/**
+ * Finds the canary domain a URL's host is on. The most specific domain wins, so an internal host can
+ * have another policy than the rest of its domain.
+ *
+ * @param url An absolute or protocol-relative URL
+ * @param domains Canary domains
+ * @returns The matching domain, or undefined when the host is not a canary token host
+ */
+export function findCanaryDomain(url: string, domains: CanaryDomain[]): CanaryDomain | undefined {
+    const authority = AUTHORITY_HOST.exec(url);
+    if (!authority) return undefined;
+
+    const host = normalizeDomain(authority[1]);
+    return domains
+        .filter(({ domain }) => host === domain || host.endsWith(`.${domain}`))
+        .sort((a, b) => b.domain.length - a.domain.length)[0];
+}
+
+/**
+ * Creates the rule flagging URLs on canary token hosts. A tripwire raises an 'info' violation reminding
+ * reviewers to keep it, and quickfix patches leave it untouched; a leak raises an 'error'. The policy
+ * is recorded in the finding's 'canary' attribute.
+ *
+ * @param domains Canary domains, from parseCanaryDomains()
+ * @returns The canary token rule
+ */
+export function createCanaryTokenRule(domains: CanaryDomain[]): Rule {
+    return {
+        id: CANARY_TOKEN_RULE,
+        description: 'Canary token URLs are tripwires to keep, or leaks to remove',
+        evaluate: (finding: URLMatch, _file: FileContext): Violation[] => {
+            if (finding.category) return [];
+
+            const match = findCanaryDomain(finding.url, domains);
+            if (!match) return [];
+
+            setFindingAttribute(finding, CANARY_ATTRIBUTE, match.policy);
+            if (match.policy === 'tripwire') {
+                const message = `${finding.url} is a canary token tripwire on ${match.domain}; keep it as it is`;
+                return [{ rule: CANARY_TOKEN_RULE, severity: 'info', message }];
+            }
+            const message =
+                `${finding.url} is a canary token on ${match.domain} that should not be in the code; ` +
+                'remove it and find out how it got here';
+            return [{ rule: CANARY_TOKEN_RULE, severity: 'error', message }];
+        },
+    };
+}
+
+function normalizeDomain(domain: string): string {
+    return toAsciiHost(domain.trim()).replace(/\.$/, '');
+}
@@ -0,0 +1,130 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { DOC_LINK_CATEGORY } from './docLinks';
+import { INFORMATIONAL_CATEGORY } from './falsePositives';
+import { GO_IMPORT_CATEGORY } from './goImports';
+import { hostForms, toAsciiHost } from './idn';
+import { LICENSE_CATEGORY } from './licenseHeaders';
+import { REALTIME_RPC_CATEGORY } from './realtimeEndpoints';
+import { RELATIVE_URL_CATEGORY } from './relativeUrls';
+import { WINDOWS_PATH_CATEGORY } from './windowsPaths';
+
+/**
+ * Categories assigned by the detector to findings that are not plain absolute URLs. User-defined
+ * rules never override them and cannot reuse their names.
+ */
+export const BUILT_IN_CATEGORIES = [
+    DOC_LINK_CATEGORY,
+    GO_IMPORT_CATEGORY,
+    INFORMATIONAL_CATEGORY,
+    LICENSE_CATEGORY,
+    RELATIVE_URL_CATEGORY,
+    WINDOWS_PATH_CATEGORY,
+];
+
+/**
+ * Maps URL hosts to an organization-specific category. Exactly one of host and hostRegex is given.
+ *
+ * @example
+ * ```typescript
+ * { host: '*.internal.example.com', category: 'internal-api' }
+ * { hostRegex: '^s3[.-]([a-z0-9-]+\\.)?amazonaws\\.com$', category: 'object-storage' }
+ * ```
+ */
+export interface CategoryRule {
+    /** Host to match exactly, or '*.' followed by a domain to match any of its subdomains */
+    host?: string;
+    /** Regular expression tested against the host (case-insensitive) */
+    hostRegex?: string;
+    /** Category given to matching URLs */
+    category: string;
+}
+
+/**
+ * Compiled category rules. The first matching rule wins.
+ */
+export class CategoryRules {
+    private matchers: Array<{ matches: (host: string) => boolean; category: string }>;
+
+    /**
+     * Compiles category rules.
+     *
+     * @param rules Rules in priority order
+     * @throws {Error} When a rule has neither or both of host and hostRegex, an invalid regular
+     * expression, or a built-in category (including 'realtime-rpc')
+     */
+    constructor(rules: CategoryRule[]) {
+        this.matchers = rules.map((rule, index) => {
+            const label = `Category rule ${index + 1} (${rule.category})`;
+            if (BUILT_IN_CATEGORIES.includes(rule.category) || rule.category === REALTIME_RPC_CATEGORY) {
+                throw new Error(`${label} uses the built-in category ${rule.category}`);
+            }
+            if (!rule.host === !rule.hostRegex) {
+                throw new Error(`${label} must have exactly one of host and hostRegex`);
+            }
+            const matches = rule.host ? hostMatcher(rule.host) : regexMatcher(rule.hostRegex!, label);
+            return { matches, category: rule.category };
+        });
+    }
+
+    /**
+     * Returns the category of the first rule matching a host.
+     *
+     * @param host Host name of a URL
+     * @returns The category, or undefined when no rule matches
+     */
+    public categoryOf(host: string): string | undefined {
+        if (!host) return undefined;
+        const normalized = host.toLowerCase().replace(/\.$/, '');
+        const matcher = this.matchers.find(candidate => candidate.matches(normalized));
+        return matcher && matcher.category;
+    }
+}
+
+/**
+ * Compiles a host pattern: an exact host, or '*.' followed by a domain to match any of its subdomains.
+ * Internationalized hosts are compared in punycode, so a pattern written in Unicode matches the
+ * punycode form of the host and the other way around.
+ *
+ * @param pattern The host pattern
+ * @returns Tests a lowercase host without a trailing dot
+ */
+export function hostMatcher(pattern: string): (host: string) => boolean {
+    const normalized = toAsciiHost(pattern);
+    if (normalized.startsWith('*.')) {
+        const suffix = normalized.substring(1);
+        return host => toAsciiHost(host).endsWith(suffix);
+    }
+    return host => toAsciiHost(host) === normalized;
+}
+
+/**
+ * Compiles a regular expression tested against hosts (case-insensitive). Internationalized hosts match
+ * when either their punycode or their Unicode form does.
+ *
+ * @param pattern The regular expression
+ * @param label Describes the rule the pattern belongs to in the error message
+ * @returns Tests a host
+ * @throws {Error} When the pattern is not a valid regular expression
+ */
+export function regexMatcher(pattern: string, label: string): (host: string) => boolean {
+    let regex: RegExp;
+    try {
+        regex = new RegExp(pattern, 'i');
+    } catch (error: any) {
+        throw new Error(`${label} has an invalid hostRegex: ${error.message}`);
+    }
+    return host => hostForms(host).some(form => regex.test(form));
+}
@@ -14,12 +14,76 @@
  * and limitations under the License.
  */
 
-import { Command } from 'commander';
+import { Command, InvalidArgumentError, OptionValues } from 'commander';
 import * as fs from 'fs';
 import { URLDetector } from './urlDetector';
-import { OutputFormat } from './options';
-import { ConsoleLogger, NullLogger, ResultsOnlyLogger } from './logger';
-import { OutputFormatter } from './outputFormatter';
+import { LanguageManager, formatGrammarIssue } from './languageManager';
+import { DetectorOptions, DetectorOptionsConfig, OutputEncoding, OutputFormat } from './options';
+import { ConsoleLogger, Logger, NullLogger, ResultsOnlyLogger } from './logger';
+import { OutputFormatter, escapeNonAscii } from './outputFormatter';
+import { ReportSections, createReport, readReport, serializeNdjsonFinding } from './report';
+import { EmailConfig, createReportEmail, sendEmail } from './emailNotifier';
+import { JiraConfig, syncJiraIssues } from './jira';
+import { WebhookConfig, formatLifecycleCounts, postLifecycleEvents, recordLifecycle } from './lifecycleEvents';
+import { analyzeEnvironments } from './environments';
+import { groupFindings } from './findingGroups';
+import { DEFAULT_WORST_OFFENDERS, buildTeamRollup } from './teamRollup';
+import { DIRECT_EGRESS, buildReachabilityMatrix } from './reachability';
+import { DEFAULT_DUPLICATE_MIN_FILES, findDuplicateEndpoints } from './duplicateEndpoints';
+import { buildPortInventory } from './portInventory';
+import { formatSkipSummary } from './skipDiagnostics';
+import { PROFILE_NAMES, Profile, getProfile } from './profiles';
+import {
+    PROBLEM_MATCHER_OWNER,
+    formatProblemLines,
+    formatStepSummary,
+    readActionInputs,
+    shouldFail,
+    writeProblemMatcher,
+    writeStepOutputs,
+    writeStepSummary,
+} from './githubAction';
+import { OWNER_ATTRIBUTE } from './codeOwners';
+import { splitQuarantined, summarizeQuarantine, writeQuarantineReport } from './quarantine';
+import { checkIncrease, formatIncreaseAlert, loadBaselineCount, parseIncreaseThreshold } from './increaseAlert';
+import { DEFAULT_TREND_STORE, TrendFormat, TrendStore, createTrendSnapshot, formatTrendSeries } from './trendStore';
+import { runGit } from './gitMetadata';
+import { INTERRUPT_EXIT_CODES, handleInterrupts } from './interrupt';
+import { DEFAULT_STREAM_MAX_LINE_LENGTH } from './streamScan';
+import { GoImportPolicy } from './goImports';
+import { CategoryRule } from './categoryRules';
+import { SchemePolicyEntry } from './schemePolicy';
+import { SeverityEscalation } from './severityEscalation';
+import { LanguageOptions } from './languageOptions';
+import { RulePluginConfig } from './pluginSandbox';
+import { CanaryPolicy } from './canaryTokens';
+import { FALSE_POSITIVE_HEURISTICS, FalsePositiveHeuristic } from './falsePositives';
+import { SCHEMA_NAMES, SchemaName, getSchema } from './schema';
+import {
+    DATA_BUNDLE_CACHE_ENTRY,
+    DEFAULT_TLD_SOURCE,
+    importDataBundle,
+    pullDataBundle,
+    signDataBundle,
+} from './dataBundle';
+import { CacheDirectory, resolveCacheDir } from './cacheDir';
+import { DEFAULT_TRIAGE_STORE, TRIAGE_STATES, TriageStore, parseTriageState } from './triage';
+import { DEFAULT_CACHE_SIZE, DEFAULT_PROFILE, ScanServer, loadServerProfiles } from './server';
+import { DEFAULT_SCAN_RETENTION, DEFAULT_SCAN_STORE, ScanStore } from './scanStore';
+import {
+    ChangeBatcher,
+    DEFAULT_DIRECTORY_EVENT_RATE,
+    DEFAULT_WATCH_DEBOUNCE_MS,
+    DEFAULT_WATCH_MAX_WAIT_MS,
+    WatchBatch,
+    createBatchFilter,
+    watchTree,
+} from './watchMode';
+import { normalizeFingerprintPath } from './fingerprint';
+import { effectiveConfig, hashConfig } from './manifest';
+import { DEFAULT_NOTIFICATION_LOG, NotificationLog, parseDedupeWindow } from './notificationDedupe';
+import { DEFAULT_MIN_STRING_LENGTH } from './binaryStrings';
+import { formatSampleEstimate } from './sampling';
 const packageJson = require('../package.json');
 
 const program = new Command();
@@ -28,19 +92,129 @@ program
     .name('url-detector')
     .description('Scan source code and text files for URLs, detecting all discovered URLs')
     .version(packageJson.version)
+    // Options after a subcommand name belong to the subcommand
+    .enablePositionalOptions()
+    .option('--config <file>', 'JSON config file; flags given on the command line take precedence')
+    .option('--profile <name>', `Built-in profile (${PROFILE_NAMES.join(', ')}); flags and --config take precedence`)
     .option('-s, --scan <patterns...>', 'Glob patterns for files to scan', ['**/*'])
     .option('-e, --exclude <patterns...>', 'Glob patterns for files to exclude', [])
     .option('-i, --ignore-domains <domains...>', 'List of domains to ignore (e.g., example.com)', [])
     .option('--include-comments', 'Also scan commented-out lines for URLs', false)
     .option('--include-non-fqdn', 'Include non-fully qualified domain names like "localhost"', false)
-    .option('-f, --format <format>', 'Output format: table, json, csv', 'table')
+    .option(
+        '-f, --format <format>',
+        'Output format: table, json, csv, ndjson, sarif, patchset, html, or a sink',
+        'table',
+    )
+    .option('--sink-module <modules...>', 'Modules that register custom output formats with registerSink()')
     .option('-o, --output <file>', 'Output file path (defaults to stdout)')
+    .option('--output-encoding <encoding>', 'Output file encoding: utf8, utf8-bom, utf16le', 'utf8')
+    .option('--ascii-json', 'Escape non-ASCII characters in json, ndjson, and sarif output', false)
+    .option('--no-csv-formula-guard', "Don't quote CSV cells starting with =, +, -, or @ (formula injection guard)")
+    .option('--csv-fingerprints', 'Add a Fingerprint column to CSV output', false)
     .option('-q, --quiet', 'Run in quiet mode with no console output', false)
     .option('--results-only', 'Show only results, suppressing progress and info messages', false)
     .option('--fail-on-error', 'Exit with non-zero code if any URLs are found', false)
     .option('--concurrency <number>', 'Maximum number of files to scan concurrently', parseInt, 10)
+    .option('--chunk-size <mb>', 'Scan files larger than this many megabytes in segments (0: never)', integerOption(0))
+    .option('--max-file-size <mb>', 'Skip files larger than this many megabytes', integerOption(0))
+    .option('--parse-timeout <ms>', 'Give up parsing a file after this many milliseconds', integerOption(0))
+    .option('--sample <percent>', 'Scan a deterministic sample of files (e.g., 5%) and estimate the full totals')
+    .option('--sample-seed <seed>', 'Seed of --sample; change it to draw different files')
     .option('--scan-file <file>', 'File containing glob patterns to scan (one per line)')
     .option('--exclude-file <file>', 'File containing glob patterns to exclude (one per line)')
+    .option('--patch <file>', 'Scan only the lines a unified diff adds instead of files (- for stdin)')
+    .option('--stream', "Scan stdin as an unbounded text stream, writing each line's findings at once", false)
+    .option(
+        '--stream-max-line <chars>',
+        'Longest line --stream holds in memory; longer lines are scanned in pieces',
+        integerOption(1),
+        DEFAULT_STREAM_MAX_LINE_LENGTH,
+    )
+    .option('--include-git-metadata', 'Also scan commit messages, tag annotations, and .gitmodules URLs', false)
+    .option(
+        '--git-history-depth <count>',
+        'Most recent commits whose messages --include-git-metadata scans (0 for the whole history)',
+        integerOption(0),
+    )
+    .option('--git-blame', "Record the date and commit each finding's line was introduced (from git)", false)
+    .option('--skip-generated', 'Skip generated and minified files instead of tagging their findings', false)
+    .option('--collapse-duplicates', 'Report the findings of identical files, like vendored copies, only once', false)
+    .option('--license-headers', 'Report only URLs in license headers and verify them against canonical URLs', false)
+    .option('--check-license-links', 'Also report unreachable license header URLs (implies --license-headers)', false)
+    .option('--validate-doc-links', 'Check relative Markdown and HTML links for missing files and anchors', false)
+    .option('--include-relative-urls', 'Also report relative URLs and paths in HTML, CSS, and scripts', false)
+    .option('--include-windows-paths', 'Also report UNC paths and file URLs with drive letters', false)
+    .option('--sri-advisory', 'Suggest Subresource Integrity for CDN scripts and stylesheets in HTML', false)
+    .option('--open-redirect', 'Flag URLs whose redirect parameters carry full URLs (e.g., ?next=https://...)', false)
+    .option('--redirect-params <names...>', 'Query parameters checked by --open-redirect (default: next, url, ...)')
+    .option('--encoding-anomalies', 'Flag double-encoding, overlong encodings, and encoded line breaks in URLs', false)
+    .option('--obfuscated-hosts', 'Flag decimal, octal, and hex IP hosts and host names in the userinfo', false)
+    .option('--canary-tokens', 'Flag URLs on canary token hosts, such as canarytokens.com', false)
+    .option('--canary-domains <domains...>', 'More canary token domains, as domain or domain=tripwire|leak')
+    .option('--canary-policy <policy>', 'Whether canary token URLs are tripwires to keep or leaks', 'tripwire')
+    .option(
+        '--false-positive-heuristics [names...]',
+        'Report likely false positives as informational: xml-namespace, example-domain, go-import-path (default: all)',
+    )
+    .option('--audit-go-imports', 'Report Go import and module paths and flag deprecated hosts', false)
+    .option('--go-deprecated-hosts <hosts...>', 'Hosts to flag in Go import paths (default: code.google.com)')
+    .option('--go-forbid-gopkg-in', 'Flag Go imports through gopkg.in', false)
+    .option('--go-allowed-owners <owners...>', 'Allowed github.com/gitlab.com/bitbucket.org owners for Go imports')
+    .option('--go-network-calls', 'Mark Go URLs with the HTTP, gRPC, or WebSocket call they flow into', false)
+    .option('--rule-plugin <modules...>', 'Rule plugins to run in a sandbox without file or network access')
+    .option('--code-owners', 'Attach the owners from the CODEOWNERS file to each finding', false)
+    .option('--data-bundle [file]', 'Imported data bundle for TLD validation and host feed tagging (default: cached)')
+    .option('--cache-dir <dir>', 'Cache directory shared by url-detector runs (default: the XDG cache directory)')
+    .option('--openapi <files...>', 'OpenAPI documents (JSON) that map findings to APIs and flag undeclared endpoints')
+    .option('--deprecation-registry <file>', 'Registry (JSON) of deprecated URL prefixes, replacements, and sunsets')
+    .option('--triage-store <file>', 'Carry triage states forward onto findings from this store')
+    .option('--hide-triaged', 'Drop findings triaged as accepted-risk or false-positive', false)
+    .option('--filter <expression>', 'Report only findings matching a filter expression, or a filter named in --config')
+    .option('--email-to <addresses...>', 'Email the scan summary to these recipients after the scan')
+    .option('--email-from <address>', 'Sender address of the summary email')
+    .option('--smtp <url>', 'SMTP server as smtp://[user@]host[:port] or smtps://...')
+    .option('--email-attach-report', 'Attach the full JSON report to the summary email', false)
+    .option('--notify-dedupe-window <duration>', 'Notify each unchanged finding once per window (e.g., 24h, 7d)')
+    .option(
+        '--notification-log <file>',
+        'Where --notify-dedupe-window records notified findings',
+        DEFAULT_NOTIFICATION_LOG,
+    )
+    .option('--create-jira-issues', 'Open Jira issues for new error-severity findings (needs jira in --config)', false)
+    .option('--webhook <urls...>', 'Post finding lifecycle events (new, resolved, reopened) to these URLs')
+    .option('--scan-store [dir]', 'Store scans to compute lifecycle events against (default: .url-detector/scans)')
+    .option('--group-by <attribute>', 'Group findings by an attribute in the report (e.g., owner, or category)')
+    .option('--team-rollup', 'Report findings per team, by the teams in --config or else CODEOWNERS', false)
+    .option('--team-baseline <report>', 'Previous report to count new and fixed findings per team against')
+    .option('--compare-to <report>', 'Previous report to mark html findings added, removed, or unchanged against')
+    .option(
+        '--worst-offenders <count>',
+        'Worst offending files listed per team in --team-rollup',
+        integerOption(1),
+        DEFAULT_WORST_OFFENDERS,
+    )
+    .option('--environment-report', 'Report services with missing or inconsistent prod/staging/dev endpoints', false)
+    .option('--environments <names...>', 'Environments every service should reference (default: all seen)')
+    .option('--duplicate-endpoints', 'Report URLs hard-coded in many files and where to consolidate them', false)
+    .option(
+        '--duplicate-min-files <number>',
+        'Files a URL must appear in for --duplicate-endpoints',
+        integerOption(2),
+        DEFAULT_DUPLICATE_MIN_FILES,
+    )
+    .option('--port-inventory', 'Report non-standard ports in URLs by host and file, for firewall reviews', false)
+    .option('--report-coverage', 'Report files without a parser for their language, with counts by extension', false)
+    .option('--allow-incompatible-grammars', 'Scan files of grammars the tree-sitter version rejects with regex', false)
+    .option('--reachability-matrix', 'Probe each http(s) URL through every egress and report which can reach it', false)
+    .option('--egress-proxy <proxies...>', 'Egresses for --reachability-matrix as name=url (default: direct only)')
+    .option('--quarantine <file>', 'Move credentials in URLs and high-risk findings to this owner-only report')
+    .option('--quarantine-feeds <feeds...>', 'Host feeds whose hits are quarantined (default: malicious)')
+    .option('--quarantine-rules <rules...>', 'Rule ids whose violations are quarantined')
+    .option('--alert-on-increase <threshold>', 'Fail if findings grow by more than this since the baseline (20% or 50)')
+    .option('--alert-baseline <report>', 'Previous report to compare with for --alert-on-increase')
+    .option('--alert-trend-store <file>', 'Trend store whose latest snapshot is the baseline', DEFAULT_TREND_STORE)
+    .option('--alert-warn-only', 'Report and email a triggered --alert-on-increase without failing', false)
     .action(async options => {
         // Create appropriate logger based on CLI options
         let logger;
@@ -53,47 +227,130 @@ program
         }
 
         try {
-            // Create mutable copy of options for processing
-            let scanPatterns = (options.scan as string[]) || [];
-            let excludePatterns = (options.exclude as string[]) || [];
+            const { options: merged, scanPatterns, excludePatterns } = await resolveScanOptions(program);
+            options = merged;
 
-            // Load patterns from files if specified
-            if (options.scanFile) {
-                const fileScanPatterns = await loadPatternsFromFile(options.scanFile as string);
-                scanPatterns = [...scanPatterns, ...fileScanPatterns];
+            // Reject a malformed threshold or window before a long scan
+            if (options.alertOnIncrease) {
+                parseIncreaseThreshold(options.alertOnIncrease as string);
+            }
+            if (options.notifyDedupeWindow) {
+                parseDedupeWindow(options.notifyDedupeWindow as string);
             }
 
-            if (options.excludeFile) {
-                const fileExcludePatterns = await loadPatternsFromFile(options.excludeFile as string);
-                excludePatterns = [...excludePatterns, ...fileExcludePatterns];
+            // Read the comparison report before a long scan
+            const previous = options.compareTo ? await readReport(options.compareTo as string) : null;
+            if (previous && options.format !== 'html') {
+                logger.warn('--compare-to only applies to --format html');
             }
 
             // Create detector with options and logger
-            const detector = new URLDetector(
-                {
-                    scan: scanPatterns,
-                    exclude: excludePatterns,
-                    ignoreDomains: options.ignoreDomains as string[],
-                    includeComments: options.includeComments as boolean,
-                    includeNonFqdn: options.includeNonFqdn as boolean,
-                    format: options.format as OutputFormat,
-                    output: options.output as string,
-                    resultsOnly: options.resultsOnly as boolean,
-                    failOnError: options.failOnError as boolean,
-                    concurrency: options.concurrency as number,
-                },
-                logger,
-            );
+            const detector = new URLDetector(buildDetectorConfig(options, scanPatterns, excludePatterns), logger);
+            checkGrammarCompatibility(detector, options.allowIncompatibleGrammars as boolean);
 
-            // Process results
-            const results = await detector.process();
+            if (options.stream) {
+                await scanStdinStream(detector, options, logger);
+                return;
+            }
+
+            // Process results; on SIGINT/SIGTERM the findings collected so far are still written
+            const interrupt = handleInterrupts(logger);
+            let results = options.patch
+                ? await detector.scanPatch(await readPatch(options.patch as string))
+                : await detector.process(interrupt.signal);
+            const manifest = await detector.createManifest(options.profile as string | undefined);
+            if (detector.isPartial) {
+                logger.warn(`Scan interrupted after ${manifest.fileCount} file(s); the results are partial`);
+            }
 
             // Calculate summary
             const totalFiles = results.length;
             const totalUrls = results.reduce((sum, r) => sum + r.urls.length, 0);
 
-            // Handle output formatting - format results if we found URLs or if explicitly requested
-            if (totalUrls > 0) {
+            // Report-level analyses
+            const sections: ReportSections = {};
+            // A sample's counts are not comparable with a full baseline
+            if (options.alertOnIncrease && !detector.isPartial && !options.sample) {
+                const baseline = await loadBaselineCount(
+                    options.alertBaseline
+                        ? { report: options.alertBaseline as string }
+                        : { trendStore: options.alertTrendStore as string },
+                );
+                if (baseline) {
+                    const threshold = options.alertOnIncrease as string;
+                    sections.increaseAlert = checkIncrease(baseline.baseline, baseline.count, totalUrls, threshold);
+                    const message = formatIncreaseAlert(sections.increaseAlert);
+                    if (sections.increaseAlert.triggered) logger.warn(message);
+                    else logger.info(message);
+                } else {
+                    logger.warn('No baseline for --alert-on-increase; pass --alert-baseline or run trend record first');
+                }
+            }
+            if (options.quarantine) {
+                // Quarantined findings stay out of every other section, the email, and Jira
+                const { kept, quarantined } = splitQuarantined(results, {
+                    feeds: options.quarantineFeeds as string[] | undefined,
+                    rules: options.quarantineRules as string[] | undefined,
+                });
+                await writeQuarantineReport(options.quarantine as string, quarantined, manifest);
+                sections.quarantine = summarizeQuarantine(quarantined, options.quarantine as string);
+                results = kept;
+            }
+
+            if (options.environmentReport) {
+                sections.environments = analyzeEnvironments(results, {
+                    expectedEnvironments: options.environments as string[] | undefined,
+                });
+            }
+
+            if (options.groupBy) {
+                sections.groupedBy = options.groupBy as string;
+                sections.groups = groupFindings(results, options.groupBy as string);
+            }
+
+            if (options.teamRollup) {
+                const baseline = options.teamBaseline ? await readReport(options.teamBaseline as string) : previous;
+                sections.teams = buildTeamRollup(results, {
+                    teams: options.teams as Record<string, string> | undefined,
+                    baseline: baseline ? baseline.files : undefined,
+                    worstOffenders: options.worstOffenders as number,
+                });
+            }
+
+            if (options.duplicateEndpoints) {
+                sections.duplicates = findDuplicateEndpoints(results, options.duplicateMinFiles as number);
+            }
+
+            if (options.portInventory) {
+                sections.ports = buildPortInventory(results);
+            }
+
+            if (options.reportCoverage) {
+                sections.coverage = detector.getLanguageCoverage();
+            }
+
+            const skipped = detector.getSkippedFiles();
+            if (skipped.length > 0) {
+                sections.skipped = skipped;
+            }
+
+            const sampleEstimate = detector.getSampleEstimate();
+            if (sampleEstimate) {
+                sections.sample = sampleEstimate;
+            }
+
+            if (options.reachabilityMatrix && !detector.isPartial) {
+                const proxies = resolveEgressProxies(
+                    options.egressProxies as Record<string, string> | undefined,
+                    options.egressProxy as string[] | undefined,
+                );
+                logger.info(`Probing URLs through ${Object.keys(proxies).join(', ')}`);
+                sections.reachability = await buildReachabilityMatrix(results, proxies);
+            }
+
+            // Handle output formatting - format results if we found URLs or if explicitly requested;
+            // a comparison still lists the findings that were removed
+            if (totalUrls > 0 || previous) {
                 const outputFormatter = new OutputFormatter(
                     {
                         format: (options.format as OutputFormat) || 'table',
@@ -102,20 +359,114 @@ program
                         withLineNumbers: true,
                         withFilenames: true,
                         context: 0,
+                        encoding: detector.getOptions.outputEncoding,
+                        asciiJson: detector.getOptions.asciiJson,
+                        csvFormulaGuard: detector.getOptions.csvFormulaGuard,
+                        csvFingerprints: detector.getOptions.csvFingerprints,
+                        domainMap: detector.getOptions.domainMap,
+                        compareTo: previous || undefined,
+                        teams: options.teams as Record<string, string> | undefined,
                     },
                     logger,
                 );
 
-                await outputFormatter.formatAndOutput(results);
+                const reportSections = Object.keys(sections).length > 0 ? sections : undefined;
+                await outputFormatter.formatAndOutput(results, reportSections, manifest);
             }
 
             // Print summary using logger
             logger.info(`Processed ${totalFiles} file(s), found ${totalUrls} URL(s)`);
+            const skipSummary = formatSkipSummary(skipped);
+            if (skipSummary) {
+                logger.info(skipSummary);
+            }
+            if (sampleEstimate) {
+                logger.info(formatSampleEstimate(sampleEstimate));
+            }
+
+            // Findings notified within the dedupe window in the same state are left out of notifications
+            const notificationLog = options.notifyDedupeWindow
+                ? new NotificationLog(
+                      parseDedupeWindow(options.notifyDedupeWindow as string),
+                      options.notificationLog as string,
+                  )
+                : null;
+            const dueResults = notificationLog ? await notificationLog.selectDue(results) : results;
+            let notified = false;
+
+            const email = resolveEmailConfig(options.email as EmailConfig | undefined, {
+                to: options.emailTo as string[] | undefined,
+                from: options.emailFrom as string | undefined,
+                smtp: options.smtp as string | undefined,
+                attachReport: options.emailAttachReport as boolean,
+            });
+            const alertTriggered = !!sections.increaseAlert && sections.increaseAlert.triggered;
+            if (email && notificationLog && dueResults.length === 0 && !alertTriggered) {
+                logger.info('No findings due within the dedupe window; not emailing the scan summary');
+            } else if (email) {
+                const report = createReport(
+                    dueResults,
+                    Object.keys(sections).length > 0 ? sections : undefined,
+                    manifest,
+                );
+                await sendEmail(email.smtp, createReportEmail(report, email));
+                logger.info(`Emailed the scan summary to ${email.to.join(', ')}`);
+                notified = true;
+            }
+
+            if (options.createJiraIssues && !detector.isPartial) {
+                if (!options.jira) {
+                    throw new Error('--create-jira-issues requires a jira section in the --config file');
+                }
+                const sync = await syncJiraIssues(dueResults, options.jira as JiraConfig);
+                logger.info(`Created ${sync.created.length} Jira issue(s), ${sync.existing.length} already tracked`);
+                notified = true;
+            }
+
+            if (notificationLog && notified) {
+                await notificationLog.record(dueResults);
+            }
+
+            // Webhooks use the default store unless --scan-store names one
+            const webhooks: WebhookConfig[] = [
+                ...((options.webhooks as WebhookConfig[] | undefined) || []),
+                ...((options.webhook as string[] | undefined) || []).map(url => ({ url })),
+            ];
+            const scanStore =
+                options.scanStore === true || (!options.scanStore && webhooks.length > 0)
+                    ? DEFAULT_SCAN_STORE
+                    : (options.scanStore as string | undefined);
+            if (scanStore && (detector.isPartial || sampleEstimate || options.patch)) {
+                // Findings a partial, sampled, or patch scan did not look at would count as resolved
+                logger.warn('Not storing an incomplete scan; lifecycle events compare complete scans only');
+            } else if (scanStore) {
+                const profile = (options.profile as string | undefined) || DEFAULT_PROFILE;
+                const lifecycle = await recordLifecycle(new ScanStore(scanStore), profile, results);
+                if (!lifecycle) {
+                    logger.info(`Stored the first scan of profile ${profile} in ${scanStore} as the baseline`);
+                } else {
+                    logger.info(`Since the previous scan: ${formatLifecycleCounts(lifecycle.events)}`);
+                    for (const webhook of webhooks) {
+                        const posted = await postLifecycleEvents(lifecycle, webhook);
+                        if (posted > 0) logger.info(`Posted ${posted} lifecycle event(s) to ${webhook.url}`);
+                    }
+                }
+            }
+
+            const received = interrupt.received();
+            interrupt.dispose();
+            if (received) {
+                process.exit(INTERRUPT_EXIT_CODES[received]);
+            }
 
             // Exit with error code if URLs found and fail-on-error is set
             if (options.failOnError && totalUrls > 0) {
                 process.exit(1);
             }
+
+            if (sections.increaseAlert && sections.increaseAlert.triggered && !options.alertWarnOnly) {
+                process.exit(1);
+            }
         } catch (error: unknown) {
             // Use the same logger - errors will be shown in results-only mode, hidden in quiet mode
             const errorMessage = error instanceof Error ? error.message : String(error);
@@ -124,6 +475,824 @@ program
         }
     });
 
+program
+    .command('ls')
+    .description('List the files a scan with the options before ls would scan, and how, without scanning them')
+    .option('-f, --format <format>', 'Output format: table, json', 'table')
+    .action(async options => {
+        const logger = ConsoleLogger;
+        try {
+            if (options.format !== 'table' && options.format !== 'json') {
+                throw new Error(`Invalid format: ${options.format}. Must be one of: table, json`);
+            }
+            const { options: scanOptions, scanPatterns, excludePatterns } = await resolveScanOptions(program);
+
+            const detector = new URLDetector(buildDetectorConfig(scanOptions, scanPatterns, excludePatterns), logger);
+            const listings = await detector.listFiles();
+
+            if (options.format === 'json') {
+                logger.log(JSON.stringify(listings, null, 2));
+            } else {
+                const width = Math.max(4, ...listings.map(listing => listing.file.length));
+                for (const listing of listings) {
+                    const mode = listing.reason ? `${listing.mode} (${listing.reason})` : listing.mode;
+                    logger.log(`${listing.file.padEnd(width)}  ${listing.language.padEnd(12)}  ${mode}`);
+                }
+            }
+
+            const count = (mode: string) => listings.filter(listing => listing.mode === mode).length;
+            logger.info(
+                `${listings.length} file(s): ${count('parse')} parsed, ${count('regex')} scanned with regex, ` +
+                    `${count('skip')} skipped`,
+            );
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+program
+    .command('watch')
+    .description('Scan, then rescan changed files as they change, with the options before watch')
+    .option(
+        '--debounce <ms>',
+        'Quiet period after the last change before rescanning',
+        integerOption(0),
+        DEFAULT_WATCH_DEBOUNCE_MS,
+    )
+    .option(
+        '--max-wait <ms>',
+        'Longest a change waits while changes keep arriving',
+        integerOption(0),
+        DEFAULT_WATCH_MAX_WAIT_MS,
+    )
+    .option(
+        '--dir-event-rate <per-second>',
+        'Changes per second in one directory before the whole directory is rescanned',
+        integerOption(1),
+        DEFAULT_DIRECTORY_EVENT_RATE,
+    )
+    .action(async options => {
+        const logger = ConsoleLogger;
+        try {
+            const { options: scanOptions, scanPatterns, excludePatterns } = await resolveScanOptions(program);
+            const detector = new URLDetector(buildDetectorConfig(scanOptions, scanPatterns, excludePatterns), logger);
+            // Every batch is printed, so the report goes to stdout rather than an --output file
+            const outputFormatter = new OutputFormatter(
+                {
+                    format: (scanOptions.format as OutputFormat) || 'table',
+                    outputFile: null,
+                    withLineNumbers: true,
+                    withFilenames: true,
+                    context: 0,
+                    encoding: detector.getOptions.outputEncoding,
+                    asciiJson: detector.getOptions.asciiJson,
+                    csvFormulaGuard: detector.getOptions.csvFormulaGuard,
+                    csvFingerprints: detector.getOptions.csvFingerprints,
+                    domainMap: detector.getOptions.domainMap,
+                },
+                logger,
+            );
+
+            const initial = await detector.process();
+            if (initial.some(result => result.urls.length > 0)) {
+                await outputFormatter.formatAndOutput(initial);
+            }
+            logger.info(`Processed ${initial.length} file(s); watching for changes`);
+
+            const rescan = async (batch: WatchBatch): Promise<void> => {
+                // Listing again applies the scan and exclude patterns to new files and drops deleted ones
+                const listings = await detector.listFiles();
+                const inBatch = createBatchFilter(batch);
+                const files = listings
+                    .filter(listing => listing.mode !== 'skip')
+                    .map(listing => listing.file)
+                    .filter(file => inBatch(normalizeFingerprintPath(file)));
+                if (files.length === 0) return;
+
+                const results = await detector.processFiles(files);
+                const totalUrls = results.reduce((sum, result) => sum + result.urls.length, 0);
+                if (totalUrls > 0) {
+                    await outputFormatter.formatAndOutput(results);
+                }
+                logger.info(`Rescanned ${files.length} file(s), found ${totalUrls} URL(s)`);
+            };
+
+            const batcher = new ChangeBatcher(
+                rescan,
+                {
+                    debounce: options.debounce as number,
+                    maxWait: options.maxWait as number,
+                    directoryEventRate: options.dirEventRate as number,
+                },
+                logger,
+            );
+            const watcher = watchTree('.', batcher);
+            const stop = async () => {
+                watcher.close();
+                await batcher.close();
+                process.exit(0);
+            };
+            process.once('SIGINT', stop);
+            process.once('SIGTERM', stop);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+program
+    .command('binary')
+    .description('Scan compiled binaries (ELF, PE, Mach-O) for baked-in URLs, with the options before binary')
+    .argument('<files...>', 'Binaries to scan')
+    .option('--min-length <chars>', 'Shortest printable string to extract', integerOption(1), DEFAULT_MIN_STRING_LENGTH)
+    .action(async (files: string[], options) => {
+        const logger = ConsoleLogger;
+        try {
+            const { options: scanOptions } = await resolveScanOptions(program);
+            const detector = new URLDetector(buildDetectorConfig(scanOptions, files, []), logger);
+
+            const results = [];
+            for (const file of files) {
+                results.push(await detector.scanBinary(file, options.minLength as number));
+            }
+
+            const totalUrls = results.reduce((sum, result) => sum + result.urls.length, 0);
+            if (totalUrls > 0) {
+                const outputFormatter = new OutputFormatter(
+                    {
+                        format: (scanOptions.format as OutputFormat) || 'table',
+                        outputFile: (scanOptions.output as string) || null,
+                        withLineNumbers: true,
+                        withFilenames: true,
+                        context: 0,
+                        encoding: detector.getOptions.outputEncoding,
+                        asciiJson: detector.getOptions.asciiJson,
+                        csvFormulaGuard: detector.getOptions.csvFormulaGuard,
+                        csvFingerprints: detector.getOptions.csvFingerprints,
+                    },
+                    logger,
+                );
+                await outputFormatter.formatAndOutput(
+                    results.filter(result => result.urls.length > 0),
+                    undefined,
+                    await detector.createManifest(scanOptions.profile as string | undefined),
+                );
+            }
+            logger.info(`Processed ${results.length} binary file(s), found ${totalUrls} URL(s)`);
+
+            if (scanOptions.failOnError && totalUrls > 0) {
+                process.exit(1);
+            }
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+program
+    .command('action')
+    .description('Run as a GitHub Action step, configured by INPUT_* environment variables (see README)')
+    .action(async () => {
+        const logger = ConsoleLogger;
+        try {
+            const inputs = readActionInputs();
+            // Precedence: action inputs, then the config file, then the profile
+            const config: DetectorOptionsConfig = {
+                ...(inputs.profile ? getProfile(inputs.profile).options : {}),
+                ...(inputs.config ? await DetectorOptions.loadConfigFile(inputs.config) : {}),
+            };
+            if (inputs.scan.length > 0) config.scan = inputs.scan;
+            if (inputs.exclude.length > 0) config.exclude = inputs.exclude;
+
+            const detector = new URLDetector({ ...config, format: inputs.format }, logger);
+            const results = await detector.process();
+            const skipped = detector.getSkippedFiles();
+
+            if (inputs.output) {
+                const manifest = await detector.createManifest(inputs.profile);
+                const outputFormatter = new OutputFormatter(
+                    {
+                        format: inputs.format,
+                        outputFile: inputs.output,
+                        withLineNumbers: true,
+                        withFilenames: true,
+                        context: 0,
+                        encoding: detector.getOptions.outputEncoding,
+                        asciiJson: detector.getOptions.asciiJson,
+                        csvFormulaGuard: detector.getOptions.csvFormulaGuard,
+                        csvFingerprints: detector.getOptions.csvFingerprints,
+                        domainMap: detector.getOptions.domainMap,
+                    },
+                    logger,
+                );
+                await outputFormatter.formatAndOutput(results, skipped.length > 0 ? { skipped } : undefined, manifest);
+            }
+
+            const matcher = await writeProblemMatcher();
+            logger.log(`::add-matcher::${matcher}`);
+            formatProblemLines(results).forEach(line => logger.log(line));
+            logger.log(`::remove-matcher owner=${PROBLEM_MATCHER_OWNER}::`);
+
+            await writeStepSummary(formatStepSummary(results, skipped));
+            const findings = results.reduce((sum, result) => sum + result.urls.length, 0);
+            const violations = results.reduce(
+                (sum, result) => sum + result.urls.reduce((count, url) => count + (url.violations || []).length, 0),
+                0,
+            );
+            await writeStepOutputs({ findings, violations, report: inputs.output || '' });
+
+            const skipSummary = formatSkipSummary(skipped);
+            logger.info(`Processed ${results.length} file(s), found ${findings} URL(s), ${violations} violation(s)`);
+            if (skipSummary) {
+                logger.info(skipSummary);
+            }
+            if (shouldFail(results, inputs.failOn)) {
+                logger.error(`Failing the step (fail-on: ${inputs.failOn})`);
+                process.exit(1);
+            }
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+const trend = program.command('trend').description('Track finding counts per commit or date for dashboards');
+
+trend
+    .command('record')
+    .description('Record the finding counts of a report in the trend store')
+    .argument('<report>', 'Report file written with --format json, ndjson, or sarif')
+    .option('--store <file>', 'Trend store file', DEFAULT_TREND_STORE)
+    .option('--commit <sha>', 'Commit the snapshot is recorded for (defaults to the current HEAD)')
+    .option('--date <date>', 'Record the snapshot for a date (YYYY-MM-DD) instead of a commit')
+    .action(async (reportFile: string, options) => {
+        const logger = ConsoleLogger;
+        try {
+            const report = await readReport(reportFile);
+            let commit = options.commit as string | undefined;
+            if (!commit && !options.date) {
+                commit = await runGit(process.cwd(), ['rev-parse', 'HEAD'])
+                    .then(stdout => stdout.trim())
+                    .catch(() => undefined);
+            }
+
+            const snapshot = createTrendSnapshot(report, { commit, date: options.date as string | undefined });
+            const snapshots = await new TrendStore(options.store as string).record(snapshot);
+            logger.info(`Recorded ${snapshot.total} finding(s) for ${snapshot.key} (${snapshots.length} snapshot(s))`);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+trend
+    .command('report')
+    .description('Output the recorded finding counts as a time series')
+    .option('--store <file>', 'Trend store file', DEFAULT_TREND_STORE)
+    .option('--since <date>', 'Only include snapshots recorded on or after this date')
+    .option('-f, --format <format>', 'Output format: json, csv', 'json')
+    .option('-o, --output <file>', 'Output file path (defaults to stdout)')
+    .action(async options => {
+        const logger = ConsoleLogger;
+        try {
+            const snapshots = await new TrendStore(options.store as string).series(options.since as string | undefined);
+            const output = formatTrendSeries(snapshots, options.format as TrendFormat);
+            if (options.output) {
+                await fs.promises.writeFile(options.output as string, output, 'utf8');
+                logger.info(`Output written to ${options.output}`);
+            } else {
+                logger.log(output);
+            }
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+program
+    .command('schema')
+    .description('Print the JSON Schema of the config file or the JSON report format')
+    .argument('<name>', `Schema to print: ${SCHEMA_NAMES.join(', ')}`)
+    .action((name: string) => {
+        const logger = ConsoleLogger;
+        try {
+            logger.log(JSON.stringify(getSchema(name as SchemaName), null, 2));
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+const config = program.command('config').description('Inspect the configuration scans run with');
+
+config
+    .command('print-effective')
+    .description('Print the options a scan with the options before config would run with, as in report manifests')
+    .action(async () => {
+        const logger = ConsoleLogger;
+        try {
+            const { options: scanOptions, scanPatterns, excludePatterns } = await resolveScanOptions(program);
+            const options = new DetectorOptions(buildDetectorConfig(scanOptions, scanPatterns, excludePatterns));
+
+            logger.log(
+                JSON.stringify(
+                    {
+                        profile: scanOptions.profile as string | undefined,
+                        configHash: hashConfig(options),
+                        effectiveConfig: effectiveConfig(options),
+                    },
+                    null,
+                    2,
+                ),
+            );
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+const triage = program.command('triage').description('Track remediation decisions for findings by fingerprint');
+
+triage
+    .command('set')
+    .description('Set the triage state of findings')
+    .argument('<state>', `New state: ${TRIAGE_STATES.join(', ')}`)
+    .argument('<fingerprints...>', 'Fingerprints of the findings, as shown in JSON reports')
+    .option('--store <file>', 'Triage store file', DEFAULT_TRIAGE_STORE)
+    .option('--note <text>', 'Reason for the decision')
+    .action(async (state: string, fingerprints: string[], options) => {
+        const logger = ConsoleLogger;
+        try {
+            const store = new TriageStore(options.store as string);
+            const entries = await store.setState(fingerprints, parseTriageState(state), options.note as string);
+            logger.info(`Marked ${entries.length} finding(s) as ${state}`);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+triage
+    .command('list')
+    .description('List triage decisions as JSON')
+    .option('--store <file>', 'Triage store file', DEFAULT_TRIAGE_STORE)
+    .option('--state <state>', 'Only list findings in this state')
+    .action(async options => {
+        const logger = ConsoleLogger;
+        try {
+            const state = options.state ? parseTriageState(options.state as string) : undefined;
+            const entries = Array.from((await new TriageStore(options.store as string).load()).values());
+            logger.log(JSON.stringify({ entries: entries.filter(entry => !state || entry.state === state) }, null, 2));
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+const data = program.command('data').description('Package the TLD list and host feeds for offline environments');
+
+data
+    .command('pull')
+    .description('Download the TLD list and host feeds into a signed bundle')
+    .requiredOption('--sign-key <file>', 'PEM private key to sign the bundle with (Ed25519 recommended)')
+    .option('--tlds <source>', 'URL or file of the TLD list', DEFAULT_TLD_SOURCE)
+    .option('--feed <feeds...>', 'Host feeds as name=source (e.g., shortener=https://example.com/list.txt)', [])
+    .option('-o, --output <file>', 'Bundle file to write', 'url-detector-data.json')
+    .action(async options => {
+        const logger = ConsoleLogger;
+        try {
+            const feedSources: Record<string, string> = {};
+            for (const feed of options.feed as string[]) {
+                const separator = feed.indexOf('=');
+                if (separator <= 0) {
+                    throw new Error(`Invalid feed "${feed}"; expected name=source`);
+                }
+                feedSources[feed.substring(0, separator)] = feed.substring(separator + 1);
+            }
+
+            const content = await pullDataBundle(options.tlds as string, feedSources);
+            const privateKey = await fs.promises.readFile(options.signKey as string, 'utf8');
+            const bundle = signDataBundle(content, privateKey);
+            await fs.promises.writeFile(options.output as string, JSON.stringify(bundle, null, 2), 'utf8');
+            const feedCount = Object.keys(content.feeds).length;
+            logger.info(`Wrote ${content.tlds.length} TLD(s) and ${feedCount} feed(s) to ${options.output}`);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+data
+    .command('import')
+    .description('Verify a bundle and install it for --data-bundle')
+    .argument('<bundle>', 'Bundle file written by data pull')
+    .requiredOption('--public-key <file>', 'PEM public key the bundle must be signed with')
+    .option('--target <file>', 'Where to install the bundle (default: the cache directory)')
+    .action(async (bundleFile: string, options) => {
+        const logger = ConsoleLogger;
+        try {
+            const cache = new CacheDirectory(resolveCacheDir(program.opts().cacheDir));
+            const target = (options.target as string | undefined) || cache.path(DATA_BUNDLE_CACHE_ENTRY);
+            const publicKey = await fs.promises.readFile(options.publicKey as string, 'utf8');
+            const content = await importDataBundle(bundleFile, publicKey, options.target as string | undefined, cache);
+            logger.info(`Imported data pulled at ${content.createdAt} into ${target}`);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+const cache = program.command('cache').description('Inspect or clear the cache directory shared by url-detector runs');
+
+cache
+    .command('info')
+    .description('List the cache directory and its entries as JSON')
+    .action(async () => {
+        const logger = ConsoleLogger;
+        try {
+            const info = await new CacheDirectory(resolveCacheDir(program.opts().cacheDir)).info();
+            logger.log(JSON.stringify(info, null, 2));
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+cache
+    .command('clear')
+    .description('Remove every entry of the cache directory, waiting for running writers')
+    .action(async () => {
+        const logger = ConsoleLogger;
+        try {
+            const directory = new CacheDirectory(resolveCacheDir(program.opts().cacheDir));
+            const removed = await directory.clear();
+            logger.info(`Removed ${removed} entr${removed === 1 ? 'y' : 'ies'} from ${directory.root}`);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+program
+    .command('serve')
+    .description('Serve scans over HTTP, with a policy profile selected per request')
+    .option('--port <number>', 'Port to listen on (0 picks a free port)', integerOption(0, 65535), 8080)
+    .option('--host <host>', 'Interface to bind', '127.0.0.1')
+    .option('--profiles <file>', 'JSON file mapping profile names to configurations (like --config)')
+    .option(
+        '--cache-size <number>',
+        'File results cached per profile (0 disables)',
+        integerOption(0),
+        DEFAULT_CACHE_SIZE,
+    )
+    .option('--scan-store [dir]', 'Store completed scans for the query endpoints (default: .url-detector/scans)')
+    .option(
+        '--scan-retention <number>',
+        'Stored scans kept before the oldest are deleted',
+        integerOption(1),
+        DEFAULT_SCAN_RETENTION,
+    )
+    .action(async options => {
+        const logger = ConsoleLogger;
+        try {
+            // The root --config provides the default profile unless the profiles file defines one
+            const rootConfig = program.opts().config as string | undefined;
+            const profiles: Record<string, DetectorOptionsConfig> = {
+                [DEFAULT_PROFILE]: rootConfig ? await DetectorOptions.loadConfigFile(rootConfig) : {},
+                ...(options.profiles ? await loadServerProfiles(options.profiles as string) : {}),
+            };
+
+            // A bare --scan-store uses the default directory
+            const scanStore =
+                options.scanStore === true ? DEFAULT_SCAN_STORE : (options.scanStore as string | undefined);
+            const server = new ScanServer(
+                {
+                    profiles,
+                    cacheSize: options.cacheSize as number,
+                    scanStore,
+                    scanRetention: options.scanRetention as number,
+                },
+                logger,
+            );
+            const address = await server.listen(options.port as number, options.host as string);
+            logger.info(`Listening on http://${address.address}:${address.port}`);
+            logger.info(`Profiles: ${Object.keys(profiles).join(', ')}`);
+            if (scanStore) logger.info(`Storing scans in ${scanStore}`);
+        } catch (error: unknown) {
+            const errorMessage = error instanceof Error ? error.message : String(error);
+            logger.error('Error:', errorMessage);
+            process.exit(1);
+        }
+    });
+
+/**
+ * Creates a Commander option parser accepting base-10 integers within a range.
+ *
+ * @param min Smallest accepted value
+ * @param max Largest accepted value (default: no limit)
+ * @returns Parses an option value
+ */
+function integerOption(min: number, max: number = Number.MAX_SAFE_INTEGER): (value: string) => number {
+    return value => {
+        const parsed = Number(value);
+        if (!/^\s*-?\d+\s*$/.test(value) || parsed < min || parsed > max) {
+            const range = max === Number.MAX_SAFE_INTEGER ? `>= ${min}` : `from ${min} to ${max}`;
+            throw new InvalidArgumentError(`Expected an integer ${range}.`);
+        }
+        return parsed;
+    };
+}
+
+/**
+ * Combines the scan and exclude patterns given directly with those read from --scan-file and --exclude-file.
+ */
+async function resolvePatterns(
+    options: Record<string, unknown>,
+): Promise<{ scanPatterns: string[]; excludePatterns: string[] }> {
+    let scanPatterns = (options.scan as string[]) || [];
+    let excludePatterns = (options.exclude as string[]) || [];
+
+    if (options.scanFile) {
+        scanPatterns = [...scanPatterns, ...(await loadPatternsFromFile(options.scanFile as string))];
+    }
+    if (options.excludeFile) {
+        excludePatterns = [...excludePatterns, ...(await loadPatternsFromFile(options.excludeFile as string))];
+    }
+
+    return { scanPatterns, excludePatterns };
+}
+
+/**
+ * Builds the detector configuration from the scan options of the root command.
+ */
+function buildDetectorConfig(
+    options: Record<string, unknown>,
+    scanPatterns: string[],
+    excludePatterns: string[],
+): DetectorOptionsConfig {
+    // A bare --data-bundle loads the bundle data import installed in the cache directory
+    const cache = new CacheDirectory(resolveCacheDir(options.cacheDir as string | undefined));
+    const dataBundle = options.dataBundle === true ? cache.path(DATA_BUNDLE_CACHE_ENTRY) : options.dataBundle;
+
+    return {
+        scan: scanPatterns,
+        exclude: excludePatterns,
+        ignoreDomains: options.ignoreDomains as string[],
+        includeComments: options.includeComments as boolean,
+        includeNonFqdn: options.includeNonFqdn as boolean,
+        format: options.format as OutputFormat,
+        output: options.output as string,
+        outputEncoding: options.outputEncoding as OutputEncoding,
+        asciiJson: options.asciiJson as boolean,
+        csvFormulaGuard: options.csvFormulaGuard as boolean,
+        csvFingerprints: options.csvFingerprints as boolean,
+        resultsOnly: options.resultsOnly as boolean,
+        failOnError: options.failOnError as boolean,
+        concurrency: options.concurrency as number,
+        chunkSize: options.chunkSize as number | undefined,
+        maxFileSize: options.maxFileSize as number | undefined,
+        parseTimeout: options.parseTimeout as number | undefined,
+        sample: options.sample as string | undefined,
+        sampleSeed: options.sampleSeed as string | undefined,
+        maxDepth: options.maxDepth as number | undefined,
+        fallbackRegex: options.fallbackRegex as boolean | undefined,
+        genericTokenizer: options.genericTokenizer as boolean | undefined,
+        languageOptions: options.languageOptions as LanguageOptions | undefined,
+        context: options.context as number | undefined,
+        includeGitMetadata: options.includeGitMetadata as boolean,
+        gitHistoryDepth: options.gitHistoryDepth as number | undefined,
+        gitBlame: options.gitBlame as boolean,
+        skipGenerated: options.skipGenerated as boolean,
+        collapseDuplicates: options.collapseDuplicates as boolean,
+        licenseHeaders: options.licenseHeaders as boolean,
+        checkLicenseLinks: options.checkLicenseLinks as boolean,
+        validateDocLinks: options.validateDocLinks as boolean,
+        includeRelativeUrls: options.includeRelativeUrls as boolean,
+        includeWindowsPaths: options.includeWindowsPaths as boolean,
+        sriAdvisory: options.sriAdvisory as boolean,
+        encodingAnomalies: options.encodingAnomalies as boolean,
+        obfuscatedHosts: options.obfuscatedHosts as boolean,
+        openRedirect: options.openRedirect as boolean,
+        redirectParams: options.redirectParams as string[] | undefined,
+        canaryTokens: options.canaryTokens as boolean,
+        canaryDomains: options.canaryDomains as string[] | undefined,
+        canaryPolicy: options.canaryPolicy as CanaryPolicy,
+        falsePositiveHeuristics:
+            options.falsePositiveHeuristics === true
+                ? FALSE_POSITIVE_HEURISTICS
+                : (options.falsePositiveHeuristics as FalsePositiveHeuristic[] | undefined),
+        codeOwners:
+            (options.codeOwners as boolean) ||
+            options.groupBy === OWNER_ATTRIBUTE ||
+            ((!!options.teamRollup || !!options.compareTo) && !options.teams),
+        teams: options.teams as Record<string, string> | undefined,
+        dataBundle: dataBundle as string | undefined,
+        openApiSpecs: [
+            ...((options.openApiSpecs as string[] | undefined) || []),
+            ...((options.openapi as string[] | undefined) || []),
+        ],
+        deprecationRegistry: options.deprecationRegistry as string | undefined,
+        domainMap: options.domainMap as Record<string, string> | undefined,
+        triageStore: options.triageStore as string | undefined,
+        hideTriaged: options.hideTriaged as boolean,
+        filter: options.filter as string | undefined,
+        filters: options.filters as Record<string, string> | undefined,
+        severityEscalation: options.severityEscalation as SeverityEscalation[] | undefined,
+        categoryRules: options.categoryRules as CategoryRule[] | undefined,
+        schemePolicy: options.schemePolicy as SchemePolicyEntry[] | undefined,
+        auditGoImports: options.auditGoImports as boolean,
+        goImportPolicy: mergeGoImportPolicy(options.goImportPolicy as GoImportPolicy | undefined, {
+            deprecatedHosts: options.goDeprecatedHosts as string[] | undefined,
+            forbidGopkgIn: options.goForbidGopkgIn as boolean,
+            allowedOwners: options.goAllowedOwners as string[] | undefined,
+        }),
+        goNetworkCalls: options.goNetworkCalls as boolean,
+        rulePlugins: [
+            ...((options.rulePlugins as RulePluginConfig[] | undefined) || []),
+            ...((options.rulePlugin as string[] | undefined) || []).map(module => ({ module })),
+        ],
+    };
+}
+
+/**
+ * Merges the root command's options with its --config file and --profile, where the command line takes
+ * precedence over the config file and the config file over the profile, loads the --sink-module modules,
+ * and resolves the scan patterns.
+ */
+async function resolveScanOptions(
+    command: Command,
+): Promise<{ options: OptionValues; scanPatterns: string[]; excludePatterns: string[] }> {
+    if (command.opts().config) {
+        applyConfigFile(command, await DetectorOptions.loadConfigFile(command.opts().config as string));
+    }
+    if (command.opts().profile) {
+        applyProfile(command, getProfile(command.opts().profile as string));
+    }
+    const options = command.opts();
+    // Sinks register their formats before any detector options check the format
+    if (options.sinkModule) {
+        loadSinkModules(options.sinkModule as string[]);
+    }
+    return { options, ...(await resolvePatterns(options)) };
+}
+
+/**
+ * Applies config file values to every option that was not given on the command line.
+ */
+function applyConfigFile(command: Command, config: DetectorOptionsConfig): void {
+    for (const [key, value] of Object.entries(config)) {
+        if (command.getOptionValueSource(key) !== 'cli') {
+            command.setOptionValueWithSource(key, value, 'config');
+        }
+    }
+}
+
+/**
+ * Applies a built-in profile to every option that neither the command line nor the config file set.
+ */
+function applyProfile(command: Command, profile: Profile): void {
+    for (const [key, value] of Object.entries(profile.options)) {
+        const source = command.getOptionValueSource(key);
+        if (source === undefined || source === 'default') {
+            command.setOptionValueWithSource(key, value, 'profile');
+        }
+    }
+}
+
+/**
+ * Loads modules that register custom output formats. Relative paths are resolved from the working directory.
+ */
+function loadSinkModules(modules: string[]): void {
+    for (const module of modules) {
+        // eslint-disable-next-line @typescript-eslint/no-require-imports
+        require(require.resolve(module, { paths: [process.cwd()] }));
+    }
+}
+
+/**
+ * Fails with one diagnostic per grammar the tree-sitter core library rejected, instead of silently
+ * scanning those languages with the regex fallback, unless incompatible grammars are allowed.
+ */
+function checkGrammarCompatibility(detector: URLDetector, allowIncompatible: boolean): void {
+    const issues = detector.getGrammarIssues();
+    if (issues.length === 0 || allowIncompatible) return;
+
+    throw new Error(
+        `Incompatible tree-sitter grammars:\n${issues.map(issue => `  ${formatGrammarIssue(issue)}`).join('\n')}\n` +
+            `Reinstall grammars built for tree-sitter ${LanguageManager.getTreeSitterVersion()}, ` +
+            'or pass --allow-incompatible-grammars to scan their files with regex detection',
+    );
+}
+
+/**
+ * Combines the email section of a config file with the --email-* and --smtp flags, which take precedence.
+ */
+function resolveEmailConfig(fromConfig: EmailConfig | undefined, fromFlags: Partial<EmailConfig>): EmailConfig | null {
+    const to = fromFlags.to || (fromConfig && fromConfig.to);
+    if (!to || to.length === 0) return null;
+
+    const smtp = fromFlags.smtp || (fromConfig && fromConfig.smtp);
+    const from = fromFlags.from || (fromConfig && fromConfig.from);
+    if (!smtp || !from) {
+        throw new Error('Emailing the summary requires --smtp and --email-from (or an email section in --config)');
+    }
+    return {
+        smtp,
+        from,
+        to,
+        subject: fromConfig && fromConfig.subject,
+        attachReport: fromFlags.attachReport || (fromConfig && fromConfig.attachReport),
+    };
+}
+
+/**
+ * Combines the egressProxies from a config file with the --egress-proxy flags (name=url), which take precedence.
+ * Probes go direct when neither is given.
+ */
+function resolveEgressProxies(
+    fromConfig: Record<string, string> | undefined,
+    fromFlags: string[] | undefined,
+): Record<string, string> {
+    if (!fromFlags || fromFlags.length === 0) {
+        return fromConfig && Object.keys(fromConfig).length > 0 ? fromConfig : { [DIRECT_EGRESS]: '' };
+    }
+
+    const proxies: Record<string, string> = {};
+    for (const proxy of fromFlags) {
+        const separator = proxy.indexOf('=');
+        if (separator <= 0) {
+            throw new Error(`Invalid egress proxy "${proxy}"; expected name=url`);
+        }
+        proxies[proxy.substring(0, separator)] = proxy.substring(separator + 1);
+    }
+    return proxies;
+}
+
+/**
+ * Combines the goImportPolicy from a config file with the --go-* flags, which take precedence.
+ */
+function mergeGoImportPolicy(fromConfig: GoImportPolicy | undefined, fromFlags: GoImportPolicy): GoImportPolicy {
+    return {
+        deprecatedHosts: fromFlags.deprecatedHosts || (fromConfig && fromConfig.deprecatedHosts),
+        forbidGopkgIn: fromFlags.forbidGopkgIn || (fromConfig && fromConfig.forbidGopkgIn),
+        allowedOwners: fromFlags.allowedOwners || (fromConfig && fromConfig.allowedOwners),
+    };
+}
+
+/**
+ * Scans stdin for --stream, writing the findings of each line to stdout as ndjson finding records as soon
+ * as the line is read. The records are the output, so --quiet and --results-only only silence the
+ * messages. The stream ends at end of input or on SIGINT/SIGTERM, which exits as an interrupted scan does.
+ */
+async function scanStdinStream(detector: URLDetector, options: OptionValues, logger: Logger): Promise<void> {
+    if (options.format !== 'ndjson') {
+        throw new Error('--stream writes findings as they are found and needs --format ndjson');
+    }
+
+    const asciiJson = detector.getOptions.asciiJson;
+    const interrupt = handleInterrupts(logger);
+    const summary = await detector.scanStream(
+        process.stdin,
+        result => {
+            for (const finding of result.urls) {
+                const record = serializeNdjsonFinding(result.file, finding);
+                process.stdout.write(`${asciiJson ? escapeNonAscii(record) : record}\n`);
+            }
+        },
+        { maxLineLength: options.streamMaxLine as number, signal: interrupt.signal },
+    );
+    const received = interrupt.received();
+    interrupt.dispose();
+
+    logger.info(`Processed ${summary.lines} line(s), found ${summary.urls} URL(s)`);
+    if (received) {
+        process.exit(INTERRUPT_EXIT_CODES[received]);
+    }
+    // As in a scan, --fail-on-error fails on any URL reported
+    if (options.failOnError && summary.urls > 0) {
+        process.exit(1);
+    }
+}
+
+async function readPatch(file: string): Promise<string> {
+    return file === '-' ? fs.readFileSync(process.stdin.fd, 'utf8') : fs.promises.readFile(file, 'utf8');
+}
+
 async function loadPatternsFromFile(filePath: string): Promise<string[]> {
     const content = await fs.promises.readFile(filePath, 'utf8');
     return content
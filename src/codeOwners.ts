@@ -0,0 +1,116 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import { minimatch } from 'minimatch';
+
+/** Attribute holding the owners of the file a finding was detected in, separated by spaces */
+export const OWNER_ATTRIBUTE = 'owner';
+
+/** Locations searched for a CODEOWNERS file, in the order GitHub uses */
+export const CODEOWNERS_LOCATIONS = ['.github/CODEOWNERS', 'CODEOWNERS', 'docs/CODEOWNERS'];
+
+/**
+ * A single CODEOWNERS entry.
+ */
+export interface CodeOwnersRule {
+    /** Path pattern as written in the file */
+    pattern: string;
+    /** Owners (users, teams, or emails); empty when the entry removes ownership */
+    owners: string[];
+    /** Line number of the entry (1-indexed) */
+    line: number;
+}
+
+/**
+ * Parses the entries of a CODEOWNERS file, skipping blank lines and comments.
+ *
+ * @param content Content of the CODEOWNERS file
+ * @returns Entries in file order
+ */
+export function parseCodeOwners(content: string): CodeOwnersRule[] {
+    const rules: CodeOwnersRule[] = [];
+    content.split('\n').forEach((rawLine, index) => {
+        const line = rawLine.replace(/\s+#.*$/, '').trim();
+        if (!line || line.startsWith('#')) return;
+
+        const [pattern, ...owners] = line.split(/\s+/);
+        rules.push({ pattern, owners, line: index + 1 });
+    });
+    return rules;
+}
+
+/**
+ * Resolves the owners of repository files from a CODEOWNERS file. As on GitHub, the last matching
+ * entry wins, patterns containing a slash are anchored at the repository root, other patterns match
+ * at any depth, and a pattern naming a directory covers everything below it.
+ */
+export class CodeOwners {
+    private rules: Array<CodeOwnersRule & { globs: string[] }>;
+
+    /**
+     * @param rules Entries parsed with parseCodeOwners
+     */
+    constructor(rules: CodeOwnersRule[]) {
+        this.rules = rules.map(rule => ({ ...rule, globs: toGlobs(rule.pattern) }));
+    }
+
+    /**
+     * Loads the CODEOWNERS file of a repository from the first of CODEOWNERS_LOCATIONS that exists.
+     *
+     * @param rootDir Repository root
+     * @returns The owners resolver, or null when the repository has no CODEOWNERS file
+     */
+    static async load(rootDir: string): Promise<CodeOwners | null> {
+        for (const location of CODEOWNERS_LOCATIONS) {
+            try {
+                const content = await fs.promises.readFile(path.join(rootDir, location), 'utf8');
+                return new CodeOwners(parseCodeOwners(content));
+            } catch {
+                // Try the next location
+            }
+        }
+        return null;
+    }
+
+    /**
+     * Returns the owners of a file.
+     *
+     * @param filePath Path relative to the repository root, with forward slashes
+     * @returns Owners from the last matching entry, or an empty array when the file has no owner
+     */
+    public ownersOf(filePath: string): string[] {
+        for (let index = this.rules.length - 1; index >= 0; index--) {
+            const rule = this.rules[index];
+            if (rule.globs.some(glob => minimatch(filePath, glob, { dot: true }))) {
+                return rule.owners;
+            }
+        }
+        return [];
+    }
+}
+
+function toGlobs(pattern: string): string[] {
+    const directoryOnly = pattern.endsWith('/');
+    const trimmed = pattern.replace(/^\//, '').replace(/\/$/, '');
+    // A slash at the start or in the middle anchors the pattern at the root
+    const anchored = pattern.startsWith('/') || trimmed.includes('/');
+    const base = anchored ? trimmed : `**/${trimmed}`;
+
+    if (directoryOnly) return [`${base}/**`];
+    // `docs/*` matches files directly in docs/ but not in its subdirectories
+    if (trimmed.endsWith('*')) return [base];
+    return [base, `${base}/**`];
+}
@@ -0,0 +1,78 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+import { normalizeFingerprintPath } from './fingerprint';
+import { Rule } from './ruleEngine';
+
+/** Whether code is test code or production code */
+export type CodeScope = 'test' | 'production';
+
+/** Attribute set to 'test' on findings in test code; production findings are left untagged */
+export const SCOPE_ATTRIBUTE = 'scope';
+
+/** Directory names that hold test code in most ecosystems (Maven's src/test included) */
+const TEST_DIRECTORY_PATTERN = /(^|\/)(tests?|__tests__|__mocks__|specs?|testdata|fixtures)\//i;
+
+/** Test file naming conventions per language */
+const TEST_FILE_PATTERNS: Record<string, RegExp[]> = {
+    go: [/_test\.go$/],
+    javascript: [/\.(test|spec)\.[cm]?jsx?$/],
+    typescript: [/\.(test|spec)\.[cm]?tsx?$/],
+    python: [/^test_.*\.py$/, /_test\.py$/, /^conftest\.py$/],
+    java: [/Tests?\.java$/, /IT\.java$/],
+    kotlin: [/Tests?\.kt$/],
+    scala: [/(Test|Spec|Suite)\.scala$/],
+    csharp: [/Tests?\.cs$/],
+    ruby: [/_spec\.rb$/, /_test\.rb$/, /^test_.*\.rb$/],
+    php: [/Test\.php$/],
+    c: [/^test_.*\.c$/, /_test\.c$/],
+    cpp: [/_test\.(cc|cpp|cxx)$/, /^test_.*\.(cc|cpp|cxx)$/],
+    bash: [/\.bats$/, /_test\.sh$/],
+};
+
+/**
+ * Classifies a file as test or production code from its path, using directory conventions
+ * (`tests/`, `spec/`, `__tests__/`, `testdata/`, ...) and the language's test file naming
+ * conventions (`_test.go`, `*.spec.ts`, `test_*.py`, `*Test.java`, ...).
+ *
+ * @param filePath Path of the file
+ * @param language Language identifier of the file (e.g., 'go')
+ * @returns 'test' for test code, 'production' otherwise
+ */
+export function classifyCodeScope(filePath: string, language: string): CodeScope {
+    const relativePath = normalizeFingerprintPath(filePath);
+    if (TEST_DIRECTORY_PATTERN.test(relativePath)) {
+        return 'test';
+    }
+
+    const fileName = path.basename(relativePath);
+    const patterns = TEST_FILE_PATTERNS[language] || Object.values(TEST_FILE_PATTERNS).flat();
+    return patterns.some(pattern => pattern.test(fileName)) ? 'test' : 'production';
+}
+
+/**
+ * Restricts a rule to findings in files of the given scope, so policies can differ between test and
+ * production code (e.g., allowing http://localhost in tests only).
+ *
+ * @param rule The rule to restrict
+ * @param scope The scope the rule applies to
+ * @returns A rule with the same id that only evaluates findings in the given scope
+ */
+export function forScope(rule: Rule, scope: CodeScope): Rule {
+    return {
+        ...rule,
+        evaluate: (finding, file) => ((file.scope ?? 'production') === scope ? rule.evaluate(finding, file) : []),
+    };
+}
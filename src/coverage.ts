@@ -0,0 +1,79 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+
+/** Extension reported for files without one, which are counted by file name instead */
+const NO_EXTENSION = '(none)';
+
+/**
+ * Files of one extension that no parser exists for.
+ */
+export interface ExtensionCoverage {
+    /** Lowercase extension including the dot (e.g., '.vue'), or the file name for files without one */
+    extension: string;
+    /** Language detected for the extension ('unknown' when no language is configured) */
+    language: string;
+    /** Number of files */
+    files: number;
+}
+
+/**
+ * How much of the scanned tree was parsed with a tree-sitter grammar.
+ */
+export interface LanguageCoverage {
+    /** Number of files scanned */
+    scannedFiles: number;
+    /** Number of files parsed with a grammar */
+    parsedFiles: number;
+    /** Whether files without a parser were scanned with regex detection; if not, they were skipped */
+    fallbackRegex: boolean;
+    /** Files without a parser, sorted */
+    unparsedFiles: string[];
+    /** Files without a parser by extension, most files first */
+    extensions: ExtensionCoverage[];
+}
+
+/**
+ * Summarizes the files that no parser exists for, by extension, so grammars can be prioritized.
+ *
+ * @param scannedFiles Number of files scanned
+ * @param unparsed Language detected for each file without a parser, by file
+ * @param fallbackRegex Whether those files were scanned with regex detection
+ * @returns The coverage
+ */
+export function buildLanguageCoverage(
+    scannedFiles: number,
+    unparsed: Map<string, string>,
+    fallbackRegex: boolean,
+): LanguageCoverage {
+    const extensions = new Map<string, ExtensionCoverage>();
+    for (const [file, language] of unparsed) {
+        const basename = path.basename(file);
+        const extension = path.extname(basename).toLowerCase() || basename || NO_EXTENSION;
+        const entry = extensions.get(extension) || { extension, language, files: 0 };
+        entry.files++;
+        extensions.set(extension, entry);
+    }
+
+    return {
+        scannedFiles,
+        parsedFiles: Math.max(0, scannedFiles - unparsed.size),
+        fallbackRegex,
+        unparsedFiles: Array.from(unparsed.keys()).sort(),
+        extensions: Array.from(extensions.values()).sort(
+            (a, b) => b.files - a.files || a.extension.localeCompare(b.extension),
+        ),
+    };
+}
@@ -0,0 +1,238 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import { writeAtomically } from './atomicWrite';
+import { DEFAULT_HTTP_CLIENT, HttpClient } from './httpClient';
+import { CacheDirectory } from './cacheDir';
+import { toAsciiHost } from './idn';
+
+/** Name of the imported data bundle in the cache directory */
+export const DATA_BUNDLE_CACHE_ENTRY = 'data.json';
+
+/** IANA list of top-level domains */
+export const DEFAULT_TLD_SOURCE = 'https://data.iana.org/TLD/tlds-alpha-by-domain.txt';
+
+/** Attribute holding the feeds a finding's host is listed in, separated by spaces */
+export const FEED_ATTRIBUTE = 'feed';
+
+/** Name of the feed listing URL shortener hosts */
+export const SHORTENER_FEED = 'shortener';
+
+/** Version of the bundle file layout */
+const BUNDLE_FORMAT = 1;
+
+/**
+ * Reference data packaged for offline use.
+ */
+export interface DataBundleContent {
+    /** ISO timestamp of the pull */
+    createdAt: string;
+    /** Where each list was pulled from ('tlds' and the feed names) */
+    sources: Record<string, string>;
+    /** Top-level domains, lowercase */
+    tlds: string[];
+    /** Host feeds by name (e.g., 'shortener'); a listed domain covers its subdomains */
+    feeds: Record<string, string[]>;
+}
+
+/**
+ * A signed bundle as written by `data pull` and read by `data import`.
+ */
+export interface DataBundle {
+    format: number;
+    content: DataBundleContent;
+    /** Base64 signature of the JSON-serialized content */
+    signature: string;
+}
+
+/**
+ * Parses a plain-text list with one entry per line. Blank lines and '#' comments are skipped,
+ * and entries are lowercased.
+ *
+ * @param text Content of the list
+ * @returns Entries in list order
+ */
+export function parseList(text: string): string[] {
+    return text
+        .split('\n')
+        .map(line => line.replace(/#.*$/, '').trim().toLowerCase())
+        .filter(line => line.length > 0);
+}
+
+/**
+ * Reads a list from an http(s) URL or a local file.
+ *
+ * @param source URL or file path
+ * @param client Network stack to download with (default: the global fetch)
+ * @returns Entries of the list
+ * @throws {Error} When the source cannot be read
+ */
+export async function readListSource(source: string, client: HttpClient = DEFAULT_HTTP_CLIENT): Promise<string[]> {
+    if (!/^https?:\/\//i.test(source)) {
+        return parseList(await fs.promises.readFile(source, 'utf8'));
+    }
+
+    const response = await client.fetch(source);
+    if (!response.ok) {
+        throw new Error(`Failed to download ${source}: HTTP ${response.status}`);
+    }
+    return parseList(await response.text());
+}
+
+/**
+ * Downloads the TLD list and host feeds.
+ *
+ * @param tldSource URL or file of the TLD list
+ * @param feedSources URL or file of each feed, by feed name
+ * @param client Network stack to download with (default: the global fetch)
+ * @returns Bundle content, ready to be signed
+ */
+export async function pullDataBundle(
+    tldSource: string,
+    feedSources: Record<string, string> = {},
+    client: HttpClient = DEFAULT_HTTP_CLIENT,
+): Promise<DataBundleContent> {
+    const feeds: Record<string, string[]> = {};
+    for (const [name, source] of Object.entries(feedSources)) {
+        feeds[name] = await readListSource(source, client);
+    }
+
+    return {
+        createdAt: new Date().toISOString(),
+        sources: { tlds: tldSource, ...feedSources },
+        tlds: await readListSource(tldSource, client),
+        feeds,
+    };
+}
+
+/**
+ * Signs bundle content with a private key. Ed25519 keys are recommended
+ * (`openssl genpkey -algorithm ed25519`).
+ *
+ * @param content Bundle content
+ * @param privateKey PEM-encoded private key
+ * @returns The signed bundle
+ */
+export function signDataBundle(content: DataBundleContent, privateKey: string): DataBundle {
+    const signature = crypto.sign(null, Buffer.from(JSON.stringify(content)), privateKey);
+    return { format: BUNDLE_FORMAT, content, signature: signature.toString('base64') };
+}
+
+/**
+ * Verifies the signature of a bundle.
+ *
+ * @param bundle The bundle to verify
+ * @param publicKey PEM-encoded public key of the signer
+ * @returns The verified content
+ * @throws {Error} When the bundle is malformed or the signature does not match
+ */
+export function verifyDataBundle(bundle: DataBundle, publicKey: string): DataBundleContent {
+    if (!bundle || bundle.format !== BUNDLE_FORMAT || !bundle.content || typeof bundle.signature !== 'string') {
+        throw new Error(`Unsupported data bundle; expected format ${BUNDLE_FORMAT}`);
+    }
+
+    const data = Buffer.from(JSON.stringify(bundle.content));
+    if (!crypto.verify(null, data, publicKey, Buffer.from(bundle.signature, 'base64'))) {
+        throw new Error('Data bundle signature does not match the public key');
+    }
+    return bundle.content;
+}
+
+/**
+ * Verifies a bundle file and installs it where the detector loads it from.
+ *
+ * @param bundleFile Bundle written by `data pull`
+ * @param publicKey PEM-encoded public key of the signer
+ * @param target Where to install the bundle (default: DATA_BUNDLE_CACHE_ENTRY in the cache directory)
+ * @param cache Cache directory used without a target (default: the resolved cache directory)
+ * @returns The imported content
+ * @throws {Error} When the bundle cannot be read or fails verification
+ */
+export async function importDataBundle(
+    bundleFile: string,
+    publicKey: string,
+    target?: string,
+    cache: CacheDirectory = new CacheDirectory(),
+): Promise<DataBundleContent> {
+    const text = await fs.promises.readFile(bundleFile, 'utf8');
+    const content = verifyDataBundle(JSON.parse(text), publicKey);
+
+    if (!target) {
+        // Parallel imports on one runner are serialized by the cache lock
+        await cache.writeFile(DATA_BUNDLE_CACHE_ENTRY, text);
+        return content;
+    }
+
+    await writeAtomically(target, text);
+    return content;
+}
+
+/**
+ * Lookups against the lists of an imported bundle.
+ */
+export class HostData {
+    private tlds: Set<string>;
+    private feeds: Array<[string, Set<string>]>;
+
+    /**
+     * @param content Bundle content
+     */
+    constructor(content: DataBundleContent) {
+        this.tlds = new Set(content.tlds);
+        this.feeds = Object.entries(content.feeds).map(([name, hosts]) => [name, new Set(hosts.map(toAsciiHost))]);
+    }
+
+    /**
+     * Loads an imported bundle. The signature was checked on import, so it is not verified again.
+     *
+     * @param filePath Path of the imported bundle
+     * @returns The host data
+     * @throws {Error} When the file is not a data bundle
+     */
+    public static async load(filePath: string): Promise<HostData> {
+        const bundle = JSON.parse(await fs.promises.readFile(filePath, 'utf8')) as DataBundle;
+        if (!bundle || !bundle.content || !Array.isArray(bundle.content.tlds)) {
+            throw new Error(`Invalid data bundle ${filePath}: missing TLD list`);
+        }
+        return new HostData(bundle.content);
+    }
+
+    /**
+     * The known top-level domains.
+     */
+    public get knownTlds(): Set<string> {
+        return this.tlds;
+    }
+
+    /**
+     * Returns the feeds a host is listed in, directly or through a parent domain. Hosts are compared in
+     * punycode, so feeds may list internationalized hosts in either form.
+     *
+     * @param host Hostname, lowercase
+     * @returns Feed names in bundle order
+     */
+    public feedsOf(host: string): string[] {
+        const candidates: string[] = [];
+        const labels = toAsciiHost(host).split('.');
+        for (let i = 0; i < labels.length - 1; i++) {
+            candidates.push(labels.slice(i).join('.'));
+        }
+
+        return this.feeds
+            .filter(([, hosts]) => candidates.some(candidate => hosts.has(candidate)))
+            .map(([name]) => name);
+    }
+}
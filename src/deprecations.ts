@@ -0,0 +1,168 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { REALTIME_RPC_CATEGORY } from './realtimeEndpoints';
+import { FileContext, Rule, Violation } from './ruleEngine';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Id of the rule flagging URLs on deprecated endpoints */
+export const DEPRECATED_ENDPOINT_RULE = 'deprecated-endpoint';
+
+/** Attribute holding the suggested replacement of a deprecated URL */
+export const REPLACEMENT_ATTRIBUTE = 'replacement';
+
+/** Attribute holding the sunset date of a deprecated URL's endpoint */
+export const SUNSET_ATTRIBUTE = 'sunset';
+
+/**
+ * An endpoint listed in the deprecation registry.
+ */
+export interface DeprecatedEndpoint {
+    /** URL prefix covering the endpoint (e.g., 'https://api.example.com/v1/') */
+    prefix: string;
+    /** URL prefix replacing it, so the rest of the URL carries over (e.g., 'https://api.example.com/v2/') */
+    replacement?: string;
+    /** Date (YYYY-MM-DD) after which the endpoint is gone */
+    sunset?: string;
+    /** Free-form migration note, such as a link to the migration guide */
+    note?: string;
+}
+
+/**
+ * Where a deprecated URL should move.
+ */
+export interface DeprecationMatch {
+    endpoint: DeprecatedEndpoint;
+    /** The URL with the deprecated prefix swapped for the replacement, or null without one */
+    replacement: string | null;
+}
+
+/**
+ * Matches URLs against a registry of deprecated endpoint prefixes.
+ */
+export class DeprecationRegistry {
+    private endpoints: DeprecatedEndpoint[];
+
+    /**
+     * @param endpoints Deprecated endpoints; the longest matching prefix wins
+     * @throws {Error} When an entry has no prefix or an invalid sunset date
+     */
+    constructor(endpoints: DeprecatedEndpoint[]) {
+        for (const endpoint of endpoints) {
+            if (!endpoint.prefix) {
+                throw new Error('Deprecated endpoints must have a prefix');
+            }
+            if (endpoint.sunset !== undefined && !isValidDate(endpoint.sunset)) {
+                throw new Error(`Invalid sunset date "${endpoint.sunset}" for ${endpoint.prefix}; expected YYYY-MM-DD`);
+            }
+        }
+        this.endpoints = endpoints
+            .map(endpoint => ({ ...endpoint, prefix: normalizeOrigin(endpoint.prefix) }))
+            .sort((a, b) => b.prefix.length - a.prefix.length);
+    }
+
+    /**
+     * Loads a registry file: a JSON object mapping URL prefixes to their replacement, sunset date,
+     * and note, or an array of entries with a prefix each.
+     *
+     * @param file Path of the registry
+     * @returns The registry
+     * @throws {Error} When the file cannot be read or parsed, or holds an invalid entry
+     */
+    public static async load(file: string): Promise<DeprecationRegistry> {
+        const text = await fs.promises.readFile(file, 'utf8');
+        let document: unknown;
+        try {
+            document = JSON.parse(text);
+        } catch (error: any) {
+            throw new Error(`Failed to parse deprecation registry ${file}: ${error.message}`);
+        }
+        if (Array.isArray(document)) {
+            return new DeprecationRegistry(document as DeprecatedEndpoint[]);
+        }
+        if (!document || typeof document !== 'object') {
+            throw new Error(`Deprecation registry ${file} must be an object or an array`);
+        }
+        return new DeprecationRegistry(
+            Object.entries(document as Record<string, Omit<DeprecatedEndpoint, 'prefix'>>).map(([prefix, entry]) => ({
+                ...entry,
+                prefix,
+            })),
+        );
+    }
+
+    /**
+     * Finds the deprecated endpoint a URL calls. A prefix matches whole path segments, so
+     * 'https://api.example.com/v1' covers '/v1/users' and '/v1?q=1' but not '/v10'.
+     *
+     * @param url An absolute URL
+     * @returns The endpoint and suggested replacement, or null when the URL is not deprecated
+     */
+    public match(url: string): DeprecationMatch | null {
+        const normalized = normalizeOrigin(url);
+        const endpoint = this.endpoints.find(({ prefix }) => {
+            if (!normalized.startsWith(prefix)) return false;
+            const next = normalized.charAt(prefix.length);
+            return /[/?#]$/.test(prefix) || next === '' || '/?#'.includes(next);
+        });
+        if (!endpoint) return null;
+
+        const rest = normalized.substring(endpoint.prefix.length);
+        return { endpoint, replacement: endpoint.replacement ? endpoint.replacement + rest : null };
+    }
+}
+
+/**
+ * Creates the rule flagging URLs on deprecated endpoints: a warning until the sunset date, an error
+ * from then on. The suggested replacement and the sunset date are recorded in the 'replacement'
+ * and 'sunset' attributes.
+ *
+ * @param registry The deprecated endpoints
+ * @param now Date the sunset dates are compared with (default: today)
+ * @returns The deprecation rule
+ */
+export function createDeprecationRule(registry: DeprecationRegistry, now: Date = new Date()): Rule {
+    const today = now.toISOString().substring(0, 10);
+
+    return {
+        id: DEPRECATED_ENDPOINT_RULE,
+        description: 'URLs should not call deprecated endpoints',
+        evaluate: (finding: URLMatch, _file: FileContext): Violation[] => {
+            if (finding.category && finding.category !== REALTIME_RPC_CATEGORY) return [];
+
+            const match = registry.match(finding.url);
+            if (!match) return [];
+
+            const { sunset, note } = match.endpoint;
+            if (match.replacement) setFindingAttribute(finding, REPLACEMENT_ATTRIBUTE, match.replacement);
+            if (sunset) setFindingAttribute(finding, SUNSET_ATTRIBUTE, sunset);
+
+            const sunsetPassed = sunset !== undefined && sunset <= today;
+            let message = `${finding.url} is deprecated`;
+            if (sunset) message += sunsetPassed ? ` and was sunset on ${sunset}` : ` and will be sunset on ${sunset}`;
+            if (match.replacement) message += `; use ${match.replacement}`;
+            if (note) message += ` (${note})`;
+            return [{ rule: DEPRECATED_ENDPOINT_RULE, severity: sunsetPassed ? 'error' : 'warning', message }];
+        },
+    };
+}
+
+function normalizeOrigin(url: string): string {
+    return url.replace(/^[a-z][a-z0-9+.-]*:\/\/[^/?#]*/i, origin => origin.toLowerCase());
+}
+
+function isValidDate(date: string): boolean {
+    return /^\d{4}-\d{2}-\d{2}$/.test(date) && !isNaN(Date.parse(date));
+}
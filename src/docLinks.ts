@@ -0,0 +1,274 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import { Violation } from './ruleEngine';
+import { URLMatch } from './urlFilter';
+
+/** Category assigned to relative links and fragments found in documentation */
+export const DOC_LINK_CATEGORY = 'doc-link';
+
+const MARKDOWN_EXTENSIONS = ['.md', '.markdown', '.mdx'];
+const HTML_EXTENSIONS = ['.html', '.htm'];
+
+// Link patterns capture the text before the target as group 1 and the target as group 2
+
+/** Inline links and images: [text](target "title") and ![alt](target) */
+const MARKDOWN_INLINE_LINK = /(!?\[[^\]\n]*\]\(\s*<?)([^)\s>]+)>?(?:\s+["'(][^)\n]*)?\)/g;
+
+/** Reference definitions: [label]: target "title" */
+const MARKDOWN_REFERENCE_LINK = /^([ \t]{0,3}\[[^\]\n]+\]:[ \t]*<?)([^\s>]+)/gm;
+
+/** href and src attributes in HTML, including HTML embedded in Markdown */
+const HTML_LINK_ATTRIBUTE = /(\b(?:href|src)\s*=\s*["'])([^"'\n]+)["']/gi;
+
+/** Explicit anchors: id and name attributes */
+const HTML_ANCHOR_ATTRIBUTE = /\b(?:id|name)\s*=\s*["']([^"'\n]+)["']/gi;
+
+/**
+ * Determines whether a file is a Markdown or HTML document whose links can be validated.
+ *
+ * @param filePath Path of the file
+ * @returns True for Markdown and HTML files
+ */
+export function isDocumentationFile(filePath: string): boolean {
+    const extension = path.extname(filePath).toLowerCase();
+    return MARKDOWN_EXTENSIONS.includes(extension) || HTML_EXTENSIONS.includes(extension);
+}
+
+/**
+ * Determines whether a file is a Markdown document.
+ *
+ * @param filePath Path of the file
+ * @returns True for .md, .markdown, and .mdx files
+ */
+export function isMarkdownFile(filePath: string): boolean {
+    return MARKDOWN_EXTENSIONS.includes(path.extname(filePath).toLowerCase());
+}
+
+/**
+ * Extracts intra-repository links from a Markdown or HTML document: relative paths and fragment-only
+ * links. Absolute URLs are left to the regular detection, and links inside Markdown code are skipped.
+ *
+ * @param content Content of the document
+ * @param filePath Path of the document, used to tell Markdown from HTML
+ * @returns Findings in the 'doc-link' category, in source order
+ */
+export function extractDocLinks(content: string, filePath: string): URLMatch[] {
+    const isMarkdown = isMarkdownFile(filePath);
+    const text = isMarkdown ? blankMarkdownCode(content) : content;
+    const patterns = isMarkdown
+        ? [MARKDOWN_INLINE_LINK, MARKDOWN_REFERENCE_LINK, HTML_LINK_ATTRIBUTE]
+        : [HTML_LINK_ATTRIBUTE];
+
+    const findings: URLMatch[] = [];
+    const seen = new Set<number>();
+    for (const pattern of patterns) {
+        for (const match of text.matchAll(pattern)) {
+            const target = match[2];
+            const start = match.index! + match[1].length;
+            if (!isRelativeLink(target) || seen.has(start)) continue;
+
+            seen.add(start);
+            findings.push(createFinding(content, target, start));
+        }
+    }
+
+    return findings.sort((a, b) => a.start - b.start);
+}
+
+/**
+ * Converts a heading to the anchor GitHub generates for it: lowercased, punctuation removed,
+ * and spaces replaced with hyphens.
+ *
+ * @param heading Heading text
+ * @returns The anchor slug
+ */
+export function slugifyHeading(heading: string): string {
+    return heading
+        .trim()
+        .toLowerCase()
+        .replace(/<[^>]+>/g, '')
+        .replace(/[^\p{L}\p{N}\s_-]/gu, '')
+        .replace(/\s/g, '-');
+}
+
+/**
+ * Collects the anchors a document defines: heading slugs for Markdown (with GitHub's -1, -2 suffixes
+ * for duplicates) and explicit id and name attributes for both Markdown and HTML.
+ *
+ * @param content Content of the document
+ * @param filePath Path of the document, used to tell Markdown from HTML
+ * @returns The set of anchors, without the leading '#'
+ */
+export function collectAnchors(content: string, filePath: string): Set<string> {
+    const anchors = new Set<string>();
+
+    if (isMarkdownFile(filePath)) {
+        const lines = blankMarkdownCode(content).split('\n');
+        const slugCounts = new Map<string, number>();
+        lines.forEach((line, index) => {
+            const atx = line.match(/^[ \t]{0,3}#{1,6}[ \t]+(.+?)[ \t#]*$/);
+            const setext = !atx && line.trim() && /^[ \t]{0,3}(=+|-+)[ \t]*$/.test(lines[index + 1] || '');
+            const heading = atx ? atx[1] : setext ? line : null;
+            if (heading === null) return;
+
+            const slug = slugifyHeading(heading);
+            const count = slugCounts.get(slug) || 0;
+            slugCounts.set(slug, count + 1);
+            anchors.add(count === 0 ? slug : `${slug}-${count}`);
+        });
+    }
+
+    for (const match of content.matchAll(HTML_ANCHOR_ATTRIBUTE)) {
+        anchors.add(match[1]);
+    }
+
+    return anchors;
+}
+
+/**
+ * Validates documentation links against the file system: the target file must exist, and a fragment
+ * must name an anchor in the target document. Anchors are only checked in Markdown and HTML targets,
+ * since fragments into other files (e.g., line anchors in source files) are interpreted by the viewer.
+ */
+export class DocLinkValidator {
+    private rootDir: string;
+    private anchorCache = new Map<string, Promise<Set<string> | null>>();
+
+    /**
+     * @param rootDir Repository root that links starting with '/' resolve against (default: working directory)
+     */
+    constructor(rootDir: string = process.cwd()) {
+        this.rootDir = rootDir;
+    }
+
+    /**
+     * Forgets the anchors read from target documents, so later validations see their current content.
+     */
+    public clearCache(): void {
+        this.anchorCache.clear();
+    }
+
+    /**
+     * Validates links found in a document and attaches 'broken-link' and 'broken-anchor' violations.
+     *
+     * @param findings Links extracted with extractDocLinks
+     * @param filePath Path of the document containing the links
+     */
+    public async validate(findings: URLMatch[], filePath: string): Promise<void> {
+        for (const finding of findings) {
+            const violation = await this.check(finding.url, filePath);
+            if (violation) {
+                finding.violations = [...(finding.violations || []), violation];
+            }
+        }
+    }
+
+    private async check(link: string, filePath: string): Promise<Violation | null> {
+        const hashIndex = link.indexOf('#');
+        const linkPath = (hashIndex === -1 ? link : link.substring(0, hashIndex)).split('?')[0];
+        const fragment = hashIndex === -1 ? '' : link.substring(hashIndex + 1);
+
+        let targetPath = path.resolve(filePath);
+        if (linkPath) {
+            const decoded = safeDecode(linkPath);
+            targetPath = decoded.startsWith('/')
+                ? path.join(this.rootDir, decoded)
+                : path.resolve(path.dirname(filePath), decoded);
+
+            const stat = await fs.promises.stat(targetPath).catch(() => null);
+            if (!stat) {
+                return { rule: 'broken-link', severity: 'error', message: `Link target ${linkPath} does not exist` };
+            }
+            if (stat.isDirectory()) return null;
+        }
+
+        if (!fragment || !isDocumentationFile(targetPath)) return null;
+
+        const anchors = await this.getAnchors(targetPath);
+        if (anchors && !anchors.has(safeDecode(fragment)) && !anchors.has(fragment.toLowerCase())) {
+            const target = linkPath || path.basename(filePath);
+            return { rule: 'broken-anchor', severity: 'error', message: `Anchor #${fragment} not found in ${target}` };
+        }
+        return null;
+    }
+
+    private getAnchors(targetPath: string): Promise<Set<string> | null> {
+        let anchors = this.anchorCache.get(targetPath);
+        if (!anchors) {
+            anchors = fs.promises
+                .readFile(targetPath, 'utf8')
+                .then(content => collectAnchors(content, targetPath))
+                .catch(() => null);
+            this.anchorCache.set(targetPath, anchors);
+        }
+        return anchors;
+    }
+}
+
+function isRelativeLink(target: string): boolean {
+    // Absolute URLs (any scheme, including mailto: and data:) and protocol-relative URLs are not repository links
+    return !/^[a-zA-Z][a-zA-Z0-9+.-]*:/.test(target) && !target.startsWith('//') && target !== '#';
+}
+
+/**
+ * Replaces fenced code blocks and inline code spans with spaces so their contents are not treated as
+ * links or headings, while keeping offsets intact.
+ *
+ * @param content Content of a Markdown document
+ * @returns The content with its code blanked out
+ */
+export function blankMarkdownCode(content: string): string {
+    const blank = (code: string) => ' '.repeat(code.length);
+    let fence: string | null = null;
+
+    return content
+        .split('\n')
+        .map(line => {
+            const marker = line.match(/^[ \t]*(```|~~~)/);
+            if (fence) {
+                if (marker && marker[1] === fence) fence = null;
+                return blank(line);
+            }
+            if (marker) {
+                fence = marker[1];
+                return blank(line);
+            }
+            return line.replace(/`[^`]+`/g, blank);
+        })
+        .join('\n');
+}
+
+function createFinding(content: string, target: string, start: number): URLMatch {
+    const lines = content.substring(0, start).split('\n');
+    return {
+        url: target,
+        start,
+        end: start + target.length,
+        line: lines.length,
+        column: lines[lines.length - 1].length + 1,
+        sourceType: 'unknown',
+        category: DOC_LINK_CATEGORY,
+    };
+}
+
+function safeDecode(value: string): string {
+    try {
+        return decodeURIComponent(value);
+    } catch {
+        return value;
+    }
+}
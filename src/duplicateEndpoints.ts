@@ -0,0 +1,123 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+import { BUILT_IN_CATEGORIES } from './categoryRules';
+import { normalizeFingerprintPath } from './fingerprint';
+import { FileResult } from './urlFilter';
+
+/** Default number of files an endpoint must appear in to be reported */
+export const DEFAULT_DUPLICATE_MIN_FILES = 3;
+
+/** Extensions of files that typically hold configuration */
+const CONFIG_EXTENSIONS = ['.json', '.yaml', '.yml', '.toml', '.ini', '.properties', '.conf', '.cfg'];
+
+/** File names that typically hold shared constants or settings (including .env files) */
+const CONFIG_NAME_PATTERN = /(^|[._-])(config|configuration|settings|constants?|endpoints|urls|env)([._-]|$)/i;
+
+/**
+ * A URL hard-coded in several files, with hints on where to consolidate it.
+ */
+export interface DuplicateEndpoint {
+    /** Normalized URL */
+    url: string;
+    /** Number of occurrences */
+    count: number;
+    /** Files containing the URL */
+    files: string[];
+    /** Occurrences (file:line) in configuration or constants files that could become the single definition */
+    candidates: string[];
+    /** Deepest directory shared by all files, where a new shared constant could live */
+    commonDirectory: string;
+}
+
+/**
+ * Normalizes a URL so trivially different spellings of the same endpoint compare equal: the scheme
+ * and host are lowercased, default ports, fragments, and trailing slashes are removed, and
+ * protocol-relative URLs are treated as https.
+ *
+ * @param url The URL
+ * @returns The normalized URL, or null when it cannot be parsed
+ */
+export function normalizeEndpoint(url: string): string | null {
+    let parsed: URL;
+    try {
+        parsed = new URL(url.startsWith('//') ? `https:${url}` : url);
+    } catch {
+        return null;
+    }
+    if (!parsed.host) return null;
+
+    const pathname = parsed.pathname.replace(/\/+$/, '');
+    return `${parsed.protocol}//${parsed.host}${pathname}${parsed.search}`;
+}
+
+/**
+ * Finds URLs hard-coded in many files and suggests where to consolidate them: existing occurrences
+ * in configuration or constants files, and the deepest directory shared by every file.
+ *
+ * @param results Scan results
+ * @param minFiles Minimum number of files an endpoint must appear in (default: 3)
+ * @returns Duplicated endpoints sorted by descending file count, then occurrence count, then URL
+ */
+export function findDuplicateEndpoints(
+    results: FileResult[],
+    minFiles: number = DEFAULT_DUPLICATE_MIN_FILES,
+): DuplicateEndpoint[] {
+    const occurrences = new Map<string, Array<{ file: string; line: number }>>();
+    for (const result of results) {
+        for (const urlObj of result.urls) {
+            if (urlObj.category && BUILT_IN_CATEGORIES.includes(urlObj.category)) continue;
+
+            const url = normalizeEndpoint(urlObj.url);
+            if (!url) continue;
+
+            const entries = occurrences.get(url) || [];
+            entries.push({ file: result.file, line: urlObj.line });
+            occurrences.set(url, entries);
+        }
+    }
+
+    const duplicates: DuplicateEndpoint[] = [];
+    for (const [url, entries] of occurrences) {
+        const files = Array.from(new Set(entries.map(entry => entry.file))).sort();
+        if (files.length < minFiles) continue;
+
+        duplicates.push({
+            url,
+            count: entries.length,
+            files,
+            candidates: entries.filter(entry => isConfigFile(entry.file)).map(entry => `${entry.file}:${entry.line}`),
+            commonDirectory: commonDirectory(files),
+        });
+    }
+
+    return duplicates.sort(
+        (a, b) => b.files.length - a.files.length || b.count - a.count || a.url.localeCompare(b.url),
+    );
+}
+
+function isConfigFile(file: string): boolean {
+    const basename = path.basename(file);
+    return CONFIG_EXTENSIONS.includes(path.extname(basename).toLowerCase()) || CONFIG_NAME_PATTERN.test(basename);
+}
+
+function commonDirectory(files: string[]): string {
+    const directories = files.map(file => path.posix.dirname(normalizeFingerprintPath(file)).split('/'));
+    const shared: string[] = [];
+    for (let i = 0; directories.every(parts => i < parts.length && parts[i] === directories[0][i]); i++) {
+        shared.push(directories[0][i]);
+    }
+    return shared.length === 0 || (shared.length === 1 && shared[0] === '.') ? '.' : shared.join('/');
+}
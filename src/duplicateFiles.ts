@@ -0,0 +1,71 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as path from 'path';
+import { normalizeFingerprintPath } from './fingerprint';
+import { FileResult, setFindingAttribute } from './urlFilter';
+
+/** Attribute listing the other paths with identical content whose findings were collapsed, separated by spaces */
+export const DUPLICATES_ATTRIBUTE = 'duplicates';
+
+/**
+ * Hashes file content to recognize identical copies.
+ *
+ * @param content Content of the file
+ * @returns SHA-256 hex digest
+ */
+export function contentHash(content: string | Buffer): string {
+    return crypto.createHash('sha256').update(content).digest('hex');
+}
+
+/**
+ * Collapses the results of files with identical content, such as vendored copies of a dependency or
+ * git submodules checked out twice, into the result of one of them. Files only count as copies when
+ * they also share an extension, since the same text can be parsed differently as another language.
+ * The kept copy is the one with the fewest path segments, then the first in path order, and each of its
+ * findings lists the other paths in the duplicates attribute. Results without a known hash, such as
+ * git metadata and segmented files, are kept as they are.
+ *
+ * @param results Scan results
+ * @param hashes Content hash by file path, as in the results
+ * @returns The results in their original order, without the collapsed copies
+ */
+export function collapseDuplicateFiles(results: FileResult[], hashes: Map<string, string>): FileResult[] {
+    const groups = new Map<string, FileResult[]>();
+    for (const result of results) {
+        const hash = hashes.get(result.file);
+        if (!hash) continue;
+        const key = `${hash}\0${path.extname(result.file).toLowerCase()}`;
+        const group = groups.get(key);
+        if (group) group.push(result);
+        else groups.set(key, [result]);
+    }
+
+    const collapsed = new Set<FileResult>();
+    for (const group of groups.values()) {
+        if (group.length < 2) continue;
+
+        const [kept, ...copies] = group
+            .map(result => ({ result, file: normalizeFingerprintPath(result.file) }))
+            .sort((a, b) => a.file.split('/').length - b.file.split('/').length || a.file.localeCompare(b.file));
+        const paths = copies.map(copy => copy.file);
+        for (const urlObj of kept.result.urls) {
+            setFindingAttribute(urlObj, DUPLICATES_ATTRIBUTE, paths.join(' '));
+        }
+        copies.forEach(copy => collapsed.add(copy.result));
+    }
+
+    return results.filter(result => !collapsed.has(result));
+}
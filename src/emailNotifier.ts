@@ -0,0 +1,361 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as net from 'net';
+import * as os from 'os';
+import * as tls from 'tls';
+import { Report, TOOL_NAME, serializeReport } from './report';
+import { formatIncreaseAlert } from './increaseAlert';
+import { SEVERITIES, getFindingSeverity } from './ruleEngine';
+
+/** Environment variable holding the SMTP password when it is not part of the SMTP URL */
+export const SMTP_PASSWORD_ENV = 'URL_DETECTOR_SMTP_PASSWORD';
+
+/** Number of hosts listed in the summary */
+const TOP_HOSTS = 10;
+
+/**
+ * Where and how to email scan summaries.
+ *
+ * @example
+ * ```json
+ * { "smtp": "smtp://reports@mail.example.com:587", "from": "reports@example.com", "to": ["team@example.com"] }
+ * ```
+ */
+export interface EmailConfig {
+    /** SMTP server as smtp://[user@]host[:port] (STARTTLS, default port 587) or smtps:// (TLS, default port 465) */
+    smtp: string;
+    /** Sender address */
+    from: string;
+    /** Recipient addresses */
+    to: string[];
+    /** Subject line (default: finding and file counts) */
+    subject?: string;
+    /** Whether to attach the full JSON report (default: false) */
+    attachReport?: boolean;
+}
+
+/**
+ * A file attached to an email.
+ */
+export interface EmailAttachment {
+    filename: string;
+    contentType: string;
+    content: string;
+}
+
+/**
+ * An email ready to be sent.
+ */
+export interface EmailMessage {
+    from: string;
+    to: string[];
+    subject: string;
+    text: string;
+    attachments?: EmailAttachment[];
+}
+
+/**
+ * Summarizes a report as plain text: a triggered increase alert, totals, findings per highest
+ * violation severity, and the hosts with the most findings.
+ *
+ * @param report The report to summarize
+ * @returns The summary
+ */
+export function formatEmailSummary(report: Report): string {
+    const bySeverity = new Map<string, number>();
+    const byHost = new Map<string, number>();
+    for (const result of report.files) {
+        for (const urlObj of result.urls) {
+            const severity = getFindingSeverity(urlObj);
+            if (severity) bySeverity.set(severity, (bySeverity.get(severity) || 0) + 1);
+
+            const host = hostOf(urlObj.url);
+            if (host) byHost.set(host, (byHost.get(host) || 0) + 1);
+        }
+    }
+
+    const lines = [
+        `${TOOL_NAME} scan summary`,
+        '',
+        `Files with findings: ${report.files.filter(result => result.urls.length > 0).length}`,
+        `Findings: ${report.summary.totalUrls} (${report.summary.uniqueUrls} unique)`,
+    ];
+    const alert = report.sections && report.sections.increaseAlert;
+    if (alert && alert.triggered) {
+        lines.splice(1, 0, '', `ALERT: ${formatIncreaseAlert(alert)}`);
+    }
+    for (const severity of [...SEVERITIES].reverse()) {
+        if (bySeverity.has(severity)) lines.push(`  ${severity}: ${bySeverity.get(severity)}`);
+    }
+
+    const hosts = Array.from(byHost.entries())
+        .sort((a, b) => b[1] - a[1] || a[0].localeCompare(b[0]))
+        .slice(0, TOP_HOSTS);
+    if (hosts.length > 0) {
+        lines.push('', 'Top hosts:');
+        hosts.forEach(([host, count]) => lines.push(`  ${host}: ${count}`));
+    }
+    return lines.join('\n');
+}
+
+/**
+ * Builds the summary email for a report. The subject starts with 'ALERT:' when the increase alert triggered.
+ *
+ * @param report The report to send
+ * @param config Email configuration
+ * @returns The message
+ */
+export function createReportEmail(report: Report, config: EmailConfig): EmailMessage {
+    const alert = report.sections && report.sections.increaseAlert;
+    const subject =
+        (alert && alert.triggered ? 'ALERT: ' : '') +
+        (config.subject || `${TOOL_NAME}: ${report.summary.totalUrls} finding(s) in ${report.files.length} file(s)`);
+    const message: EmailMessage = { from: config.from, to: config.to, subject, text: formatEmailSummary(report) };
+    if (config.attachReport) {
+        message.attachments = [
+            {
+                filename: `${TOOL_NAME}-report.json`,
+                contentType: 'application/json',
+                content: serializeReport(report, 'json'),
+            },
+        ];
+    }
+    return message;
+}
+
+/**
+ * Renders a message in MIME format with CRLF line endings. Bodies are base64-encoded so any
+ * content survives SMTP transport.
+ *
+ * @param message The message
+ * @param date Date header value (default: now)
+ * @returns The raw message
+ */
+export function buildMimeMessage(message: EmailMessage, date: Date = new Date()): string {
+    const headers = [
+        `From: ${message.from}`,
+        `To: ${message.to.join(', ')}`,
+        `Subject: ${encodeHeader(message.subject)}`,
+        `Date: ${date.toUTCString()}`,
+        `Message-ID: <${crypto.randomUUID()}@${TOOL_NAME}>`,
+        'MIME-Version: 1.0',
+    ];
+    const textPart = [
+        'Content-Type: text/plain; charset=utf-8',
+        'Content-Transfer-Encoding: base64',
+        '',
+        base64(message.text),
+    ];
+
+    if (!message.attachments || message.attachments.length === 0) {
+        return [...headers, ...textPart].join('\r\n');
+    }
+
+    const boundary = `${TOOL_NAME}-${crypto.randomBytes(12).toString('hex')}`;
+    const lines = [...headers, `Content-Type: multipart/mixed; boundary="${boundary}"`, ''];
+    lines.push(`--${boundary}`, ...textPart);
+    for (const attachment of message.attachments) {
+        lines.push(
+            `--${boundary}`,
+            `Content-Type: ${attachment.contentType}; name="${attachment.filename}"`,
+            `Content-Disposition: attachment; filename="${attachment.filename}"`,
+            'Content-Transfer-Encoding: base64',
+            '',
+            base64(attachment.content),
+        );
+    }
+    lines.push(`--${boundary}--`);
+    return lines.join('\r\n');
+}
+
+/**
+ * Sends a message through an SMTP server. Plain smtp:// connections are upgraded with STARTTLS when
+ * the server offers it; credentials are never sent over an unencrypted connection.
+ *
+ * @param smtpUrl SMTP server as smtp://[user[:password]@]host[:port] or smtps://...; the password
+ * may instead be given in the URL_DETECTOR_SMTP_PASSWORD environment variable
+ * @param message The message
+ * @param timeoutMs Socket inactivity timeout in milliseconds (default: 30000)
+ * @throws {Error} When the server rejects a command or the connection fails
+ */
+export async function sendEmail(smtpUrl: string, message: EmailMessage, timeoutMs: number = 30000): Promise<void> {
+    const url = new URL(smtpUrl);
+    if (url.protocol !== 'smtp:' && url.protocol !== 'smtps:') {
+        throw new Error(`Unsupported SMTP protocol ${url.protocol}; expected smtp:// or smtps://`);
+    }
+    const implicitTls = url.protocol === 'smtps:';
+    const host = url.hostname;
+    const port = url.port ? parseInt(url.port, 10) : implicitTls ? 465 : 587;
+    const username = decodeURIComponent(url.username);
+    const password = decodeURIComponent(url.password) || process.env[SMTP_PASSWORD_ENV] || '';
+
+    const connection = new SmtpConnection(
+        implicitTls ? tls.connect({ host, port, servername: host }) : net.connect({ host, port }),
+        timeoutMs,
+    );
+    try {
+        await connection.expect(220);
+        let capabilities = await connection.command(`EHLO ${os.hostname()}`, 250);
+        let secure = implicitTls;
+
+        if (!secure && /^STARTTLS$/im.test(capabilities)) {
+            await connection.command('STARTTLS', 220);
+            await connection.upgrade(host);
+            capabilities = await connection.command(`EHLO ${os.hostname()}`, 250);
+            secure = true;
+        }
+
+        if (username) {
+            if (!secure) {
+                throw new Error(`SMTP server ${host} does not support TLS; refusing to send credentials`);
+            }
+            if (!/^AUTH\b.*\bPLAIN\b/im.test(capabilities)) {
+                throw new Error(`SMTP server ${host} does not support AUTH PLAIN`);
+            }
+            const credentials = Buffer.from(`\0${username}\0${password}`).toString('base64');
+            await connection.command(`AUTH PLAIN ${credentials}`, 235);
+        }
+
+        await connection.command(`MAIL FROM:<${message.from}>`, 250);
+        for (const recipient of message.to) {
+            await connection.command(`RCPT TO:<${recipient}>`, 250, 251);
+        }
+        await connection.command('DATA', 354);
+        // Lines starting with a dot are escaped so they cannot end the message early
+        const data = buildMimeMessage(message).replace(/^\./gm, '..');
+        await connection.command(`${data}\r\n.`, 250);
+        await connection.command('QUIT', 221);
+    } finally {
+        connection.close();
+    }
+}
+
+/**
+ * Line-oriented SMTP conversation over a socket that can be upgraded to TLS.
+ */
+class SmtpConnection {
+    private socket: net.Socket;
+    private timeoutMs: number;
+    private buffer = '';
+    private failure: Error | null = null;
+    private notify: (() => void) | null = null;
+
+    constructor(socket: net.Socket, timeoutMs: number) {
+        this.timeoutMs = timeoutMs;
+        this.socket = socket;
+        this.attach(socket);
+    }
+
+    /**
+     * Sends a command and waits for a reply with one of the expected codes.
+     *
+     * @returns The reply text
+     */
+    public async command(line: string, ...expected: number[]): Promise<string> {
+        this.socket.write(`${line}\r\n`);
+        return this.expect(...expected);
+    }
+
+    /**
+     * Waits for a reply with one of the expected codes.
+     *
+     * @returns The reply text
+     */
+    public async expect(...expected: number[]): Promise<string> {
+        const lines: string[] = [];
+        let line: string;
+        do {
+            line = await this.readLine();
+            lines.push(line.substring(4));
+        } while (line.charAt(3) === '-');
+
+        const code = parseInt(line.substring(0, 3), 10);
+        if (!expected.includes(code)) {
+            throw new Error(`SMTP server replied ${code}: ${lines.join(' ')}`);
+        }
+        return lines.join('\n');
+    }
+
+    /**
+     * Switches the connection to TLS after a successful STARTTLS.
+     */
+    public async upgrade(servername: string): Promise<void> {
+        this.socket.removeAllListeners('data');
+        const secureSocket = tls.connect({ socket: this.socket, servername });
+        await new Promise<void>((resolve, reject) => {
+            secureSocket.once('secureConnect', resolve);
+            secureSocket.once('error', reject);
+        });
+        this.socket = secureSocket;
+        this.attach(secureSocket);
+    }
+
+    public close(): void {
+        this.socket.destroy();
+    }
+
+    private attach(socket: net.Socket): void {
+        socket.setTimeout(this.timeoutMs, () => socket.destroy(new Error('SMTP connection timed out')));
+        socket.on('data', chunk => {
+            this.buffer += chunk.toString('utf8');
+            this.wake();
+        });
+        socket.on('error', error => {
+            this.failure = error;
+            this.wake();
+        });
+        socket.on('close', () => {
+            this.failure = this.failure || new Error('SMTP connection closed');
+            this.wake();
+        });
+    }
+
+    private wake(): void {
+        const notify = this.notify;
+        this.notify = null;
+        if (notify) notify();
+    }
+
+    private async readLine(): Promise<string> {
+        let newline = this.buffer.indexOf('\n');
+        while (newline < 0) {
+            if (this.failure) throw this.failure;
+            await new Promise<void>(resolve => (this.notify = resolve));
+            newline = this.buffer.indexOf('\n');
+        }
+
+        const line = this.buffer.substring(0, newline).replace(/\r$/, '');
+        this.buffer = this.buffer.substring(newline + 1);
+        return line;
+    }
+}
+
+function hostOf(url: string): string {
+    try {
+        return new URL(url.startsWith('//') ? `https:${url}` : url).hostname.toLowerCase();
+    } catch {
+        return '';
+    }
+}
+
+function encodeHeader(value: string): string {
+    // eslint-disable-next-line no-control-regex
+    return /^[\x20-\x7e]*$/.test(value) ? value : `=?UTF-8?B?${Buffer.from(value).toString('base64')}?=`;
+}
+
+function base64(content: string): string {
+    return (Buffer.from(content, 'utf8').toString('base64').match(/.{1,76}/g) || []).join('\r\n');
+}
@@ -0,0 +1,119 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { FileContext, Rule, Severity, Violation } from './ruleEngine';
+import { URLMatch } from './urlFilter';
+
+/** Id of the rule flagging suspicious percent-encoding in URLs */
+export const ENCODING_ANOMALY_RULE = 'encoding-anomaly';
+
+/**
+ * Kinds of suspicious percent-encoding:
+ * - double-encoding: an encoded '%' followed by hex digits (e.g., %2520), read as a different character
+ *   by components that decode twice
+ * - overlong-encoding: a UTF-8 sequence longer than needed for its character (e.g., %c0%ae for '.'),
+ *   used to slip characters past filters
+ * - crlf: an encoded carriage return or line feed (e.g., %0d%0a), used to inject headers or split
+ *   requests and responses
+ */
+export type EncodingAnomalyKind = 'double-encoding' | 'overlong-encoding' | 'crlf';
+
+/**
+ * A suspicious sequence found in a URL.
+ */
+export interface EncodingAnomaly {
+    kind: EncodingAnomalyKind;
+    /** The sequence as written in the URL */
+    sequence: string;
+    /** Offset of the sequence in the URL */
+    index: number;
+}
+
+const ANOMALY_PATTERNS: Array<[EncodingAnomalyKind, RegExp]> = [
+    // A line break, encoded once or more
+    ['crlf', /%(?:25)*0[ad](?:%(?:25)*0[ad])*/gi],
+    // Two-byte sequences for ASCII, three-byte sequences below U+0800, and four-byte sequences below U+10000
+    [
+        'overlong-encoding',
+        /%c[01]%[89ab][0-9a-f]|%e0%[89][0-9a-f]%[89ab][0-9a-f]|%f0%8[0-9a-f](?:%[89ab][0-9a-f]){2}/gi,
+    ],
+    ['double-encoding', /%25(?:25)*[0-9a-f]{2}/gi],
+];
+
+const SEVERITY_BY_KIND: Record<EncodingAnomalyKind, Severity> = {
+    crlf: 'error',
+    'overlong-encoding': 'error',
+    'double-encoding': 'warning',
+};
+
+/**
+ * Finds suspicious percent-encoding in a URL. A double-encoded line break is reported as crlf only.
+ *
+ * @param url The URL to inspect
+ * @returns The anomalies in order of position
+ */
+export function findEncodingAnomalies(url: string): EncodingAnomaly[] {
+    const anomalies: EncodingAnomaly[] = [];
+    for (const [kind, pattern] of ANOMALY_PATTERNS) {
+        for (const match of url.matchAll(pattern)) {
+            const index = match.index!;
+            const overlaps = anomalies.some(
+                anomaly => index < anomaly.index + anomaly.sequence.length && anomaly.index < index + match[0].length,
+            );
+            if (!overlaps) anomalies.push({ kind, sequence: match[0], index });
+        }
+    }
+    return anomalies.sort((a, b) => a.index - b.index);
+}
+
+/**
+ * Creates the rule flagging URLs with double-encoding, overlong UTF-8 encodings, or encoded line
+ * breaks, which are associated with request smuggling, header injection, and open redirects. Each kind
+ * found in a URL raises one violation naming its first occurrence: 'error' for overlong encodings and
+ * line breaks, which have no legitimate use in a URL, and 'warning' for double-encoding, which URLs
+ * nested in query parameters can need.
+ *
+ * @returns The encoding anomaly rule
+ */
+export function createEncodingAnomalyRule(): Rule {
+    return {
+        id: ENCODING_ANOMALY_RULE,
+        description: 'URLs must not contain double-encoding, overlong UTF-8 encodings, or encoded line breaks',
+        evaluate: (finding: URLMatch, _file: FileContext): Violation[] => {
+            const violations: Violation[] = [];
+            const reported = new Set<EncodingAnomalyKind>();
+            for (const anomaly of findEncodingAnomalies(finding.url)) {
+                if (reported.has(anomaly.kind)) continue;
+                reported.add(anomaly.kind);
+                violations.push({
+                    rule: ENCODING_ANOMALY_RULE,
+                    severity: SEVERITY_BY_KIND[anomaly.kind],
+                    message: describeAnomaly(anomaly),
+                });
+            }
+            return violations;
+        },
+    };
+}
+
+function describeAnomaly({ kind, sequence }: EncodingAnomaly): string {
+    switch (kind) {
+        case 'crlf':
+            return `${sequence} encodes a line break, which can inject headers or split requests`;
+        case 'overlong-encoding':
+            return `${sequence} is an overlong UTF-8 encoding, which can slip characters past filters`;
+        case 'double-encoding':
+            return `${sequence} is double-encoded and decodes to ${sequence.replace(/^%25/i, '%')}`;
+    }
+}
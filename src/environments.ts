@@ -0,0 +1,175 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { BUILT_IN_CATEGORIES } from './categoryRules';
+import { FileResult } from './urlFilter';
+
+/** Host name tokens that identify an environment, keyed by the canonical environment name */
+export const DEFAULT_ENVIRONMENT_ALIASES: Record<string, string[]> = {
+    production: ['prod', 'production', 'prd', 'live'],
+    staging: ['staging', 'stage', 'stg', 'preprod'],
+    development: ['dev', 'development', 'develop'],
+    qa: ['qa', 'uat', 'test'],
+};
+
+/**
+ * Options for the environment consistency analysis.
+ */
+export interface EnvironmentAnalysisOptions {
+    /** Environments every service is expected to have (default: every environment seen in the scan) */
+    expectedEnvironments?: string[];
+    /** Host name tokens per environment (default: DEFAULT_ENVIRONMENT_ALIASES) */
+    aliases?: Record<string, string[]>;
+}
+
+/**
+ * A URL referencing one environment of a service.
+ */
+export interface EnvironmentEndpoint {
+    environment: string;
+    url: string;
+    file: string;
+    line: number;
+}
+
+/**
+ * A service whose per-environment endpoints are missing or inconsistent.
+ */
+export interface EnvironmentService {
+    /** Host with the environment tokens removed (e.g., 'switch.go.example.com' for 'prod-switch.go.example.com') */
+    service: string;
+    /** Environments referenced for the service */
+    environments: string[];
+    /** Expected environments that are never referenced */
+    missing: string[];
+    /** Differences between environments (e.g., scheme or port) */
+    inconsistencies: string[];
+    /** Every URL referencing the service */
+    endpoints: EnvironmentEndpoint[];
+}
+
+/**
+ * Groups detected URLs by service, where hosts that differ only in an environment token
+ * (`prod-api.example.com`, `api.staging.example.com`, `api-dev.example.com`) belong to the same service,
+ * and reports services where an expected environment is never referenced or where environments
+ * disagree on scheme or port.
+ *
+ * @param results Scan results
+ * @param options Expected environments and host name tokens
+ * @returns Services with missing or inconsistent environments, sorted by service name
+ */
+export function analyzeEnvironments(
+    results: FileResult[],
+    options: EnvironmentAnalysisOptions = {},
+): EnvironmentService[] {
+    const tokenToEnvironment = new Map<string, string>();
+    for (const [environment, tokens] of Object.entries(options.aliases || DEFAULT_ENVIRONMENT_ALIASES)) {
+        tokens.forEach(token => tokenToEnvironment.set(token.toLowerCase(), environment));
+    }
+
+    const services = new Map<string, EnvironmentEndpoint[]>();
+    for (const result of results) {
+        for (const urlObj of result.urls) {
+            if (urlObj.category && BUILT_IN_CATEGORIES.includes(urlObj.category)) continue;
+
+            const parsed = parseHost(urlObj.url);
+            if (!parsed) continue;
+
+            const split = splitEnvironment(parsed.host, tokenToEnvironment);
+            if (!split) continue;
+
+            const endpoints = services.get(split.service) || [];
+            endpoints.push({ environment: split.environment, url: urlObj.url, file: result.file, line: urlObj.line });
+            services.set(split.service, endpoints);
+        }
+    }
+
+    const seenEnvironments = new Set<string>();
+    services.forEach(endpoints => endpoints.forEach(endpoint => seenEnvironments.add(endpoint.environment)));
+    const expected = options.expectedEnvironments || Array.from(seenEnvironments);
+
+    const reported: EnvironmentService[] = [];
+    for (const [service, endpoints] of services) {
+        const environments = Array.from(new Set(endpoints.map(endpoint => endpoint.environment))).sort();
+        const missing = expected.filter(environment => !environments.includes(environment)).sort();
+        const inconsistencies = findInconsistencies(endpoints);
+
+        if (missing.length > 0 || inconsistencies.length > 0) {
+            reported.push({ service, environments, missing, inconsistencies, endpoints });
+        }
+    }
+
+    return reported.sort((a, b) => a.service.localeCompare(b.service));
+}
+
+function parseHost(url: string): { scheme: string; host: string; port: string } | null {
+    const match = url.match(/^(?:([a-zA-Z][a-zA-Z0-9+.-]*):)?\/\/(?:[^@/?#]*@)?([^/?#:]+)(?::(\d+))?/);
+    if (!match) return null;
+
+    const scheme = (match[1] || '').toLowerCase();
+    const port = match[3] || (scheme === 'https' || scheme === 'wss' ? '443' : scheme ? '80' : '');
+    return { scheme, host: match[2].toLowerCase(), port };
+}
+
+/**
+ * Removes the first environment token from a host. Tokens are whole labels (`api.staging.example.com`)
+ * or hyphen-separated parts of a label (`prod-api.example.com`, `api-dev.example.com`).
+ */
+function splitEnvironment(
+    host: string,
+    tokenToEnvironment: Map<string, string>,
+): { service: string; environment: string } | null {
+    const labels = host.split('.');
+    // The registrable domain (last two labels) never names an environment
+    for (let index = 0; index < labels.length - 2; index++) {
+        const parts = labels[index].split('-');
+        const tokenIndex = parts.findIndex(part => tokenToEnvironment.has(part));
+        if (tokenIndex === -1) continue;
+
+        const environment = tokenToEnvironment.get(parts[tokenIndex])!;
+        parts.splice(tokenIndex, 1);
+        const serviceLabels = [...labels];
+        if (parts.length > 0) {
+            serviceLabels[index] = parts.join('-');
+        } else {
+            serviceLabels.splice(index, 1);
+        }
+        return { service: serviceLabels.join('.'), environment };
+    }
+    return null;
+}
+
+function findInconsistencies(endpoints: EnvironmentEndpoint[]): string[] {
+    const inconsistencies: string[] = [];
+
+    for (const aspect of ['scheme', 'port'] as const) {
+        const environmentsByValue = new Map<string, Set<string>>();
+        for (const endpoint of endpoints) {
+            const value = parseHost(endpoint.url)![aspect];
+            if (!value) continue;
+            const environments = environmentsByValue.get(value) || new Set<string>();
+            environments.add(endpoint.environment);
+            environmentsByValue.set(value, environments);
+        }
+
+        if (environmentsByValue.size > 1) {
+            const details = Array.from(environmentsByValue.entries())
+                .map(([value, environments]) => `${value} (${Array.from(environments).sort().join(', ')})`)
+                .join(' vs ');
+            inconsistencies.push(`${aspect} differs: ${details}`);
+        }
+    }
+
+    return inconsistencies;
+}
@@ -0,0 +1,169 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Category of findings that look like URLs but are not endpoints the code talks to */
+export const INFORMATIONAL_CATEGORY = 'informational';
+
+/** Attribute naming the heuristic that moved a finding to the informational category */
+export const HEURISTIC_ATTRIBUTE = 'heuristic';
+
+/**
+ * Heuristics recognizing URL-like strings in code that are not live endpoints:
+ * - xml-namespace: XML namespace names, such as xmlns attributes, the namespace argument of DOM *NS()
+ *   methods, and {namespace}tag names in ElementTree
+ * - example-domain: hosts reserved for documentation and testing by RFC 2606 and RFC 6761, such as
+ *   example.com and the .test, .example, .invalid, and .localhost top-level domains
+ * - go-import-path: Go import paths, such as //github.com/org/repo without a scheme or the target of
+ *   go get, go install, and importpath
+ */
+export type FalsePositiveHeuristic = 'xml-namespace' | 'example-domain' | 'go-import-path';
+
+/** All false positive heuristics */
+export const FALSE_POSITIVE_HEURISTICS: FalsePositiveHeuristic[] = [
+    'xml-namespace',
+    'example-domain',
+    'go-import-path',
+];
+
+const EXAMPLE_DOMAINS = ['example.com', 'example.net', 'example.org'];
+const RESERVED_TLDS = ['example', 'invalid', 'localhost', 'test'];
+
+// Hosts serving Go modules; forge paths name an owner and a repository
+const GO_VANITY_HOSTS = [
+    'golang.org',
+    'google.golang.org',
+    'gopkg.in',
+    'go.googlesource.com',
+    'go.uber.org',
+    'go.etcd.io',
+    'go.opentelemetry.io',
+    'k8s.io',
+    'sigs.k8s.io',
+    'honnef.co',
+];
+const GO_FORGE_HOSTS = ['github.com', 'gitlab.com', 'bitbucket.org'];
+
+const AUTHORITY_HOST = /^(?:[a-z][a-z0-9+.-]*:)?\/\/(?:[^/?#@]*@)?([^/?#:]+)/i;
+const GO_IMPORT_PATH = /^(?:https?:)?\/\/([a-z0-9.-]+)((?:\/[A-Za-z0-9_.~+-]+)+)\/?$/i;
+
+// Text before the finding on its line that makes it a namespace name
+const XML_NAMESPACE_CONTEXTS = [
+    /\b(?:xmlns(?::[\w.-]+)?|targetNamespace|namespace(?:Uri|URI)?)\s*[=:]\s*["'`]?$/i,
+    /\b\w+NS\s*\(\s*["'`]$/,
+];
+const SCHEMA_LOCATION = /\bschemaLocation\s*=\s*["']([^"']*)$/i;
+const GO_IMPORT_CONTEXT =
+    /(?:\bgo\s+(?:get|install)(?:\s+-\S+)*|\bimportpath\s*=|\bimport(?:\s+[\w.]+)?)\s*["'`(]?\s*$/;
+
+/**
+ * Validates heuristic names, from the config file or command line.
+ *
+ * @param names Heuristic names
+ * @returns The heuristics, without duplicates
+ * @throws {Error} When a name is not a known heuristic
+ */
+export function parseFalsePositiveHeuristics(names: string[]): FalsePositiveHeuristic[] {
+    for (const name of names) {
+        if (!FALSE_POSITIVE_HEURISTICS.includes(name as FalsePositiveHeuristic)) {
+            throw new Error(
+                `Unknown false positive heuristic "${name}"; expected one of ${FALSE_POSITIVE_HEURISTICS.join(', ')}`,
+            );
+        }
+    }
+    return FALSE_POSITIVE_HEURISTICS.filter(heuristic => names.includes(heuristic));
+}
+
+/**
+ * Finds the first enabled heuristic recognizing a finding as a likely false positive.
+ *
+ * @param finding The finding, with start as its offset in content
+ * @param content Content of the file the finding is in
+ * @param heuristics Enabled heuristics
+ * @returns The matching heuristic, or undefined when the finding looks like a live URL
+ */
+export function matchFalsePositive(
+    finding: URLMatch,
+    content: string,
+    heuristics: FalsePositiveHeuristic[],
+): FalsePositiveHeuristic | undefined {
+    const before = content.substring(content.lastIndexOf('\n', finding.start - 1) + 1, finding.start);
+    return heuristics.find(heuristic => {
+        switch (heuristic) {
+            case 'xml-namespace':
+                return isXmlNamespace(finding, content, before);
+            case 'example-domain':
+                return isExampleDomain(finding.url);
+            case 'go-import-path':
+                return isGoImportPath(finding.url, before);
+        }
+    });
+}
+
+/**
+ * Moves a finding recognized by one of the heuristics to the 'informational' category, and records the
+ * heuristic in its 'heuristic' attribute. Findings that already have a category are left alone.
+ *
+ * @param finding The finding, with start as its offset in content
+ * @param content Content of the file the finding is in
+ * @param heuristics Enabled heuristics
+ * @returns Whether the finding was moved
+ */
+export function classifyFalsePositive(
+    finding: URLMatch,
+    content: string,
+    heuristics: FalsePositiveHeuristic[],
+): boolean {
+    if (finding.category) return false;
+
+    const heuristic = matchFalsePositive(finding, content, heuristics);
+    if (!heuristic) return false;
+
+    finding.category = INFORMATIONAL_CATEGORY;
+    setFindingAttribute(finding, HEURISTIC_ATTRIBUTE, heuristic);
+    return true;
+}
+
+function isXmlNamespace(finding: URLMatch, content: string, before: string): boolean {
+    if (XML_NAMESPACE_CONTEXTS.some(context => context.test(before))) return true;
+
+    // ElementTree and lxml write qualified names as {namespace}tag
+    if (content.charAt(finding.start - 1) === '{' && content.charAt(finding.end) === '}') return true;
+
+    // xsi:schemaLocation pairs each namespace with the location of its schema, which is a real URL
+    const schemaLocation = SCHEMA_LOCATION.exec(before);
+    return !!schemaLocation && schemaLocation[1].split(/\s+/).filter(Boolean).length % 2 === 0;
+}
+
+function isExampleDomain(url: string): boolean {
+    const authority = AUTHORITY_HOST.exec(url);
+    if (!authority) return false;
+
+    const host = authority[1].toLowerCase().replace(/\.$/, '');
+    const tld = host.substring(host.lastIndexOf('.') + 1);
+    if (RESERVED_TLDS.includes(tld)) return true;
+    return EXAMPLE_DOMAINS.some(domain => host === domain || host.endsWith(`.${domain}`));
+}
+
+function isGoImportPath(url: string, before: string): boolean {
+    const importPath = GO_IMPORT_PATH.exec(url);
+    if (!importPath) return false;
+
+    const host = importPath[1].toLowerCase();
+    const segments = importPath[2].split('/').length - 1;
+    const moduleHost = GO_VANITY_HOSTS.includes(host) || (GO_FORGE_HOSTS.includes(host) && segments >= 2);
+    // With a scheme, only the target of a Go tool or import is an import path rather than a web page
+    return moduleHost && (url.startsWith('//') || GO_IMPORT_CONTEXT.test(before));
+}
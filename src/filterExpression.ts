@@ -0,0 +1,368 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { SEVERITIES, Severity, getFindingSeverity } from './ruleEngine';
+import { FileResult, URLMatch } from './urlFilter';
+
+/**
+ * A compiled filter: whether a finding in a file is kept.
+ */
+export type FindingPredicate = (finding: URLMatch, file: string) => boolean;
+
+/** Comparison operators of the filter language */
+export type FilterOperator = '==' | '!=' | '<' | '<=' | '>' | '>=' | 'contains' | 'startswith' | 'endswith' | 'matches';
+
+/**
+ * Fields of a finding that filters can test. Other names are read from the finding's attributes
+ * (e.g., owner, scope).
+ */
+export const FILTER_FIELDS = [
+    'url',
+    'scheme',
+    'host',
+    'port',
+    'path',
+    'file',
+    'line',
+    'column',
+    'context',
+    'sourceType',
+    'category',
+    'severity',
+    'rule',
+    'fingerprint',
+];
+
+const NUMERIC_FIELDS = ['port', 'line', 'column'];
+const WORD_OPERATORS = ['contains', 'startswith', 'endswith', 'matches'];
+const DEFAULT_PORTS: Record<string, number> = { ftp: 21, http: 80, https: 443, ws: 80, wss: 443 };
+const SEVERITY_ALIASES: Record<string, Severity> = { warn: 'warning', err: 'error' };
+
+type Token =
+    | { type: 'word'; text: string; position: number }
+    | { type: 'string'; text: string; position: number }
+    | { type: 'number'; text: string; position: number }
+    | { type: 'symbol'; text: string; position: number }
+    | { type: 'end'; text: ''; position: number };
+
+/** Value a field yields for a finding: one value, several (rule), or none */
+type FieldValue = string | number | Array<string | number> | undefined;
+
+/**
+ * Compiles a filter expression, such as
+ * `host endswith ".ru" and context == string and severity >= warn`.
+ *
+ * Comparisons are `field operator value`, combined with `and`, `or`, `not`, and parentheses.
+ * Operators are `==`, `!=`, `<`, `<=`, `>`, `>=`, `contains`, `startswith`, `endswith`, and `matches`
+ * (a regular expression). Values are quoted strings, numbers, or bare words. `severity` is the
+ * finding's highest violation severity, compared in severity order (`none` when it has no
+ * violations); `rule` matches when any violation's rule does, and `!=` when none does.
+ *
+ * @param expression The filter expression
+ * @returns The compiled predicate
+ * @throws {Error} When the expression is malformed, a regular expression is invalid, or a numeric
+ * or severity comparison has a value of the wrong kind
+ */
+export function compileFilter(expression: string): FindingPredicate {
+    return new FilterParser(expression).parse();
+}
+
+/**
+ * Resolves the filter option: the name of a configured filter, or an expression.
+ *
+ * @param filter Name of a filter in `filters`, or a filter expression
+ * @param filters Named filter expressions
+ * @returns The expression
+ */
+export function resolveFilter(filter: string, filters: Record<string, string> = {}): string {
+    return Object.prototype.hasOwnProperty.call(filters, filter) ? filters[filter] : filter;
+}
+
+/**
+ * Keeps only the findings a filter accepts. Files are kept even when none of their findings is, so
+ * file counts are not affected.
+ *
+ * @param results Scan results
+ * @param predicate The compiled filter
+ * @returns New results with the accepted findings
+ */
+export function applyFilter(results: FileResult[], predicate: FindingPredicate): FileResult[] {
+    return results.map(result => ({ ...result, urls: result.urls.filter(finding => predicate(finding, result.file)) }));
+}
+
+class FilterParser {
+    private expression: string;
+    private tokens: Token[];
+    private index = 0;
+
+    constructor(expression: string) {
+        this.expression = expression;
+        this.tokens = this.tokenize();
+    }
+
+    public parse(): FindingPredicate {
+        const predicate = this.parseOr();
+        if (this.peek().type !== 'end') {
+            throw this.error(`unexpected '${this.peek().text}'`);
+        }
+        return predicate;
+    }
+
+    private parseOr(): FindingPredicate {
+        let left = this.parseAnd();
+        while (this.acceptKeyword('or')) {
+            const [a, b] = [left, this.parseAnd()];
+            left = (finding, file) => a(finding, file) || b(finding, file);
+        }
+        return left;
+    }
+
+    private parseAnd(): FindingPredicate {
+        let left = this.parseNot();
+        while (this.acceptKeyword('and')) {
+            const [a, b] = [left, this.parseNot()];
+            left = (finding, file) => a(finding, file) && b(finding, file);
+        }
+        return left;
+    }
+
+    private parseNot(): FindingPredicate {
+        if (this.acceptKeyword('not')) {
+            const inner = this.parseNot();
+            return (finding, file) => !inner(finding, file);
+        }
+        if (this.peek().type === 'symbol' && this.peek().text === '(') {
+            this.index++;
+            const inner = this.parseOr();
+            if (this.peek().text !== ')') throw this.error("expected ')'");
+            this.index++;
+            return inner;
+        }
+        return this.parseComparison();
+    }
+
+    private parseComparison(): FindingPredicate {
+        const fieldToken = this.next();
+        if (fieldToken.type !== 'word' || isKeyword(fieldToken.text)) {
+            throw this.error('expected a field name', fieldToken);
+        }
+        const field = fieldToken.text;
+
+        const operatorToken = this.next();
+        const operator = operatorToken.text.toLowerCase() as FilterOperator;
+        const isOperator =
+            (operatorToken.type === 'symbol' && ['==', '!=', '<', '<=', '>', '>='].includes(operator)) ||
+            (operatorToken.type === 'word' && WORD_OPERATORS.includes(operator));
+        if (!isOperator) throw this.error('expected an operator', operatorToken);
+
+        const valueToken = this.next();
+        if (valueToken.type === 'end' || valueToken.type === 'symbol') {
+            throw this.error('expected a value', valueToken);
+        }
+        const test = this.createTest(field, operator, valueToken);
+        return (finding, file) => {
+            const value = readField(field, finding, file);
+            if (Array.isArray(value)) {
+                return operator === '!=' ? !value.some(item => !test(item)) : value.some(test);
+            }
+            if (value === undefined) return operator === '!=';
+            return test(value);
+        };
+    }
+
+    private createTest(field: string, operator: FilterOperator, token: Token): (value: string | number) => boolean {
+        if (field === 'severity' && ['<', '<=', '>', '>=', '==', '!='].includes(operator)) {
+            const expected = parseSeverity(token.text);
+            if (expected === undefined) throw this.error(`unknown severity '${token.text}'`, token);
+            return value => compareNumbers(severityRank(String(value)), operator, expected);
+        }
+        if (NUMERIC_FIELDS.includes(field) && !WORD_OPERATORS.includes(operator)) {
+            if (token.type !== 'number') throw this.error(`${field} is compared with a number`, token);
+            const expected = parseInt(token.text, 10);
+            return value => compareNumbers(Number(value), operator, expected);
+        }
+
+        const expected = token.text;
+        switch (operator) {
+            case 'contains':
+                return value => String(value).includes(expected);
+            case 'startswith':
+                return value => String(value).startsWith(expected);
+            case 'endswith':
+                return value => String(value).endsWith(expected);
+            case 'matches': {
+                let regex: RegExp;
+                try {
+                    regex = new RegExp(expected);
+                } catch (error: unknown) {
+                    const errorMessage = error instanceof Error ? error.message : String(error);
+                    throw this.error(`invalid regular expression: ${errorMessage}`, token);
+                }
+                return value => regex.test(String(value));
+            }
+            case '==':
+                return value => String(value) === expected;
+            case '!=':
+                return value => String(value) !== expected;
+            default:
+                return value => compareNumbers(String(value).localeCompare(expected), operator, 0);
+        }
+    }
+
+    private tokenize(): Token[] {
+        const tokens: Token[] = [];
+        const text = this.expression;
+        let position = 0;
+        while (position < text.length) {
+            const char = text[position];
+            if (/\s/.test(char)) {
+                position++;
+            } else if (char === '"' || char === "'") {
+                let value = '';
+                let end = position + 1;
+                while (end < text.length && text[end] !== char) {
+                    if (text[end] === '\\' && end + 1 < text.length) end++;
+                    value += text[end++];
+                }
+                if (end >= text.length) {
+                    throw new Error(`Invalid filter "${text}": unterminated string at position ${position + 1}`);
+                }
+                tokens.push({ type: 'string', text: value, position });
+                position = end + 1;
+            } else {
+                const match =
+                    /^(?:[=!<>]=|[<>()])/.exec(text.substring(position)) ||
+                    /^\d+(?![\w.])/.exec(text.substring(position)) ||
+                    /^[^\s()=!<>"']+/.exec(text.substring(position));
+                if (!match) {
+                    throw new Error(`Invalid filter "${text}": unexpected '${char}' at position ${position + 1}`);
+                }
+                const type = /^[=!<>()]/.test(match[0]) ? 'symbol' : /^\d+$/.test(match[0]) ? 'number' : 'word';
+                tokens.push({ type, text: match[0], position });
+                position += match[0].length;
+            }
+        }
+        tokens.push({ type: 'end', text: '', position });
+        return tokens;
+    }
+
+    private peek(): Token {
+        return this.tokens[this.index];
+    }
+
+    private next(): Token {
+        const token = this.tokens[this.index];
+        if (token.type !== 'end') this.index++;
+        return token;
+    }
+
+    private acceptKeyword(keyword: string): boolean {
+        const token = this.peek();
+        if (token.type === 'word' && token.text.toLowerCase() === keyword) {
+            this.index++;
+            return true;
+        }
+        return false;
+    }
+
+    private error(message: string, token: Token = this.peek()): Error {
+        const at = token.type === 'end' ? 'at the end' : `at position ${token.position + 1}`;
+        return new Error(`Invalid filter "${this.expression}": ${message} ${at}`);
+    }
+}
+
+function isKeyword(word: string): boolean {
+    return ['and', 'or', 'not'].includes(word.toLowerCase());
+}
+
+function readField(field: string, finding: URLMatch, file: string): FieldValue {
+    switch (field) {
+        case 'url':
+            return finding.url;
+        case 'file':
+            return file;
+        case 'line':
+            return finding.line;
+        case 'column':
+            return finding.column;
+        case 'context':
+        case 'sourceType':
+            return finding.sourceType;
+        case 'category':
+            return finding.category;
+        case 'fingerprint':
+            return finding.fingerprint;
+        case 'severity':
+            return getFindingSeverity(finding) || 'none';
+        case 'rule':
+            return (finding.violations || []).map(violation => violation.rule);
+        case 'scheme':
+        case 'host':
+        case 'port':
+        case 'path':
+            return readUrlPart(field, finding.url);
+        default:
+            return finding.attributes ? finding.attributes[field] : undefined;
+    }
+}
+
+function readUrlPart(part: 'scheme' | 'host' | 'port' | 'path', url: string): FieldValue {
+    let parsed: URL;
+    try {
+        parsed = new URL(url.startsWith('//') ? `https:${url}` : url);
+    } catch {
+        return undefined;
+    }
+    const scheme = url.startsWith('//') ? undefined : parsed.protocol.replace(/:$/, '');
+    switch (part) {
+        case 'scheme':
+            return scheme;
+        case 'host':
+            return parsed.hostname.toLowerCase() || undefined;
+        case 'port':
+            return parsed.port ? parseInt(parsed.port, 10) : scheme ? DEFAULT_PORTS[scheme] : undefined;
+        case 'path':
+            return parsed.pathname;
+    }
+}
+
+function parseSeverity(text: string): number | undefined {
+    const name = text.toLowerCase();
+    if (name === 'none') return -1;
+    const severity = SEVERITY_ALIASES[name] || (name as Severity);
+    return SEVERITIES.includes(severity) ? SEVERITIES.indexOf(severity) : undefined;
+}
+
+function severityRank(severity: string): number {
+    return severity === 'none' ? -1 : SEVERITIES.indexOf(severity as Severity);
+}
+
+function compareNumbers(actual: number, operator: FilterOperator, expected: number): boolean {
+    switch (operator) {
+        case '==':
+            return actual === expected;
+        case '!=':
+            return actual !== expected;
+        case '<':
+            return actual < expected;
+        case '<=':
+            return actual <= expected;
+        case '>':
+            return actual > expected;
+        case '>=':
+            return actual >= expected;
+        default:
+            return false;
+    }
+}
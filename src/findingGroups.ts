@@ -0,0 +1,68 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { FileResult } from './urlFilter';
+
+/** Group value for findings that do not carry the grouping attribute */
+export const UNGROUPED = '(none)';
+
+/** Grouping key that groups findings by their category instead of an attribute */
+export const CATEGORY_GROUPING = 'category';
+
+/**
+ * Findings sharing one value of an attribute (e.g., all findings owned by one team).
+ */
+export interface FindingGroup {
+    /** Attribute value, or '(none)' for findings without the attribute */
+    value: string;
+    /** Files containing at least one finding of the group */
+    files: string[];
+    /** Number of findings */
+    urlCount: number;
+    /** Number of violations raised against the findings */
+    violationCount: number;
+}
+
+/**
+ * Groups findings by the value of an attribute, so remediation work can be split by owner,
+ * scope, or any other attribute set by classifiers and plugins. Grouping by 'category' uses the
+ * finding category, including categories from user-defined category rules.
+ *
+ * @param results Scan results
+ * @param attribute Name of the attribute to group by (e.g., 'owner'), or 'category'
+ * @returns Groups sorted by descending finding count, then by value
+ */
+export function groupFindings(results: FileResult[], attribute: string): FindingGroup[] {
+    const groups = new Map<string, FindingGroup>();
+
+    for (const result of results) {
+        for (const urlObj of result.urls) {
+            const value =
+                (attribute === CATEGORY_GROUPING
+                    ? urlObj.category
+                    : urlObj.attributes && urlObj.attributes[attribute]) || UNGROUPED;
+            let group = groups.get(value);
+            if (!group) {
+                group = { value, files: [], urlCount: 0, violationCount: 0 };
+                groups.set(value, group);
+            }
+
+            if (!group.files.includes(result.file)) group.files.push(result.file);
+            group.urlCount++;
+            group.violationCount += (urlObj.violations || []).length;
+        }
+    }
+
+    return Array.from(groups.values()).sort((a, b) => b.urlCount - a.urlCount || a.value.localeCompare(b.value));
+}
@@ -0,0 +1,144 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as path from 'path';
+import { URLMatch } from './urlFilter';
+
+/** Identifier of the fingerprint algorithm, bumped whenever the hash inputs change */
+export const FINGERPRINT_VERSION = 'v1';
+
+const DEFAULT_PORTS: Record<string, string> = { 'http:': '80', 'https:': '443', 'ws:': '80', 'wss:': '443' };
+
+/**
+ * Normalizes a URL for comparison: lowercases the scheme and host and drops default ports.
+ * The path, query, and fragment are kept as-is since they are case-sensitive.
+ *
+ * @param url The URL to normalize
+ * @returns The normalized URL, or the trimmed input if it cannot be parsed
+ */
+export function normalizeUrl(url: string): string {
+    const trimmed = url.trim();
+    const match = trimmed.match(/^([a-zA-Z][a-zA-Z0-9+.-]*:)?\/\/([^/?#]*)(.*)$/);
+    if (!match) return trimmed;
+
+    const scheme = (match[1] || '').toLowerCase();
+    let authority = match[2].toLowerCase();
+    const portMatch = authority.match(/:(\d+)$/);
+    if (portMatch && DEFAULT_PORTS[scheme] === portMatch[1]) {
+        authority = authority.slice(0, -portMatch[0].length);
+    }
+
+    return `${scheme}//${authority}${match[3]}`;
+}
+
+/**
+ * Normalizes a file path for fingerprinting: relative to the working directory, with forward slashes,
+ * so the same checkout in different locations produces the same fingerprints.
+ *
+ * @param filePath Absolute or relative file path
+ * @returns The normalized path
+ */
+export function normalizeFingerprintPath(filePath: string): string {
+    const relative = path.isAbsolute(filePath) ? path.relative(process.cwd(), filePath) : filePath;
+    return relative.replace(/\\/g, '/');
+}
+
+/**
+ * Collects the content a fingerprint hashes besides the URL: the line containing a finding and the
+ * nearest non-blank line above and below it. Blank lines are skipped so that inserting or removing
+ * them next to a finding keeps its fingerprint.
+ *
+ * @param lines Lines of the file, without line breaks
+ * @param index Zero-based index of the line containing the finding
+ * @returns The context lines joined with line breaks
+ */
+export function fingerprintContext(lines: string[], index: number): string {
+    let above = index - 1;
+    while (above >= 0 && lines[above].trim() === '') above--;
+    let below = index + 1;
+    while (below < lines.length && lines[below].trim() === '') below++;
+
+    return [lines[above], lines[index], lines[below]].map(line => line || '').join('\n');
+}
+
+/**
+ * Computes a stable fingerprint for a finding.
+ *
+ * The fingerprint hashes the normalized URL, the normalized file path, where the URL was found
+ * (string or comment), and the whitespace-collapsed content of the line it appears on and its
+ * non-blank neighbours (see fingerprintContext()). Line and column numbers are deliberately excluded
+ * so fingerprints survive code being moved up or down, while the neighbouring lines tell apart
+ * identical lines in different places. The occurrence index disambiguates identical URLs that still
+ * share all of these within one file.
+ *
+ * @param finding The finding to fingerprint
+ * @param filePath Path of the file the finding was detected in
+ * @param context Line containing the finding, or its context from fingerprintContext()
+ * @param occurrence Zero-based index among findings in the file with identical hash inputs (default: 0)
+ * @returns Hex-encoded fingerprint
+ */
+export function computeFingerprint(
+    finding: URLMatch,
+    filePath: string,
+    context: string,
+    occurrence: number = 0,
+): string {
+    const parts = [
+        FINGERPRINT_VERSION,
+        normalizeUrl(finding.url),
+        normalizeFingerprintPath(filePath),
+        finding.sourceType,
+        ...context.split('\n').map(line => line.trim().replace(/\s+/g, ' ')),
+        String(occurrence),
+    ];
+    return crypto.createHash('sha256').update(parts.join('\0')).digest('hex').slice(0, 32);
+}
+
+/**
+ * Assigns a fingerprint to every finding detected in a file.
+ *
+ * @param findings Findings detected in the file, in source order
+ * @param filePath Path of the file the findings were detected in
+ * @param sourceCode Full content of the file
+ * @returns The same findings with their `fingerprint` set
+ */
+export function assignFingerprints(findings: URLMatch[], filePath: string, sourceCode: string): URLMatch[] {
+    const sourceLines = sourceCode.split('\n');
+    const contexts = findings.map(finding => fingerprintContext(sourceLines, finding.line - 1));
+    return assignLineFingerprints(findings, filePath, contexts);
+}
+
+/**
+ * Assigns a fingerprint to every finding detected in a file whose content is not held in memory.
+ *
+ * @param findings Findings detected in the file, in source order
+ * @param filePath Path of the file the findings were detected in
+ * @param contexts Context of each finding from fingerprintContext(), or only its line when the
+ * neighbouring lines are not available, by index
+ * @returns The same findings with their `fingerprint` set
+ */
+export function assignLineFingerprints(findings: URLMatch[], filePath: string, contexts: string[]): URLMatch[] {
+    const occurrences = new Map<string, number>();
+
+    findings.forEach((finding, index) => {
+        const context = contexts[index];
+        const base = computeFingerprint(finding, filePath, context);
+        const occurrence = occurrences.get(base) || 0;
+        occurrences.set(base, occurrence + 1);
+        finding.fingerprint = occurrence === 0 ? base : computeFingerprint(finding, filePath, context, occurrence);
+    });
+
+    return findings;
+}
@@ -0,0 +1,81 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+
+/** Attribute set to 'true' on findings in generated files */
+export const GENERATED_ATTRIBUTE = 'generated';
+
+/** File name patterns produced by code generators and minifiers */
+const GENERATED_FILE_PATTERNS = [
+    /[._]pb\.go$/,
+    /[._]pb2(_grpc)?\.py$/,
+    /\.pb\.(cc|h)$/,
+    /_grpc\.pb\.go$/,
+    /\.g\.(cs|dart)$/,
+    /\.designer\.cs$/i,
+    /\.generated\.\w+$/,
+    /\.min\.(js|css|mjs)$/,
+    /-bundle\.min\.\w+$/,
+];
+
+/**
+ * Header markers written by generators. The Go convention (https://go.dev/s/generatedcode) is a line
+ * matching `^// Code generated .* DO NOT EDIT\.$`; other ecosystems use similar wording or `@generated`.
+ */
+const GENERATED_HEADER_PATTERNS = [
+    /^\s*(\/\/|#|\/\*|\*|--|<!--)\s*Code generated .* DO NOT EDIT\.?/m,
+    /@generated\b/,
+    /\bauto-?generated\b.*\bdo not (edit|modify)\b/i,
+];
+
+/** Only the start of a file is inspected for generator headers */
+const HEADER_SCAN_LENGTH = 2048;
+
+/** Average line length above which a file is considered minified */
+const MINIFIED_AVERAGE_LINE_LENGTH = 500;
+
+/**
+ * Determines whether a file was produced by a code generator or minifier.
+ *
+ * A file is considered generated when its name matches a known generator output pattern
+ * (e.g., `*_pb.go`, `*.min.js`), when its header contains a generator marker such as
+ * `// Code generated ... DO NOT EDIT.`, or when its lines are long enough to indicate minification.
+ *
+ * @param filePath Path of the file
+ * @param content Content of the file
+ * @returns True if the file is generated
+ */
+export function isGeneratedFile(filePath: string, content: string): boolean {
+    const fileName = path.basename(filePath);
+    if (GENERATED_FILE_PATTERNS.some(pattern => pattern.test(fileName))) {
+        return true;
+    }
+
+    const header = content.substring(0, HEADER_SCAN_LENGTH);
+    if (GENERATED_HEADER_PATTERNS.some(pattern => pattern.test(header))) {
+        return true;
+    }
+
+    return isMinified(content);
+}
+
+function isMinified(content: string): boolean {
+    if (content.length < MINIFIED_AVERAGE_LINE_LENGTH) {
+        return false;
+    }
+
+    const lineCount = content.split('\n').length;
+    return content.length / lineCount > MINIFIED_AVERAGE_LINE_LENGTH;
+}
@@ -0,0 +1,118 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+
+/** Extensions of prose files, which have no comment syntax and keep plain regex detection */
+export const PROSE_EXTENSIONS = ['.md', '.markdown', '.txt', '.rst', '.adoc', '.log', '.csv'];
+
+/**
+ * A string literal or comment found by tokenize().
+ */
+export interface Token {
+    kind: 'string' | 'comment';
+    /** Offset of the opening quote or comment marker */
+    start: number;
+    /** Offset after the closing quote or comment marker, or the end of the line or file */
+    end: number;
+}
+
+// Characters after which a comment marker starts a comment; anything else may be part of a URL
+// (https://host, /#/route, /path/*)
+const COMMENT_BOUNDARY = /[\s;,(){}[\]]/;
+
+// A quote right after a letter or digit is an apostrophe (don't, it's)
+const WORD_CHARACTER = /[\p{L}\p{N}]/u;
+
+/**
+ * Finds string literals and comments in source code of an unknown language by the syntaxes most
+ * languages share: '...', "...", and `...` strings with backslash escapes, `//` and `#` line comments,
+ * and `/* ... *\/` block comments. Comment markers count at the start of a line or after whitespace or
+ * punctuation only, so the `//` of a URL and its `#` fragment do not start comments. Single and double
+ * quoted strings end at the end of the line; backtick strings and block comments may span lines.
+ *
+ * @param source Source code
+ * @returns Strings and comments in source order
+ */
+export function tokenize(source: string): Token[] {
+    const tokens: Token[] = [];
+    let index = 0;
+
+    while (index < source.length) {
+        const char = source[index];
+        const previous = index > 0 ? source[index - 1] : '\n';
+
+        if (COMMENT_BOUNDARY.test(previous) && (char === '#' || source.startsWith('//', index))) {
+            const end = lineEnd(source, index);
+            tokens.push({ kind: 'comment', start: index, end });
+            index = end;
+        } else if (COMMENT_BOUNDARY.test(previous) && source.startsWith('/*', index)) {
+            const close = source.indexOf('*/', index + 2);
+            const end = close === -1 ? source.length : close + 2;
+            tokens.push({ kind: 'comment', start: index, end });
+            index = end;
+        } else if (char === '"' || char === '`' || (char === "'" && !WORD_CHARACTER.test(previous))) {
+            const end = stringEnd(source, index);
+            tokens.push({ kind: 'string', start: index, end });
+            index = end;
+        } else {
+            index++;
+        }
+    }
+
+    return tokens;
+}
+
+/**
+ * Where an offset lies among the tokens of a file.
+ *
+ * @param tokens Tokens from tokenize(), in source order
+ * @param offset Offset in the source
+ * @returns 'string' or 'comment' inside a token, otherwise 'unknown'
+ */
+export function tokenKindAt(tokens: Token[], offset: number): Token['kind'] | 'unknown' {
+    let low = 0;
+    let high = tokens.length - 1;
+    while (low <= high) {
+        const middle = (low + high) >> 1;
+        const token = tokens[middle];
+        if (offset < token.start) high = middle - 1;
+        else if (offset >= token.end) low = middle + 1;
+        else return token.kind;
+    }
+    return 'unknown';
+}
+
+/**
+ * Whether a file is prose, such as Markdown or plain text, where '#' and quotes carry no syntax.
+ */
+export function isProseFile(filePath: string): boolean {
+    return PROSE_EXTENSIONS.includes(path.extname(filePath).toLowerCase());
+}
+
+function lineEnd(source: string, index: number): number {
+    const newline = source.indexOf('\n', index);
+    return newline === -1 ? source.length : newline;
+}
+
+function stringEnd(source: string, start: number): number {
+    const quote = source[start];
+    for (let index = start + 1; index < source.length; index++) {
+        const char = source[index];
+        if (char === '\\') index++;
+        else if (char === quote) return index + 1;
+        else if (char === '\n' && quote !== '`') return index;
+    }
+    return source.length;
+}
@@ -0,0 +1,113 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+import { runGit } from './gitMetadata';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Attribute holding the date (YYYY-MM-DD) the line of a finding was introduced */
+export const INTRODUCED_ATTRIBUTE = 'introduced';
+
+/** Attribute holding the commit that introduced the line of a finding */
+export const INTRODUCED_COMMIT_ATTRIBUTE = 'introducedIn';
+
+/** Commit id git blame reports for lines that are not committed yet */
+const UNCOMMITTED = /^0{40}$/;
+
+/**
+ * The commit that last changed a line.
+ */
+export interface BlameLine {
+    /** Commit SHA */
+    commit: string;
+    /** Author time of the commit */
+    date: Date;
+}
+
+/**
+ * Parses `git blame --porcelain` output.
+ *
+ * @param output Output of git blame --porcelain
+ * @returns Committed lines keyed by line number (1-indexed); uncommitted lines are omitted
+ */
+export function parseBlamePorcelain(output: string): Map<number, BlameLine> {
+    const authorTimes = new Map<string, number>();
+    const lineCommits = new Map<number, string>();
+    let commit = '';
+    let line = 0;
+
+    for (const text of output.split('\n')) {
+        const header = text.match(/^([0-9a-f]{40}) \d+ (\d+)/);
+        if (header) {
+            commit = header[1];
+            line = parseInt(header[2], 10);
+        } else if (text.startsWith('author-time ')) {
+            authorTimes.set(commit, parseInt(text.substring('author-time '.length), 10));
+        } else if (text.startsWith('\t') && !UNCOMMITTED.test(commit)) {
+            lineCommits.set(line, commit);
+        }
+    }
+
+    const lines = new Map<number, BlameLine>();
+    lineCommits.forEach((sha, lineNumber) => {
+        // Author details are only printed the first time a commit appears
+        const time = authorTimes.get(sha);
+        if (time !== undefined) lines.set(lineNumber, { commit: sha, date: new Date(time * 1000) });
+    });
+    return lines;
+}
+
+/**
+ * Runs git blame on a file.
+ *
+ * @param filePath Path of a file tracked by git
+ * @returns Committed lines keyed by line number
+ * @throws {Error} When the file is not in a git repository or is not tracked
+ */
+export async function blameFile(filePath: string): Promise<Map<number, BlameLine>> {
+    const output = await runGit(path.dirname(filePath), ['blame', '--porcelain', '--', path.basename(filePath)]);
+    return parseBlamePorcelain(output);
+}
+
+/**
+ * Records the date and commit each finding's line was introduced in the 'introduced' and
+ * 'introducedIn' attributes. Findings on uncommitted lines are left without them.
+ *
+ * @param findings Findings detected in the file
+ * @param filePath Path of the file
+ * @throws {Error} When the file is not in a git repository or is not tracked
+ */
+export async function annotateIntroduced(findings: URLMatch[], filePath: string): Promise<void> {
+    if (findings.length === 0) return;
+
+    const lines = await blameFile(filePath);
+    for (const finding of findings) {
+        const blame = lines.get(finding.line);
+        if (!blame) continue;
+
+        setFindingAttribute(finding, INTRODUCED_ATTRIBUTE, blame.date.toISOString().substring(0, 10));
+        setFindingAttribute(finding, INTRODUCED_COMMIT_ATTRIBUTE, blame.commit);
+    }
+}
+
+/**
+ * Returns the date a finding's line was introduced, for age-based rules.
+ *
+ * @param finding A finding annotated by annotateIntroduced
+ * @returns The date, or undefined when the finding has no 'introduced' attribute
+ */
+export function getIntroducedDate(finding: URLMatch): Date | undefined {
+    const introduced = finding.attributes && finding.attributes[INTRODUCED_ATTRIBUTE];
+    return introduced ? new Date(introduced) : undefined;
+}
@@ -0,0 +1,99 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { execFile } from 'child_process';
+import * as fs from 'fs';
+import * as path from 'path';
+import { promisify } from 'util';
+
+const execFileAsync = promisify(execFile);
+
+/** Upper bound on git output size; commit history of large repositories can be big */
+const GIT_MAX_BUFFER = 256 * 1024 * 1024;
+
+/** Most recent commits whose messages are scanned by default */
+export const DEFAULT_GIT_HISTORY_DEPTH = 1000;
+
+/**
+ * A piece of git metadata to scan, addressed by a pseudo file path.
+ */
+export interface GitMetadataSource {
+    /** Pseudo path identifying the source (e.g., 'git:commit/1a2b3c', 'git:tag/v1.0.0', '.gitmodules') */
+    file: string;
+    /** Text content to scan for URLs */
+    content: string;
+}
+
+/**
+ * Runs a git command in the given directory and returns its stdout.
+ *
+ * @param cwd Working directory for the command
+ * @param args Arguments to pass to git
+ * @returns Promise resolving to the command's stdout
+ */
+export async function runGit(cwd: string, args: string[]): Promise<string> {
+    const { stdout } = await execFileAsync('git', args, { cwd, maxBuffer: GIT_MAX_BUFFER, encoding: 'utf8' });
+    return stdout;
+}
+
+/**
+ * Collects the messages of the most recent commits, annotated tag messages, and the .gitmodules file
+ * of a repository.
+ *
+ * @param cwd Any directory inside the repository
+ * @param historyDepth Most recent commits to collect the messages of; 0 collects the whole history
+ *   (default: DEFAULT_GIT_HISTORY_DEPTH)
+ * @returns Promise resolving to the metadata sources to scan
+ * @throws {Error} When the directory is not inside a git repository or git is not installed
+ */
+export async function collectGitMetadata(
+    cwd: string,
+    historyDepth: number = DEFAULT_GIT_HISTORY_DEPTH,
+): Promise<GitMetadataSource[]> {
+    const sources: GitMetadataSource[] = [];
+    const topLevel = (await runGit(cwd, ['rev-parse', '--show-toplevel'])).trim();
+
+    // %x1e (record separator) between commits, %x00 between hash and message
+    const depth = historyDepth > 0 ? [`--max-count=${historyDepth}`] : [];
+    const log = await runGit(topLevel, ['log', ...depth, '--format=%H%x00%B%x1e']);
+    for (const record of log.split('\x1e')) {
+        const [hash, message] = record.replace(/^\n/, '').split('\x00');
+        if (hash && message && message.trim().length > 0) {
+            sources.push({ file: `git:commit/${hash}`, content: message });
+        }
+    }
+
+    // Lightweight tags point directly at commits whose messages are already covered above
+    const tags = await runGit(topLevel, [
+        'for-each-ref',
+        'refs/tags',
+        '--format=%(objecttype)%00%(refname:short)%00%(contents)%1e',
+    ]);
+    for (const record of tags.split('\x1e')) {
+        const [objectType, name, contents] = record.replace(/^\n/, '').split('\x00');
+        if (objectType === 'tag' && name && contents && contents.trim().length > 0) {
+            sources.push({ file: `git:tag/${name}`, content: contents });
+        }
+    }
+
+    const gitmodulesPath = path.join(topLevel, '.gitmodules');
+    try {
+        const content = await fs.promises.readFile(gitmodulesPath, 'utf8');
+        sources.push({ file: gitmodulesPath, content });
+    } catch {
+        // Repository has no submodules
+    }
+
+    return sources;
+}
@@ -0,0 +1,239 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { normalizeFingerprintPath } from './fingerprint';
+import { OutputFormat } from './options';
+import { SEVERITIES, Severity, compareSeverity } from './ruleEngine';
+import { SkippedFile, formatSkipSummary } from './skipDiagnostics';
+import { FileResult } from './urlDetector';
+
+/** Owner of the problem matcher, used to remove it after the step */
+export const PROBLEM_MATCHER_OWNER = 'url-detector';
+
+/**
+ * Problem matcher for the lines written by formatProblemLines(), turning them into annotations on
+ * the pull request diff.
+ */
+export const PROBLEM_MATCHER = {
+    problemMatcher: [
+        {
+            owner: PROBLEM_MATCHER_OWNER,
+            pattern: [
+                {
+                    regexp: '^(.+):(\\d+):(\\d+): (error|warning|notice): (.*) \\[([^\\]]+)\\]$',
+                    file: 1,
+                    line: 2,
+                    column: 3,
+                    severity: 4,
+                    message: 5,
+                    code: 6,
+                },
+            ],
+        },
+    ],
+};
+
+/** Maximum number of violations listed in the step summary */
+export const SUMMARY_ROW_LIMIT = 50;
+
+/** When the step fails: never, on any finding, or on violations of a severity or higher */
+export type FailOn = 'none' | 'findings' | Severity;
+
+/** Accepted values of the fail-on input */
+export const FAIL_ON_VALUES: FailOn[] = ['none', 'findings', ...SEVERITIES];
+
+/**
+ * Configuration of the action, read from the `INPUT_*` environment variables GitHub sets for the
+ * step's `with:` inputs.
+ */
+export interface ActionInputs {
+    /** Glob patterns for files to scan (input `scan`; default: the config file's, or all files) */
+    scan: string[];
+    /** Glob patterns for files to exclude (input `exclude`) */
+    exclude: string[];
+    /** JSON config file (input `config`) */
+    config?: string;
+    /** Built-in profile (input `profile`) */
+    profile?: string;
+    /** Report file to write (input `output`; default: none) */
+    output?: string;
+    /** Format of the report file (input `format`; default: sarif) */
+    format: OutputFormat;
+    /** When the step fails (input `fail-on`; default: error) */
+    failOn: FailOn;
+}
+
+/**
+ * Reads the action inputs. Like `core.getInput()`, input names are upper-cased with spaces replaced
+ * by underscores; list inputs accept one entry per line or commas.
+ *
+ * @param env Environment to read (default: process.env)
+ * @returns The inputs
+ * @throws {Error} When fail-on has an unknown value
+ */
+export function readActionInputs(env: NodeJS.ProcessEnv = process.env): ActionInputs {
+    const failOn = (getInput(env, 'fail-on') || 'error') as FailOn;
+    if (!FAIL_ON_VALUES.includes(failOn)) {
+        throw new Error(`Invalid fail-on input: ${failOn}. Must be one of: ${FAIL_ON_VALUES.join(', ')}`);
+    }
+    return {
+        scan: getListInput(env, 'scan'),
+        exclude: getListInput(env, 'exclude'),
+        config: getInput(env, 'config') || undefined,
+        profile: getInput(env, 'profile') || undefined,
+        output: getInput(env, 'output') || undefined,
+        format: getInput(env, 'format') || 'sarif',
+        failOn,
+    };
+}
+
+/**
+ * Formats every violation as a line the problem matcher understands:
+ * `file:line:column: severity: message [rule]`, with info reported as notice.
+ *
+ * @param results Scan results
+ * @returns One line per violation, in file order
+ */
+export function formatProblemLines(results: FileResult[]): string[] {
+    const lines: string[] = [];
+    for (const result of results) {
+        const file = normalizeFingerprintPath(result.file);
+        for (const finding of result.urls) {
+            for (const violation of finding.violations || []) {
+                const severity = violation.severity === 'info' ? 'notice' : violation.severity;
+                const message = violation.message.replace(/\s+/g, ' ');
+                lines.push(`${file}:${finding.line}:${finding.column}: ${severity}: ${message} [${violation.rule}]`);
+            }
+        }
+    }
+    return lines;
+}
+
+/**
+ * Whether the step should fail.
+ *
+ * @param results Scan results
+ * @param failOn The fail-on input
+ * @returns True when a finding, or a violation of the fail-on severity or higher, was found
+ */
+export function shouldFail(results: FileResult[], failOn: FailOn): boolean {
+    if (failOn === 'none') return false;
+    const findings = results.flatMap(result => result.urls);
+    if (failOn === 'findings') return findings.length > 0;
+    return findings.some(finding =>
+        (finding.violations || []).some(violation => compareSeverity(violation.severity, failOn) >= 0),
+    );
+}
+
+/**
+ * Formats the Markdown job summary: finding and violation counts, the violations (up to
+ * SUMMARY_ROW_LIMIT), and the skipped files.
+ *
+ * @param results Scan results
+ * @param skipped Files that were not scanned
+ * @returns The Markdown summary
+ */
+export function formatStepSummary(results: FileResult[], skipped: SkippedFile[] = []): string {
+    const rows: string[] = [];
+    const counts: Record<Severity, number> = { info: 0, warning: 0, error: 0 };
+    let findingCount = 0;
+    for (const result of results) {
+        const file = normalizeFingerprintPath(result.file);
+        for (const finding of result.urls) {
+            findingCount++;
+            for (const violation of finding.violations || []) {
+                counts[violation.severity]++;
+                const cells = [violation.severity, `${file}:${finding.line}`, finding.url, violation.message];
+                rows.push(`| ${cells.map(escapeCell).join(' | ')} |`);
+            }
+        }
+    }
+
+    const lines = [
+        '## URL Detector',
+        '',
+        `Scanned ${results.length} file(s) and found ${findingCount} URL(s): ` +
+            `${counts.error} error(s), ${counts.warning} warning(s), ${counts.info} info.`,
+    ];
+    if (rows.length > 0) {
+        lines.push('', '| Severity | Location | URL | Message |', '|----------|----------|-----|---------|');
+        lines.push(...rows.slice(0, SUMMARY_ROW_LIMIT));
+        if (rows.length > SUMMARY_ROW_LIMIT) {
+            lines.push('', `${rows.length - SUMMARY_ROW_LIMIT} more violation(s) are in the step log.`);
+        }
+    }
+    const skipSummary = formatSkipSummary(skipped);
+    if (skipSummary) {
+        lines.push('', `${skipSummary}.`);
+    }
+    return `${lines.join('\n')}\n`;
+}
+
+/**
+ * Appends the job summary to the file named by GITHUB_STEP_SUMMARY, when set.
+ *
+ * @param markdown The summary
+ * @param env Environment to read (default: process.env)
+ * @returns Whether the summary was written
+ */
+export async function writeStepSummary(markdown: string, env: NodeJS.ProcessEnv = process.env): Promise<boolean> {
+    if (!env.GITHUB_STEP_SUMMARY) return false;
+    await fs.promises.appendFile(env.GITHUB_STEP_SUMMARY, markdown, 'utf8');
+    return true;
+}
+
+/**
+ * Sets step outputs through the file named by GITHUB_OUTPUT, when set.
+ *
+ * @param outputs Output values by name
+ * @param env Environment to read (default: process.env)
+ */
+export async function writeStepOutputs(
+    outputs: Record<string, string | number>,
+    env: NodeJS.ProcessEnv = process.env,
+): Promise<void> {
+    if (!env.GITHUB_OUTPUT) return;
+    const lines = Object.entries(outputs).map(([name, value]) => `${name}=${value}\n`);
+    await fs.promises.appendFile(env.GITHUB_OUTPUT, lines.join(''), 'utf8');
+}
+
+/**
+ * Writes the problem matcher to the runner's temporary directory, where the runner can read it.
+ *
+ * @param env Environment to read (default: process.env)
+ * @returns Path of the matcher file, for the `::add-matcher::` command
+ */
+export async function writeProblemMatcher(env: NodeJS.ProcessEnv = process.env): Promise<string> {
+    const file = path.join(env.RUNNER_TEMP || os.tmpdir(), 'url-detector-matcher.json');
+    await fs.promises.writeFile(file, JSON.stringify(PROBLEM_MATCHER, null, 2), 'utf8');
+    return file;
+}
+
+function getInput(env: NodeJS.ProcessEnv, name: string): string {
+    return (env[`INPUT_${name.replace(/ /g, '_').toUpperCase()}`] || '').trim();
+}
+
+function getListInput(env: NodeJS.ProcessEnv, name: string): string[] {
+    return getInput(env, name)
+        .split(/[\n,]/)
+        .map(entry => entry.trim())
+        .filter(entry => entry.length > 0);
+}
+
+function escapeCell(text: string): string {
+    return text.replace(/\|/g, '\\|').replace(/\s+/g, ' ');
+}
@@ -0,0 +1,224 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { Rule, Violation } from './ruleEngine';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Category assigned to findings that are Go import or module paths rather than URLs */
+export const GO_IMPORT_CATEGORY = 'go-import';
+
+/** Hosts that no longer serve Go code and are flagged by default */
+export const DEFAULT_DEPRECATED_GO_HOSTS = ['code.google.com'];
+
+/** Code forges where the second path element names the repository owner */
+const FORGE_HOSTS = ['github.com', 'gitlab.com', 'bitbucket.org'];
+
+/** Where a Go import path was declared */
+export type GoImportKind = 'import' | 'canonical' | 'module' | 'require' | 'replace';
+
+/** go.mod directives that reference module paths; group 1 is the prefix before the path */
+const GO_MOD_DIRECTIVES: Array<[GoImportKind, RegExp]> = [
+    ['module', /^(\s*module\s+)(\S+)/],
+    ['require', /^(\s*require\s+)(\S+)\s+v/],
+    ['replace', /^(\s*replace\s+.*=>\s*)(\S+)/],
+];
+
+/** Entries inside require ( ... ) and replace ( ... ) blocks */
+const GO_MOD_BLOCK_ENTRIES: Record<'require' | 'replace', RegExp> = {
+    require: /^(\s*)(\S+)\s+v/,
+    replace: /^(.*=>\s*)(\S+)/,
+};
+
+/**
+ * Policy applied by the Go import rules.
+ */
+export interface GoImportPolicy {
+    /** Hosts whose import paths are reported as deprecated (default: DEFAULT_DEPRECATED_GO_HOSTS) */
+    deprecatedHosts?: string[];
+    /** Whether gopkg.in import paths are forbidden (default: false) */
+    forbidGopkgIn?: boolean;
+    /**
+     * Owners allowed on github.com, gitlab.com, and bitbucket.org. When set, imports from other
+     * owners on those forges are reported as possible personal forks.
+     */
+    allowedOwners?: string[];
+}
+
+/**
+ * Extracts remote import paths from Go source code, including canonical import comments.
+ * Standard library imports (no dot in the first path element) are skipped.
+ *
+ * @param sourceCode Go source code
+ * @returns Findings in the 'go-import' category, in source order
+ */
+export function extractGoImports(sourceCode: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+    const specPattern = /(?:[\w.]+[ \t]+)?["`]([^"`\n]+)["`]/g;
+
+    const singlePattern = /^[ \t]*import[ \t]+((?:[\w.]+[ \t]+)?["`][^"`\n]+["`])/gm;
+    for (const match of sourceCode.matchAll(singlePattern)) {
+        const specOffset = match.index! + match[0].length - match[1].length;
+        addSpecs(findings, sourceCode, match[1], specOffset, specPattern, 'import');
+    }
+
+    const groupPattern = /^[ \t]*import[ \t]*\(([^)]*)\)/gm;
+    for (const match of sourceCode.matchAll(groupPattern)) {
+        const bodyOffset = match.index! + match[0].indexOf('(') + 1;
+        addSpecs(findings, sourceCode, match[1], bodyOffset, specPattern, 'import');
+    }
+
+    const canonicalPattern = /^[ \t]*package[ \t]+\w+[ \t]*\/\/[ \t]*import[ \t]+"([^"\n]+)"/gm;
+    for (const match of sourceCode.matchAll(canonicalPattern)) {
+        const start = match.index! + match[0].length - match[1].length - 1;
+        addFinding(findings, sourceCode, match[1], start, 'comment', 'canonical');
+    }
+
+    return findings.sort((a, b) => a.start - b.start);
+}
+
+/**
+ * Extracts the module path and remote dependency paths (require and replace targets) from a go.mod file.
+ *
+ * @param content Content of a go.mod file
+ * @returns Findings in the 'go-import' category, in source order
+ */
+export function extractGoModPaths(content: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+    let block: 'require' | 'replace' | null = null;
+    let offset = 0;
+
+    for (const line of content.split('\n')) {
+        const code = line.replace(/\/\/.*$/, '');
+        const blockStart = code.match(/^\s*(require|replace)\s*\(/);
+
+        if (blockStart) {
+            block = blockStart[1] as 'require' | 'replace';
+        } else if (block && /^\s*\)/.test(code)) {
+            block = null;
+        } else {
+            const candidates: Array<[GoImportKind, RegExp]> = block
+                ? [[block, GO_MOD_BLOCK_ENTRIES[block]]]
+                : GO_MOD_DIRECTIVES;
+            for (const [kind, pattern] of candidates) {
+                const match = code.match(pattern);
+                if (match) {
+                    addFinding(findings, content, match[2], offset + match[1].length, 'unknown', kind);
+                    break;
+                }
+            }
+        }
+
+        offset += line.length + 1;
+    }
+
+    return findings;
+}
+
+/**
+ * Creates the Go import rules for a policy. The rules only inspect findings in the 'go-import' category.
+ *
+ * @param policy Policy to enforce
+ * @returns Rules to register with a RuleEngine or URLDetector
+ */
+export function createGoImportRules(policy: GoImportPolicy = {}): Rule[] {
+    const deprecatedHosts = (policy.deprecatedHosts || DEFAULT_DEPRECATED_GO_HOSTS).map(host => host.toLowerCase());
+    const allowedOwners = policy.allowedOwners && policy.allowedOwners.map(owner => owner.toLowerCase());
+
+    const rules: Rule[] = [
+        goImportRule('go-deprecated-host', 'Go imports from hosts that no longer serve code', (host, _owner, url) =>
+            deprecatedHosts.includes(host) ? `Import path ${url} points at deprecated host ${host}` : null,
+        ),
+    ];
+
+    if (policy.forbidGopkgIn) {
+        rules.push(
+            goImportRule('go-gopkg-in', 'Go imports through the gopkg.in redirector', (host, _owner, url) =>
+                host === 'gopkg.in' ? `Import path ${url} uses gopkg.in, which policy forbids` : null,
+            ),
+        );
+    }
+
+    if (allowedOwners) {
+        rules.push(
+            goImportRule('go-personal-fork', 'Go imports from forge owners outside the allowlist', (host, owner, url) =>
+                FORGE_HOSTS.includes(host) && owner && !allowedOwners.includes(owner)
+                    ? `Import path ${url} is owned by ${owner}, which is not an allowed owner (possible personal fork)`
+                    : null,
+            ),
+        );
+    }
+
+    return rules;
+}
+
+function goImportRule(
+    id: string,
+    description: string,
+    check: (host: string, owner: string | undefined, url: string) => string | null,
+): Rule {
+    return {
+        id,
+        description,
+        evaluate: (finding: URLMatch): Violation[] => {
+            if (finding.category !== GO_IMPORT_CATEGORY) return [];
+
+            const [host, owner] = finding.url.toLowerCase().split('/');
+            const message = check(host, owner, finding.url);
+            return message ? [{ rule: id, severity: 'warning', message }] : [];
+        },
+    };
+}
+
+function addSpecs(
+    findings: URLMatch[],
+    sourceCode: string,
+    specs: string,
+    specsOffset: number,
+    specPattern: RegExp,
+    kind: GoImportKind,
+): void {
+    // Strip line comments without shifting offsets so commented-out imports are skipped
+    const code = specs.replace(/\/\/[^\n]*/g, comment => ' '.repeat(comment.length));
+    for (const match of code.matchAll(specPattern)) {
+        const start = specsOffset + match.index! + match[0].length - match[1].length - 1;
+        addFinding(findings, sourceCode, match[1], start, 'string', kind);
+    }
+}
+
+function addFinding(
+    findings: URLMatch[],
+    sourceCode: string,
+    importPath: string,
+    start: number,
+    sourceType: URLMatch['sourceType'],
+    kind: GoImportKind,
+): void {
+    // Standard library paths have no dot in their first element; relative paths start with one
+    const firstElement = importPath.split('/')[0];
+    if (!firstElement.includes('.') || firstElement.startsWith('.')) return;
+
+    const before = sourceCode.substring(0, start);
+    const lines = before.split('\n');
+    const finding: URLMatch = {
+        url: importPath,
+        start,
+        end: start + importPath.length,
+        line: lines.length,
+        column: lines[lines.length - 1].length + 1,
+        sourceType,
+        category: GO_IMPORT_CATEGORY,
+    };
+    setFindingAttribute(finding, 'goImportKind', kind);
+    findings.push(finding);
+}
@@ -0,0 +1,349 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { tokenize } from './genericTokenizer';
+import { GO_IMPORT_CATEGORY } from './goImports';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Attribute holding the network call a Go URL flows into, or 'none' for inert data */
+export const NETWORK_CALL_ATTRIBUTE = 'networkCall';
+
+/** Value of the networkCall attribute for URLs that flow into no network call */
+export const NO_NETWORK_CALL = 'none';
+
+/** Go calls that contact a URL or address, with the position of that argument */
+export const GO_NETWORK_CALLS: Record<string, number> = {
+    'http.Get': 0,
+    'http.Head': 0,
+    'http.Post': 0,
+    'http.PostForm': 0,
+    'http.NewRequest': 1,
+    'http.NewRequestWithContext': 2,
+    'grpc.Dial': 0,
+    'grpc.DialContext': 1,
+    'grpc.NewClient': 0,
+    'websocket.Dial': 0,
+    'websocket.DefaultDialer.Dial': 0,
+    'net.Dial': 1,
+    'net.DialTimeout': 1,
+};
+
+/**
+ * Methods of *http.Client taking a URL first, recognized on http.DefaultClient, names assigned an
+ * http.Client literal, and receivers whose name ends in 'client'
+ */
+const CLIENT_METHODS = ['Get', 'Head', 'Post', 'PostForm'];
+
+/** Identifiers that are never variables */
+const GO_KEYWORDS = new Set(
+    (
+        'break case chan const continue default defer else fallthrough for func go goto if import interface map ' +
+        'package range return select struct switch type var nil true false iota'
+    ).split(' '),
+);
+
+/** Bound on the passes that trace names back from call sites, which settle after a few in practice */
+const MAX_PASSES = 10;
+
+const CALL = /(?<![\w.])([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*)\s*\(/g;
+const FUNC_DECLARATION = /\bfunc\s*(?:\([^)]*\)\s*)?([A-Za-z_]\w*)\s*\(/g;
+const ASSIGNMENT =
+    /^[ \t]*(?:(?:var|const)[ \t]+)?([A-Za-z_][\w.]*(?:[ \t]*,[ \t]*[A-Za-z_][\w.]*)*)(?:[ \t]+[\w.*[\]]+)?[ \t]*:?=(?!=)/gm;
+const KEYED_FIELD = /(?<=[{,]\s*)([A-Za-z_]\w*)[ \t]*:(?!=)/g;
+const CLIENT_LITERAL = /^\s*&?http\.Client\s*\{/;
+const RETURN = /\breturn\b/g;
+const IDENTIFIER = /(?<![\w.])[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*/g;
+
+interface Span {
+    start: number;
+    end: number;
+}
+
+interface CallSite {
+    /** Callee as written, e.g. 'http.Get' or 'c.client.Get' */
+    name: string;
+    /** Spans of the arguments, split at top-level commas */
+    args: Span[];
+}
+
+interface FunctionDeclaration {
+    name: string;
+    /** Offset of the name, where the declaration would be mistaken for a call */
+    nameStart: number;
+    params: string[];
+    body: Span | null;
+}
+
+/** Values assigned to names: variables, constants, fields, and the results of functions */
+interface Assignment {
+    names: string[];
+    value: Span;
+}
+
+/**
+ * Marks whether Go URL strings flow into a network call, so reviewers can prioritize the URLs a program
+ * really contacts over inert data such as documentation links or test fixtures. The 'networkCall'
+ * attribute names the call (e.g., 'http.Get', 'grpc.Dial') or is 'none'.
+ *
+ * The analysis is lexical and file-local: a URL flows into a call when it is written in the call's URL
+ * argument, or assigned to a name (a variable, constant, struct field, or function result) that reaches
+ * that argument, directly or through other assignments. Functions passing a parameter on to a network
+ * call count as network calls themselves. Names are matched without scoping, and fields by their name
+ * alone, so a name reused for unrelated values can mark extra URLs. URLs in comments and Go import
+ * paths are left alone.
+ *
+ * @param urls Findings in the file
+ * @param content Go source code
+ */
+export function annotateNetworkCalls(urls: URLMatch[], content: string): void {
+    const candidates = urls.filter(url => url.sourceType !== 'comment' && url.category !== GO_IMPORT_CATEGORY);
+    if (candidates.length === 0) return;
+
+    const code = blankLiterals(content);
+    const functions = findFunctions(code);
+    const declarations = new Set(functions.map(fn => fn.nameStart));
+    const calls = findCalls(code).filter(call => !declarations.has(call.start));
+    const assignments = [...findAssignments(code), ...findKeyedFields(code), ...findReturns(code, functions)];
+    const clients = new Set(['DefaultClient']);
+    for (const { names, value } of assignments) {
+        if (CLIENT_LITERAL.test(code.substring(value.start, value.end))) names.forEach(name => clients.add(name));
+    }
+
+    // Trace names back from the URL argument of each network call until nothing changes
+    const wrappers = new Map<string, { label: string; position: number }>();
+    const reaching = new Map<string, string>();
+    const targets: Array<Span & { label: string }> = [];
+    for (let pass = 0, changed = true; changed && pass < MAX_PASSES; pass++) {
+        changed = false;
+        targets.length = 0;
+        for (const call of calls) {
+            const network = resolveNetworkCall(call.site.name, clients, wrappers);
+            const argument = network && call.site.args[network.position];
+            if (!network || !argument) continue;
+            targets.push({ ...argument, label: network.label });
+            changed = addIdentifiers(reaching, code, argument, network.label) || changed;
+        }
+        for (const assignment of assignments) {
+            const name = assignment.names.find(assigned => reaching.has(assigned));
+            if (name) changed = addIdentifiers(reaching, code, assignment.value, reaching.get(name)!) || changed;
+        }
+        for (const fn of functions) {
+            const position = fn.params.findIndex(param => reaching.has(param));
+            if (position !== -1 && !wrappers.has(fn.name)) {
+                wrappers.set(fn.name, { label: reaching.get(fn.params[position])!, position });
+                changed = true;
+            }
+        }
+    }
+
+    for (const url of candidates) {
+        const target = targets.find(span => url.start >= span.start && url.end <= span.end);
+        const assignment = target
+            ? undefined
+            : assignments.find(
+                  ({ names, value }) =>
+                      url.start >= value.start && url.end <= value.end && names.some(name => reaching.has(name)),
+              );
+        const label = target
+            ? target.label
+            : assignment
+              ? reaching.get(assignment.names.find(name => reaching.has(name))!)!
+              : NO_NETWORK_CALL;
+        setFindingAttribute(url, NETWORK_CALL_ATTRIBUTE, label);
+    }
+}
+
+/**
+ * The call label and URL argument position of a callee, or null when it is not a network call.
+ */
+function resolveNetworkCall(
+    name: string,
+    clients: Set<string>,
+    wrappers: Map<string, { label: string; position: number }>,
+): { label: string; position: number } | null {
+    if (name in GO_NETWORK_CALLS) return { label: name, position: GO_NETWORK_CALLS[name] };
+
+    const parts = name.split('.');
+    const method = parts[parts.length - 1];
+    const receiver = parts.length >= 2 ? parts[parts.length - 2] : '';
+    if ((clients.has(receiver) || /client$/i.test(receiver)) && CLIENT_METHODS.includes(method)) {
+        return { label: `http.Client.${method}`, position: 0 };
+    }
+    return wrappers.get(method) || null;
+}
+
+/**
+ * Records the names in a span as reaching a call. Selectors are named by their last segment, so
+ * `c.baseURL` reaches as `baseURL`.
+ *
+ * @returns Whether any name was new
+ */
+function addIdentifiers(reaching: Map<string, string>, code: string, span: Span, label: string): boolean {
+    let added = false;
+    for (const match of code.substring(span.start, span.end).matchAll(IDENTIFIER)) {
+        const name = lastSegment(match[0]);
+        if (GO_KEYWORDS.has(name) || reaching.has(name)) continue;
+        reaching.set(name, label);
+        added = true;
+    }
+    return added;
+}
+
+function lastSegment(selector: string): string {
+    return selector.substring(selector.lastIndexOf('.') + 1);
+}
+
+/**
+ * Replaces string literals and comments with spaces, keeping offsets and line breaks, so brackets and
+ * names inside them are not mistaken for code.
+ */
+function blankLiterals(content: string): string {
+    let code = '';
+    let offset = 0;
+    for (const token of tokenize(content)) {
+        const blank = content.substring(token.start, token.end).replace(/[^\n]/g, ' ');
+        code += content.substring(offset, token.start) + blank;
+        offset = token.end;
+    }
+    return code + content.substring(offset);
+}
+
+function findCalls(code: string): Array<{ start: number; site: CallSite }> {
+    const calls: Array<{ start: number; site: CallSite }> = [];
+    for (const match of code.matchAll(CALL)) {
+        const open = match.index! + match[0].length - 1;
+        calls.push({ start: match.index!, site: { name: match[1], args: splitArguments(code, open) } });
+    }
+    return calls;
+}
+
+function findFunctions(code: string): FunctionDeclaration[] {
+    const functions: FunctionDeclaration[] = [];
+    for (const match of code.matchAll(FUNC_DECLARATION)) {
+        const open = match.index! + match[0].length - 1;
+        const close = matchingBracket(code, open);
+        const params = code
+            .substring(open + 1, close)
+            .split(',')
+            .map(param => /^\s*([A-Za-z_]\w*)/.exec(param))
+            .filter((param): param is RegExpExecArray => param !== null)
+            .map(param => param[1]);
+
+        // The body is the first brace after the parameters and results
+        const brace = code.indexOf('{', close);
+        const body = brace === -1 ? null : { start: brace + 1, end: matchingBracket(code, brace) };
+        functions.push({ name: match[1], nameStart: match.index! + match[0].indexOf(match[1], 4), params, body });
+    }
+    return functions;
+}
+
+function findAssignments(code: string): Assignment[] {
+    const assignments: Assignment[] = [];
+    for (const match of code.matchAll(ASSIGNMENT)) {
+        const names = match[1]
+            .split(',')
+            .map(name => lastSegment(name.trim()))
+            .filter(name => !GO_KEYWORDS.has(name));
+        if (names.length === 0) continue;
+        const start = match.index! + match[0].length;
+        assignments.push({ names, value: { start, end: statementEnd(code, start) } });
+    }
+    return assignments;
+}
+
+/**
+ * Treats the fields of keyed composite literals as assigned, so `http.Get(c.baseURL)` reaches the URL in
+ * `Client{baseURL: "https://..."}`.
+ */
+function findKeyedFields(code: string): Assignment[] {
+    const fields: Assignment[] = [];
+    for (const match of code.matchAll(KEYED_FIELD)) {
+        if (GO_KEYWORDS.has(match[1])) continue;
+        const start = match.index! + match[0].length;
+        fields.push({ names: [match[1]], value: { start, end: statementEnd(code, start, true) } });
+    }
+    return fields;
+}
+
+/**
+ * Treats the values a function returns as assigned to the function's name, so `http.Get(endpoint())`
+ * reaches the URL in `return "https://..."`.
+ */
+function findReturns(code: string, functions: FunctionDeclaration[]): Assignment[] {
+    const returns: Assignment[] = [];
+    for (const match of code.matchAll(RETURN)) {
+        const start = match.index! + match[0].length;
+        // The innermost function containing the return is the last one starting before it
+        const fn = functions
+            .filter(({ body }) => body && body.start <= start && start < body.end)
+            .sort((a, b) => b.body!.start - a.body!.start)[0];
+        if (fn) returns.push({ names: [fn.name], value: { start, end: statementEnd(code, start) } });
+    }
+    return returns;
+}
+
+/**
+ * Splits the arguments of a call at top-level commas.
+ *
+ * @param code Code with literals blanked
+ * @param open Offset of the opening parenthesis
+ */
+function splitArguments(code: string, open: number): Span[] {
+    const close = matchingBracket(code, open);
+    const args: Span[] = [];
+    let start = open + 1;
+    let depth = 0;
+    for (let index = open + 1; index < close; index++) {
+        const char = code[index];
+        if ('([{'.includes(char)) depth++;
+        else if (')]}'.includes(char)) depth--;
+        else if (char === ',' && depth === 0) {
+            args.push({ start, end: index });
+            start = index + 1;
+        }
+    }
+    if (close > open + 1) args.push({ start, end: close });
+    return args;
+}
+
+/**
+ * Offset of the bracket closing the one at open, or the end of the code when it is never closed.
+ */
+function matchingBracket(code: string, open: number): number {
+    let depth = 0;
+    for (let index = open; index < code.length; index++) {
+        const char = code[index];
+        if ('([{'.includes(char)) depth++;
+        else if (')]}'.includes(char) && --depth === 0) return index;
+    }
+    return code.length;
+}
+
+/**
+ * End of the statement starting at an offset: the end of its line, or of the line closing the brackets
+ * it opens, such as a multi-line composite literal. The value of a field ends at its comma as well.
+ */
+function statementEnd(code: string, start: number, field: boolean = false): number {
+    let depth = 0;
+    for (let index = start; index < code.length; index++) {
+        const char = code[index];
+        if ('([{'.includes(char)) depth++;
+        else if (')]}'.includes(char)) {
+            if (--depth < 0) return index;
+        } else if ((char === '\n' || char === ';' || (field && char === ',')) && depth === 0) {
+            return index;
+        }
+    }
+    return code.length;
+}
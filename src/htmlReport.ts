@@ -0,0 +1,286 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { normalizeFingerprintPath } from './fingerprint';
+import { ScanManifest } from './manifest';
+import { Report, ReportSections } from './report';
+import { SEVERITIES, Severity, compareSeverity, getFindingSeverity } from './ruleEngine';
+import { TeamRollup, buildTeamRollup } from './teamRollup';
+import { FileResult, URLMatch } from './urlFilter';
+
+/**
+ * How a finding changed since the previous scan: 'added' findings are new, 'removed' ones were
+ * fixed or deleted, and 'unchanged' ones are in both scans.
+ */
+export type ComparisonStatus = 'added' | 'removed' | 'unchanged';
+
+/** Comparison statuses, in the order the HTML report lists them */
+export const COMPARISON_STATUSES: ComparisonStatus[] = ['added', 'removed', 'unchanged'];
+
+/**
+ * A finding of either scan with its comparison status.
+ */
+export interface ComparedFinding {
+    status: ComparisonStatus;
+    /** File of the finding, relative to the working directory with forward slashes */
+    file: string;
+    finding: URLMatch;
+}
+
+/**
+ * Options for createHtmlReport().
+ */
+export interface HtmlReportOptions {
+    /** Report of the previous scan, which turns on comparison mode (default: none) */
+    previous?: Report;
+    /** Team by path prefix for the team rollups; the longest matching prefix wins (default: use the owner attribute) */
+    teams?: Record<string, string>;
+    /** Page title (default: 'URL Detector Report') */
+    title?: string;
+}
+
+/**
+ * Compares the findings of two scans by fingerprint. Findings without a fingerprint, as in reports of
+ * early versions, are matched by file and URL instead.
+ *
+ * @param results Results of the current scan
+ * @param previous Results of the previous scan
+ * @returns Added and unchanged findings in scan order, followed by the removed findings
+ */
+export function compareFindings(results: FileResult[], previous: FileResult[]): ComparedFinding[] {
+    const before = new Set<string>();
+    for (const result of previous) {
+        for (const finding of result.urls) before.add(comparisonKey(result.file, finding));
+    }
+
+    const now = new Set<string>();
+    const compared: ComparedFinding[] = [];
+    for (const result of results) {
+        const file = normalizeFingerprintPath(result.file);
+        for (const finding of result.urls) {
+            const key = comparisonKey(result.file, finding);
+            now.add(key);
+            compared.push({ status: before.has(key) ? 'unchanged' : 'added', file, finding });
+        }
+    }
+    for (const result of previous) {
+        const file = normalizeFingerprintPath(result.file);
+        for (const finding of result.urls) {
+            if (!now.has(comparisonKey(result.file, finding))) compared.push({ status: 'removed', file, finding });
+        }
+    }
+    return compared;
+}
+
+/**
+ * Renders scan results as a self-contained HTML page, with inline styles and no scripts, that can be
+ * mailed or shown in a review meeting. Findings are listed from the most severe down and color coded
+ * by their highest violation severity, followed by a rollup per team.
+ *
+ * Given the report of a previous scan, the page compares the two: findings are marked added, removed,
+ * or unchanged, with added ones listed first, and the team rollups count new and fixed findings.
+ * A team rollup section of the current scan is shown as it is; otherwise the rollups are computed from
+ * the teams option.
+ *
+ * @param results Scan results
+ * @param sections Report-level analyses of the scan
+ * @param manifest Manifest of the scan, for the date and commit in the header
+ * @param options Report options
+ * @returns The HTML document
+ */
+export function createHtmlReport(
+    results: FileResult[],
+    sections?: ReportSections,
+    manifest?: ScanManifest,
+    options: HtmlReportOptions = {},
+): string {
+    const previous = options.previous;
+    const findings: ComparedFinding[] = previous
+        ? compareFindings(results, previous.files)
+        : results.flatMap(result =>
+              result.urls.map(finding => ({
+                  status: 'unchanged' as ComparisonStatus,
+                  file: normalizeFingerprintPath(result.file),
+                  finding,
+              })),
+          );
+    findings.sort(
+        (a, b) =>
+            (previous ? COMPARISON_STATUSES.indexOf(a.status) - COMPARISON_STATUSES.indexOf(b.status) : 0) ||
+            severityRank(b.finding) - severityRank(a.finding) ||
+            a.file.localeCompare(b.file) ||
+            a.finding.start - b.finding.start,
+    );
+    const teams =
+        (sections && sections.teams) ||
+        buildTeamRollup(results, { teams: options.teams, baseline: previous ? previous.files : undefined });
+    const title = options.title || 'URL Detector Report';
+
+    return [
+        '<!DOCTYPE html>',
+        '<html lang="en">',
+        '<head>',
+        '<meta charset="utf-8">',
+        `<title>${escapeHtml(title)}</title>`,
+        `<style>${STYLES}</style>`,
+        '</head>',
+        '<body>',
+        `<h1>${escapeHtml(title)}</h1>`,
+        formatScans(manifest, previous && previous.manifest),
+        formatSummary(findings, !!previous),
+        formatFindings(findings, !!previous),
+        formatTeams(teams, !!previous),
+        '</body>',
+        '</html>',
+        '',
+    ].join('\n');
+}
+
+/**
+ * Escapes text for use in HTML content and attribute values.
+ *
+ * @param text The text
+ * @returns The escaped text
+ */
+export function escapeHtml(text: string): string {
+    return text.replace(/[&<>"']/g, char => HTML_ENTITIES[char]);
+}
+
+const HTML_ENTITIES: Record<string, string> = {
+    '&': '&amp;',
+    '<': '&lt;',
+    '>': '&gt;',
+    '"': '&quot;',
+    "'": '&#39;',
+};
+
+/** Severities from the most severe down, the order of the summary rows and team columns */
+const DESCENDING_SEVERITIES = [...SEVERITIES].reverse();
+
+const STYLES = [
+    'body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:2em;color:#1f2328}',
+    'table{border-collapse:collapse;margin-bottom:2em}',
+    'th,td{border:1px solid #d0d7de;padding:4px 8px;text-align:left;vertical-align:top}',
+    'th{background:#f6f8fa}',
+    'td.url{font-family:monospace;word-break:break-all}',
+    'tr.added{background:#ffebe9}',
+    'tr.removed{background:#dafbe1;text-decoration:line-through}',
+    'tr.unchanged{background:#fff}',
+    '.severity{border-radius:3px;padding:0 6px;color:#fff;font-size:0.85em}',
+    '.severity.error{background:#cf222e}',
+    '.severity.warning{background:#bf8700}',
+    '.severity.info{background:#0969da}',
+    '.severity.none{background:#6e7781}',
+].join('\n');
+
+function comparisonKey(file: string, finding: URLMatch): string {
+    return finding.fingerprint || `${normalizeFingerprintPath(file)}\0${finding.url}`;
+}
+
+function severityRank(finding: URLMatch): number {
+    const severity = getFindingSeverity(finding);
+    return severity ? SEVERITIES.indexOf(severity) : -1;
+}
+
+function formatScans(manifest: ScanManifest | undefined, previous: ScanManifest | undefined): string {
+    const describe = (scan: ScanManifest): string =>
+        escapeHtml(scan.createdAt) + (scan.commit ? ` at commit <code>${escapeHtml(scan.commit)}</code>` : '');
+
+    const lines: string[] = [];
+    if (manifest) lines.push(`<p>Scan of ${describe(manifest)}</p>`);
+    if (previous) lines.push(`<p>Compared with the scan of ${describe(previous)}</p>`);
+    return lines.join('\n');
+}
+
+function formatSummary(findings: ComparedFinding[], comparing: boolean): string {
+    const columns: ComparisonStatus[] = comparing ? COMPARISON_STATUSES : ['unchanged'];
+    const count = (status: ComparisonStatus, severity: Severity | undefined): number =>
+        findings.filter(entry => entry.status === status && getFindingSeverity(entry.finding) === severity).length;
+
+    const head = comparing ? columns.map(status => `<th>${capitalize(status)}</th>`).join('') : '<th>Findings</th>';
+    const rows: (Severity | undefined)[] = [...DESCENDING_SEVERITIES, undefined];
+    return [
+        '<h2>Summary</h2>',
+        '<table>',
+        `<tr><th>Severity</th>${head}</tr>`,
+        ...rows.map(
+            severity =>
+                `<tr><td>${formatSeverity(severity)}</td>` +
+                columns.map(status => `<td>${count(status, severity)}</td>`).join('') +
+                '</tr>',
+        ),
+        '</table>',
+    ].join('\n');
+}
+
+function formatFindings(findings: ComparedFinding[], comparing: boolean): string {
+    if (findings.length === 0) return '<h2>Findings</h2>\n<p>No URLs found.</p>';
+
+    const head = ['Severity', 'File', 'Line:Col', 'URL', 'Violations'];
+    if (comparing) head.unshift('Status');
+    return [
+        '<h2>Findings</h2>',
+        '<table>',
+        `<tr>${head.map(column => `<th>${column}</th>`).join('')}</tr>`,
+        ...findings.map(({ status, file, finding }) => {
+            const violations = [...(finding.violations || [])]
+                .sort((a, b) => compareSeverity(b.severity, a.severity))
+                .map(violation => `${escapeHtml(violation.rule)}: ${escapeHtml(violation.message)}`)
+                .join('<br>');
+            const cells = [
+                `<td>${formatSeverity(getFindingSeverity(finding))}</td>`,
+                `<td>${escapeHtml(file)}</td>`,
+                `<td>${finding.line}:${finding.column}</td>`,
+                `<td class="url">${escapeHtml(finding.url)}</td>`,
+                `<td>${violations}</td>`,
+            ];
+            if (comparing) cells.unshift(`<td>${capitalize(status)}</td>`);
+            return `<tr class="${status}">${cells.join('')}</tr>`;
+        }),
+        '</table>',
+    ].join('\n');
+}
+
+function formatTeams(teams: TeamRollup[], comparing: boolean): string {
+    if (teams.length === 0) return '';
+
+    const head = ['Team', 'Files', 'URLs', ...DESCENDING_SEVERITIES.map(capitalize), 'Worst files'];
+    if (comparing) head.splice(3, 0, 'New', 'Fixed');
+    return [
+        '<h2>Teams</h2>',
+        '<table>',
+        `<tr>${head.map(column => `<th>${column}</th>`).join('')}</tr>`,
+        ...teams.map(team => {
+            const cells = [
+                escapeHtml(team.team),
+                String(team.fileCount),
+                String(team.urlCount),
+                ...DESCENDING_SEVERITIES.map(severity => String(team.severities[severity])),
+                team.worstFiles.map(offender => escapeHtml(offender.file)).join('<br>'),
+            ];
+            if (comparing) cells.splice(3, 0, String(team.newCount ?? 0), String(team.fixedCount ?? 0));
+            return `<tr>${cells.map(cell => `<td>${cell}</td>`).join('')}</tr>`;
+        }),
+        '</table>',
+    ].join('\n');
+}
+
+function formatSeverity(severity: Severity | undefined): string {
+    const name = severity || 'none';
+    return `<span class="severity ${name}">${name}</span>`;
+}
+
+function capitalize(text: string): string {
+    return text.charAt(0).toUpperCase() + text.slice(1);
+}
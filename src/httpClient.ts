@@ -0,0 +1,50 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as https from 'https';
+import * as net from 'net';
+
+/**
+ * Makes an HTTP request; same contract as the global fetch.
+ */
+export type FetchFunction = (url: string, init?: RequestInit) => Promise<Response>;
+
+/**
+ * The network stack used by every network-enabled feature, so library users can route validation
+ * traffic through an instrumented or mTLS-enabled client instead of the default transport. The
+ * client is shared by concurrent requests and must be safe to call concurrently.
+ *
+ * @example
+ * ```typescript
+ * const agent = new https.Agent({ cert, key, ca });
+ * const client: HttpClient = { fetch: instrumentedFetch, agent, lookup: corporateLookup };
+ * const detector = new URLDetector({ checkLicenseLinks: true }, ConsoleLogger, client);
+ * await buildReachabilityMatrix(results, { direct: '' }, 10000, client);
+ * ```
+ */
+export interface HttpClient {
+    /** Makes the requests of license link checks, data bundle downloads, and Jira issues */
+    fetch: FetchFunction;
+    /** Agent for https reachability probes (e.g., with client certificates), whose TLS options also apply to tunnels */
+    agent?: https.Agent;
+    /** Resolves host names for reachability probes, including proxy hosts (default: dns.lookup) */
+    lookup?: net.LookupFunction;
+}
+
+/**
+ * The default network stack: the global fetch, the default https agent, and dns.lookup.
+ */
+export const DEFAULT_HTTP_CLIENT: HttpClient = {
+    fetch: (url, init) => fetch(url, init),
+};
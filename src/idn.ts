@@ -0,0 +1,64 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { domainToASCII, domainToUnicode } from 'url';
+
+/** Characters of glob patterns, whose labels are kept as written */
+const GLOB_SYNTAX = /[*?[\]{}!]/;
+
+/**
+ * Converts a host, or a host pattern such as '*.münchen.de', to its lowercase ASCII form, with
+ * internationalized labels in punycode ('*.xn--mnchen-3ya.de'). Labels are converted one at a time
+ * with the IDNA mapping browsers use, so wildcards survive and full-width or uppercase characters
+ * are folded; labels with glob syntax or that cannot be converted are kept as written.
+ *
+ * @param host The host or pattern
+ * @returns The ASCII form
+ */
+export function toAsciiHost(host: string): string {
+    const lower = host.toLowerCase();
+    // eslint-disable-next-line no-control-regex
+    if (/^[\x00-\x7f]*$/.test(lower)) return lower;
+    return lower
+        .split('.')
+        .map(label => (GLOB_SYNTAX.test(label) ? label : domainToASCII(label) || label))
+        .join('.');
+}
+
+/**
+ * Converts a host, or a host pattern, to its lowercase Unicode form, decoding punycode labels.
+ *
+ * @param host The host or pattern
+ * @returns The Unicode form
+ */
+export function toUnicodeHost(host: string): string {
+    const ascii = toAsciiHost(host);
+    if (!ascii.includes('xn--')) return ascii;
+    return ascii
+        .split('.')
+        .map(label => (label.startsWith('xn--') && domainToUnicode(label)) || label)
+        .join('.');
+}
+
+/**
+ * The forms a host can be written in: ASCII (punycode) first, then Unicode when it differs.
+ *
+ * @param host The host or pattern
+ * @returns One or two forms, lowercase
+ */
+export function hostForms(host: string): string[] {
+    const ascii = toAsciiHost(host);
+    const unicode = toUnicodeHost(ascii);
+    return unicode === ascii ? [ascii] : [ascii, unicode];
+}
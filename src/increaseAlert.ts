@@ -0,0 +1,130 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { readReport } from './report';
+import { TrendStore } from './trendStore';
+
+/**
+ * Largest allowed growth in the number of findings: a percentage of the baseline ('20%') or a
+ * number of findings ('50').
+ */
+export interface IncreaseThreshold {
+    value: number;
+    relative: boolean;
+}
+
+/**
+ * Result of comparing the finding count of a run against its baseline.
+ */
+export interface IncreaseAlert {
+    /** Where the baseline count came from (a report file, or a trend store snapshot key) */
+    baseline: string;
+    /** Findings in the baseline */
+    baselineCount: number;
+    /** Findings in the current run */
+    currentCount: number;
+    /** Growth in findings (negative when findings went down) */
+    increase: number;
+    /** Growth as a percentage of the baseline, or null when the baseline has no findings */
+    percent: number | null;
+    /** The threshold as given (e.g., '20%') */
+    threshold: string;
+    /** Whether the growth exceeds the threshold */
+    triggered: boolean;
+}
+
+/**
+ * Parses a threshold such as '20%' (relative to the baseline) or '50' (findings).
+ *
+ * @param text The threshold
+ * @returns The parsed threshold
+ * @throws {Error} When the text is not a non-negative number, optionally followed by '%'
+ */
+export function parseIncreaseThreshold(text: string): IncreaseThreshold {
+    const match = text.trim().match(/^(\d+(?:\.\d+)?)(%?)$/);
+    if (!match) {
+        throw new Error(`Invalid increase threshold: ${text}. Use a percentage (20%) or a number of findings (50)`);
+    }
+    return { value: parseFloat(match[1]), relative: match[2] === '%' };
+}
+
+/**
+ * Compares the current finding count against the baseline. With a relative threshold, any growth
+ * from an empty baseline triggers the alert.
+ *
+ * @param baseline Where the baseline count came from
+ * @param baselineCount Findings in the baseline
+ * @param currentCount Findings in the current run
+ * @param threshold Threshold as given on the command line (e.g., '20%')
+ * @returns The comparison
+ */
+export function checkIncrease(
+    baseline: string,
+    baselineCount: number,
+    currentCount: number,
+    threshold: string,
+): IncreaseAlert {
+    const limit = parseIncreaseThreshold(threshold);
+    const increase = currentCount - baselineCount;
+    const percent = baselineCount > 0 ? Math.round((increase / baselineCount) * 1000) / 10 : null;
+
+    let triggered: boolean;
+    if (!limit.relative) {
+        triggered = increase > limit.value;
+    } else if (percent === null) {
+        triggered = increase > 0;
+    } else {
+        triggered = (increase / baselineCount) * 100 > limit.value;
+    }
+
+    return { baseline, baselineCount, currentCount, increase, percent, threshold, triggered };
+}
+
+/**
+ * Reads the baseline finding count from a previous report, or from the most recent snapshot in a
+ * trend store.
+ *
+ * @param source Either a report file, or a trend store
+ * @returns Where the count came from and the count, or null when the trend store has no snapshots
+ */
+export async function loadBaselineCount(
+    source: { report: string } | { trendStore: string },
+): Promise<{ baseline: string; count: number } | null> {
+    if ('report' in source) {
+        const report = await readReport(source.report);
+        return { baseline: source.report, count: report.summary.totalUrls };
+    }
+
+    const snapshots = await new TrendStore(source.trendStore).load();
+    if (snapshots.length === 0) return null;
+
+    const latest = snapshots[snapshots.length - 1];
+    return { baseline: `${source.trendStore}#${latest.key}`, count: latest.total };
+}
+
+/**
+ * Describes an alert in one line for logs and email summaries.
+ *
+ * @param alert The comparison
+ * @returns The description
+ */
+export function formatIncreaseAlert(alert: IncreaseAlert): string {
+    const percent = alert.percent === null ? '' : `, ${alert.percent >= 0 ? '+' : ''}${alert.percent}%`;
+    const change = `${alert.increase >= 0 ? '+' : ''}${alert.increase}${percent}`;
+    const verdict = alert.triggered ? 'exceeds' : 'within';
+    return (
+        `Findings went from ${alert.baselineCount} to ${alert.currentCount} (${change}) since ${alert.baseline}, ` +
+        `${verdict} the allowed increase of ${alert.threshold}`
+    );
+}
@@ -12,11 +12,480 @@
  * and limitations under the License.
  */
 
-export { URLDetector } from './urlDetector';
-export { DetectorOptions } from './options';
-export { LanguageManager, LanguageConfig } from './languageManager';
-export { URLFilter } from './urlFilter';
-export { OutputFormatter } from './outputFormatter';
+export { URLDetector, FileListing } from './urlDetector';
+export { FetchFunction, HttpClient, DEFAULT_HTTP_CLIENT } from './httpClient';
+export { DetectorOptions, OutputEncoding } from './options';
+export {
+    LanguageManager,
+    LanguageConfig,
+    LanguageGrammar,
+    GrammarIssue,
+    EMBEDDED_GRAMMARS_FILE,
+    formatGrammarIssue,
+} from './languageManager';
+export { URLFilter, URLMatch, setFindingAttribute } from './urlFilter';
+export {
+    URL_PATTERN,
+    SCHEMA_IDENTIFIER_PATTERNS,
+    TextExtractionOptions,
+    isSchemaIdentifier,
+    extractUrls,
+} from './textExtraction';
+export {
+    STREAM_SOURCE,
+    DEFAULT_STREAM_MAX_LINE_LENGTH,
+    StreamScanOptions,
+    StreamScanSummary,
+    StreamLine,
+    readStreamLines,
+} from './streamScan';
+export {
+    RuleEngine,
+    Rule,
+    Violation,
+    FileContext,
+    Severity,
+    SEVERITIES,
+    compareSeverity,
+    getFindingSeverity,
+} from './ruleEngine';
+export {
+    PluginCapability,
+    PLUGIN_CAPABILITIES,
+    DEFAULT_PLUGIN_TIMEOUT_MS,
+    DEFAULT_PLUGIN_MEMORY_MB,
+    RulePluginConfig,
+    PluginError,
+    loadRulePlugin,
+} from './pluginSandbox';
+export { OutputFormatter, encodeOutput, escapeNonAscii } from './outputFormatter';
+export {
+    Report,
+    ReportFormat,
+    ReportSections,
+    REPORT_FORMATS,
+    createReport,
+    serializeReport,
+    serializeNdjsonFinding,
+    parseReport,
+    detectReportFormat,
+    readReport,
+    writeReport,
+} from './report';
+export {
+    BUILT_IN_FORMATS,
+    Finding,
+    Sink,
+    SinkOutput,
+    SinkFactory,
+    registerSink,
+    unregisterSink,
+    getSinkFormats,
+    createSink,
+    runSink,
+} from './sinks';
+export {
+    ScanManifest,
+    UNHASHED_OPTIONS,
+    POLICY_OPTIONS,
+    CREDENTIAL_OPTIONS,
+    canonicalJson,
+    effectiveConfig,
+    hashConfig,
+    hashPolicy,
+    createScanManifest,
+} from './manifest';
+export {
+    GO_IMPORT_CATEGORY,
+    GoImportPolicy,
+    GoImportKind,
+    extractGoImports,
+    extractGoModPaths,
+    createGoImportRules,
+} from './goImports';
+export { NETWORK_CALL_ATTRIBUTE, NO_NETWORK_CALL, GO_NETWORK_CALLS, annotateNetworkCalls } from './goNetworkFlow';
+export { GENERATED_ATTRIBUTE, isGeneratedFile } from './generatedCode';
+export { DUPLICATES_ATTRIBUTE, contentHash, collapseDuplicateFiles } from './duplicateFiles';
+export {
+    LICENSE_CATEGORY,
+    CANONICAL_LICENSE_URLS,
+    LicenseHeader,
+    findLicenseHeader,
+    selectLicenseHeaderUrls,
+    matchCanonicalLicenseUrl,
+    createLicenseUrlRule,
+    checkLicenseLinks,
+} from './licenseHeaders';
+export {
+    DOC_LINK_CATEGORY,
+    DocLinkValidator,
+    isDocumentationFile,
+    isMarkdownFile,
+    blankMarkdownCode,
+    extractDocLinks,
+    collectAnchors,
+    slugifyHeading,
+} from './docLinks';
+export {
+    RELATIVE_URL_CATEGORY,
+    RELATIVE_URL_LANGUAGES,
+    RelativeReferenceKind,
+    extractRelativeUrls,
+    classifyRelativeReference,
+} from './relativeUrls';
+export { WINDOWS_PATH_CATEGORY, SHARE_ATTRIBUTE, WindowsPathKind, extractWindowsPaths } from './windowsPaths';
+export {
+    REALTIME_RPC_CATEGORY,
+    PROTOCOL_ATTRIBUTE,
+    RealtimeProtocol,
+    classifyRealtimeEndpoint,
+    extractGrpcTargets,
+    mergeGrpcTargets,
+} from './realtimeEndpoints';
+export { CDN_ATTRIBUTE, detectCdn, createSriAdvisoryRule } from './sriAdvisory';
+export {
+    ENCODING_ANOMALY_RULE,
+    EncodingAnomalyKind,
+    EncodingAnomaly,
+    findEncodingAnomalies,
+    createEncodingAnomalyRule,
+} from './encodingAnomalies';
+export {
+    OBFUSCATED_HOST_RULE,
+    ObfuscatedHostKind,
+    ObfuscatedHost,
+    findObfuscatedHosts,
+    createObfuscatedHostRule,
+} from './obfuscatedHosts';
+export {
+    OPEN_REDIRECT_RULE,
+    DEFAULT_REDIRECT_PARAMS,
+    RedirectParam,
+    findRedirectParams,
+    createOpenRedirectRule,
+} from './openRedirect';
+export {
+    CANARY_TOKEN_RULE,
+    CANARY_ATTRIBUTE,
+    CanaryPolicy,
+    CANARY_POLICIES,
+    DEFAULT_CANARY_DOMAINS,
+    CanaryDomain,
+    parseCanaryDomains,
+    findCanaryDomain,
+    createCanaryTokenRule,
+} from './canaryTokens';
+export {
+    INFORMATIONAL_CATEGORY,
+    HEURISTIC_ATTRIBUTE,
+    FalsePositiveHeuristic,
+    FALSE_POSITIVE_HEURISTICS,
+    parseFalsePositiveHeuristics,
+    matchFalsePositive,
+    classifyFalsePositive,
+} from './falsePositives';
+export {
+    DEFAULT_WATCH_DEBOUNCE_MS,
+    DEFAULT_WATCH_MAX_WAIT_MS,
+    DEFAULT_DIRECTORY_EVENT_RATE,
+    WatchBatch,
+    ChangeBatcherOptions,
+    ChangeBatcher,
+    createBatchFilter,
+    watchTree,
+} from './watchMode';
+export {
+    DEFAULT_MIN_STRING_LENGTH,
+    BINARY_OFFSET_ATTRIBUTE,
+    ExecutableFormat,
+    BinaryString,
+    detectExecutableFormat,
+    isGoBinary,
+    extractStrings,
+    stringByteOffset,
+} from './binaryStrings';
+export { PatchFile, parsePatch, patchedContent } from './patchScan';
+export { LanguageOptions, createLanguageExclusion, applyLanguageOptions } from './languageOptions';
+export { PROSE_EXTENSIONS, Token, tokenize, tokenKindAt, isProseFile } from './genericTokenizer';
+export {
+    FindingPredicate,
+    FilterOperator,
+    FILTER_FIELDS,
+    compileFilter,
+    resolveFilter,
+    applyFilter,
+} from './filterExpression';
+export {
+    API_ATTRIBUTE,
+    ENDPOINT_ATTRIBUTE,
+    UNKNOWN_ENDPOINT_RULE,
+    ApiSpec,
+    ApiMatch,
+    ApiCatalog,
+    parseOpenApiSpec,
+    createOpenApiRule,
+} from './openApi';
+export {
+    DEPRECATED_ENDPOINT_RULE,
+    REPLACEMENT_ATTRIBUTE,
+    SUNSET_ATTRIBUTE,
+    DeprecatedEndpoint,
+    DeprecationMatch,
+    DeprecationRegistry,
+    createDeprecationRule,
+} from './deprecations';
+export {
+    DEFAULT_ENVIRONMENT_ALIASES,
+    EnvironmentAnalysisOptions,
+    EnvironmentEndpoint,
+    EnvironmentService,
+    analyzeEnvironments,
+} from './environments';
+export {
+    DEFAULT_TREND_STORE,
+    TrendSnapshot,
+    TrendFormat,
+    TrendStore,
+    createTrendSnapshot,
+    formatTrendSeries,
+} from './trendStore';
+export {
+    IncreaseThreshold,
+    IncreaseAlert,
+    parseIncreaseThreshold,
+    checkIncrease,
+    loadBaselineCount,
+    formatIncreaseAlert,
+} from './increaseAlert';
+export { InterruptSignal, INTERRUPT_EXIT_CODES, ScanInterrupt, handleInterrupts } from './interrupt';
+export {
+    JsonSchema,
+    SchemaName,
+    SCHEMA_NAMES,
+    CONFIG_SCHEMA,
+    REPORT_SCHEMA,
+    getSchema,
+    validateSchema,
+} from './schema';
+export { OWNER_ATTRIBUTE, CODEOWNERS_LOCATIONS, CodeOwnersRule, CodeOwners, parseCodeOwners } from './codeOwners';
+export { UNGROUPED, CATEGORY_GROUPING, FindingGroup, groupFindings } from './findingGroups';
+export {
+    UNOWNED_TEAM,
+    DEFAULT_WORST_OFFENDERS,
+    TeamOffender,
+    TeamRollup,
+    TeamRollupOptions,
+    createTeamResolver,
+    buildTeamRollup,
+} from './teamRollup';
+export {
+    ComparisonStatus,
+    COMPARISON_STATUSES,
+    ComparedFinding,
+    HtmlReportOptions,
+    compareFindings,
+    createHtmlReport,
+    escapeHtml,
+} from './htmlReport';
+export {
+    ESCALATION_ATTRIBUTE,
+    SeverityEscalation,
+    escalateSeverity,
+    getEscalationLevels,
+    applySeverityEscalation,
+} from './severityEscalation';
+export {
+    INTRODUCED_ATTRIBUTE,
+    INTRODUCED_COMMIT_ATTRIBUTE,
+    BlameLine,
+    parseBlamePorcelain,
+    blameFile,
+    annotateIntroduced,
+    getIntroducedDate,
+} from './gitBlame';
+export {
+    QUARANTINE_ATTRIBUTE,
+    CREDENTIALS_REASON,
+    DEFAULT_QUARANTINE_FEEDS,
+    QuarantinePolicy,
+    QuarantineSummary,
+    hasCredentials,
+    getQuarantineReasons,
+    splitQuarantined,
+    summarizeQuarantine,
+    writeQuarantineReport,
+} from './quarantine';
+export {
+    DEFAULT_DUPLICATE_MIN_FILES,
+    DuplicateEndpoint,
+    normalizeEndpoint,
+    findDuplicateEndpoints,
+} from './duplicateEndpoints';
+export { PortUsage, getNonStandardPort, buildPortInventory } from './portInventory';
+export { ExtensionCoverage, LanguageCoverage, buildLanguageCoverage } from './coverage';
+export {
+    EstimatedTotal,
+    SAMPLE_CONFIDENCE,
+    SampleEstimate,
+    estimateTotals,
+    formatSampleEstimate,
+    parseSampleRate,
+    selectSample,
+} from './sampling';
+export {
+    Quickfix,
+    QuickfixKind,
+    QuickfixOptions,
+    SUPPRESSION_MARKER,
+    TextEdit,
+    createPatchset,
+    dropSuppressed,
+    formatUnifiedDiff,
+    planQuickfix,
+} from './quickfix';
+export {
+    BINARY_SNIFF_LENGTH,
+    SKIP_REASONS,
+    SkipReason,
+    SkippedFile,
+    isBinaryContent,
+    countSkipReasons,
+    formatSkipSummary,
+} from './skipDiagnostics';
+export {
+    DEFAULT_CHUNK_SIZE_MB,
+    SEGMENT_OVERLAP,
+    FileSegment,
+    SegmentMatch,
+    SegmentScan,
+    planSegments,
+    readSegment,
+    scanSegment,
+    stitchSegments,
+} from './segmentedScan';
+export { BUILT_IN_CATEGORIES, CategoryRule, CategoryRules } from './categoryRules';
+export { hostForms, toAsciiHost, toUnicodeHost } from './idn';
+export { SCHEME_POLICY_RULE, SchemePolicyEntry, createSchemePolicyRule } from './schemePolicy';
+export { DIRECT_EGRESS, ProbeResult, ReachabilityEntry, probeUrl, buildReachabilityMatrix } from './reachability';
+export {
+    JIRA_TOKEN_ENV,
+    FINGERPRINT_LABEL_PREFIX,
+    JiraConfig,
+    JiraFinding,
+    JiraSyncResult,
+    selectJiraFindings,
+    fingerprintLabel,
+    createIssueFields,
+    syncJiraIssues,
+} from './jira';
+export {
+    WEBHOOK_SECRET_ENV,
+    WEBHOOK_SIGNATURE_HEADER,
+    LIFECYCLE_EVENT_TYPES,
+    LifecycleEventType,
+    LifecycleEvent,
+    LifecyclePayload,
+    WebhookConfig,
+    computeLifecycleEvents,
+    recordLifecycle,
+    postLifecycleEvents,
+    formatLifecycleCounts,
+} from './lifecycleEvents';
+export {
+    SMTP_PASSWORD_ENV,
+    EmailConfig,
+    EmailAttachment,
+    EmailMessage,
+    formatEmailSummary,
+    createReportEmail,
+    buildMimeMessage,
+    sendEmail,
+} from './emailNotifier';
+export {
+    DEFAULT_NOTIFICATION_LOG,
+    NotificationEntry,
+    NotificationLog,
+    notificationState,
+    parseDedupeWindow,
+} from './notificationDedupe';
+export {
+    DEFAULT_TRIAGE_STORE,
+    TRIAGE_ATTRIBUTE,
+    TRIAGE_STATES,
+    TriageState,
+    TriageEntry,
+    TriageSummary,
+    TriageStore,
+    parseTriageState,
+    applyTriage,
+} from './triage';
+export {
+    DATA_BUNDLE_CACHE_ENTRY,
+    DEFAULT_TLD_SOURCE,
+    FEED_ATTRIBUTE,
+    SHORTENER_FEED,
+    DataBundleContent,
+    DataBundle,
+    HostData,
+    parseList,
+    readListSource,
+    pullDataBundle,
+    signDataBundle,
+    verifyDataBundle,
+    importDataBundle,
+} from './dataBundle';
+export {
+    CACHE_DIR_ENV,
+    CACHE_LOCK_FILE,
+    DEFAULT_LOCK_TIMEOUT_MS,
+    STALE_LOCK_MS,
+    CacheEntry,
+    CacheInfo,
+    CacheDirectory,
+    resolveCacheDir,
+} from './cacheDir';
+export {
+    DEFAULT_PROFILE,
+    PROFILE_HEADER,
+    SCAN_ID_HEADER,
+    DEFAULT_MAX_BODY_BYTES,
+    DEFAULT_CACHE_SIZE,
+    ScanRequest,
+    ServerOptions,
+    ScanServer,
+    contentHash,
+    loadServerProfiles,
+} from './server';
+export {
+    DEFAULT_SCAN_STORE,
+    DEFAULT_SCAN_RETENTION,
+    DEFAULT_PAGE_SIZE,
+    MAX_PAGE_SIZE,
+    ScanSummary,
+    StoredScan,
+    FindingQuery,
+    FindingPage,
+    ScanStore,
+    parseFindingQuery,
+    queryFindings,
+} from './scanStore';
+export { CodeScope, SCOPE_ATTRIBUTE, classifyCodeScope, forScope } from './codeScope';
+export {
+    PROBLEM_MATCHER,
+    PROBLEM_MATCHER_OWNER,
+    SUMMARY_ROW_LIMIT,
+    FAIL_ON_VALUES,
+    FailOn,
+    ActionInputs,
+    readActionInputs,
+    formatProblemLines,
+    shouldFail,
+    formatStepSummary,
+    writeStepSummary,
+    writeStepOutputs,
+    writeProblemMatcher,
+} from './githubAction';
+export { BUILT_IN_PROFILES, PROFILE_NAMES, Profile, ProfileName, ProfileOptions, getProfile } from './profiles';
 export { Logger, NullLogger, ConsoleLogger, ResultsOnlyLogger } from './logger';
 
 import { URLDetector } from './urlDetector';
@@ -0,0 +1,79 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { EventEmitter } from 'events';
+import { Logger } from './logger';
+
+/** Signals that interrupt a scan */
+export type InterruptSignal = 'SIGINT' | 'SIGTERM';
+
+/** Exit code after writing partial results, following the shell convention of 128 + signal number */
+export const INTERRUPT_EXIT_CODES: Record<InterruptSignal, number> = {
+    SIGINT: 130,
+    SIGTERM: 143,
+};
+
+const INTERRUPT_SIGNALS: InterruptSignal[] = ['SIGINT', 'SIGTERM'];
+
+/**
+ * Tracks SIGINT and SIGTERM during a scan.
+ */
+export interface ScanInterrupt {
+    /** Aborted on the first signal */
+    signal: AbortSignal;
+    /** The first signal received, or null while the scan runs undisturbed */
+    received(): InterruptSignal | null;
+    /** Removes the signal handlers */
+    dispose(): void;
+}
+
+/**
+ * Installs SIGINT and SIGTERM handlers for a scan. The first signal aborts the returned signal, so the
+ * scan finishes the files in progress and the findings collected so far can be written; a second
+ * signal exits immediately with the code of the first.
+ *
+ * @param logger Logger for the interruption warning
+ * @param target Emitter of the signals (default: process)
+ * @param exit Called on the second signal (default: process.exit)
+ * @returns The interrupt state
+ */
+export function handleInterrupts(
+    logger: Logger,
+    target: EventEmitter = process,
+    exit: (code: number) => void = code => process.exit(code),
+): ScanInterrupt {
+    const controller = new AbortController();
+    let first: InterruptSignal | null = null;
+
+    const handlers = INTERRUPT_SIGNALS.map(name => {
+        const handler = () => {
+            if (first) {
+                exit(INTERRUPT_EXIT_CODES[first]);
+                return;
+            }
+            first = name;
+            logger.warn(`Received ${name}: finishing files in progress and writing partial results`);
+            logger.warn('Send the signal again to exit immediately');
+            controller.abort();
+        };
+        target.on(name, handler);
+        return { name, handler };
+    });
+
+    return {
+        signal: controller.signal,
+        received: () => first,
+        dispose: () => handlers.forEach(({ name, handler }) => target.removeListener(name, handler)),
+    };
+}
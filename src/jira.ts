@@ -0,0 +1,196 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { DEFAULT_HTTP_CLIENT, HttpClient } from './httpClient';
+import { TOOL_NAME } from './report';
+import { Violation } from './ruleEngine';
+import { TRIAGE_ATTRIBUTE } from './triage';
+import { FileResult, URLMatch } from './urlFilter';
+
+/** Environment variable holding the Jira API token */
+export const JIRA_TOKEN_ENV = 'URL_DETECTOR_JIRA_TOKEN';
+
+/** Prefix of the label that ties an issue to a finding fingerprint */
+export const FINGERPRINT_LABEL_PREFIX = `${TOOL_NAME}-fp-`;
+
+/** Number of fingerprint labels looked up per search request */
+const SEARCH_BATCH_SIZE = 50;
+
+/** Triage states whose findings never get an issue */
+const DISMISSED_STATES = ['accepted-risk', 'false-positive'];
+
+/**
+ * Where and how to open Jira issues for findings.
+ */
+export interface JiraConfig {
+    /** Base URL of the Jira instance (e.g., https://example.atlassian.net) */
+    url: string;
+    /** Key of the project issues are created in */
+    project: string;
+    /** Issue type (default: Bug) */
+    issueType?: string;
+    /** Additional labels for every issue */
+    labels?: string[];
+    /** Account for basic authentication (Jira Cloud); a bearer token is used when omitted (Jira Data Center) */
+    user?: string;
+}
+
+/**
+ * An error-severity finding to open an issue for.
+ */
+export interface JiraFinding {
+    file: string;
+    finding: URLMatch;
+    /** Error-severity violations of the finding */
+    violations: Violation[];
+}
+
+/**
+ * Outcome of syncing findings to Jira.
+ */
+export interface JiraSyncResult {
+    /** Keys of the issues created */
+    created: string[];
+    /** Fingerprints that already had an issue */
+    existing: string[];
+}
+
+/**
+ * Selects the findings that should have an issue: findings with an error-severity violation and a
+ * fingerprint, excluding findings triaged as accepted-risk or false-positive. Findings sharing a
+ * fingerprint are reported once.
+ *
+ * @param results Scan results
+ * @returns Findings to open issues for
+ */
+export function selectJiraFindings(results: FileResult[]): JiraFinding[] {
+    const selected = new Map<string, JiraFinding>();
+    for (const result of results) {
+        for (const finding of result.urls) {
+            const violations = (finding.violations || []).filter(violation => violation.severity === 'error');
+            const triage = finding.attributes && finding.attributes[TRIAGE_ATTRIBUTE];
+            if (violations.length === 0 || !finding.fingerprint || DISMISSED_STATES.includes(triage || '')) continue;
+
+            if (!selected.has(finding.fingerprint)) {
+                selected.set(finding.fingerprint, { file: result.file, finding, violations });
+            }
+        }
+    }
+    return Array.from(selected.values());
+}
+
+/**
+ * Returns the label that identifies the issue of a finding.
+ *
+ * @param fingerprint Fingerprint of the finding
+ * @returns The label
+ */
+export function fingerprintLabel(fingerprint: string): string {
+    return `${FINGERPRINT_LABEL_PREFIX}${fingerprint}`;
+}
+
+/**
+ * Builds the fields of the issue for a finding, with its location, violations, and remediation hints.
+ *
+ * @param item The finding
+ * @param config Jira configuration
+ * @returns Fields for the Jira create issue API
+ */
+export function createIssueFields(item: JiraFinding, config: JiraConfig): Record<string, unknown> {
+    const { file, finding, violations } = item;
+    const description = [
+        `${TOOL_NAME} found a URL that violates an error-severity rule.`,
+        '',
+        `*File:* ${file}`,
+        `*Line:* ${finding.line}`,
+        `*URL:* {noformat}${finding.url}{noformat}`,
+        `*Fingerprint:* ${finding.fingerprint}`,
+        '',
+        '*Violations:*',
+        ...violations.map(violation => `* ${violation.rule}: ${violation.message}`),
+        '',
+        '*Remediation:*',
+        '* Address the violations above and rescan; the finding disappears from the report once fixed.',
+        `* If the URL is intended, record the decision with \`${TOOL_NAME} triage set accepted-risk ` +
+            `${finding.fingerprint} --note "..."\` so it is not reported again.`,
+    ];
+
+    return {
+        project: { key: config.project },
+        issuetype: { name: config.issueType || 'Bug' },
+        summary: `${violations[0].rule}: ${finding.url} in ${file}:${finding.line}`.substring(0, 255),
+        description: description.join('\n'),
+        labels: [TOOL_NAME, ...(config.labels || []), fingerprintLabel(finding.fingerprint!)],
+    };
+}
+
+/**
+ * Opens a Jira issue for every new error-severity finding. Issues are labelled with the finding
+ * fingerprint, and findings that already have an issue (open or closed) are skipped, so running
+ * after every scan never creates duplicates.
+ *
+ * @param results Scan results with fingerprints assigned
+ * @param config Jira configuration
+ * @param token API token (default: the URL_DETECTOR_JIRA_TOKEN environment variable)
+ * @param client Network stack to call Jira with (default: the global fetch)
+ * @returns Created issue keys and the fingerprints that already had one
+ * @throws {Error} When Jira rejects a request
+ */
+export async function syncJiraIssues(
+    results: FileResult[],
+    config: JiraConfig,
+    token: string = process.env[JIRA_TOKEN_ENV] || '',
+    client: HttpClient = DEFAULT_HTTP_CLIENT,
+): Promise<JiraSyncResult> {
+    const baseUrl = config.url.replace(/\/+$/, '');
+    const headers: Record<string, string> = {
+        'Content-Type': 'application/json',
+        Accept: 'application/json',
+        Authorization: config.user
+            ? `Basic ${Buffer.from(`${config.user}:${token}`).toString('base64')}`
+            : `Bearer ${token}`,
+    };
+
+    const request = async (method: string, apiPath: string, body: unknown): Promise<any> => {
+        const response = await client.fetch(`${baseUrl}${apiPath}`, { method, headers, body: JSON.stringify(body) });
+        if (!response.ok) {
+            throw new Error(`Jira ${method} ${apiPath} failed: HTTP ${response.status} ${await response.text()}`);
+        }
+        return response.json();
+    };
+
+    const findings = selectJiraFindings(results);
+    const existing = new Set<string>();
+    for (let i = 0; i < findings.length; i += SEARCH_BATCH_SIZE) {
+        const batch = findings.slice(i, i + SEARCH_BATCH_SIZE);
+        const labels = batch.map(item => fingerprintLabel(item.finding.fingerprint!));
+        const jql = `project = "${config.project}" AND labels in (${labels.map(label => `"${label}"`).join(', ')})`;
+        const page = await request('POST', '/rest/api/2/search', { jql, fields: ['labels'], maxResults: 1000 });
+        for (const issue of page.issues || []) {
+            const issueLabels: string[] = (issue.fields && issue.fields.labels) || [];
+            issueLabels
+                .filter(label => label.startsWith(FINGERPRINT_LABEL_PREFIX))
+                .forEach(label => existing.add(label.substring(FINGERPRINT_LABEL_PREFIX.length)));
+        }
+    }
+
+    const created: string[] = [];
+    for (const item of findings) {
+        if (existing.has(item.finding.fingerprint!)) continue;
+        const issue = await request('POST', '/rest/api/2/issue', { fields: createIssueFields(item, config) });
+        created.push(issue.key);
+    }
+
+    return { created, existing: findings.map(item => item.finding.fingerprint!).filter(fp => existing.has(fp)) };
+}
@@ -13,7 +13,15 @@
  */
 
 import { Logger, NullLogger } from './logger';
+import * as fs from 'fs';
 import * as path from 'path';
+import Parser from 'tree-sitter';
+
+/**
+ * Manifest written next to the compiled code by slim executable builds, listing the grammar modules
+ * that were embedded. Full builds have no manifest and load every grammar.
+ */
+export const EMBEDDED_GRAMMARS_FILE = 'embedded-grammars.json';
 
 /**
  * Configuration for a programming language parser with Tree-sitter integration.
@@ -33,6 +41,40 @@ export interface LanguageConfig {
     filenames?: string[];
 }
 
+/**
+ * The grammar module and version a language is parsed with.
+ */
+export interface LanguageGrammar {
+    module: string;
+    version: string;
+}
+
+/**
+ * A grammar that loads but that the installed tree-sitter core library cannot use, typically because it
+ * was generated for a newer or older ABI. Such grammars are left unused instead of crashing the parser.
+ */
+export interface GrammarIssue {
+    language: string;
+    module: string;
+    /** Package version of the grammar ('unknown' when it cannot be read) */
+    version: string;
+    /** Why the core library rejected the grammar */
+    error: string;
+}
+
+/**
+ * Describes a grammar issue in one line, naming the grammar and core library versions.
+ *
+ * @param issue The issue
+ * @returns The diagnostic
+ */
+export function formatGrammarIssue(issue: GrammarIssue): string {
+    return (
+        `${issue.module} ${issue.version} (${issue.language}) is not compatible with tree-sitter ` +
+        `${LanguageManager.getTreeSitterVersion()}: ${issue.error}`
+    );
+}
+
 /**
  * Manages programming language configurations and Tree-sitter parser loading.
  *
@@ -72,6 +114,39 @@ export class LanguageManager {
     private languages: Map<string, unknown>;
     private languageConfigs: LanguageConfig[];
     private logger: Logger;
+    private embeddedGrammars: Set<string> | null;
+    private grammarIssues: GrammarIssue[] = [];
+
+    /**
+     * Gets a copy of the default language configurations, as used by the build to map language names
+     * to grammar modules.
+     *
+     * @returns Array of the default language configurations
+     */
+    public static getDefaultLanguages(): LanguageConfig[] {
+        return LanguageManager.DEFAULT_LANGUAGES.map(config => ({ ...config }));
+    }
+
+    /**
+     * Gets the package version of the tree-sitter core library grammars are checked against.
+     *
+     * @returns The version, or 'unknown' when it cannot be read
+     */
+    public static getTreeSitterVersion(): string {
+        return readModuleVersion('tree-sitter');
+    }
+
+    /**
+     * Reads the grammar modules embedded in a slim executable build.
+     *
+     * @param directory Directory containing the manifest (default: the directory of this module)
+     * @returns Embedded grammar module names, or null for a full build
+     */
+    public static readEmbeddedGrammars(directory: string = __dirname): Set<string> | null {
+        const manifest = path.join(directory, EMBEDDED_GRAMMARS_FILE);
+        if (!fs.existsSync(manifest)) return null;
+        return new Set(JSON.parse(fs.readFileSync(manifest, 'utf8')) as string[]);
+    }
 
     /**
      * Creates a new LanguageManager instance with optional custom configuration.
@@ -99,14 +174,22 @@ export class LanguageManager {
         this.languages = new Map();
         this.logger = logger || NullLogger;
         this.languageConfigs = customLanguages ? [...customLanguages] : [...LanguageManager.DEFAULT_LANGUAGES];
+        this.embeddedGrammars = LanguageManager.readEmbeddedGrammars();
         this.loadLanguages();
     }
 
     private loadLanguages(): void {
         // Clear existing languages
         this.languages.clear();
+        this.grammarIssues = [];
 
         for (const config of this.languageConfigs) {
+            // Default grammars left out of a slim build are expected to be missing; files fall back to regex
+            if (this.embeddedGrammars && !this.embeddedGrammars.has(config.module) && isDefaultGrammar(config.module)) {
+                this.logger.debug(`Skipping ${config.name} parser, not embedded in this build`);
+                continue;
+            }
+
             try {
                 // eslint-disable-next-line @typescript-eslint/no-require-imports
                 const languageModule = require(config.module);
@@ -121,6 +204,16 @@ export class LanguageManager {
                     language = languageModule;
                 }
 
+                // A grammar built for another ABI crashes at parse time, so reject it while loading
+                const incompatibility = checkCompatibility(language);
+                if (incompatibility) {
+                    const version = readModuleVersion(config.module);
+                    const issue = { language: config.name, module: config.module, version, error: incompatibility };
+                    this.grammarIssues.push(issue);
+                    this.logger.warn(`${formatGrammarIssue(issue)}; ${config.name} files will not be parsed`);
+                    continue;
+                }
+
                 this.languages.set(config.name, language);
 
                 for (const ext of config.extensions) {
@@ -247,6 +340,56 @@ export class LanguageManager {
         return [...this.languageConfigs];
     }
 
+    /**
+     * Gets the package version of every grammar module that loaded successfully.
+     *
+     * @returns Versions by module name ('unknown' when the package version cannot be read)
+     *
+     * @example
+     * ```typescript
+     * manager.getGrammarVersions(); // { 'tree-sitter-javascript': '0.23.1', ... }
+     * ```
+     */
+    public getGrammarVersions(): Record<string, string> {
+        const versions: Record<string, string> = {};
+        for (const config of this.languageConfigs) {
+            if (this.languages.has(config.name)) {
+                versions[config.module] = readModuleVersion(config.module);
+            }
+        }
+        return versions;
+    }
+
+    /**
+     * Gets the grammar each loaded language is parsed with, for reports.
+     *
+     * @returns Grammar module and version by language name
+     *
+     * @example
+     * ```typescript
+     * manager.getLanguageGrammars(); // { javascript: { module: 'tree-sitter-javascript', version: '0.23.1' }, ... }
+     * ```
+     */
+    public getLanguageGrammars(): Record<string, LanguageGrammar> {
+        const grammars: Record<string, LanguageGrammar> = {};
+        for (const config of this.languageConfigs) {
+            if (this.languages.has(config.name)) {
+                grammars[config.name] = { module: config.module, version: readModuleVersion(config.module) };
+            }
+        }
+        return grammars;
+    }
+
+    /**
+     * Gets the grammars that loaded but were rejected by the tree-sitter core library. Their languages
+     * are handled like languages without a grammar.
+     *
+     * @returns The issues, in configuration order
+     */
+    public getGrammarIssues(): GrammarIssue[] {
+        return [...this.grammarIssues];
+    }
+
     /**
      * Retrieves the names of all supported languages.
      *
@@ -369,3 +512,39 @@ export class LanguageManager {
         return 'unknown';
     }
 }
+
+function readModuleVersion(module: string): string {
+    try {
+        // Packages with an exports map may not expose package.json, so look for it next to the entry point
+        let directory = path.dirname(require.resolve(module));
+        while (directory !== path.dirname(directory)) {
+            const manifest = path.join(directory, 'package.json');
+            if (fs.existsSync(manifest)) {
+                const pkg = JSON.parse(fs.readFileSync(manifest, 'utf8'));
+                if (pkg.name === module) return pkg.version || 'unknown';
+            }
+            directory = path.dirname(directory);
+        }
+    } catch {
+        // Not resolvable, e.g. a grammar object registered without a package
+    }
+    return 'unknown';
+}
+
+/**
+ * Tries a grammar with the core library, which checks its ABI version when a parser adopts it.
+ *
+ * @returns Why the grammar was rejected, or null when it is usable
+ */
+function checkCompatibility(language: unknown): string | null {
+    try {
+        new Parser().setLanguage(language as Parser.Language);
+        return null;
+    } catch (error: unknown) {
+        return error instanceof Error ? error.message : String(error);
+    }
+}
+
+function isDefaultGrammar(module: string): boolean {
+    return LanguageManager.getDefaultLanguages().some(config => config.module === module);
+}
@@ -0,0 +1,110 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { blankMarkdownCode, isMarkdownFile } from './docLinks';
+import { URLMatch } from './urlFilter';
+
+/**
+ * Extraction settings that only make sense for one language, so a polyglot repository can, for
+ * example, skip Markdown code samples while still scanning Go struct tags. Every setting defaults to
+ * true, which reports URLs everywhere as before.
+ *
+ * @example
+ * ```typescript
+ * { go: { goGenerate: false }, markdown: { codeBlocks: false }, html: { dataAttributes: false } }
+ * ```
+ */
+export interface LanguageOptions {
+    go?: {
+        /** Report URLs in struct tags, such as XML namespaces in `xml:"..."` (default: true) */
+        structTags?: boolean;
+        /** Report URLs in //go:generate directives (default: true) */
+        goGenerate?: boolean;
+    };
+    markdown?: {
+        /** Report URLs in fenced code blocks and inline code spans (default: true) */
+        codeBlocks?: boolean;
+    };
+    html?: {
+        /** Report URLs in data-* attribute values (default: true) */
+        dataAttributes?: boolean;
+    };
+}
+
+/** A struct tag: a raw string of key:"value" pairs after a field declaration */
+const GO_STRUCT_TAG = /[ \t]`(?:[A-Za-z_][\w.-]*:"(?:[^"\\\n]|\\.)*"[ \t]*)+`/g;
+const GO_GENERATE = /^[ \t]*\/\/go:generate\b[^\n]*/gm;
+/** A data-* attribute; group 1 is the value, quoted or not */
+const HTML_DATA_ATTRIBUTE = /\sdata-[\w.:-]*\s*=\s*("[^"]*"|'[^']*'|[^\s"'=<>`]+)/gi;
+
+/**
+ * Finds the parts of a file whose URLs the language options turn off.
+ *
+ * @param content Content of the file
+ * @param filePath Path of the file, used to recognize Markdown, which has no parser
+ * @param language Language of the file
+ * @param options The language options
+ * @returns Test for whether an offset lies in an excluded part, or null when nothing is excluded
+ */
+export function createLanguageExclusion(
+    content: string,
+    filePath: string,
+    language: string,
+    options: LanguageOptions,
+): ((offset: number) => boolean) | null {
+    const spans: Array<[number, number]> = [];
+    const addSpans = (pattern: RegExp, group: number = 0) => {
+        for (const match of content.matchAll(pattern)) {
+            const start = match.index! + match[0].length - match[group].length;
+            spans.push([start, start + match[group].length]);
+        }
+    };
+
+    if (language === 'go' && options.go) {
+        if (options.go.structTags === false) addSpans(GO_STRUCT_TAG);
+        if (options.go.goGenerate === false) addSpans(GO_GENERATE);
+    }
+    if (language === 'html' && options.html && options.html.dataAttributes === false) {
+        addSpans(HTML_DATA_ATTRIBUTE, 1);
+    }
+    if (isMarkdownFile(filePath) && options.markdown && options.markdown.codeBlocks === false) {
+        // Code is blanked out with spaces, so an offset that changed lies in code
+        const blanked = blankMarkdownCode(content);
+        if (blanked !== content) return offset => blanked[offset] !== content[offset];
+    }
+
+    if (spans.length === 0) return null;
+    return offset => spans.some(([start, end]) => offset >= start && offset < end);
+}
+
+/**
+ * Drops the URLs the language options turn off for a file.
+ *
+ * @param urls URLs found in the file
+ * @param content Content of the file
+ * @param filePath Path of the file
+ * @param language Language of the file
+ * @param options The language options
+ * @returns The URLs outside excluded parts
+ */
+export function applyLanguageOptions(
+    urls: URLMatch[],
+    content: string,
+    filePath: string,
+    language: string,
+    options: LanguageOptions,
+): URLMatch[] {
+    const excluded = createLanguageExclusion(content, filePath, language, options);
+    return excluded ? urls.filter(url => !excluded(url.start)) : urls;
+}
@@ -0,0 +1,285 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import pLimit from 'p-limit';
+import { normalizeUrl } from './fingerprint';
+import { DEFAULT_HTTP_CLIENT, HttpClient } from './httpClient';
+import { Rule, Violation } from './ruleEngine';
+import { FileResult, URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Category assigned to URLs found in license headers */
+export const LICENSE_CATEGORY = 'license';
+
+/** Attribute holding the SPDX identifier a license URL was matched to */
+export const LICENSE_ATTRIBUTE = 'license';
+
+/**
+ * Canonical URLs of common licenses, keyed by SPDX identifier. The SPDX license list page
+ * (https://spdx.org/licenses/<id>.html) is accepted for every identifier.
+ */
+export const CANONICAL_LICENSE_URLS: Record<string, string[]> = {
+    'Apache-2.0': [
+        'https://www.apache.org/licenses/LICENSE-2.0',
+        'https://www.apache.org/licenses/LICENSE-2.0.txt',
+        'https://www.apache.org/licenses/LICENSE-2.0.html',
+    ],
+    MIT: ['https://opensource.org/licenses/MIT', 'https://opensource.org/license/mit'],
+    'BSD-2-Clause': ['https://opensource.org/licenses/BSD-2-Clause', 'https://opensource.org/license/bsd-2-clause'],
+    'BSD-3-Clause': ['https://opensource.org/licenses/BSD-3-Clause', 'https://opensource.org/license/bsd-3-clause'],
+    ISC: ['https://opensource.org/licenses/ISC', 'https://opensource.org/license/isc-license-txt'],
+    'GPL-2.0': ['https://www.gnu.org/licenses/old-licenses/gpl-2.0.html', 'https://www.gnu.org/licenses/gpl-2.0.txt'],
+    'GPL-3.0': ['https://www.gnu.org/licenses/gpl-3.0.html', 'https://www.gnu.org/licenses/gpl-3.0.txt'],
+    'LGPL-2.1': ['https://www.gnu.org/licenses/old-licenses/lgpl-2.1.html'],
+    'LGPL-3.0': ['https://www.gnu.org/licenses/lgpl-3.0.html', 'https://www.gnu.org/licenses/lgpl-3.0.txt'],
+    'AGPL-3.0': ['https://www.gnu.org/licenses/agpl-3.0.html', 'https://www.gnu.org/licenses/agpl-3.0.txt'],
+    'MPL-2.0': ['https://mozilla.org/MPL/2.0', 'https://www.mozilla.org/en-US/MPL/2.0'],
+    'EPL-2.0': ['https://www.eclipse.org/legal/epl-2.0', 'https://www.eclipse.org/legal/epl-v20.html'],
+    'CC0-1.0': ['https://creativecommons.org/publicdomain/zero/1.0'],
+    Unlicense: ['https://unlicense.org'],
+};
+
+/** License index pages cited by headers of several licenses (e.g., "see <https://www.gnu.org/licenses/>") */
+const LICENSE_INDEX_URLS = ['https://www.gnu.org/licenses', 'https://spdx.org/licenses'];
+
+/** Hosts that publish license texts; unrecognized URLs on these hosts are likely mutated license links */
+const LICENSE_HOSTS = [
+    'apache.org',
+    'opensource.org',
+    'gnu.org',
+    'mozilla.org',
+    'eclipse.org',
+    'creativecommons.org',
+    'unlicense.org',
+    'spdx.org',
+];
+
+/** Statuses of servers that do not support HEAD requests; the URL is then requested with GET */
+const HEAD_UNSUPPORTED_STATUSES = [405, 501];
+
+/** Only the start of a file is searched for a license header */
+const MAX_HEADER_LINES = 100;
+
+/** Lines that can be part of a comment block, across the languages the detector supports */
+const COMMENT_LINE_PATTERN = /^\s*(\/\/|#|\/\*|\*|--|<!--|;|%|rem\b)/i;
+
+const LICENSE_KEYWORD_PATTERN = /\b(licen[cs]e[sd]?|copyright|spdx-license-identifier)\b/i;
+
+/**
+ * Character range of a license header within a file.
+ */
+export interface LicenseHeader {
+    /** Offset of the first character of the header */
+    start: number;
+    /** Offset just past the last character of the header */
+    end: number;
+    /** SPDX identifier declared with `SPDX-License-Identifier`, if any */
+    spdxId?: string;
+}
+
+/**
+ * Finds the license header of a file: the leading comment block (after an optional shebang or XML
+ * declaration), provided it mentions a license or copyright.
+ *
+ * @param content Content of the file
+ * @returns The header range, or null when the file has no recognizable license header
+ */
+export function findLicenseHeader(content: string): LicenseHeader | null {
+    const lines = content.split('\n').slice(0, MAX_HEADER_LINES);
+    let offset = 0;
+    let start = -1;
+    let end = -1;
+    let blockTerminator: string | null = null;
+
+    for (const line of lines) {
+        const lineEnd = offset + line.length + 1;
+        const trimmed = line.trim();
+
+        if (blockTerminator) {
+            // Inside a block comment every line belongs to the header, marker or not
+            end = lineEnd;
+            if (trimmed.includes(blockTerminator)) blockTerminator = null;
+        } else if (start === -1 && (trimmed === '' || trimmed.startsWith('#!') || trimmed.startsWith('<?'))) {
+            // Leading blank lines, shebangs, and XML or PHP declarations precede the header
+        } else if (COMMENT_LINE_PATTERN.test(line)) {
+            if (start === -1) start = offset + line.length - line.trimStart().length;
+            end = lineEnd;
+            if (trimmed.startsWith('/*') && !trimmed.includes('*/', 2)) blockTerminator = '*/';
+            if (trimmed.startsWith('<!--') && !trimmed.includes('-->')) blockTerminator = '-->';
+        } else if (trimmed !== '' || start !== -1) {
+            break;
+        }
+
+        offset = lineEnd;
+    }
+
+    if (start === -1) return null;
+
+    const text = content.substring(start, Math.min(end, content.length));
+    if (!LICENSE_KEYWORD_PATTERN.test(text)) return null;
+
+    const spdxMatch = text.match(/SPDX-License-Identifier:\s*([\w.+-]+)/);
+    return { start, end: Math.min(end, content.length), spdxId: spdxMatch ? spdxMatch[1] : undefined };
+}
+
+/**
+ * Selects the findings located inside a file's license header and places them in the 'license' category.
+ *
+ * @param findings All findings detected in the file
+ * @param content Content of the file
+ * @returns Findings inside the license header
+ */
+export function selectLicenseHeaderUrls(findings: URLMatch[], content: string): URLMatch[] {
+    const header = findLicenseHeader(content);
+    if (!header) return [];
+
+    return findings
+        .filter(finding => finding.start >= header.start && finding.start < header.end)
+        .map(finding => {
+            finding.category = LICENSE_CATEGORY;
+            if (header.spdxId) setFindingAttribute(finding, 'spdxId', header.spdxId);
+            const spdxId = matchCanonicalLicenseUrl(finding.url);
+            if (spdxId) setFindingAttribute(finding, LICENSE_ATTRIBUTE, spdxId);
+            return finding;
+        });
+}
+
+/**
+ * Matches a URL against the canonical license URLs, ignoring the scheme, a leading `www.`,
+ * trailing slashes, and trailing sentence punctuation.
+ *
+ * @param url URL found in a license header
+ * @returns The SPDX identifier of the matching license, or null if the URL is not canonical
+ */
+export function matchCanonicalLicenseUrl(url: string): string | null {
+    const key = canonicalKey(url);
+    for (const [spdxId, urls] of Object.entries(CANONICAL_LICENSE_URLS)) {
+        const spdxUrls = [`https://spdx.org/licenses/${spdxId}`, `https://spdx.org/licenses/${spdxId}.html`];
+        if ([...urls, ...spdxUrls].some(candidate => canonicalKey(candidate) === key)) {
+            return spdxId;
+        }
+    }
+    return null;
+}
+
+/**
+ * Creates the rule that verifies license header URLs. URLs on license publishing hosts that match no
+ * canonical URL are reported as mutated, and canonical URLs for a different license than the declared
+ * `SPDX-License-Identifier` as mismatched.
+ *
+ * @returns The license URL verification rule
+ */
+export function createLicenseUrlRule(): Rule {
+    return {
+        id: 'license-url',
+        description: 'License header URLs must match the canonical URL of the declared license',
+        evaluate: (finding: URLMatch): Violation[] => {
+            if (finding.category !== LICENSE_CATEGORY) return [];
+
+            const attributes = finding.attributes || {};
+            const spdxId = attributes[LICENSE_ATTRIBUTE];
+            const declared = attributes.spdxId;
+            if (spdxId) {
+                if (declared && !sameLicense(declared, spdxId)) {
+                    const message = `License URL ${finding.url} is for ${spdxId} but the header declares ${declared}`;
+                    return [{ rule: 'license-url-mismatch', severity: 'error', message }];
+                }
+                return [];
+            }
+
+            const key = canonicalKey(finding.url);
+            if (LICENSE_INDEX_URLS.some(indexUrl => canonicalKey(indexUrl) === key)) {
+                return [];
+            }
+
+            if (isLicenseHost(finding.url)) {
+                const message = `License URL ${finding.url} does not match any canonical license URL`;
+                return [{ rule: 'license-url-mutated', severity: 'warning', message }];
+            }
+            return [];
+        },
+    };
+}
+
+/**
+ * Requests every license URL in the results and reports those that fail or respond with an error
+ * status as dead. Each distinct URL is requested once, with HEAD, or with GET from servers that do
+ * not support HEAD.
+ *
+ * @param results Scan results containing license findings
+ * @param timeoutMs Timeout per request in milliseconds (default: 10000)
+ * @param client Network stack to make the requests with (default: the global fetch)
+ * @param concurrency Maximum number of requests in flight (default: 10)
+ */
+export async function checkLicenseLinks(
+    results: FileResult[],
+    timeoutMs: number = 10000,
+    client: HttpClient = DEFAULT_HTTP_CLIENT,
+    concurrency: number = 10,
+): Promise<void> {
+    const findings = results.flatMap(result => result.urls).filter(finding => finding.category === LICENSE_CATEGORY);
+    const statusByUrl = new Map<string, Promise<string | null>>();
+    const limit = pLimit(concurrency);
+
+    for (const finding of findings) {
+        const url = stripTrailingPunctuation(finding.url);
+        if (!statusByUrl.has(url)) statusByUrl.set(url, limit(() => probeUrl(url, timeoutMs, client)));
+    }
+
+    for (const finding of findings) {
+        const failure = await statusByUrl.get(stripTrailingPunctuation(finding.url))!;
+        if (failure) {
+            const violation: Violation = {
+                rule: 'license-url-dead',
+                severity: 'error',
+                message: `License URL ${finding.url} is unreachable: ${failure}`,
+            };
+            finding.violations = [...(finding.violations || []), violation];
+        }
+    }
+}
+
+async function probeUrl(url: string, timeoutMs: number, client: HttpClient): Promise<string | null> {
+    try {
+        let response = await client.fetch(url, { method: 'HEAD', signal: AbortSignal.timeout(timeoutMs) });
+        if (HEAD_UNSUPPORTED_STATUSES.includes(response.status)) {
+            response = await client.fetch(url, { method: 'GET', signal: AbortSignal.timeout(timeoutMs) });
+            // Only the status matters, so the page is not downloaded
+            if (response.body) await response.body.cancel().catch(() => undefined);
+        }
+        return response.status >= 400 ? `HTTP ${response.status}` : null;
+    } catch (error: any) {
+        return error.message;
+    }
+}
+
+function stripTrailingPunctuation(url: string): string {
+    return url.replace(/[.,;:!?)>\]'"]+$/, '');
+}
+
+function canonicalKey(url: string): string {
+    return normalizeUrl(stripTrailingPunctuation(url))
+        .replace(/^[a-z][a-z0-9+.-]*:\/\//, '')
+        .replace(/^www\./, '')
+        .replace(/\/+$/, '');
+}
+
+function isLicenseHost(url: string): boolean {
+    const host = canonicalKey(url).split(/[/?#:]/)[0];
+    return LICENSE_HOSTS.some(licenseHost => host === licenseHost || host.endsWith(`.${licenseHost}`));
+}
+
+function sameLicense(declared: string, spdxId: string): boolean {
+    // GPL-family identifiers carry -only/-or-later suffixes in SPDX expressions
+    return declared.replace(/-(only|or-later)$|\+$/, '') === spdxId;
+}
@@ -0,0 +1,217 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import { DEFAULT_HTTP_CLIENT, HttpClient } from './httpClient';
+import { TOOL_NAME, TOOL_VERSION } from './report';
+import { ScanStore } from './scanStore';
+import { Finding } from './sinks';
+import { FileResult } from './urlFilter';
+
+/** Environment variable holding the secret webhook payloads are signed with */
+export const WEBHOOK_SECRET_ENV = 'URL_DETECTOR_WEBHOOK_SECRET';
+
+/** Header carrying the HMAC-SHA256 signature of the payload, as 'sha256=<hex>' */
+export const WEBHOOK_SIGNATURE_HEADER = 'X-Url-Detector-Signature';
+
+/** Lifecycle event types, in the order events are listed */
+export const LIFECYCLE_EVENT_TYPES = ['new', 'resolved', 'reopened'] as const;
+
+/**
+ * How a finding changed since the previous scan: first seen, gone, or seen again after it was gone.
+ */
+export type LifecycleEventType = (typeof LIFECYCLE_EVENT_TYPES)[number];
+
+/**
+ * A finding that changed state between two scans.
+ */
+export interface LifecycleEvent {
+    type: LifecycleEventType;
+    fingerprint: string;
+    /** The finding; for resolved findings, as found by the previous scan */
+    finding: Finding;
+}
+
+/**
+ * The events of one scan, as posted to webhooks.
+ */
+export interface LifecyclePayload {
+    tool: string;
+    version: string;
+    /** Policy profile the scans ran with */
+    profile: string;
+    /** Identifier of the scan in the scan store */
+    scanId: string;
+    /** Identifier of the scan it was compared with */
+    previousScanId: string;
+    /** ISO timestamp of the scan */
+    createdAt: string;
+    events: LifecycleEvent[];
+}
+
+/**
+ * A URL that lifecycle events are posted to.
+ *
+ * @example
+ * ```json
+ * { "url": "https://hooks.example.com/url-detector", "events": ["new", "reopened"] }
+ * ```
+ */
+export interface WebhookConfig {
+    url: string;
+    /** Event types posted (default: all) */
+    events?: LifecycleEventType[];
+    /** Additional request headers, e.g. for authentication */
+    headers?: Record<string, string>;
+}
+
+/**
+ * Computes lifecycle events between two scans, by finding fingerprint. A finding in the current scan
+ * but not the previous one is new, unless an earlier scan had it, in which case it is reopened; a
+ * finding in the previous scan but not the current one is resolved. Findings without a fingerprint are
+ * ignored, and findings sharing a fingerprint count once.
+ *
+ * @param current Results of the current scan
+ * @param previous Results of the previous scan
+ * @param earlier Whether a finding absent from the previous scan was in an earlier one (default: never)
+ * @returns Events ordered by type, then as the findings appear in the results
+ */
+export function computeLifecycleEvents(
+    current: FileResult[],
+    previous: FileResult[],
+    earlier: (fingerprint: string) => boolean = () => false,
+): LifecycleEvent[] {
+    const currentFindings = indexFindings(current);
+    const previousFindings = indexFindings(previous);
+
+    const events: LifecycleEvent[] = [];
+    for (const [fingerprint, finding] of currentFindings) {
+        if (previousFindings.has(fingerprint)) continue;
+        events.push({ type: earlier(fingerprint) ? 'reopened' : 'new', fingerprint, finding });
+    }
+    for (const [fingerprint, finding] of previousFindings) {
+        if (!currentFindings.has(fingerprint)) events.push({ type: 'resolved', fingerprint, finding });
+    }
+    return events.sort((a, b) => LIFECYCLE_EVENT_TYPES.indexOf(a.type) - LIFECYCLE_EVENT_TYPES.indexOf(b.type));
+}
+
+/**
+ * Stores a scan and computes its lifecycle events against the previous stored scan of the same
+ * profile. Findings missing from the previous scan are looked up in older stored scans to tell
+ * reopened findings from new ones. The first stored scan of a profile is the baseline and has no
+ * events.
+ *
+ * @param store The scan store
+ * @param profile Policy profile the scan ran with
+ * @param results Results of the scan
+ * @param now Time of completion (default: now)
+ * @returns The events, or null for a baseline scan
+ */
+export async function recordLifecycle(
+    store: ScanStore,
+    profile: string,
+    results: FileResult[],
+    now: Date = new Date(),
+): Promise<LifecyclePayload | null> {
+    const [previousSummary, ...olderSummaries] = await store.list(profile);
+    const previous = previousSummary ? await store.load(previousSummary.id) : null;
+    const summary = await store.save(profile, results, now);
+    if (!previous) return null;
+
+    // Older scans are only read when some finding is missing from the previous one
+    const inPrevious = fingerprintsOf(previous.results);
+    const missing = new Set([...fingerprintsOf(results)].filter(fingerprint => !inPrevious.has(fingerprint)));
+    const seenEarlier = new Set<string>();
+    for (const older of olderSummaries) {
+        if (missing.size === 0) break;
+        const scan = await store.load(older.id);
+        if (!scan) continue;
+        for (const fingerprint of fingerprintsOf(scan.results)) {
+            if (missing.delete(fingerprint)) seenEarlier.add(fingerprint);
+        }
+    }
+
+    return {
+        tool: TOOL_NAME,
+        version: TOOL_VERSION,
+        profile,
+        scanId: summary.id,
+        previousScanId: previous.id,
+        createdAt: summary.createdAt,
+        events: computeLifecycleEvents(results, previous.results, fingerprint => seenEarlier.has(fingerprint)),
+    };
+}
+
+/**
+ * Posts lifecycle events to a webhook as JSON. Only the event types the webhook subscribes to are sent,
+ * and nothing is sent when none of them occurred. With a secret, the body is signed with HMAC-SHA256
+ * in the X-Url-Detector-Signature header, so receivers can verify it came from the scanner.
+ *
+ * @param payload The events of a scan
+ * @param webhook The webhook
+ * @param secret Secret to sign the body with (default: the URL_DETECTOR_WEBHOOK_SECRET environment variable)
+ * @param client Network stack to post with (default: the global fetch)
+ * @returns Number of events posted
+ * @throws {Error} When the webhook does not answer with a 2xx status
+ */
+export async function postLifecycleEvents(
+    payload: LifecyclePayload,
+    webhook: WebhookConfig,
+    secret: string = process.env[WEBHOOK_SECRET_ENV] || '',
+    client: HttpClient = DEFAULT_HTTP_CLIENT,
+): Promise<number> {
+    const events = webhook.events
+        ? payload.events.filter(event => webhook.events!.includes(event.type))
+        : payload.events;
+    if (events.length === 0) return 0;
+
+    const body = JSON.stringify({ ...payload, events });
+    const headers: Record<string, string> = { ...webhook.headers, 'Content-Type': 'application/json' };
+    if (secret) {
+        headers[WEBHOOK_SIGNATURE_HEADER] = `sha256=${crypto.createHmac('sha256', secret).update(body).digest('hex')}`;
+    }
+
+    const response = await client.fetch(webhook.url, { method: 'POST', headers, body });
+    if (!response.ok) {
+        throw new Error(`Webhook ${webhook.url} failed: HTTP ${response.status} ${await response.text()}`);
+    }
+    return events.length;
+}
+
+/**
+ * Counts events per type for logs, e.g. '2 new, 1 resolved, 0 reopened'.
+ *
+ * @param events The events
+ * @returns The counts
+ */
+export function formatLifecycleCounts(events: LifecycleEvent[]): string {
+    const counts = LIFECYCLE_EVENT_TYPES.map(type => `${events.filter(event => event.type === type).length} ${type}`);
+    return counts.join(', ');
+}
+
+function indexFindings(results: FileResult[]): Map<string, Finding> {
+    const findings = new Map<string, Finding>();
+    for (const result of results) {
+        for (const finding of result.urls) {
+            if (finding.fingerprint && !findings.has(finding.fingerprint)) {
+                findings.set(finding.fingerprint, { ...finding, file: result.file });
+            }
+        }
+    }
+    return findings;
+}
+
+function fingerprintsOf(results: FileResult[]): Set<string> {
+    return new Set(indexFindings(results).keys());
+}
@@ -0,0 +1,192 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import { runGit } from './gitMetadata';
+import { LanguageGrammar } from './languageManager';
+import { DetectorOptions } from './options';
+import { Rule } from './ruleEngine';
+
+// eslint-disable-next-line @typescript-eslint/no-require-imports
+const packageJson = require('../package.json');
+
+/**
+ * Options left out of the config hash: they only affect how results are written, or may hold credentials.
+ */
+export const UNHASHED_OPTIONS = [
+    'format',
+    'outputFile',
+    'outputEncoding',
+    'asciiJson',
+    'csvFormulaGuard',
+    'csvFingerprints',
+    'resultsOnly',
+    'failOnError',
+    'concurrency',
+    'email',
+    'jira',
+    'webhooks',
+    'egressProxies',
+    'teams',
+];
+
+/**
+ * Options left out of the effective config recorded in reports, because they may hold credentials.
+ */
+export const CREDENTIAL_OPTIONS = ['email', 'jira', 'webhooks', 'egressProxies'];
+
+/**
+ * Options that decide which findings raise violations, hashed into the policy hash instead of the config hash.
+ */
+export const POLICY_OPTIONS = [
+    'severityEscalation',
+    'categoryRules',
+    'schemePolicy',
+    'redirectParams',
+    'canaryDomains',
+    'canaryPolicy',
+    'falsePositiveHeuristics',
+    'goImportPolicy',
+    'openApiSpecs',
+    'deprecationRegistry',
+    'rulePlugins',
+];
+
+/**
+ * Reproducibility metadata included in every report, so audit evidence shows exactly what was
+ * checked and with which rules.
+ */
+export interface ScanManifest {
+    /** Version of url-detector */
+    toolVersion: string;
+    /** Version of each loaded tree-sitter grammar, by module name */
+    grammars: Record<string, string>;
+    /** Version of the tree-sitter core library; absent in reports of earlier versions */
+    treeSitterVersion?: string;
+    /** Grammar module and version each parsed language used; absent in reports of earlier versions */
+    languageGrammars?: Record<string, LanguageGrammar>;
+    /** SHA-256 of the options that decide what is scanned */
+    configHash: string;
+    /** SHA-256 of the registered rules and the options that decide which findings raise violations */
+    policyHash: string;
+    /** Number of files scanned */
+    fileCount: number;
+    /** Whether the scan was interrupted, so the report only covers the files scanned before */
+    partial: boolean;
+    /** Git commit checked out in the working directory, or null outside a git repository */
+    commit: string | null;
+    /** When the manifest was created (ISO 8601) */
+    createdAt: string;
+    /** Built-in profile the scan ran with, if any */
+    profile?: string;
+    /** Options the scan ran with, from effectiveConfig(); absent in reports of earlier versions */
+    effectiveConfig?: Record<string, unknown>;
+}
+
+/**
+ * Serializes a value as JSON with object keys sorted, so equal values always produce the same text.
+ *
+ * @param value The value
+ * @returns Canonical JSON text
+ */
+export function canonicalJson(value: unknown): string {
+    return JSON.stringify(value, (_key, nested) => {
+        if (!nested || typeof nested !== 'object' || Array.isArray(nested)) return nested;
+        return Object.fromEntries(Object.keys(nested).sort().map(key => [key, nested[key]]));
+    });
+}
+
+function sha256(text: string): string {
+    return crypto.createHash('sha256').update(text).digest('hex');
+}
+
+function pickOptions(options: DetectorOptions, include: (key: string) => boolean): Record<string, unknown> {
+    return Object.fromEntries(Object.entries(options).filter(([key]) => include(key)));
+}
+
+/**
+ * Hashes the options that decide what is scanned: patterns, filters, and the opt-in detectors.
+ * Output settings and sections that may hold credentials are left out.
+ *
+ * @param options Detector options
+ * @returns SHA-256 hex digest
+ */
+export function hashConfig(options: DetectorOptions): string {
+    const config = pickOptions(options, key => !UNHASHED_OPTIONS.includes(key) && !POLICY_OPTIONS.includes(key));
+    return sha256(canonicalJson(config));
+}
+
+/**
+ * The options a scan runs with once the defaults, profile, config file, and flags are resolved, as
+ * plain JSON data with sorted keys, so reviewers can see exactly which policy produced a report.
+ * The email, jira, webhooks, and egressProxies sections are left out, as they may hold credentials.
+ *
+ * @param options Detector options
+ * @returns The effective configuration
+ */
+export function effectiveConfig(options: DetectorOptions): Record<string, unknown> {
+    return JSON.parse(canonicalJson(pickOptions(options, key => !CREDENTIAL_OPTIONS.includes(key))));
+}
+
+/**
+ * Hashes the registered rules (ids, descriptions, and source of their evaluate functions) and the
+ * severity escalation, category rules, Go import policy, and OpenAPI documents.
+ *
+ * @param rules Registered rules in evaluation order
+ * @param options Detector options
+ * @returns SHA-256 hex digest
+ */
+export function hashPolicy(rules: Rule[], options: DetectorOptions): string {
+    const policy = {
+        rules: rules.map(rule => ({ id: rule.id, description: rule.description, source: rule.evaluate.toString() })),
+        options: pickOptions(options, key => POLICY_OPTIONS.includes(key)),
+    };
+    return sha256(canonicalJson(policy));
+}
+
+/**
+ * Creates the manifest of a scan.
+ *
+ * @param options Detector options
+ * @param rules Registered rules
+ * @param grammars Version of each loaded grammar, by module name
+ * @param fileCount Number of files scanned
+ * @param partial Whether the scan was interrupted
+ * @param cwd Working directory whose git commit is recorded (default: process.cwd())
+ * @returns Promise resolving to the manifest
+ */
+export async function createScanManifest(
+    options: DetectorOptions,
+    rules: Rule[],
+    grammars: Record<string, string>,
+    fileCount: number,
+    partial: boolean = false,
+    cwd: string = process.cwd(),
+): Promise<ScanManifest> {
+    const commit = await runGit(cwd, ['rev-parse', 'HEAD'])
+        .then(stdout => stdout.trim())
+        .catch(() => null);
+
+    return {
+        toolVersion: packageJson.version,
+        grammars,
+        configHash: hashConfig(options),
+        policyHash: hashPolicy(rules, options),
+        fileCount,
+        partial,
+        commit,
+        createdAt: new Date().toISOString(),
+        effectiveConfig: effectiveConfig(options),
+    };
+}
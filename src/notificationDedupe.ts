@@ -0,0 +1,169 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { writeAtomically } from './atomicWrite';
+import { getFindingSeverity } from './ruleEngine';
+import { TRIAGE_ATTRIBUTE } from './triage';
+import { FileResult, URLMatch } from './urlFilter';
+
+/** Default location of the notification log, relative to the working directory */
+export const DEFAULT_NOTIFICATION_LOG = '.url-detector/notifications.json';
+
+/** Version of the notification log file layout */
+const NOTIFICATION_LOG_VERSION = 1;
+
+const DURATION_UNITS: Record<string, number> = {
+    m: 60 * 1000,
+    h: 60 * 60 * 1000,
+    d: 24 * 60 * 60 * 1000,
+    w: 7 * 24 * 60 * 60 * 1000,
+};
+
+/**
+ * When a finding was last notified, and in which state.
+ */
+export interface NotificationEntry {
+    /** Fingerprint of the finding */
+    fingerprint: string;
+    /** State of the finding when notified, from notificationState() */
+    state: string;
+    /** ISO timestamp of the last notification */
+    notifiedAt: string;
+}
+
+interface NotificationLogFile {
+    version: number;
+    entries: NotificationEntry[];
+}
+
+/**
+ * Parses a dedupe window such as '30m', '24h', '7d', or '2w'.
+ *
+ * @param text The window
+ * @returns The window in milliseconds
+ * @throws {Error} When the text is not a positive whole number followed by m, h, d, or w
+ */
+export function parseDedupeWindow(text: string): number {
+    const match = text.trim().match(/^(\d+)([mhdw])$/);
+    if (!match || parseInt(match[1], 10) === 0) {
+        throw new Error(`Invalid dedupe window: ${text}. Use minutes, hours, days, or weeks (30m, 24h, 7d, 2w)`);
+    }
+    return parseInt(match[1], 10) * DURATION_UNITS[match[2]];
+}
+
+/**
+ * The state of a finding that decides whether it is worth notifying again: its triage state and its
+ * highest violation severity. A finding is notified again as soon as either changes.
+ *
+ * @param finding The finding
+ * @returns The state, e.g. 'open/error'
+ */
+export function notificationState(finding: URLMatch): string {
+    const triage = (finding.attributes && finding.attributes[TRIAGE_ATTRIBUTE]) || 'open';
+    return `${triage}/${getFindingSeverity(finding) || 'none'}`;
+}
+
+/**
+ * Notifications sent per finding fingerprint, stored in a JSON file, so a persistent finding notifies
+ * the team once per dedupe window instead of on every scheduled scan.
+ */
+export class NotificationLog {
+    private filePath: string;
+    private windowMs: number;
+
+    /**
+     * @param windowMs How long a notified finding stays quiet while its state is unchanged
+     * @param filePath Path of the log file (default: .url-detector/notifications.json)
+     */
+    constructor(windowMs: number, filePath: string = DEFAULT_NOTIFICATION_LOG) {
+        this.windowMs = windowMs;
+        this.filePath = filePath;
+    }
+
+    /**
+     * Loads every entry in the log.
+     *
+     * @returns Entries keyed by fingerprint, empty when the log does not exist yet
+     * @throws {Error} When the log file is not a valid notification log
+     */
+    public async load(): Promise<Map<string, NotificationEntry>> {
+        let text: string;
+        try {
+            text = await fs.promises.readFile(this.filePath, 'utf8');
+        } catch (error: any) {
+            if (error.code === 'ENOENT') return new Map();
+            throw error;
+        }
+
+        const data = JSON.parse(text) as NotificationLogFile;
+        if (!data || !Array.isArray(data.entries)) {
+            throw new Error(`Invalid notification log ${this.filePath}: missing entries array`);
+        }
+        return new Map(data.entries.map(entry => [entry.fingerprint, entry]));
+    }
+
+    /**
+     * Selects the findings due for notification: findings without a fingerprint, findings never
+     * notified, findings whose state changed since, and findings last notified before the window.
+     *
+     * @param results Scan results with fingerprints assigned
+     * @param now Current time (default: now)
+     * @returns Copies of the results holding only the due findings; files without any are left out
+     */
+    public async selectDue(results: FileResult[], now: Date = new Date()): Promise<FileResult[]> {
+        const entries = await this.load();
+        const isDue = (finding: URLMatch): boolean => {
+            const entry = finding.fingerprint ? entries.get(finding.fingerprint) : undefined;
+            return (
+                !entry ||
+                entry.state !== notificationState(finding) ||
+                now.getTime() - Date.parse(entry.notifiedAt) >= this.windowMs
+            );
+        };
+
+        return results
+            .map(result => ({ ...result, urls: result.urls.filter(isDue) }))
+            .filter(result => result.urls.length > 0);
+    }
+
+    /**
+     * Records that findings were notified. Entries whose window has passed are pruned, so the log
+     * does not grow with findings that were fixed long ago.
+     *
+     * @param results The findings that were notified
+     * @param now Time of the notification (default: now)
+     */
+    public async record(results: FileResult[], now: Date = new Date()): Promise<void> {
+        const entries = await this.load();
+        for (const [fingerprint, entry] of entries) {
+            if (now.getTime() - Date.parse(entry.notifiedAt) >= this.windowMs) entries.delete(fingerprint);
+        }
+        for (const result of results) {
+            for (const finding of result.urls) {
+                if (!finding.fingerprint) continue;
+                entries.set(finding.fingerprint, {
+                    fingerprint: finding.fingerprint,
+                    state: notificationState(finding),
+                    notifiedAt: now.toISOString(),
+                });
+            }
+        }
+
+        const sorted = Array.from(entries.values()).sort((a, b) => a.fingerprint.localeCompare(b.fingerprint));
+        const data: NotificationLogFile = { version: NOTIFICATION_LOG_VERSION, entries: sorted };
+
+        await writeAtomically(this.filePath, JSON.stringify(data, null, 2));
+    }
+}
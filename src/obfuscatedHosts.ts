@@ -0,0 +1,144 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { FileContext, Rule, Violation } from './ruleEngine';
+import { URLMatch } from './urlFilter';
+
+/** Id of the rule flagging URLs whose host is written to mislead the reader */
+export const OBFUSCATED_HOST_RULE = 'obfuscated-host';
+
+/**
+ * Kinds of obfuscated host notation:
+ * - decimal-ip: an IPv4 address written as one number or with parts left out (e.g., 3232235777, 127.1)
+ * - octal-ip: an IPv4 address with zero-prefixed parts, which browsers read as octal (e.g., 0300.0250.1.1)
+ * - hex-ip: an IPv4 address with 0x-prefixed parts (e.g., 0xc0a80101, 0xc0.0xa8.1.1)
+ * - userinfo-spoofing: a host name in the userinfo, in front of the real host (e.g., trusted.com@evil.com)
+ */
+export type ObfuscatedHostKind = 'decimal-ip' | 'octal-ip' | 'hex-ip' | 'userinfo-spoofing';
+
+/**
+ * An obfuscated host found in a URL.
+ */
+export interface ObfuscatedHost {
+    kind: ObfuscatedHostKind;
+    /** The host as written in the URL, or the userinfo for userinfo-spoofing */
+    written: string;
+    /** The host the URL actually points to, with IPv4 addresses in dotted-decimal notation */
+    actual: string;
+}
+
+const AUTHORITY = /^(?:[a-z][a-z0-9+.-]*:)?\/\/([^/?#\\]*)/i;
+const HOST_NAME = /^(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z][a-z0-9-]*[a-z0-9]\.?$/i;
+
+/**
+ * Finds the ways a URL hides the host it points to. A numeric host is read the way browsers and
+ * inet_aton() read it; a dotted quad of plain decimal parts is the normal notation and is not reported.
+ * Userinfo is reported only when its user name looks like a host name, since ordinary credentials are
+ * handled by quarantine.
+ *
+ * @param url The URL to inspect
+ * @returns The obfuscations found, userinfo first
+ */
+export function findObfuscatedHosts(url: string): ObfuscatedHost[] {
+    const authority = AUTHORITY.exec(url);
+    if (!authority) return [];
+
+    const found: ObfuscatedHost[] = [];
+    const at = authority[1].lastIndexOf('@');
+    const userinfo = at >= 0 ? authority[1].substring(0, at) : '';
+    const hostPort = authority[1].substring(at + 1);
+    const host = hostPort.replace(/:\d*$/, '');
+
+    const ipv4 = parseNumericHost(host);
+    const actual = ipv4 ? ipv4.address : host.toLowerCase();
+
+    const user = decodeUserinfo(userinfo.split(':')[0]);
+    if (HOST_NAME.test(user) || parseNumericHost(user)) {
+        found.push({ kind: 'userinfo-spoofing', written: userinfo, actual });
+    }
+    if (ipv4 && ipv4.kind) {
+        found.push({ kind: ipv4.kind, written: host, actual });
+    }
+    return found;
+}
+
+/**
+ * Creates the rule flagging URLs whose host is written as a decimal, octal, or hexadecimal number, or
+ * preceded by a host name in the userinfo. These notations make a URL look like it points somewhere
+ * other than it does and are common in phishing and SSRF payloads, so each raises an 'error' naming
+ * the host the URL really points to.
+ *
+ * @returns The obfuscated host rule
+ */
+export function createObfuscatedHostRule(): Rule {
+    return {
+        id: OBFUSCATED_HOST_RULE,
+        description: 'URLs must not disguise their host with numeric IP notations or a host name in the userinfo',
+        evaluate: (finding: URLMatch, _file: FileContext): Violation[] =>
+            findObfuscatedHosts(finding.url).map(obfuscation => ({
+                rule: OBFUSCATED_HOST_RULE,
+                severity: 'error',
+                message: describeObfuscation(obfuscation),
+            })),
+    };
+}
+
+/**
+ * Parses a host made only of numeric parts, as the WHATWG URL parser does.
+ *
+ * @returns The dotted-decimal address and the kind of notation (null for a plain dotted quad), or null
+ * when the host is not a valid numeric IPv4 address
+ */
+function parseNumericHost(host: string): { address: string; kind: ObfuscatedHostKind | null } | null {
+    const parts = host.replace(/\.$/, '').split('.');
+    if (parts.length > 4 || !parts.every(part => /^(?:0x[0-9a-f]*|0[0-7]*|[1-9]\d*)$/i.test(part))) return null;
+
+    const values = parts.map(part =>
+        /^0x/i.test(part) ? parseInt(part.substring(2) || '0', 16) : parseInt(part, /^0\d/.test(part) ? 8 : 10),
+    );
+
+    // The last part fills the bytes the written parts leave out
+    const last = values[values.length - 1];
+    if (values.slice(0, -1).some(value => value > 255) || last >= 256 ** (5 - values.length)) return null;
+    let address = last;
+    values.slice(0, -1).forEach((value, index) => (address += value * 256 ** (3 - index)));
+
+    const octets = [24, 16, 8, 0].map(shift => Math.floor(address / 2 ** shift) % 256);
+    let kind: ObfuscatedHostKind | null = null;
+    if (parts.some(part => /^0x/i.test(part))) kind = 'hex-ip';
+    else if (parts.some(part => /^0\d/.test(part))) kind = 'octal-ip';
+    else if (parts.length < 4) kind = 'decimal-ip';
+    return { address: octets.join('.'), kind };
+}
+
+function decodeUserinfo(text: string): string {
+    try {
+        return decodeURIComponent(text);
+    } catch {
+        return text;
+    }
+}
+
+function describeObfuscation({ kind, written, actual }: ObfuscatedHost): string {
+    switch (kind) {
+        case 'userinfo-spoofing':
+            return `${written}@ is userinfo, not the host; the URL points to ${actual}`;
+        case 'decimal-ip':
+            return `${written} is the IP address ${actual} in decimal notation`;
+        case 'octal-ip':
+            return `${written} is the IP address ${actual} in octal notation`;
+        case 'hex-ip':
+            return `${written} is the IP address ${actual} in hexadecimal notation`;
+    }
+}
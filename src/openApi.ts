@@ -0,0 +1,209 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { REALTIME_RPC_CATEGORY } from './realtimeEndpoints';
+import { FileContext, Rule, Violation } from './ruleEngine';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Attribute holding the name of the API a finding calls */
+export const API_ATTRIBUTE = 'api';
+
+/** Attribute holding the path template of the endpoint a finding calls (e.g., '/users/{id}') */
+export const ENDPOINT_ATTRIBUTE = 'endpoint';
+
+/** Id of the rule flagging calls to endpoints that no spec declares */
+export const UNKNOWN_ENDPOINT_RULE = 'openapi-unknown-endpoint';
+
+/**
+ * An API described by an OpenAPI document: where it is served and which paths it declares.
+ */
+export interface ApiSpec {
+    /** Service name: the x-service-name extension, or else info.title, or else the file name */
+    name: string;
+    /** Absolute server URLs, with variables replaced by their defaults */
+    servers: string[];
+    /** Declared path templates (e.g., '/users/{id}') */
+    paths: string[];
+}
+
+/**
+ * Where a URL belongs among the loaded APIs.
+ */
+export interface ApiMatch {
+    /** Name of the API whose server the URL points at */
+    api: string;
+    /** The matching path template, or null when the API declares no such path */
+    endpoint: string | null;
+}
+
+interface CompiledServer {
+    api: string;
+    origin: string;
+    basePath: string;
+    paths: Array<{ template: string; pattern: RegExp }>;
+}
+
+/**
+ * Reads an OpenAPI 3 or Swagger 2 document in JSON.
+ *
+ * @param document The parsed document
+ * @param source File the document was read from, used as the name when the document has no title
+ * @returns The API's name, servers, and paths
+ * @throws {Error} When the document is neither OpenAPI 3 nor Swagger 2
+ */
+export function parseOpenApiSpec(document: any, source: string): ApiSpec {
+    if (!document || typeof document !== 'object' || (!document.openapi && !document.swagger)) {
+        throw new Error(`${source} is not an OpenAPI document`);
+    }
+
+    const info = document.info || {};
+    const name = document['x-service-name'] || info['x-service-name'] || info.title || source;
+    const paths = Object.keys(document.paths || {});
+
+    let servers: string[];
+    if (document.swagger) {
+        const schemes: string[] = document.schemes || ['https'];
+        servers = document.host ? schemes.map(scheme => `${scheme}://${document.host}${document.basePath || ''}`) : [];
+    } else {
+        servers = (document.servers || []).map((server: any) => expandServerVariables(server));
+    }
+
+    // Relative server URLs cannot be tied to a host, so they never match
+    return { name, servers: servers.filter(server => /^[a-z][a-z0-9+.-]*:\/\//i.test(server)), paths };
+}
+
+/**
+ * Matches URLs against the servers and paths of a set of OpenAPI documents.
+ */
+export class ApiCatalog {
+    private servers: CompiledServer[];
+
+    /**
+     * @param specs APIs to match against; the first API whose server matches a URL owns it
+     */
+    constructor(specs: ApiSpec[]) {
+        this.servers = [];
+        for (const spec of specs) {
+            const paths = spec.paths.map(template => ({ template, pattern: compilePathTemplate(template) }));
+            for (const server of spec.servers) {
+                const url = parseUrl(server);
+                if (!url) continue;
+                const basePath = url.pathname.replace(/\/+$/, '');
+                this.servers.push({ api: spec.name, origin: url.origin, basePath, paths });
+            }
+        }
+        // Longer base paths first, so /v2 wins over / on the same host
+        this.servers.sort((a, b) => b.basePath.length - a.basePath.length);
+    }
+
+    /**
+     * Loads OpenAPI documents (JSON) from files.
+     *
+     * @param files Paths of the documents
+     * @returns The catalog of their APIs
+     * @throws {Error} When a file cannot be read or is not an OpenAPI document
+     */
+    public static async load(files: string[]): Promise<ApiCatalog> {
+        const specs: ApiSpec[] = [];
+        for (const file of files) {
+            const text = await fs.promises.readFile(file, 'utf8');
+            let document: unknown;
+            try {
+                document = JSON.parse(text);
+            } catch (error: any) {
+                throw new Error(`Failed to parse OpenAPI document ${file}: ${error.message}`);
+            }
+            specs.push(parseOpenApiSpec(document, file));
+        }
+        return new ApiCatalog(specs);
+    }
+
+    /**
+     * Finds the API and endpoint a URL calls.
+     *
+     * @param url An absolute URL
+     * @returns The API and matching path template, or null when the URL is not served by any loaded API
+     */
+    public match(url: string): ApiMatch | null {
+        const parsed = parseUrl(url);
+        if (!parsed) return null;
+
+        const pathname = parsed.pathname.replace(/\/+$/, '') || '/';
+        let owner: string | null = null;
+        for (const server of this.servers) {
+            if (server.origin !== parsed.origin) continue;
+            if (pathname !== server.basePath && !pathname.startsWith(`${server.basePath}/`)) continue;
+
+            owner = owner || server.api;
+            const rest = pathname.substring(server.basePath.length) || '/';
+            const endpoint = server.paths.find(candidate => candidate.pattern.test(rest));
+            if (endpoint) return { api: server.api, endpoint: endpoint.template };
+        }
+        return owner ? { api: owner, endpoint: null } : null;
+    }
+}
+
+/**
+ * Creates the rule that tags findings with the API and endpoint they call, in the 'api' and
+ * 'endpoint' attributes, and raises a warning for URLs on a known API server whose path no
+ * document declares.
+ *
+ * @param catalog The loaded APIs
+ * @returns The OpenAPI rule
+ */
+export function createOpenApiRule(catalog: ApiCatalog): Rule {
+    return {
+        id: UNKNOWN_ENDPOINT_RULE,
+        description: 'URLs on API servers should call endpoints declared in the OpenAPI documents',
+        evaluate: (finding: URLMatch, _file: FileContext): Violation[] => {
+            if (finding.category && finding.category !== REALTIME_RPC_CATEGORY) return [];
+
+            const match = catalog.match(finding.url);
+            if (!match) return [];
+
+            setFindingAttribute(finding, API_ATTRIBUTE, match.api);
+            if (match.endpoint) {
+                setFindingAttribute(finding, ENDPOINT_ATTRIBUTE, match.endpoint);
+                return [];
+            }
+            const message = `${finding.url} is served by ${match.api} but matches no path in its OpenAPI document`;
+            return [{ rule: UNKNOWN_ENDPOINT_RULE, severity: 'warning', message }];
+        },
+    };
+}
+
+function expandServerVariables(server: any): string {
+    const variables = server.variables || {};
+    return String(server.url || '').replace(/\{([^}]+)\}/g, (placeholder, name) =>
+        variables[name] && variables[name].default !== undefined ? String(variables[name].default) : placeholder,
+    );
+}
+
+function compilePathTemplate(template: string): RegExp {
+    const normalized = template.replace(/\/+$/, '') || '/';
+    const source = normalized
+        .split(/(\{[^}]+\})/)
+        .map(part => (part.startsWith('{') ? '[^/]+' : part.replace(/[.*+?^$()|[\]\\]/g, '\\$&')))
+        .join('');
+    return new RegExp(`^${source}$`);
+}
+
+function parseUrl(url: string): URL | null {
+    try {
+        return new URL(url);
+    } catch {
+        return null;
+    }
+}
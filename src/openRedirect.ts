@@ -0,0 +1,114 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { FileContext, Rule, Violation } from './ruleEngine';
+import { URLMatch } from './urlFilter';
+
+/** Id of the rule flagging URLs that pass a full URL in a redirect parameter */
+export const OPEN_REDIRECT_RULE = 'open-redirect';
+
+/** Query parameters that commonly name where to send the user next */
+export const DEFAULT_REDIRECT_PARAMS = [
+    'callback',
+    'continue',
+    'dest',
+    'destination',
+    'forward',
+    'goto',
+    'next',
+    'redir',
+    'redirect',
+    'redirect_uri',
+    'redirect_url',
+    'return',
+    'return_to',
+    'returnTo',
+    'returnUrl',
+    'target',
+    'url',
+];
+
+/** A value that browsers follow to another host: a scheme followed by '//', or '//' (slashes may be backslashes) */
+const ABSOLUTE_TARGET = /^\s*(?:[a-z][a-z0-9+.-]*:)?[/\\]{2}/i;
+
+/**
+ * A query parameter of a URL that carries a full URL.
+ */
+export interface RedirectParam {
+    /** Name of the parameter as written */
+    name: string;
+    /** The target URL, percent-decoded */
+    target: string;
+}
+
+/**
+ * Finds the redirect parameters of a URL whose values are full URLs. Values are decoded up to twice,
+ * so encoded targets (`?next=https%3A%2F%2Fevil.example`) are found too.
+ *
+ * @param url The URL to inspect
+ * @param params Names of redirect parameters, matched case-insensitively (default: DEFAULT_REDIRECT_PARAMS)
+ * @returns The matching parameters in order
+ */
+export function findRedirectParams(url: string, params: string[] = DEFAULT_REDIRECT_PARAMS): RedirectParam[] {
+    const beforeFragment = url.split('#')[0];
+    const queryStart = beforeFragment.indexOf('?');
+    if (queryStart === -1) return [];
+
+    const names = new Set(params.map(name => name.toLowerCase()));
+    const query = beforeFragment.substring(queryStart + 1);
+    const found: RedirectParam[] = [];
+    for (const pair of query.split('&')) {
+        const separator = pair.indexOf('=');
+        if (separator === -1) continue;
+        const name = decode(pair.substring(0, separator));
+        if (!names.has(name.toLowerCase())) continue;
+
+        const target = decode(decode(pair.substring(separator + 1)));
+        if (ABSOLUTE_TARGET.test(target)) {
+            found.push({ name, target });
+        }
+    }
+    return found;
+}
+
+/**
+ * Creates the rule flagging URLs whose redirect parameters carry a full URL (`?next=https://...`),
+ * a common source of open-redirect bugs when the receiving endpoint redirects without checking the
+ * target. Each such parameter raises a 'warning'.
+ *
+ * @param params Names of redirect parameters, matched case-insensitively (default: DEFAULT_REDIRECT_PARAMS)
+ * @returns The open redirect rule
+ */
+export function createOpenRedirectRule(params: string[] = DEFAULT_REDIRECT_PARAMS): Rule {
+    return {
+        id: OPEN_REDIRECT_RULE,
+        description: 'Redirect parameters should not carry full URLs',
+        evaluate: (finding: URLMatch, _file: FileContext): Violation[] =>
+            findRedirectParams(finding.url, params).map(({ name, target }): Violation => ({
+                rule: OPEN_REDIRECT_RULE,
+                severity: 'warning',
+                message:
+                    `Query parameter ${name} carries the full URL ${target}; ` +
+                    'make sure the endpoint only redirects to allowed hosts',
+            })),
+    };
+}
+
+function decode(text: string): string {
+    try {
+        return decodeURIComponent(text.replace(/\+/g, ' '));
+    } catch {
+        return text;
+    }
+}
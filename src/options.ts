@@ -13,11 +13,36 @@
  */
 
 import * as fs from 'fs';
+import { EmailConfig } from './emailNotifier';
+import { GoImportPolicy } from './goImports';
+import { JiraConfig } from './jira';
+import { WebhookConfig } from './lifecycleEvents';
+import { CONFIG_SCHEMA, validateSchema } from './schema';
+import { SeverityEscalation } from './severityEscalation';
+import { CategoryRule } from './categoryRules';
+import { SchemePolicyEntry } from './schemePolicy';
+import { LanguageOptions } from './languageOptions';
+import { RulePluginConfig } from './pluginSandbox';
+import { DEFAULT_CHUNK_SIZE_MB } from './segmentedScan';
+import { DEFAULT_REDIRECT_PARAMS } from './openRedirect';
+import { CanaryPolicy, parseCanaryDomains } from './canaryTokens';
+import { FalsePositiveHeuristic, parseFalsePositiveHeuristics } from './falsePositives';
+import { DEFAULT_TRIAGE_STORE } from './triage';
+import { DEFAULT_GIT_HISTORY_DEPTH } from './gitMetadata';
+import { BUILT_IN_FORMATS, getSinkFormats } from './sinks';
+import { parseSampleRate } from './sampling';
 
 /**
- * Supported output formats for URL detection results
+ * Supported output formats for URL detection results. Any format registered with registerSink() is
+ * accepted too; `string & {}` keeps editor completion for the built-in names.
  */
-export type OutputFormat = 'table' | 'json' | 'csv';
+export type OutputFormat = 'table' | 'json' | 'csv' | 'ndjson' | 'sarif' | 'patchset' | 'html' | (string & {});
+
+/**
+ * Encodings for output files. 'utf8-bom' and 'utf16le' start with a byte order mark, so
+ * spreadsheet applications detect the encoding of CSV files.
+ */
+export type OutputEncoding = 'utf8' | 'utf8-bom' | 'utf16le';
 
 /**
  * Configuration interface for URL detector options.
@@ -39,6 +64,14 @@ export interface DetectorOptionsConfig {
     format?: OutputFormat;
     /** Path to output file, or null for stdout (default: null) */
     output?: string | null;
+    /** Encoding of the output file (default: 'utf8') */
+    outputEncoding?: OutputEncoding;
+    /** Whether to escape non-ASCII characters in json, ndjson, and sarif output as \\u escapes (default: false) */
+    asciiJson?: boolean;
+    /** Whether to prefix CSV cells starting with =, +, -, @, tab, or carriage return with a quote (default: true) */
+    csvFormulaGuard?: boolean;
+    /** Whether to add a Fingerprint column to CSV output (default: false) */
+    csvFingerprints?: boolean;
 
     /** Whether to output only the results without metadata (default: false) */
     resultsOnly?: boolean;
@@ -46,14 +79,147 @@ export interface DetectorOptionsConfig {
     failOnError?: boolean;
     /** Number of concurrent file processing operations (default: 10) */
     concurrency?: number;
+    /** Size in megabytes above which a file is scanned in concurrent segments; 0 never splits files (default: 64) */
+    chunkSize?: number;
+    /** Size in megabytes above which a file is skipped; 0 scans files of any size (default: 0) */
+    maxFileSize?: number;
+    /** Milliseconds tree-sitter may spend parsing one file before giving up; 0 waits indefinitely (default: 0) */
+    parseTimeout?: number;
+    /** Percentage of files to scan, to estimate the totals of a full scan (e.g., '5%'; default: every file) */
+    sample?: string;
+    /** Seed of the sample; change it to draw different files (default: '') */
+    sampleSeed?: string;
 
     /** Maximum directory depth to scan (default: Infinity) */
     maxDepth?: number;
     /** Whether to use fallback regex patterns when primary detection fails (default: true) */
     fallbackRegex?: boolean;
+    /** Whether files without a parser have strings and comments told apart heuristically (default: true) */
+    genericTokenizer?: boolean;
+    /** Extraction settings for Go, Markdown, and HTML, such as skipping Markdown code blocks (default: {}) */
+    languageOptions?: LanguageOptions;
 
     /** Number of context lines to include around detected URLs (default: 0) */
     context?: number;
+
+    /** Whether to also scan commit messages, tag annotations, and .gitmodules (default: false) */
+    includeGitMetadata?: boolean;
+    /** Most recent commits whose messages includeGitMetadata scans; 0 scans the whole history (default: 1000) */
+    gitHistoryDepth?: number;
+
+    /** Whether to record the date and commit each finding's line was introduced, from git blame (default: false) */
+    gitBlame?: boolean;
+
+    /** Whether to skip generated and minified files instead of tagging their findings (default: false) */
+    skipGenerated?: boolean;
+
+    /** Whether to report the findings of files with identical content, like vendored copies, once (default: false) */
+    collapseDuplicates?: boolean;
+
+    /** Whether to report only license header URLs and verify them against canonical license URLs (default: false) */
+    licenseHeaders?: boolean;
+
+    /** Whether to request license header URLs and report dead links; implies licenseHeaders (default: false) */
+    checkLicenseLinks?: boolean;
+
+    /** Whether to report relative links in Markdown and HTML and validate their targets and anchors (default: false) */
+    validateDocLinks?: boolean;
+
+    /** Whether to report relative URLs and path references in HTML, CSS, and scripts (default: false) */
+    includeRelativeUrls?: boolean;
+
+    /** Whether to report UNC paths and file URLs with drive letters (default: false) */
+    includeWindowsPaths?: boolean;
+
+    /** Whether to suggest Subresource Integrity for CDN scripts and stylesheets in HTML (default: false) */
+    sriAdvisory?: boolean;
+
+    /** Whether to flag double-encoding, overlong UTF-8 encodings, and encoded line breaks in URLs (default: false) */
+    encodingAnomalies?: boolean;
+
+    /** Whether to flag decimal, octal, and hexadecimal IP hosts and host names in the userinfo (default: false) */
+    obfuscatedHosts?: boolean;
+
+    /** Whether to flag URLs whose redirect parameters carry full URLs, as in ?next=https://... (default: false) */
+    openRedirect?: boolean;
+
+    /** Query parameters the open redirect rule checks (default: redirect, next, url, returnUrl, and similar) */
+    redirectParams?: string[];
+
+    /** Whether to flag URLs on canary token hosts, such as canarytokens.com (default: false) */
+    canaryTokens?: boolean;
+
+    /** More canary token domains, each a domain or domain=tripwire|leak; implies canaryTokens (default: []) */
+    canaryDomains?: string[];
+
+    /** Policy of the canary token domains that do not name one, tripwire or leak (default: 'tripwire') */
+    canaryPolicy?: CanaryPolicy;
+
+    /** Heuristics moving likely false positives to the informational category, such as example-domain (default: []) */
+    falsePositiveHeuristics?: FalsePositiveHeuristic[];
+
+    /** Path-based severity shifts applied to violations, e.g. +1 under auth/ (default: []) */
+    severityEscalation?: SeverityEscalation[];
+
+    /** Host-to-category rules for plain URLs; the first matching rule wins (default: []) */
+    categoryRules?: CategoryRule[];
+
+    /** Schemes forbidden on some or all hosts, such as ws:// on production hosts; first match wins (default: []) */
+    schemePolicy?: SchemePolicyEntry[];
+
+    /** Whether to attach the owners from the repository's CODEOWNERS file to each finding (default: false) */
+    codeOwners?: boolean;
+
+    /** Imported data bundle whose TLD list validates hosts and whose feeds tag findings (default: none) */
+    dataBundle?: string;
+
+    /** OpenAPI documents (JSON) whose servers and paths tag findings with their API and endpoint (default: []) */
+    openApiSpecs?: string[];
+
+    /** Registry (JSON) of deprecated URL prefixes with their replacement and sunset date (default: none) */
+    deprecationRegistry?: string;
+
+    /** Hosts replaced in --format patchset fixes, old host to new host (default: {}) */
+    domainMap?: Record<string, string>;
+
+    /** Triage store whose states are carried forward onto findings by fingerprint (default: none) */
+    triageStore?: string;
+
+    /** Whether to drop findings triaged as accepted-risk or false-positive; implies the default triageStore */
+    hideTriaged?: boolean;
+
+    /** Filter expression findings must match to be reported, or the name of one in filters (default: none) */
+    filter?: string;
+
+    /** Named filter expressions, selected with filter (default: {}) */
+    filters?: Record<string, string>;
+
+    /** Email the scan summary to these recipients after the scan (default: no email) */
+    email?: EmailConfig;
+
+    /** Jira instance and project to open issues in for new error-severity findings (default: none) */
+    jira?: JiraConfig;
+
+    /** Webhooks finding lifecycle events (new, resolved, reopened) are posted to after the scan (default: none) */
+    webhooks?: WebhookConfig[];
+
+    /** Egress proxy URLs by environment name for reachability probing; empty means direct (default: direct only) */
+    egressProxies?: Record<string, string>;
+
+    /** Team by path prefix for team rollups; the longest matching prefix wins (default: CODEOWNERS owners) */
+    teams?: Record<string, string>;
+
+    /** Whether to report Go import and module paths and audit them against goImportPolicy (default: false) */
+    auditGoImports?: boolean;
+
+    /** Policy for Go import auditing (default: flag deprecated hosts only) */
+    goImportPolicy?: GoImportPolicy;
+
+    /** Whether to mark Go URLs with the HTTP, gRPC, or WebSocket call they flow into (default: false) */
+    goNetworkCalls?: boolean;
+
+    /** Third-party rule plugins, run in a sandbox with time and memory limits (default: []) */
+    rulePlugins?: RulePluginConfig[];
 }
 
 /**
@@ -81,19 +247,73 @@ export class DetectorOptions {
     public includeNonFqdn: boolean;
     public format: OutputFormat;
     public outputFile: string | null;
+    public outputEncoding: OutputEncoding;
+    public asciiJson: boolean;
+    public csvFormulaGuard: boolean;
+    public csvFingerprints: boolean;
 
     public resultsOnly: boolean;
     public failOnError: boolean;
     public concurrency: number;
+    public chunkSize: number;
+    public maxFileSize: number;
+    public parseTimeout: number;
+    public sample: string | null;
+    public sampleSeed: string;
 
     public maxDepth: number;
     public withLineNumbers: boolean;
     public withFilenames: boolean;
     public relativePaths: boolean;
     public fallbackRegex: boolean;
+    public genericTokenizer: boolean;
+    public languageOptions: LanguageOptions;
 
     public context: number;
 
+    public includeGitMetadata: boolean;
+    public gitHistoryDepth: number;
+    public gitBlame: boolean;
+    public skipGenerated: boolean;
+    public collapseDuplicates: boolean;
+
+    public licenseHeaders: boolean;
+    public checkLicenseLinks: boolean;
+
+    public validateDocLinks: boolean;
+    public includeRelativeUrls: boolean;
+    public includeWindowsPaths: boolean;
+    public sriAdvisory: boolean;
+    public encodingAnomalies: boolean;
+    public obfuscatedHosts: boolean;
+    public openRedirect: boolean;
+    public redirectParams: string[];
+    public canaryTokens: boolean;
+    public canaryDomains: string[];
+    public canaryPolicy: CanaryPolicy;
+    public falsePositiveHeuristics: FalsePositiveHeuristic[];
+    public codeOwners: boolean;
+    public dataBundle: string | null;
+    public openApiSpecs: string[];
+    public deprecationRegistry: string | null;
+    public domainMap: Record<string, string>;
+    public triageStore: string | null;
+    public hideTriaged: boolean;
+    public filter: string | null;
+    public filters: Record<string, string>;
+    public email: EmailConfig | null;
+    public jira: JiraConfig | null;
+    public webhooks: WebhookConfig[];
+    public egressProxies: Record<string, string> | null;
+    public teams: Record<string, string> | null;
+    public severityEscalation: SeverityEscalation[];
+    public categoryRules: CategoryRule[];
+    public schemePolicy: SchemePolicyEntry[];
+    public auditGoImports: boolean;
+    public goImportPolicy: GoImportPolicy;
+    public goNetworkCalls: boolean;
+    public rulePlugins: RulePluginConfig[];
+
     /**
      * Creates a new DetectorOptions instance with the provided configuration.
      *
@@ -130,6 +350,10 @@ export class DetectorOptions {
         // Output options
         this.format = options.format || 'table';
         this.outputFile = options.output || null;
+        this.outputEncoding = options.outputEncoding || 'utf8';
+        this.asciiJson = options.asciiJson || false;
+        this.csvFormulaGuard = options.csvFormulaGuard !== false;
+        this.csvFingerprints = options.csvFingerprints || false;
 
         // Control options
         this.resultsOnly = options.resultsOnly || false;
@@ -137,6 +361,11 @@ export class DetectorOptions {
 
         // Performance options
         this.concurrency = options.concurrency ?? 10;
+        this.chunkSize = options.chunkSize ?? DEFAULT_CHUNK_SIZE_MB;
+        this.maxFileSize = options.maxFileSize || 0;
+        this.parseTimeout = options.parseTimeout || 0;
+        this.sample = options.sample || null;
+        this.sampleSeed = options.sampleSeed || '';
 
         // Internal options (maintain compatibility with existing code)
 
@@ -145,18 +374,73 @@ export class DetectorOptions {
         this.withFilenames = true;
         this.relativePaths = true;
         this.fallbackRegex = options.fallbackRegex !== false;
+        this.genericTokenizer = options.genericTokenizer !== false;
+        this.languageOptions = options.languageOptions || {};
 
         this.context = options.context || 0;
 
+        // Additional sources
+        this.includeGitMetadata = options.includeGitMetadata || false;
+        this.gitHistoryDepth = options.gitHistoryDepth ?? DEFAULT_GIT_HISTORY_DEPTH;
+        this.gitBlame = options.gitBlame || false;
+        this.skipGenerated = options.skipGenerated || false;
+        this.collapseDuplicates = options.collapseDuplicates || false;
+
+        // License header mode
+        this.checkLicenseLinks = options.checkLicenseLinks || false;
+        this.licenseHeaders = options.licenseHeaders || this.checkLicenseLinks;
+
+        // Language-specific auditing
+        this.validateDocLinks = options.validateDocLinks || false;
+        this.includeRelativeUrls = options.includeRelativeUrls || false;
+        this.includeWindowsPaths = options.includeWindowsPaths || false;
+        this.sriAdvisory = options.sriAdvisory || false;
+        this.encodingAnomalies = options.encodingAnomalies || false;
+        this.obfuscatedHosts = options.obfuscatedHosts || false;
+        this.openRedirect = options.openRedirect || false;
+        this.redirectParams = options.redirectParams || DEFAULT_REDIRECT_PARAMS;
+        this.canaryDomains = options.canaryDomains || [];
+        this.canaryTokens = options.canaryTokens || this.canaryDomains.length > 0;
+        this.canaryPolicy = options.canaryPolicy || 'tripwire';
+        this.falsePositiveHeuristics = options.falsePositiveHeuristics || [];
+        this.codeOwners = options.codeOwners || false;
+        this.dataBundle = options.dataBundle || null;
+        this.openApiSpecs = options.openApiSpecs || [];
+        this.deprecationRegistry = options.deprecationRegistry || null;
+        this.domainMap = options.domainMap || {};
+        this.hideTriaged = options.hideTriaged || false;
+        this.triageStore = options.triageStore || (this.hideTriaged ? DEFAULT_TRIAGE_STORE : null);
+        this.filter = options.filter || null;
+        this.filters = options.filters || {};
+        this.email = options.email || null;
+        this.jira = options.jira || null;
+        this.webhooks = options.webhooks || [];
+        this.egressProxies = options.egressProxies || null;
+        this.teams = options.teams || null;
+        this.severityEscalation = options.severityEscalation || [];
+        this.categoryRules = options.categoryRules || [];
+        this.schemePolicy = options.schemePolicy || [];
+        this.auditGoImports = options.auditGoImports || false;
+        this.goImportPolicy = options.goImportPolicy || {};
+        this.goNetworkCalls = options.goNetworkCalls || false;
+        this.rulePlugins = options.rulePlugins || [];
+
         this.validateOptions();
     }
 
     private validateOptions(): void {
-        const validOutputFormats: OutputFormat[] = ['json', 'csv', 'table'];
+        const validOutputFormats: OutputFormat[] = [...BUILT_IN_FORMATS, ...getSinkFormats()];
         if (!validOutputFormats.includes(this.format)) {
             throw new Error(`Invalid output format: ${this.format}. Valid formats: ${validOutputFormats.join(', ')}`);
         }
 
+        const validOutputEncodings: OutputEncoding[] = ['utf8', 'utf8-bom', 'utf16le'];
+        if (!validOutputEncodings.includes(this.outputEncoding)) {
+            throw new Error(
+                `Invalid output encoding: ${this.outputEncoding}. Valid encodings: ${validOutputEncodings.join(', ')}`,
+            );
+        }
+
         if (this.maxDepth < 0) {
             throw new Error('Max depth must be >= 0');
         }
@@ -164,6 +448,29 @@ export class DetectorOptions {
         if (this.concurrency < 1) {
             throw new Error('Concurrency must be >= 1');
         }
+
+        if (this.chunkSize < 0) {
+            throw new Error('Chunk size must be >= 0');
+        }
+
+        if (this.maxFileSize < 0) {
+            throw new Error('Max file size must be >= 0');
+        }
+
+        if (this.parseTimeout < 0) {
+            throw new Error('Parse timeout must be >= 0');
+        }
+
+        if (this.gitHistoryDepth < 0) {
+            throw new Error('Git history depth must be >= 0');
+        }
+
+        if (this.sample !== null) {
+            parseSampleRate(this.sample);
+        }
+
+        parseCanaryDomains(this.canaryDomains, this.canaryPolicy);
+        parseFalsePositiveHeuristics(this.falsePositiveHeuristics);
     }
 
     /**
@@ -201,6 +508,43 @@ export class DetectorOptions {
         return [];
     }
 
+    /**
+     * Loads a JSON config file holding DetectorOptionsConfig properties.
+     *
+     * The file is validated against the published config schema (`url-detector schema config`),
+     * so misspelled properties and wrongly typed values are reported instead of silently ignored.
+     * A `$schema` property may be included for editor autocompletion.
+     *
+     * @param filePath Path to the config file
+     * @returns Promise resolving to the configuration
+     * @throws {Error} When the file cannot be read, is not valid JSON, or does not match the schema
+     *
+     * @example
+     * ```typescript
+     * const config = await DetectorOptions.loadConfigFile('.url-detector.json');
+     * const options = new DetectorOptions({ ...config, format: 'json' });
+     * ```
+     */
+    static async loadConfigFile(filePath: string): Promise<DetectorOptionsConfig> {
+        const content = await fs.promises.readFile(filePath, 'utf8');
+
+        let config: unknown;
+        try {
+            config = JSON.parse(content);
+        } catch (error: any) {
+            throw new Error(`Invalid config file ${filePath}: ${error.message}`);
+        }
+
+        const errors = validateSchema(config, CONFIG_SCHEMA);
+        if (errors.length > 0) {
+            throw new Error(`Invalid config file ${filePath}: ${errors.join('; ')}`);
+        }
+
+        const options = { ...(config as DetectorOptionsConfig & { $schema?: string }) };
+        delete options.$schema;
+        return options;
+    }
+
     /**
      * Loads file patterns from a text file.
      *
@@ -17,7 +17,14 @@ import * as path from 'path';
 import Table from 'cli-table3';
 import { Logger, NullLogger } from './logger';
 import { FileResult } from './urlDetector';
-import { OutputFormat } from './options';
+import { OutputEncoding, OutputFormat } from './options';
+import { Report, ReportFormat, ReportSections, createReport, serializeReport, toJsonOutput } from './report';
+import { ScanManifest } from './manifest';
+import { formatIncreaseAlert } from './increaseAlert';
+import { EstimatedTotal } from './sampling';
+import { createPatchset } from './quickfix';
+import { createHtmlReport } from './htmlReport';
+import { createSink, runSink } from './sinks';
 
 /**
  * Configuration options for output formatting.
@@ -36,30 +43,58 @@ export interface OutputFormatterOptions {
     withFilenames?: boolean;
     /** Number of context lines to include around URLs (default: 0) */
     context?: number;
+
+    /** Encoding of the output file; stdout is always UTF-8 (default: 'utf8') */
+    encoding?: OutputEncoding;
+    /** Whether to escape non-ASCII characters in json, ndjson, and sarif output (default: false) */
+    asciiJson?: boolean;
+    /** Whether to neutralize CSV cells that spreadsheets would evaluate as formulas (default: true) */
+    csvFormulaGuard?: boolean;
+    /** Whether to add a Fingerprint column to csv output (default: false) */
+    csvFingerprints?: boolean;
+    /** Hosts replaced in patchset fixes, old host to new host (default: {}) */
+    domainMap?: Record<string, string>;
+    /** Report of a previous scan the html format compares with (default: none) */
+    compareTo?: Report;
+    /** Team by path prefix for the html format's team rollups (default: use the owner attribute) */
+    teams?: Record<string, string>;
 }
 
-export interface OutputSummary {
-    totalFiles: number;
-    totalUrls: number;
-    uniqueUrls: number;
+/** Leading characters that make spreadsheet applications treat a cell as a formula */
+const FORMULA_PREFIX = /^[=+\-@\t\r]/;
+
+/**
+ * Escapes every non-ASCII character in serialized JSON as a \u escape, so the output survives
+ * pipelines that are not UTF-8 clean. Characters outside the Basic Multilingual Plane become
+ * surrogate pairs, as JSON requires.
+ *
+ * @param json Serialized JSON (or NDJSON)
+ * @returns The ASCII-only equivalent
+ */
+export function escapeNonAscii(json: string): string {
+    return json.replace(/[\u0080-\uffff]/g, char => `\\u${char.charCodeAt(0).toString(16).padStart(4, '0')}`);
 }
 
-export interface JsonOutput {
-    summary: OutputSummary;
-    files: Array<{
-        file: string;
-        urlCount: number;
-        urls: Array<{
-            url: string;
-            line?: number;
-            column?: number;
-            start: number;
-            end: number;
-            context?: string[];
-        }>;
-    }>;
+/**
+ * Encodes output text for writing to a file.
+ *
+ * @param text The output
+ * @param encoding Target encoding
+ * @returns The encoded bytes, with a byte order mark for 'utf8-bom' and 'utf16le'
+ */
+export function encodeOutput(text: string, encoding: OutputEncoding): Buffer {
+    switch (encoding) {
+        case 'utf8-bom':
+            return Buffer.from(`\ufeff${text}`, 'utf8');
+        case 'utf16le':
+            return Buffer.from(`\ufeff${text}`, 'utf16le');
+        default:
+            return Buffer.from(text, 'utf8');
+    }
 }
 
+export { OutputSummary, JsonOutput } from './report';
+
 export class OutputFormatter {
     private options: OutputFormatterOptions;
     private logger: Logger;
@@ -69,7 +104,11 @@ export class OutputFormatter {
         this.logger = logger || NullLogger;
     }
 
-    public async formatAndOutput(results: FileResult[]): Promise<void> {
+    public async formatAndOutput(
+        results: FileResult[],
+        sections?: ReportSections,
+        manifest?: ScanManifest,
+    ): Promise<void> {
         let output: string;
 
         if (this.options.onlyUrls) {
@@ -78,23 +117,45 @@ export class OutputFormatter {
             const format = this.options.format || 'table';
             switch (format) {
                 case 'json':
-                    output = this.formatJson(results);
+                    output = this.escapeJson(this.formatJson(results, sections, manifest));
+                    break;
+                case 'ndjson':
+                case 'sarif':
+                    output = this.escapeJson(
+                        serializeReport(createReport(results, sections, manifest), format as ReportFormat),
+                    );
                     break;
                 case 'csv':
                     output = this.formatCsv(results);
                     break;
+                case 'patchset':
+                    output = await createPatchset(results, { domainMap: this.options.domainMap });
+                    if (!output) this.logger.info('No autofixable findings; the patchset is empty');
+                    break;
+                case 'html':
+                    output = createHtmlReport(results, sections, manifest, {
+                        previous: this.options.compareTo,
+                        teams: this.options.teams,
+                    });
+                    break;
                 case 'table':
-                    output = this.formatTable(results);
+                    output =
+                        this.formatTable(results) + this.formatSectionTables(sections) + this.formatManifest(manifest);
                     break;
 
-                default:
-                    throw new Error(`Unknown output format: ${format}`);
+                default: {
+                    const sink = createSink(format);
+                    if (!sink) {
+                        throw new Error(`Unknown output format: ${format}`);
+                    }
+                    output = await runSink(sink, createReport(results, sections, manifest));
+                }
             }
         }
 
         const outputFile = this.options.outputFile;
         if (outputFile) {
-            await fs.promises.writeFile(outputFile, output, 'utf8');
+            await fs.promises.writeFile(outputFile, encodeOutput(output, this.options.encoding || 'utf8'));
             this.logger.info(`Output written to ${outputFile}`);
         } else {
             // Output results through the logger - the logger will handle quiet/console behavior
@@ -114,34 +175,19 @@ export class OutputFormatter {
         return urls.join('\n');
     }
 
-    private formatJson(results: FileResult[]): string {
-        const output: JsonOutput = {
-            summary: {
-                totalFiles: results.length,
-                totalUrls: results.reduce((sum, r) => sum + r.urls.length, 0),
-                uniqueUrls: this.getUniqueUrls(results).length,
-            },
-            files: results.map(result => ({
-                file: result.file,
-                urlCount: result.urls.length,
-                urls: result.urls
-                    .map(urlObj => ({
-                        url: urlObj.url,
-                        line: this.options.withLineNumbers ? urlObj.line : undefined,
-                        column: this.options.withLineNumbers ? urlObj.column : undefined,
-                        start: urlObj.start,
-                        end: urlObj.end,
-                        context: urlObj.context,
-                    }))
-                    .filter(url => url.line !== undefined || !this.options.withLineNumbers),
-            })),
-        };
-
+    private formatJson(results: FileResult[], sections?: ReportSections, manifest?: ScanManifest): string {
+        const output = toJsonOutput(createReport(results, sections, manifest), !!this.options.withLineNumbers);
         return JSON.stringify(output, null, 2);
     }
 
     private formatCsv(results: FileResult[]): string {
+        // The Fingerprint column is only added on request and the Attributes column only when some
+        // finding carries attributes, keeping the default layout stable for existing consumers
+        const withFingerprints = !!this.options.csvFingerprints;
+        const withAttributes = results.some(result => result.urls.some(urlObj => urlObj.attributes));
         const headers = ['FilePath', 'FileName', 'LineNumber', 'ColumnPosition', 'URL'];
+        if (withFingerprints) headers.push('Fingerprint');
+        if (withAttributes) headers.push('Attributes');
         const rows: string[] = [headers.join(',')];
 
         for (const result of results) {
@@ -154,6 +200,8 @@ export class OutputFormatter {
                     urlObj.column.toString(),
                     this.escapeCsv(urlObj.url),
                 ];
+                if (withFingerprints) row.push(urlObj.fingerprint || '');
+                if (withAttributes) row.push(this.escapeCsv(this.formatAttributes(urlObj.attributes)));
 
                 rows.push(row.join(','));
             }
@@ -162,6 +210,12 @@ export class OutputFormatter {
         return rows.join('\n');
     }
 
+    private formatAttributes(attributes: Record<string, string> | undefined): string {
+        return Object.entries(attributes || {})
+            .map(([key, value]) => `${key}=${value}`)
+            .join(';');
+    }
+
     private formatTable(results: FileResult[]): string {
         if (results.length === 0) {
             return 'No URLs found.';
@@ -195,20 +249,272 @@ export class OutputFormatter {
         return table.toString();
     }
 
-    private getUniqueUrls(results: FileResult[]): string[] {
-        const urls = new Set<string>();
+    private formatSectionTables(sections: ReportSections | undefined): string {
+        return (
+            this.formatGroupTable(sections) +
+            this.formatTeamTable(sections) +
+            this.formatEnvironmentTable(sections) +
+            this.formatReachabilityTable(sections) +
+            this.formatDuplicateTable(sections) +
+            this.formatPortTable(sections) +
+            this.formatCoverageTable(sections) +
+            this.formatQuarantineTable(sections) +
+            this.formatIncreaseAlert(sections) +
+            this.formatSampleTable(sections)
+        );
+    }
 
-        for (const result of results) {
-            for (const urlObj of result.urls) {
-                urls.add(urlObj.url);
-            }
+    private formatGroupTable(sections: ReportSections | undefined): string {
+        const groups = (sections && sections.groups) || [];
+        if (groups.length === 0) return '';
+
+        const table = new Table({
+            head: [sections!.groupedBy || 'Group', 'Files', 'URLs', 'Violations'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+            colWidths: [40, 10, 10, 12],
+        });
+
+        for (const group of groups) {
+            table.push([this.truncate(group.value, 38), group.files.length, group.urlCount, group.violationCount]);
+        }
+
+        return `\n\nFindings by ${sections!.groupedBy}:\n` + table.toString();
+    }
+
+    private formatTeamTable(sections: ReportSections | undefined): string {
+        const teams = (sections && sections.teams) || [];
+        if (teams.length === 0) return '';
+
+        const table = new Table({
+            head: ['Team', 'Files', 'URLs', 'Violations (E/W/I)', 'New', 'Fixed', 'Worst files'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+            colWidths: [25, 8, 8, 20, 8, 8, 50],
+            wordWrap: true,
+        });
+
+        for (const team of teams) {
+            const { error, warning, info } = team.severities;
+            table.push([
+                this.truncate(team.team, 23),
+                team.fileCount,
+                team.urlCount,
+                `${team.violationCount} (${error}/${warning}/${info})`,
+                team.newCount ?? '-',
+                team.fixedCount ?? '-',
+                team.worstFiles.map(offender => `${offender.file} (${offender.violationCount})`).join('\n'),
+            ]);
+        }
+
+        return '\n\nFindings by team:\n' + table.toString();
+    }
+
+    private formatEnvironmentTable(sections: ReportSections | undefined): string {
+        const environments = (sections && sections.environments) || [];
+        if (environments.length === 0) return '';
+
+        const table = new Table({
+            head: ['Service', 'Environments', 'Missing', 'Inconsistencies'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+            colWidths: [30, 20, 20, 40],
+            wordWrap: true,
+        });
+
+        for (const service of environments) {
+            table.push([
+                this.truncate(service.service, 28),
+                service.environments.join(', '),
+                service.missing.join(', '),
+                service.inconsistencies.join('\n'),
+            ]);
+        }
+
+        return '\n\nEnvironment consistency:\n' + table.toString();
+    }
+
+    private formatReachabilityTable(sections: ReportSections | undefined): string {
+        const entries = (sections && sections.reachability) || [];
+        if (entries.length === 0) return '';
+
+        const egresses = Object.keys(entries[0].results);
+        const table = new Table({
+            head: ['URL', ...egresses],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+            colWidths: [50, ...egresses.map(() => 16)],
+        });
+
+        for (const entry of entries) {
+            table.push([
+                this.truncate(entry.url, 48),
+                ...egresses.map(egress => {
+                    const probe = entry.results[egress];
+                    return probe.reachable ? `yes (${probe.status})` : 'no';
+                }),
+            ]);
+        }
+
+        return '\n\nReachability:\n' + table.toString();
+    }
+
+    private formatDuplicateTable(sections: ReportSections | undefined): string {
+        const duplicates = (sections && sections.duplicates) || [];
+        if (duplicates.length === 0) return '';
+
+        const table = new Table({
+            head: ['URL', 'Files', 'Count', 'Consolidate In'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+            colWidths: [50, 8, 8, 44],
+            wordWrap: true,
+        });
+
+        for (const duplicate of duplicates) {
+            // Without an existing central definition, suggest the directory all usages share
+            const locations = duplicate.candidates.length > 0 ? duplicate.candidates : [duplicate.commonDirectory];
+            const url = this.truncate(duplicate.url, 48);
+            table.push([url, duplicate.files.length, duplicate.count, locations.join('\n')]);
+        }
+
+        return '\n\nDuplicate endpoints:\n' + table.toString();
+    }
+
+    private formatPortTable(sections: ReportSections | undefined): string {
+        const ports = (sections && sections.ports) || [];
+        if (ports.length === 0) return '';
+
+        const table = new Table({
+            head: ['Port', 'Host', 'Schemes', 'Count', 'Files'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+            colWidths: [8, 36, 12, 8, 46],
+            wordWrap: true,
+        });
+
+        for (const usage of ports) {
+            table.push([usage.port, usage.host, usage.schemes.join(', '), usage.count, usage.files.join('\n')]);
         }
 
-        return Array.from(urls);
+        return '\n\nNon-standard ports:\n' + table.toString();
+    }
+
+    private formatCoverageTable(sections: ReportSections | undefined): string {
+        const coverage = sections && sections.coverage;
+        if (!coverage) return '';
+
+        const unparsed = coverage.scannedFiles - coverage.parsedFiles;
+        const title =
+            `\n\nLanguage coverage: ${coverage.parsedFiles} of ${coverage.scannedFiles} file(s) parsed, ` +
+            `${unparsed} ${coverage.fallbackRegex ? 'scanned with regex' : 'skipped'} without a parser`;
+        if (coverage.extensions.length === 0) return title;
+
+        const table = new Table({
+            head: ['Extension', 'Language', 'Files'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+        });
+
+        for (const entry of coverage.extensions) {
+            table.push([entry.extension, entry.language, entry.files]);
+        }
+
+        return `${title}:\n${table.toString()}`;
+    }
+
+    private formatQuarantineTable(sections: ReportSections | undefined): string {
+        const quarantine = sections && sections.quarantine;
+        if (!quarantine || quarantine.count === 0) return '';
+
+        const table = new Table({
+            head: ['Reason', 'Findings'],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+        });
+
+        for (const [reason, count] of Object.entries(quarantine.reasons)) {
+            table.push([reason, count]);
+        }
+
+        const title = `Quarantined ${quarantine.count} finding(s) in ${quarantine.files} file(s) to ${quarantine.file}`;
+        return `\n\n${title}:\n` + table.toString();
+    }
+
+    private escapeJson(json: string): string {
+        return this.options.asciiJson ? escapeNonAscii(json) : json;
+    }
+
+    private formatIncreaseAlert(sections: ReportSections | undefined): string {
+        const alert = sections && sections.increaseAlert;
+        if (!alert) return '';
+        return `\n\n${alert.triggered ? 'ALERT: ' : ''}${formatIncreaseAlert(alert)}`;
+    }
+
+    private formatSampleTable(sections: ReportSections | undefined): string {
+        const estimate = sections && sections.sample;
+        if (!estimate) return '';
+
+        const table = new Table({
+            head: ['Total', 'Sampled', 'Estimated', `${Math.round(estimate.confidence * 100)}% interval`],
+            style: {
+                head: ['cyan'],
+                border: ['grey'],
+            },
+        });
+
+        const totals: Array<[string, EstimatedTotal]> = [
+            ['Findings', estimate.findings],
+            ['Violations', estimate.violations],
+            ['Files with findings', estimate.filesWithFindings],
+        ];
+        for (const [name, total] of totals) {
+            table.push([name, total.observed, total.estimate, `${total.low}-${total.high}`]);
+        }
+
+        return (
+            `\n\nSampled ${estimate.sampled} of ${estimate.population} file(s) (${estimate.rate}); ` +
+            `estimated totals of a full scan:\n${table.toString()}`
+        );
+    }
+
+    private formatManifest(manifest: ScanManifest | undefined): string {
+        if (!manifest) return '';
+
+        const grammars = Object.keys(manifest.grammars).length;
+        return (
+            `\n\nScanned ${manifest.fileCount} file(s) at commit ${manifest.commit || 'n/a'} ` +
+            `with url-detector ${manifest.toolVersion} and ${grammars} grammar(s)` +
+            (manifest.treeSitterVersion ? ` on tree-sitter ${manifest.treeSitterVersion}` : '') +
+            (manifest.profile ? `\nProfile: ${manifest.profile}` : '') +
+            `\nConfig hash: ${manifest.configHash}\nPolicy hash: ${manifest.policyHash}` +
+            (manifest.partial ? '\nPartial results: the scan was interrupted before every file was scanned' : '')
+        );
     }
 
     private escapeCsv(value: string | number): string {
-        const strValue = typeof value === 'string' ? value : value.toString();
+        let strValue = typeof value === 'string' ? value : value.toString();
+
+        // A quote prefix keeps a crafted URL or path such as =HYPERLINK(...) from running as a formula
+        if (this.options.csvFormulaGuard !== false && FORMULA_PREFIX.test(strValue)) {
+            strValue = `'${strValue}`;
+        }
 
         if (strValue.includes(',') || strValue.includes('"') || strValue.includes('\n')) {
             return `"${strValue.replace(/"/g, '""')}"`;
@@ -0,0 +1,119 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+/**
+ * A file changed by a unified diff, with the lines of the new file the diff shows.
+ */
+export interface PatchFile {
+    /** Path of the file after the change, without the b/ prefix git adds */
+    path: string;
+    /** Path of the file before the change, or null for a new file */
+    oldPath: string | null;
+    /** Added lines by their line number in the new file */
+    added: Map<number, string>;
+    /** Added and context lines by their line number in the new file */
+    lines: Map<number, string>;
+}
+
+const HUNK_HEADER = /^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@/;
+
+/**
+ * Parses a unified diff, as written by `git diff`, `git format-patch`, or `diff -u`. Text around the
+ * file diffs, such as the mail headers and signature of a formatted patch, is ignored, and hunks are
+ * read by their line counts, so removed lines starting with '--' are not taken for headers.
+ * Deleted files are left out; binary diffs and pure renames come back without lines.
+ *
+ * @param patch Text of the diff
+ * @returns The changed files, in the order of the diff
+ */
+export function parsePatch(patch: string): PatchFile[] {
+    const files: PatchFile[] = [];
+    let current: PatchFile | null = null;
+    let oldPath: string | null = null;
+    let oldRemaining = 0;
+    let newRemaining = 0;
+    let lineNumber = 0;
+
+    for (const line of patch.split(/\r?\n/)) {
+        if (oldRemaining > 0 || newRemaining > 0) {
+            const marker = line === '' ? ' ' : line.charAt(0);
+            if (marker === '\\') continue; // "\ No newline at end of file"
+            if (marker === '-') {
+                oldRemaining--;
+                continue;
+            }
+            if (marker === '+' || marker === ' ') {
+                if (current) {
+                    current.lines.set(lineNumber, line.substring(1));
+                    if (marker === '+') current.added.set(lineNumber, line.substring(1));
+                }
+                if (marker === ' ') oldRemaining--;
+                newRemaining--;
+                lineNumber++;
+                continue;
+            }
+            // A truncated hunk; read the line as a header
+            oldRemaining = newRemaining = 0;
+        }
+
+        if (line.startsWith('diff ')) {
+            current = null;
+            oldPath = null;
+        } else if (line.startsWith('--- ')) {
+            oldPath = parseHeaderPath(line.substring(4));
+        } else if (line.startsWith('+++ ')) {
+            const newPath = parseHeaderPath(line.substring(4));
+            current = newPath ? { path: newPath, oldPath, added: new Map(), lines: new Map() } : null;
+            if (current) files.push(current);
+        } else {
+            const hunk = HUNK_HEADER.exec(line);
+            if (hunk) {
+                oldRemaining = hunk[2] === undefined ? 1 : parseInt(hunk[2], 10);
+                newRemaining = hunk[4] === undefined ? 1 : parseInt(hunk[4], 10);
+                lineNumber = parseInt(hunk[3], 10);
+            }
+        }
+    }
+
+    return files;
+}
+
+/**
+ * Rebuilds as much of the new file as a diff shows: added and context lines at their line numbers,
+ * with the lines in between left empty, so findings are reported at their positions in the new file.
+ *
+ * @param file A changed file from parsePatch()
+ * @returns The partial content of the new file
+ */
+export function patchedContent(file: PatchFile): string {
+    const last = Math.max(0, ...Array.from(file.lines.keys()));
+    const lines: string[] = [];
+    for (let lineNumber = 1; lineNumber <= last; lineNumber++) {
+        lines.push(file.lines.get(lineNumber) ?? '');
+    }
+    return lines.join('\n');
+}
+
+/**
+ * Reads the path of a ---/+++ header: drops the timestamp diff -u appends after a tab, the quotes
+ * git puts around unusual names, and the a/ or b/ prefix. /dev/null becomes null.
+ */
+function parseHeaderPath(header: string): string | null {
+    let file = header.split('\t')[0].trim();
+    if (file.startsWith('"') && file.endsWith('"')) {
+        file = file.slice(1, -1).replace(/\\(["\\])/g, '$1');
+    }
+    if (file === '/dev/null') return null;
+    return file.replace(/^[ab]\//, '');
+}
@@ -0,0 +1,339 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { MessageChannel, MessagePort, Worker, receiveMessageOnPort } from 'worker_threads';
+import { FileContext, Rule, SEVERITIES, Severity, Violation } from './ruleEngine';
+import { URLMatch } from './urlFilter';
+
+/**
+ * Access a rule plugin can be granted beyond pure computation.
+ */
+export type PluginCapability = 'filesystem' | 'network';
+
+/**
+ * All plugin capabilities.
+ */
+export const PLUGIN_CAPABILITIES: PluginCapability[] = ['filesystem', 'network'];
+
+/** Time limit of a single rule evaluation, in milliseconds */
+export const DEFAULT_PLUGIN_TIMEOUT_MS = 1000;
+
+/** Heap limit of a plugin, in megabytes */
+export const DEFAULT_PLUGIN_MEMORY_MB = 64;
+
+/** Time limit for starting the sandbox and running the plugin's top-level code */
+const LOAD_TIMEOUT_MS = 10000;
+
+/** Allowance for passing messages to and from the sandbox on top of the time limit */
+const MESSAGE_GRACE_MS = 1000;
+
+/**
+ * A third-party rule plugin and the limits it runs under.
+ *
+ * @example
+ * ```json
+ * { "module": "./rules/internal-hosts.js", "timeoutMs": 200, "capabilities": ["filesystem"] }
+ * ```
+ */
+export interface RulePluginConfig {
+    /** Path (relative to the working directory) or package name of the plugin's single-file module */
+    module: string;
+    /** Time limit of a single rule evaluation, in milliseconds (default: 1000) */
+    timeoutMs?: number;
+    /** Heap limit of the plugin, in megabytes (default: 64) */
+    memoryMb?: number;
+    /** Access granted to the plugin (default: none) */
+    capabilities?: PluginCapability[];
+}
+
+/**
+ * A rule plugin that broke its sandbox limits, asked for a capability it was not granted, or
+ * returned malformed violations.
+ */
+export class PluginError extends Error {
+    public readonly plugin: string;
+
+    constructor(plugin: string, message: string) {
+        super(`Plugin ${plugin} ${message}`);
+        this.plugin = plugin;
+    }
+}
+
+interface SandboxReply {
+    ok: boolean;
+    value?: string;
+    error?: string;
+    /** Whether the error was a broken time limit, after which the plugin is not called again */
+    limit?: boolean;
+}
+
+// Runs in the worker thread. The plugin itself runs in a separate vm context that sees none of the
+// worker's globals (no process, require, or Buffer); only JSON strings cross into it, and host
+// functions are only handed over for granted capabilities.
+const WORKER_SOURCE = `
+const { workerData } = require('worker_threads');
+const vm = require('vm');
+const { port, signal, source, filename, modules, network, timeoutMs, loadTimeoutMs } = workerData;
+
+const PRELUDE = \`
+'use strict';
+const __rules = new Map();
+let __file;
+function __init(factory, allowed, hostRequire) {
+    const module = { exports: {} };
+    const require = name => {
+        if (!allowed.includes(name)) throw new Error('Module ' + name + ' is not available without a capability');
+        return hostRequire(name);
+    };
+    const noop = () => undefined;
+    const console = { log: noop, info: noop, warn: noop, error: noop, debug: noop };
+    factory(module, module.exports, require, console);
+    const exported = module.exports;
+    const rules = Array.isArray(exported) ? exported : exported && exported.rules;
+    if (!Array.isArray(rules)) throw new Error('does not export an array of rules or { rules }');
+    for (const rule of rules) {
+        if (!rule || typeof rule.id !== 'string' || !rule.id || typeof rule.evaluate !== 'function') {
+            throw new Error('exports a rule without an id or an evaluate function');
+        }
+        __rules.set(rule.id, rule);
+    }
+    return JSON.stringify(rules.map(rule => ({ id: rule.id, description: rule.description })));
+}
+function __evaluate(request) {
+    const { rule, finding, file } = JSON.parse(request);
+    if (file) __file = file;
+    return JSON.stringify(__rules.get(rule).evaluate(finding, __file));
+}
+\`;
+
+// A null-prototype global keeps the worker's Object and Function constructors out of reach
+const context = vm.createContext(Object.create(null), { codeGeneration: { strings: false, wasm: true } });
+vm.runInContext(PRELUDE, context);
+if (network) context.fetch = fetch;
+
+function run(message) {
+    if (message.type === 'load') {
+        const factory = new vm.Script('(function (module, exports, require, console) {' + source + '\\n})', {
+            filename,
+        }).runInContext(context, { timeout: loadTimeoutMs });
+        context.__factory = factory;
+        context.__allowed = JSON.stringify(modules);
+        context.__hostRequire = modules.length > 0 ? name => require(name) : undefined;
+        return vm.runInContext('__init(__factory, JSON.parse(__allowed), __hostRequire)', context, {
+            timeout: loadTimeoutMs,
+        });
+    }
+    context.__request = message.request;
+    return vm.runInContext('__evaluate(__request)', context, { timeout: timeoutMs });
+}
+
+port.on('message', message => {
+    let reply;
+    try {
+        reply = { ok: true, value: run(message) };
+    } catch (error) {
+        let text;
+        try {
+            text = error && typeof error.message === 'string' ? error.message : String(error);
+        } catch {
+            text = 'threw an unreadable error';
+        }
+        reply = { ok: false, error: text, limit: !!error && error.code === 'ERR_SCRIPT_EXECUTION_TIMEOUT' };
+    }
+    port.postMessage(reply);
+    Atomics.store(signal, 0, 1);
+    Atomics.notify(signal, 0);
+});
+`;
+
+/** Node modules each capability makes available to require() */
+const CAPABILITY_MODULES: Record<PluginCapability, string[]> = {
+    filesystem: ['fs', 'fs/promises', 'path'],
+    network: ['http', 'https', 'net', 'tls', 'dns', 'url'],
+};
+
+/**
+ * A plugin module running in its own worker thread, called synchronously so that its rules fit the
+ * synchronous Rule interface.
+ */
+class PluginSandbox {
+    private readonly name: string;
+    private readonly timeoutMs: number;
+    private readonly worker: Worker;
+    private readonly port: MessagePort;
+    private readonly signal: Int32Array;
+    private lastFile: FileContext | null = null;
+    private failure: string | null = null;
+
+    constructor(config: RulePluginConfig, filename: string, source: string) {
+        this.name = config.module;
+        this.timeoutMs = config.timeoutMs ?? DEFAULT_PLUGIN_TIMEOUT_MS;
+        this.signal = new Int32Array(new SharedArrayBuffer(Int32Array.BYTES_PER_ELEMENT));
+
+        const capabilities = config.capabilities || [];
+        const { port1, port2 } = new MessageChannel();
+        this.port = port1;
+        this.worker = new Worker(WORKER_SOURCE, {
+            eval: true,
+            workerData: {
+                port: port2,
+                signal: this.signal,
+                source,
+                filename,
+                modules: capabilities.flatMap(capability => CAPABILITY_MODULES[capability]),
+                network: capabilities.includes('network'),
+                timeoutMs: this.timeoutMs,
+                loadTimeoutMs: LOAD_TIMEOUT_MS,
+            },
+            transferList: [port2],
+            resourceLimits: { maxOldGenerationSizeMb: config.memoryMb ?? DEFAULT_PLUGIN_MEMORY_MB },
+        });
+        // A worker that runs out of memory is reported by the call waiting on it, not by crashing the scan
+        this.worker.on('error', () => undefined);
+        // A sandbox that is never disposed must not keep the process alive
+        this.worker.unref();
+    }
+
+    /**
+     * Runs the plugin's top-level code and lists the rules it exports.
+     */
+    public load(): Array<{ id: string; description?: string }> {
+        return JSON.parse(this.call({ type: 'load' }, LOAD_TIMEOUT_MS));
+    }
+
+    /**
+     * Evaluates one of the plugin's rules. The file content is only sent when the file changes.
+     */
+    public evaluate(rule: string, finding: URLMatch, file: FileContext): Violation[] {
+        if (this.failure) return [];
+
+        const request = JSON.stringify({ rule, finding, file: file === this.lastFile ? undefined : file });
+        this.lastFile = file;
+        return this.validate(rule, JSON.parse(this.call({ type: 'evaluate', request }, this.timeoutMs)));
+    }
+
+    /**
+     * Stops the worker thread.
+     */
+    public terminate(): void {
+        void this.worker.terminate();
+    }
+
+    private call(message: Record<string, string>, timeoutMs: number): string {
+        Atomics.store(this.signal, 0, 0);
+        this.port.postMessage(message);
+
+        if (Atomics.wait(this.signal, 0, 0, timeoutMs + MESSAGE_GRACE_MS) === 'timed-out') {
+            // Out of memory or stuck outside the vm timeout; the worker cannot be trusted to answer again
+            this.terminate();
+            throw this.disable(`exceeded its ${timeoutMs}ms time limit or ${this.memoryDescription()} memory limit`);
+        }
+        const received = receiveMessageOnPort(this.port);
+        const reply = received && (received.message as SandboxReply);
+        if (!reply) {
+            throw this.disable('stopped answering');
+        }
+        if (!reply.ok) {
+            if (reply.limit) throw this.disable(`exceeded its ${timeoutMs}ms time limit`);
+            throw new PluginError(this.name, `failed: ${reply.error}`);
+        }
+        if (reply.value === undefined) {
+            throw new PluginError(this.name, 'returned no value');
+        }
+        return reply.value;
+    }
+
+    private validate(rule: string, value: unknown): Violation[] {
+        if (value === null || value === undefined) return [];
+        if (!Array.isArray(value)) {
+            throw new PluginError(this.name, `rule ${rule} returned ${typeof value} instead of an array of violations`);
+        }
+        return value.map(violation => {
+            if (
+                !violation ||
+                !SEVERITIES.includes(violation.severity) ||
+                typeof violation.message !== 'string' ||
+                (violation.rule !== undefined && typeof violation.rule !== 'string')
+            ) {
+                throw new PluginError(this.name, `rule ${rule} returned a malformed violation`);
+            }
+            const severity = violation.severity as Severity;
+            return { rule: violation.rule || rule, severity, message: violation.message };
+        });
+    }
+
+    private disable(reason: string): PluginError {
+        this.failure = reason;
+        return new PluginError(this.name, `${reason}; its rules are disabled for the rest of the scan`);
+    }
+
+    private memoryDescription(): string {
+        const limits = this.worker.resourceLimits;
+        return limits && limits.maxOldGenerationSizeMb ? `${limits.maxOldGenerationSizeMb}MB` : 'its';
+    }
+}
+
+/**
+ * Loads a third-party rule plugin into a resource-limited sandbox and wraps its rules.
+ *
+ * The plugin is a single CommonJS file exporting an array of rules, or `{ rules }`, with the same
+ * shape as Rule. It runs in its own worker thread with a heap limit, in a context without Node's
+ * globals, where every evaluation has a time limit. require() only offers the modules of granted
+ * capabilities ('filesystem': fs and path; 'network': http, https, net, tls, dns, and fetch), so by
+ * default a plugin can neither read files nor open connections.
+ *
+ * A plugin that throws, is denied a module, or returns malformed violations fails like any other
+ * rule: the RuleEngine logs the PluginError and the scan goes on. A plugin that breaks its time or
+ * memory limit is also disabled, so its rules raise no further violations.
+ *
+ * @param config The plugin and its limits
+ * @returns Rules evaluated in the sandbox, with the ids the plugin gave them
+ * @throws {PluginError} When the plugin cannot be read, fails or times out while loading, or exports no valid rules
+ *
+ * @example
+ * ```typescript
+ * // internal-hosts.js: module.exports = { rules: [{ id: 'internal-host', evaluate: finding => ... }] };
+ * loadRulePlugin({ module: './internal-hosts.js', timeoutMs: 200 }).forEach(rule => detector.registerRule(rule));
+ * ```
+ */
+export function loadRulePlugin(config: RulePluginConfig): Rule[] {
+    const unsupported = (config.capabilities || []).filter(capability => !PLUGIN_CAPABILITIES.includes(capability));
+    if (unsupported.length > 0) {
+        throw new PluginError(config.module, `requests unknown capabilities: ${unsupported.join(', ')}`);
+    }
+
+    let filename: string;
+    let source: string;
+    try {
+        filename = require.resolve(config.module, { paths: [process.cwd()] });
+        source = fs.readFileSync(filename, 'utf8');
+    } catch (error: unknown) {
+        // Resolution errors list the require stack on further lines
+        const errorMessage = error instanceof Error ? error.message.split('\n')[0] : String(error);
+        throw new PluginError(config.module, `could not be read: ${errorMessage}`);
+    }
+
+    const sandbox = new PluginSandbox(config, filename, source);
+    try {
+        return sandbox.load().map(({ id, description }) => ({
+            id,
+            description: typeof description === 'string' ? description : undefined,
+            evaluate: (finding: URLMatch, file: FileContext) => sandbox.evaluate(id, finding, file),
+        }));
+    } catch (error: unknown) {
+        sandbox.terminate();
+        throw error;
+    }
+}
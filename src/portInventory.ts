@@ -0,0 +1,84 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { BUILT_IN_CATEGORIES } from './categoryRules';
+import { FileResult } from './urlFilter';
+
+/**
+ * A non-standard port hard-coded for a host, with where it is used.
+ */
+export interface PortUsage {
+    /** Lowercase host */
+    host: string;
+    /** Port number */
+    port: number;
+    /** Schemes the port is used with (e.g., ['http', 'https']) */
+    schemes: string[];
+    /** Number of occurrences */
+    count: number;
+    /** Files containing the host and port */
+    files: string[];
+}
+
+/**
+ * Extracts the scheme, host, and port of a URL with an explicit port other than the scheme's default
+ * (e.g., :8080 or :9443, but not :443 for https).
+ *
+ * @param url The URL
+ * @returns The scheme, host, and port, or null when the URL has no non-standard port or cannot be parsed
+ */
+export function getNonStandardPort(url: string): { scheme: string; host: string; port: number } | null {
+    let parsed: URL;
+    try {
+        parsed = new URL(url.startsWith('//') ? `https:${url}` : url);
+    } catch {
+        return null;
+    }
+    // The URL parser drops default ports, so any port left is non-standard
+    if (!parsed.hostname || !parsed.port) return null;
+
+    return { scheme: parsed.protocol.replace(/:$/, ''), host: parsed.hostname, port: parseInt(parsed.port, 10) };
+}
+
+/**
+ * Aggregates the non-standard ports seen in URLs by host, for firewall rule reviews.
+ *
+ * @param results Scan results
+ * @returns Ports by host, sorted by port, then host
+ */
+export function buildPortInventory(results: FileResult[]): PortUsage[] {
+    const usages = new Map<string, PortUsage>();
+    for (const result of results) {
+        for (const urlObj of result.urls) {
+            if (urlObj.category && BUILT_IN_CATEGORIES.includes(urlObj.category)) continue;
+
+            const endpoint = getNonStandardPort(urlObj.url);
+            if (!endpoint) continue;
+
+            const key = `${endpoint.host}:${endpoint.port}`;
+            let usage = usages.get(key);
+            if (!usage) {
+                usage = { host: endpoint.host, port: endpoint.port, schemes: [], count: 0, files: [] };
+                usages.set(key, usage);
+            }
+            if (!usage.schemes.includes(endpoint.scheme)) usage.schemes.push(endpoint.scheme);
+            if (!usage.files.includes(result.file)) usage.files.push(result.file);
+            usage.count++;
+        }
+    }
+
+    return Array.from(usages.values())
+        .map(usage => ({ ...usage, schemes: usage.schemes.sort(), files: usage.files.sort() }))
+        .sort((a, b) => a.port - b.port || a.host.localeCompare(b.host));
+}
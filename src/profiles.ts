@@ -0,0 +1,102 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { DetectorOptionsConfig } from './options';
+
+/** Names of the built-in profiles */
+export type ProfileName = 'security' | 'link-check' | 'inventory';
+
+/**
+ * Options set by a profile: detector options, plus the report sections the CLI adds to the report.
+ */
+export interface ProfileOptions extends DetectorOptionsConfig {
+    /** Probe URLs through the configured egresses (--reachability-matrix) */
+    reachabilityMatrix?: boolean;
+    /** Report URLs hard-coded in many files (--duplicate-endpoints) */
+    duplicateEndpoints?: boolean;
+    /** Report non-standard ports by host (--port-inventory) */
+    portInventory?: boolean;
+    /** Report files without a parser (--report-coverage) */
+    reportCoverage?: boolean;
+    /** Attribute to group findings by (--group-by) */
+    groupBy?: string;
+}
+
+/**
+ * A named bundle of options for a common use case.
+ */
+export interface Profile {
+    /** What the profile is for, shown in help text */
+    description: string;
+    options: ProfileOptions;
+}
+
+/**
+ * The built-in profiles. Options given on the command line or in the config file take precedence
+ * over the profile's.
+ */
+export const BUILT_IN_PROFILES: Record<ProfileName, Profile> = {
+    security: {
+        description: 'Insecure schemes, encodings, and hosts, open redirects, and CDN assets lacking SRI, as SARIF',
+        options: {
+            skipGenerated: true,
+            sriAdvisory: true,
+            encodingAnomalies: true,
+            obfuscatedHosts: true,
+            openRedirect: true,
+            schemePolicy: [
+                { schemes: ['http'], require: 'https', severity: 'warning' },
+                { schemes: ['ws'], require: 'wss', severity: 'warning' },
+                { schemes: ['ftp', 'telnet'], severity: 'warning' },
+            ],
+            format: 'sarif',
+        },
+    },
+    'link-check': {
+        description: 'Broken documentation links and unreachable URLs, comments included',
+        options: {
+            includeComments: true,
+            validateDocLinks: true,
+            reachabilityMatrix: true,
+        },
+    },
+    inventory: {
+        description: 'Every URL with categories, ports, duplicates, and language coverage, as JSON',
+        options: {
+            includeComments: true,
+            groupBy: 'category',
+            duplicateEndpoints: true,
+            portInventory: true,
+            reportCoverage: true,
+            format: 'json',
+        },
+    },
+};
+
+/** Names of the built-in profiles */
+export const PROFILE_NAMES = Object.keys(BUILT_IN_PROFILES) as ProfileName[];
+
+/**
+ * Looks up a built-in profile.
+ *
+ * @param name Name of the profile
+ * @returns The profile
+ * @throws {Error} When no built-in profile has the name
+ */
+export function getProfile(name: string): Profile {
+    if (!Object.prototype.hasOwnProperty.call(BUILT_IN_PROFILES, name)) {
+        throw new Error(`Unknown profile: ${name}. Must be one of: ${PROFILE_NAMES.join(', ')}`);
+    }
+    return BUILT_IN_PROFILES[name as ProfileName];
+}
@@ -0,0 +1,169 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { FEED_ATTRIBUTE } from './dataBundle';
+import { ScanManifest } from './manifest';
+import { createReport, serializeReport } from './report';
+import { FileResult, URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Attribute holding the reasons a finding was quarantined, separated by spaces */
+export const QUARANTINE_ATTRIBUTE = 'quarantine';
+
+/** Reason given to findings with credentials in the URL */
+export const CREDENTIALS_REASON = 'credentials';
+
+/** Host feeds whose hits are quarantined by default */
+export const DEFAULT_QUARANTINE_FEEDS = ['malicious'];
+
+/** File mode of the quarantine report: readable and writable by the owner only */
+const QUARANTINE_FILE_MODE = 0o600;
+
+/**
+ * Decides which findings are too sensitive for the main report. Findings with credentials in the URL
+ * are always quarantined.
+ */
+export interface QuarantinePolicy {
+    /** Host feeds (from the data bundle) whose hits are quarantined (default: ['malicious']) */
+    feeds?: string[];
+    /** Ids of rules whose violations are quarantined */
+    rules?: string[];
+}
+
+/**
+ * What the main report says about the quarantined findings.
+ */
+export interface QuarantineSummary {
+    /** Path of the restricted report holding the quarantined findings */
+    file: string;
+    /** Number of quarantined findings */
+    count: number;
+    /** Number of files with quarantined findings */
+    files: number;
+    /** Quarantined findings per reason ('credentials', 'feed:<name>', or 'rule:<id>') */
+    reasons: Record<string, number>;
+}
+
+/**
+ * Checks whether a URL carries credentials: a password in the user info, or any user info in an
+ * http(s) URL, where it is usually an access token.
+ *
+ * @param url The URL
+ * @returns true if the URL has credentials
+ */
+export function hasCredentials(url: string): boolean {
+    const match = url.match(/^([a-zA-Z][a-zA-Z0-9+.-]*):\/\/([^/?#@\s]+)@/);
+    if (!match) return false;
+
+    const scheme = match[1].toLowerCase();
+    return match[2].includes(':') || scheme === 'http' || scheme === 'https';
+}
+
+/**
+ * Returns the reasons a finding must be quarantined.
+ *
+ * @param finding The finding
+ * @param policy Feeds and rules to quarantine
+ * @returns Reasons ('credentials', 'feed:<name>', or 'rule:<id>'), empty when the finding can be reported
+ */
+export function getQuarantineReasons(finding: URLMatch, policy: QuarantinePolicy = {}): string[] {
+    const reasons: string[] = [];
+    if (hasCredentials(finding.url)) reasons.push(CREDENTIALS_REASON);
+
+    const quarantinedFeeds = policy.feeds || DEFAULT_QUARANTINE_FEEDS;
+    const feeds = ((finding.attributes && finding.attributes[FEED_ATTRIBUTE]) || '').split(' ');
+    feeds.filter(feed => quarantinedFeeds.includes(feed)).forEach(feed => reasons.push(`feed:${feed}`));
+
+    const quarantinedRules = policy.rules || [];
+    for (const violation of finding.violations || []) {
+        const reason = `rule:${violation.rule}`;
+        if (quarantinedRules.includes(violation.rule) && !reasons.includes(reason)) reasons.push(reason);
+    }
+
+    return reasons;
+}
+
+/**
+ * Moves high-risk findings out of the scan results. Quarantined findings get their reasons in the
+ * 'quarantine' attribute, and files left without findings are dropped from the kept results.
+ *
+ * @param results Scan results
+ * @param policy Feeds and rules to quarantine
+ * @returns The results safe to report, and the quarantined findings
+ */
+export function splitQuarantined(
+    results: FileResult[],
+    policy: QuarantinePolicy = {},
+): { kept: FileResult[]; quarantined: FileResult[] } {
+    const kept: FileResult[] = [];
+    const quarantined: FileResult[] = [];
+
+    for (const result of results) {
+        const safe: URLMatch[] = [];
+        const sensitive: URLMatch[] = [];
+        for (const urlObj of result.urls) {
+            const reasons = getQuarantineReasons(urlObj, policy);
+            if (reasons.length > 0) {
+                setFindingAttribute(urlObj, QUARANTINE_ATTRIBUTE, reasons.join(' '));
+                sensitive.push(urlObj);
+            } else {
+                safe.push(urlObj);
+            }
+        }
+
+        if (safe.length > 0) kept.push({ ...result, urls: safe });
+        if (sensitive.length > 0) quarantined.push({ ...result, urls: sensitive });
+    }
+
+    return { kept, quarantined };
+}
+
+/**
+ * Summarizes quarantined findings for the main report without their URLs or locations.
+ *
+ * @param quarantined Quarantined findings returned by splitQuarantined()
+ * @param file Path of the quarantine report
+ * @returns Counts per reason
+ */
+export function summarizeQuarantine(quarantined: FileResult[], file: string): QuarantineSummary {
+    const reasons: Record<string, number> = {};
+    let count = 0;
+    for (const result of quarantined) {
+        for (const urlObj of result.urls) {
+            count++;
+            for (const reason of ((urlObj.attributes && urlObj.attributes[QUARANTINE_ATTRIBUTE]) || '').split(' ')) {
+                if (reason) reasons[reason] = (reasons[reason] || 0) + 1;
+            }
+        }
+    }
+    return { file, count, files: quarantined.length, reasons };
+}
+
+/**
+ * Writes quarantined findings as a JSON report readable and writable by the owner only.
+ *
+ * @param filePath Path of the quarantine report
+ * @param quarantined Quarantined findings returned by splitQuarantined()
+ * @param manifest Manifest of the scan the findings come from
+ */
+export async function writeQuarantineReport(
+    filePath: string,
+    quarantined: FileResult[],
+    manifest?: ScanManifest,
+): Promise<void> {
+    const text = serializeReport(createReport(quarantined, undefined, manifest), 'json');
+    // The mode only applies to new files, so an existing (possibly world-readable) report is replaced
+    await fs.promises.rm(filePath, { force: true });
+    await fs.promises.writeFile(filePath, text, { encoding: 'utf8', mode: QUARANTINE_FILE_MODE, flag: 'wx' });
+}
@@ -0,0 +1,303 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import { CANARY_ATTRIBUTE } from './canaryTokens';
+import { REPLACEMENT_ATTRIBUTE } from './deprecations';
+import { normalizeFingerprintPath } from './fingerprint';
+import { toAsciiHost } from './idn';
+import { SCHEME_POLICY_RULE } from './schemePolicy';
+import { FileResult, URLMatch } from './urlFilter';
+
+/** Comment marker that drops the findings on the line after it */
+export const SUPPRESSION_MARKER = 'url-detector-ignore-next-line';
+
+/** Secure scheme each insecure scheme is upgraded to */
+const SECURE_SCHEMES = new Map([
+    ['http', 'https'],
+    ['ws', 'wss'],
+    ['ftp', 'ftps'],
+]);
+
+/** Comment syntaxes and the extensions (or file names) of the files using them */
+const COMMENT_SYNTAXES: Array<{ open: string; close: string; files: string[] }> = [
+    {
+        open: '// ',
+        close: '',
+        files: ['.js', '.jsx', '.mjs', '.cjs', '.ts', '.tsx', '.java', '.c', '.h', '.cc', '.cpp', '.hpp', '.cs'],
+    },
+    { open: '// ', close: '', files: ['.go', '.rs', '.kt', '.kts', '.scala', '.sc', '.swift', '.php', '.dart'] },
+    { open: '// ', close: '', files: ['.groovy', '.gradle', '.proto', '.jsonc'] },
+    { open: '# ', close: '', files: ['.py', '.rb', '.sh', '.bash', '.zsh', '.yaml', '.yml', '.toml', '.pl', '.r'] },
+    { open: '# ', close: '', files: ['.ps1', '.properties', '.conf', 'dockerfile', 'makefile'] },
+    { open: '-- ', close: '', files: ['.sql', '.lua', '.hs'] },
+    { open: '<!-- ', close: ' -->', files: ['.html', '.htm', '.xml', '.md', '.markdown', '.vue', '.svelte'] },
+    { open: '/* ', close: ' */', files: ['.css', '.scss', '.less'] },
+];
+
+/** Lines of unchanged context around each change in a patch */
+const CONTEXT_LINES = 3;
+
+/**
+ * How a finding is fixed: its scheme upgraded to the secure variant, its host replaced through the
+ * domain map, its deprecated endpoint replaced, or the finding suppressed with a comment.
+ */
+export type QuickfixKind = 'https-upgrade' | 'domain-mapping' | 'deprecation-replacement' | 'suppression';
+
+/**
+ * Replacement of a range of a file's content.
+ */
+export interface TextEdit {
+    start: number;
+    end: number;
+    text: string;
+}
+
+/**
+ * A fix for one finding.
+ */
+export interface Quickfix {
+    /** How the finding is fixed; a URL can have its host mapped and its scheme upgraded at once */
+    kinds: QuickfixKind[];
+    url: string;
+    edit: TextEdit;
+}
+
+/**
+ * Options for finding fixes.
+ */
+export interface QuickfixOptions {
+    /** Hosts to replace, old host to new host (e.g., { 'api.old.example.com': 'api.example.com' }) */
+    domainMap?: Record<string, string>;
+}
+
+/**
+ * Decides how a finding is fixed. A deprecated endpoint with a replacement is replaced; otherwise the
+ * host is mapped through the domain map, and URLs flagged by the scheme policy get the secure variant
+ * of their scheme. Other findings with violations are suppressed with a comment on the line before,
+ * in files whose comment syntax is known. Canary token tripwires are never touched.
+ *
+ * @param finding The finding
+ * @param content Content of the file the finding is in
+ * @param filePath Path of the file, whose extension decides the comment syntax
+ * @param options Fix options
+ * @returns The fix, or null when the finding needs none or cannot be fixed
+ */
+export function planQuickfix(
+    finding: URLMatch,
+    content: string,
+    filePath: string,
+    options: QuickfixOptions = {},
+): Quickfix | null {
+    // Findings whose text was normalized or that come from other sources have nothing to edit in place
+    if (content.substring(finding.start, finding.end) !== finding.url) return null;
+    if (finding.attributes && finding.attributes[CANARY_ATTRIBUTE] === 'tripwire') return null;
+
+    const kinds: QuickfixKind[] = [];
+    let url = finding.url;
+    const replacement = finding.attributes && finding.attributes[REPLACEMENT_ATTRIBUTE];
+    if (replacement) {
+        url = replacement;
+        kinds.push('deprecation-replacement');
+    } else {
+        const mapped = mapHost(url, options.domainMap || {});
+        if (mapped !== url) {
+            url = mapped;
+            kinds.push('domain-mapping');
+        }
+        const violations = finding.violations || [];
+        const scheme = /^([a-z][a-z0-9+.-]*):\/\//i.exec(url);
+        const secure = scheme ? SECURE_SCHEMES.get(scheme[1].toLowerCase()) : undefined;
+        if (scheme && secure && violations.some(violation => violation.rule === SCHEME_POLICY_RULE)) {
+            url = secure + url.substring(scheme[1].length);
+            kinds.push('https-upgrade');
+        }
+    }
+    if (kinds.length > 0) {
+        return { kinds, url: finding.url, edit: { start: finding.start, end: finding.end, text: url } };
+    }
+
+    const syntax = getCommentSyntax(filePath);
+    if (!syntax || !finding.violations || finding.violations.length === 0) return null;
+
+    // The comment goes on a line of its own, indented like the line of the finding
+    const lineStart = content.lastIndexOf('\n', finding.start - 1) + 1;
+    const lineEnd = content.indexOf('\n', lineStart);
+    const indent = /^[ \t]*/.exec(content.substring(lineStart, finding.start))![0];
+    const eol = lineEnd > 0 && content.charAt(lineEnd - 1) === '\r' ? '\r\n' : '\n';
+    const text = `${indent}${syntax.open}${SUPPRESSION_MARKER}${syntax.close}${eol}`;
+    return { kinds: ['suppression'], url: finding.url, edit: { start: lineStart, end: lineStart, text } };
+}
+
+/**
+ * Drops the findings on lines that follow a line containing the suppression marker.
+ *
+ * @param urls Findings in the content
+ * @param content Content the findings were detected in
+ * @returns The findings that are not suppressed
+ */
+export function dropSuppressed(urls: URLMatch[], content: string): URLMatch[] {
+    if (!content.includes(SUPPRESSION_MARKER)) return urls;
+
+    return urls.filter(finding => {
+        const lineStart = content.lastIndexOf('\n', finding.start - 1) + 1;
+        if (lineStart === 0) return true;
+        const previousStart = content.lastIndexOf('\n', lineStart - 2) + 1;
+        return !content.substring(previousStart, lineStart).includes(SUPPRESSION_MARKER);
+    });
+}
+
+/**
+ * Creates unified diffs fixing every autofixable finding, one per file, in the format `git apply` and
+ * `patch -p1` accept. Files are read from disk, so the results must describe the current tree; files
+ * that cannot be read are left out. Overlapping fixes are applied first come, first served, and a line
+ * is suppressed at most once.
+ *
+ * @param results Scan results
+ * @param options Fix options
+ * @returns The patches, or an empty string when nothing can be fixed
+ */
+export async function createPatchset(results: FileResult[], options: QuickfixOptions = {}): Promise<string> {
+    const patches: string[] = [];
+    for (const result of results) {
+        if (result.urls.length === 0) continue;
+
+        let content: string;
+        try {
+            content = await fs.promises.readFile(result.file, 'utf8');
+        } catch {
+            continue;
+        }
+
+        const edits: TextEdit[] = [];
+        for (const finding of result.urls) {
+            const fix = planQuickfix(finding, content, result.file, options);
+            if (!fix) continue;
+            const overlaps = edits.some(({ start, end }) =>
+                start === end ? start === fix.edit.start : fix.edit.start < end && start < fix.edit.end,
+            );
+            if (!overlaps) edits.push(fix.edit);
+        }
+        if (edits.length > 0) {
+            patches.push(formatUnifiedDiff(normalizeFingerprintPath(result.file), content, edits));
+        }
+    }
+    return patches.join('');
+}
+
+/**
+ * Formats the unified diff of applying edits to a file's content. Edits must not overlap, and none may
+ * span a line break, so each one changes a single line or inserts lines before one.
+ *
+ * @param filePath Path of the file in the diff headers, relative to the repository root
+ * @param content Current content of the file
+ * @param edits The edits
+ * @returns The diff, with git headers
+ */
+export function formatUnifiedDiff(filePath: string, content: string, edits: TextEdit[]): string {
+    const lines = splitLines(content);
+    const lineStarts: number[] = [];
+    let lineStart = 0;
+    for (const line of lines) {
+        lineStarts.push(lineStart);
+        lineStart += line.length;
+    }
+
+    // New text of every changed line, by line index
+    const changed = new Map<number, string>();
+    const byLine = new Map<number, TextEdit[]>();
+    for (const edit of edits) {
+        const index = lineIndexOf(lineStarts, edit.start);
+        byLine.set(index, [...(byLine.get(index) || []), edit]);
+    }
+    for (const [index, lineEdits] of byLine) {
+        let text = lines[index];
+        for (const edit of lineEdits.sort((a, b) => b.start - a.start)) {
+            const start = edit.start - lineStarts[index];
+            text = text.substring(0, start) + edit.text + text.substring(edit.end - lineStarts[index]);
+        }
+        changed.set(index, text);
+    }
+
+    const indexes = Array.from(changed.keys()).sort((a, b) => a - b);
+    let output = `diff --git a/${filePath} b/${filePath}\n--- a/${filePath}\n+++ b/${filePath}\n`;
+    let offset = 0;
+    for (let i = 0; i < indexes.length; ) {
+        // Changes closer than twice the context share a hunk
+        let j = i;
+        while (j + 1 < indexes.length && indexes[j + 1] - indexes[j] <= 2 * CONTEXT_LINES) j++;
+        const from = Math.max(0, indexes[i] - CONTEXT_LINES);
+        const to = Math.min(lines.length - 1, indexes[j] + CONTEXT_LINES);
+
+        let body = '';
+        let added = 0;
+        for (let index = from; index <= to; index++) {
+            const text = changed.get(index);
+            if (text === undefined) {
+                body += diffLine(' ', lines[index]);
+                continue;
+            }
+            const newLines = splitLines(text);
+            body += diffLine('-', lines[index]) + newLines.map(line => diffLine('+', line)).join('');
+            added += newLines.length - 1;
+        }
+
+        const oldCount = to - from + 1;
+        output += `@@ -${from + 1},${oldCount} +${from + 1 + offset},${oldCount + added} @@\n${body}`;
+        offset += added;
+        i = j + 1;
+    }
+    return output;
+}
+
+function mapHost(url: string, domainMap: Record<string, string>): string {
+    const authority = /^((?:[a-z][a-z0-9+.-]*:)?\/\/(?:[^/?#@]*@)?)(\[[^\]]*\]|[^:/?#]*)/i.exec(url);
+    if (!authority) return url;
+
+    const host = toAsciiHost(authority[2]);
+    const target = Object.keys(domainMap).find(key => toAsciiHost(key) === host);
+    return target ? authority[1] + domainMap[target] + url.substring(authority[0].length) : url;
+}
+
+function getCommentSyntax(filePath: string): { open: string; close: string } | null {
+    const basename = path.basename(filePath).toLowerCase();
+    const extension = path.extname(basename);
+    return COMMENT_SYNTAXES.find(syntax => syntax.files.includes(extension || basename)) || null;
+}
+
+/**
+ * Splits text into lines that keep their line breaks, so joining them gives the text back.
+ */
+function splitLines(text: string): string[] {
+    return text.match(/[^\n]*\n|[^\n]+$/g) || [];
+}
+
+/**
+ * Index of the line containing an offset, by binary search of the line start offsets.
+ */
+function lineIndexOf(lineStarts: number[], offset: number): number {
+    let low = 0;
+    let high = lineStarts.length - 1;
+    while (low < high) {
+        const middle = Math.ceil((low + high) / 2);
+        if (lineStarts[middle] <= offset) low = middle;
+        else high = middle - 1;
+    }
+    return low;
+}
+
+function diffLine(prefix: string, line: string): string {
+    return line.endsWith('\n') ? `${prefix}${line}` : `${prefix}${line}\n\\ No newline at end of file\n`;
+}
@@ -0,0 +1,188 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as http from 'http';
+import * as https from 'https';
+import * as net from 'net';
+import * as tls from 'tls';
+import pLimit from 'p-limit';
+import { DEFAULT_HTTP_CLIENT, HttpClient } from './httpClient';
+import { FileResult } from './urlFilter';
+
+/** Egress name for requests made without a proxy */
+export const DIRECT_EGRESS = 'direct';
+
+/** Default number of URLs probed concurrently */
+const PROBE_CONCURRENCY = 10;
+
+/**
+ * Outcome of requesting a URL through one egress path. A URL is reachable when any HTTP response
+ * comes back, whatever its status: the question is whether the network path exists, not whether
+ * the endpoint is healthy.
+ */
+export interface ProbeResult {
+    reachable: boolean;
+    /** HTTP status of the response */
+    status?: number;
+    /** Why the URL could not be reached */
+    error?: string;
+}
+
+/**
+ * Reachability of one URL from every egress path.
+ */
+export interface ReachabilityEntry {
+    url: string;
+    /** Results keyed by egress name */
+    results: Record<string, ProbeResult>;
+}
+
+/**
+ * Probes a URL with a HEAD request, directly or through an HTTP proxy. HTTPS URLs are tunnelled
+ * through the proxy with CONNECT.
+ *
+ * @param url The http(s) URL to probe
+ * @param proxyUrl Proxy as http://[user:password@]host:port; the request is made directly when omitted
+ * @param timeoutMs Timeout of the request in milliseconds (default: 10000)
+ * @param client Network stack whose https agent and DNS lookup are used (default: Node's)
+ * @returns The probe result
+ */
+export async function probeUrl(
+    url: string,
+    proxyUrl?: string,
+    timeoutMs: number = 10000,
+    client: HttpClient = DEFAULT_HTTP_CLIENT,
+): Promise<ProbeResult> {
+    try {
+        const status = await new Promise<number>((resolve, reject) => {
+            // Tunnelled sockets outlive the CONNECT request and are closed separately
+            const sockets: net.Socket[] = [];
+            const settle = (callback: () => void) => {
+                clearTimeout(timer);
+                request.destroy();
+                sockets.forEach(socket => socket.destroy());
+                callback();
+            };
+            const timeout = new Error(`Timed out after ${timeoutMs}ms`);
+            const timer = setTimeout(() => settle(() => reject(timeout)), timeoutMs);
+            const request = createProbeRequest(
+                new URL(url),
+                proxyUrl ? new URL(proxyUrl) : undefined,
+                status => settle(() => resolve(status)),
+                error => settle(() => reject(error)),
+                socket => sockets.push(socket),
+                client,
+            );
+            request.on('error', error => settle(() => reject(error)));
+            request.end();
+        });
+        return { reachable: true, status };
+    } catch (error: any) {
+        return { reachable: false, error: error.message };
+    }
+}
+
+function createProbeRequest(
+    target: URL,
+    proxy: URL | undefined,
+    resolve: (status: number) => void,
+    reject: (error: Error) => void,
+    track: (socket: net.Socket) => void,
+    client: HttpClient,
+): http.ClientRequest {
+    const onResponse = (response: http.IncomingMessage) => {
+        response.resume();
+        resolve(response.statusCode || 0);
+    };
+    const path = `${target.pathname}${target.search}`;
+    const lookup = client.lookup;
+
+    if (!proxy) {
+        return target.protocol === 'https:'
+            ? https.request(target, { method: 'HEAD', agent: client.agent, lookup }, onResponse)
+            : http.request(target, { method: 'HEAD', lookup }, onResponse);
+    }
+
+    const proxyHeaders: http.OutgoingHttpHeaders = {};
+    if (proxy.username) {
+        const credentials = `${decodeURIComponent(proxy.username)}:${decodeURIComponent(proxy.password)}`;
+        proxyHeaders['Proxy-Authorization'] = `Basic ${Buffer.from(credentials).toString('base64')}`;
+    }
+    const proxyOptions = { host: proxy.hostname, port: proxy.port || 80, lookup };
+
+    if (target.protocol !== 'https:') {
+        // Plain HTTP goes through the proxy as an absolute-form request
+        return http.request({ ...proxyOptions, method: 'HEAD', path: target.href, headers: proxyHeaders }, onResponse);
+    }
+
+    const targetPort = target.port || '443';
+    const connect = http.request({
+        ...proxyOptions,
+        method: 'CONNECT',
+        path: `${target.hostname}:${targetPort}`,
+        headers: { ...proxyHeaders, Host: `${target.hostname}:${targetPort}` },
+    });
+    connect.on('connect', (response: http.IncomingMessage, socket: net.Socket) => {
+        track(socket);
+        if (response.statusCode !== 200) {
+            reject(new Error(`Proxy refused CONNECT: HTTP ${response.statusCode}`));
+            return;
+        }
+        const request = https.request(
+            {
+                host: target.hostname,
+                path,
+                method: 'HEAD',
+                createConnection: () =>
+                    tls.connect({ ...(client.agent ? client.agent.options : {}), socket, servername: target.hostname }),
+            },
+            onResponse,
+        );
+        request.on('error', reject);
+        request.end();
+    });
+    return connect;
+}
+
+/**
+ * Probes every distinct http(s) URL in the results through every egress path, showing which
+ * environments can actually reach each hard-coded endpoint.
+ *
+ * @param results Scan results
+ * @param proxies Proxy URL by egress name; use DIRECT_EGRESS with an empty URL for direct requests
+ * @param timeoutMs Timeout per request in milliseconds (default: 10000)
+ * @param client Network stack whose https agent and DNS lookup are used (default: Node's)
+ * @returns One entry per distinct URL, sorted by URL
+ */
+export async function buildReachabilityMatrix(
+    results: FileResult[],
+    proxies: Record<string, string>,
+    timeoutMs: number = 10000,
+    client: HttpClient = DEFAULT_HTTP_CLIENT,
+): Promise<ReachabilityEntry[]> {
+    const urls = Array.from(new Set(results.flatMap(result => result.urls.map(urlObj => urlObj.url))))
+        .filter(url => /^https?:\/\//i.test(url))
+        .sort();
+    const limit = pLimit(PROBE_CONCURRENCY);
+
+    return Promise.all(
+        urls.map(async url => {
+            const entry: ReachabilityEntry = { url, results: {} };
+            for (const [name, proxyUrl] of Object.entries(proxies)) {
+                entry.results[name] = await limit(() => probeUrl(url, proxyUrl || undefined, timeoutMs, client));
+            }
+            return entry;
+        }),
+    );
+}
@@ -0,0 +1,115 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { tokenKindAt, tokenize } from './genericTokenizer';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Category assigned to WebSocket URLs and gRPC targets, which network reviews treat apart from HTTP */
+export const REALTIME_RPC_CATEGORY = 'realtime-rpc';
+
+/** Attribute holding the protocol of a realtime/RPC endpoint */
+export const PROTOCOL_ATTRIBUTE = 'protocol';
+
+/** Protocol of a realtime/RPC endpoint */
+export type RealtimeProtocol = 'websocket' | 'grpc';
+
+const SCHEME_PROTOCOLS: Record<string, RealtimeProtocol> = { ws: 'websocket', wss: 'websocket', dns: 'grpc' };
+
+/**
+ * The target argument of grpc.Dial, grpc.DialContext (after the context), and grpc.NewClient calls
+ * when it is a string literal; group 1 is the target.
+ */
+const GRPC_DIAL = /\bgrpc\.(?:(?:Dial|NewClient)\s*\(|DialContext\s*\(\s*[\w.]+(?:\(\))?\s*,)\s*["`]([^"`\n]+)["`]/g;
+
+/** Targets of local sockets, which are not network endpoints */
+const LOCAL_TARGET = /^unix(?:-abstract)?:/i;
+
+/**
+ * Classifies WebSocket URLs (`ws://`, `wss://`) and gRPC DNS targets (`dns:///host:port`) in the
+ * 'realtime-rpc' category, with the protocol in the 'protocol' attribute. The URL pattern reports
+ * these schemes as protocol-relative matches, so the scheme is read from the source in front of the
+ * finding. Findings that already have a category are left alone.
+ *
+ * @param finding The finding
+ * @param content Content of the file the finding is in
+ */
+export function classifyRealtimeEndpoint(finding: URLMatch, content: string): void {
+    if (finding.category) return;
+
+    const protocol = SCHEME_PROTOCOLS[schemeOf(finding, content) || ''];
+    if (protocol) {
+        finding.category = REALTIME_RPC_CATEGORY;
+        setFindingAttribute(finding, PROTOCOL_ATTRIBUTE, protocol);
+    }
+}
+
+/**
+ * Extracts gRPC targets passed as string literals to grpc.Dial, grpc.DialContext, and
+ * grpc.NewClient in Go source code, such as `api.example.com:443` or `dns:///api.example.com:443`.
+ * Unix socket targets are skipped. Calls in comments are reported with the 'comment' source type.
+ *
+ * @param sourceCode Go source code
+ * @returns Findings in the 'realtime-rpc' category, in source order
+ */
+export function extractGrpcTargets(sourceCode: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+    const tokens = tokenize(sourceCode);
+
+    for (const match of sourceCode.matchAll(GRPC_DIAL)) {
+        const target = match[1].trim();
+        if (!target || LOCAL_TARGET.test(target)) continue;
+
+        const start = match.index! + match[0].length - 1 - match[1].length + match[1].indexOf(target);
+        const lines = sourceCode.substring(0, start).split('\n');
+        const finding: URLMatch = {
+            url: target,
+            start,
+            end: start + target.length,
+            line: lines.length,
+            column: lines[lines.length - 1].length + 1,
+            sourceType: tokenKindAt(tokens, match.index!) === 'comment' ? 'comment' : 'string',
+            category: REALTIME_RPC_CATEGORY,
+        };
+        setFindingAttribute(finding, PROTOCOL_ATTRIBUTE, 'grpc');
+        findings.push(finding);
+    }
+
+    return findings;
+}
+
+/**
+ * Adds gRPC targets to the URLs of a file. URLs inside a target (the `//host:port` of
+ * `dns:///host:port`) are dropped, so each target is reported once.
+ *
+ * @param urls URLs found in the file
+ * @param targets Targets from extractGrpcTargets()
+ * @returns The URLs and targets in source order
+ */
+export function mergeGrpcTargets(urls: URLMatch[], targets: URLMatch[]): URLMatch[] {
+    if (targets.length === 0) return urls;
+
+    const inTarget = (url: URLMatch) => targets.some(target => url.start >= target.start && url.end <= target.end);
+    return [...urls.filter(url => !inTarget(url)), ...targets].sort((a, b) => a.start - b.start);
+}
+
+function schemeOf(finding: URLMatch, content: string): string | null {
+    const explicit = /^([a-z][a-z0-9+.-]*):/i.exec(finding.url);
+    if (explicit) return explicit[1].toLowerCase();
+    if (!finding.url.startsWith('//')) return null;
+
+    // dns:///host leaves 'dns:/' in front of the //host match
+    const before = content.substring(Math.max(0, finding.start - 32), finding.start);
+    const preceding = /([a-z][a-z0-9+.-]*):\/?$/i.exec(before);
+    return preceding ? preceding[1].toLowerCase() : null;
+}
@@ -0,0 +1,177 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Category assigned to relative URLs and path references */
+export const RELATIVE_URL_CATEGORY = 'relative-url';
+
+/** Languages in which relative references are detected */
+export const RELATIVE_URL_LANGUAGES = ['html', 'css', 'javascript', 'typescript'];
+
+/** What a relative reference points at */
+export type RelativeReferenceKind = 'asset' | 'route' | 'path';
+
+/** File extensions of static assets; references ending in one of these are assets rather than routes */
+const ASSET_EXTENSIONS = new Set([
+    'png',
+    'jpg',
+    'jpeg',
+    'gif',
+    'svg',
+    'webp',
+    'avif',
+    'ico',
+    'bmp',
+    'css',
+    'js',
+    'mjs',
+    'map',
+    'json',
+    'woff',
+    'woff2',
+    'ttf',
+    'otf',
+    'eot',
+    'mp4',
+    'webm',
+    'mp3',
+    'wav',
+    'ogg',
+    'pdf',
+    'txt',
+    'xml',
+    'html',
+    'htm',
+    'wasm',
+]);
+
+// Patterns capture the text before the reference as group 1 and the reference as group 2
+
+/** HTML attributes that hold URLs */
+const HTML_URL_ATTRIBUTE = /(\b(?:href|src|action|formaction|poster|data|background|cite)\s*=\s*["'])([^"'\s]+)["']/gi;
+
+/** srcset lists several candidates: "a.png 1x, b.png 2x" */
+const HTML_SRCSET_ATTRIBUTE = /(\bsrcset\s*=\s*["'])([^"']+)["']/gi;
+
+/** CSS url(...) values, quoted or not */
+const CSS_URL_FUNCTION = /(\burl\(\s*["']?)([^"')\s]+)/gi;
+
+/** CSS @import with a bare string */
+const CSS_IMPORT = /(@import\s+["'])([^"']+)["']/gi;
+
+/** String literals starting with /, ./, or ../ */
+const SCRIPT_PATH_STRING = /(["'`])(\.{0,2}\/(?!\/)[^"'`\s]+)\1/g;
+
+/** Module specifiers in import, export, require, and dynamic import, which are code dependencies rather than URLs */
+const SCRIPT_MODULE_SPECIFIER = /(?:\bfrom\s*|\brequire\s*\(\s*|\bimport\s*\(\s*|^\s*import\s+)$/;
+
+/**
+ * Detects relative URLs and path references (`/api/v2/users`, `../assets/logo.png`) in HTML, CSS,
+ * JavaScript, and TypeScript. In HTML and CSS every relative URL attribute and `url()` value is reported;
+ * in scripts only string literals starting with `/`, `./`, or `../`, excluding module specifiers.
+ *
+ * @param sourceCode Source code to scan
+ * @param language Language identifier of the source (see RELATIVE_URL_LANGUAGES)
+ * @returns Findings in the 'relative-url' category, in source order
+ */
+export function extractRelativeUrls(sourceCode: string, language: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+
+    if (language === 'html') {
+        collect(findings, sourceCode, HTML_URL_ATTRIBUTE);
+        for (const match of sourceCode.matchAll(HTML_SRCSET_ATTRIBUTE)) {
+            let offset = match.index! + match[1].length;
+            for (const candidate of match[2].split(',')) {
+                const reference = candidate.trim().split(/\s+/)[0];
+                const start = offset + candidate.indexOf(reference);
+                if (reference && isRelativeReference(reference)) {
+                    findings.push(createFinding(sourceCode, reference, start));
+                }
+                offset += candidate.length + 1;
+            }
+        }
+        // Inline styles and scripts
+        collect(findings, sourceCode, CSS_URL_FUNCTION);
+        collectScriptPaths(findings, sourceCode);
+    } else if (language === 'css') {
+        collect(findings, sourceCode, CSS_URL_FUNCTION);
+        collect(findings, sourceCode, CSS_IMPORT);
+    } else if (language === 'javascript' || language === 'typescript') {
+        collectScriptPaths(findings, sourceCode);
+    }
+
+    // HTML attributes are also matched as string literals; keep one finding per position
+    const unique = new Map<number, URLMatch>();
+    for (const finding of findings) {
+        if (!unique.has(finding.start)) unique.set(finding.start, finding);
+    }
+    return Array.from(unique.values()).sort((a, b) => a.start - b.start);
+}
+
+/**
+ * Classifies a relative reference as a static asset (by file extension), a route (root-relative
+ * without an asset extension), or another relative path.
+ *
+ * @param reference The relative reference
+ * @returns The kind of reference
+ */
+export function classifyRelativeReference(reference: string): RelativeReferenceKind {
+    const pathname = reference.split(/[?#]/)[0];
+    const extension = pathname.includes('.') ? pathname.substring(pathname.lastIndexOf('.') + 1).toLowerCase() : '';
+    if (ASSET_EXTENSIONS.has(extension)) return 'asset';
+    return pathname.startsWith('/') ? 'route' : 'path';
+}
+
+function collect(findings: URLMatch[], sourceCode: string, pattern: RegExp): void {
+    for (const match of sourceCode.matchAll(pattern)) {
+        if (isRelativeReference(match[2])) {
+            findings.push(createFinding(sourceCode, match[2], match.index! + match[1].length));
+        }
+    }
+}
+
+function collectScriptPaths(findings: URLMatch[], sourceCode: string): void {
+    for (const match of sourceCode.matchAll(SCRIPT_PATH_STRING)) {
+        const lineStart = sourceCode.lastIndexOf('\n', match.index!) + 1;
+        if (SCRIPT_MODULE_SPECIFIER.test(sourceCode.substring(lineStart, match.index!))) continue;
+
+        findings.push(createFinding(sourceCode, match[2], match.index! + match[1].length));
+    }
+}
+
+function isRelativeReference(reference: string): boolean {
+    // Absolute URLs (any scheme), protocol-relative URLs, fragments, and template placeholders are skipped
+    return (
+        !/^[a-zA-Z][a-zA-Z0-9+.-]*:/.test(reference) &&
+        !reference.startsWith('//') &&
+        !reference.startsWith('#') &&
+        !/^[{<$]/.test(reference)
+    );
+}
+
+function createFinding(sourceCode: string, reference: string, start: number): URLMatch {
+    const lines = sourceCode.substring(0, start).split('\n');
+    const finding: URLMatch = {
+        url: reference,
+        start,
+        end: start + reference.length,
+        line: lines.length,
+        column: lines[lines.length - 1].length + 1,
+        sourceType: 'string',
+        category: RELATIVE_URL_CATEGORY,
+    };
+    setFindingAttribute(finding, 'referenceKind', classifyRelativeReference(reference));
+    return finding;
+}
@@ -0,0 +1,536 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { URLMatch } from './urlFilter';
+import { FileResult } from './urlDetector';
+import { Severity, Violation, compareSeverity } from './ruleEngine';
+import { FINGERPRINT_VERSION } from './fingerprint';
+import { EnvironmentService } from './environments';
+import { FindingGroup } from './findingGroups';
+import { TeamRollup } from './teamRollup';
+import { ReachabilityEntry } from './reachability';
+import { DuplicateEndpoint } from './duplicateEndpoints';
+import { PortUsage } from './portInventory';
+import { LanguageCoverage } from './coverage';
+import { SkippedFile } from './skipDiagnostics';
+import { QuarantineSummary } from './quarantine';
+import { ScanManifest } from './manifest';
+import { IncreaseAlert } from './increaseAlert';
+import { SampleEstimate } from './sampling';
+
+// eslint-disable-next-line @typescript-eslint/no-require-imports
+const packageJson = require('../package.json');
+
+/** Name of the tool as reported in machine-readable formats */
+export const TOOL_NAME = 'url-detector';
+
+/** Version of the tool as reported in machine-readable formats */
+export const TOOL_VERSION: string = packageJson.version;
+
+/**
+ * Machine-readable report formats that can be both written and read back.
+ */
+export type ReportFormat = 'json' | 'ndjson' | 'sarif';
+
+/**
+ * All report formats supported by the codec.
+ */
+export const REPORT_FORMATS: ReportFormat[] = ['json', 'ndjson', 'sarif'];
+
+/**
+ * Aggregate counts for a scan.
+ */
+export interface OutputSummary {
+    totalFiles: number;
+    totalUrls: number;
+    uniqueUrls: number;
+}
+
+/**
+ * Report-level analyses that describe the scan as a whole rather than a single finding.
+ */
+export interface ReportSections {
+    /** Services with missing or inconsistent per-environment endpoints */
+    environments?: EnvironmentService[];
+    /** Attribute the findings are grouped by in groups (e.g., 'owner') */
+    groupedBy?: string;
+    /** Findings grouped by the groupedBy attribute */
+    groups?: FindingGroup[];
+    /** Findings, new and fixed findings, and worst offending files per team */
+    teams?: TeamRollup[];
+    /** Which egress paths can reach each http(s) URL */
+    reachability?: ReachabilityEntry[];
+    /** URLs hard-coded in many files, with consolidation hints */
+    duplicates?: DuplicateEndpoint[];
+    /** Non-standard ports hard-coded in URLs, by host */
+    ports?: PortUsage[];
+    /** Files scanned without a parser for their language, by extension */
+    coverage?: LanguageCoverage;
+    /** Files that were not scanned for URLs, with the reason for each */
+    skipped?: SkippedFile[];
+    /** Counts of the high-risk findings moved to the restricted quarantine report */
+    quarantine?: QuarantineSummary;
+    /** Growth in findings since the baseline run, checked against the --alert-on-increase threshold */
+    increaseAlert?: IncreaseAlert;
+    /** Totals a full scan is estimated to report, when only a sample of files was scanned */
+    sample?: SampleEstimate;
+}
+
+/**
+ * Serialized form of a single finding in the JSON report.
+ */
+export interface JsonUrlEntry {
+    url: string;
+    line?: number;
+    column?: number;
+    start: number;
+    end: number;
+    sourceType?: URLMatch['sourceType'];
+    context?: string[];
+    category?: string;
+    violations?: Violation[];
+    attributes?: Record<string, string>;
+    fingerprint?: string;
+}
+
+/**
+ * Shape of the JSON report format.
+ */
+export interface JsonOutput {
+    summary: OutputSummary;
+    files: Array<{
+        file: string;
+        urlCount: number;
+        urls: JsonUrlEntry[];
+    }>;
+    sections?: ReportSections;
+    manifest?: ScanManifest;
+}
+
+/**
+ * In-memory representation of a scan report shared by every format.
+ */
+export interface Report {
+    /** Aggregate counts for the scan */
+    summary: OutputSummary;
+    /** Per-file results */
+    files: FileResult[];
+    /** Report-level analyses, present only when requested */
+    sections?: ReportSections;
+    /** What was scanned and with which tool, grammar, config, and policy versions */
+    manifest?: ScanManifest;
+}
+
+/**
+ * Builds a report from scan results.
+ *
+ * @param results Results returned by URLDetector.process()
+ * @param sections Report-level analyses to include
+ * @param manifest Reproducibility metadata from URLDetector.createManifest()
+ * @returns Report with a computed summary
+ */
+export function createReport(results: FileResult[], sections?: ReportSections, manifest?: ScanManifest): Report {
+    const uniqueUrls = new Set<string>();
+    for (const result of results) {
+        for (const urlObj of result.urls) {
+            uniqueUrls.add(urlObj.url);
+        }
+    }
+
+    const report: Report = {
+        summary: {
+            totalFiles: results.length,
+            totalUrls: results.reduce((sum, r) => sum + r.urls.length, 0),
+            uniqueUrls: uniqueUrls.size,
+        },
+        files: results,
+    };
+    if (sections) report.sections = sections;
+    if (manifest) report.manifest = manifest;
+    return report;
+}
+
+/**
+ * Converts a report to the JSON report structure.
+ *
+ * @param report The report to convert
+ * @param withLineNumbers Whether to include line and column numbers (default: true)
+ * @returns The JSON report structure
+ */
+export function toJsonOutput(report: Report, withLineNumbers: boolean = true): JsonOutput {
+    return {
+        summary: report.summary,
+        files: report.files.map(result => ({
+            file: result.file,
+            urlCount: result.urls.length,
+            urls: result.urls
+                .map(urlObj => ({
+                    url: urlObj.url,
+                    line: withLineNumbers ? urlObj.line : undefined,
+                    column: withLineNumbers ? urlObj.column : undefined,
+                    start: urlObj.start,
+                    end: urlObj.end,
+                    sourceType: urlObj.sourceType,
+                    context: urlObj.context,
+                    category: urlObj.category,
+                    violations: urlObj.violations,
+                    attributes: urlObj.attributes,
+                    fingerprint: urlObj.fingerprint,
+                }))
+                .filter(url => url.line !== undefined || !withLineNumbers),
+        })),
+        sections: report.sections,
+        manifest: report.manifest,
+    };
+}
+
+function fromJsonUrlEntry(entry: JsonUrlEntry): URLMatch {
+    const urlObj: URLMatch = {
+        url: entry.url,
+        start: entry.start,
+        end: entry.end,
+        line: entry.line ?? 0,
+        column: entry.column ?? 0,
+        sourceType: entry.sourceType || 'unknown',
+    };
+    if (entry.context) urlObj.context = entry.context;
+    if (entry.category) urlObj.category = entry.category;
+    if (entry.violations) urlObj.violations = entry.violations;
+    if (entry.attributes) urlObj.attributes = entry.attributes;
+    if (entry.fingerprint) urlObj.fingerprint = entry.fingerprint;
+    return urlObj;
+}
+
+function serializeJson(report: Report): string {
+    return JSON.stringify(toJsonOutput(report), null, 2);
+}
+
+function parseJson(text: string): Report {
+    const data = JSON.parse(text) as JsonOutput;
+    if (!data || !Array.isArray(data.files)) {
+        throw new Error('Invalid JSON report: missing files array');
+    }
+
+    const files = data.files.map(entry => ({
+        file: entry.file,
+        urls: (entry.urls || []).map(fromJsonUrlEntry),
+    }));
+    const report: Report = { summary: data.summary || createReport(files).summary, files };
+    return withSections(report, data.sections, data.manifest);
+}
+
+function withSections(report: Report, sections: ReportSections | undefined, manifest?: ScanManifest): Report {
+    if (sections) report.sections = sections;
+    if (manifest) report.manifest = manifest;
+    return report;
+}
+
+/**
+ * Serializes one finding as the finding record of an ndjson report, for output written as findings
+ * are found rather than all at once.
+ *
+ * @param file File the finding was detected in
+ * @param urlObj The finding
+ * @returns One line of ndjson, without the line break
+ */
+export function serializeNdjsonFinding(file: string, urlObj: URLMatch): string {
+    return JSON.stringify({ type: 'finding', file, ...toJsonEntry(urlObj) });
+}
+
+function serializeNdjson(report: Report): string {
+    const lines: string[] = [];
+    if (report.manifest) {
+        lines.push(JSON.stringify({ type: 'manifest', ...report.manifest }));
+    }
+    for (const result of report.files) {
+        for (const urlObj of result.urls) {
+            lines.push(serializeNdjsonFinding(result.file, urlObj));
+        }
+    }
+    if (report.sections) {
+        lines.push(JSON.stringify({ type: 'sections', ...report.sections }));
+    }
+    lines.push(JSON.stringify({ type: 'summary', ...report.summary }));
+    return lines.join('\n');
+}
+
+function toJsonEntry(urlObj: URLMatch): JsonUrlEntry {
+    return {
+        url: urlObj.url,
+        line: urlObj.line,
+        column: urlObj.column,
+        start: urlObj.start,
+        end: urlObj.end,
+        sourceType: urlObj.sourceType,
+        context: urlObj.context,
+        category: urlObj.category,
+        violations: urlObj.violations,
+        attributes: urlObj.attributes,
+        fingerprint: urlObj.fingerprint,
+    };
+}
+
+function parseNdjson(text: string): Report {
+    const byFile = new Map<string, URLMatch[]>();
+    let summary: OutputSummary | null = null;
+    let sections: ReportSections | undefined;
+    let manifest: ScanManifest | undefined;
+
+    const lines = text.split('\n');
+    for (let index = 0; index < lines.length; index++) {
+        if (lines[index].trim().length === 0) continue;
+
+        let record: Record<string, unknown>;
+        try {
+            record = JSON.parse(lines[index]);
+        } catch {
+            throw new Error(`Invalid ndjson report: line ${index + 1} is not valid JSON`);
+        }
+
+        if (record.type === 'summary') {
+            summary = {
+                totalFiles: record.totalFiles as number,
+                totalUrls: record.totalUrls as number,
+                uniqueUrls: record.uniqueUrls as number,
+            };
+        } else if (record.type === 'sections') {
+            sections = Object.fromEntries(Object.entries(record).filter(([key]) => key !== 'type'));
+        } else if (record.type === 'manifest') {
+            manifest = Object.fromEntries(Object.entries(record).filter(([key]) => key !== 'type')) as ScanManifest;
+        } else if (record.type === 'finding') {
+            const file = record.file as string;
+            if (!byFile.has(file)) byFile.set(file, []);
+            byFile.get(file)!.push(fromJsonUrlEntry(record as unknown as JsonUrlEntry));
+        }
+    }
+
+    const files = Array.from(byFile.entries()).map(([file, urls]) => ({ file, urls }));
+    return withSections({ summary: summary || createReport(files).summary, files }, sections, manifest);
+}
+
+const SARIF_SCHEMA = 'https://json.schemastore.org/sarif-2.1.0.json';
+const SARIF_DETECTION_RULE = 'url-detected';
+const SARIF_FINGERPRINT_KEY = `urlDetector/${FINGERPRINT_VERSION}`;
+
+function toSarifLevel(severity: Severity | undefined): string {
+    switch (severity) {
+        case 'error':
+            return 'error';
+        case 'warning':
+            return 'warning';
+        default:
+            return 'note';
+    }
+}
+
+function serializeSarif(report: Report): string {
+    const ruleIds = new Set<string>([SARIF_DETECTION_RULE]);
+    const results: unknown[] = [];
+
+    report.files.forEach((result, artifactIndex) => {
+        for (const urlObj of result.urls) {
+            const violations = urlObj.violations || [];
+            violations.forEach(violation => ruleIds.add(violation.rule));
+
+            // The rule and level both come from the most severe violation, the first of equally severe ones
+            const primary = violations.reduce<Violation | undefined>(
+                (most, violation) =>
+                    !most || compareSeverity(violation.severity, most.severity) > 0 ? violation : most,
+                undefined,
+            );
+
+            const messages = [`URL detected: ${urlObj.url}`, ...violations.map(v => `${v.rule}: ${v.message}`)];
+            results.push({
+                ruleId: primary ? primary.rule : SARIF_DETECTION_RULE,
+                level: toSarifLevel(primary && primary.severity),
+                message: { text: messages.join('\n') },
+                locations: [
+                    {
+                        physicalLocation: {
+                            artifactLocation: { uri: result.file, index: artifactIndex },
+                            region: {
+                                startLine: urlObj.line,
+                                startColumn: urlObj.column,
+                                charOffset: urlObj.start,
+                                charLength: urlObj.end - urlObj.start,
+                            },
+                        },
+                    },
+                ],
+                partialFingerprints: urlObj.fingerprint ? { [SARIF_FINGERPRINT_KEY]: urlObj.fingerprint } : undefined,
+                properties: {
+                    url: urlObj.url,
+                    sourceType: urlObj.sourceType,
+                    context: urlObj.context,
+                    category: urlObj.category,
+                    violations: urlObj.violations,
+                    attributes: urlObj.attributes,
+                },
+            });
+        }
+    });
+
+    const sarif = {
+        $schema: SARIF_SCHEMA,
+        version: '2.1.0',
+        runs: [
+            {
+                tool: {
+                    driver: {
+                        name: TOOL_NAME,
+                        version: TOOL_VERSION,
+                        informationUri: packageJson.homepage,
+                        rules: Array.from(ruleIds).map(id => ({ id })),
+                    },
+                },
+                artifacts: report.files.map(result => ({ location: { uri: result.file } })),
+                results,
+                properties: { summary: report.summary, sections: report.sections, manifest: report.manifest },
+            },
+        ],
+    };
+
+    return JSON.stringify(sarif, null, 2);
+}
+
+/* eslint-disable @typescript-eslint/no-explicit-any */
+function parseSarif(text: string): Report {
+    const sarif = JSON.parse(text);
+    const run = sarif && Array.isArray(sarif.runs) ? sarif.runs[0] : undefined;
+    if (!run) {
+        throw new Error('Invalid SARIF report: missing runs');
+    }
+
+    const files: FileResult[] = (run.artifacts || []).map((artifact: any) => ({
+        file: artifact.location.uri,
+        urls: [],
+    }));
+    const byFile = new Map<string, FileResult>(files.map(result => [result.file, result]));
+
+    for (const result of run.results || []) {
+        const location = result.locations && result.locations[0] && result.locations[0].physicalLocation;
+        if (!location) continue;
+
+        const uri: string = location.artifactLocation.uri;
+        if (!byFile.has(uri)) {
+            const fileResult: FileResult = { file: uri, urls: [] };
+            files.push(fileResult);
+            byFile.set(uri, fileResult);
+        }
+
+        const region = location.region || {};
+        const properties = result.properties || {};
+        byFile.get(uri)!.urls.push(
+            fromJsonUrlEntry({
+                url: properties.url,
+                line: region.startLine,
+                column: region.startColumn,
+                start: region.charOffset ?? 0,
+                end: (region.charOffset ?? 0) + (region.charLength ?? 0),
+                sourceType: properties.sourceType,
+                context: properties.context,
+                category: properties.category,
+                violations: properties.violations,
+                attributes: properties.attributes,
+                fingerprint: result.partialFingerprints && result.partialFingerprints[SARIF_FINGERPRINT_KEY],
+            }),
+        );
+    }
+
+    const properties = run.properties || {};
+    const report: Report = { summary: properties.summary || createReport(files).summary, files };
+    return withSections(report, properties.sections, properties.manifest);
+}
+/* eslint-enable @typescript-eslint/no-explicit-any */
+
+/**
+ * Serializes a report in the given format.
+ *
+ * @param report The report to serialize
+ * @param format Target format
+ * @returns Serialized report text
+ */
+export function serializeReport(report: Report, format: ReportFormat): string {
+    switch (format) {
+        case 'json':
+            return serializeJson(report);
+        case 'ndjson':
+            return serializeNdjson(report);
+        case 'sarif':
+            return serializeSarif(report);
+        default:
+            throw new Error(`Unknown report format: ${format}`);
+    }
+}
+
+/**
+ * Parses report text previously produced by serializeReport().
+ *
+ * @param text Serialized report text
+ * @param format Format of the text, or undefined to detect it automatically
+ * @returns The parsed report
+ * @throws {Error} When the text is not a valid report in the given format
+ */
+export function parseReport(text: string, format?: ReportFormat): Report {
+    switch (format || detectReportFormat(text)) {
+        case 'json':
+            return parseJson(text);
+        case 'ndjson':
+            return parseNdjson(text);
+        case 'sarif':
+            return parseSarif(text);
+        default:
+            throw new Error(`Unknown report format: ${format}`);
+    }
+}
+
+/**
+ * Detects the format of serialized report text.
+ *
+ * @param text Serialized report text
+ * @returns The detected format
+ */
+export function detectReportFormat(text: string): ReportFormat {
+    try {
+        const data = JSON.parse(text);
+        if (data && Array.isArray(data.runs)) return 'sarif';
+        if (data && Array.isArray(data.files)) return 'json';
+    } catch {
+        // Multiple JSON documents separated by newlines
+    }
+    return 'ndjson';
+}
+
+/**
+ * Reads and parses a report file, detecting its format from the content.
+ *
+ * @param filePath Path to the report file
+ * @returns Promise resolving to the parsed report
+ */
+export async function readReport(filePath: string): Promise<Report> {
+    const text = await fs.promises.readFile(filePath, 'utf8');
+    return parseReport(text);
+}
+
+/**
+ * Serializes a report and writes it to a file.
+ *
+ * @param filePath Path to the output file
+ * @param report The report to write
+ * @param format Target format
+ */
+export async function writeReport(filePath: string, report: Report, format: ReportFormat): Promise<void> {
+    await fs.promises.writeFile(filePath, serializeReport(report, format), 'utf8');
+}
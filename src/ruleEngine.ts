@@ -0,0 +1,192 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { CodeScope } from './codeScope';
+import { Logger, NullLogger } from './logger';
+import { URLMatch } from './urlFilter';
+
+/**
+ * Severity levels for rule violations, ordered from least to most severe.
+ */
+export type Severity = 'info' | 'warning' | 'error';
+
+/**
+ * All severity levels in ascending order of severity.
+ */
+export const SEVERITIES: Severity[] = ['info', 'warning', 'error'];
+
+/**
+ * A policy violation raised by a rule against a single finding.
+ */
+export interface Violation {
+    /** Identifier of the rule that raised this violation */
+    rule: string;
+    /** Severity of the violation */
+    severity: Severity;
+    /** Human-readable description of the problem */
+    message: string;
+}
+
+/**
+ * Metadata about the file a finding was detected in, passed to every rule evaluation.
+ */
+export interface FileContext {
+    /** Path to the file that was scanned */
+    file: string;
+    /** Detected language of the file (e.g., 'javascript', or 'unknown') */
+    language: string;
+    /** Full text content of the file */
+    content: string;
+    /** Whether the file is test or production code (undefined for non-file sources) */
+    scope?: CodeScope;
+}
+
+/**
+ * A programmatic policy rule evaluated against every finding that survives filtering.
+ *
+ * @example
+ * ```typescript
+ * const noPlainHttp: Rule = {
+ *     id: 'no-plain-http',
+ *     evaluate: finding =>
+ *         finding.url.startsWith('http://')
+ *             ? [{ rule: 'no-plain-http', severity: 'error', message: 'Use https' }]
+ *             : [],
+ * };
+ * ```
+ */
+export interface Rule {
+    /** Unique identifier for the rule (e.g., 'no-plain-http') */
+    id: string;
+    /** Optional human-readable description of what the rule checks */
+    description?: string;
+    /**
+     * Evaluates a single finding and returns any violations.
+     *
+     * @param finding The URL finding to evaluate
+     * @param file Metadata about the file the finding was detected in
+     * @returns Array of violations, or an empty array if the finding is acceptable
+     */
+    evaluate(finding: URLMatch, file: FileContext): Violation[];
+}
+
+/**
+ * Compares two severities.
+ *
+ * @returns A negative number if a is less severe than b, zero if equal, positive otherwise
+ */
+export function compareSeverity(a: Severity, b: Severity): number {
+    return SEVERITIES.indexOf(a) - SEVERITIES.indexOf(b);
+}
+
+/**
+ * Returns the highest severity among a finding's violations, or undefined if it has none.
+ *
+ * @param finding The finding to inspect
+ * @returns The most severe violation level, or undefined
+ */
+export function getFindingSeverity(finding: URLMatch): Severity | undefined {
+    let highest: Severity | undefined;
+    for (const violation of finding.violations || []) {
+        if (!highest || compareSeverity(violation.severity, highest) > 0) {
+            highest = violation.severity;
+        }
+    }
+    return highest;
+}
+
+/**
+ * Holds registered rules and evaluates them against findings.
+ *
+ * Rules are evaluated in registration order. A rule that throws is logged and skipped
+ * so that one faulty rule cannot abort the whole scan.
+ */
+export class RuleEngine {
+    private rules: Map<string, Rule>;
+    private logger: Logger;
+
+    /**
+     * Creates a new RuleEngine.
+     *
+     * @param logger Optional logger used to report rules that throw during evaluation
+     */
+    constructor(logger?: Logger) {
+        this.rules = new Map();
+        this.logger = logger || NullLogger;
+    }
+
+    /**
+     * Registers a rule. A rule with the same id replaces the existing one.
+     *
+     * @param rule The rule to register
+     * @throws {Error} When the rule has no id
+     */
+    public register(rule: Rule): void {
+        if (!rule.id) {
+            throw new Error('Rule must have a non-empty id');
+        }
+        this.rules.set(rule.id, rule);
+    }
+
+    /**
+     * Removes a registered rule by id.
+     *
+     * @param id The id of the rule to remove
+     * @returns true if the rule was found and removed, false otherwise
+     */
+    public unregister(id: string): boolean {
+        return this.rules.delete(id);
+    }
+
+    /**
+     * Gets all registered rules in registration order.
+     *
+     * @returns Array of registered rules
+     */
+    public getRules(): Rule[] {
+        return Array.from(this.rules.values());
+    }
+
+    /**
+     * Evaluates all registered rules against each finding and attaches the resulting
+     * violations to the finding's `violations` array.
+     *
+     * @param findings Findings detected in a single file
+     * @param file Metadata about the file the findings were detected in
+     * @returns The same findings, with violations attached where rules raised any
+     */
+    public evaluate(findings: URLMatch[], file: FileContext): URLMatch[] {
+        if (this.rules.size === 0) {
+            return findings;
+        }
+
+        for (const finding of findings) {
+            const violations: Violation[] = [];
+            for (const rule of this.rules.values()) {
+                try {
+                    violations.push(...rule.evaluate(finding, file));
+                } catch (error: unknown) {
+                    const errorMessage = error instanceof Error ? error.message : String(error);
+                    this.logger.warn(`Rule ${rule.id} failed on ${file.file}: ${errorMessage}`);
+                }
+            }
+
+            if (violations.length > 0) {
+                finding.violations = [...(finding.violations || []), ...violations];
+            }
+        }
+
+        return findings;
+    }
+}
@@ -0,0 +1,174 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import { normalizeFingerprintPath } from './fingerprint';
+import { FileResult } from './urlFilter';
+
+/** Confidence level of the estimated intervals */
+export const SAMPLE_CONFIDENCE = 0.95;
+
+/** Standard normal quantile for SAMPLE_CONFIDENCE */
+const Z_SCORE = 1.96;
+
+/**
+ * Estimate of a total over the whole tree, from the sampled files.
+ */
+export interface EstimatedTotal {
+    /** Count in the sampled files */
+    observed: number;
+    /** Count the full tree is estimated to have */
+    estimate: number;
+    /** Lower bound of the confidence interval, never below the observed count */
+    low: number;
+    /** Upper bound of the confidence interval */
+    high: number;
+}
+
+/**
+ * Totals a full scan is estimated to report, extrapolated from a sampled scan.
+ */
+export interface SampleEstimate {
+    /** Number of files a full scan would read */
+    population: number;
+    /** Number of files sampled */
+    sampled: number;
+    /** The sample size as given (e.g., '5%') */
+    rate: string;
+    /** Seed the sample was drawn with */
+    seed: string;
+    /** Confidence level of the intervals (e.g., 0.95) */
+    confidence: number;
+    findings: EstimatedTotal;
+    violations: EstimatedTotal;
+    filesWithFindings: EstimatedTotal;
+}
+
+/**
+ * Parses a sample size such as '5%' or '0.5%'.
+ *
+ * @param text The sample size
+ * @returns The fraction of files to sample, above 0 and at most 1
+ * @throws {Error} When the text is not a percentage above 0% and at most 100%
+ */
+export function parseSampleRate(text: string): number {
+    const match = text.trim().match(/^(\d+(?:\.\d+)?)%$/);
+    const percent = match ? parseFloat(match[1]) : NaN;
+    if (!(percent > 0 && percent <= 100)) {
+        throw new Error(`Invalid sample size: ${text}. Use a percentage above 0% and at most 100% (e.g., 5%)`);
+    }
+    return percent / 100;
+}
+
+/**
+ * Draws a deterministic pseudo-random sample of files: files are ranked by a hash of the seed and
+ * their path relative to the working directory, and the first ones are taken. The same tree, rate,
+ * and seed always give the same sample, on any machine, and a larger rate adds files to a smaller
+ * rate's sample instead of drawing a new one.
+ *
+ * @param files Files to sample from
+ * @param rate Fraction of files to sample, as returned by parseSampleRate()
+ * @param seed Seed; change it to draw a different sample (default: '')
+ * @returns The sampled files in their original order; at least one when files is not empty
+ */
+export function selectSample(files: string[], rate: number, seed: string = ''): string[] {
+    const size = Math.min(files.length, Math.ceil(files.length * rate));
+    const ranked = files
+        .map(file => ({ file, rank: sampleRank(file, seed) }))
+        .sort((a, b) => a.rank.localeCompare(b.rank))
+        .slice(0, size);
+    const selected = new Set(ranked.map(entry => entry.file));
+    return files.filter(file => selected.has(file));
+}
+
+/**
+ * Extrapolates the totals of a full scan from the results of a sampled one. Sampled files without
+ * results (skipped, or without findings) count as files without findings. Intervals use the normal
+ * approximation with the finite population correction, so they narrow as the sample grows and
+ * collapse to the observed counts when every file is sampled.
+ *
+ * @param results Results of the sampled scan; results for files outside the sample are ignored
+ * @param sample The sampled files
+ * @param population Number of files the sample was drawn from
+ * @param rate The sample size as given (e.g., '5%')
+ * @param seed Seed the sample was drawn with
+ * @returns The estimate
+ */
+export function estimateTotals(
+    results: FileResult[],
+    sample: string[],
+    population: number,
+    rate: string,
+    seed: string = '',
+): SampleEstimate {
+    const byFile = new Map(results.map(result => [result.file, result]));
+    const findings: number[] = [];
+    const violations: number[] = [];
+    const withFindings: number[] = [];
+    for (const file of sample) {
+        const urls = byFile.has(file) ? byFile.get(file)!.urls : [];
+        findings.push(urls.length);
+        violations.push(urls.reduce((sum, url) => sum + (url.violations ? url.violations.length : 0), 0));
+        withFindings.push(urls.length > 0 ? 1 : 0);
+    }
+
+    return {
+        population,
+        sampled: sample.length,
+        rate,
+        seed,
+        confidence: SAMPLE_CONFIDENCE,
+        findings: estimateTotal(findings, population),
+        violations: estimateTotal(violations, population),
+        filesWithFindings: estimateTotal(withFindings, population, population),
+    };
+}
+
+/**
+ * Describes an estimate in one line for logs.
+ *
+ * @param estimate The estimate
+ * @returns The description
+ */
+export function formatSampleEstimate(estimate: SampleEstimate): string {
+    const total = (name: string, value: EstimatedTotal) => `${name} ~${value.estimate} (${value.low}-${value.high})`;
+    return (
+        `Sampled ${estimate.sampled} of ${estimate.population} file(s) (${estimate.rate}); ` +
+        `estimated totals at ${Math.round(estimate.confidence * 100)}% confidence: ` +
+        `${total('findings', estimate.findings)}, ${total('violations', estimate.violations)}, ` +
+        `${total('files with findings', estimate.filesWithFindings)}`
+    );
+}
+
+function estimateTotal(values: number[], population: number, max: number = Infinity): EstimatedTotal {
+    const n = values.length;
+    const observed = values.reduce((sum, value) => sum + value, 0);
+    if (n === 0) return { observed, estimate: 0, low: 0, high: 0 };
+
+    const mean = observed / n;
+    const variance = n > 1 ? values.reduce((sum, value) => sum + (value - mean) ** 2, 0) / (n - 1) : 0;
+    const margin = Z_SCORE * population * Math.sqrt((Math.max(0, 1 - n / population) * variance) / n);
+    const estimate = population * mean;
+
+    return {
+        observed,
+        estimate: Math.round(estimate),
+        low: Math.max(observed, Math.floor(estimate - margin)),
+        high: Math.min(max, Math.ceil(estimate + margin)),
+    };
+}
+
+function sampleRank(file: string, seed: string): string {
+    return crypto.createHash('sha256').update(`${seed}\0${normalizeFingerprintPath(file)}`).digest('hex');
+}
@@ -0,0 +1,287 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import * as path from 'path';
+import { writeAtomically } from './atomicWrite';
+import { compileFilter } from './filterExpression';
+import { Finding } from './sinks';
+import { FileResult } from './urlFilter';
+
+/** Default directory of the scan store, relative to the working directory */
+export const DEFAULT_SCAN_STORE = '.url-detector/scans';
+
+/** Default number of scans kept; older scans are deleted as new ones are saved */
+export const DEFAULT_SCAN_RETENTION = 100;
+
+/** Default number of findings per page */
+export const DEFAULT_PAGE_SIZE = 100;
+
+/** Largest number of findings a page may hold */
+export const MAX_PAGE_SIZE = 1000;
+
+/** Version of the scan store index layout */
+const SCAN_INDEX_VERSION = 1;
+
+const INDEX_FILE = 'index.json';
+const SCAN_ID = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/;
+
+/**
+ * A completed scan, without its results.
+ */
+export interface ScanSummary {
+    /** Identifier of the scan */
+    id: string;
+    /** Policy profile the scan ran with */
+    profile: string;
+    /** ISO timestamp of completion */
+    createdAt: string;
+    totalFiles: number;
+    totalFindings: number;
+}
+
+/**
+ * A completed scan with its results.
+ */
+export interface StoredScan extends ScanSummary {
+    results: FileResult[];
+}
+
+interface ScanIndexFile {
+    version: number;
+    scans: ScanSummary[];
+}
+
+/**
+ * Selection of findings from a stored scan. Every criterion given must match.
+ */
+export interface FindingQuery {
+    /** Host, or '*.' followed by a domain to match its subdomains */
+    host?: string;
+    /** Lowest violation severity; findings without violations count as 'none' */
+    severity?: string;
+    /** Id of a rule with a violation on the finding */
+    rule?: string;
+    /** Path of the file the finding is in */
+    file?: string;
+    category?: string;
+    /** Filter expression, as for --filter */
+    filter?: string;
+    /** Number of matching findings to skip (default: 0) */
+    offset: number;
+    /** Largest number of findings to return (default: DEFAULT_PAGE_SIZE) */
+    limit: number;
+}
+
+/**
+ * One page of the findings matching a query.
+ */
+export interface FindingPage {
+    /** Number of findings matching the query across all pages */
+    total: number;
+    offset: number;
+    limit: number;
+    /** Offset of the next page, or null on the last page */
+    nextOffset: number | null;
+    findings: Finding[];
+}
+
+/**
+ * Reads a finding query from URL query parameters: host, severity, rule, file, category, filter,
+ * offset, and limit.
+ *
+ * @param params The query parameters
+ * @returns The query
+ * @throws {Error} When offset or limit is not a whole number in range
+ */
+export function parseFindingQuery(params: URLSearchParams): FindingQuery {
+    const query: FindingQuery = {
+        offset: parseBound(params.get('offset'), 'offset', 0, Number.MAX_SAFE_INTEGER, 0),
+        limit: parseBound(params.get('limit'), 'limit', 1, MAX_PAGE_SIZE, DEFAULT_PAGE_SIZE),
+    };
+    for (const key of ['host', 'severity', 'rule', 'file', 'category', 'filter'] as const) {
+        const value = params.get(key);
+        if (value) query[key] = value;
+    }
+    return query;
+}
+
+/**
+ * Selects one page of the findings matching a query, in file order. The criteria are translated into a
+ * filter expression, so they behave exactly like --filter.
+ *
+ * @param results Scan results
+ * @param query The query
+ * @returns The page
+ * @throws {Error} When the filter expression or severity is invalid
+ */
+export function queryFindings(results: FileResult[], query: FindingQuery): FindingPage {
+    const conditions: string[] = [];
+    if (query.host) {
+        conditions.push(
+            query.host.startsWith('*.')
+                ? `host endswith ${quote(query.host.substring(1).toLowerCase())}`
+                : `host == ${quote(query.host.toLowerCase())}`,
+        );
+    }
+    if (query.severity) conditions.push(`severity >= ${quote(query.severity)}`);
+    if (query.rule) conditions.push(`rule == ${quote(query.rule)}`);
+    if (query.file) conditions.push(`file == ${quote(query.file)}`);
+    if (query.category) conditions.push(`category == ${quote(query.category)}`);
+    if (query.filter) conditions.push(`(${query.filter})`);
+    const predicate = conditions.length > 0 ? compileFilter(conditions.join(' and ')) : null;
+
+    const matching: Finding[] = [];
+    for (const result of results) {
+        for (const finding of result.urls) {
+            if (!predicate || predicate(finding, result.file)) matching.push({ ...finding, file: result.file });
+        }
+    }
+
+    const end = query.offset + query.limit;
+    return {
+        total: matching.length,
+        offset: query.offset,
+        limit: query.limit,
+        nextOffset: end < matching.length ? end : null,
+        findings: matching.slice(query.offset, end),
+    };
+}
+
+/**
+ * Completed scans kept in a directory, one JSON file per scan plus an index, so server clients can page
+ * through the findings of a large scan instead of downloading its whole report. Only the most recent
+ * scans are kept.
+ */
+export class ScanStore {
+    private directory: string;
+    private retention: number;
+    private recent: StoredScan | null = null;
+    private writes: Promise<unknown> = Promise.resolve();
+
+    /**
+     * @param directory Directory of the store (default: .url-detector/scans)
+     * @param retention Number of scans kept (default: 100)
+     */
+    constructor(directory: string = DEFAULT_SCAN_STORE, retention: number = DEFAULT_SCAN_RETENTION) {
+        this.directory = directory;
+        this.retention = retention;
+    }
+
+    /**
+     * Lists the stored scans, newest first.
+     *
+     * @param profile Only list the scans of this profile (default: all)
+     * @returns The scans
+     * @throws {Error} When the index is not a valid scan index
+     */
+    public async list(profile?: string): Promise<ScanSummary[]> {
+        const scans = await this.readIndex();
+        return scans.filter(scan => profile === undefined || scan.profile === profile).reverse();
+    }
+
+    /**
+     * Stores the results of a completed scan, deleting the oldest scans beyond the retention limit.
+     * Saves are serialized, so concurrent scans never overwrite each other's index entries.
+     *
+     * @param profile Policy profile the scan ran with
+     * @param results The results
+     * @param now Time of completion (default: now)
+     * @returns Summary of the stored scan
+     */
+    public save(profile: string, results: FileResult[], now: Date = new Date()): Promise<ScanSummary> {
+        const saved = this.writes.then(() => this.write(profile, results, now));
+        this.writes = saved.catch(() => undefined);
+        return saved;
+    }
+
+    /**
+     * Loads a stored scan. The most recently loaded scan is kept in memory, since clients page through
+     * one scan at a time.
+     *
+     * @param id Identifier of the scan
+     * @returns The scan, or null when no such scan is stored
+     */
+    public async load(id: string): Promise<StoredScan | null> {
+        if (!SCAN_ID.test(id)) return null;
+        if (this.recent && this.recent.id === id) return this.recent;
+
+        let text: string;
+        try {
+            text = await fs.promises.readFile(this.scanPath(id), 'utf8');
+        } catch (error: any) {
+            if (error.code === 'ENOENT') return null;
+            throw error;
+        }
+        this.recent = JSON.parse(text) as StoredScan;
+        return this.recent;
+    }
+
+    private async write(profile: string, results: FileResult[], now: Date): Promise<ScanSummary> {
+        const summary: ScanSummary = {
+            id: crypto.randomUUID(),
+            profile,
+            createdAt: now.toISOString(),
+            totalFiles: results.length,
+            totalFindings: results.reduce((sum, result) => sum + result.urls.length, 0),
+        };
+        await writeAtomically(this.scanPath(summary.id), JSON.stringify({ ...summary, results }));
+
+        const scans = [...(await this.readIndex()), summary];
+        const expired = scans.splice(0, Math.max(0, scans.length - this.retention));
+        const index: ScanIndexFile = { version: SCAN_INDEX_VERSION, scans };
+        await writeAtomically(path.join(this.directory, INDEX_FILE), JSON.stringify(index, null, 2));
+
+        for (const scan of expired) {
+            await fs.promises.rm(this.scanPath(scan.id), { force: true });
+            if (this.recent && this.recent.id === scan.id) this.recent = null;
+        }
+        return summary;
+    }
+
+    private async readIndex(): Promise<ScanSummary[]> {
+        const indexPath = path.join(this.directory, INDEX_FILE);
+        let text: string;
+        try {
+            text = await fs.promises.readFile(indexPath, 'utf8');
+        } catch (error: any) {
+            if (error.code === 'ENOENT') return [];
+            throw error;
+        }
+
+        const data = JSON.parse(text) as ScanIndexFile;
+        if (!data || !Array.isArray(data.scans)) {
+            throw new Error(`Invalid scan index ${indexPath}: missing scans array`);
+        }
+        return data.scans;
+    }
+
+    private scanPath(id: string): string {
+        return path.join(this.directory, `${id}.json`);
+    }
+}
+
+function parseBound(text: string | null, name: string, min: number, max: number, fallback: number): number {
+    if (text === null || text === '') return fallback;
+    const value = Number(text);
+    if (!Number.isInteger(value) || value < min || value > max) {
+        throw new Error(`Invalid ${name}: ${text}. Use a whole number from ${min} to ${max}`);
+    }
+    return value;
+}
+
+function quote(value: string): string {
+    return `"${value.replace(/["\\]/g, '\\$&')}"`;
+}
@@ -0,0 +1,739 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { CANARY_POLICIES } from './canaryTokens';
+import { FALSE_POSITIVE_HEURISTICS } from './falsePositives';
+import { LIFECYCLE_EVENT_TYPES } from './lifecycleEvents';
+import { PLUGIN_CAPABILITIES } from './pluginSandbox';
+import { SEVERITIES } from './ruleEngine';
+import { SKIP_REASONS } from './skipDiagnostics';
+
+/**
+ * The subset of JSON Schema (draft-07) used to describe the config file and report formats.
+ */
+export interface JsonSchema {
+    $schema?: string;
+    title?: string;
+    description?: string;
+    type?: JsonSchemaType | JsonSchemaType[];
+    enum?: unknown[];
+    minimum?: number;
+    items?: JsonSchema;
+    properties?: Record<string, JsonSchema>;
+    required?: string[];
+    additionalProperties?: boolean | JsonSchema;
+}
+
+type JsonSchemaType = 'object' | 'array' | 'string' | 'number' | 'integer' | 'boolean' | 'null';
+
+/**
+ * Documents that a schema is published for.
+ */
+export type SchemaName = 'config' | 'report';
+
+/**
+ * All published schemas.
+ */
+export const SCHEMA_NAMES: SchemaName[] = ['config', 'report'];
+
+const DRAFT_07 = 'http://json-schema.org/draft-07/schema#';
+
+function stringArray(description: string): JsonSchema {
+    return { type: 'array', items: { type: 'string' }, description };
+}
+
+function flag(description: string): JsonSchema {
+    return { type: 'boolean', description };
+}
+
+/**
+ * Schema of the config file, mirroring DetectorOptionsConfig.
+ */
+export const CONFIG_SCHEMA: JsonSchema = {
+    $schema: DRAFT_07,
+    title: 'url-detector configuration',
+    type: 'object',
+    properties: {
+        $schema: { type: 'string', description: 'Schema reference for editors' },
+        scan: stringArray('Glob patterns for files to scan (default: ["**/*"])'),
+        exclude: stringArray('Glob patterns for files to exclude'),
+        ignoreDomains: stringArray('Domain patterns to ignore; supports wildcards'),
+        includeComments: flag('Also scan commented-out lines for URLs'),
+        includeNonFqdn: flag('Include non-fully qualified domain names like "localhost"'),
+        // Sinks register formats at runtime, so the detector checks the name against the registry
+        format: {
+            type: 'string',
+            description: 'Output format: table, json, csv, ndjson, sarif, patchset, html, or a sink (default: table)',
+        },
+        output: { type: ['string', 'null'], description: 'Output file path, or null for stdout' },
+        outputEncoding: {
+            type: 'string',
+            enum: ['utf8', 'utf8-bom', 'utf16le'],
+            description: 'Encoding of the output file (default: utf8)',
+        },
+        asciiJson: flag('Escape non-ASCII characters in json, ndjson, and sarif output'),
+        csvFormulaGuard: flag('Quote CSV cells that spreadsheets would run as formulas (default: true)'),
+        csvFingerprints: flag('Add a Fingerprint column to CSV output'),
+        resultsOnly: flag('Show only results, suppressing progress and info messages'),
+        failOnError: flag('Exit with a non-zero code if any URLs are found'),
+        concurrency: { type: 'integer', minimum: 1, description: 'Maximum number of files to scan concurrently' },
+        chunkSize: {
+            type: 'integer',
+            minimum: 0,
+            description: 'Size in megabytes above which a file is scanned in concurrent segments; 0 never splits files',
+        },
+        maxFileSize: {
+            type: 'integer',
+            minimum: 0,
+            description: 'Size in megabytes above which a file is skipped; 0 scans files of any size',
+        },
+        parseTimeout: {
+            type: 'integer',
+            minimum: 0,
+            description: 'Milliseconds tree-sitter may spend parsing one file; 0 waits indefinitely',
+        },
+        sample: {
+            type: 'string',
+            description: "Percentage of files to scan, to estimate the totals of a full scan (e.g., '5%')",
+        },
+        sampleSeed: { type: 'string', description: 'Seed of the sample; change it to draw different files' },
+        maxDepth: { type: 'integer', minimum: 0, description: 'Maximum directory depth to scan' },
+        fallbackRegex: flag('Use regex detection when parsing fails (default: true)'),
+        genericTokenizer: flag('Tell strings and comments apart in files without a parser (default: true)'),
+        languageOptions: {
+            type: 'object',
+            description: 'Extraction settings for individual languages',
+            properties: {
+                go: {
+                    type: 'object',
+                    properties: {
+                        structTags: flag('Report URLs in struct tags (default: true)'),
+                        goGenerate: flag('Report URLs in //go:generate directives (default: true)'),
+                    },
+                    additionalProperties: false,
+                },
+                markdown: {
+                    type: 'object',
+                    properties: {
+                        codeBlocks: flag('Report URLs in fenced code blocks and inline code (default: true)'),
+                    },
+                    additionalProperties: false,
+                },
+                html: {
+                    type: 'object',
+                    properties: { dataAttributes: flag('Report URLs in data-* attribute values (default: true)') },
+                    additionalProperties: false,
+                },
+            },
+            additionalProperties: false,
+        },
+        context: { type: 'integer', minimum: 0, description: 'Number of context lines around detected URLs' },
+        includeGitMetadata: flag('Also scan commit messages, tag annotations, and .gitmodules'),
+        gitHistoryDepth: {
+            type: 'integer',
+            minimum: 0,
+            description: 'Most recent commits whose messages includeGitMetadata scans; 0 scans the whole history',
+        },
+        gitBlame: flag("Record the date and commit each finding's line was introduced, from git blame"),
+        skipGenerated: flag('Skip generated and minified files instead of tagging their findings'),
+        collapseDuplicates: flag('Report the findings of identical files, like vendored copies, only once'),
+        licenseHeaders: flag('Report only license header URLs and verify them against canonical URLs'),
+        checkLicenseLinks: flag('Also report unreachable license header URLs; implies licenseHeaders'),
+        validateDocLinks: flag('Check relative Markdown and HTML links for missing files and anchors'),
+        includeRelativeUrls: flag('Also report relative URLs and paths in HTML, CSS, and scripts'),
+        includeWindowsPaths: flag('Also report UNC paths and file URLs with drive letters'),
+        sriAdvisory: flag('Suggest Subresource Integrity for CDN scripts and stylesheets in HTML'),
+        encodingAnomalies: flag('Flag double-encoding, overlong UTF-8 encodings, and encoded line breaks in URLs'),
+        obfuscatedHosts: flag('Flag decimal, octal, and hexadecimal IP hosts and host names in the userinfo'),
+        openRedirect: flag('Flag URLs whose redirect parameters carry full URLs'),
+        redirectParams: stringArray('Query parameters the open redirect rule checks'),
+        canaryTokens: flag('Flag URLs on canary token hosts, such as canarytokens.com'),
+        canaryDomains: stringArray('More canary token domains, each a domain or domain=tripwire|leak'),
+        canaryPolicy: {
+            type: 'string',
+            enum: CANARY_POLICIES,
+            description: 'Whether canary token URLs are tripwires to keep or leaks to remove (default: tripwire)',
+        },
+        falsePositiveHeuristics: {
+            type: 'array',
+            items: { type: 'string', enum: FALSE_POSITIVE_HEURISTICS },
+            description: 'Heuristics reporting likely false positives, such as example.com URLs, as informational',
+        },
+        severityEscalation: {
+            type: 'array',
+            description: 'Path-based severity shifts applied to violations',
+            items: {
+                type: 'object',
+                properties: {
+                    paths: stringArray("Glob patterns; a trailing '/' covers everything below the directory"),
+                    levels: { type: 'integer', description: 'Severity levels to raise violations by (default: 1)' },
+                },
+                required: ['paths'],
+                additionalProperties: false,
+            },
+        },
+        categoryRules: {
+            type: 'array',
+            description: 'Host-to-category rules for plain URLs; the first matching rule wins',
+            items: {
+                type: 'object',
+                properties: {
+                    host: { type: 'string', description: "Exact host, or '*.' and a domain to match its subdomains" },
+                    hostRegex: { type: 'string', description: 'Regular expression tested against the host' },
+                    category: { type: 'string', description: 'Category given to matching URLs' },
+                },
+                required: ['category'],
+                additionalProperties: false,
+            },
+        },
+        schemePolicy: {
+            type: 'array',
+            description: 'Schemes forbidden on some or all hosts; the first matching entry wins',
+            items: {
+                type: 'object',
+                properties: {
+                    schemes: stringArray("Forbidden schemes, without '://'"),
+                    host: { type: 'string', description: "Exact host, or '*.' and a domain to match its subdomains" },
+                    hostRegex: { type: 'string', description: 'Regular expression tested against the host' },
+                    ports: {
+                        type: 'array',
+                        description: "Ports the entry applies to, counting the scheme's default port",
+                        items: { type: 'integer' },
+                    },
+                    require: { type: 'string', description: 'Scheme to use instead, named in the message' },
+                    severity: { type: 'string', enum: SEVERITIES, description: 'Default: error' },
+                    message: { type: 'string', description: 'Explanation appended to the violation message' },
+                },
+                required: ['schemes'],
+                additionalProperties: false,
+            },
+        },
+        codeOwners: flag('Attach the owners from the CODEOWNERS file to each finding'),
+        dataBundle: { type: 'string', description: 'Imported data bundle for TLD validation and host feed tagging' },
+        openApiSpecs: stringArray('OpenAPI documents (JSON) that map findings to APIs and flag undeclared endpoints'),
+        deprecationRegistry: {
+            type: 'string',
+            description: 'Registry (JSON) of deprecated URL prefixes with their replacement and sunset date',
+        },
+        domainMap: {
+            type: 'object',
+            description: 'Hosts replaced in --format patchset fixes, old host to new host',
+            additionalProperties: { type: 'string' },
+        },
+        triageStore: { type: 'string', description: 'Triage store whose states are carried forward onto findings' },
+        hideTriaged: flag('Drop findings triaged as accepted-risk or false-positive'),
+        filter: { type: 'string', description: 'Filter expression findings must match, or the name of one in filters' },
+        filters: {
+            type: 'object',
+            description: 'Named filter expressions, selected with filter',
+            additionalProperties: { type: 'string' },
+        },
+        email: {
+            type: 'object',
+            description: 'Email the scan summary after the scan',
+            properties: {
+                smtp: { type: 'string', description: 'SMTP server as smtp://[user@]host[:port] or smtps://...' },
+                from: { type: 'string', description: 'Sender address' },
+                to: stringArray('Recipient addresses'),
+                subject: { type: 'string', description: 'Subject line (default: finding and file counts)' },
+                attachReport: flag('Attach the full JSON report'),
+            },
+            required: ['smtp', 'from', 'to'],
+            additionalProperties: false,
+        },
+        jira: {
+            type: 'object',
+            description: 'Jira project to open issues in for new error-severity findings (with --create-jira-issues)',
+            properties: {
+                url: { type: 'string', description: 'Base URL of the Jira instance' },
+                project: { type: 'string', description: 'Key of the project issues are created in' },
+                issueType: { type: 'string', description: 'Issue type (default: Bug)' },
+                labels: stringArray('Additional labels for every issue'),
+                user: { type: 'string', description: 'Account for basic authentication (default: bearer token)' },
+            },
+            required: ['url', 'project'],
+            additionalProperties: false,
+        },
+        webhooks: {
+            type: 'array',
+            description: 'Webhooks finding lifecycle events are posted to after the scan',
+            items: {
+                type: 'object',
+                properties: {
+                    url: { type: 'string', description: 'URL the events are posted to' },
+                    events: {
+                        type: 'array',
+                        description: 'Event types posted (default: all)',
+                        items: { type: 'string', enum: [...LIFECYCLE_EVENT_TYPES] },
+                    },
+                    headers: {
+                        type: 'object',
+                        description: 'Additional request headers, e.g. for authentication',
+                        additionalProperties: { type: 'string' },
+                    },
+                },
+                required: ['url'],
+                additionalProperties: false,
+            },
+        },
+        teams: {
+            type: 'object',
+            description: 'Team by path prefix for --team-rollup; the longest matching prefix wins',
+            additionalProperties: { type: 'string' },
+        },
+        egressProxies: {
+            type: 'object',
+            description: 'Egress proxy URLs by environment name for --reachability-matrix (empty for direct)',
+            additionalProperties: { type: 'string' },
+        },
+        auditGoImports: flag('Report Go import and module paths and audit them against goImportPolicy'),
+        goImportPolicy: {
+            type: 'object',
+            description: 'Policy for Go import auditing',
+            properties: {
+                deprecatedHosts: stringArray('Hosts to flag in Go import paths (default: ["code.google.com"])'),
+                forbidGopkgIn: flag('Flag Go imports through gopkg.in'),
+                allowedOwners: stringArray('Allowed github.com/gitlab.com/bitbucket.org owners for Go imports'),
+            },
+            additionalProperties: false,
+        },
+        goNetworkCalls: flag('Mark Go URLs with the HTTP, gRPC, or WebSocket call they flow into'),
+        rulePlugins: {
+            type: 'array',
+            description: 'Third-party rule plugins, run in a sandbox with time and memory limits',
+            items: {
+                type: 'object',
+                properties: {
+                    module: { type: 'string', description: 'Path or package name of the single-file plugin module' },
+                    timeoutMs: {
+                        type: 'integer',
+                        minimum: 1,
+                        description: 'Time limit of a single rule evaluation, in milliseconds (default: 1000)',
+                    },
+                    memoryMb: {
+                        type: 'integer',
+                        minimum: 1,
+                        description: 'Heap limit of the plugin, in megabytes (default: 64)',
+                    },
+                    capabilities: {
+                        type: 'array',
+                        description: 'Access granted to the plugin (default: none)',
+                        items: { type: 'string', enum: PLUGIN_CAPABILITIES },
+                    },
+                },
+                required: ['module'],
+                additionalProperties: false,
+            },
+        },
+    },
+    additionalProperties: false,
+};
+
+const VIOLATION_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        rule: { type: 'string' },
+        severity: { type: 'string', enum: SEVERITIES },
+        message: { type: 'string' },
+    },
+    required: ['rule', 'severity', 'message'],
+};
+
+const URL_ENTRY_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        url: { type: 'string' },
+        line: { type: 'integer', description: 'Line number (1-indexed)' },
+        column: { type: 'integer', description: 'Column number (1-indexed)' },
+        start: { type: 'integer', description: 'Character offset where the URL starts' },
+        end: { type: 'integer', description: 'Character offset where the URL ends' },
+        sourceType: { type: 'string', enum: ['string', 'comment', 'unknown'] },
+        context: stringArray('Context lines around the URL'),
+        category: { type: 'string', description: "Kind of finding when it is not a plain URL (e.g., 'go-import')" },
+        violations: { type: 'array', items: VIOLATION_SCHEMA },
+        attributes: { type: 'object', additionalProperties: { type: 'string' } },
+        fingerprint: { type: 'string', description: 'Stable identifier that survives line renumbering' },
+    },
+    required: ['url', 'start', 'end'],
+};
+
+const ENVIRONMENT_SERVICE_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        service: { type: 'string' },
+        environments: stringArray('Environments referenced for the service'),
+        missing: stringArray('Expected environments that are never referenced'),
+        inconsistencies: stringArray('Differences between environments'),
+        endpoints: {
+            type: 'array',
+            items: {
+                type: 'object',
+                properties: {
+                    environment: { type: 'string' },
+                    url: { type: 'string' },
+                    file: { type: 'string' },
+                    line: { type: 'integer' },
+                },
+                required: ['environment', 'url', 'file', 'line'],
+            },
+        },
+    },
+    required: ['service', 'environments', 'missing', 'inconsistencies', 'endpoints'],
+};
+
+const FINDING_GROUP_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        value: { type: 'string' },
+        files: stringArray('Files containing at least one finding of the group'),
+        urlCount: { type: 'integer' },
+        violationCount: { type: 'integer' },
+    },
+    required: ['value', 'files', 'urlCount', 'violationCount'],
+};
+
+const TEAM_ROLLUP_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        team: { type: 'string' },
+        fileCount: { type: 'integer', description: 'Files of the team with findings' },
+        urlCount: { type: 'integer' },
+        violationCount: { type: 'integer' },
+        severities: {
+            type: 'object',
+            description: 'Violations by severity',
+            properties: { error: { type: 'integer' }, warning: { type: 'integer' }, info: { type: 'integer' } },
+            required: ['error', 'warning', 'info'],
+        },
+        newCount: { type: 'integer', description: 'Findings not in the baseline; present with a baseline' },
+        fixedCount: { type: 'integer', description: 'Baseline findings no longer found; present with a baseline' },
+        worstFiles: {
+            type: 'array',
+            description: 'Files with the most violations, then the most findings',
+            items: {
+                type: 'object',
+                properties: {
+                    file: { type: 'string' },
+                    urlCount: { type: 'integer' },
+                    violationCount: { type: 'integer' },
+                },
+                required: ['file', 'urlCount', 'violationCount'],
+            },
+        },
+    },
+    required: ['team', 'fileCount', 'urlCount', 'violationCount', 'severities', 'worstFiles'],
+};
+
+const DUPLICATE_ENDPOINT_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        url: { type: 'string', description: 'Normalized URL' },
+        count: { type: 'integer' },
+        files: stringArray('Files containing the URL'),
+        candidates: stringArray('Occurrences (file:line) in configuration or constants files'),
+        commonDirectory: { type: 'string', description: 'Deepest directory shared by all files' },
+    },
+    required: ['url', 'count', 'files', 'candidates', 'commonDirectory'],
+};
+
+const PORT_USAGE_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        host: { type: 'string' },
+        port: { type: 'integer' },
+        schemes: stringArray('Schemes the port is used with'),
+        count: { type: 'integer' },
+        files: stringArray('Files containing the host and port'),
+    },
+    required: ['host', 'port', 'schemes', 'count', 'files'],
+};
+
+const LANGUAGE_COVERAGE_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        scannedFiles: { type: 'integer' },
+        parsedFiles: { type: 'integer', description: 'Files parsed with a tree-sitter grammar' },
+        fallbackRegex: { type: 'boolean', description: 'Whether files without a parser were scanned with regex' },
+        unparsedFiles: stringArray('Files without a parser for their language'),
+        extensions: {
+            type: 'array',
+            items: {
+                type: 'object',
+                properties: {
+                    extension: { type: 'string', description: 'Extension, or the file name for files without one' },
+                    language: { type: 'string' },
+                    files: { type: 'integer' },
+                },
+                required: ['extension', 'language', 'files'],
+            },
+        },
+    },
+    required: ['scannedFiles', 'parsedFiles', 'fallbackRegex', 'unparsedFiles', 'extensions'],
+};
+
+const SKIPPED_FILE_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        file: { type: 'string' },
+        reason: { type: 'string', enum: SKIP_REASONS },
+        detail: { type: 'string', description: 'Specifics, such as the file size or the parser error' },
+    },
+    required: ['file', 'reason'],
+};
+
+const QUARANTINE_SUMMARY_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        file: { type: 'string', description: 'Restricted report holding the quarantined findings' },
+        count: { type: 'integer' },
+        files: { type: 'integer', description: 'Number of files with quarantined findings' },
+        reasons: {
+            type: 'object',
+            description: "Quarantined findings per reason ('credentials', 'feed:<name>', or 'rule:<id>')",
+            additionalProperties: { type: 'integer' },
+        },
+    },
+    required: ['file', 'count', 'files', 'reasons'],
+};
+
+const ESTIMATED_TOTAL_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        observed: { type: 'integer', description: 'Count in the sampled files' },
+        estimate: { type: 'integer', description: 'Count the full tree is estimated to have' },
+        low: { type: 'integer', description: 'Lower bound of the confidence interval' },
+        high: { type: 'integer', description: 'Upper bound of the confidence interval' },
+    },
+    required: ['observed', 'estimate', 'low', 'high'],
+};
+
+const SAMPLE_ESTIMATE_SCHEMA: JsonSchema = {
+    type: 'object',
+    description: 'Totals a full scan is estimated to report, when only a sample of files was scanned',
+    properties: {
+        population: { type: 'integer', description: 'Number of files a full scan would read' },
+        sampled: { type: 'integer', description: 'Number of files sampled' },
+        rate: { type: 'string', description: "Sample size as given (e.g., '5%')" },
+        seed: { type: 'string' },
+        confidence: { type: 'number', description: 'Confidence level of the intervals (e.g., 0.95)' },
+        findings: ESTIMATED_TOTAL_SCHEMA,
+        violations: ESTIMATED_TOTAL_SCHEMA,
+        filesWithFindings: ESTIMATED_TOTAL_SCHEMA,
+    },
+    required: ['population', 'sampled', 'rate', 'seed', 'confidence', 'findings', 'violations', 'filesWithFindings'],
+};
+
+const INCREASE_ALERT_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        baseline: { type: 'string', description: 'Report file or trend store snapshot the run is compared to' },
+        baselineCount: { type: 'integer' },
+        currentCount: { type: 'integer' },
+        increase: { type: 'integer' },
+        percent: { type: ['number', 'null'], description: 'Growth as a percentage of the baseline' },
+        threshold: { type: 'string', description: "Allowed increase (e.g., '20%' or '50')" },
+        triggered: { type: 'boolean' },
+    },
+    required: ['baseline', 'baselineCount', 'currentCount', 'increase', 'percent', 'threshold', 'triggered'],
+};
+
+const SCAN_MANIFEST_SCHEMA: JsonSchema = {
+    type: 'object',
+    description: 'What was scanned and with which tool, grammar, config, and policy versions',
+    properties: {
+        toolVersion: { type: 'string' },
+        grammars: {
+            type: 'object',
+            description: 'Version of each loaded tree-sitter grammar, by module name',
+            additionalProperties: { type: 'string' },
+        },
+        treeSitterVersion: { type: 'string', description: 'Version of the tree-sitter core library' },
+        languageGrammars: {
+            type: 'object',
+            description: 'Grammar module and version each parsed language used, by language name',
+            additionalProperties: {
+                type: 'object',
+                properties: { module: { type: 'string' }, version: { type: 'string' } },
+                required: ['module', 'version'],
+            },
+        },
+        configHash: { type: 'string', description: 'SHA-256 of the options that decide what is scanned' },
+        policyHash: { type: 'string', description: 'SHA-256 of the registered rules and policy options' },
+        fileCount: { type: 'integer', description: 'Number of files scanned' },
+        partial: { type: 'boolean', description: 'Whether the scan was interrupted before every file was scanned' },
+        commit: { type: ['string', 'null'], description: 'Git commit of the working directory' },
+        createdAt: { type: 'string', description: 'When the manifest was created (ISO 8601)' },
+        profile: { type: 'string', description: 'Built-in profile the scan ran with' },
+        effectiveConfig: {
+            type: 'object',
+            description: 'Options the scan ran with after the profile, config file, and flags, without credentials',
+        },
+    },
+    required: ['toolVersion', 'grammars', 'configHash', 'policyHash', 'fileCount', 'partial', 'commit', 'createdAt'],
+};
+
+const REACHABILITY_ENTRY_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        url: { type: 'string' },
+        results: {
+            type: 'object',
+            description: 'Probe result by egress name',
+            additionalProperties: {
+                type: 'object',
+                properties: {
+                    reachable: { type: 'boolean' },
+                    status: { type: 'integer', description: 'HTTP status of the response' },
+                    error: { type: 'string', description: 'Why the URL could not be reached' },
+                },
+                required: ['reachable'],
+            },
+        },
+    },
+    required: ['url', 'results'],
+};
+
+/**
+ * Schema of the JSON report format, mirroring JsonOutput.
+ */
+export const REPORT_SCHEMA: JsonSchema = {
+    $schema: DRAFT_07,
+    title: 'url-detector JSON report',
+    type: 'object',
+    properties: {
+        summary: {
+            type: 'object',
+            properties: {
+                totalFiles: { type: 'integer' },
+                totalUrls: { type: 'integer' },
+                uniqueUrls: { type: 'integer' },
+            },
+            required: ['totalFiles', 'totalUrls', 'uniqueUrls'],
+        },
+        files: {
+            type: 'array',
+            items: {
+                type: 'object',
+                properties: {
+                    file: { type: 'string' },
+                    urlCount: { type: 'integer' },
+                    urls: { type: 'array', items: URL_ENTRY_SCHEMA },
+                },
+                required: ['file', 'urls'],
+            },
+        },
+        sections: {
+            type: 'object',
+            description: 'Report-level analyses, present only when requested',
+            properties: {
+                environments: { type: 'array', items: ENVIRONMENT_SERVICE_SCHEMA },
+                groupedBy: { type: 'string', description: "Attribute the findings are grouped by (e.g., 'owner')" },
+                groups: { type: 'array', items: FINDING_GROUP_SCHEMA },
+                teams: { type: 'array', items: TEAM_ROLLUP_SCHEMA },
+                reachability: { type: 'array', items: REACHABILITY_ENTRY_SCHEMA },
+                duplicates: { type: 'array', items: DUPLICATE_ENDPOINT_SCHEMA },
+                ports: { type: 'array', items: PORT_USAGE_SCHEMA },
+                coverage: LANGUAGE_COVERAGE_SCHEMA,
+                skipped: {
+                    type: 'array',
+                    description: 'Files not scanned for URLs and why; present whenever files were skipped',
+                    items: SKIPPED_FILE_SCHEMA,
+                },
+                quarantine: QUARANTINE_SUMMARY_SCHEMA,
+                increaseAlert: INCREASE_ALERT_SCHEMA,
+                sample: SAMPLE_ESTIMATE_SCHEMA,
+            },
+        },
+        manifest: SCAN_MANIFEST_SCHEMA,
+    },
+    required: ['summary', 'files'],
+};
+
+/**
+ * Returns a published schema by name.
+ *
+ * @param name Name of the schema
+ * @returns The schema
+ * @throws {Error} When the name is not a published schema
+ */
+export function getSchema(name: SchemaName): JsonSchema {
+    switch (name) {
+        case 'config':
+            return CONFIG_SCHEMA;
+        case 'report':
+            return REPORT_SCHEMA;
+        default:
+            throw new Error(`Unknown schema: ${name}. Valid schemas: ${SCHEMA_NAMES.join(', ')}`);
+    }
+}
+
+/**
+ * Validates a value against a schema. Only the keywords used by the published schemas are supported.
+ *
+ * @param value The value to validate
+ * @param schema The schema to validate against
+ * @param location JSON path of the value, used in messages (default: '$')
+ * @returns Validation errors, empty when the value is valid
+ */
+export function validateSchema(value: unknown, schema: JsonSchema, location: string = '$'): string[] {
+    if (schema.type) {
+        const types = Array.isArray(schema.type) ? schema.type : [schema.type];
+        if (!types.some(type => matchesType(value, type))) {
+            return [`${location} must be ${types.join(' or ')}`];
+        }
+    }
+    if (schema.enum && !schema.enum.includes(value)) {
+        return [`${location} must be one of ${schema.enum.map(v => JSON.stringify(v)).join(', ')}`];
+    }
+    if (schema.minimum !== undefined && typeof value === 'number' && value < schema.minimum) {
+        return [`${location} must be >= ${schema.minimum}`];
+    }
+
+    const errors: string[] = [];
+    if (Array.isArray(value) && schema.items) {
+        value.forEach((item, index) => errors.push(...validateSchema(item, schema.items!, `${location}[${index}]`)));
+    }
+    if (matchesType(value, 'object')) {
+        const object = value as Record<string, unknown>;
+        for (const key of schema.required || []) {
+            if (!(key in object)) errors.push(`${location}.${key} is required`);
+        }
+        for (const [key, child] of Object.entries(object)) {
+            const propertySchema = schema.properties && schema.properties[key];
+            if (propertySchema) {
+                errors.push(...validateSchema(child, propertySchema, `${location}.${key}`));
+            } else if (schema.additionalProperties === false) {
+                errors.push(`${location}.${key} is not a known property`);
+            } else if (typeof schema.additionalProperties === 'object') {
+                errors.push(...validateSchema(child, schema.additionalProperties, `${location}.${key}`));
+            }
+        }
+    }
+    return errors;
+}
+
+function matchesType(value: unknown, type: JsonSchemaType): boolean {
+    switch (type) {
+        case 'object':
+            return typeof value === 'object' && value !== null && !Array.isArray(value);
+        case 'array':
+            return Array.isArray(value);
+        case 'integer':
+            return Number.isInteger(value);
+        case 'null':
+            return value === null;
+        default:
+            return typeof value === type;
+    }
+}
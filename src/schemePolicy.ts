@@ -0,0 +1,155 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { hostMatcher, regexMatcher } from './categoryRules';
+import { REALTIME_RPC_CATEGORY } from './realtimeEndpoints';
+import { FileContext, Rule, SEVERITIES, Severity, Violation } from './ruleEngine';
+import { URLMatch } from './urlFilter';
+
+/** Id of the rule flagging URLs whose scheme breaks the scheme policy */
+export const SCHEME_POLICY_RULE = 'scheme-policy';
+
+/** Ports used by each scheme when a URL gives none */
+const DEFAULT_PORTS: Record<string, number> = {
+    ftp: 21,
+    ftps: 990,
+    http: 80,
+    https: 443,
+    ldap: 389,
+    ldaps: 636,
+    ws: 80,
+    wss: 443,
+};
+
+/**
+ * Forbids schemes, optionally only on some hosts or ports. At most one of host and hostRegex is
+ * given; an entry with neither applies to every host.
+ *
+ * @example
+ * ```typescript
+ * { schemes: ['ws'], host: '*.prod.example.com', require: 'wss' }
+ * { schemes: ['ftp'] }
+ * { schemes: ['http'], ports: [443], message: 'TLS port with a plain-text scheme' }
+ * ```
+ */
+export interface SchemePolicyEntry {
+    /** Forbidden schemes, without '://' (e.g., 'ws', 'ftp') */
+    schemes: string[];
+    /** Host to match exactly, or '*.' followed by a domain to match any of its subdomains */
+    host?: string;
+    /** Regular expression tested against the host (case-insensitive) */
+    hostRegex?: string;
+    /** Ports the entry applies to, counting the scheme's default port when the URL gives none (default: all) */
+    ports?: number[];
+    /** Scheme to use instead, named in the violation message */
+    require?: string;
+    /** Severity of the violations (default: error) */
+    severity?: Severity;
+    /** Explanation appended to the violation message */
+    message?: string;
+}
+
+interface CompiledEntry {
+    entry: SchemePolicyEntry;
+    schemes: string[];
+    matchesHost: (host: string) => boolean;
+    label: string;
+}
+
+/**
+ * Creates the rule enforcing a scheme policy. Each URL is checked against the entries in order and the
+ * first entry that forbids its scheme on its host and port raises a violation, so `ws://` can be
+ * limited to development hosts and `ftp://` banned everywhere with one list.
+ *
+ * Schemes the detector does not report as part of the URL (e.g., `ws:` before a `//host` match) are
+ * read from the source text in front of the finding.
+ *
+ * @param policy Entries in priority order
+ * @returns The scheme policy rule
+ * @throws {Error} When an entry has no schemes, both host and hostRegex, an invalid regular
+ * expression, or an unknown severity
+ */
+export function createSchemePolicyRule(policy: SchemePolicyEntry[]): Rule {
+    const entries: CompiledEntry[] = policy.map((entry, index) => {
+        const label = `Scheme policy entry ${index + 1}`;
+        if (!entry.schemes || entry.schemes.length === 0) {
+            throw new Error(`${label} must list at least one scheme`);
+        }
+        if (entry.host && entry.hostRegex) {
+            throw new Error(`${label} must have at most one of host and hostRegex`);
+        }
+        if (entry.severity && !SEVERITIES.includes(entry.severity)) {
+            throw new Error(`${label} has an unknown severity: ${entry.severity}`);
+        }
+        const matchesHost = entry.host
+            ? hostMatcher(entry.host)
+            : entry.hostRegex
+              ? regexMatcher(entry.hostRegex, label)
+              : () => true;
+        const schemes = entry.schemes.map(scheme => scheme.toLowerCase().replace(/:(\/\/)?$/, ''));
+        return { entry, schemes, matchesHost, label };
+    });
+
+    return {
+        id: SCHEME_POLICY_RULE,
+        description: 'URL schemes must follow the configured scheme policy',
+        evaluate: (finding: URLMatch, file: FileContext): Violation[] => {
+            if (finding.category && finding.category !== REALTIME_RPC_CATEGORY) return [];
+
+            const scheme = getScheme(finding, file.content);
+            if (!scheme) return [];
+            const parsed = parseUrl(finding.url.startsWith('//') ? `${scheme}:${finding.url}` : finding.url);
+            if (!parsed) return [];
+
+            const host = parsed.hostname.toLowerCase().replace(/\.$/, '');
+            const port = parsed.port ? parseInt(parsed.port, 10) : DEFAULT_PORTS[scheme];
+            const match = entries.find(
+                ({ entry, schemes, matchesHost }) =>
+                    schemes.includes(scheme) &&
+                    matchesHost(host) &&
+                    (!entry.ports || (port !== undefined && entry.ports.includes(port))),
+            );
+            if (!match) return [];
+
+            const { entry } = match;
+            const where = entry.host || entry.hostRegex ? ` on ${host}` : '';
+            const onPort = entry.ports ? ` to port ${port}` : '';
+            let message = `${scheme}:// is not allowed${where}${onPort}`;
+            if (entry.require) message += `; use ${entry.require}:// instead`;
+            if (entry.message) message += ` (${entry.message})`;
+            return [{ rule: SCHEME_POLICY_RULE, severity: entry.severity || 'error', message }];
+        },
+    };
+}
+
+/**
+ * The lowercase scheme of a finding, looking in front of protocol-relative matches for a scheme the
+ * URL pattern does not include.
+ */
+function getScheme(finding: URLMatch, content: string): string | null {
+    const explicit = /^([a-z][a-z0-9+.-]*):/i.exec(finding.url);
+    if (explicit) return explicit[1].toLowerCase();
+    if (!finding.url.startsWith('//')) return null;
+
+    const preceding = /([a-z][a-z0-9+.-]*):$/i.exec(content.substring(Math.max(0, finding.start - 32), finding.start));
+    return preceding ? preceding[1].toLowerCase() : null;
+}
+
+function parseUrl(url: string): URL | null {
+    try {
+        return new URL(url);
+    } catch {
+        return null;
+    }
+}
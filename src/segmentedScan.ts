@@ -0,0 +1,243 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { assignLineFingerprints } from './fingerprint';
+import { URLMatch } from './urlFilter';
+
+/** Default size, in megabytes, above which a file is split into segments */
+export const DEFAULT_CHUNK_SIZE_MB = 64;
+
+/**
+ * Bytes each segment reads beyond both ends of the range it owns, so URLs crossing a boundary are
+ * seen whole by the segment they start in. URLs longer than this are cut at the boundary.
+ */
+export const SEGMENT_OVERLAP = 16 * 1024;
+
+/**
+ * A byte range of a file scanned on its own. A segment reports only the matches that start in the
+ * range it owns, and reads the overlap on both sides to see them whole.
+ */
+export interface FileSegment {
+    /** First byte owned by the segment */
+    start: number;
+    /** Byte after the last one owned */
+    end: number;
+    /** First byte read, up to the overlap before start */
+    readStart: number;
+    /** Byte after the last one read, up to the overlap after end */
+    readEnd: number;
+}
+
+/**
+ * A match found in a segment, positioned relative to the start of the segment.
+ */
+export interface SegmentMatch {
+    url: string;
+    /** UTF-16 code units between the start of the segment and the match */
+    offset: number;
+    /** Line breaks between the start of the segment and the match */
+    lines: number;
+    /** Column of the match when a line break precedes it within the segment, otherwise null */
+    column: number | null;
+    /** Fingerprint context of the match (see fingerprintContext()), as far as the segment read it */
+    context: string;
+}
+
+/**
+ * What a segment contributes to the positions of the segments after it, and its matches.
+ */
+export interface SegmentScan {
+    matches: SegmentMatch[];
+    /** UTF-16 code units in the owned range */
+    length: number;
+    /** Line breaks in the owned range */
+    lines: number;
+    /** UTF-16 code units after the last line break in the owned range, or null when it has none */
+    tail: number | null;
+}
+
+/**
+ * Splits a file into segments.
+ *
+ * @param fileSize Size of the file in bytes
+ * @param segmentSize Bytes owned by each segment
+ * @param overlap Bytes read beyond both ends of each segment (default: SEGMENT_OVERLAP)
+ * @returns The segments in file order
+ */
+export function planSegments(fileSize: number, segmentSize: number, overlap: number = SEGMENT_OVERLAP): FileSegment[] {
+    const segments: FileSegment[] = [];
+    for (let start = 0; start < fileSize; start += segmentSize) {
+        const end = Math.min(start + segmentSize, fileSize);
+        segments.push({
+            start,
+            end,
+            readStart: Math.max(0, start - overlap),
+            readEnd: Math.min(fileSize, end + overlap),
+        });
+    }
+    return segments;
+}
+
+/**
+ * Reads the bytes of a segment, overlap included.
+ *
+ * @param handle Open handle of the file
+ * @param segment The segment to read
+ * @returns The bytes from readStart to readEnd
+ */
+export async function readSegment(handle: fs.promises.FileHandle, segment: FileSegment): Promise<Buffer> {
+    const buffer = Buffer.alloc(segment.readEnd - segment.readStart);
+    const { bytesRead } = await handle.read(buffer, 0, buffer.length, segment.readStart);
+    return buffer.subarray(0, bytesRead);
+}
+
+/**
+ * Finds the matches that start in the owned range of a segment and measures the range, so that
+ * stitchSegments() can place them in the file without the segments before it being decoded.
+ *
+ * @param buffer Bytes of the segment from readStart to readEnd (UTF-8)
+ * @param segment The segment
+ * @param pattern Global regular expression for URLs
+ * @param accept Whether to keep a matched URL (default: keep all)
+ * @returns The matches in order and the measurements of the owned range
+ */
+export function scanSegment(
+    buffer: Buffer,
+    segment: FileSegment,
+    pattern: RegExp,
+    accept: (url: string) => boolean = () => true,
+): SegmentScan {
+    const ownStart = segment.start - segment.readStart;
+    const ownEnd = Math.min(segment.end - segment.readStart, buffer.length);
+
+    // The overlap may begin inside a multi-byte character; decode from the next character boundary
+    let first = 0;
+    while (first < ownStart && isContinuationByte(buffer[first])) first++;
+    const text = buffer.toString('utf8', first);
+
+    // Measurements of the owned range up to `measured`, advanced match by match
+    let measured = ownStart;
+    let length = 0;
+    let lines = 0;
+    let tail = null as number | null;
+    const measureTo = (byte: number): void => {
+        for (; measured < byte; measured++) {
+            const units = utf16Units(buffer[measured]);
+            length += units;
+            if (buffer[measured] === 0x0a) {
+                lines++;
+                tail = 0;
+            } else if (tail !== null) {
+                tail += units;
+            }
+        }
+    };
+
+    const matches: SegmentMatch[] = [];
+    const regex = new RegExp(pattern.source, pattern.flags.includes('g') ? pattern.flags : `${pattern.flags}g`);
+    let decoded = 0;
+    let byte = first;
+    let match: RegExpExecArray | null;
+    while ((match = regex.exec(text)) !== null) {
+        byte += Buffer.byteLength(text.substring(decoded, match.index));
+        decoded = match.index;
+        if (byte >= ownEnd) break;
+        if (byte < ownStart || !accept(match[0])) continue;
+
+        measureTo(byte);
+        matches.push({
+            url: match[0],
+            offset: length,
+            lines,
+            column: tail === null ? null : tail + 1,
+            context: contextAt(text, match.index),
+        });
+    }
+    measureTo(ownEnd);
+
+    return { matches, length, lines, tail };
+}
+
+/**
+ * Places the matches of a file's segments in the file and fingerprints them. Positions carry over
+ * from segment to segment, so the result is the same as scanning the whole file at once, except for
+ * URLs longer than the overlap, and fingerprints whose context lines reach beyond the overlap.
+ *
+ * @param scans Scans of every segment of the file, in file order
+ * @param filePath Path of the file
+ * @returns The findings in source order
+ */
+export function stitchSegments(scans: SegmentScan[], filePath: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+    const contexts: string[] = [];
+    let offset = 0;
+    let lines = 0;
+    let column = 0;
+
+    for (const scan of scans) {
+        for (const match of scan.matches) {
+            const start = offset + match.offset;
+            findings.push({
+                url: match.url,
+                start,
+                end: start + match.url.length,
+                line: lines + match.lines + 1,
+                column: match.column !== null ? match.column : column + match.offset + 1,
+                sourceType: 'unknown',
+            });
+            contexts.push(match.context);
+        }
+        offset += scan.length;
+        lines += scan.lines;
+        column = scan.tail !== null ? scan.tail : column + scan.length;
+    }
+
+    return assignLineFingerprints(findings, filePath, contexts);
+}
+
+/**
+ * Collects the fingerprint context of the text at an offset: its line and the nearest non-blank line
+ * above and below, as fingerprintContext() does for a whole file.
+ */
+function contextAt(text: string, offset: number): string {
+    const lineStart = text.lastIndexOf('\n', offset - 1) + 1;
+    const lineEnd = text.indexOf('\n', offset);
+    const line = text.substring(lineStart, lineEnd === -1 ? text.length : lineEnd);
+
+    let above = '';
+    for (let end = lineStart - 1; end >= 0 && above.trim() === ''; ) {
+        const start = end === 0 ? 0 : text.lastIndexOf('\n', end - 1) + 1;
+        above = text.substring(start, end);
+        end = start - 1;
+    }
+    let below = '';
+    for (let start = lineEnd; start !== -1 && below.trim() === ''; ) {
+        const end = text.indexOf('\n', start + 1);
+        below = text.substring(start + 1, end === -1 ? text.length : end);
+        start = end;
+    }
+
+    return [above, line, below].join('\n');
+}
+
+function isContinuationByte(byte: number): boolean {
+    return (byte & 0xc0) === 0x80;
+}
+
+/** UTF-16 code units contributed by a UTF-8 byte: one per character, two for four-byte characters */
+function utf16Units(byte: number): number {
+    if (isContinuationByte(byte)) return 0;
+    return byte >= 0xf0 ? 2 : 1;
+}
@@ -0,0 +1,402 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import * as http from 'http';
+import { AddressInfo } from 'net';
+import { Logger, NullLogger } from './logger';
+import { DetectorOptionsConfig } from './options';
+import { createReport, toJsonOutput } from './report';
+import { DEFAULT_SCAN_RETENTION, FindingPage, ScanStore, parseFindingQuery, queryFindings } from './scanStore';
+import { CONFIG_SCHEMA, JsonSchema, validateSchema } from './schema';
+import { applySeverityEscalation } from './severityEscalation';
+import { FileResult, URLDetector } from './urlDetector';
+
+/** Profile used by requests that do not select one */
+export const DEFAULT_PROFILE = 'default';
+
+/** Request header selecting the policy profile */
+export const PROFILE_HEADER = 'x-url-detector-profile';
+
+/** Response header carrying the id of a stored scan */
+export const SCAN_ID_HEADER = 'x-url-detector-scan-id';
+
+/** Default limit on request body size (10 MiB) */
+export const DEFAULT_MAX_BODY_BYTES = 10 * 1024 * 1024;
+
+/** Default number of file results cached per profile */
+export const DEFAULT_CACHE_SIZE = 1000;
+
+/**
+ * Body of a scan request.
+ */
+export interface ScanRequest {
+    /** Files to scan; the path selects the language and appears in the report */
+    files: Array<{ path: string; content: string }>;
+}
+
+/**
+ * Configuration of the scan server.
+ */
+export interface ServerOptions {
+    /** Policy profiles by name; requests that do not select a profile use 'default' */
+    profiles: Record<string, DetectorOptionsConfig>;
+    /** Largest accepted request body in bytes (default: 10 MiB) */
+    maxBodyBytes?: number;
+    /** Number of file results cached per profile; 0 disables caching (default: 1000) */
+    cacheSize?: number;
+    /** Directory completed scans are stored in for the query endpoints; scans are not stored when omitted */
+    scanStore?: string;
+    /** Number of stored scans kept (default: 100) */
+    scanRetention?: number;
+}
+
+/**
+ * A profile's detector and its cache of file results keyed by content hash.
+ */
+interface Tenant {
+    detector: URLDetector;
+    /** Hash of the profile configuration, so ETags change when the policy does */
+    configHash: string;
+    cache: Map<string, FileResult | null>;
+}
+
+const SCAN_REQUEST_SCHEMA: JsonSchema = {
+    type: 'object',
+    properties: {
+        files: {
+            type: 'array',
+            items: {
+                type: 'object',
+                properties: { path: { type: 'string' }, content: { type: 'string' } },
+                required: ['path', 'content'],
+            },
+        },
+    },
+    required: ['files'],
+};
+
+/**
+ * Error with the HTTP status it should be reported with.
+ */
+class HttpError extends Error {
+    public readonly status: number;
+
+    constructor(status: number, message: string) {
+        super(message);
+        this.status = status;
+    }
+}
+
+/**
+ * Hashes a submitted file. The path is included because it selects the language and
+ * path-based policies.
+ *
+ * @param filePath Path of the file
+ * @param content Content of the file
+ * @returns Hex-encoded SHA-256 hash
+ */
+export function contentHash(filePath: string, content: string): string {
+    return crypto.createHash('sha256').update(filePath).update('\0').update(content).digest('hex');
+}
+
+/**
+ * Loads server profiles from a JSON file mapping profile names to configurations. Every
+ * configuration is validated against the config file schema.
+ *
+ * @param filePath Path to the profiles file
+ * @returns Profiles by name
+ * @throws {Error} When the file cannot be read or a profile does not match the schema
+ */
+export async function loadServerProfiles(filePath: string): Promise<Record<string, DetectorOptionsConfig>> {
+    const profiles = JSON.parse(await fs.promises.readFile(filePath, 'utf8'));
+    const errors = validateSchema(profiles, { type: 'object', additionalProperties: CONFIG_SCHEMA });
+    if (errors.length > 0) {
+        throw new Error(`Invalid profiles file ${filePath}: ${errors.join('; ')}`);
+    }
+    return profiles;
+}
+
+/**
+ * HTTP server that scans submitted file contents. One deployed instance can serve several teams:
+ * each request selects a named policy profile, either in the path (`POST /profiles/{name}/scan`)
+ * or in the `X-Url-Detector-Profile` header (`POST /scan`), and every profile gets its own detector
+ * so allowlists, rules, and caches are never shared between tenants.
+ *
+ * File results are cached per profile by content hash, so unchanged files are not rescanned. Scan
+ * responses carry an ETag; a request whose `If-None-Match` header matches it gets `304 Not Modified`.
+ *
+ * With a scan store, completed scans are kept and their id is returned in the `X-Url-Detector-Scan-Id`
+ * header, so clients can page through the findings of a large scan. Stored scans are only visible
+ * through the profile they ran with.
+ *
+ * Endpoints:
+ * - `POST /scan`, `POST /profiles/{name}/scan`: scan `{ files: [{ path, content }] }`, returning a JSON report
+ * - `GET /scans`, `GET /scans/{id}`: list stored scans, or get one's summary
+ * - `GET /scans/{id}/findings`: page through a scan's findings, filtered by host, severity, rule, file,
+ *   category, or filter expression (each also under `/profiles/{name}`)
+ * - `GET /profiles`: list profile names
+ * - `GET /health`: liveness probe
+ */
+export class ScanServer {
+    private options: ServerOptions;
+    private logger: Logger;
+    private server: http.Server;
+    private tenants = new Map<string, Tenant>();
+    private store: ScanStore | null;
+
+    /**
+     * @param options Profiles and limits
+     * @param logger Logger for request and error messages (default: NullLogger)
+     */
+    constructor(options: ServerOptions, logger: Logger = NullLogger) {
+        this.options = options;
+        this.logger = logger;
+        this.store = options.scanStore
+            ? new ScanStore(options.scanStore, options.scanRetention ?? DEFAULT_SCAN_RETENTION)
+            : null;
+        this.server = http.createServer((req, res) => {
+            this.handle(req, res).catch(error => {
+                const status = error instanceof HttpError ? error.status : 500;
+                if (status === 500) this.logger.error(`Failed to handle ${req.method} ${req.url}: ${error.message}`);
+                this.send(res, status, { error: error.message });
+            });
+        });
+    }
+
+    /**
+     * Starts listening for requests.
+     *
+     * @param port Port to listen on; 0 picks a free port (default: 0)
+     * @param host Interface to bind (default: 127.0.0.1)
+     * @returns The bound address
+     */
+    public listen(port: number = 0, host: string = '127.0.0.1'): Promise<AddressInfo> {
+        return new Promise((resolve, reject) => {
+            this.server.once('error', reject);
+            this.server.listen(port, host, () => resolve(this.server.address() as AddressInfo));
+        });
+    }
+
+    /**
+     * Stops accepting requests and waits for open connections to finish.
+     */
+    public close(): Promise<void> {
+        return new Promise((resolve, reject) => this.server.close(error => (error ? reject(error) : resolve())));
+    }
+
+    /**
+     * Returns the detector of a profile, creating it on first use.
+     *
+     * @param profile Name of the profile
+     * @returns The profile's detector
+     * @throws {Error} When the profile does not exist
+     */
+    public getDetector(profile: string): URLDetector {
+        return this.getTenant(profile).detector;
+    }
+
+    private getTenant(profile: string): Tenant {
+        let tenant = this.tenants.get(profile);
+        if (!tenant) {
+            const config = Object.prototype.hasOwnProperty.call(this.options.profiles, profile)
+                ? this.options.profiles[profile]
+                : undefined;
+            if (!config) {
+                throw new HttpError(404, `Unknown profile: ${profile}`);
+            }
+            tenant = {
+                detector: new URLDetector(config, this.logger),
+                configHash: crypto.createHash('sha256').update(JSON.stringify(config)).digest('hex'),
+                cache: new Map(),
+            };
+            this.tenants.set(profile, tenant);
+        }
+        return tenant;
+    }
+
+    private async handle(req: http.IncomingMessage, res: http.ServerResponse): Promise<void> {
+        const requestUrl = new URL(req.url || '/', 'http://localhost');
+        const pathname = requestUrl.pathname;
+        this.logger.debug(`${req.method} ${pathname}`);
+
+        if (req.method === 'GET' && pathname === '/health') {
+            this.send(res, 200, { status: 'ok' });
+            return;
+        }
+        if (req.method === 'GET' && pathname === '/profiles') {
+            this.send(res, 200, { profiles: Object.keys(this.options.profiles) });
+            return;
+        }
+
+        const scansRoute = pathname.match(/^(?:\/profiles\/([^/]+))?\/scans(?:\/([^/]+)(\/findings)?)?$/);
+        if (scansRoute) {
+            if (req.method !== 'GET') {
+                throw new HttpError(405, `Method not allowed: ${req.method}`);
+            }
+            await this.handleScans(res, this.resolveProfile(req, scansRoute[1]), scansRoute, requestUrl.searchParams);
+            return;
+        }
+
+        const scanRoute = pathname.match(/^(?:\/profiles\/([^/]+))?\/scan$/);
+        if (!scanRoute) {
+            throw new HttpError(404, `Not found: ${pathname}`);
+        }
+        if (req.method !== 'POST') {
+            throw new HttpError(405, `Method not allowed: ${req.method}`);
+        }
+
+        const profile = this.resolveProfile(req, scanRoute[1]);
+        const tenant = this.getTenant(profile);
+        const request = await this.readScanRequest(req);
+
+        const hashes = request.files.map(file => contentHash(file.path, file.content));
+        const requestHash = crypto.createHash('sha256').update(tenant.configHash).update(hashes.join('\n'));
+        const etag = `"${requestHash.digest('hex')}"`;
+        if (matchesEtag(req.headers['if-none-match'], etag)) {
+            res.writeHead(304, { ETag: etag });
+            res.end();
+            return;
+        }
+
+        const results = await this.scan(tenant, request, hashes);
+        const headers: http.OutgoingHttpHeaders = { ETag: etag };
+        if (this.store) {
+            headers[SCAN_ID_HEADER] = (await this.store.save(profile, results)).id;
+        }
+        this.send(res, 200, toJsonOutput(createReport(results)), headers);
+    }
+
+    /**
+     * Selects the profile of a request: from the path, else from the profile header, else the default.
+     */
+    private resolveProfile(req: http.IncomingMessage, pathProfile: string | undefined): string {
+        const header = req.headers[PROFILE_HEADER];
+        return pathProfile ? decodeURIComponent(pathProfile) : (header as string) || DEFAULT_PROFILE;
+    }
+
+    private async handleScans(
+        res: http.ServerResponse,
+        profile: string,
+        route: RegExpMatchArray,
+        params: URLSearchParams,
+    ): Promise<void> {
+        this.getTenant(profile);
+        if (!this.store) {
+            throw new HttpError(404, 'Scans are not stored; start the server with a scan store');
+        }
+        if (!route[2]) {
+            this.send(res, 200, { scans: await this.store.list(profile) });
+            return;
+        }
+
+        const id = decodeURIComponent(route[2]);
+        const scan = await this.store.load(id);
+        // Scans of other profiles are reported as missing, so tenants cannot probe each other's ids
+        if (!scan || scan.profile !== profile) {
+            throw new HttpError(404, `Unknown scan: ${id}`);
+        }
+        const { results, ...summary } = scan;
+        if (!route[3]) {
+            this.send(res, 200, summary);
+            return;
+        }
+
+        let page: FindingPage;
+        try {
+            page = queryFindings(results, parseFindingQuery(params));
+        } catch (error: any) {
+            throw new HttpError(400, error.message);
+        }
+        this.send(res, 200, { scan: summary, ...page });
+    }
+
+    private async scan(tenant: Tenant, request: ScanRequest, hashes: string[]): Promise<FileResult[]> {
+        const cacheSize = this.options.cacheSize ?? DEFAULT_CACHE_SIZE;
+        const results: FileResult[] = [];
+
+        for (let i = 0; i < request.files.length; i++) {
+            const file = request.files[i];
+            let result: FileResult | null | undefined = tenant.cache.get(hashes[i]);
+            if (result !== undefined) {
+                // Refresh the entry so the least recently used one is evicted first
+                tenant.cache.delete(hashes[i]);
+            } else {
+                result = await tenant.detector.scanContent(file.content, file.path);
+            }
+
+            if (cacheSize > 0) {
+                tenant.cache.set(hashes[i], result);
+                if (tenant.cache.size > cacheSize) tenant.cache.delete(tenant.cache.keys().next().value!);
+            }
+            // Escalation below updates violations in place, so never hand out the cached object
+            if (result) results.push(structuredClone(result));
+        }
+
+        applySeverityEscalation(results, tenant.detector.getOptions.severityEscalation);
+        return results;
+    }
+
+    private async readScanRequest(req: http.IncomingMessage): Promise<ScanRequest> {
+        const maxBodyBytes = this.options.maxBodyBytes ?? DEFAULT_MAX_BODY_BYTES;
+        const chunks: Buffer[] = [];
+        let size = 0;
+        for await (const chunk of req) {
+            size += chunk.length;
+            if (size > maxBodyBytes) {
+                throw new HttpError(413, `Request body exceeds ${maxBodyBytes} bytes`);
+            }
+            chunks.push(chunk);
+        }
+
+        let body: unknown;
+        try {
+            body = JSON.parse(Buffer.concat(chunks).toString('utf8'));
+        } catch (error: any) {
+            throw new HttpError(400, `Invalid JSON: ${error.message}`);
+        }
+
+        const errors = validateSchema(body, SCAN_REQUEST_SCHEMA);
+        if (errors.length > 0) {
+            throw new HttpError(400, `Invalid scan request: ${errors.join('; ')}`);
+        }
+        return body as ScanRequest;
+    }
+
+    private send(
+        res: http.ServerResponse,
+        status: number,
+        body: unknown,
+        headers: http.OutgoingHttpHeaders = {},
+    ): void {
+        if (res.headersSent) {
+            res.end();
+            return;
+        }
+        res.writeHead(status, { 'Content-Type': 'application/json', ...headers });
+        res.end(JSON.stringify(body));
+    }
+}
+
+/**
+ * Checks whether an If-None-Match header lists an ETag. Weak validators match their strong counterparts.
+ */
+function matchesEtag(header: string | undefined, etag: string): boolean {
+    if (!header) return false;
+    return header.split(',').some(tag => {
+        const value = tag.trim();
+        return value === '*' || value.replace(/^W\//, '') === etag;
+    });
+}
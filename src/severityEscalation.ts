@@ -0,0 +1,99 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { minimatch } from 'minimatch';
+import { SEVERITIES, Severity } from './ruleEngine';
+import { normalizeFingerprintPath } from './fingerprint';
+import { FileResult, setFindingAttribute } from './urlFilter';
+
+/** Attribute recording how many levels a finding's violations were raised or lowered (e.g., '+1') */
+export const ESCALATION_ATTRIBUTE = 'severityEscalation';
+
+/**
+ * Raises (or lowers) the severity of violations in files under matching paths, so the same rule is
+ * treated more strictly in security-sensitive modules.
+ *
+ * @example
+ * ```typescript
+ * { paths: ['auth/', 'payments/**'], levels: 1 }
+ * ```
+ */
+export interface SeverityEscalation {
+    /** Glob patterns relative to the working directory; a trailing '/' covers everything below the directory */
+    paths: string[];
+    /** Number of severity levels to raise violations by; negative values lower them (default: 1) */
+    levels?: number;
+}
+
+/**
+ * Shifts a severity by a number of levels, staying within 'info' and 'error'.
+ *
+ * @param severity The original severity
+ * @param levels Levels to shift by
+ * @returns The shifted severity
+ */
+export function escalateSeverity(severity: Severity, levels: number): Severity {
+    const index = SEVERITIES.indexOf(severity) + levels;
+    return SEVERITIES[Math.max(0, Math.min(SEVERITIES.length - 1, index))];
+}
+
+/**
+ * Returns the escalation that applies to a file. When several entries match, the strictest (largest) shift wins.
+ *
+ * @param filePath Path of the file
+ * @param escalations Configured escalations
+ * @returns Levels to shift violations by, 0 when no entry matches
+ */
+export function getEscalationLevels(filePath: string, escalations: SeverityEscalation[]): number {
+    const relativePath = normalizeFingerprintPath(filePath);
+    let levels: number | undefined;
+
+    for (const escalation of escalations) {
+        const matches = escalation.paths.some(pattern =>
+            minimatch(relativePath, pattern.endsWith('/') ? `${pattern}**` : pattern, { dot: true }),
+        );
+        const shift = escalation.levels ?? 1;
+        if (matches && (levels === undefined || shift > levels)) {
+            levels = shift;
+        }
+    }
+
+    return levels || 0;
+}
+
+/**
+ * Applies path-based severity escalation to every violation in the results, and records the shift
+ * in the 'severityEscalation' attribute of the affected findings.
+ *
+ * @param results Scan results with violations attached
+ * @param escalations Configured escalations
+ */
+export function applySeverityEscalation(results: FileResult[], escalations: SeverityEscalation[]): void {
+    if (escalations.length === 0) return;
+
+    for (const result of results) {
+        const levels = getEscalationLevels(result.file, escalations);
+        if (levels === 0) continue;
+
+        for (const urlObj of result.urls) {
+            if (!urlObj.violations || urlObj.violations.length === 0) continue;
+
+            urlObj.violations = urlObj.violations.map(violation => ({
+                ...violation,
+                severity: escalateSeverity(violation.severity, levels),
+            }));
+            setFindingAttribute(urlObj, ESCALATION_ATTRIBUTE, levels > 0 ? `+${levels}` : `${levels}`);
+        }
+    }
+}
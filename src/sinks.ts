@@ -0,0 +1,133 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { ScanManifest } from './manifest';
+import { OutputSummary, Report, ReportSections } from './report';
+import { URLMatch } from './urlFilter';
+
+/** Formats implemented by the output formatter itself, which sinks cannot replace */
+export const BUILT_IN_FORMATS = ['table', 'json', 'csv', 'ndjson', 'sarif', 'patchset', 'html'];
+
+/**
+ * A finding together with the file it was detected in.
+ */
+export interface Finding extends URLMatch {
+    file: string;
+}
+
+/**
+ * Text a sink adds to the output; nothing is added for undefined.
+ */
+export type SinkOutput = string | void | Promise<string | void>;
+
+/**
+ * A custom output format. The output formatter calls begin() once, write() for every finding in
+ * file order, and end() once, concatenates the returned text, and writes it to the output file or
+ * stdout like any built-in format.
+ *
+ * @example
+ * ```typescript
+ * registerSink('audit-xml', () => ({
+ *     begin: () => '<audit>\n',
+ *     write: finding => `  <url line="${finding.line}">${escapeXml(finding.url)}</url>\n`,
+ *     end: summary => `  <summary urls="${summary.totalUrls}"/>\n</audit>\n`,
+ * }));
+ * ```
+ */
+export interface Sink {
+    /** Starts the output (e.g., a header or XML prolog) */
+    begin(): SinkOutput;
+    /** Adds one finding */
+    write(finding: Finding): SinkOutput;
+    /** Finishes the output with the scan summary, report sections, and manifest */
+    end(summary: OutputSummary, sections?: ReportSections, manifest?: ScanManifest): SinkOutput;
+}
+
+/**
+ * Creates a fresh sink for each output, so sinks can keep state between calls.
+ */
+export type SinkFactory = () => Sink;
+
+const sinks = new Map<string, SinkFactory>();
+
+/**
+ * Registers a custom output format, usable as `format` in the options and `--format` on the command line.
+ * A sink registered under the same name replaces the existing one.
+ *
+ * @param format Name of the format (e.g., 'audit-xml')
+ * @param factory Creates the sink for each output
+ * @throws {Error} When the name is empty or a built-in format
+ */
+export function registerSink(format: string, factory: SinkFactory): void {
+    if (!format) {
+        throw new Error('Sink must have a non-empty format name');
+    }
+    if (BUILT_IN_FORMATS.includes(format)) {
+        throw new Error(`Cannot register a sink for the built-in format ${format}`);
+    }
+    sinks.set(format, factory);
+}
+
+/**
+ * Removes a registered output format.
+ *
+ * @param format Name of the format
+ * @returns true if the format was registered, false otherwise
+ */
+export function unregisterSink(format: string): boolean {
+    return sinks.delete(format);
+}
+
+/**
+ * Gets the names of the registered output formats in registration order.
+ *
+ * @returns Format names
+ */
+export function getSinkFormats(): string[] {
+    return Array.from(sinks.keys());
+}
+
+/**
+ * Creates the sink for a registered output format.
+ *
+ * @param format Name of the format
+ * @returns A new sink, or null when no sink is registered for the format
+ */
+export function createSink(format: string): Sink | null {
+    const factory = sinks.get(format);
+    return factory ? factory() : null;
+}
+
+/**
+ * Feeds a report through a sink.
+ *
+ * @param sink The sink
+ * @param report The report to output
+ * @returns Promise resolving to the concatenated output
+ */
+export async function runSink(sink: Sink, report: Report): Promise<string> {
+    const chunks: string[] = [];
+    const append = (chunk: string | void) => {
+        if (typeof chunk === 'string') chunks.push(chunk);
+    };
+
+    append(await sink.begin());
+    for (const result of report.files) {
+        for (const urlObj of result.urls) {
+            append(await sink.write({ ...urlObj, file: result.file }));
+        }
+    }
+    append(await sink.end(report.summary, report.sections, report.manifest));
+    return chunks.join('');
+}
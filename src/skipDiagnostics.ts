@@ -0,0 +1,102 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+/**
+ * Why a file matching the scan patterns was not scanned for URLs:
+ * - too-large: larger than maxFileSize
+ * - binary: contains NUL bytes near the start
+ * - ignored-by-pattern: matched by an exclude pattern; reported once per excluded directory and once per
+ *   pattern excluding files, rather than per file
+ * - unsupported-language: no parser for its language and fallbackRegex is off
+ * - parse-timeout: parsing took longer than parseTimeout and fallbackRegex is off
+ * - parse-error: the parser failed and fallbackRegex is off
+ * - generated: generated or minified, with skipGenerated set
+ * - unreadable: the file could not be read
+ * - interrupted: the scan was interrupted before the file was reached
+ */
+export type SkipReason =
+    | 'too-large'
+    | 'binary'
+    | 'ignored-by-pattern'
+    | 'unsupported-language'
+    | 'parse-timeout'
+    | 'parse-error'
+    | 'generated'
+    | 'unreadable'
+    | 'interrupted';
+
+/** All skip reasons, in the order summaries list them */
+export const SKIP_REASONS: SkipReason[] = [
+    'too-large',
+    'binary',
+    'ignored-by-pattern',
+    'unsupported-language',
+    'parse-timeout',
+    'parse-error',
+    'generated',
+    'unreadable',
+    'interrupted',
+];
+
+/** Bytes inspected for NUL bytes to recognize binary files, as git does */
+export const BINARY_SNIFF_LENGTH = 8000;
+
+/**
+ * A file that was not scanned for URLs, and why.
+ */
+export interface SkippedFile {
+    /**
+     * Path relative to the working directory. For ignored-by-pattern, an excluded directory with a
+     * trailing slash, or the exclude pattern for files it excluded
+     */
+    file: string;
+    reason: SkipReason;
+    /** Specifics, such as the file size or the parser error */
+    detail?: string;
+}
+
+/**
+ * Whether content is binary: like git, any NUL byte in the first 8000 bytes.
+ *
+ * @param buffer Content of the file, or its beginning
+ * @returns True for binary content
+ */
+export function isBinaryContent(buffer: Buffer): boolean {
+    return buffer.subarray(0, BINARY_SNIFF_LENGTH).includes(0);
+}
+
+/**
+ * Counts skipped files by reason.
+ *
+ * @param skipped The skipped files
+ * @returns Counts for the reasons that occur, in SKIP_REASONS order
+ */
+export function countSkipReasons(skipped: SkippedFile[]): Array<{ reason: SkipReason; files: number }> {
+    return SKIP_REASONS.map(reason => ({
+        reason,
+        files: skipped.filter(entry => entry.reason === reason).length,
+    })).filter(entry => entry.files > 0);
+}
+
+/**
+ * Formats the one-line summary of skipped files printed at the end of a scan.
+ *
+ * @param skipped The skipped files
+ * @returns The summary (e.g., 'Skipped 3 file(s): 2 binary, 1 too-large'), or null when none were skipped
+ */
+export function formatSkipSummary(skipped: SkippedFile[]): string | null {
+    if (skipped.length === 0) return null;
+    const counts = countSkipReasons(skipped).map(({ reason, files }) => `${files} ${reason}`);
+    return `Skipped ${skipped.length} file(s): ${counts.join(', ')}`;
+}
@@ -0,0 +1,106 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { FileContext, Rule, Violation } from './ruleEngine';
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Attribute holding the CDN detected for a finding that lacks Subresource Integrity */
+export const CDN_ATTRIBUTE = 'cdn';
+
+/** Well-known public CDNs, matched against the URL host */
+const KNOWN_CDNS: Array<[RegExp, string]> = [
+    [/^cdn\.jsdelivr\.net$/, 'jsDelivr'],
+    [/^unpkg\.com$/, 'unpkg'],
+    [/^cdnjs\.cloudflare\.com$/, 'cdnjs'],
+    [/^ajax\.googleapis\.com$/, 'Google Hosted Libraries'],
+    [/^code\.jquery\.com$/, 'jQuery CDN'],
+    [/^(stackpath|maxcdn|netdna)\.bootstrapcdn\.com$/, 'BootstrapCDN'],
+    [/^ajax\.aspnetcdn\.com$/, 'Microsoft Ajax CDN'],
+    [/^(cdn\.skypack\.dev|esm\.sh|ga\.jspm\.io)$/, 'ES module CDN'],
+    [/^cdn\.staticfile\.(org|net)$/, 'Staticfile CDN'],
+    [/^use\.fontawesome\.com$/, 'Font Awesome CDN'],
+];
+
+/** Hosts that look like a CDN without being a known one (e.g., cdn.example.com, static-cdn1.example.net) */
+const GENERIC_CDN_HOST = /(^|[.-])cdn\d*[.-]/;
+
+/** link rel values for which browsers enforce integrity */
+const SRI_LINK_RELS = /\brel\s*=\s*["']?[^"'>]*\b(stylesheet|preload|modulepreload)\b/i;
+
+/**
+ * Determines the CDN a URL is served from.
+ *
+ * @param url An absolute or protocol-relative URL
+ * @returns The CDN name, 'generic CDN' for CDN-like hosts, or null if the URL is not served by a CDN
+ */
+export function detectCdn(url: string): string | null {
+    const hostMatch = url.match(/^(?:[a-zA-Z][a-zA-Z0-9+.-]*:)?\/\/([^/?#:]+)/);
+    if (!hostMatch) return null;
+
+    const host = hostMatch[1].toLowerCase();
+    const known = KNOWN_CDNS.find(([pattern]) => pattern.test(host));
+    if (known) return known[1];
+    return GENERIC_CDN_HOST.test(host) ? 'generic CDN' : null;
+}
+
+/**
+ * Creates the rule that suggests Subresource Integrity for `<script src>` and `<link href>` tags in HTML
+ * that load from a third-party CDN without an `integrity` attribute. The advisory is raised at 'info'
+ * severity, and the detected CDN is recorded in the finding's 'cdn' attribute.
+ *
+ * @returns The SRI advisory rule
+ */
+export function createSriAdvisoryRule(): Rule {
+    return {
+        id: 'sri-missing',
+        description: 'CDN-hosted scripts and stylesheets should carry a Subresource Integrity hash',
+        evaluate: (finding: URLMatch, file: FileContext): Violation[] => {
+            if (file.language !== 'html') return [];
+
+            const tag = findEnclosingTag(file.content, finding.start);
+            if (!tag || !isIntegrityCandidate(tag.name, tag.text, finding.start - tag.start)) return [];
+            if (/\bintegrity\s*=/i.test(tag.text)) return [];
+
+            const cdn = detectCdn(finding.url);
+            if (!cdn) return [];
+
+            setFindingAttribute(finding, CDN_ATTRIBUTE, cdn);
+            const message =
+                `<${tag.name}> loads ${finding.url} from ${cdn} without an integrity attribute; ` +
+                'add a Subresource Integrity hash';
+            return [{ rule: 'sri-missing', severity: 'info', message }];
+        },
+    };
+}
+
+function findEnclosingTag(content: string, position: number): { name: string; text: string; start: number } | null {
+    const start = content.lastIndexOf('<', position);
+    const end = content.indexOf('>', position);
+    if (start === -1 || end === -1 || content.lastIndexOf('>', position) > start) return null;
+
+    const text = content.substring(start, end + 1);
+    const nameMatch = text.match(/^<\s*([a-zA-Z][\w-]*)/);
+    return nameMatch ? { name: nameMatch[1].toLowerCase(), text, start } : null;
+}
+
+function isIntegrityCandidate(tagName: string, tagText: string, urlOffset: number): boolean {
+    const beforeUrl = tagText.substring(0, urlOffset);
+    if (tagName === 'script') {
+        return /\bsrc\s*=\s*["']?$/i.test(beforeUrl);
+    }
+    if (tagName === 'link') {
+        return /\bhref\s*=\s*["']?$/i.test(beforeUrl) && SRI_LINK_RELS.test(tagText);
+    }
+    return false;
+}
@@ -0,0 +1,115 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { StringDecoder } from 'string_decoder';
+
+/** Name stream findings are reported under when no other is given */
+export const STREAM_SOURCE = '<stdin>';
+
+/** Default longest line held in memory, in characters (64 KiB) */
+export const DEFAULT_STREAM_MAX_LINE_LENGTH = 64 * 1024;
+
+/**
+ * Options for URLDetector.scanStream().
+ */
+export interface StreamScanOptions {
+    /** Name the findings are reported under, as their file (default: '<stdin>') */
+    source?: string;
+    /** Longest piece of a line held in memory, in characters (default: 65536) */
+    maxLineLength?: number;
+    /** Stops reading when aborted; a Readable input is destroyed so a pending read ends too */
+    signal?: AbortSignal;
+}
+
+/**
+ * Counts of a finished stream scan.
+ */
+export interface StreamScanSummary {
+    /** Lines read */
+    lines: number;
+    /** Findings passed on */
+    urls: number;
+}
+
+/**
+ * A line of a text stream, or a piece of one longer than the line length limit.
+ */
+export interface StreamLine {
+    /** Text of the line, without the line break */
+    text: string;
+    /** Line number in the stream (1-based) */
+    line: number;
+    /** Column of the first character of text in the line (1-based); above 1 for later pieces of a long line */
+    column: number;
+    /** Character offset of the first character of text in the stream */
+    offset: number;
+}
+
+/**
+ * Splits a text stream into lines as the data arrives, holding at most one line in memory. A line longer
+ * than maxLineLength is passed on in pieces of that length, so a URL crossing a piece boundary is cut in
+ * two. UTF-8 characters split across chunks are decoded whole, and CRLF line breaks are recognized.
+ *
+ * @param input The stream, such as process.stdin
+ * @param maxLineLength Longest piece of a line held in memory, in characters
+ * @returns The lines, in stream order
+ */
+export async function* readStreamLines(
+    input: AsyncIterable<string | Buffer>,
+    maxLineLength: number = DEFAULT_STREAM_MAX_LINE_LENGTH,
+): AsyncGenerator<StreamLine> {
+    if (!(maxLineLength > 0)) {
+        throw new Error('Max line length must be > 0');
+    }
+
+    const decoder = new StringDecoder('utf8');
+    let pending = '';
+    let line = 1;
+    let column = 1;
+    let offset = 0;
+
+    const piece = (text: string, length: number): StreamLine => {
+        const result = { text, line, column, offset };
+        column += length;
+        offset += length;
+        return result;
+    };
+
+    for await (const chunk of input) {
+        pending += typeof chunk === 'string' ? chunk : decoder.write(chunk);
+
+        let start = 0;
+        let newline: number;
+        while ((newline = pending.indexOf('\n', start)) >= 0) {
+            const text = pending.substring(start, newline);
+            yield piece(text.endsWith('\r') ? text.slice(0, -1) : text, newline + 1 - start);
+            start = newline + 1;
+            line++;
+            column = 1;
+        }
+        pending = pending.substring(start);
+        while (pending.length > maxLineLength) {
+            // Keep a trailing CR with the next piece, where it may end the line
+            const length =
+                maxLineLength > 1 && pending.charAt(maxLineLength - 1) === '\r' ? maxLineLength - 1 : maxLineLength;
+            yield piece(pending.substring(0, length), length);
+            pending = pending.substring(length);
+        }
+    }
+
+    pending += decoder.end();
+    if (pending.length > 0) {
+        yield piece(pending.endsWith('\r') ? pending.slice(0, -1) : pending, pending.length);
+    }
+}
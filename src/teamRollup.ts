@@ -0,0 +1,177 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { OWNER_ATTRIBUTE } from './codeOwners';
+import { normalizeFingerprintPath } from './fingerprint';
+import { Severity } from './ruleEngine';
+import { FileResult, URLMatch } from './urlFilter';
+
+/** Team of findings in files no team prefix or CODEOWNERS entry covers */
+export const UNOWNED_TEAM = '(unowned)';
+
+/** Default number of worst offending files listed per team */
+export const DEFAULT_WORST_OFFENDERS = 5;
+
+/**
+ * A file with many findings, listed among a team's worst offenders.
+ */
+export interface TeamOffender {
+    file: string;
+    urlCount: number;
+    violationCount: number;
+}
+
+/**
+ * Finding counts of one team.
+ */
+export interface TeamRollup {
+    team: string;
+    /** Files of the team with findings */
+    fileCount: number;
+    urlCount: number;
+    violationCount: number;
+    /** Violations by severity */
+    severities: Record<Severity, number>;
+    /** Findings whose fingerprint is not in the baseline (only with a baseline) */
+    newCount?: number;
+    /** Baseline findings whose fingerprint is no longer found (only with a baseline) */
+    fixedCount?: number;
+    /** Files with the most violations, then the most findings */
+    worstFiles: TeamOffender[];
+}
+
+/**
+ * Options for buildTeamRollup().
+ */
+export interface TeamRollupOptions {
+    /** Team by path prefix; the longest matching prefix wins (default: use the owner attribute) */
+    teams?: Record<string, string>;
+    /** Results of a previous scan, for new and fixed counts per team */
+    baseline?: FileResult[];
+    /** Worst offending files listed per team (default: DEFAULT_WORST_OFFENDERS) */
+    worstOffenders?: number;
+}
+
+/**
+ * Creates the function assigning findings to teams: by the longest path prefix in the mapping
+ * (`src/payments` covers `src/payments/api.ts`), otherwise by the finding's CODEOWNERS owners.
+ *
+ * @param teams Team by path prefix, relative to the working directory
+ * @returns Resolver from a file and one of its findings to the team
+ */
+export function createTeamResolver(teams: Record<string, string> = {}): (file: string, finding: URLMatch) => string {
+    const prefixes = Object.entries(teams)
+        .map(([prefix, team]) => ({ prefix: normalizePrefix(prefix), team }))
+        .sort((a, b) => b.prefix.length - a.prefix.length);
+
+    return (file, finding) => {
+        const normalized = normalizePrefix(normalizeFingerprintPath(file));
+        const match = prefixes.find(
+            ({ prefix }) => prefix === '' || normalized === prefix || normalized.startsWith(`${prefix}/`),
+        );
+        if (match) return match.team;
+        return (finding.attributes && finding.attributes[OWNER_ATTRIBUTE]) || UNOWNED_TEAM;
+    };
+}
+
+/**
+ * Rolls findings up by team: files, findings, and violations by severity, new and fixed findings
+ * against a baseline, and each team's worst offending files.
+ *
+ * @param results Scan results
+ * @param options Team mapping, baseline, and number of worst offenders
+ * @returns Teams sorted by descending violation count, then finding count, then name
+ */
+export function buildTeamRollup(results: FileResult[], options: TeamRollupOptions = {}): TeamRollup[] {
+    const resolveTeam = createTeamResolver(options.teams);
+    const worstOffenders = options.worstOffenders ?? DEFAULT_WORST_OFFENDERS;
+    const rollups = new Map<string, TeamRollup>();
+    const offenders = new Map<string, Map<string, TeamOffender>>();
+    const current = new Map<string, Set<string>>();
+
+    const rollupOf = (team: string): TeamRollup => {
+        let rollup = rollups.get(team);
+        if (!rollup) {
+            rollup = {
+                team,
+                fileCount: 0,
+                urlCount: 0,
+                violationCount: 0,
+                severities: { error: 0, warning: 0, info: 0 },
+                worstFiles: [],
+            };
+            rollups.set(team, rollup);
+            offenders.set(team, new Map());
+            current.set(team, new Set());
+        }
+        return rollup;
+    };
+
+    for (const result of results) {
+        const file = normalizeFingerprintPath(result.file);
+        for (const finding of result.urls) {
+            const team = resolveTeam(result.file, finding);
+            const rollup = rollupOf(team);
+            const violations = finding.violations || [];
+            rollup.urlCount++;
+            rollup.violationCount += violations.length;
+            violations.forEach(violation => rollup.severities[violation.severity]++);
+            if (finding.fingerprint) current.get(team)!.add(finding.fingerprint);
+
+            const files = offenders.get(team)!;
+            const offender = files.get(file) || { file, urlCount: 0, violationCount: 0 };
+            offender.urlCount++;
+            offender.violationCount += violations.length;
+            files.set(file, offender);
+        }
+    }
+
+    if (options.baseline) {
+        const previous = new Map<string, Set<string>>();
+        for (const result of options.baseline) {
+            for (const finding of result.urls) {
+                if (!finding.fingerprint) continue;
+                const team = resolveTeam(result.file, finding);
+                rollupOf(team);
+                if (!previous.has(team)) previous.set(team, new Set());
+                previous.get(team)!.add(finding.fingerprint);
+            }
+        }
+        for (const [team, rollup] of rollups) {
+            const before = previous.get(team) || new Set<string>();
+            const now = current.get(team)!;
+            rollup.newCount = Array.from(now).filter(fingerprint => !before.has(fingerprint)).length;
+            rollup.fixedCount = Array.from(before).filter(fingerprint => !now.has(fingerprint)).length;
+        }
+    }
+
+    for (const [team, rollup] of rollups) {
+        const files = Array.from(offenders.get(team)!.values());
+        rollup.fileCount = files.length;
+        rollup.worstFiles = files
+            .sort(
+                (a, b) =>
+                    b.violationCount - a.violationCount || b.urlCount - a.urlCount || a.file.localeCompare(b.file),
+            )
+            .slice(0, worstOffenders);
+    }
+
+    return Array.from(rollups.values()).sort(
+        (a, b) => b.violationCount - a.violationCount || b.urlCount - a.urlCount || a.team.localeCompare(b.team),
+    );
+}
+
+function normalizePrefix(prefix: string): string {
+    return prefix.replace(/\\/g, '/').replace(/^\.?\//, '').replace(/\/$/, '');
+}
@@ -0,0 +1,167 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { normalizeEndpoint } from './duplicateEndpoints';
+import { URLFilter, URLMatch } from './urlFilter';
+
+/**
+ * The URL matcher shared by every scan: http(s) URLs and protocol-relative URLs whose host has a
+ * letter, up to whitespace, quotes, angle brackets, braces, or a template `$`.
+ */
+export const URL_PATTERN = /(?:https?:\/\/|\/\/(?=[a-zA-Z0-9.-]+[a-zA-Z]))[^\s<>"'`${}]+/g;
+
+/**
+ * Formal public identifiers of DTDs and schemas (e.g., '//W3C//DTD XHTML 1.0//EN'), which the URL
+ * pattern matches but which are not URLs.
+ */
+export const SCHEMA_IDENTIFIER_PATTERNS: RegExp[] = [
+    /^\/\/W3C\/\/DTD/i,
+    /^\/\/EN$/i,
+    /^\/\/IETF\/\/DTD/i,
+    /^\/\/OASIS\/\/DTD/i,
+    /^\/\/ISO\/\/DTD/i,
+    /^\/\/XML-DEV\/\/DTD/i,
+    /^\/\/Apache\/\/DTD/i,
+    /^\/\/Sun\/\/DTD/i,
+    /^\/\/Dublin Core\/\/DTD/i,
+];
+
+/**
+ * Options for extracting URLs from plain text.
+ */
+export interface TextExtractionOptions {
+    /** Whether to report protocol-relative URLs ('//cdn.example.com/app.js') (default: false) */
+    includeProtocolRelative?: boolean;
+    /** Host patterns to drop, exact or glob, in addition to www.w3.org (default: []) */
+    ignoreDomains?: string[];
+    /** Whether to keep hosts that are not fully qualified, like localhost (default: false) */
+    includeNonFqdn?: boolean;
+    /** Whether to report URLs normalized as by normalizeEndpoint (default: false) */
+    normalize?: boolean;
+    /** Whether to report each URL once, at its first occurrence, after normalization (default: false) */
+    unique?: boolean;
+}
+
+/**
+ * Whether a match of URL_PATTERN is the formal public identifier of a DTD or schema.
+ *
+ * @param url The match
+ * @returns True for identifiers such as '//W3C//DTD HTML 4.01//EN'
+ */
+export function isSchemaIdentifier(url: string): boolean {
+    return SCHEMA_IDENTIFIER_PATTERNS.some(pattern => pattern.test(url));
+}
+
+/**
+ * Extracts URLs from plain text such as logs, chat messages, or command output, with the matcher
+ * source scans use but without parsing a language. Prose puts punctuation right after URLs, so
+ * trailing sentence punctuation (`.`, `,`, `;`, `:`, `!`, `?`) and closing brackets without an
+ * opening one in the URL are left out: 'see https://example.com/a_(b).' yields
+ * 'https://example.com/a_(b)'. A protocol-relative match directly after a word character, a colon,
+ * or a slash belongs to another scheme or a path and is never reported.
+ *
+ * Findings have sourceType 'unknown', and their start and end are character offsets into the text
+ * (decoded as UTF-8 when a Buffer is given), even when normalize rewrites the url.
+ *
+ * @param text The text
+ * @param options Extraction options
+ * @returns Findings in text order
+ *
+ * @example
+ * ```typescript
+ * const urls = extractUrls('GET https://api.example.com/v1/orders failed (see https://status.example.com).');
+ * urls.map(url => url.url); // ['https://api.example.com/v1/orders', 'https://status.example.com']
+ * ```
+ */
+export function extractUrls(text: string | Buffer, options: TextExtractionOptions = {}): URLMatch[] {
+    const content = typeof text === 'string' ? text : text.toString('utf8');
+    const filter = new URLFilter({
+        ignoreDomains: options.ignoreDomains,
+        includeComments: true,
+        includeNonFqdn: options.includeNonFqdn,
+    });
+
+    const findings: URLMatch[] = [];
+    let line = 1;
+    let lineStart = 0;
+    let counted = 0;
+    for (const match of content.matchAll(URL_PATTERN)) {
+        const start = match.index!;
+        const protocolRelative = match[0].startsWith('//');
+        if (protocolRelative && (!options.includeProtocolRelative || /[\w:/]/.test(content.charAt(start - 1)))) {
+            continue;
+        }
+        if (isSchemaIdentifier(match[0])) continue;
+
+        const url = trimUrl(match[0]);
+        if (/^(?:https?:)?\/\/$/.test(url)) continue;
+
+        for (; counted < start; counted++) {
+            if (content.charCodeAt(counted) === 0x0a) {
+                line++;
+                lineStart = counted + 1;
+            }
+        }
+        findings.push({
+            url,
+            start,
+            end: start + url.length,
+            line,
+            column: start - lineStart + 1,
+            sourceType: 'unknown',
+        });
+    }
+
+    let extracted = filter.filterUrls(findings);
+    if (options.normalize) {
+        extracted = extracted.map(finding => ({ ...finding, url: normalizeEndpoint(finding.url) || finding.url }));
+    }
+    if (options.unique) {
+        const seen = new Set<string>();
+        extracted = extracted.filter(finding => {
+            if (seen.has(finding.url)) return false;
+            seen.add(finding.url);
+            return true;
+        });
+    }
+    return extracted;
+}
+
+/** Pairs of brackets a URL may legitimately end in when it opened them itself */
+const CLOSING_BRACKETS: Record<string, string> = { ')': '(', ']': '[' };
+
+/**
+ * Removes sentence punctuation and unbalanced closing brackets from the end of a URL.
+ */
+function trimUrl(url: string): string {
+    let end = url.length;
+    for (;;) {
+        const last = url.charAt(end - 1);
+        if ('.,;:!?'.includes(last)) {
+            end--;
+        } else if (CLOSING_BRACKETS[last] && count(url, last, end) > count(url, CLOSING_BRACKETS[last], end)) {
+            end--;
+        } else {
+            return url.substring(0, end);
+        }
+    }
+}
+
+function count(text: string, character: string, end: number): number {
+    let total = 0;
+    for (let index = 0; index < end; index++) {
+        if (text.charAt(index) === character) total++;
+    }
+    return total;
+}
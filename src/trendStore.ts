@@ -0,0 +1,202 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { writeAtomically } from './atomicWrite';
+import { Report } from './report';
+import { SEVERITIES, getFindingSeverity } from './ruleEngine';
+
+/** Default location of the trend store, relative to the working directory */
+export const DEFAULT_TREND_STORE = '.url-detector/trends.json';
+
+/** Severity bucket for findings without violations */
+export const NO_SEVERITY = 'none';
+
+/** Category bucket for findings without a category (plain URLs) */
+export const UNCATEGORIZED = 'url';
+
+/** Version of the trend store file layout */
+const TREND_STORE_VERSION = 1;
+
+/**
+ * Finding counts for one commit or date.
+ */
+export interface TrendSnapshot {
+    /** Commit SHA or date (YYYY-MM-DD) the snapshot is recorded for; recording the same key again replaces it */
+    key: string;
+    /** Commit SHA, when the snapshot is recorded for a commit */
+    commit?: string;
+    /** ISO timestamp of the recording */
+    recordedAt: string;
+    /** Total number of findings */
+    total: number;
+    /** Findings per highest violation severity ('none' for findings without violations) */
+    bySeverity: Record<string, number>;
+    /** Findings per category ('url' for plain URLs) */
+    byCategory: Record<string, number>;
+}
+
+/**
+ * Serialization format for a trend series.
+ */
+export type TrendFormat = 'json' | 'csv';
+
+interface TrendStoreFile {
+    version: number;
+    snapshots: TrendSnapshot[];
+}
+
+/**
+ * Counts the findings of a report by severity and category.
+ *
+ * @param report The report to summarize
+ * @param target Commit or date the snapshot is recorded for; the date wins when both are given
+ * @param recordedAt Time of the recording (default: now)
+ * @returns The snapshot
+ */
+export function createTrendSnapshot(
+    report: Report,
+    target: { commit?: string; date?: string },
+    recordedAt: Date = new Date(),
+): TrendSnapshot {
+    const bySeverity: Record<string, number> = {};
+    const byCategory: Record<string, number> = {};
+    let total = 0;
+
+    for (const result of report.files) {
+        for (const urlObj of result.urls) {
+            const severity = getFindingSeverity(urlObj) || NO_SEVERITY;
+            const category = urlObj.category || UNCATEGORIZED;
+            bySeverity[severity] = (bySeverity[severity] || 0) + 1;
+            byCategory[category] = (byCategory[category] || 0) + 1;
+            total++;
+        }
+    }
+
+    const snapshot: TrendSnapshot = {
+        key: target.date || target.commit || recordedAt.toISOString().substring(0, 10),
+        recordedAt: recordedAt.toISOString(),
+        total,
+        bySeverity,
+        byCategory,
+    };
+    if (target.commit) snapshot.commit = target.commit;
+    return snapshot;
+}
+
+/**
+ * File-backed store of trend snapshots, ordered by recording time. The store is a single JSON file
+ * that can be cached between CI runs or committed alongside the dashboard that charts it.
+ */
+export class TrendStore {
+    private filePath: string;
+
+    /**
+     * @param filePath Path of the store file (default: .url-detector/trends.json)
+     */
+    constructor(filePath: string = DEFAULT_TREND_STORE) {
+        this.filePath = filePath;
+    }
+
+    /**
+     * Loads every snapshot in the store.
+     *
+     * @returns Snapshots ordered by recording time, or an empty array when the store does not exist yet
+     * @throws {Error} When the store file is not a valid trend store
+     */
+    public async load(): Promise<TrendSnapshot[]> {
+        let text: string;
+        try {
+            text = await fs.promises.readFile(this.filePath, 'utf8');
+        } catch (error: any) {
+            if (error.code === 'ENOENT') return [];
+            throw error;
+        }
+
+        const data = JSON.parse(text) as TrendStoreFile;
+        if (!data || !Array.isArray(data.snapshots)) {
+            throw new Error(`Invalid trend store ${this.filePath}: missing snapshots array`);
+        }
+        return data.snapshots;
+    }
+
+    /**
+     * Adds a snapshot to the store, replacing any snapshot with the same key.
+     *
+     * @param snapshot The snapshot to record
+     * @returns The snapshots in the store after recording
+     */
+    public async record(snapshot: TrendSnapshot): Promise<TrendSnapshot[]> {
+        const snapshots = (await this.load()).filter(existing => existing.key !== snapshot.key);
+        snapshots.push(snapshot);
+        snapshots.sort((a, b) => a.recordedAt.localeCompare(b.recordedAt));
+
+        const data: TrendStoreFile = { version: TREND_STORE_VERSION, snapshots };
+        await writeAtomically(this.filePath, JSON.stringify(data, null, 2));
+        return snapshots;
+    }
+
+    /**
+     * Loads the snapshots recorded on or after a date.
+     *
+     * @param since ISO date or timestamp; all snapshots are returned when omitted
+     * @returns Matching snapshots ordered by recording time
+     */
+    public async series(since?: string): Promise<TrendSnapshot[]> {
+        const snapshots = await this.load();
+        return since ? snapshots.filter(snapshot => snapshot.recordedAt >= since) : snapshots;
+    }
+}
+
+/**
+ * Serializes a trend series for charting. JSON keeps the snapshot structure; CSV has one row per
+ * snapshot with a column per severity (`severity:error`, ...) and category (`category:url`, ...)
+ * seen anywhere in the series.
+ *
+ * @param snapshots Snapshots ordered by recording time
+ * @param format Target format
+ * @returns Serialized series
+ */
+export function formatTrendSeries(snapshots: TrendSnapshot[], format: TrendFormat): string {
+    if (format === 'json') {
+        return JSON.stringify({ series: snapshots }, null, 2);
+    }
+    if (format !== 'csv') {
+        throw new Error(`Unknown trend format: ${format}`);
+    }
+
+    const severityOrder = [...SEVERITIES].reverse().concat(NO_SEVERITY);
+    const severities = severityOrder.filter(severity => snapshots.some(s => severity in s.bySeverity));
+    const categories = Array.from(new Set(snapshots.flatMap(s => Object.keys(s.byCategory)))).sort();
+
+    const headers = [
+        'Key',
+        'Commit',
+        'RecordedAt',
+        'Total',
+        ...severities.map(severity => `severity:${severity}`),
+        ...categories.map(category => `category:${category}`),
+    ];
+    const rows = snapshots.map(snapshot =>
+        [
+            snapshot.key,
+            snapshot.commit || '',
+            snapshot.recordedAt,
+            snapshot.total,
+            ...severities.map(severity => snapshot.bySeverity[severity] || 0),
+            ...categories.map(category => snapshot.byCategory[category] || 0),
+        ].join(','),
+    );
+    return [headers.join(','), ...rows].join('\n');
+}
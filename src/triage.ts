@@ -0,0 +1,205 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import { writeAtomically } from './atomicWrite';
+import { FileResult, setFindingAttribute } from './urlFilter';
+
+/** Default location of the triage store, relative to the working directory */
+export const DEFAULT_TRIAGE_STORE = '.url-detector/triage.json';
+
+/** Attribute holding the triage state of a finding */
+export const TRIAGE_ATTRIBUTE = 'triage';
+
+/**
+ * Remediation state of a finding.
+ */
+export type TriageState = 'open' | 'accepted-risk' | 'false-positive' | 'fixed';
+
+/**
+ * All triage states.
+ */
+export const TRIAGE_STATES: TriageState[] = ['open', 'accepted-risk', 'false-positive', 'fixed'];
+
+/** States that hide a finding from reports when hideTriaged is set */
+const DISMISSED_STATES: TriageState[] = ['accepted-risk', 'false-positive'];
+
+/** Version of the triage store file layout */
+const TRIAGE_STORE_VERSION = 1;
+
+/**
+ * The recorded triage decision for one finding.
+ */
+export interface TriageEntry {
+    /** Fingerprint of the finding */
+    fingerprint: string;
+    state: TriageState;
+    /** Reason for the decision */
+    note?: string;
+    /** URL of the finding, when the entry was recorded from a scan */
+    url?: string;
+    /** File of the finding, when the entry was recorded from a scan */
+    file?: string;
+    /** ISO timestamp of the last change */
+    updatedAt: string;
+}
+
+interface TriageStoreFile {
+    version: number;
+    entries: TriageEntry[];
+}
+
+/**
+ * Validates a triage state given on the command line or in a file.
+ *
+ * @param state The state to check
+ * @returns The state
+ * @throws {Error} When the state is not a triage state
+ */
+export function parseTriageState(state: string): TriageState {
+    if (!TRIAGE_STATES.includes(state as TriageState)) {
+        throw new Error(`Unknown triage state: ${state}. Valid states: ${TRIAGE_STATES.join(', ')}`);
+    }
+    return state as TriageState;
+}
+
+/**
+ * Triage decisions keyed by finding fingerprint, stored in a JSON file. Because fingerprints survive
+ * line renumbering, a decision made once is carried forward to every later scan of the same finding.
+ */
+export class TriageStore {
+    private filePath: string;
+
+    /**
+     * @param filePath Path of the store file (default: .url-detector/triage.json)
+     */
+    constructor(filePath: string = DEFAULT_TRIAGE_STORE) {
+        this.filePath = filePath;
+    }
+
+    /**
+     * Loads every entry in the store.
+     *
+     * @returns Entries keyed by fingerprint, empty when the store does not exist yet
+     * @throws {Error} When the store file is not a valid triage store
+     */
+    public async load(): Promise<Map<string, TriageEntry>> {
+        let text: string;
+        try {
+            text = await fs.promises.readFile(this.filePath, 'utf8');
+        } catch (error: any) {
+            if (error.code === 'ENOENT') return new Map();
+            throw error;
+        }
+
+        const data = JSON.parse(text) as TriageStoreFile;
+        if (!data || !Array.isArray(data.entries)) {
+            throw new Error(`Invalid triage store ${this.filePath}: missing entries array`);
+        }
+        return new Map(data.entries.map(entry => [entry.fingerprint, entry]));
+    }
+
+    /**
+     * Writes entries to the store, replacing the whole file.
+     *
+     * @param entries Entries keyed by fingerprint
+     */
+    public async save(entries: Map<string, TriageEntry>): Promise<void> {
+        const sorted = Array.from(entries.values()).sort((a, b) => a.fingerprint.localeCompare(b.fingerprint));
+        const data: TriageStoreFile = { version: TRIAGE_STORE_VERSION, entries: sorted };
+
+        await writeAtomically(this.filePath, JSON.stringify(data, null, 2));
+    }
+
+    /**
+     * Records a triage decision for findings.
+     *
+     * @param fingerprints Fingerprints of the findings
+     * @param state The new state
+     * @param note Reason for the decision; an existing note is kept when omitted
+     * @returns The updated entries
+     */
+    public async setState(fingerprints: string[], state: TriageState, note?: string): Promise<TriageEntry[]> {
+        const entries = await this.load();
+        const updatedAt = new Date().toISOString();
+
+        const updated = fingerprints.map(fingerprint => {
+            const existing = entries.get(fingerprint);
+            const entry: TriageEntry = { ...existing, fingerprint, state, updatedAt };
+            if (note !== undefined) entry.note = note;
+            entries.set(fingerprint, entry);
+            return entry;
+        });
+
+        await this.save(entries);
+        return updated;
+    }
+}
+
+/**
+ * Result of carrying triage states forward onto a scan.
+ */
+export interface TriageSummary {
+    /** Findings per state, counting findings without an entry as 'open' */
+    counts: Record<TriageState, number>;
+    /** Fingerprints of findings marked 'fixed' that were found again and reopened */
+    reopened: string[];
+}
+
+/**
+ * Carries triage states forward onto scan results: every finding gets its state in the 'triage'
+ * attribute ('open' when it was never triaged). Findings marked 'fixed' that are found again are
+ * reopened in the store, so regressions are not hidden by an old decision.
+ *
+ * @param results Scan results with fingerprints assigned
+ * @param store The triage store
+ * @param hideTriaged Whether to drop findings marked 'accepted-risk' or 'false-positive' from the results
+ * @returns Counts per state and the reopened findings
+ */
+export async function applyTriage(
+    results: FileResult[],
+    store: TriageStore,
+    hideTriaged: boolean = false,
+): Promise<TriageSummary> {
+    const entries = await store.load();
+    const counts: Record<TriageState, number> = { open: 0, 'accepted-risk': 0, 'false-positive': 0, fixed: 0 };
+    const reopened: string[] = [];
+    const updatedAt = new Date().toISOString();
+
+    for (const result of results) {
+        for (const urlObj of result.urls) {
+            const entry = urlObj.fingerprint ? entries.get(urlObj.fingerprint) : undefined;
+            let state: TriageState = entry ? entry.state : 'open';
+            if (entry && state === 'fixed') {
+                state = 'open';
+                entries.set(entry.fingerprint, { ...entry, state, url: urlObj.url, file: result.file, updatedAt });
+                reopened.push(entry.fingerprint);
+            }
+
+            counts[state]++;
+            setFindingAttribute(urlObj, TRIAGE_ATTRIBUTE, state);
+        }
+
+        if (hideTriaged) {
+            result.urls = result.urls.filter(
+                urlObj => !DISMISSED_STATES.includes(urlObj.attributes![TRIAGE_ATTRIBUTE] as TriageState),
+            );
+        }
+    }
+
+    if (reopened.length > 0) {
+        await store.save(entries);
+    }
+    return { counts, reopened };
+}
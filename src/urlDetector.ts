@@ -14,14 +14,69 @@
 
 import * as fs from 'fs';
 import * as path from 'path';
+import { Readable } from 'stream';
 import fg from 'fast-glob';
+import { minimatch } from 'minimatch';
 import Parser from 'tree-sitter';
-import { LanguageManager } from './languageManager';
+import { GrammarIssue, LanguageManager } from './languageManager';
 import { DetectorOptions, DetectorOptionsConfig } from './options';
-import { URLFilter, URLMatch } from './urlFilter';
+import { URLFilter, URLMatch, setFindingAttribute } from './urlFilter';
 import pLimit from 'p-limit';
 import { sanitizeGlobPatterns } from './pathSanitizer';
 import { Logger, NullLogger } from './logger';
+import { Rule, RuleEngine } from './ruleEngine';
+import { assignFingerprints, assignLineFingerprints, normalizeFingerprintPath } from './fingerprint';
+import { GitMetadataSource, collectGitMetadata } from './gitMetadata';
+import { GENERATED_ATTRIBUTE, isGeneratedFile } from './generatedCode';
+import { SCOPE_ATTRIBUTE, classifyCodeScope } from './codeScope';
+import { checkLicenseLinks, createLicenseUrlRule, selectLicenseHeaderUrls } from './licenseHeaders';
+import { DocLinkValidator, extractDocLinks, isDocumentationFile } from './docLinks';
+import { RELATIVE_URL_LANGUAGES, extractRelativeUrls } from './relativeUrls';
+import { extractWindowsPaths } from './windowsPaths';
+import { isProseFile, tokenKindAt, tokenize } from './genericTokenizer';
+import { classifyRealtimeEndpoint, extractGrpcTargets, mergeGrpcTargets } from './realtimeEndpoints';
+import { ApiCatalog, createOpenApiRule } from './openApi';
+import { DeprecationRegistry, createDeprecationRule } from './deprecations';
+import { parsePatch, patchedContent } from './patchScan';
+import { LanguageCoverage, buildLanguageCoverage } from './coverage';
+import { createSriAdvisoryRule } from './sriAdvisory';
+import { createEncodingAnomalyRule } from './encodingAnomalies';
+import { createOpenRedirectRule } from './openRedirect';
+import { createObfuscatedHostRule } from './obfuscatedHosts';
+import { createCanaryTokenRule, parseCanaryDomains } from './canaryTokens';
+import { classifyFalsePositive } from './falsePositives';
+import { applyLanguageOptions } from './languageOptions';
+import { createGoImportRules, extractGoImports, extractGoModPaths } from './goImports';
+import { CodeOwners, OWNER_ATTRIBUTE } from './codeOwners';
+import { applySeverityEscalation } from './severityEscalation';
+import { annotateIntroduced } from './gitBlame';
+import { annotateNetworkCalls } from './goNetworkFlow';
+import { collapseDuplicateFiles, contentHash } from './duplicateFiles';
+import { loadRulePlugin } from './pluginSandbox';
+import { URL_PATTERN, extractUrls, isSchemaIdentifier } from './textExtraction';
+import { STREAM_SOURCE, StreamScanOptions, StreamScanSummary, readStreamLines } from './streamScan';
+import { FEED_ATTRIBUTE, HostData } from './dataBundle';
+import { TriageStore, applyTriage } from './triage';
+import { CategoryRules } from './categoryRules';
+import { FindingPredicate, applyFilter, compileFilter, resolveFilter } from './filterExpression';
+import { createSchemePolicyRule } from './schemePolicy';
+import { ScanManifest, createScanManifest } from './manifest';
+import { DEFAULT_HTTP_CLIENT, HttpClient } from './httpClient';
+import { planSegments, readSegment, scanSegment, stitchSegments } from './segmentedScan';
+import { BINARY_SNIFF_LENGTH, SkipReason, SkippedFile, isBinaryContent } from './skipDiagnostics';
+import { SampleEstimate, estimateTotals, parseSampleRate, selectSample } from './sampling';
+import { dropSuppressed } from './quickfix';
+import {
+    BINARY_OFFSET_ATTRIBUTE,
+    DEFAULT_MIN_STRING_LENGTH,
+    detectExecutableFormat,
+    extractStrings,
+    isGoBinary,
+    stringByteOffset,
+} from './binaryStrings';
+
+/** Bytes read from the top of a segmented file to recognize generated files and license headers */
+const SEGMENTED_HEAD_BYTES = 64 * 1024;
 
 /**
  * Result data for a single file scan
@@ -33,8 +88,44 @@ export interface FileResult {
     urls: URLMatch[];
 }
 
+/**
+ * How a file matching the scan patterns would be scanned, as listed by listFiles()
+ */
+export interface FileListing {
+    /** Path relative to the working directory */
+    file: string;
+    /** Language detected from the path ('unknown' when no language is configured) */
+    language: string;
+    /** Parsed with a tree-sitter grammar, scanned with regex detection, or skipped */
+    mode: 'parse' | 'regex' | 'skip';
+    /** Why the file would be skipped, or 'segmented' for a file scanned in segments */
+    reason?: SkipReason | 'segmented';
+}
+
 /* eslint-disable @typescript-eslint/no-explicit-any, @typescript-eslint/no-unused-vars */
 
+/**
+ * An entry of a directory read while finding files.
+ */
+interface ReadEntry {
+    /** Absolute path */
+    path: string;
+    directory: boolean;
+}
+
+/**
+ * A file that is scanned, with the content read to decide it.
+ */
+interface AdmittedFile {
+    size: number;
+    /** Whether the file is scanned in segments */
+    segmented: boolean;
+    /** The whole file, or its first bytes for a segmented file or a listing */
+    content: Buffer;
+    /** Whether the content is generated or minified */
+    generated: boolean;
+}
+
 /**
  * Internal interface representing a tree-sitter AST node.
  * Used for type safety when traversing the abstract syntax tree.
@@ -82,10 +173,22 @@ export class URLDetector {
     private parser: Parser;
     private languageManager: LanguageManager;
     private urlPattern: RegExp;
-    private commonSchemaPatterns: RegExp[];
     private urlFilter: URLFilter;
+    private ruleEngine: RuleEngine;
+    private docLinkValidator: DocLinkValidator;
+    private codeOwners: CodeOwners | null = null;
+    private hostData: HostData | null = null;
+    private categoryRules: CategoryRules;
+    private findingFilter: FindingPredicate | null;
+    private scannedFileCount = 0;
+    private partial = false;
+    private unparsedFiles = new Map<string, string>();
+    private skippedFiles = new Map<string, SkippedFile>();
+    private contentHashes = new Map<string, string>();
+    private sampleEstimate: SampleEstimate | null = null;
 
     private logger: Logger;
+    private httpClient: HttpClient;
 
     /**
      * Creates a new URLDetector instance with the specified configuration options.
@@ -96,6 +199,7 @@ export class URLDetector {
      *
      * @param options Configuration options for URL detection
      * @param logger Logger instance for output messages (defaults to NullLogger for quiet operation)
+     * @param httpClient Network stack for license link checks (defaults to the global fetch)
      * @example
      * ```typescript
      * const detector = new URLDetector({
@@ -106,28 +210,57 @@ export class URLDetector {
      * }, ConsoleLogger);
      * ```
      */
-    constructor(options: DetectorOptionsConfig = {}, logger: Logger = NullLogger) {
+    constructor(
+        options: DetectorOptionsConfig = {},
+        logger: Logger = NullLogger,
+        httpClient: HttpClient = DEFAULT_HTTP_CLIENT,
+    ) {
         this.options = new DetectorOptions(options);
         this.logger = logger;
+        this.httpClient = httpClient;
         this.parser = new Parser();
         this.languageManager = new LanguageManager(this.logger);
-        this.urlPattern = /(?:https?:\/\/|\/\/(?=[a-zA-Z0-9.-]+[a-zA-Z]))[^\s<>"'`${}]+/g;
-        this.commonSchemaPatterns = [
-            /^\/\/W3C\/\/DTD/i,
-            /^\/\/EN$/i,
-            /^\/\/IETF\/\/DTD/i,
-            /^\/\/OASIS\/\/DTD/i,
-            /^\/\/ISO\/\/DTD/i,
-            /^\/\/XML-DEV\/\/DTD/i,
-            /^\/\/Apache\/\/DTD/i,
-            /^\/\/Sun\/\/DTD/i,
-            /^\/\/Dublin Core\/\/DTD/i,
-        ];
-        this.urlFilter = new URLFilter({
-            ignoreDomains: this.options.ignoreDomains,
-            includeComments: this.options.includeComments,
-            includeNonFqdn: this.options.includeNonFqdn,
-        });
+        this.urlPattern = new RegExp(URL_PATTERN);
+        this.urlFilter = this.createUrlFilter();
+        this.categoryRules = new CategoryRules(this.options.categoryRules);
+        this.findingFilter = this.options.filter
+            ? compileFilter(resolveFilter(this.options.filter, this.options.filters))
+            : null;
+        this.ruleEngine = new RuleEngine(this.logger);
+        this.docLinkValidator = new DocLinkValidator();
+        if (this.options.licenseHeaders) {
+            this.ruleEngine.register(createLicenseUrlRule());
+        }
+        if (this.options.sriAdvisory) {
+            this.ruleEngine.register(createSriAdvisoryRule());
+        }
+        if (this.options.encodingAnomalies) {
+            this.ruleEngine.register(createEncodingAnomalyRule());
+        }
+        if (this.options.obfuscatedHosts) {
+            this.ruleEngine.register(createObfuscatedHostRule());
+        }
+        if (this.options.openRedirect) {
+            this.ruleEngine.register(createOpenRedirectRule(this.options.redirectParams));
+        }
+        if (this.options.canaryTokens) {
+            const domains = parseCanaryDomains(this.options.canaryDomains, this.options.canaryPolicy);
+            this.ruleEngine.register(createCanaryTokenRule(domains));
+        }
+        if (this.options.schemePolicy.length > 0) {
+            this.ruleEngine.register(createSchemePolicyRule(this.options.schemePolicy));
+        }
+        if (this.options.auditGoImports) {
+            createGoImportRules(this.options.goImportPolicy).forEach(rule => this.ruleEngine.register(rule));
+        }
+        for (const plugin of this.options.rulePlugins) {
+            try {
+                loadRulePlugin(plugin).forEach(rule => this.ruleEngine.register(rule));
+            } catch (error: unknown) {
+                const errorMessage = error instanceof Error ? error.message : String(error);
+                this.logger.warn(`${errorMessage}; skipping its rules`);
+            }
+        }
     }
 
     /**
@@ -150,13 +283,133 @@ export class URLDetector {
         return this.urlFilter;
     }
 
+    /**
+     * Gets the rule engine used by this detector.
+     *
+     * @returns The RuleEngine instance that evaluates findings during process()
+     */
+    public get getRuleEngine(): RuleEngine {
+        return this.ruleEngine;
+    }
+
+    /**
+     * Registers a policy rule that is evaluated against every finding during process().
+     * Violations raised by the rule are attached to the finding's `violations` array.
+     *
+     * @param rule The rule to register; replaces any existing rule with the same id
+     *
+     * @example
+     * ```typescript
+     * detector.registerRule({
+     *     id: 'no-plain-http',
+     *     evaluate: (finding, file) =>
+     *         finding.url.startsWith('http://') && !file.file.includes('/test/')
+     *             ? [{ rule: 'no-plain-http', severity: 'error', message: 'Use https' }]
+     *             : [],
+     * });
+     * ```
+     */
+    public registerRule(rule: Rule): void {
+        this.ruleEngine.register(rule);
+    }
+
+    /**
+     * Removes a previously registered policy rule.
+     *
+     * @param id The id of the rule to remove
+     * @returns true if the rule was found and removed, false otherwise
+     */
+    public unregisterRule(id: string): boolean {
+        return this.ruleEngine.unregister(id);
+    }
+
+    /**
+     * Whether the last process() run was interrupted through its abort signal, so its results only
+     * cover the files scanned before the interruption.
+     */
+    public get isPartial(): boolean {
+        return this.partial;
+    }
+
+    /**
+     * Summarizes which files of the last process() run had no parser for their language, and were
+     * scanned with regex detection or, without fallbackRegex, skipped.
+     *
+     * @returns The language coverage
+     */
+    public getLanguageCoverage(): LanguageCoverage {
+        return buildLanguageCoverage(this.scannedFileCount, this.unparsedFiles, this.options.fallbackRegex);
+    }
+
+    /**
+     * Lists the files of the last process() run that were not scanned for URLs, with the reason for
+     * each: too large, binary, excluded by a pattern, no parser, parse timeout or error, generated,
+     * unreadable, or not reached before an interruption.
+     *
+     * @returns The skipped files sorted by path
+     */
+    public getSkippedFiles(): SkippedFile[] {
+        return Array.from(this.skippedFiles.values()).sort((a, b) => a.file.localeCompare(b.file));
+    }
+
+    /**
+     * Estimates the totals a full scan would report from the last process() run, when the sample
+     * option was set. Interrupted runs have no estimate, since their unscanned files would bias it.
+     *
+     * @returns The estimate, or null when the run scanned every file or was interrupted
+     */
+    public getSampleEstimate(): SampleEstimate | null {
+        return this.sampleEstimate;
+    }
+
+    /**
+     * Lists the grammars that were installed but rejected by the tree-sitter core library, typically
+     * because they were built for another ABI version. Their files are handled like files without a parser.
+     *
+     * @returns The issues, empty when every grammar is compatible
+     */
+    public getGrammarIssues(): GrammarIssue[] {
+        return this.languageManager.getGrammarIssues();
+    }
+
+    /**
+     * Creates the reproducibility manifest of the last process() run: tool and grammar versions,
+     * config and policy hashes, the effective configuration, the number of files scanned, whether the
+     * run was interrupted, and the git commit of the working directory.
+     *
+     * @param profile Built-in profile the options were resolved with, recorded in the manifest
+     * @returns Promise resolving to the manifest
+     *
+     * @example
+     * ```typescript
+     * const results = await detector.process();
+     * const report = createReport(results, undefined, await detector.createManifest());
+     * ```
+     */
+    public async createManifest(profile?: string): Promise<ScanManifest> {
+        const manifest = await createScanManifest(
+            this.options,
+            this.ruleEngine.getRules(),
+            this.languageManager.getGrammarVersions(),
+            this.scannedFileCount,
+            this.partial,
+        );
+        manifest.treeSitterVersion = LanguageManager.getTreeSitterVersion();
+        manifest.languageGrammars = this.languageManager.getLanguageGrammars();
+        if (profile) manifest.profile = profile;
+        return manifest;
+    }
+
     /**
      * Detects URLs in the provided source code using tree-sitter parsing.
      *
      * This method is the core URL detection functionality. It attempts to parse the source code
      * using the appropriate tree-sitter grammar for the specified language, then traverses the
      * abstract syntax tree to find URLs in string literals and comments. If parsing fails or
-     * no grammar is available, it can optionally fall back to regex-based detection.
+     * no grammar is available, it can optionally fall back to regex-based detection. In Go, the
+     * string targets of gRPC dial calls are reported as well, in the 'realtime-rpc' category. URLs in
+     * the parts of a file turned off by languageOptions (Go struct tags, Markdown code) are dropped.
+     * Every returned URL carries a stable `fingerprint` that does not depend on its line number.
      *
      * @param sourceCode The source code content to scan for URLs
      * @param language The programming language of the source code (e.g., 'javascript', 'python')
@@ -172,10 +425,24 @@ export class URLDetector {
      * ```
      */
     public async detectURLs(sourceCode: string, language: string, filePath: string = '<unknown>'): Promise<URLMatch[]> {
+        let urls = this.extractURLs(sourceCode, language, filePath);
+        if (language === 'go') {
+            urls = mergeGrpcTargets(urls, extractGrpcTargets(sourceCode));
+        }
+        urls = applyLanguageOptions(urls, sourceCode, filePath, language, this.options.languageOptions);
+        return assignFingerprints(urls, filePath, sourceCode);
+    }
+
+    private extractURLs(sourceCode: string, language: string, filePath: string): URLMatch[] {
+        let timedOut = false;
         try {
             const languageGrammar = this.languageManager.getLanguage(language);
+            if (!languageGrammar) {
+                this.unparsedFiles.set(filePath, language);
+            }
 
-            if (!languageGrammar && !this.options.fallbackRegex) {
+            if (this.scanMode(language) === 'skip') {
+                this.skip(filePath, 'unsupported-language', `No parser for ${language}`);
                 return [];
             }
 
@@ -183,13 +450,24 @@ export class URLDetector {
                 this.logger.warn(
                     `No parser available for language ${language} for file ${filePath}, using fallback regex`,
                 );
+                if (this.options.genericTokenizer && !isProseFile(filePath)) {
+                    return this.tokenizedDetection(sourceCode, filePath);
+                }
                 return this.fallbackDetection(sourceCode, filePath);
             }
 
             // Create a fresh parser instance to avoid conflicts
             const parser = new Parser();
             parser.setLanguage(languageGrammar as Parser.Language);
+            if (this.options.parseTimeout > 0) {
+                parser.setTimeoutMicros(this.options.parseTimeout * 1000);
+            }
             const tree = parser.parse(sourceCode);
+            if (!tree) {
+                // Tree-sitter gives up without a tree when the timeout expires
+                timedOut = true;
+                throw new Error(`Parsing took longer than ${this.options.parseTimeout} ms`);
+            }
 
             return this.extractURLsFromTree(tree, sourceCode, filePath);
         } catch (error: any) {
@@ -200,6 +478,7 @@ export class URLDetector {
                 return this.fallbackDetection(sourceCode, filePath);
             } else {
                 this.logger.warn(`Failed to parse ${filePath}: ${error.message}`);
+                this.skip(filePath, timedOut ? 'parse-timeout' : 'parse-error', error.message);
                 return [];
             }
         }
@@ -403,7 +682,7 @@ export class URLDetector {
 
         while ((match = this.urlPattern.exec(text)) !== null) {
             // Skip common schema/DOCTYPE patterns that aren't real URLs
-            if (this.isCommonSchemaPattern(match[0])) {
+            if (isSchemaIdentifier(match[0])) {
                 continue;
             }
 
@@ -433,10 +712,6 @@ export class URLDetector {
         return urls;
     }
 
-    private isCommonSchemaPattern(url: string): boolean {
-        return this.commonSchemaPatterns.some(pattern => pattern.test(url));
-    }
-
     private fallbackDetection(sourceCode: string, filePath: string): URLMatch[] {
         const urls: URLMatch[] = [];
         const sourceLines = sourceCode.split('\n');
@@ -447,7 +722,7 @@ export class URLDetector {
             const column = this.getColumnNumber(sourceCode, match.index);
 
             // Skip common schema/DOCTYPE patterns that aren't real URLs
-            if (this.isCommonSchemaPattern(match[0])) {
+            if (isSchemaIdentifier(match[0])) {
                 continue;
             }
 
@@ -472,8 +747,68 @@ export class URLDetector {
         return urls;
     }
 
+    /**
+     * Regex detection for files without a parser, with each finding's source type taken from the
+     * strings and comments the generic tokenizer finds, so comment handling still applies.
+     */
+    private tokenizedDetection(sourceCode: string, filePath: string): URLMatch[] {
+        const tokens = tokenize(sourceCode);
+        const urls = this.fallbackDetection(sourceCode, filePath);
+        for (const url of urls) {
+            url.sourceType = tokenKindAt(tokens, url.start);
+        }
+        return urls;
+    }
+
+    /**
+     * Lists the files process() would scan, after the scan and exclude patterns, with how each would be
+     * scanned, without detecting URLs. The same checks as in process() decide which files are skipped;
+     * files are only read for their first bytes, unless skipGenerated needs the whole content.
+     *
+     * @returns Listings sorted by path
+     *
+     * @example
+     * ```typescript
+     * const listings = await new URLDetector({ scan: ['src/**'], skipGenerated: true }).listFiles();
+     * listings.filter(listing => listing.mode === 'skip').forEach(listing => console.log(listing.file));
+     * ```
+     */
+    public async listFiles(): Promise<FileListing[]> {
+        const filePaths = await this.findFiles();
+        const limit = pLimit(this.options.concurrency || 10);
+
+        const listings = await Promise.all(
+            filePaths.map(filePath =>
+                limit(async (): Promise<FileListing> => {
+                    const file = normalizeFingerprintPath(filePath);
+                    const language = this.languageManager.detectLanguageFromPath(filePath);
+
+                    let admitted: AdmittedFile | Pick<SkippedFile, 'reason' | 'detail'>;
+                    try {
+                        admitted = await this.admitFile(filePath, false);
+                    } catch {
+                        return { file, language, mode: 'skip', reason: 'unreadable' };
+                    }
+                    if ('reason' in admitted) {
+                        return { file, language, mode: 'skip', reason: admitted.reason };
+                    }
+                    if (admitted.segmented) {
+                        return { file, language, mode: 'regex', reason: 'segmented' };
+                    }
+
+                    const mode = this.scanMode(language);
+                    return mode === 'skip'
+                        ? { file, language, mode, reason: 'unsupported-language' }
+                        : { file, language, mode };
+                }),
+            ),
+        );
+
+        return listings.sort((a, b) => a.file.localeCompare(b.file));
+    }
+
     // File finding and reading methods (moved from FileScanner)
-    private async findFiles(): Promise<string[]> {
+    private async findFiles(reportExcluded: boolean = false): Promise<string[]> {
         // Use fast-glob to find files matching patterns
         // Sanitize glob patterns to prevent path traversal
         const scanPatterns = sanitizeGlobPatterns(this.options.scan || ['**/*']);
@@ -482,6 +817,21 @@ export class URLDetector {
         // Set up the working directory
         const cwd = process.cwd();
 
+        // Entries of the directories the walk reads, to report what the exclude patterns left out
+        // without walking the excluded trees
+        const entries: ReadEntry[] = [];
+        const readdir = (directory: string, options: any, callback: any) => {
+            if (typeof options === 'function') return fs.readdir(directory, options);
+            fs.readdir(directory, options, (error: NodeJS.ErrnoException | null, dirents: any[]) => {
+                for (const dirent of error ? [] : dirents) {
+                    if (typeof dirent === 'string') continue;
+                    entries.push({ path: path.resolve(cwd, directory, dirent.name), directory: dirent.isDirectory() });
+                }
+                callback(error, dirents);
+            });
+        };
+        const observe = reportExcluded && excludePatterns.length > 0;
+
         try {
             const files = await fg(scanPatterns, {
                 cwd: cwd,
@@ -492,31 +842,348 @@ export class URLDetector {
                 suppressErrors: true,
                 absolute: false,
                 markDirectories: false,
+                fs: observe ? { readdir: readdir as unknown as typeof fs.readdir } : undefined,
             });
 
-            return files.map(file => path.resolve(cwd, file));
+            const filePaths = files.map(file => path.resolve(cwd, file));
+            if (observe) this.reportExcluded(entries, new Set(filePaths), scanPatterns, excludePatterns);
+            return filePaths;
         } catch (error: any) {
             throw new Error(`Failed to find files: ${error.message}`);
         }
     }
 
+    /**
+     * Records what the exclude patterns left out of a walk as ignored-by-pattern: one entry per
+     * directory the walk did not descend into, and one per pattern matching files it did read.
+     */
+    private reportExcluded(entries: ReadEntry[], found: Set<string>, scan: string[], exclude: string[]): void {
+        const excludedFiles = new Map<string, number>();
+        for (const entry of entries) {
+            const relative = normalizeFingerprintPath(entry.path);
+            if (found.has(entry.path) || path.basename(relative).startsWith('.')) continue;
+
+            if (entry.directory) {
+                // fast-glob prunes directories matched by exclude patterns ending in /**
+                const pattern = exclude.find(
+                    candidate =>
+                        candidate.endsWith('/**') && minimatch(relative, candidate.slice(0, -3), { dot: true }),
+                );
+                if (pattern) {
+                    const file = `${relative}/`;
+                    const detail = `excluded by ${pattern}`;
+                    this.skippedFiles.set(file, { file, reason: 'ignored-by-pattern', detail });
+                }
+            } else if (scan.some(pattern => minimatch(relative, pattern))) {
+                const pattern = exclude.find(candidate => minimatch(relative, candidate, { dot: true }));
+                if (pattern) excludedFiles.set(pattern, (excludedFiles.get(pattern) || 0) + 1);
+            }
+        }
+
+        for (const [pattern, files] of excludedFiles) {
+            this.skippedFiles.set(pattern, { file: pattern, reason: 'ignored-by-pattern', detail: `${files} file(s)` });
+        }
+    }
+
     private async processFile(filePath: string): Promise<FileResult | null> {
         try {
-            const content: string = await fs.promises.readFile(filePath, 'utf8');
-            const language = this.languageManager.detectLanguageFromPath(filePath);
-            const urls = await this.detectURLs(content, language, filePath);
-            const filteredUrls = this.urlFilter.filterUrls(urls);
-
-            return {
-                file: filePath,
-                urls: filteredUrls,
-            };
+            const admitted = await this.admitFile(filePath, true);
+            if ('reason' in admitted) {
+                this.skip(filePath, admitted.reason, admitted.detail);
+                return null;
+            }
+            const content = admitted.content.toString('utf8');
+            if (admitted.segmented) {
+                return await this.scanSegmentedFile(filePath, admitted.size, content, admitted.generated);
+            }
+            const result = await this.scanContent(content, filePath);
+            if (result && this.options.collapseDuplicates) {
+                this.contentHashes.set(filePath, contentHash(admitted.content));
+            }
+            return result;
         } catch (error: any) {
             this.logger.warn(`Failed to process file ${filePath}: ${error.message}`);
+            this.skip(filePath, 'unreadable', error.message);
             return null;
         }
     }
 
+    /**
+     * Decides whether a file is scanned, for processFile() and listFiles() alike: files over maxFileSize
+     * are skipped unread, then binary content, and generated content when skipGenerated is set. Files
+     * scanned in segments are only read for their first SEGMENTED_HEAD_BYTES.
+     *
+     * @param filePath The file
+     * @param readWhole Whether to read other files in full; otherwise their first bytes are read, unless
+     *     skipGenerated needs the whole content
+     * @returns The file with the content read, or why it is skipped
+     */
+    private async admitFile(
+        filePath: string,
+        readWhole: boolean,
+    ): Promise<AdmittedFile | Pick<SkippedFile, 'reason' | 'detail'>> {
+        const { size } = await fs.promises.stat(filePath);
+        if (this.isTooLarge(size)) {
+            return { reason: 'too-large', detail: `${size} bytes` };
+        }
+
+        const segmented = this.isSegmented(size);
+        let content: Buffer;
+        if (segmented) {
+            // Generated-file markers and license headers are at the top of the file
+            content = await readHead(filePath, SEGMENTED_HEAD_BYTES);
+        } else if (readWhole || this.options.skipGenerated) {
+            content = await fs.promises.readFile(filePath);
+        } else {
+            content = await readHead(filePath, BINARY_SNIFF_LENGTH);
+        }
+        if (isBinaryContent(content)) {
+            return { reason: 'binary' };
+        }
+
+        const generated = isGeneratedFile(filePath, content.toString('utf8'));
+        if (generated && this.options.skipGenerated) {
+            this.logger.debug(`Skipping generated file ${filePath}`);
+            return { reason: 'generated' };
+        }
+        return { size, segmented, content, generated };
+    }
+
+    /**
+     * How content of a language is scanned: parsed with its grammar, searched with regex detection when
+     * it has none and fallbackRegex is set, or not at all.
+     */
+    private scanMode(language: string): 'parse' | 'regex' | 'skip' {
+        if (this.languageManager.getLanguage(language)) return 'parse';
+        return this.options.fallbackRegex ? 'regex' : 'skip';
+    }
+
+    /**
+     * Records why a file was not scanned for URLs, for getSkippedFiles().
+     */
+    private skip(filePath: string, reason: SkipReason, detail?: string): void {
+        const file = normalizeFingerprintPath(filePath);
+        this.skippedFiles.set(file, detail ? { file, reason, detail } : { file, reason });
+    }
+
+    /**
+     * Whether a file exceeds maxFileSize.
+     */
+    private isTooLarge(size: number): boolean {
+        return this.options.maxFileSize > 0 && size > this.options.maxFileSize * 1024 * 1024;
+    }
+
+    /**
+     * Scans content that is already in memory with the full pipeline used by process(): detection,
+     * filtering, the opt-in detectors, classification, and rule evaluation. The path only has to
+     * exist on disk for features that read the file system or git (doc link validation, git blame).
+     *
+     * @param content Content to scan
+     * @param filePath Path the content belongs to, used for language detection and reporting
+     * @returns The findings, or null when the content is generated and skipGenerated is set
+     *
+     * @example
+     * ```typescript
+     * const result = await detector.scanContent('fetch("http://example.com");', 'src/app.js');
+     * ```
+     */
+    public async scanContent(content: string, filePath: string): Promise<FileResult | null> {
+        const generated = isGeneratedFile(filePath, content);
+        if (generated && this.options.skipGenerated) {
+            this.logger.debug(`Skipping generated file ${filePath}`);
+            return null;
+        }
+
+        const language = this.languageManager.detectLanguageFromPath(filePath);
+        const urls = await this.detectURLs(content, language, filePath);
+        let filteredUrls: URLMatch[];
+        if (this.options.licenseHeaders) {
+            // License headers are comments, so they bypass the comment filter
+            filteredUrls = selectLicenseHeaderUrls(urls, content);
+        } else {
+            filteredUrls = this.urlFilter.filterUrls(urls);
+        }
+        if (!this.options.licenseHeaders) {
+            const categorized = await this.detectCategorizedFindings(content, language, filePath);
+            filteredUrls = [...filteredUrls, ...categorized].sort((a, b) => a.start - b.start);
+        }
+        filteredUrls = dropSuppressed(filteredUrls, content);
+        await this.annotateFindings(filteredUrls, filePath, language, content, generated);
+
+        return {
+            file: filePath,
+            urls: filteredUrls,
+        };
+    }
+
+    /**
+     * Scans a compiled binary (ELF, PE, or Mach-O) for baked-in URLs, without its source. Printable
+     * strings are extracted as the `strings` tool does and searched with regex detection; each finding's
+     * line is the number of the string it was found in, and the 'offset' attribute holds its byte
+     * offset in the file. Other files are scanned the same way, with a warning. As in a scan, severity
+     * escalation, triage states, and the finding filter apply to the findings.
+     *
+     * @param filePath Path of the binary
+     * @param minLength Shortest string to extract (default: DEFAULT_MIN_STRING_LENGTH)
+     * @returns The findings
+     *
+     * @example
+     * ```typescript
+     * const result = await detector.scanBinary('dist/server');
+     * result.urls.forEach(finding => console.log(finding.attributes!.offset, finding.url));
+     * ```
+     */
+    public async scanBinary(filePath: string, minLength: number = DEFAULT_MIN_STRING_LENGTH): Promise<FileResult> {
+        const buffer = await fs.promises.readFile(filePath);
+        this.scannedFileCount++;
+        if (!detectExecutableFormat(buffer)) {
+            this.logger.warn(`${filePath} is not an ELF, PE, or Mach-O binary; scanning its strings anyway`);
+        }
+        if (isGoBinary(buffer)) {
+            // Go stores string literals back to back, without terminators
+            this.logger.info(`${filePath} is a Go binary; URLs may run into the string stored after them`);
+        }
+
+        const strings = extractStrings(buffer, minLength);
+        const result = this.scanPlainText(strings.map(string => string.text).join('\n'), filePath);
+        for (const finding of result.urls) {
+            const offset = stringByteOffset(strings[finding.line - 1], finding.column);
+            setFindingAttribute(finding, BINARY_OFFSET_ATTRIBUTE, `0x${offset.toString(16)}`);
+        }
+        const [finished] = await this.finishResults([result]);
+        return finished;
+    }
+
+    /**
+     * Whether a file is large enough to be scanned in segments.
+     */
+    private isSegmented(size: number): boolean {
+        return this.options.chunkSize > 0 && size > this.options.chunkSize * 1024 * 1024;
+    }
+
+    /**
+     * Scans a file larger than chunkSize in segments of chunkSize megabytes, read and searched
+     * concurrently and stitched back together, so the file is never held in memory whole. Segments
+     * are searched with regex detection, since no syntax tree can be built from part of a file; the
+     * opt-in detectors for categorized findings are skipped, and rules see empty file content.
+     */
+    private async scanSegmentedFile(
+        filePath: string,
+        size: number,
+        head: string,
+        generated: boolean,
+    ): Promise<FileResult> {
+        const segments = planSegments(size, this.options.chunkSize * 1024 * 1024);
+        const language = this.languageManager.detectLanguageFromPath(filePath);
+        const limit = pLimit(this.options.concurrency || 10);
+        const handle = await fs.promises.open(filePath, 'r');
+        let urls: URLMatch[];
+        try {
+            this.logger.debug(`Scanning ${filePath} in ${segments.length} segments`);
+            const scans = await Promise.all(
+                segments.map(segment =>
+                    limit(async () => {
+                        const buffer = await readSegment(handle, segment);
+                        return scanSegment(buffer, segment, this.urlPattern, url => !isSchemaIdentifier(url));
+                    }),
+                ),
+            );
+            urls = stitchSegments(scans, filePath);
+        } finally {
+            await handle.close();
+        }
+
+        const filteredUrls = this.options.licenseHeaders
+            ? selectLicenseHeaderUrls(urls, head)
+            : this.urlFilter.filterUrls(urls);
+        await this.annotateFindings(filteredUrls, filePath, language, '', generated);
+
+        return {
+            file: filePath,
+            urls: filteredUrls,
+        };
+    }
+
+    /**
+     * Classifies and tags the findings of a file, then evaluates the rules against them.
+     */
+    private async annotateFindings(
+        filteredUrls: URLMatch[],
+        filePath: string,
+        language: string,
+        content: string,
+        generated: boolean,
+    ): Promise<void> {
+        const scope = classifyCodeScope(filePath, language);
+        const owners = this.codeOwners ? this.codeOwners.ownersOf(normalizeFingerprintPath(filePath)) : [];
+        for (const urlObj of filteredUrls) {
+            if (scope === 'test') setFindingAttribute(urlObj, SCOPE_ATTRIBUTE, scope);
+            if (generated) setFindingAttribute(urlObj, GENERATED_ATTRIBUTE, 'true');
+            if (owners.length > 0) setFindingAttribute(urlObj, OWNER_ATTRIBUTE, owners.join(' '));
+            const feeds = this.hostData ? this.hostData.feedsOf(this.urlFilter.extractDomain(urlObj.url)) : [];
+            if (feeds.length > 0) setFindingAttribute(urlObj, FEED_ATTRIBUTE, feeds.join(' '));
+            classifyFalsePositive(urlObj, content, this.options.falsePositiveHeuristics);
+            classifyRealtimeEndpoint(urlObj, content);
+            // User-defined categories apply to plain URLs only
+            const host = this.urlFilter.extractDomain(urlObj.url);
+            const category = !urlObj.category && this.categoryRules.categoryOf(host);
+            if (category) urlObj.category = category;
+        }
+        if (this.options.goNetworkCalls && language === 'go') {
+            annotateNetworkCalls(filteredUrls, content);
+        }
+        if (this.options.gitBlame) {
+            await annotateIntroduced(filteredUrls, filePath).catch(error =>
+                this.logger.debug(`No git history for ${filePath}: ${error.message}`),
+            );
+        }
+        this.ruleEngine.evaluate(filteredUrls, { file: filePath, language, content, scope });
+    }
+
+    /**
+     * Runs the opt-in detectors for findings that are not absolute URLs: Go import paths, documentation
+     * links, relative URLs, and Windows paths. These bypass URL filtering and are reported in their own
+     * categories.
+     */
+    private async detectCategorizedFindings(content: string, language: string, filePath: string): Promise<URLMatch[]> {
+        const findings: URLMatch[] = [];
+
+        if (this.options.auditGoImports) {
+            if (language === 'go') {
+                findings.push(...extractGoImports(content));
+            } else if (path.basename(filePath) === 'go.mod') {
+                findings.push(...extractGoModPaths(content));
+            }
+        }
+
+        if (this.options.validateDocLinks && isDocumentationFile(filePath)) {
+            const docLinks = extractDocLinks(content, filePath);
+            await this.docLinkValidator.validate(docLinks, filePath);
+            findings.push(...docLinks);
+        }
+
+        if (this.options.includeRelativeUrls && RELATIVE_URL_LANGUAGES.includes(language)) {
+            // In HTML, documentation links and relative URLs overlap; the validated link wins
+            const taken = new Set(findings.map(finding => finding.start));
+            findings.push(...extractRelativeUrls(content, language).filter(finding => !taken.has(finding.start)));
+        }
+
+        if (this.options.includeWindowsPaths) {
+            findings.push(...extractWindowsPaths(content));
+        }
+
+        return assignFingerprints(findings, filePath, content);
+    }
+
+    private createUrlFilter(knownTlds?: Set<string>): URLFilter {
+        return new URLFilter({
+            ignoreDomains: this.options.ignoreDomains,
+            includeComments: this.options.includeComments,
+            includeNonFqdn: this.options.includeNonFqdn,
+            knownTlds,
+        });
+    }
+
     private getLineNumber(text: string, position: number): number {
         const beforePosition = text.substring(0, position);
         return beforePosition.split('\n').length;
@@ -547,8 +1214,20 @@ export class URLDetector {
      * 2. Process files concurrently (respecting options.concurrency limit)
      * 3. Detect URLs in each file using detectURLs()
      * 4. Apply URL filtering using the configured URLFilter
-     * 5. Format and output results
+     * 5. Evaluate registered rules against the remaining findings
+     * 6. Optionally scan git commit messages, tag annotations, and .gitmodules
+     * 7. Optionally check license header links for dead URLs
+     * 8. Apply path-based severity escalation to violations
+     * 9. Optionally carry triage states forward from the triage store
+     * 10. Format and output results
+     *
+     * Files that are not scanned for URLs are recorded with the reason, for getSkippedFiles().
+     *
+     * When the signal is aborted, files not yet started are skipped, files in progress are finished,
+     * and git metadata and license link checks are left out, so the findings collected so far can be
+     * written promptly. isPartial reports whether this happened.
      *
+     * @param signal Optional signal that interrupts the scan (e.g., on SIGINT)
      * @returns Promise resolving to array of FileResult objects containing detected URLs
      *
      * @example
@@ -564,24 +1243,302 @@ export class URLDetector {
      * });
      * ```
      */
-    public async process(): Promise<FileResult[]> {
-        const filePaths = await this.findFiles();
+    public async process(signal?: AbortSignal): Promise<FileResult[]> {
+        this.scannedFileCount = 0;
+        this.partial = false;
+        this.unparsedFiles.clear();
+        this.skippedFiles.clear();
+        this.contentHashes.clear();
+        this.sampleEstimate = null;
+        this.docLinkValidator.clearCache();
+        const foundPaths = await this.findFiles(true);
+
+        // Files left out of the sample are not reported as skipped; the estimate accounts for them
+        const { sample, sampleSeed } = this.options;
+        const filePaths = sample ? selectSample(foundPaths, parseSampleRate(sample), sampleSeed) : foundPaths;
+        if (sample) {
+            this.logger.info(`Sampling ${filePaths.length} of ${foundPaths.length} file(s) (${sample})`);
+        }
 
-        if (filePaths.length === 0) {
+        if (filePaths.length === 0 && !this.options.includeGitMetadata) {
             this.logger.info('No files found to process.');
             return [];
         }
 
+        await this.loadReferenceData();
+
         // Create concurrency limiter
         const limit = pLimit(this.options.concurrency || 10);
 
         // Process files concurrently with limit (read + detect URLs in one step)
-        const fileProcessPromises = filePaths.map(filePath => limit(() => this.processFile(filePath)));
+        const fileProcessPromises = filePaths.map(filePath =>
+            limit(() => {
+                if (signal && signal.aborted) {
+                    this.skip(filePath, 'interrupted');
+                    return Promise.resolve(null);
+                }
+                return this.processFile(filePath);
+            }),
+        );
 
         // Wait for all file processing to complete and filter out nulls (failed files)
         const allResults = await Promise.all(fileProcessPromises);
+        let results = allResults.filter((result): result is FileResult => result !== null);
+        this.scannedFileCount = results.length;
+        this.partial = !!signal && signal.aborted;
+
+        if (this.options.collapseDuplicates) {
+            const scanned = results.length;
+            results = collapseDuplicateFiles(results, this.contentHashes);
+            if (results.length < scanned) {
+                this.logger.info(`Collapsed the findings of ${scanned - results.length} duplicate file(s)`);
+            }
+        }
+
+        if (this.options.includeGitMetadata && !this.partial) {
+            results.push(...(await this.processGitMetadata()));
+        }
+
+        if (this.options.checkLicenseLinks && !this.partial) {
+            await checkLicenseLinks(results, undefined, this.httpClient, this.options.concurrency || 10);
+        }
+
+        const finished = await this.finishResults(results);
+        if (sample && !this.partial) {
+            this.sampleEstimate = estimateTotals(finished, filePaths, foundPaths.length, sample, sampleSeed);
+        }
+        return finished;
+    }
+
+    /**
+     * Scans the given files the way process() scans each file it finds: files over maxFileSize and
+     * binary files are skipped, files over chunkSize are scanned in segments, and a file that cannot be
+     * read, for example because it was deleted since it was listed, is skipped with a warning without
+     * affecting the others. Meant for rescanning changed files after process(), whose reference data it
+     * reuses; the scan and exclude patterns are not applied.
+     *
+     * @param filePaths Files to scan, relative to the working directory or absolute
+     * @returns Promise resolving to the findings of the scanned files
+     *
+     * @example
+     * ```typescript
+     * await detector.process();
+     * const results = await detector.processFiles(['src/app.ts']);
+     * ```
+     */
+    public async processFiles(filePaths: string[]): Promise<FileResult[]> {
+        this.partial = false;
+        this.unparsedFiles.clear();
+        this.skippedFiles.clear();
+        this.contentHashes.clear();
+        this.docLinkValidator.clearCache();
+
+        const limit = pLimit(this.options.concurrency || 10);
+        const allResults = await Promise.all(
+            filePaths.map(filePath => limit(() => this.processFile(path.resolve(process.cwd(), filePath)))),
+        );
         const results = allResults.filter((result): result is FileResult => result !== null);
+        this.scannedFileCount = results.length;
+        return this.finishResults(results);
+    }
+
+    /**
+     * Scans the lines a unified diff adds, e.g. a patch mailed for review, without the files being
+     * checked out. Each file's language is inferred from its path in the diff headers, the added and
+     * context lines are scanned at their line numbers in the new file, and only findings on added lines
+     * are kept, so positions are relative to the new file. Deleted files and binary diffs are skipped.
+     *
+     * @param patch Text of the unified diff (`git diff`, `git format-patch`, or `diff -u`)
+     * @returns Promise resolving to the findings per changed file
+     *
+     * @example
+     * ```typescript
+     * const results = await detector.scanPatch(fs.readFileSync('changes.diff', 'utf8'));
+     * ```
+     */
+    public async scanPatch(patch: string): Promise<FileResult[]> {
+        this.scannedFileCount = 0;
+        this.partial = false;
+        this.unparsedFiles.clear();
+        this.skippedFiles.clear();
+        await this.loadReferenceData();
+
+        const results: FileResult[] = [];
+        for (const file of parsePatch(patch)) {
+            if (file.added.size === 0) continue;
+            const result = await this.scanContent(patchedContent(file), path.resolve(process.cwd(), file.path));
+            this.scannedFileCount++;
+            if (!result) continue;
+            result.urls = result.urls.filter(finding => file.added.has(finding.line));
+            results.push(result);
+        }
+        return this.finishResults(results);
+    }
+
+    /**
+     * Scans an unbounded text stream, such as a log piped to stdin, line by line, and passes on the
+     * findings of each line as soon as it is read, so the detector can sit in a log pipeline. URLs are
+     * extracted as by extractUrls(), with trailing punctuation left out, then filtered and evaluated
+     * against the registered rules like other findings. Severity escalation and the finding filter
+     * apply; triage states do not, since the findings of a stream are not kept.
+     *
+     * At most one line is held in memory: longer lines are scanned in pieces of maxLineLength. Findings
+     * carry their line and column in the stream, and start and end are character offsets into it.
+     *
+     * @param input The stream
+     * @param onFindings Called with the findings of each line that has any, awaited before reading on
+     * @param options Source name, line length limit, and abort signal
+     * @returns Promise resolving to the lines read and findings passed on once the stream ends
+     *
+     * @example
+     * ```typescript
+     * await detector.scanStream(process.stdin, result => {
+     *     result.urls.forEach(finding => console.log(finding.url));
+     * });
+     * ```
+     */
+    public async scanStream(
+        input: AsyncIterable<string | Buffer>,
+        onFindings: (result: FileResult) => void | Promise<void>,
+        options: StreamScanOptions = {},
+    ): Promise<StreamScanSummary> {
+        const source = options.source || STREAM_SOURCE;
+        const signal = options.signal;
+        this.partial = false;
+        await this.loadReferenceData();
+
+        const stop = () => {
+            if (input instanceof Readable) input.destroy();
+        };
+        signal?.addEventListener('abort', stop, { once: true });
+
+        const summary: StreamScanSummary = { lines: 0, urls: 0 };
+        try {
+            for await (const line of readStreamLines(input, options.maxLineLength)) {
+                summary.lines = line.line;
+                const urls = this.urlFilter.filterUrls(extractUrls(line.text, { includeNonFqdn: true }));
+                if (urls.length === 0) continue;
+
+                for (const urlObj of urls) {
+                    urlObj.column += line.column - 1;
+                    urlObj.line = line.line;
+                    urlObj.start += line.offset;
+                    urlObj.end += line.offset;
+                }
+                assignLineFingerprints(urls, source, urls.map(() => line.text));
+                this.ruleEngine.evaluate(urls, { file: source, language: 'text', content: line.text });
+
+                let results: FileResult[] = [{ file: source, urls }];
+                applySeverityEscalation(results, this.options.severityEscalation);
+                if (this.findingFilter) results = applyFilter(results, this.findingFilter);
+                if (results[0].urls.length === 0) continue;
+
+                summary.urls += results[0].urls.length;
+                await onFindings(results[0]);
+                if (signal?.aborted) break;
+            }
+        } catch (error: unknown) {
+            // Destroying the input on abort ends a pending read with an error
+            if (!signal?.aborted) throw error;
+        } finally {
+            signal?.removeEventListener('abort', stop);
+        }
 
-        return results;
+        this.partial = !!signal?.aborted;
+        return summary;
+    }
+
+    /**
+     * Loads the owners, data bundle, and API and deprecation data the options refer to.
+     */
+    private async loadReferenceData(): Promise<void> {
+        if (this.options.codeOwners) {
+            this.codeOwners = await CodeOwners.load(process.cwd());
+            if (!this.codeOwners) {
+                this.logger.warn('No CODEOWNERS file found; findings will not carry owners');
+            }
+        }
+
+        if (this.options.dataBundle) {
+            this.hostData = await HostData.load(this.options.dataBundle);
+            this.urlFilter = this.createUrlFilter(this.hostData.knownTlds);
+        }
+
+        if (this.options.openApiSpecs.length > 0) {
+            this.ruleEngine.register(createOpenApiRule(await ApiCatalog.load(this.options.openApiSpecs)));
+        }
+
+        if (this.options.deprecationRegistry) {
+            const registry = await DeprecationRegistry.load(this.options.deprecationRegistry);
+            this.ruleEngine.register(createDeprecationRule(registry));
+        }
+    }
+
+    /**
+     * Applies severity escalation, triage states, and the finding filter to scan results.
+     */
+    private async finishResults(results: FileResult[]): Promise<FileResult[]> {
+        applySeverityEscalation(results, this.options.severityEscalation);
+
+        if (this.options.triageStore) {
+            const store = new TriageStore(this.options.triageStore);
+            const summary = await applyTriage(results, store, this.options.hideTriaged);
+            for (const fingerprint of summary.reopened) {
+                this.logger.warn(`Finding ${fingerprint} was marked fixed but was found again; reopened`);
+            }
+        }
+
+        return this.findingFilter ? applyFilter(results, this.findingFilter) : results;
+    }
+
+    /**
+     * Scans commit messages, annotated tag messages, and .gitmodules of the repository containing
+     * the working directory. Only sources that contain URLs are returned, so long histories do not
+     * flood the results with empty entries.
+     */
+    private async processGitMetadata(): Promise<FileResult[]> {
+        let sources: GitMetadataSource[];
+        try {
+            sources = await collectGitMetadata(process.cwd(), this.options.gitHistoryDepth);
+        } catch (error: any) {
+            this.logger.warn(`Failed to read git metadata: ${error.message}`);
+            return [];
+        }
+
+        return sources
+            .map(source => this.scanPlainText(source.content, source.file))
+            .filter(result => result.urls.length > 0);
+    }
+
+    /**
+     * Scans text with regex detection. Callers pass the results through finishResults.
+     */
+    private scanPlainText(content: string, filePath: string): FileResult {
+        const urls = assignFingerprints(this.fallbackDetection(content, filePath), filePath, content);
+        const filteredUrls = this.urlFilter.filterUrls(urls);
+        this.ruleEngine.evaluate(filteredUrls, { file: filePath, language: 'text', content });
+
+        return {
+            file: filePath,
+            urls: filteredUrls,
+        };
+    }
+}
+
+/**
+ * Reads the first bytes of a file.
+ *
+ * @param filePath The file
+ * @param length Most bytes to read
+ * @returns The bytes read, fewer for a shorter file
+ */
+async function readHead(filePath: string, length: number): Promise<Buffer> {
+    const handle = await fs.promises.open(filePath, 'r');
+    try {
+        const buffer = Buffer.alloc(length);
+        const { bytesRead } = await handle.read(buffer, 0, length, 0);
+        return buffer.subarray(0, bytesRead);
+    } finally {
+        await handle.close();
     }
 }
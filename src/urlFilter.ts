@@ -13,6 +13,8 @@
  */
 
 import { minimatch } from 'minimatch';
+import { hostForms } from './idn';
+import { Violation } from './ruleEngine';
 
 /**
  * Represents a URL found in source code with its location and context information.
@@ -32,6 +34,25 @@ export interface URLMatch {
     sourceType: 'string' | 'comment' | 'unknown';
     /** Additional context lines around the URL for better understanding */
     context?: string[];
+    /** Kind of finding when it is not a plain URL (e.g., 'go-import' for Go import paths) */
+    category?: string;
+    /** Policy violations raised by registered rules */
+    violations?: Violation[];
+    /** Open key/value metadata populated by classifiers, enrichers, and plugins */
+    attributes?: Record<string, string>;
+    /** Stable identifier that survives line renumbering, used by baselines, suppressions, and diffs */
+    fingerprint?: string;
+}
+
+/**
+ * Sets a metadata attribute on a finding, creating the attributes map if needed.
+ *
+ * @param finding The finding to annotate
+ * @param key Attribute name (e.g., 'owner')
+ * @param value Attribute value
+ */
+export function setFindingAttribute(finding: URLMatch, key: string, value: string): void {
+    finding.attributes = { ...(finding.attributes || {}), [key]: value };
 }
 
 /**
@@ -44,6 +65,8 @@ export interface URLFilterOptions {
     includeComments?: boolean;
     /** Whether to include non-fully qualified domain names like 'localhost' (default: false) */
     includeNonFqdn?: boolean;
+    /** Top-level domains a fully qualified domain must end in; any TLD of 2+ characters is accepted when omitted */
+    knownTlds?: Set<string>;
 }
 
 /**
@@ -122,7 +145,18 @@ export class URLFilter {
         return filtered;
     }
 
-    private extractDomain(url: string): string {
+    /**
+     * Extracts the lowercase hostname of a URL, including protocol-relative and malformed URLs and
+     * gRPC targets.
+     *
+     * @param url The URL
+     * @returns The hostname, or an empty string when none can be found
+     */
+    public extractDomain(url: string): string {
+        // gRPC targets name the host without an authority (api.example.com:443, dns:///api.example.com:443)
+        const grpcTarget = url.match(/^(?:dns:(?:\/\/[^/]*)?\/)?(?:\[([0-9a-f:]+)\]|([a-z0-9.-]+)):\d+$/i);
+        if (grpcTarget) return (grpcTarget[1] || grpcTarget[2]).toLowerCase();
+
         try {
             // Handle protocol-relative URLs by prepending https:
             const urlToParse = url.startsWith('//') ? 'https:' + url : url;
@@ -140,10 +174,14 @@ export class URLFilter {
     }
 
     private matchesAnyPattern(value: string, patterns: string[]): boolean {
-        return patterns.some(pattern => {
-            // Support both exact matches and glob patterns
-            return value === pattern || minimatch(value, pattern);
-        });
+        // Internationalized hosts match in either their Unicode or their punycode form
+        const values = hostForms(value);
+        return patterns.some(pattern =>
+            hostForms(pattern).some(form =>
+                // Support both exact matches and glob patterns
+                values.some(candidate => candidate === form || minimatch(candidate, form)),
+            ),
+        );
     }
 
     private isFqdn(domain: string): boolean {
@@ -158,8 +196,10 @@ export class URLFilter {
         // Must not be a single level domain (like localhost, server, db)
         const parts = domain.split('.');
 
-        // At least 2 parts and the last part should be a valid TLD (at least 2 chars)
-        return parts.length >= 2 && parts[parts.length - 1].length >= 2;
+        // At least 2 parts and the last part should be a valid TLD (at least 2 chars, or a known one)
+        const tld = parts[parts.length - 1];
+        const validTld = this.options.knownTlds ? this.options.knownTlds.has(tld) : tld.length >= 2;
+        return parts.length >= 2 && validTld;
     }
 
     private isIPAddress(domain: string): boolean {
@@ -0,0 +1,251 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import { Logger, NullLogger } from './logger';
+
+/** Default quiet period, in milliseconds, after the last change before a batch is rescanned */
+export const DEFAULT_WATCH_DEBOUNCE_MS = 300;
+
+/** Default longest time, in milliseconds, a change waits while changes keep arriving */
+export const DEFAULT_WATCH_MAX_WAIT_MS = 5000;
+
+/** Default number of changes per second in one directory before the directory is rescanned as a whole */
+export const DEFAULT_DIRECTORY_EVENT_RATE = 100;
+
+/** Window, in milliseconds, over which the changes in a directory are counted against the rate */
+const RATE_WINDOW_MS = 1000;
+
+/**
+ * Changes to rescan together.
+ */
+export interface WatchBatch {
+    /** Changed files, relative to the watched directory with forward slashes, sorted */
+    files: string[];
+    /** Directories changing faster than the rate, to be rescanned as a whole, sorted ('.' for the root) */
+    directories: string[];
+}
+
+/**
+ * Backpressure settings for ChangeBatcher.
+ */
+export interface ChangeBatcherOptions {
+    /** Quiet period, in milliseconds, after the last change before the batch runs (default: 300) */
+    debounce?: number;
+    /** Longest time, in milliseconds, the first change of a batch waits while changes keep arriving (default: 5000) */
+    maxWait?: number;
+    /** Changes per second in one directory before its files are coalesced into the directory (default: 100) */
+    directoryEventRate?: number;
+}
+
+/**
+ * Turns a stream of file change events into batches, so a `git checkout` touching thousands of files
+ * causes a handful of rescans instead of thousands:
+ * - debouncing: a batch runs once no change has arrived for `debounce` ms, or `maxWait` ms after its
+ *   first change at the latest
+ * - coalescing: repeated changes to a file count once, and a directory with more than
+ *   `directoryEventRate` changes within a second is reported in place of its files
+ * - backpressure: one batch runs at a time; changes arriving meanwhile form the next batch
+ */
+export class ChangeBatcher {
+    private onBatch: (batch: WatchBatch) => Promise<void>;
+    private logger: Logger;
+    private debounce: number;
+    private maxWait: number;
+    private directoryEventRate: number;
+    private files = new Set<string>();
+    private directories = new Set<string>();
+    private directoryEvents = new Map<string, { start: number; count: number }>();
+    private debounceTimer: NodeJS.Timeout | null = null;
+    private maxWaitTimer: NodeJS.Timeout | null = null;
+    private running: Promise<void> | null = null;
+    private deferred = false;
+    private closed = false;
+
+    /**
+     * Creates a new ChangeBatcher.
+     *
+     * @param onBatch Rescans a batch; errors are logged and do not stop later batches
+     * @param options Backpressure settings
+     * @param logger Optional logger for failed batches
+     * @throws {Error} When a setting is negative, or the directory event rate is below 1
+     */
+    constructor(
+        onBatch: (batch: WatchBatch) => Promise<void>,
+        options: ChangeBatcherOptions = {},
+        logger: Logger = NullLogger,
+    ) {
+        this.onBatch = onBatch;
+        this.logger = logger;
+        this.debounce = options.debounce ?? DEFAULT_WATCH_DEBOUNCE_MS;
+        this.maxWait = options.maxWait ?? DEFAULT_WATCH_MAX_WAIT_MS;
+        this.directoryEventRate = options.directoryEventRate ?? DEFAULT_DIRECTORY_EVENT_RATE;
+        if (this.debounce < 0 || this.maxWait < 0) {
+            throw new Error('Watch debounce and max wait must be 0 or more milliseconds');
+        }
+        if (this.directoryEventRate < 1) {
+            throw new Error('Watch directory event rate must be at least 1');
+        }
+    }
+
+    /** Number of files and directories waiting for the next batch */
+    public get pending(): number {
+        return this.files.size + this.directories.size;
+    }
+
+    /**
+     * Records a change to a file.
+     *
+     * @param file Path of the changed file, relative to the watched directory
+     */
+    public add(file: string): void {
+        if (this.closed) return;
+        const normalized = file.split(path.sep).join('/').replace(/^\.\//, '');
+        const directory = path.posix.dirname(normalized);
+
+        if (!this.isCoalesced(directory)) {
+            if (this.countEvent(directory) > this.directoryEventRate) {
+                this.coalesce(directory);
+            } else {
+                this.files.add(normalized);
+            }
+        }
+
+        this.schedule();
+    }
+
+    /**
+     * Runs the pending batch now, or after the running batch when one is running.
+     *
+     * @returns Resolves when the batch has run
+     */
+    public async flush(): Promise<void> {
+        this.clearTimers();
+        if (this.running) {
+            this.deferred = true;
+            return this.running;
+        }
+        if (this.pending === 0) return;
+
+        const batch: WatchBatch = {
+            files: Array.from(this.files).sort(),
+            directories: Array.from(this.directories).sort(),
+        };
+        this.files.clear();
+        this.directories.clear();
+        // Rates carry over into the next batch until their window ends
+        const now = Date.now();
+        for (const [directory, window] of this.directoryEvents) {
+            if (now - window.start >= RATE_WINDOW_MS) this.directoryEvents.delete(directory);
+        }
+
+        this.running = this.onBatch(batch)
+            .catch((error: unknown) => {
+                const errorMessage = error instanceof Error ? error.message : String(error);
+                this.logger.error(`Rescan failed: ${errorMessage}`);
+            })
+            .finally(() => {
+                this.running = null;
+                if (this.deferred && !this.closed) {
+                    this.deferred = false;
+                    this.flush();
+                }
+            });
+        return this.running;
+    }
+
+    /**
+     * Stops batching. Pending changes are dropped; a running batch is waited for.
+     */
+    public async close(): Promise<void> {
+        this.closed = true;
+        this.clearTimers();
+        this.files.clear();
+        this.directories.clear();
+        await this.running;
+    }
+
+    private schedule(): void {
+        if (this.debounceTimer) clearTimeout(this.debounceTimer);
+        this.debounceTimer = setTimeout(() => this.flush(), this.debounce);
+        if (!this.maxWaitTimer) {
+            this.maxWaitTimer = setTimeout(() => this.flush(), this.maxWait);
+        }
+    }
+
+    private clearTimers(): void {
+        if (this.debounceTimer) clearTimeout(this.debounceTimer);
+        if (this.maxWaitTimer) clearTimeout(this.maxWaitTimer);
+        this.debounceTimer = null;
+        this.maxWaitTimer = null;
+    }
+
+    /** Counts a change in a directory, returning the changes it had in the current one-second window */
+    private countEvent(directory: string): number {
+        const now = Date.now();
+        let window = this.directoryEvents.get(directory);
+        if (!window || now - window.start >= RATE_WINDOW_MS) {
+            window = { start: now, count: 0 };
+            this.directoryEvents.set(directory, window);
+        }
+        return ++window.count;
+    }
+
+    /** Whether changes in a directory are covered by a coalesced directory (itself or an ancestor) */
+    private isCoalesced(directory: string): boolean {
+        for (const coalesced of this.directories) {
+            if (coalesced === '.' || directory === coalesced || directory.startsWith(`${coalesced}/`)) return true;
+        }
+        return false;
+    }
+
+    /** Replaces the pending files and directories under a directory by the directory itself */
+    private coalesce(directory: string): void {
+        const covers = (file: string) => directory === '.' || file.startsWith(`${directory}/`);
+        for (const file of this.files) {
+            if (covers(file)) this.files.delete(file);
+        }
+        for (const nested of this.directories) {
+            if (covers(nested)) this.directories.delete(nested);
+        }
+        this.directories.add(directory);
+    }
+}
+
+/**
+ * Creates a test for whether a file is part of a batch: listed itself, or inside one of its directories.
+ *
+ * @param batch The batch
+ * @returns Whether a file, relative to the watched directory with forward slashes, should be rescanned
+ */
+export function createBatchFilter(batch: WatchBatch): (file: string) => boolean {
+    const files = new Set(batch.files);
+    return file =>
+        files.has(file) || batch.directories.some(directory => directory === '.' || file.startsWith(`${directory}/`));
+}
+
+/**
+ * Watches a directory tree and feeds every change to a batcher. Uses recursive fs.watch, which
+ * Node.js supports on Linux, macOS, and Windows.
+ *
+ * @param root Directory to watch
+ * @param batcher Batcher receiving the changes, relative to root
+ * @returns The watcher, to close when done
+ */
+export function watchTree(root: string, batcher: ChangeBatcher): fs.FSWatcher {
+    return fs.watch(root, { recursive: true }, (_event, filename) => {
+        if (filename) batcher.add(filename.toString());
+    });
+}
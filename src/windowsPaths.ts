@@ -0,0 +1,87 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { URLMatch, setFindingAttribute } from './urlFilter';
+
+/** Category assigned to UNC paths and file URLs with drive letters */
+export const WINDOWS_PATH_CATEGORY = 'windows-path';
+
+/** Attribute holding the share of a UNC path (`\\server\share`) */
+export const SHARE_ATTRIBUTE = 'share';
+
+/** What a Windows path points at */
+export type WindowsPathKind = 'unc' | 'file-url';
+
+/**
+ * UNC paths, raw (`\\server\share\dir`) or with escaped backslashes as in string literals
+ * (`\\\\server\\share\\dir`). Group 1 is the separator, group 2 the server, group 3 the share, and
+ * group 4 the rest of the path.
+ */
+const UNC_PATH = /(?<![\\\w])(\\{1,2})\1(\w[\w.-]*)\1([^\\/\s"'`<>|*?:;,]+)((?:\1[^\\/\s"'`<>|*?:;,]+)*)/g;
+
+/** file: URLs with a drive letter (`file:///C:/dir`, `file://c|/dir`); group 1 is the drive letter */
+const DRIVE_FILE_URL = /\bfile:\/{2,3}([a-zA-Z])[:|](?:[/\\][^\s"'`<>)]*)?/gi;
+
+/**
+ * Detects UNC paths and file URLs with drive letters, which Windows-heavy codebases use to reference
+ * internal shares. UNC paths are reported with single backslashes whatever the escaping in the source.
+ *
+ * @param sourceCode Source code to scan
+ * @returns Findings in the 'windows-path' category, in source order
+ */
+export function extractWindowsPaths(sourceCode: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+
+    for (const match of sourceCode.matchAll(UNC_PATH)) {
+        const separator = match[1];
+        const text = trimTrailingPunctuation(match[0]);
+        const path = text.substring(separator.length * 2).split(separator).join('\\');
+        const finding = createFinding(sourceCode, `\\\\${path}`, match.index!, text.length, 'unc');
+        setFindingAttribute(finding, SHARE_ATTRIBUTE, `\\\\${match[2]}\\${match[3]}`);
+        findings.push(finding);
+    }
+
+    for (const match of sourceCode.matchAll(DRIVE_FILE_URL)) {
+        const text = trimTrailingPunctuation(match[0]);
+        findings.push(createFinding(sourceCode, text, match.index!, text.length, 'file-url'));
+    }
+
+    return findings.sort((a, b) => a.start - b.start);
+}
+
+/** Sentence punctuation after a path in comments and documentation is not part of it */
+function trimTrailingPunctuation(text: string): string {
+    return text.replace(/[.)\]]+$/, '');
+}
+
+function createFinding(
+    sourceCode: string,
+    url: string,
+    start: number,
+    length: number,
+    kind: WindowsPathKind,
+): URLMatch {
+    const lines = sourceCode.substring(0, start).split('\n');
+    const finding: URLMatch = {
+        url,
+        start,
+        end: start + length,
+        line: lines.length,
+        column: lines[lines.length - 1].length + 1,
+        sourceType: 'string',
+        category: WINDOWS_PATH_CATEGORY,
+    };
+    setFindingAttribute(finding, 'pathKind', kind);
+    return finding;
+}
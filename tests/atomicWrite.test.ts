@@ -0,0 +1,58 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { writeAtomically } from '../src/atomicWrite';
+
+describe('writeAtomically', () => {
+    let dir: string;
+
+    beforeEach(() => {
+        dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-atomic-'));
+    });
+
+    afterEach(() => {
+        fs.rmSync(dir, { recursive: true, force: true });
+    });
+
+    test('should create the directory and leave no temporary file behind', async () => {
+        const filePath = path.join(dir, 'nested', 'store.json');
+
+        await writeAtomically(filePath, '{"a":1}');
+
+        expect(fs.readFileSync(filePath, 'utf8')).toBe('{"a":1}');
+        expect(fs.readdirSync(path.dirname(filePath))).toEqual(['store.json']);
+    });
+
+    test('should replace existing content', async () => {
+        const filePath = path.join(dir, 'bundle.bin');
+        fs.writeFileSync(filePath, 'old content');
+
+        await writeAtomically(filePath, Buffer.from([1, 2, 3]));
+
+        expect(fs.readFileSync(filePath)).toEqual(Buffer.from([1, 2, 3]));
+    });
+
+    test('should keep concurrent writes of one file from mixing', async () => {
+        const filePath = path.join(dir, 'trends.json');
+        const contents = ['a', 'b', 'c', 'd'].map(letter => letter.repeat(256 * 1024));
+
+        await Promise.all(contents.map(content => writeAtomically(filePath, content)));
+
+        expect(contents).toContain(fs.readFileSync(filePath, 'utf8'));
+        expect(fs.readdirSync(dir)).toEqual(['trends.json']);
+    });
+});
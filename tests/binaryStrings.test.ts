@@ -0,0 +1,67 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { detectExecutableFormat, extractStrings, isGoBinary, stringByteOffset } from '../src/binaryStrings';
+
+function peHeader(): Buffer {
+    const buffer = Buffer.alloc(0x100);
+    buffer.write('MZ', 0, 'latin1');
+    buffer.writeUInt32LE(0x80, 0x3c);
+    buffer.write('PE\0\0', 0x80, 'latin1');
+    return buffer;
+}
+
+describe('detectExecutableFormat', () => {
+    test('should recognize ELF, PE, and Mach-O magic numbers', () => {
+        expect(detectExecutableFormat(Buffer.from('\x7fELF\x02\x01\x01\x00', 'latin1'))).toBe('elf');
+        expect(detectExecutableFormat(peHeader())).toBe('pe');
+        expect(detectExecutableFormat(Buffer.from([0xcf, 0xfa, 0xed, 0xfe, 7, 0, 0, 1]))).toBe('mach-o');
+        expect(detectExecutableFormat(Buffer.from([0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 2]))).toBe('mach-o');
+    });
+
+    test('should not mistake other files for executables', () => {
+        expect(detectExecutableFormat(Buffer.from([0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 52]))).toBeNull();
+        expect(detectExecutableFormat(Buffer.from('MZ plain text that is long enough'.padEnd(0x100)))).toBeNull();
+        expect(detectExecutableFormat(Buffer.from('#!/bin/sh\necho hi\n'))).toBeNull();
+        expect(detectExecutableFormat(Buffer.from('\x7fEL'))).toBeNull();
+    });
+
+    test('should recognize Go binaries by their build information', () => {
+        const goBinary = Buffer.concat([Buffer.from('\x7fELF'), Buffer.from('\xff Go buildinf:', 'latin1')]);
+        expect(isGoBinary(goBinary)).toBe(true);
+        expect(isGoBinary(Buffer.from('\x7fELF'))).toBe(false);
+    });
+});
+
+describe('extractStrings', () => {
+    test('should extract ASCII and UTF-16LE runs of the minimum length in offset order', () => {
+        const buffer = Buffer.concat([
+            Buffer.from('\x00\x01short\x00a longer string\x00', 'latin1'),
+            Buffer.from('wide text', 'utf16le'),
+            Buffer.from('\x00\x00\x7ftab\there ok\xff', 'latin1'),
+        ]);
+
+        expect(extractStrings(buffer, 8)).toEqual([
+            { text: 'a longer string', offset: 8, width: 1 },
+            { text: 'wide text', offset: 24, width: 2 },
+            { text: 'tab\there ok', offset: 45, width: 1 },
+        ]);
+        expect(extractStrings(buffer, 5).map(string => string.text)).toContain('short');
+    });
+
+    test('should map string columns to byte offsets', () => {
+        expect(stringByteOffset({ text: 'abc', offset: 16, width: 1 }, 3)).toBe(18);
+        expect(stringByteOffset({ text: 'abc', offset: 16, width: 2 }, 3)).toBe(20);
+    });
+});
@@ -0,0 +1,187 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { CACHE_LOCK_FILE, CACHE_TAG_FILE, CacheDirectory, STALE_LOCK_MS, resolveCacheDir } from '../src/cacheDir';
+
+describe('resolveCacheDir', () => {
+    test('should prefer the override, then the environment variable, then XDG_CACHE_HOME', () => {
+        const env = { URL_DETECTOR_CACHE_DIR: '/env/cache', XDG_CACHE_HOME: '/xdg', HOME: '/home/dev' };
+
+        expect(resolveCacheDir('/flag/cache', env, 'linux')).toBe(path.resolve('/flag/cache'));
+        expect(resolveCacheDir(undefined, env, 'linux')).toBe(path.resolve('/env/cache'));
+        expect(resolveCacheDir(undefined, { XDG_CACHE_HOME: '/xdg', HOME: '/home/dev' }, 'linux')).toBe(
+            path.join('/xdg', 'url-detector'),
+        );
+    });
+
+    test('should fall back to the platform cache location', () => {
+        expect(resolveCacheDir(undefined, { HOME: '/home/dev' }, 'linux')).toBe(
+            path.join('/home/dev', '.cache', 'url-detector'),
+        );
+        expect(resolveCacheDir(undefined, { HOME: '/Users/dev' }, 'darwin')).toBe(
+            path.join('/Users/dev', 'Library', 'Caches', 'url-detector'),
+        );
+        expect(resolveCacheDir(undefined, { LOCALAPPDATA: 'C:\\Users\\dev\\AppData\\Local' }, 'win32')).toBe(
+            path.join('C:\\Users\\dev\\AppData\\Local', 'url-detector', 'Cache'),
+        );
+    });
+
+    test('should ignore a relative XDG_CACHE_HOME', () => {
+        expect(resolveCacheDir(undefined, { XDG_CACHE_HOME: 'cache', HOME: '/home/dev' }, 'linux')).toBe(
+            path.join('/home/dev', '.cache', 'url-detector'),
+        );
+    });
+});
+
+describe('CacheDirectory', () => {
+    let root: string;
+    let cache: CacheDirectory;
+
+    beforeEach(() => {
+        root = path.join(fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-cache-')), 'cache');
+        cache = new CacheDirectory(root);
+    });
+
+    afterEach(() => {
+        jest.restoreAllMocks();
+        fs.rmSync(path.dirname(root), { recursive: true, force: true });
+    });
+
+    test('should write entries and list them with their sizes', async () => {
+        await cache.writeFile('data.json', '{"a":1}');
+        await cache.writeFile(path.join('links', 'results.json'), '[]');
+
+        const info = await cache.info();
+
+        expect(info.root).toBe(root);
+        expect(info.exists).toBe(true);
+        expect(info.entries.map(entry => [entry.name, entry.size])).toEqual([
+            ['data.json', 7],
+            ['links', 2],
+        ]);
+        expect(info.totalSize).toBe(9);
+        expect(fs.readdirSync(root).sort()).toEqual([CACHE_TAG_FILE, 'data.json', 'links']);
+    });
+
+    test('should report a missing directory as empty', async () => {
+        expect(await cache.info()).toEqual({ root, exists: false, entries: [], totalSize: 0 });
+    });
+
+    test('should clear every entry', async () => {
+        await cache.writeFile('data.json', '{}');
+        await cache.writeFile(path.join('links', 'results.json'), '[]');
+
+        expect(await cache.clear()).toBe(2);
+        expect(fs.readdirSync(root)).toEqual([CACHE_TAG_FILE]);
+    });
+
+    test('should refuse to clear a directory it did not create', async () => {
+        fs.mkdirSync(root, { recursive: true });
+        fs.writeFileSync(path.join(root, 'notes.txt'), 'keep me');
+        await cache.writeFile('data.json', '{}');
+
+        await expect(cache.clear()).rejects.toThrow(`it has no ${CACHE_TAG_FILE}`);
+        expect(fs.readdirSync(root).sort()).toEqual(['data.json', 'notes.txt']);
+    });
+
+    test('should serialize concurrent writers', async () => {
+        const order: string[] = [];
+        const run = (name: string) =>
+            cache.withLock(async () => {
+                order.push(`${name} start`);
+                await new Promise(resolve => setTimeout(resolve, 20));
+                order.push(`${name} end`);
+            });
+
+        await Promise.all([run('a'), run('b'), run('c')]);
+
+        for (let i = 0; i < order.length; i += 2) {
+            expect(order[i + 1]).toBe(order[i].replace('start', 'end'));
+        }
+        expect(fs.existsSync(path.join(root, CACHE_LOCK_FILE))).toBe(false);
+    });
+
+    test('should take over a lock left by a process that is gone', async () => {
+        fs.mkdirSync(root, { recursive: true });
+        // Pids are far below this on every platform
+        fs.writeFileSync(path.join(root, CACHE_LOCK_FILE), `2147483646 ${os.hostname()} token\n`);
+
+        await cache.writeFile('data.json', '{}');
+
+        expect(fs.readFileSync(path.join(root, 'data.json'), 'utf8')).toBe('{}');
+    });
+
+    test('should time out on a lock held by a running process', async () => {
+        fs.mkdirSync(root, { recursive: true });
+        fs.writeFileSync(path.join(root, CACHE_LOCK_FILE), `${process.pid} ${os.hostname()} token\n`);
+
+        const locked = cache.withLock(async () => undefined, 100);
+
+        await expect(locked).rejects.toThrow('Timed out waiting for the cache lock');
+    });
+
+    test('should judge locks from other hosts by their age alone', async () => {
+        const lockFile = path.join(root, CACHE_LOCK_FILE);
+        fs.mkdirSync(root, { recursive: true });
+        fs.writeFileSync(lockFile, `${process.pid} build-agent-7 token\n`);
+        const fresh = cache.withLock(async () => undefined, 100);
+        await expect(fresh).rejects.toThrow('Timed out waiting for the cache lock');
+
+        const old = new Date(Date.now() - STALE_LOCK_MS - 1000);
+        fs.utimesSync(lockFile, old, old);
+        await cache.writeFile('data.json', '{}');
+
+        expect(fs.readFileSync(path.join(root, 'data.json'), 'utf8')).toBe('{}');
+    });
+
+    test('should let only one waiter take over a stale lock at a time', async () => {
+        const lockFile = path.join(root, CACHE_LOCK_FILE);
+        fs.mkdirSync(root, { recursive: true });
+        fs.writeFileSync(lockFile, `2147483646 ${os.hostname()} token\n`);
+        let holders = 0;
+        let overlapped = false;
+        const run = () =>
+            cache.withLock(async () => {
+                overlapped = overlapped || ++holders > 1;
+                await new Promise(resolve => setTimeout(resolve, 20));
+                holders--;
+            });
+
+        await Promise.all([run(), run(), run(), run()]);
+
+        expect(overlapped).toBe(false);
+        expect(fs.readdirSync(root)).toEqual([]);
+    });
+
+    test('should never remove a lock taken after the stale one was found', async () => {
+        const lockFile = path.join(root, CACHE_LOCK_FILE);
+        fs.mkdirSync(root, { recursive: true });
+        fs.writeFileSync(lockFile, `2147483646 ${os.hostname()} token\n`);
+        const live = `${process.pid} ${os.hostname()} live\n`;
+        // Another process takes the lock while this one is about to remove the stale one
+        const stat = fs.promises.stat;
+        jest.spyOn(fs.promises, 'stat').mockImplementationOnce(async file => {
+            const stats = await stat(file);
+            fs.rmSync(lockFile);
+            fs.writeFileSync(lockFile, live);
+            return stats;
+        });
+
+        await expect(cache.withLock(async () => undefined, 100)).rejects.toThrow('Timed out waiting');
+        expect(fs.readFileSync(lockFile, 'utf8')).toBe(live);
+    });
+});
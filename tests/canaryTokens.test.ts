@@ -0,0 +1,97 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { CANARY_ATTRIBUTE, createCanaryTokenRule, findCanaryDomain, parseCanaryDomains } from '../src/canaryTokens';
+import { DetectorOptions } from '../src/options';
+import { planQuickfix } from '../src/quickfix';
+import { finding } from './fixtures';
+
+describe('parseCanaryDomains', () => {
+    test('should add domains to the built-in ones with the default or their own policy', () => {
+        expect(parseCanaryDomains(['Honey.Corp.Example.com.', 'canarytokens.com=leak'], 'tripwire')).toEqual([
+            { domain: 'canarytokens.com', policy: 'leak' },
+            { domain: 'canarytokens.org', policy: 'tripwire' },
+            { domain: 'honey.corp.example.com', policy: 'tripwire' },
+        ]);
+    });
+
+    test('should reject unknown policies and empty domains', () => {
+        expect(() => parseCanaryDomains(['honey.example.com=alert'])).toThrow(
+            'Invalid canary domain "honey.example.com=alert"; expected a domain or domain=tripwire|leak',
+        );
+        expect(() => parseCanaryDomains(['=leak'])).toThrow('Invalid canary domain "=leak"');
+        expect(() => new DetectorOptions({ canaryPolicy: 'ignore' as any })).toThrow(
+            'Invalid canary policy "ignore"; expected tripwire or leak',
+        );
+    });
+});
+
+describe('findCanaryDomain', () => {
+    const domains = parseCanaryDomains(['corp.example.com', 'honey.corp.example.com=leak']);
+
+    test('should match subdomains with the most specific domain winning', () => {
+        expect(findCanaryDomain('http://canarytokens.com/about/abc123/index.html', domains)).toEqual({
+            domain: 'canarytokens.com',
+            policy: 'tripwire',
+        });
+        expect(findCanaryDomain('https://user@db.honey.corp.example.com:8443/x', domains)).toEqual({
+            domain: 'honey.corp.example.com',
+            policy: 'leak',
+        });
+        expect(findCanaryDomain('//wiki.corp.example.com/', domains)).toMatchObject({ domain: 'corp.example.com' });
+    });
+
+    test('should not match other hosts', () => {
+        expect(findCanaryDomain('https://notcanarytokens.com/', domains)).toBeUndefined();
+        expect(findCanaryDomain('https://example.com/?u=http://canarytokens.com/x', domains)).toBeUndefined();
+        expect(findCanaryDomain('mailto:security@canarytokens.org', domains)).toBeUndefined();
+    });
+});
+
+describe('canary token rule', () => {
+    const rule = createCanaryTokenRule(parseCanaryDomains(['honey.corp.example.com=leak']));
+    const file = { file: 'config/settings.py', language: 'python', content: '' };
+
+    test('should keep tripwires at info severity and out of quickfix patches', () => {
+        const url = 'http://canarytokens.com/traffic/abc123/post.jsp';
+        const tripwire = finding(url);
+
+        expect(rule.evaluate(tripwire, file)).toEqual([
+            {
+                rule: 'canary-token',
+                severity: 'info',
+                message: `${url} is a canary token tripwire on canarytokens.com; keep it as it is`,
+            },
+        ]);
+        expect(tripwire.attributes).toEqual({ [CANARY_ATTRIBUTE]: 'tripwire' });
+
+        tripwire.violations = [{ rule: 'scheme-policy', severity: 'warning', message: 'Use https' }];
+        const domainMap = { 'canarytokens.com': 'canary.example.com' };
+        expect(planQuickfix(tripwire, url, 'settings.py', { domainMap })).toBeNull();
+    });
+
+    test('should report leaks as errors', () => {
+        const leak = finding('https://aws.honey.corp.example.com/token');
+
+        expect(rule.evaluate(leak, file)).toMatchObject([{ rule: 'canary-token', severity: 'error' }]);
+        expect(leak.attributes).toEqual({ [CANARY_ATTRIBUTE]: 'leak' });
+    });
+
+    test('should skip other findings', () => {
+        const other = finding('https://api.example.com');
+
+        expect(rule.evaluate(other, file)).toEqual([]);
+        expect(other.attributes).toBeUndefined();
+    });
+});
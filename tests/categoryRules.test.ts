@@ -0,0 +1,45 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { CategoryRules } from '../src/categoryRules';
+
+describe('CategoryRules', () => {
+    const rules = new CategoryRules([
+        { host: '*.internal.example.com', category: 'internal-api' },
+        { host: 'auth.example.com', category: 'identity' },
+        { hostRegex: '^s3[.-]([a-z0-9-]+\\.)?amazonaws\\.com$', category: 'object-storage' },
+        { host: '*.example.com', category: 'corporate' },
+    ]);
+
+    test('should match subdomain wildcards, exact hosts, and regular expressions', () => {
+        expect(rules.categoryOf('billing.internal.example.com')).toBe('internal-api');
+        expect(rules.categoryOf('AUTH.example.com.')).toBe('identity');
+        expect(rules.categoryOf('s3.us-east-1.amazonaws.com')).toBe('object-storage');
+        expect(rules.categoryOf('www.example.com')).toBe('corporate');
+    });
+
+    test('should use the first matching rule and leave other hosts uncategorized', () => {
+        expect(rules.categoryOf('internal.example.com')).toBe('corporate');
+        expect(rules.categoryOf('example.com')).toBeUndefined();
+        expect(rules.categoryOf('evil-example.com')).toBeUndefined();
+        expect(rules.categoryOf('')).toBeUndefined();
+    });
+
+    test('should reject invalid rules', () => {
+        expect(() => new CategoryRules([{ category: 'x' }])).toThrow('exactly one of host and hostRegex');
+        expect(() => new CategoryRules([{ host: 'a.com', hostRegex: 'a', category: 'x' }])).toThrow('exactly one');
+        expect(() => new CategoryRules([{ hostRegex: '(', category: 'x' }])).toThrow('invalid hostRegex');
+        expect(() => new CategoryRules([{ host: 'a.com', category: 'go-import' }])).toThrow('built-in category');
+    });
+});
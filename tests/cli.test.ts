@@ -0,0 +1,95 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { program } from '../src/cli';
+import { ConsoleLogger } from '../src/logger';
+import { registerSink, unregisterSink } from '../src/sinks';
+
+describe('config print-effective', () => {
+    let dir: string;
+
+    beforeEach(async () => {
+        dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-cli-'));
+    });
+
+    afterEach(async () => {
+        jest.restoreAllMocks();
+        await fs.promises.rm(dir, { recursive: true, force: true });
+    });
+
+    async function printEffective(config: Record<string, unknown>): Promise<Record<string, unknown>> {
+        const configFile = path.join(dir, 'config.json');
+        await fs.promises.writeFile(configFile, JSON.stringify(config));
+        const log = jest.spyOn(ConsoleLogger, 'log').mockImplementation(() => undefined);
+
+        await program.parseAsync(['--config', configFile, 'config', 'print-effective'], { from: 'user' });
+
+        return JSON.parse(log.mock.calls[0][0]).effectiveConfig;
+    }
+
+    test('should apply severityEscalation from the config file', async () => {
+        const severityEscalation = [{ paths: ['auth/'], levels: 1 }];
+
+        expect(await printEffective({ severityEscalation })).toMatchObject({ severityEscalation });
+    });
+
+    test('should accept a sink format from the config file', async () => {
+        registerSink('audit-xml', () => ({ begin: () => undefined, write: () => undefined, end: () => undefined }));
+        try {
+            expect(await printEffective({ format: 'audit-xml' })).toMatchObject({ format: 'audit-xml' });
+        } finally {
+            unregisterSink('audit-xml');
+        }
+    });
+
+    test('should apply openApiSpecs from the config file', async () => {
+        expect(await printEffective({ openApiSpecs: ['specs/payments.json'] })).toMatchObject({
+            openApiSpecs: ['specs/payments.json'],
+        });
+    });
+});
+
+describe('ls', () => {
+    let dir: string;
+    let cwd: string;
+
+    beforeEach(async () => {
+        dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-cli-'));
+        cwd = process.cwd();
+        process.chdir(dir);
+    });
+
+    afterEach(async () => {
+        jest.restoreAllMocks();
+        process.chdir(cwd);
+        await fs.promises.rm(dir, { recursive: true, force: true });
+    });
+
+    test('should apply the size limits of a scan', async () => {
+        await fs.promises.writeFile('app.js', 'fetch("https://api.example.com");');
+        await fs.promises.writeFile('big.js', 'x'.repeat(1024 * 1024 + 1));
+        const log = jest.spyOn(ConsoleLogger, 'log').mockImplementation(() => undefined);
+        jest.spyOn(ConsoleLogger, 'info').mockImplementation(() => undefined);
+
+        await program.parseAsync(['--max-file-size', '1', 'ls', '--format', 'json'], { from: 'user' });
+
+        expect(JSON.parse(log.mock.calls[0][0])).toEqual([
+            { file: 'app.js', language: 'javascript', mode: 'parse' },
+            { file: 'big.js', language: 'javascript', mode: 'skip', reason: 'too-large' },
+        ]);
+    });
+});
@@ -0,0 +1,74 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { CodeOwners, parseCodeOwners } from '../src/codeOwners';
+
+const CODEOWNERS = [
+    '# Default owners',
+    '*       @org/platform',
+    '',
+    '*.js    @org/web # JavaScript everywhere',
+    '/build/logs/ @org/build',
+    'docs/*  docs@example.com',
+    'apps/   @org/apps @alice',
+    '/apps/github',
+].join('\n');
+
+describe('parseCodeOwners', () => {
+    test('should parse entries and skip comments', () => {
+        expect(parseCodeOwners(CODEOWNERS)).toEqual([
+            { pattern: '*', owners: ['@org/platform'], line: 2 },
+            { pattern: '*.js', owners: ['@org/web'], line: 4 },
+            { pattern: '/build/logs/', owners: ['@org/build'], line: 5 },
+            { pattern: 'docs/*', owners: ['docs@example.com'], line: 6 },
+            { pattern: 'apps/', owners: ['@org/apps', '@alice'], line: 7 },
+            { pattern: '/apps/github', owners: [], line: 8 },
+        ]);
+    });
+});
+
+describe('CodeOwners', () => {
+    const codeOwners = new CodeOwners(parseCodeOwners(CODEOWNERS));
+
+    test.each([
+        ['README.md', ['@org/platform']],
+        ['src/deep/app.js', ['@org/web']],
+        ['build/logs/2026/run.txt', ['@org/build']],
+        ['docs/getting-started.md', ['docs@example.com']],
+        ['docs/build-app/troubleshooting.md', ['@org/platform']],
+        ['apps/api/server.py', ['@org/apps', '@alice']],
+        ['services/apps/handler.py', ['@org/apps', '@alice']],
+        ['apps/github/action.yml', []],
+    ])('should resolve the owners of %s', (filePath, owners) => {
+        expect(codeOwners.ownersOf(filePath)).toEqual(owners);
+    });
+
+    test('should load CODEOWNERS from .github before the root', async () => {
+        const dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-owners-'));
+        try {
+            expect(await CodeOwners.load(dir)).toBeNull();
+
+            await fs.promises.writeFile(path.join(dir, 'CODEOWNERS'), '* @root-owner\n');
+            await fs.promises.mkdir(path.join(dir, '.github'));
+            await fs.promises.writeFile(path.join(dir, '.github', 'CODEOWNERS'), '* @github-owner\n');
+
+            expect((await CodeOwners.load(dir))!.ownersOf('src/app.ts')).toEqual(['@github-owner']);
+        } finally {
+            await fs.promises.rm(dir, { recursive: true, force: true });
+        }
+    });
+});
@@ -0,0 +1,80 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+import { classifyCodeScope, forScope } from '../src/codeScope';
+import { Rule } from '../src/ruleEngine';
+import { URLMatch } from '../src/urlFilter';
+
+describe('classifyCodeScope', () => {
+    test.each([
+        ['pkg/client/client_test.go', 'go'],
+        ['src/app.test.ts', 'typescript'],
+        ['src/app.spec.js', 'javascript'],
+        ['app/test_views.py', 'python'],
+        ['src/main/java/com/example/ClientTest.java', 'java'],
+        ['src/test/java/com/example/Helper.java', 'java'],
+        ['lib/client_spec.rb', 'ruby'],
+        ['web/__tests__/App.jsx', 'javascript'],
+        ['spec/fixtures/config.yaml', 'yaml'],
+        ['pkg/parser/testdata/input.json', 'json'],
+    ])('should classify %s as test code', (filePath, language) => {
+        expect(classifyCodeScope(filePath, language)).toBe('test');
+    });
+
+    test.each([
+        ['pkg/client/client.go', 'go'],
+        ['src/app.ts', 'typescript'],
+        ['src/contest.py', 'python'],
+        ['src/main/java/com/example/Latest.java', 'java'],
+        ['docs/testing-guide.md', 'unknown'],
+    ])('should classify %s as production code', (filePath, language) => {
+        expect(classifyCodeScope(filePath, language)).toBe('production');
+    });
+
+    test('should classify absolute paths relative to the working directory', () => {
+        expect(classifyCodeScope(path.join(process.cwd(), 'tests', 'app.js'), 'javascript')).toBe('test');
+    });
+});
+
+describe('forScope', () => {
+    const noLocalhost: Rule = {
+        id: 'no-localhost',
+        evaluate: () => [{ rule: 'no-localhost', severity: 'error', message: 'localhost URL' }],
+    };
+    const finding: URLMatch = {
+        url: 'http://localhost:8080',
+        start: 0,
+        end: 21,
+        line: 1,
+        column: 1,
+        sourceType: 'string',
+    };
+
+    test('should only evaluate the rule in the given scope', () => {
+        const rule = forScope(noLocalhost, 'production');
+        const production = { file: 'app.go', language: 'go', content: '', scope: 'production' as const };
+        const test = { file: 'app_test.go', language: 'go', content: '', scope: 'test' as const };
+
+        expect(rule.id).toBe('no-localhost');
+        expect(rule.evaluate(finding, production)).toHaveLength(1);
+        expect(rule.evaluate(finding, test)).toEqual([]);
+    });
+
+    test('should treat files without a scope as production code', () => {
+        const rule = forScope(noLocalhost, 'production');
+
+        expect(rule.evaluate(finding, { file: 'tool.go', language: 'go', content: '' })).toHaveLength(1);
+    });
+});
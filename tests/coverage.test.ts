@@ -0,0 +1,56 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { buildLanguageCoverage } from '../src/coverage';
+
+describe('buildLanguageCoverage', () => {
+    test('should count files without a parser by extension', () => {
+        const unparsed = new Map([
+            ['web/App.vue', 'unknown'],
+            ['web/Nav.VUE', 'unknown'],
+            ['build.gradle', 'unknown'],
+            ['ci/Jenkinsfile', 'unknown'],
+            ['src/lib.rs', 'rust'],
+        ]);
+
+        const coverage = buildLanguageCoverage(20, unparsed, true);
+
+        expect(coverage.scannedFiles).toBe(20);
+        expect(coverage.parsedFiles).toBe(15);
+        expect(coverage.fallbackRegex).toBe(true);
+        expect(coverage.unparsedFiles).toEqual([
+            'build.gradle',
+            'ci/Jenkinsfile',
+            'src/lib.rs',
+            'web/App.vue',
+            'web/Nav.VUE',
+        ]);
+        expect(coverage.extensions).toEqual([
+            { extension: '.vue', language: 'unknown', files: 2 },
+            { extension: '.gradle', language: 'unknown', files: 1 },
+            { extension: '.rs', language: 'rust', files: 1 },
+            { extension: 'Jenkinsfile', language: 'unknown', files: 1 },
+        ]);
+    });
+
+    test('should report full coverage when every file was parsed', () => {
+        expect(buildLanguageCoverage(3, new Map(), false)).toEqual({
+            scannedFiles: 3,
+            parsedFiles: 3,
+            fallbackRegex: false,
+            unparsedFiles: [],
+            extensions: [],
+        });
+    });
+});
@@ -0,0 +1,146 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { CacheDirectory } from '../src/cacheDir';
+import {
+    DATA_BUNDLE_CACHE_ENTRY,
+    DataBundleContent,
+    HostData,
+    importDataBundle,
+    parseList,
+    pullDataBundle,
+    signDataBundle,
+    verifyDataBundle,
+} from '../src/dataBundle';
+import { URLFilter } from '../src/urlFilter';
+
+function generateKeys(): { privateKey: string; publicKey: string } {
+    return crypto.generateKeyPairSync('ed25519', {
+        privateKeyEncoding: { type: 'pkcs8', format: 'pem' },
+        publicKeyEncoding: { type: 'spki', format: 'pem' },
+    });
+}
+
+const CONTENT: DataBundleContent = {
+    createdAt: '2026-03-01T00:00:00.000Z',
+    sources: { tlds: 'tlds.txt', shortener: 'shorteners.txt' },
+    tlds: ['com', 'org', 'io'],
+    feeds: { shortener: ['bit.ly', 't.co'], tracking: ['t.co'] },
+};
+
+describe('parseList', () => {
+    test('should skip comments and blank lines and lowercase entries', () => {
+        expect(parseList('# Version 2026030100\nCOM\n\n  ORG  \nbit.ly # shortener\n')).toEqual([
+            'com',
+            'org',
+            'bit.ly',
+        ]);
+    });
+});
+
+describe('data bundles', () => {
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-data-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should pull lists from files', async () => {
+        const tlds = path.join(tempDir, 'tlds.txt');
+        const shorteners = path.join(tempDir, 'shorteners.txt');
+        await fs.promises.writeFile(tlds, '# IANA\nCOM\nORG\n');
+        await fs.promises.writeFile(shorteners, 'bit.ly\n');
+
+        const content = await pullDataBundle(tlds, { shortener: shorteners });
+
+        expect(content.tlds).toEqual(['com', 'org']);
+        expect(content.feeds).toEqual({ shortener: ['bit.ly'] });
+        expect(content.sources).toEqual({ tlds, shortener: shorteners });
+    });
+
+    test('should verify signed bundles', () => {
+        const { privateKey, publicKey } = generateKeys();
+
+        expect(verifyDataBundle(signDataBundle(CONTENT, privateKey), publicKey)).toEqual(CONTENT);
+    });
+
+    test('should reject tampered bundles and other signers', () => {
+        const { privateKey, publicKey } = generateKeys();
+        const bundle = signDataBundle(CONTENT, privateKey);
+
+        const tampered = { ...bundle, content: { ...CONTENT, tlds: [...CONTENT.tlds, 'evil'] } };
+        expect(() => verifyDataBundle(tampered, publicKey)).toThrow('signature does not match');
+        expect(() => verifyDataBundle(bundle, generateKeys().publicKey)).toThrow('signature does not match');
+    });
+
+    test('should install verified bundles only', async () => {
+        const { privateKey, publicKey } = generateKeys();
+        const bundleFile = path.join(tempDir, 'bundle.json');
+        const target = path.join(tempDir, 'installed', 'data.json');
+        await fs.promises.writeFile(bundleFile, JSON.stringify(signDataBundle(CONTENT, privateKey), null, 2));
+
+        await expect(importDataBundle(bundleFile, generateKeys().publicKey, target)).rejects.toThrow('signature');
+        expect(fs.existsSync(target)).toBe(false);
+
+        expect(await importDataBundle(bundleFile, publicKey, target)).toEqual(CONTENT);
+        expect((await HostData.load(target)).knownTlds).toEqual(new Set(['com', 'org', 'io']));
+    });
+
+    test('should install into the cache directory without a target', async () => {
+        const { privateKey, publicKey } = generateKeys();
+        const bundleFile = path.join(tempDir, 'bundle.json');
+        const cache = new CacheDirectory(path.join(tempDir, 'cache'));
+        await fs.promises.writeFile(bundleFile, JSON.stringify(signDataBundle(CONTENT, privateKey), null, 2));
+
+        await importDataBundle(bundleFile, publicKey, undefined, cache);
+
+        expect((await HostData.load(cache.path(DATA_BUNDLE_CACHE_ENTRY))).knownTlds).toEqual(
+            new Set(['com', 'org', 'io']),
+        );
+        expect(fs.existsSync(cache.path('.lock'))).toBe(false);
+    });
+});
+
+describe('HostData', () => {
+    test('should match feeds on the host and its parent domains', () => {
+        const hostData = new HostData(CONTENT);
+
+        expect(hostData.feedsOf('bit.ly')).toEqual(['shortener']);
+        expect(hostData.feedsOf('go.bit.ly')).toEqual(['shortener']);
+        expect(hostData.feedsOf('t.co')).toEqual(['shortener', 'tracking']);
+        expect(hostData.feedsOf('notbit.ly')).toEqual([]);
+        expect(hostData.feedsOf('ly')).toEqual([]);
+    });
+
+    test('should let the URL filter reject hosts with unknown TLDs', () => {
+        const finding = { start: 0, end: 10, line: 1, column: 1, sourceType: 'string' as const };
+        const filter = new URLFilter({ knownTlds: new HostData(CONTENT).knownTlds });
+
+        const urls = filter.filterUrls([
+            { ...finding, url: 'https://example.com' },
+            { ...finding, url: 'https://build.local' },
+            { ...finding, url: 'http://10.0.0.1' },
+        ]);
+
+        expect(urls.map(urlObj => urlObj.url)).toEqual(['https://example.com', 'http://10.0.0.1']);
+    });
+});
@@ -0,0 +1,104 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { DeprecationRegistry, createDeprecationRule } from '../src/deprecations';
+import { finding } from './fixtures';
+
+const registry = new DeprecationRegistry([
+    { prefix: 'https://api.example.com/v1', replacement: 'https://api.example.com/v2', sunset: '2026-12-31' },
+    { prefix: 'https://api.example.com/v1/legacy/', replacement: 'https://legacy.example.com/' },
+    { prefix: 'https://old-auth.example.com', sunset: '2026-01-31', note: 'see the auth migration guide' },
+]);
+
+describe('DeprecationRegistry', () => {
+    test('should suggest the replacement for the longest matching prefix', () => {
+        expect(registry.match('https://API.example.com/v1/users?id=1')).toMatchObject({
+            replacement: 'https://api.example.com/v2/users?id=1',
+        });
+        expect(registry.match('https://api.example.com/v1/legacy/orders')).toMatchObject({
+            replacement: 'https://legacy.example.com/orders',
+        });
+        expect(registry.match('https://old-auth.example.com/login')).toMatchObject({ replacement: null });
+    });
+
+    test('should match whole path segments only', () => {
+        expect(registry.match('https://api.example.com/v1')).not.toBeNull();
+        expect(registry.match('https://api.example.com/v10/users')).toBeNull();
+        expect(registry.match('https://old-auth.example.com.evil.com/')).toBeNull();
+    });
+
+    test('should reject entries without a prefix or with an invalid sunset date', () => {
+        expect(() => new DeprecationRegistry([{ prefix: '' }])).toThrow('must have a prefix');
+        expect(() => new DeprecationRegistry([{ prefix: 'https://a.example.com', sunset: 'next year' }])).toThrow(
+            'Invalid sunset date',
+        );
+    });
+
+    test('should load a registry mapping prefixes to their replacement', async () => {
+        const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'deprecations-'));
+        const file = path.join(dir, 'registry.json');
+        const entries = { 'https://a.example.com/v1/': { replacement: 'https://b.example.com/' } };
+        fs.writeFileSync(file, JSON.stringify(entries));
+
+        const loaded = await DeprecationRegistry.load(file);
+
+        expect(loaded.match('https://a.example.com/v1/ping')).toMatchObject({
+            replacement: 'https://b.example.com/ping',
+        });
+        fs.writeFileSync(file, '{');
+        await expect(DeprecationRegistry.load(file)).rejects.toThrow('Failed to parse deprecation registry');
+        fs.rmSync(dir, { recursive: true, force: true });
+    });
+});
+
+describe('createDeprecationRule', () => {
+    const rule = createDeprecationRule(registry, new Date('2026-06-01T12:00:00Z'));
+    const file = { file: 'src/client.ts', language: 'typescript', content: '' };
+
+    test('should warn before the sunset date and record the replacement', () => {
+        const users = finding('https://api.example.com/v1/users');
+
+        expect(rule.evaluate(users, file)).toEqual([
+            {
+                rule: 'deprecated-endpoint',
+                severity: 'warning',
+                message:
+                    'https://api.example.com/v1/users is deprecated and will be sunset on 2026-12-31; ' +
+                    'use https://api.example.com/v2/users',
+            },
+        ]);
+        expect(users.attributes).toEqual({ replacement: 'https://api.example.com/v2/users', sunset: '2026-12-31' });
+    });
+
+    test('should raise an error once the sunset date has passed', () => {
+        const login = finding('https://old-auth.example.com/login');
+
+        expect(rule.evaluate(login, file)).toEqual([
+            {
+                rule: 'deprecated-endpoint',
+                severity: 'error',
+                message:
+                    'https://old-auth.example.com/login is deprecated and was sunset on 2026-01-31 ' +
+                    '(see the auth migration guide)',
+            },
+        ]);
+    });
+
+    test('should ignore URLs on current endpoints', () => {
+        expect(rule.evaluate(finding('https://api.example.com/v2/users'), file)).toEqual([]);
+    });
+});
@@ -0,0 +1,124 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { DocLinkValidator, collectAnchors, extractDocLinks, slugifyHeading } from '../src/docLinks';
+
+describe('extractDocLinks', () => {
+    test('should extract relative Markdown links, images, references, and HTML attributes', () => {
+        const content = [
+            '# Guide',
+            'See [setup](docs/setup.md#install) and ![logo](../assets/logo.png "Logo").',
+            'External [site](https://example.com) and [mail](mailto:team@example.com) are skipped.',
+            'Jump to [usage](#usage).',
+            '<a href="api/index.html">API</a>',
+            '[ref]: ./CONTRIBUTING.md',
+        ].join('\n');
+
+        const links = extractDocLinks(content, 'README.md');
+
+        expect(links.map(link => link.url)).toEqual([
+            'docs/setup.md#install',
+            '../assets/logo.png',
+            '#usage',
+            'api/index.html',
+            './CONTRIBUTING.md',
+        ]);
+        for (const link of links) {
+            expect(content.substring(link.start, link.end)).toBe(link.url);
+        }
+        expect(links[0]).toMatchObject({ line: 2, column: 13, category: 'doc-link' });
+    });
+
+    test('should skip links in Markdown code', () => {
+        const content = ['```md', '[example](missing.md)', '```', 'Inline `[x](gone.md)` too.'].join('\n');
+
+        expect(extractDocLinks(content, 'README.md')).toEqual([]);
+    });
+
+    test('should only read href and src attributes in HTML', () => {
+        const content = '<p>[not a link](x.md)</p><img src="img/a.png"><a href="#top">Top</a>';
+
+        expect(extractDocLinks(content, 'index.html').map(link => link.url)).toEqual(['img/a.png', '#top']);
+    });
+});
+
+describe('collectAnchors', () => {
+    test('should slugify headings like GitHub', () => {
+        expect(slugifyHeading('CLI Options')).toBe('cli-options');
+        expect(slugifyHeading('What\'s `new` in v2.0?')).toBe('whats-new-in-v20');
+    });
+
+    test('should collect heading, duplicate, setext, and explicit anchors', () => {
+        const content = [
+            '# Usage',
+            '## Usage',
+            'Setext Heading',
+            '--------------',
+            '<a name="custom-anchor"></a>',
+            '```',
+            '# not a heading',
+            '```',
+        ].join('\n');
+
+        expect(Array.from(collectAnchors(content, 'guide.md')).sort()).toEqual([
+            'custom-anchor',
+            'setext-heading',
+            'usage',
+            'usage-1',
+        ]);
+    });
+});
+
+describe('DocLinkValidator', () => {
+    let dir: string;
+
+    beforeEach(async () => {
+        dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-docs-'));
+        await fs.promises.mkdir(path.join(dir, 'docs'));
+        await fs.promises.writeFile(path.join(dir, 'docs', 'setup.md'), '# Setup\n## Install\n');
+        await fs.promises.writeFile(path.join(dir, 'docs', 'main.go'), 'package main\n');
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(dir, { recursive: true, force: true });
+    });
+
+    test('should report missing targets and anchors', async () => {
+        const readme = path.join(dir, 'README.md');
+        const content = [
+            '# Overview',
+            '[ok](docs/setup.md#install) [dir](docs/) [line](docs/main.go#L1) [self](#overview)',
+            '[missing](docs/missing.md) [anchor](docs/setup.md#uninstall) [self](#nowhere) [root](/docs/setup.md)',
+        ].join('\n');
+        await fs.promises.writeFile(readme, content);
+        const links = extractDocLinks(content, readme);
+
+        await new DocLinkValidator(dir).validate(links, readme);
+
+        const violations = links
+            .filter(link => link.violations)
+            .map(link => [link.url, link.violations!.map(v => v.rule)]);
+        expect(violations).toEqual([
+            ['docs/missing.md', ['broken-link']],
+            ['docs/setup.md#uninstall', ['broken-anchor']],
+            ['#nowhere', ['broken-anchor']],
+        ]);
+        expect(links.find(link => link.url === '#nowhere')!.violations![0].message).toBe(
+            'Anchor #nowhere not found in README.md',
+        );
+    });
+});
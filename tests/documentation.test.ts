@@ -64,7 +64,7 @@ describe('Documentation Sync Tests', () => {
             const cliContent = fs.readFileSync(cliPath, 'utf8');
 
             // Extract all .option() calls using regex
-            const optionRegex = /\.option\(\s*['"`]([^'"`]+)['"`]/g;
+            const optionRegex = /\.(?:option|requiredOption)\(\s*['"`]([^'"`]+)['"`]/g;
             const definedOptions: string[] = [];
             let match;
 
@@ -134,7 +134,7 @@ describe('Documentation Sync Tests', () => {
             const cliPath = path.join(__dirname, '..', 'src', 'cli.ts');
             const cliContent = fs.readFileSync(cliPath, 'utf8');
 
-            const optionRegex = /\.option\(\s*['"`]([^'"`]+)['"`]/g;
+            const optionRegex = /\.(?:option|requiredOption)\(\s*['"`]([^'"`]+)['"`]/g;
             const actualOptions: string[] = [];
             let match;
 
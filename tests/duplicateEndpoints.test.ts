@@ -0,0 +1,74 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { findDuplicateEndpoints, normalizeEndpoint } from '../src/duplicateEndpoints';
+import { FileResult, URLMatch } from '../src/urlFilter';
+
+function resultFor(file: string, urls: string[], extra: Partial<URLMatch> = {}): FileResult {
+    return {
+        file,
+        urls: urls.map((url, index) => ({
+            url,
+            start: 0,
+            end: url.length,
+            line: index + 1,
+            column: 1,
+            sourceType: 'string',
+            ...extra,
+        })),
+    };
+}
+
+describe('normalizeEndpoint', () => {
+    test('should ignore case, default ports, fragments, and trailing slashes', () => {
+        expect(normalizeEndpoint('HTTPS://API.Example.com:443/v2/#top')).toBe('https://api.example.com/v2');
+        expect(normalizeEndpoint('//api.example.com/v2?x=1')).toBe('https://api.example.com/v2?x=1');
+        expect(normalizeEndpoint('http://api.example.com:8080/')).toBe('http://api.example.com:8080');
+        expect(normalizeEndpoint('not a url')).toBeNull();
+    });
+});
+
+describe('findDuplicateEndpoints', () => {
+    test('should report URLs in enough files with consolidation hints', () => {
+        const results = [
+            resultFor('src/billing/client.ts', ['https://api.example.com/v2', 'https://api.example.com/v2/']),
+            resultFor('src/orders/client.ts', ['https://API.example.com/v2']),
+            resultFor('src/config/endpoints.ts', ['https://api.example.com/v2']),
+            resultFor('src/billing/other.ts', ['https://once.example.com', 'https://twice.example.com']),
+            resultFor('src/orders/other.ts', ['https://twice.example.com']),
+        ];
+
+        expect(findDuplicateEndpoints(results)).toEqual([
+            {
+                url: 'https://api.example.com/v2',
+                count: 4,
+                files: ['src/billing/client.ts', 'src/config/endpoints.ts', 'src/orders/client.ts'],
+                candidates: ['src/config/endpoints.ts:1'],
+                commonDirectory: 'src',
+            },
+        ]);
+        expect(findDuplicateEndpoints(results, 2).map(duplicate => duplicate.url)).toEqual([
+            'https://api.example.com/v2',
+            'https://twice.example.com',
+        ]);
+    });
+
+    test('should skip findings in built-in categories', () => {
+        const results = ['a.md', 'b.md', 'c.md'].map(file =>
+            resultFor(file, ['https://example.com/docs'], { category: 'doc-link' }),
+        );
+
+        expect(findDuplicateEndpoints(results)).toEqual([]);
+    });
+});
@@ -0,0 +1,78 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { DUPLICATES_ATTRIBUTE, collapseDuplicateFiles, contentHash } from '../src/duplicateFiles';
+import { URLDetector } from '../src/urlDetector';
+import { FileResult } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+function result(file: string, url: string = 'https://api.example.com'): FileResult {
+    return { file, urls: [finding(url)] };
+}
+
+describe('collapseDuplicateFiles', () => {
+    test('should keep the shallowest copy and list the others on its findings', () => {
+        const results = [
+            result('vendor/b/client/index.js'),
+            result('src/app.js', 'https://app.example.com'),
+            result('vendor/a/client/index.js'),
+            result('lib/client.js'),
+            result('lib/client.ts'),
+        ];
+        const hashes = new Map(results.map(entry => [entry.file, contentHash('same')]));
+        hashes.set('src/app.js', contentHash('other'));
+
+        const collapsed = collapseDuplicateFiles(results, hashes);
+
+        expect(collapsed.map(entry => entry.file)).toEqual(['src/app.js', 'lib/client.js', 'lib/client.ts']);
+        expect(collapsed[1].urls[0].attributes).toEqual({
+            [DUPLICATES_ATTRIBUTE]: 'vendor/a/client/index.js vendor/b/client/index.js',
+        });
+        expect(collapsed[0].urls[0].attributes).toBeUndefined();
+        expect(collapsed[2].urls[0].attributes).toBeUndefined();
+    });
+
+    test('should keep results without a content hash', () => {
+        const results = [result('COMMIT_EDITMSG'), result('COMMIT_EDITMSG')];
+
+        expect(collapseDuplicateFiles(results, new Map())).toEqual(results);
+    });
+});
+
+describe('URLDetector with collapseDuplicates', () => {
+    test('should report identical files once', async () => {
+        const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-duplicates-'));
+        const source = 'fetch("https://registry.example.com/api");\n';
+        fs.mkdirSync(path.join(dir, 'src'));
+        fs.mkdirSync(path.join(dir, 'vendor', 'client'), { recursive: true });
+        fs.writeFileSync(path.join(dir, 'src', 'client.js'), source);
+        fs.writeFileSync(path.join(dir, 'vendor', 'client', 'client.js'), source);
+
+        const cwd = process.cwd();
+        process.chdir(dir);
+        try {
+            const detector = new URLDetector({ scan: ['**/*.js'], collapseDuplicates: true });
+            const results = await detector.process();
+
+            expect(results.map(entry => entry.file)).toEqual([path.join(fs.realpathSync(dir), 'src', 'client.js')]);
+            expect(results[0].urls[0].attributes).toMatchObject({ duplicates: 'vendor/client/client.js' });
+        } finally {
+            process.chdir(cwd);
+            fs.rmSync(dir, { recursive: true, force: true });
+        }
+    });
+});
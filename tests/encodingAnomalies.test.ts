@@ -0,0 +1,69 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { createEncodingAnomalyRule, findEncodingAnomalies } from '../src/encodingAnomalies';
+import { finding } from './fixtures';
+
+describe('findEncodingAnomalies', () => {
+    test.each([
+        ['https://example.com/a%2520b', 'double-encoding', '%2520'],
+        ['https://example.com/%252e%252e/etc', 'double-encoding', '%252e'],
+        ['https://example.com/%c0%ae%c0%ae/etc/passwd', 'overlong-encoding', '%c0%ae'],
+        ['https://example.com/%E0%80%AF', 'overlong-encoding', '%E0%80%AF'],
+        ['https://example.com/%f0%80%80%af', 'overlong-encoding', '%f0%80%80%af'],
+        ['https://example.com/?next=%0d%0aSet-Cookie:x', 'crlf', '%0d%0a'],
+        ['https://example.com/?next=%250A', 'crlf', '%250A'],
+    ])('should find the anomaly in %s', (url, kind, sequence) => {
+        expect(findEncodingAnomalies(url)[0]).toMatchObject({ kind, sequence });
+    });
+
+    test('should not flag ordinary encodings', () => {
+        expect(findEncodingAnomalies('https://example.com/a%20b?q=%E2%82%AC&r=100%25')).toEqual([]);
+        expect(findEncodingAnomalies('https://example.com/%C3%A9t%C3%A9')).toEqual([]);
+    });
+
+    test('should list every anomaly in order without reporting a sequence twice', () => {
+        expect(findEncodingAnomalies('https://example.com/%2520/%c1%9c?x=%250d%250a')).toEqual([
+            { kind: 'double-encoding', sequence: '%2520', index: 20 },
+            { kind: 'overlong-encoding', sequence: '%c1%9c', index: 26 },
+            { kind: 'crlf', sequence: '%250d%250a', index: 35 },
+        ]);
+    });
+});
+
+describe('encoding anomaly rule', () => {
+    const rule = createEncodingAnomalyRule();
+    const file = { file: 'src/app.ts', language: 'typescript', content: '' };
+
+    test('should raise one violation per kind, by severity', () => {
+        const violations = rule.evaluate(finding('https://example.com/%2520%2541/%0d%0a?%0a'), file);
+
+        expect(violations).toEqual([
+            {
+                rule: 'encoding-anomaly',
+                severity: 'warning',
+                message: '%2520 is double-encoded and decodes to %20',
+            },
+            {
+                rule: 'encoding-anomaly',
+                severity: 'error',
+                message: '%0d%0a encodes a line break, which can inject headers or split requests',
+            },
+        ]);
+    });
+
+    test('should accept clean URLs', () => {
+        expect(rule.evaluate(finding('https://example.com/search?q=a%20b'), file)).toEqual([]);
+    });
+});
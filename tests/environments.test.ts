@@ -0,0 +1,108 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { analyzeEnvironments } from '../src/environments';
+import { FileResult, URLMatch } from '../src/urlFilter';
+
+function resultsFor(file: string, urls: string[], extra: Partial<URLMatch> = {}): FileResult {
+    return {
+        file,
+        urls: urls.map((url, index) => ({
+            url,
+            start: 0,
+            end: url.length,
+            line: index + 1,
+            column: 1,
+            sourceType: 'string',
+            ...extra,
+        })),
+    };
+}
+
+describe('analyzeEnvironments', () => {
+    test('should report scheme and port differences between environments of a service', () => {
+        const results = [
+            resultsFor('switch.go', [
+                'https://prod-switch.go.example.com',
+                'https://staging-switch.go.example.com',
+                'http://dev-switch.go.example.com:9000',
+                'https://default-switch.go.example.com',
+            ]),
+        ];
+
+        const services = analyzeEnvironments(results);
+
+        expect(services).toHaveLength(1);
+        expect(services[0]).toMatchObject({
+            service: 'switch.go.example.com',
+            environments: ['development', 'production', 'staging'],
+            missing: [],
+            inconsistencies: [
+                'scheme differs: https (production, staging) vs http (development)',
+                'port differs: 443 (production, staging) vs 9000 (development)',
+            ],
+        });
+        expect(services[0].endpoints[2]).toEqual({
+            environment: 'development',
+            url: 'http://dev-switch.go.example.com:9000',
+            file: 'switch.go',
+            line: 3,
+        });
+    });
+
+    test('should group label and hyphen tokens and report missing environments', () => {
+        const results = [
+            resultsFor('a.ts', ['https://api.staging.example.com/v1', 'https://api-prod.example.com/v1']),
+            resultsFor('b.ts', ['https://prod-auth.example.com', 'https://auth.dev.example.com']),
+        ];
+
+        const services = analyzeEnvironments(results);
+
+        expect(services.map(s => [s.service, s.missing])).toEqual([
+            ['api.example.com', ['development']],
+            ['auth.example.com', ['staging']],
+        ]);
+    });
+
+    test('should use the expected environments and aliases when given', () => {
+        const results = [resultsFor('a.ts', ['https://api.live.example.com', 'https://api.uat.example.com'])];
+
+        expect(analyzeEnvironments(results)).toEqual([]);
+        expect(analyzeEnvironments(results, { expectedEnvironments: ['production', 'qa', 'staging'] })).toMatchObject([
+            { service: 'api.example.com', missing: ['staging'] },
+        ]);
+        expect(
+            analyzeEnvironments(results, { aliases: { production: ['live'] }, expectedEnvironments: ['production'] }),
+        ).toEqual([]);
+    });
+
+    test('should ignore registrable domains, non-environment hosts, and categorized findings', () => {
+        const results = [
+            resultsFor('a.ts', ['https://dev.to/article', 'https://www.example.com', 'https://api.example.com']),
+            resultsFor('go.mod', ['https://prod.example.com/module'], { category: 'go-import' }),
+        ];
+
+        expect(analyzeEnvironments(results, { expectedEnvironments: ['production'] })).toEqual([]);
+    });
+
+    test('should analyze URLs with user-defined categories', () => {
+        const results = [
+            resultsFor('a.ts', ['https://prod-api.example.com'], { category: 'internal-api' }),
+            resultsFor('b.ts', ['https://dev-api.example.com'], { category: 'internal-api' }),
+        ];
+
+        const services = analyzeEnvironments(results, { expectedEnvironments: ['production', 'staging'] });
+        expect(services.map(service => [service.service, service.missing])).toEqual([['api.example.com', ['staging']]]);
+    });
+});
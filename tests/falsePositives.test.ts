@@ -0,0 +1,120 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import {
+    FALSE_POSITIVE_HEURISTICS,
+    HEURISTIC_ATTRIBUTE,
+    INFORMATIONAL_CATEGORY,
+    classifyFalsePositive,
+    matchFalsePositive,
+    parseFalsePositiveHeuristics,
+} from '../src/falsePositives';
+import { DetectorOptions } from '../src/options';
+import { URLMatch } from '../src/urlFilter';
+
+function findingIn(content: string, url: string): URLMatch {
+    const start = content.indexOf(url);
+    return { url, start, end: start + url.length, line: 1, column: start + 1, sourceType: 'string' };
+}
+
+function match(content: string, url: string) {
+    return matchFalsePositive(findingIn(content, url), content, FALSE_POSITIVE_HEURISTICS);
+}
+
+describe('parseFalsePositiveHeuristics', () => {
+    test('should accept known heuristics without duplicates', () => {
+        expect(parseFalsePositiveHeuristics(['go-import-path', 'xml-namespace', 'go-import-path'])).toEqual([
+            'xml-namespace',
+            'go-import-path',
+        ]);
+    });
+
+    test('should reject unknown heuristics', () => {
+        expect(() => new DetectorOptions({ falsePositiveHeuristics: ['localhost' as any] })).toThrow(
+            'Unknown false positive heuristic "localhost"; expected one of xml-namespace, example-domain',
+        );
+    });
+});
+
+describe('matchFalsePositive', () => {
+    test.each([
+        ['<svg xmlns="http://www.w3.org/2000/svg">', 'http://www.w3.org/2000/svg'],
+        ['<o:order targetNamespace="http://schemas.acme.com/order">', 'http://schemas.acme.com/order'],
+        ["use.setAttributeNS('http://www.w3.org/1999/xlink', 'href', '#icon')", 'http://www.w3.org/1999/xlink'],
+        ["root.find('{http://maven.apache.org/POM/4.0.0}version')", 'http://maven.apache.org/POM/4.0.0'],
+    ])('should recognize the XML namespace name in %s', (content, url) => {
+        expect(match(content, url)).toBe('xml-namespace');
+    });
+
+    test('should tell schema locations from the namespaces they belong to', () => {
+        const namespace = 'http://maven.apache.org/POM/4.0.0';
+        const location = 'https://maven.apache.org/xsd/maven-4.0.0.xsd';
+        const content = `<project xsi:schemaLocation="${namespace} ${location}">`;
+
+        expect(match(content, namespace)).toBe('xml-namespace');
+        expect(match(content, location)).toBeUndefined();
+    });
+
+    test.each([
+        ['https://api.example.com/v1', 'example-domain'],
+        ['http://user@Example.ORG.:8080/', 'example-domain'],
+        ['https://orders.service.test', 'example-domain'],
+        ['https://example.community', undefined],
+        ['https://notexample.com', undefined],
+    ])('should match %s against reserved example and test domains', (url, expected) => {
+        expect(match(`const api = "${url}";`, url)).toBe(expected);
+    });
+
+    test.each([
+        ['deps = ["//github.com/acme/widgets/pkg"]', '//github.com/acme/widgets/pkg', 'go-import-path'],
+        ['RUN go install -v https://golang.org/x/tools/gopls', 'https://golang.org/x/tools/gopls', 'go-import-path'],
+        ['see "https://github.com/acme/widgets"', 'https://github.com/acme/widgets', undefined],
+        ['"//github.com/acme"', '//github.com/acme', undefined],
+        ['"//github.com/acme/widgets?tab=readme"', '//github.com/acme/widgets?tab=readme', undefined],
+    ])('should match Go import paths but not web pages in %s', (content, url, expected) => {
+        expect(match(content, url)).toBe(expected);
+    });
+
+    test('should only apply enabled heuristics', () => {
+        const content = '<svg xmlns="http://www.example.com/ns">';
+        const finding = findingIn(content, 'http://www.example.com/ns');
+
+        expect(matchFalsePositive(finding, content, ['example-domain'])).toBe('example-domain');
+        expect(matchFalsePositive(finding, content, ['go-import-path'])).toBeUndefined();
+        expect(matchFalsePositive(finding, content, [])).toBeUndefined();
+    });
+});
+
+describe('classifyFalsePositive', () => {
+    test('should move matches to the informational category with the heuristic', () => {
+        const content = 'const api = "https://api.example.com";';
+        const finding = findingIn(content, 'https://api.example.com');
+
+        expect(classifyFalsePositive(finding, content, ['example-domain'])).toBe(true);
+        expect(finding.category).toBe(INFORMATIONAL_CATEGORY);
+        expect(finding.attributes).toEqual({ [HEURISTIC_ATTRIBUTE]: 'example-domain' });
+    });
+
+    test('should leave categorized findings and live URLs alone', () => {
+        const content = 'connect("wss://stream.example.com"); fetch("https://api.acme.com")';
+        const categorized = { ...findingIn(content, 'wss://stream.example.com'), category: 'realtime-rpc' };
+        const live = findingIn(content, 'https://api.acme.com');
+
+        expect(classifyFalsePositive(categorized, content, FALSE_POSITIVE_HEURISTICS)).toBe(false);
+        expect(categorized.category).toBe('realtime-rpc');
+        expect(classifyFalsePositive(live, content, FALSE_POSITIVE_HEURISTICS)).toBe(false);
+        expect(live.category).toBeUndefined();
+        expect(live.attributes).toBeUndefined();
+    });
+});
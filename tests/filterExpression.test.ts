@@ -0,0 +1,124 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { applyFilter, compileFilter, resolveFilter } from '../src/filterExpression';
+import { URLMatch } from '../src/urlFilter';
+
+const findings: URLMatch[] = [
+    {
+        url: 'http://update.example.ru/payload',
+        start: 0,
+        end: 32,
+        line: 3,
+        column: 9,
+        sourceType: 'string',
+        violations: [{ rule: 'scheme-policy', severity: 'warning', message: 'Use https' }],
+        attributes: { owner: '@team-a' },
+    },
+    {
+        url: 'https://docs.example.com:8443/guide',
+        start: 40,
+        end: 75,
+        line: 7,
+        column: 4,
+        sourceType: 'comment',
+        category: 'docs',
+    },
+    {
+        url: '//cdn.example.ru/lib.js',
+        start: 80,
+        end: 103,
+        line: 12,
+        column: 1,
+        sourceType: 'string',
+        violations: [
+            { rule: 'sri-missing', severity: 'info', message: 'Add SRI' },
+            { rule: 'open-redirect', severity: 'error', message: 'Redirect' },
+        ],
+    },
+];
+
+function select(expression: string): number[] {
+    const predicate = compileFilter(expression);
+    return findings.map((finding, index) => (predicate(finding, 'src/app.ts') ? index : -1)).filter(i => i >= 0);
+}
+
+describe('compileFilter', () => {
+    test('should evaluate the example from the documentation', () => {
+        expect(select('host endswith ".ru" and context == "string" and severity >= warn')).toEqual([0, 2]);
+    });
+
+    test.each([
+        ['scheme == http', [0]],
+        ['port == 8443', [1]],
+        ['port < 100', [0]],
+        ['path startswith "/gu"', [1]],
+        ['url contains example.com', [1]],
+        ["url matches '^https?:'", [0, 1]],
+        ['file == "src/app.ts" and line > 5 and column <= 4', [1, 2]],
+        ['category == docs', [1]],
+        ['category != docs', [0, 2]],
+        ['severity == none', [1]],
+        ['severity < warning', [1]],
+        ['severity > warn', [2]],
+        ['rule == sri-missing', [2]],
+        ['rule != sri-missing', [0, 1]],
+        ['owner == "@team-a"', [0]],
+        ['owner != "@team-a"', [1, 2]],
+        ['sourceType == comment or not (host endswith ".ru")', [1]],
+        ['NOT context == string AND line > 1 OR line == 3', [0, 1]],
+    ])('should select findings matching %s', (expression, expected) => {
+        expect(select(expression)).toEqual(expected);
+    });
+
+    test.each([
+        ['host ==', 'expected a value at the end'],
+        ['host = x', "unexpected '=' at position 6"],
+        ['== x', 'expected a field name at position 1'],
+        ['host is x', 'expected an operator at position 6'],
+        ['(host == x', "expected ')' at the end"],
+        ['host == x y', "unexpected 'y' at position 11"],
+        ['line > three', 'line is compared with a number at position 8'],
+        ['severity >= fatal', "unknown severity 'fatal' at position 13"],
+        ['url matches "("', 'invalid regular expression'],
+        ['url == "open', 'unterminated string at position 8'],
+    ])('should reject %s', (expression, message) => {
+        expect(() => compileFilter(expression)).toThrow(`Invalid filter "${expression}": `);
+        expect(() => compileFilter(expression)).toThrow(message);
+    });
+});
+
+describe('filters', () => {
+    test('should resolve named filters and fall back to the expression', () => {
+        const filters = { 'prod-errors': 'severity >= error' };
+        expect(resolveFilter('prod-errors', filters)).toBe('severity >= error');
+        expect(resolveFilter('host == a', filters)).toBe('host == a');
+        expect(resolveFilter('toString', filters)).toBe('toString');
+    });
+
+    test('should drop unmatched findings but keep their files', () => {
+        const results = [
+            { file: 'a.ts', urls: findings },
+            { file: 'b.ts', urls: [findings[1]] },
+        ];
+
+        const filtered = applyFilter(results, compileFilter('host endswith ".ru"'));
+
+        expect(filtered.map(result => [result.file, result.urls.map(finding => finding.url)])).toEqual([
+            ['a.ts', ['http://update.example.ru/payload', '//cdn.example.ru/lib.js']],
+            ['b.ts', []],
+        ]);
+        expect(results[0].urls).toHaveLength(3);
+    });
+});
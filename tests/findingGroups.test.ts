@@ -0,0 +1,63 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { UNGROUPED, groupFindings } from '../src/findingGroups';
+import { FileResult } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+describe('groupFindings', () => {
+    test('should count files, findings, and violations per attribute value', () => {
+        const web = { attributes: { owner: '@org/web' } };
+        const results: FileResult[] = [
+            {
+                file: 'web/app.js',
+                urls: [
+                    finding('https://a.example.com', web),
+                    finding('http://b.example.com', {
+                        ...web,
+                        violations: [{ rule: 'no-plain-http', severity: 'error', message: 'Use https' }],
+                    }),
+                ],
+            },
+            { file: 'web/util.js', urls: [finding('https://c.example.com', web)] },
+            { file: 'api/server.py', urls: [finding('https://d.example.com', { attributes: { owner: '@org/api' } })] },
+            { file: 'README.md', urls: [finding('https://e.example.com')] },
+        ];
+
+        expect(groupFindings(results, 'owner')).toEqual([
+            { value: '@org/web', files: ['web/app.js', 'web/util.js'], urlCount: 3, violationCount: 1 },
+            { value: '(none)', files: ['README.md'], urlCount: 1, violationCount: 0 },
+            { value: '@org/api', files: ['api/server.py'], urlCount: 1, violationCount: 0 },
+        ]);
+        expect(UNGROUPED).toBe('(none)');
+    });
+
+    test('should group by finding category', () => {
+        const results: FileResult[] = [
+            {
+                file: 'config.js',
+                urls: [
+                    finding('https://a.internal.example.com', { category: 'internal-api' }),
+                    finding('https://b.internal.example.com', { category: 'internal-api' }),
+                    finding('https://www.example.org'),
+                ],
+            },
+        ];
+
+        expect(groupFindings(results, 'category').map(group => [group.value, group.urlCount])).toEqual([
+            ['internal-api', 2],
+            ['(none)', 1],
+        ]);
+    });
+});
@@ -0,0 +1,79 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as path from 'path';
+import { normalizeUrl, normalizeFingerprintPath } from '../src/fingerprint';
+import { URLDetector } from '../src/urlDetector';
+
+describe('Fingerprints', () => {
+    let detector: URLDetector;
+
+    beforeEach(() => {
+        detector = new URLDetector();
+    });
+
+    test('should normalize scheme, host case, and default ports', () => {
+        expect(normalizeUrl('HTTPS://API.Example.com:443/Path?Q=1')).toBe('https://api.example.com/Path?Q=1');
+        expect(normalizeUrl('http://example.com:8080/')).toBe('http://example.com:8080/');
+        expect(normalizeUrl('//CDN.example.com/lib.js')).toBe('//cdn.example.com/lib.js');
+    });
+
+    test('should normalize paths relative to the working directory', () => {
+        expect(normalizeFingerprintPath(path.join(process.cwd(), 'src', 'app.js'))).toBe('src/app.js');
+        expect(normalizeFingerprintPath('src\\app.js')).toBe('src/app.js');
+    });
+
+    test('should keep fingerprints stable when lines are inserted above', async () => {
+        const before = `import { get } from './http';\nconst url = "https://api.example.com/v1";\nget(url);`;
+        const after = `// new header\n\n${before.replace('\n', '\n\n')}`;
+
+        const [original] = await detector.detectURLs(before, 'javascript', 'src/app.js');
+        const moved = (await detector.detectURLs(after, 'javascript', 'src/app.js')).find(u => u.line === 5);
+
+        expect(original.fingerprint).toMatch(/^[0-9a-f]{32}$/);
+        expect(moved!.fingerprint).toBe(original.fingerprint);
+    });
+
+    test('should tell identical lines apart by their neighbouring lines', async () => {
+        const line = 'fetch("https://api.example.com/v1");';
+        const code = `function a() {\n    ${line}\n}\nfunction b() {\n    ${line}\n}`;
+
+        const [a, b] = await detector.detectURLs(code, 'javascript', 'src/app.js');
+        const renamed = code.replace('function a', 'function c');
+        const [changed] = await detector.detectURLs(renamed, 'javascript', 'src/app.js');
+
+        expect(a.fingerprint).not.toBe(b.fingerprint);
+        expect(changed.fingerprint).not.toBe(a.fingerprint);
+    });
+
+    test('should differ across files and URLs', async () => {
+        const code = `const url = "https://api.example.com/v1";`;
+
+        const [a] = await detector.detectURLs(code, 'javascript', 'src/a.js');
+        const [b] = await detector.detectURLs(code, 'javascript', 'src/b.js');
+        const [c] = await detector.detectURLs(code.replace('v1', 'v2'), 'javascript', 'src/a.js');
+
+        expect(a.fingerprint).not.toBe(b.fingerprint);
+        expect(a.fingerprint).not.toBe(c.fingerprint);
+    });
+
+    test('should distinguish identical URLs on identical lines', async () => {
+        const code = `const url = "https://api.example.com";\nconst url = "https://api.example.com";`;
+
+        const urls = await detector.detectURLs(code, 'javascript', 'src/app.js');
+
+        expect(urls).toHaveLength(2);
+        expect(urls[0].fingerprint).not.toBe(urls[1].fingerprint);
+    });
+});
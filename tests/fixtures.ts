@@ -0,0 +1,68 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { Violation } from '../src/ruleEngine';
+import { FileResult, URLMatch } from '../src/urlFilter';
+
+/** Error-severity violation of sample findings */
+export const ERROR: Violation = { rule: 'no-plain-http', severity: 'error', message: 'Use https' };
+
+/** Warning-severity violation of sample findings */
+export const WARNING: Violation = { rule: 'deprecated-host', severity: 'warning', message: 'Host is deprecated' };
+
+/**
+ * Builds a string finding at the start of the first line.
+ *
+ * @param url The URL found
+ * @param extra Fields to set or override
+ * @returns The finding
+ */
+export function finding(url: string, extra: Partial<URLMatch> = {}): URLMatch {
+    return { url, start: 0, end: url.length, line: 1, column: 1, sourceType: 'string', ...extra };
+}
+
+/**
+ * Builds the results of a small scan for notification tests:
+ * - src/app.js on line 3: 'aaa' with an error and a warning, 'bbb' with an error, 'ccc' with a warning,
+ *   'ddd' with an error triaged as accepted-risk, and a finding without violations or fingerprint
+ * - src/copy.js: another 'aaa' with an error
+ * - src/empty.js: no findings
+ *
+ * @returns Fresh results, safe to modify
+ */
+export function sampleResults(): FileResult[] {
+    const at = { line: 3 };
+    return [
+        {
+            file: 'src/app.js',
+            urls: [
+                finding('http://api.example.com/v1', { ...at, fingerprint: 'aaa', violations: [ERROR, WARNING] }),
+                finding('http://api.example.com/v2', { ...at, fingerprint: 'bbb', violations: [ERROR] }),
+                finding('https://docs.example.com', { ...at, fingerprint: 'ccc', violations: [WARNING] }),
+                finding('http://legacy.example.com', {
+                    ...at,
+                    fingerprint: 'ddd',
+                    violations: [ERROR],
+                    attributes: { triage: 'accepted-risk' },
+                }),
+                finding('https://unknown.example.com', at),
+            ],
+        },
+        {
+            file: 'src/copy.js',
+            urls: [finding('http://api.example.com/v1', { fingerprint: 'aaa', violations: [ERROR] })],
+        },
+        { file: 'src/empty.js', urls: [] },
+    ];
+}
@@ -0,0 +1,61 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { isGeneratedFile } from '../src/generatedCode';
+
+describe('isGeneratedFile', () => {
+    test.each([
+        ['api/service.pb.go'],
+        ['api/service_pb.go'],
+        ['proto/service_pb2.py'],
+        ['Forms/Main.Designer.cs'],
+        ['dist/app.min.js'],
+        ['styles/site.min.css'],
+    ])('should detect %s by name', filePath => {
+        expect(isGeneratedFile(filePath, 'const url = "https://example.com";')).toBe(true);
+    });
+
+    test.each([
+        ['// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n'],
+        ['# Code generated by sqlc. DO NOT EDIT.\nimport os\n'],
+        ['/**\n * @generated by relay-compiler\n */\nexport default {};\n'],
+        ['// <auto-generated>\n// This file was auto-generated. Do not edit it manually.\n'],
+    ])('should detect generator headers', content => {
+        expect(isGeneratedFile('src/file.txt', content)).toBe(true);
+    });
+
+    test('should detect minified content', () => {
+        const minified = 'var a="https://example.com";'.repeat(100);
+
+        expect(isGeneratedFile('dist/vendor.js', minified)).toBe(true);
+    });
+
+    test('should not flag handwritten code', () => {
+        const content = [
+            'package main',
+            '',
+            '// Generated URLs are validated below; do not edit the list without review.',
+            'const url = "https://example.com"',
+        ].join('\n');
+
+        expect(isGeneratedFile('main.go', content)).toBe(false);
+        expect(isGeneratedFile('src/minimal.js', 'x')).toBe(false);
+    });
+
+    test('should ignore generator markers far below the header', () => {
+        const content = `${'const x = 1;\n'.repeat(500)}// Code generated by tool. DO NOT EDIT.\n`;
+
+        expect(isGeneratedFile('src/app.js', content)).toBe(false);
+    });
+});
@@ -0,0 +1,72 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { isProseFile, tokenKindAt, tokenize } from '../src/genericTokenizer';
+
+function kinds(source: string): Array<[string, string]> {
+    return tokenize(source).map(token => [token.kind, source.substring(token.start, token.end)]);
+}
+
+describe('tokenize', () => {
+    test('should find quoted strings and line and block comments', () => {
+        const source = [
+            'url = "https://api.example.com" # production',
+            "mirror = 'https://mirror.example.com' // fallback",
+            '/* https://old.example.com',
+            '   retired */ x = `https://a.example.com',
+            'https://b.example.com`',
+        ].join('\n');
+
+        expect(kinds(source)).toEqual([
+            ['string', '"https://api.example.com"'],
+            ['comment', '# production'],
+            ['string', "'https://mirror.example.com'"],
+            ['comment', '// fallback'],
+            ['comment', '/* https://old.example.com\n   retired */'],
+            ['string', '`https://a.example.com\nhttps://b.example.com`'],
+        ]);
+    });
+
+    test('should not start comments inside URLs or strings', () => {
+        expect(kinds('see https://example.com/#/route or /path/* now')).toEqual([]);
+        expect(kinds('color = "#fff // not a comment"')).toEqual([['string', '"#fff // not a comment"']]);
+        expect(kinds('call();// done')).toEqual([['comment', '// done']]);
+    });
+
+    test('should handle escapes, apostrophes, and unterminated strings', () => {
+        expect(kinds('s = "say \\"hi\\"" + x')).toEqual([['string', '"say \\"hi\\""']]);
+        expect(kinds("don't # comment")).toEqual([['comment', '# comment']]);
+        expect(kinds('a = "open\nb = 1')).toEqual([['string', '"open']]);
+    });
+});
+
+describe('tokenKindAt', () => {
+    test('should tell whether an offset is in a string, a comment, or neither', () => {
+        const source = 'a = "x" # y';
+        const tokens = tokenize(source);
+
+        expect(tokenKindAt(tokens, 0)).toBe('unknown');
+        expect(tokenKindAt(tokens, 5)).toBe('string');
+        expect(tokenKindAt(tokens, 7)).toBe('unknown');
+        expect(tokenKindAt(tokens, 10)).toBe('comment');
+    });
+});
+
+describe('isProseFile', () => {
+    test('should recognize prose by extension', () => {
+        expect(isProseFile('docs/README.md')).toBe(true);
+        expect(isProseFile('notes.TXT')).toBe(true);
+        expect(isProseFile('build.gradle')).toBe(false);
+    });
+});
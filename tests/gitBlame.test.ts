@@ -0,0 +1,81 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { annotateIntroduced, getIntroducedDate, parseBlamePorcelain } from '../src/gitBlame';
+import { runGit } from '../src/gitMetadata';
+import { URLMatch } from '../src/urlFilter';
+
+const GIT_IDENTITY = ['-c', 'user.name=Test', '-c', 'user.email=test@example.com'];
+const SHA_A = 'a'.repeat(40);
+const SHA_B = 'b'.repeat(40);
+
+function findingOnLine(line: number): URLMatch {
+    return { url: 'http://example.com', start: 0, end: 18, line, column: 1, sourceType: 'string' };
+}
+
+describe('parseBlamePorcelain', () => {
+    test('should map lines to commits and author dates', () => {
+        const output = [
+            `${SHA_A} 1 1 2`,
+            'author Test',
+            'author-time 1577836800',
+            'filename app.js',
+            '\tconst a = 1;',
+            `${SHA_A} 2 2`,
+            '\tconst b = 2;',
+            `${SHA_B} 5 3 1`,
+            'author-time 1735689600',
+            'filename app.js',
+            '\tconst c = 3;',
+            `${'0'.repeat(40)} 4 4 1`,
+            'author-time 1767225600',
+            '\tconst d = 4;',
+        ].join('\n');
+
+        const lines = parseBlamePorcelain(output);
+
+        expect(Array.from(lines.keys())).toEqual([1, 2, 3]);
+        expect(lines.get(2)).toEqual({ commit: SHA_A, date: new Date('2020-01-01T00:00:00Z') });
+        expect(lines.get(3)!.date.toISOString()).toBe('2025-01-01T00:00:00.000Z');
+    });
+});
+
+describe('annotateIntroduced', () => {
+    test('should record the introduction date and commit of committed lines', async () => {
+        const tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-blame-'));
+        const repo = await fs.promises.realpath(tempDir);
+        try {
+            const filePath = path.join(repo, 'app.js');
+            await runGit(repo, ['init', '-q']);
+            await fs.promises.writeFile(filePath, 'fetch("http://old.example.com");\n');
+            await runGit(repo, ['add', 'app.js']);
+            await runGit(repo, [...GIT_IDENTITY, 'commit', '-q', '-m', 'Add app', '--date', '2019-06-01T12:00:00Z']);
+            await fs.promises.appendFile(filePath, 'fetch("http://new.example.com");\n');
+            const commit = (await runGit(repo, ['rev-parse', 'HEAD'])).trim();
+
+            const findings = [findingOnLine(1), findingOnLine(2)];
+            await annotateIntroduced(findings, filePath);
+
+            expect(findings[0].attributes).toEqual({ introduced: '2019-06-01', introducedIn: commit });
+            expect(getIntroducedDate(findings[0])).toEqual(new Date('2019-06-01'));
+            expect(findings[1].attributes).toBeUndefined();
+            expect(getIntroducedDate(findings[1])).toBeUndefined();
+        } finally {
+            await fs.promises.rm(repo, { recursive: true, force: true });
+        }
+    });
+});
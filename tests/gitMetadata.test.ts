@@ -0,0 +1,86 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { collectGitMetadata, runGit } from '../src/gitMetadata';
+
+const GIT_IDENTITY = ['-c', 'user.name=Test', '-c', 'user.email=test@example.com', '-c', 'tag.gpgSign=false'];
+
+describe('collectGitMetadata', () => {
+    let repo: string;
+
+    beforeEach(async () => {
+        repo = await fs.promises.realpath(await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-git-')));
+        await runGit(repo, ['init', '-q']);
+        await fs.promises.writeFile(
+            path.join(repo, '.gitmodules'),
+            '[submodule "lib"]\n\tpath = lib\n\turl = https://github.com/example/lib.git\n',
+        );
+        await runGit(repo, ['add', '.gitmodules']);
+        await runGit(repo, [...GIT_IDENTITY, 'commit', '-q', '-m', 'Add lib\n\nSee https://docs.example.com/lib']);
+        await runGit(repo, [...GIT_IDENTITY, 'tag', '-a', 'v1.0.0', '-m', 'Release notes: https://example.com/v1']);
+        await runGit(repo, ['tag', 'lightweight']);
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(repo, { recursive: true, force: true });
+    });
+
+    test('should collect commit messages, annotated tags, and .gitmodules', async () => {
+        const sources = await collectGitMetadata(repo);
+        const commitHash = (await runGit(repo, ['rev-parse', 'HEAD'])).trim();
+
+        expect(sources.map(source => source.file)).toEqual([
+            `git:commit/${commitHash}`,
+            'git:tag/v1.0.0',
+            path.join(repo, '.gitmodules'),
+        ]);
+        expect(sources[0].content).toContain('https://docs.example.com/lib');
+        expect(sources[1].content).toContain('https://example.com/v1');
+        expect(sources[2].content).toContain('https://github.com/example/lib.git');
+    });
+
+    test('should resolve the repository from a subdirectory', async () => {
+        const subdir = path.join(repo, 'nested');
+        await fs.promises.mkdir(subdir);
+
+        const sources = await collectGitMetadata(subdir);
+
+        expect(sources).toHaveLength(3);
+    });
+
+    test('should only collect the most recent commits up to the history depth', async () => {
+        await runGit(repo, [...GIT_IDENTITY, 'commit', '-q', '--allow-empty', '-m', 'See https://docs.example.com/v2']);
+        const commitHash = (await runGit(repo, ['rev-parse', 'HEAD'])).trim();
+
+        const recent = await collectGitMetadata(repo, 1);
+        const all = await collectGitMetadata(repo, 0);
+
+        expect(recent.filter(source => source.file.startsWith('git:commit/')).map(source => source.file)).toEqual([
+            `git:commit/${commitHash}`,
+        ]);
+        expect(all.filter(source => source.file.startsWith('git:commit/'))).toHaveLength(2);
+    });
+
+    test('should reject directories outside a repository', async () => {
+        const outside = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-nogit-'));
+        try {
+            await expect(collectGitMetadata(outside)).rejects.toThrow();
+        } finally {
+            await fs.promises.rm(outside, { recursive: true, force: true });
+        }
+    });
+});
@@ -0,0 +1,145 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import {
+    PROBLEM_MATCHER,
+    formatProblemLines,
+    formatStepSummary,
+    readActionInputs,
+    shouldFail,
+    writeProblemMatcher,
+    writeStepOutputs,
+    writeStepSummary,
+} from '../src/githubAction';
+import { FileResult } from '../src/urlDetector';
+
+const results: FileResult[] = [
+    {
+        file: 'src/app.ts',
+        urls: [
+            {
+                url: 'http://api.example.com',
+                start: 0,
+                end: 22,
+                line: 3,
+                column: 14,
+                sourceType: 'string',
+                violations: [{ rule: 'scheme-policy', severity: 'warning', message: 'http:// is not allowed' }],
+            },
+            { url: 'https://docs.example.com', start: 40, end: 64, line: 5, column: 1, sourceType: 'comment' },
+        ],
+    },
+    {
+        file: 'web/index.html',
+        urls: [
+            {
+                url: 'https://cdn.example.com/a|b.js',
+                start: 0,
+                end: 30,
+                line: 1,
+                column: 5,
+                sourceType: 'string',
+                violations: [{ rule: 'sri-missing', severity: 'info', message: 'Add an\nintegrity hash' }],
+            },
+        ],
+    },
+];
+
+describe('readActionInputs', () => {
+    test('should read inputs with defaults', () => {
+        expect(
+            readActionInputs({
+                INPUT_SCAN: 'src/**/*\n docs/**/*.md ,\n',
+                INPUT_PROFILE: 'security',
+                'INPUT_FAIL-ON': 'warning',
+            }),
+        ).toEqual({
+            scan: ['src/**/*', 'docs/**/*.md'],
+            exclude: [],
+            config: undefined,
+            profile: 'security',
+            output: undefined,
+            format: 'sarif',
+            failOn: 'warning',
+        });
+        expect(readActionInputs({}).failOn).toBe('error');
+    });
+
+    test('should reject unknown fail-on values', () => {
+        expect(() => readActionInputs({ 'INPUT_FAIL-ON': 'always' })).toThrow('Invalid fail-on input: always');
+    });
+});
+
+describe('problem matcher output', () => {
+    test('should format violations as lines the matcher parses', () => {
+        const lines = formatProblemLines(results);
+        expect(lines).toEqual([
+            'src/app.ts:3:14: warning: http:// is not allowed [scheme-policy]',
+            'web/index.html:1:5: notice: Add an integrity hash [sri-missing]',
+        ]);
+
+        const pattern = PROBLEM_MATCHER.problemMatcher[0].pattern[0];
+        const match = new RegExp(pattern.regexp).exec(lines[0])!;
+        expect([match[pattern.file], match[pattern.line], match[pattern.severity], match[pattern.code]]).toEqual([
+            'src/app.ts',
+            '3',
+            'warning',
+            'scheme-policy',
+        ]);
+    });
+
+    test('should fail on findings or on violations at or above a severity', () => {
+        expect(shouldFail(results, 'none')).toBe(false);
+        expect(shouldFail(results, 'findings')).toBe(true);
+        expect(shouldFail(results, 'warning')).toBe(true);
+        expect(shouldFail(results, 'error')).toBe(false);
+        expect(shouldFail([], 'findings')).toBe(false);
+    });
+});
+
+describe('step summary and outputs', () => {
+    test('should summarize counts, violations, and skipped files in Markdown', () => {
+        const summary = formatStepSummary(results, [{ file: 'logo.png', reason: 'binary' }]);
+        expect(summary).toContain('Scanned 2 file(s) and found 3 URL(s): 0 error(s), 1 warning(s), 1 info.');
+        expect(summary).toContain('| warning | src/app.ts:3 | http://api.example.com | http:// is not allowed |');
+        expect(summary).toContain(
+            '| info | web/index.html:1 | https://cdn.example.com/a\\|b.js | Add an integrity hash |',
+        );
+        expect(summary).toContain('Skipped 1 file(s): 1 binary.');
+    });
+
+    test('should write the summary, outputs, and matcher to the files GitHub names', async () => {
+        const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-action-'));
+        try {
+            const env = {
+                GITHUB_STEP_SUMMARY: path.join(dir, 'summary.md'),
+                GITHUB_OUTPUT: path.join(dir, 'output'),
+                RUNNER_TEMP: dir,
+            };
+            expect(await writeStepSummary('## URL Detector\n', env)).toBe(true);
+            await writeStepOutputs({ findings: 3, report: '' }, env);
+            const matcher = await writeProblemMatcher(env);
+
+            expect(fs.readFileSync(env.GITHUB_STEP_SUMMARY, 'utf8')).toBe('## URL Detector\n');
+            expect(fs.readFileSync(env.GITHUB_OUTPUT, 'utf8')).toBe('findings=3\nreport=\n');
+            expect(JSON.parse(fs.readFileSync(matcher, 'utf8'))).toEqual(PROBLEM_MATCHER);
+            expect(await writeStepSummary('ignored', {})).toBe(false);
+        } finally {
+            fs.rmSync(dir, { recursive: true, force: true });
+        }
+    });
+});
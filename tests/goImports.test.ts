@@ -0,0 +1,132 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import {
+    GO_IMPORT_CATEGORY,
+    GoImportPolicy,
+    createGoImportRules,
+    extractGoImports,
+    extractGoModPaths,
+} from '../src/goImports';
+import { URLMatch } from '../src/urlFilter';
+import { RuleEngine } from '../src/ruleEngine';
+
+const goSource = `package client // import "code.google.com/p/client"
+
+import "fmt"
+import log "github.com/jdoe/logrus"
+
+import (
+    "net/http"
+    yaml "gopkg.in/yaml.v2"
+    _ "github.com/my-org/driver"
+    // "github.com/old/commented"
+)
+`;
+
+const goMod = `module github.com/my-org/service
+
+go 1.22
+
+require github.com/my-org/lib v1.2.3
+
+require (
+    gopkg.in/yaml.v3 v3.0.1
+    golang.org/x/net v0.20.0 // indirect
+)
+
+replace github.com/my-org/lib => github.com/jdoe/lib v1.2.4
+
+replace (
+    golang.org/x/net => ../net
+)
+`;
+
+describe('extractGoImports', () => {
+    test('should extract remote import paths and canonical import comments', () => {
+        const findings = extractGoImports(goSource);
+
+        expect(findings.map(f => [f.url, f.attributes!.goImportKind])).toEqual([
+            ['code.google.com/p/client', 'canonical'],
+            ['github.com/jdoe/logrus', 'import'],
+            ['gopkg.in/yaml.v2', 'import'],
+            ['github.com/my-org/driver', 'import'],
+        ]);
+        expect(findings.every(f => f.category === GO_IMPORT_CATEGORY)).toBe(true);
+    });
+
+    test('should report accurate positions', () => {
+        for (const finding of extractGoImports(goSource)) {
+            expect(goSource.substring(finding.start, finding.end)).toBe(finding.url);
+            const line = goSource.split('\n')[finding.line - 1];
+            expect(line.substr(finding.column - 1, finding.url.length)).toBe(finding.url);
+        }
+    });
+});
+
+describe('extractGoModPaths', () => {
+    test('should extract module, require, and replace paths', () => {
+        const findings = extractGoModPaths(goMod);
+
+        expect(findings.map(f => [f.url, f.attributes!.goImportKind])).toEqual([
+            ['github.com/my-org/service', 'module'],
+            ['github.com/my-org/lib', 'require'],
+            ['gopkg.in/yaml.v3', 'require'],
+            ['golang.org/x/net', 'require'],
+            ['github.com/jdoe/lib', 'replace'],
+        ]);
+        for (const finding of findings) {
+            expect(goMod.substring(finding.start, finding.end)).toBe(finding.url);
+        }
+    });
+});
+
+describe('createGoImportRules', () => {
+    function violationsFor(source: string, policy: GoImportPolicy) {
+        const engine = new RuleEngine();
+        createGoImportRules(policy).forEach(rule => engine.register(rule));
+        const findings = extractGoImports(source);
+        engine.evaluate(findings, { file: 'client.go', language: 'go', content: source });
+        return findings.flatMap(f => (f.violations || []).map(v => `${v.rule}:${f.url}`));
+    }
+
+    test('should flag deprecated hosts by default', () => {
+        expect(violationsFor(goSource, {})).toEqual(['go-deprecated-host:code.google.com/p/client']);
+    });
+
+    test('should flag gopkg.in and personal forks when the policy asks for it', () => {
+        expect(violationsFor(goSource, { forbidGopkgIn: true, allowedOwners: ['My-Org'] })).toEqual([
+            'go-deprecated-host:code.google.com/p/client',
+            'go-personal-fork:github.com/jdoe/logrus',
+            'go-gopkg-in:gopkg.in/yaml.v2',
+        ]);
+    });
+
+    test('should ignore findings outside the go-import category', () => {
+        const engine = new RuleEngine();
+        createGoImportRules({}).forEach(rule => engine.register(rule));
+        const finding: URLMatch = {
+            url: 'code.google.com/p/x',
+            start: 0,
+            end: 19,
+            line: 1,
+            column: 1,
+            sourceType: 'string',
+        };
+
+        engine.evaluate([finding], { file: 'a.js', language: 'javascript', content: '' });
+
+        expect(finding).not.toHaveProperty('violations');
+    });
+});
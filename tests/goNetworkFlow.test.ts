@@ -0,0 +1,132 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { annotateNetworkCalls } from '../src/goNetworkFlow';
+import { URLDetector } from '../src/urlDetector';
+import { URLMatch } from '../src/urlFilter';
+
+const goSource = `package main
+
+import (
+    "net/http"
+    "google.golang.org/grpc"
+)
+
+// Guide: https://docs.example.com/guide
+const docsURL = "https://docs.example.com/inert"
+const apiBase = "https://api.example.com"
+
+type Service struct {
+    baseURL string
+}
+
+func newService() *Service {
+    return &Service{baseURL: "https://service.example.com"}
+}
+
+func target() string {
+    return "orders.example.com:443"
+}
+
+func fetch(url string) (*http.Response, error) {
+    return http.Get(url)
+}
+
+func main() {
+    http.Get("https://direct.example.com/x")
+    req, _ := http.NewRequest("GET", apiBase+"/v2", nil)
+    fetch("https://wrapped.example.com")
+    conn, _ := grpc.Dial(target())
+    client := &http.Client{}
+    client.Post("https://client.example.com", "application/json", nil)
+    http.Head(newService().baseURL)
+    fmt.Println("https://printed.example.com", req, conn)
+}
+`;
+
+function findings(content: string, urls: string[]): URLMatch[] {
+    return urls.map(url => {
+        const start = content.indexOf(url);
+        const comment = content.lastIndexOf('//', start) > content.lastIndexOf('\n', start);
+        const sourceType: URLMatch['sourceType'] = comment ? 'comment' : 'string';
+        return { url, start, end: start + url.length, line: 1, column: 1, sourceType };
+    });
+}
+
+function networkCalls(urls: URLMatch[]): Record<string, string | undefined> {
+    return Object.fromEntries(urls.map(url => [url.url, url.attributes && url.attributes.networkCall]));
+}
+
+describe('annotateNetworkCalls', () => {
+    test('should mark URLs written in the URL argument of a call', () => {
+        const urls = findings(goSource, ['https://direct.example.com/x', 'https://client.example.com']);
+        annotateNetworkCalls(urls, goSource);
+
+        expect(networkCalls(urls)).toEqual({
+            'https://direct.example.com/x': 'http.Get',
+            'https://client.example.com': 'http.Client.Post',
+        });
+    });
+
+    test('should follow constants, fields, function results, and wrappers', () => {
+        const urls = findings(goSource, [
+            'https://api.example.com',
+            'https://service.example.com',
+            'orders.example.com:443',
+            'https://wrapped.example.com',
+        ]);
+        annotateNetworkCalls(urls, goSource);
+
+        expect(networkCalls(urls)).toEqual({
+            'https://api.example.com': 'http.NewRequest',
+            'https://service.example.com': 'http.Head',
+            'orders.example.com:443': 'grpc.Dial',
+            'https://wrapped.example.com': 'http.Get',
+        });
+    });
+
+    test('should mark inert data and leave comments alone', () => {
+        const urls = findings(goSource, [
+            'https://docs.example.com/guide',
+            'https://docs.example.com/inert',
+            'https://printed.example.com',
+        ]);
+        annotateNetworkCalls(urls, goSource);
+
+        expect(networkCalls(urls)).toEqual({
+            'https://docs.example.com/guide': undefined,
+            'https://docs.example.com/inert': 'none',
+            'https://printed.example.com': 'none',
+        });
+    });
+
+    test('should only consider the URL argument of a call', () => {
+        const source = 'func main() {\n    http.Post(endpoint, "https://schemas.example.com/json", body)\n}\n';
+        const urls = findings(source, ['https://schemas.example.com/json']);
+        annotateNetworkCalls(urls, source);
+
+        expect(networkCalls(urls)).toEqual({ 'https://schemas.example.com/json': 'none' });
+    });
+});
+
+describe('URLDetector with goNetworkCalls', () => {
+    test('should annotate Go files only when enabled', async () => {
+        const enabled = await new URLDetector({ goNetworkCalls: true }).scanContent(goSource, 'main.go');
+        const disabled = await new URLDetector().scanContent(goSource, 'main.go');
+
+        const direct = (result: typeof enabled) => result!.urls.find(url => url.url === 'https://direct.example.com/x');
+        expect(direct(enabled)!.attributes).toMatchObject({ networkCall: 'http.Get' });
+        expect((direct(disabled)!.attributes || {}).networkCall).toBeUndefined();
+    });
+});
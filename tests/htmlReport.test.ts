@@ -0,0 +1,113 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { compareFindings, createHtmlReport, escapeHtml } from '../src/htmlReport';
+import { createReport } from '../src/report';
+import { FileResult, URLMatch } from '../src/urlFilter';
+import { finding as stringFinding } from './fixtures';
+
+// Fingerprinted by URL, so the same URL is the same finding in both scans
+function finding(url: string, extra: Partial<URLMatch> = {}): URLMatch {
+    return stringFinding(url, { fingerprint: url, ...extra });
+}
+
+const error = { rule: 'no-plain-http', severity: 'error' as const, message: 'Use https' };
+const warning = { rule: 'open-redirect', severity: 'warning' as const, message: 'Check the <redirect>' };
+
+const previous: FileResult[] = [
+    { file: 'web/app.js', urls: [finding('https://kept.example.com'), finding('http://fixed.example.com')] },
+    { file: 'api/server.py', urls: [finding('https://old.example.com', { violations: [warning] })] },
+];
+const current: FileResult[] = [
+    {
+        file: 'web/app.js',
+        urls: [finding('https://kept.example.com'), finding('http://new.example.com', { violations: [error] })],
+    },
+];
+const teams = { web: 'frontend', api: 'backend' };
+
+describe('compareFindings', () => {
+    test('should mark findings added, removed, or unchanged by fingerprint', () => {
+        const compared = compareFindings(current, previous);
+
+        expect(compared.map(entry => [entry.status, entry.file, entry.finding.url])).toEqual([
+            ['unchanged', 'web/app.js', 'https://kept.example.com'],
+            ['added', 'web/app.js', 'http://new.example.com'],
+            ['removed', 'web/app.js', 'http://fixed.example.com'],
+            ['removed', 'api/server.py', 'https://old.example.com'],
+        ]);
+    });
+
+    test('should match findings without fingerprints by file and URL', () => {
+        const unfingerprinted = (results: FileResult[]): FileResult[] =>
+            results.map(result => ({
+                ...result,
+                urls: result.urls.map(url => ({ ...url, fingerprint: undefined })),
+            }));
+
+        const compared = compareFindings(unfingerprinted(current), unfingerprinted(previous));
+
+        expect(compared.filter(entry => entry.status === 'unchanged').map(entry => entry.finding.url)).toEqual([
+            'https://kept.example.com',
+        ]);
+    });
+});
+
+describe('createHtmlReport', () => {
+    test('should list added findings first with status and severity classes', () => {
+        const html = createHtmlReport(current, undefined, undefined, {
+            previous: createReport(previous),
+            teams,
+        });
+
+        const rows = html.match(/<tr class="[a-z]+">.*?<\/tr>/g)!;
+        expect(rows.map(row => row.match(/class="([a-z]+)"/)![1])).toEqual([
+            'added',
+            'removed',
+            'removed',
+            'unchanged',
+        ]);
+        expect(rows[0]).toContain('<span class="severity error">error</span>');
+        expect(rows[0]).toContain('no-plain-http: Use https');
+        expect(rows[1]).toContain('Check the &lt;redirect&gt;');
+        expect(html).toContain('<th>Status</th>');
+    });
+
+    test('should count new and fixed findings per team', () => {
+        const html = createHtmlReport(current, undefined, undefined, {
+            previous: createReport(previous),
+            teams,
+        });
+
+        expect(html).toContain('<th>Team</th><th>Files</th><th>URLs</th><th>New</th><th>Fixed</th>');
+        expect(html).toContain('<tr><td>frontend</td><td>1</td><td>2</td><td>1</td><td>1</td>');
+        expect(html).toContain('<tr><td>backend</td><td>0</td><td>0</td><td>0</td><td>1</td>');
+    });
+
+    test('should render a single scan without comparison columns', () => {
+        const html = createHtmlReport(current, undefined, undefined, { title: 'Q4 <review>' });
+
+        expect(html.startsWith('<!DOCTYPE html>')).toBe(true);
+        expect(html).toContain('<title>Q4 &lt;review&gt;</title>');
+        expect(html).not.toContain('<th>Status</th>');
+        expect(html).not.toContain('<th>New</th>');
+        expect(html).not.toContain('<script');
+    });
+
+    test('should escape markup in scanned values', () => {
+        expect(escapeHtml(`"><img src=x onerror='alert(1)'>&`)).toBe(
+            '&quot;&gt;&lt;img src=x onerror=&#39;alert(1)&#39;&gt;&amp;',
+        );
+    });
+});
@@ -0,0 +1,64 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { CategoryRules } from '../src/categoryRules';
+import { HostData } from '../src/dataBundle';
+import { hostForms, toAsciiHost, toUnicodeHost } from '../src/idn';
+import { URLFilter } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+describe('host normalization', () => {
+    test('should convert hosts and patterns label by label', () => {
+        expect(toAsciiHost('MÜNCHEN.de')).toBe('xn--mnchen-3ya.de');
+        expect(toAsciiHost('*.münchen.de')).toBe('*.xn--mnchen-3ya.de');
+        expect(toAsciiHost('API.Example.com')).toBe('api.example.com');
+        expect(toUnicodeHost('*.XN--MNCHEN-3YA.de')).toBe('*.münchen.de');
+    });
+
+    test('should list both forms of internationalized hosts only', () => {
+        expect(hostForms('münchen.de')).toEqual(['xn--mnchen-3ya.de', 'münchen.de']);
+        expect(hostForms('xn--mnchen-3ya.de')).toEqual(['xn--mnchen-3ya.de', 'münchen.de']);
+        expect(hostForms('example.com')).toEqual(['example.com']);
+    });
+});
+
+describe('internationalized host matching', () => {
+    const urls = ['https://shop.xn--mnchen-3ya.de/cart', 'https://api.example.com'].map(finding);
+
+    test('should ignore punycode hosts listed in Unicode and the other way around', () => {
+        const unicode = new URLFilter({ ignoreDomains: ['*.münchen.de'] });
+        const punycode = new URLFilter({ ignoreDomains: ['shop.xn--mnchen-3ya.de'] });
+
+        expect(unicode.filterUrls(urls).map(url => url.url)).toEqual(['https://api.example.com']);
+        expect(punycode.filterUrls([finding('//shop.münchen.de/cart')])).toEqual([]);
+    });
+
+    test('should apply category rules and feeds in either form', () => {
+        const rules = new CategoryRules([
+            { host: '*.münchen.de', category: 'partner' },
+            { hostRegex: 'köln\\.de$', category: 'regional' },
+        ]);
+        const hostData = new HostData({
+            createdAt: '2026-01-01T00:00:00.000Z',
+            sources: {},
+            tlds: ['de'],
+            feeds: { partners: ['xn--mnchen-3ya.de', 'köln.de'] },
+        });
+
+        expect(rules.categoryOf('shop.xn--mnchen-3ya.de')).toBe('partner');
+        expect(rules.categoryOf('xn--kln-sna.de')).toBe('regional');
+        expect(hostData.feedsOf('shop.münchen.de')).toEqual(['partners']);
+        expect(hostData.feedsOf('xn--kln-sna.de')).toEqual(['partners']);
+    });
+});
@@ -0,0 +1,83 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { checkIncrease, formatIncreaseAlert, loadBaselineCount, parseIncreaseThreshold } from '../src/increaseAlert';
+import { createReport, writeReport } from '../src/report';
+import { TrendStore } from '../src/trendStore';
+
+describe('parseIncreaseThreshold', () => {
+    test('should parse percentages and finding counts', () => {
+        expect(parseIncreaseThreshold('20%')).toEqual({ value: 20, relative: true });
+        expect(parseIncreaseThreshold(' 12.5% ')).toEqual({ value: 12.5, relative: true });
+        expect(parseIncreaseThreshold('50')).toEqual({ value: 50, relative: false });
+        expect(() => parseIncreaseThreshold('-5%')).toThrow('Invalid increase threshold: -5%');
+        expect(() => parseIncreaseThreshold('lots')).toThrow('Invalid increase threshold');
+    });
+});
+
+describe('checkIncrease', () => {
+    test('should trigger only when growth exceeds the threshold', () => {
+        expect(checkIncrease('scan.json', 100, 120, '20%')).toMatchObject({ percent: 20, triggered: false });
+        expect(checkIncrease('scan.json', 100, 121, '20%')).toMatchObject({ percent: 21, triggered: true });
+        expect(checkIncrease('scan.json', 100, 151, '50').triggered).toBe(true);
+        expect(checkIncrease('scan.json', 100, 40, '0%')).toMatchObject({ increase: -60, triggered: false });
+    });
+
+    test('should trigger on any growth from an empty baseline with a percentage', () => {
+        expect(checkIncrease('scan.json', 0, 1, '500%')).toMatchObject({ percent: null, triggered: true });
+        expect(checkIncrease('scan.json', 0, 0, '20%').triggered).toBe(false);
+        expect(checkIncrease('scan.json', 0, 3, '5').triggered).toBe(false);
+    });
+
+    test('should describe the comparison', () => {
+        expect(formatIncreaseAlert(checkIncrease('scan.json', 200, 500, '20%'))).toBe(
+            'Findings went from 200 to 500 (+300, +150%) since scan.json, exceeds the allowed increase of 20%',
+        );
+    });
+});
+
+describe('loadBaselineCount', () => {
+    let dir: string;
+
+    beforeEach(async () => {
+        dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-alert-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(dir, { recursive: true, force: true });
+    });
+
+    test('should read the finding count of a report', async () => {
+        const file = path.join(dir, 'scan.json');
+        const urlObj = { url: 'https://a.com', start: 0, end: 13, line: 1, column: 1, sourceType: 'string' as const };
+        await writeReport(file, createReport([{ file: 'a.js', urls: [urlObj, urlObj] }]), 'json');
+
+        expect(await loadBaselineCount({ report: file })).toEqual({ baseline: file, count: 2 });
+    });
+
+    test('should use the latest trend snapshot', async () => {
+        const storeFile = path.join(dir, 'trends.json');
+        const store = new TrendStore(storeFile);
+        expect(await loadBaselineCount({ trendStore: storeFile })).toBeNull();
+
+        const snapshot = { bySeverity: {}, byCategory: {} };
+        await store.record({ ...snapshot, key: 'abc', recordedAt: '2026-01-01T00:00:00.000Z', total: 10 });
+        await store.record({ ...snapshot, key: 'def', recordedAt: '2026-01-02T00:00:00.000Z', total: 12 });
+
+        expect(await loadBaselineCount({ trendStore: storeFile })).toEqual({ baseline: `${storeFile}#def`, count: 12 });
+    });
+});
@@ -0,0 +1,52 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { EventEmitter } from 'events';
+import { INTERRUPT_EXIT_CODES, handleInterrupts } from '../src/interrupt';
+import { NullLogger } from '../src/logger';
+
+describe('handleInterrupts', () => {
+    test('should abort on the first signal and exit on the second', () => {
+        const target = new EventEmitter();
+        const exits: number[] = [];
+        const interrupt = handleInterrupts(NullLogger, target, code => exits.push(code));
+
+        expect(interrupt.signal.aborted).toBe(false);
+        expect(interrupt.received()).toBeNull();
+
+        target.emit('SIGTERM');
+        expect(interrupt.signal.aborted).toBe(true);
+        expect(interrupt.received()).toBe('SIGTERM');
+        expect(exits).toEqual([]);
+
+        target.emit('SIGINT');
+        expect(exits).toEqual([INTERRUPT_EXIT_CODES.SIGTERM]);
+        expect(interrupt.received()).toBe('SIGTERM');
+    });
+
+    test('should remove its handlers when disposed', () => {
+        const target = new EventEmitter();
+        const interrupt = handleInterrupts(NullLogger, target, () => {});
+
+        expect(target.listenerCount('SIGINT')).toBe(1);
+        interrupt.dispose();
+
+        expect(target.listenerCount('SIGINT')).toBe(0);
+        expect(target.listenerCount('SIGTERM')).toBe(0);
+    });
+
+    test('should use 128 plus the signal number as exit codes', () => {
+        expect(INTERRUPT_EXIT_CODES).toEqual({ SIGINT: 130, SIGTERM: 143 });
+    });
+});
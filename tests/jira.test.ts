@@ -0,0 +1,91 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as http from 'http';
+import { AddressInfo } from 'net';
+import { createIssueFields, fingerprintLabel, selectJiraFindings, syncJiraIssues } from '../src/jira';
+import { ERROR, sampleResults } from './fixtures';
+
+describe('selectJiraFindings', () => {
+    test('should select untriaged error findings once per fingerprint', () => {
+        const selected = selectJiraFindings(sampleResults());
+
+        expect(selected.map(item => item.finding.fingerprint)).toEqual(['aaa', 'bbb']);
+        expect(selected[0].violations).toEqual([ERROR]);
+    });
+});
+
+describe('createIssueFields', () => {
+    test('should describe the finding and label it with its fingerprint', () => {
+        const [item] = selectJiraFindings(sampleResults());
+
+        const fields = createIssueFields(item, { url: 'https://jira', project: 'SEC', labels: ['audit'] });
+
+        expect(fields.project).toEqual({ key: 'SEC' });
+        expect(fields.issuetype).toEqual({ name: 'Bug' });
+        expect(fields.summary).toBe('no-plain-http: http://api.example.com/v1 in src/app.js:3');
+        expect(fields.labels).toEqual(['url-detector', 'audit', fingerprintLabel('aaa')]);
+        expect(fields.description).toContain('* no-plain-http: Use https');
+        expect(fields.description).toContain('url-detector triage set accepted-risk aaa');
+    });
+});
+
+describe('syncJiraIssues', () => {
+    let server: http.Server;
+    let baseUrl: string;
+    let requests: Array<{ path: string; authorization?: string; body: any }>;
+
+    beforeEach(async () => {
+        requests = [];
+        server = http.createServer((req, res) => {
+            let body = '';
+            req.on('data', chunk => (body += chunk));
+            req.on('end', () => {
+                requests.push({ path: req.url!, authorization: req.headers.authorization, body: JSON.parse(body) });
+                res.writeHead(200, { 'Content-Type': 'application/json' });
+                if (req.url === '/rest/api/2/search') {
+                    // 'aaa' already has an issue
+                    const issue = { fields: { labels: ['url-detector', fingerprintLabel('aaa')] } };
+                    res.end(JSON.stringify({ issues: [issue] }));
+                } else {
+                    res.end(JSON.stringify({ key: `SEC-${requests.length}` }));
+                }
+            });
+        });
+        await new Promise<void>(resolve => server.listen(0, '127.0.0.1', resolve));
+        baseUrl = `http://127.0.0.1:${(server.address() as AddressInfo).port}/`;
+    });
+
+    afterEach(() => {
+        server.close();
+    });
+
+    test('should create issues only for findings without one', async () => {
+        const sync = await syncJiraIssues(sampleResults(), { url: baseUrl, project: 'SEC' }, 'token');
+
+        expect(sync).toEqual({ created: ['SEC-2'], existing: ['aaa'] });
+        expect(requests[0].body.jql).toBe(
+            `project = "SEC" AND labels in ("${fingerprintLabel('aaa')}", "${fingerprintLabel('bbb')}")`,
+        );
+        expect(requests[1].path).toBe('/rest/api/2/issue');
+        expect(requests[1].body.fields.labels).toContain(fingerprintLabel('bbb'));
+        expect(requests[1].authorization).toBe('Bearer token');
+    });
+
+    test('should use basic authentication when a user is configured', async () => {
+        await syncJiraIssues(sampleResults(), { url: baseUrl, project: 'SEC', user: 'bot@example.com' }, 'token');
+
+        expect(requests[0].authorization).toBe(`Basic ${Buffer.from('bot@example.com:token').toString('base64')}`);
+    });
+});
@@ -12,7 +12,11 @@
  * and limitations under the License.
  */
 
-import { LanguageManager } from '../src/languageManager';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { EMBEDDED_GRAMMARS_FILE, LanguageManager, formatGrammarIssue } from '../src/languageManager';
+import { NullLogger } from '../src/logger';
 
 describe('LanguageManager', () => {
     let manager: LanguageManager;
@@ -44,3 +48,44 @@ describe('LanguageManager', () => {
         expect(manager.getLanguage('javascript')).toBeDefined();
     });
 });
+
+describe('LanguageManager.readEmbeddedGrammars', () => {
+    test('should read the slim build manifest and return null for full builds', () => {
+        const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-grammars-'));
+        try {
+            expect(LanguageManager.readEmbeddedGrammars(dir)).toBeNull();
+
+            fs.writeFileSync(path.join(dir, EMBEDDED_GRAMMARS_FILE), JSON.stringify(['tree-sitter-go']));
+            expect(LanguageManager.readEmbeddedGrammars(dir)).toEqual(new Set(['tree-sitter-go']));
+        } finally {
+            fs.rmSync(dir, { recursive: true, force: true });
+        }
+    });
+});
+
+describe('LanguageManager grammar compatibility', () => {
+    test('should record the grammar each language is parsed with', () => {
+        const grammars = new LanguageManager().getLanguageGrammars();
+
+        expect(grammars.javascript).toEqual({ module: 'tree-sitter-javascript', version: expect.any(String) });
+        expect(LanguageManager.getTreeSitterVersion()).not.toBe('unknown');
+    });
+
+    test('should reject a grammar the core library cannot use', () => {
+        const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-grammars-'));
+        try {
+            const module = path.join(dir, 'tree-sitter-bogus.js');
+            fs.writeFileSync(module, 'module.exports = { name: "bogus", language: {} };');
+            const manager = new LanguageManager(NullLogger, [{ name: 'bogus', module, extensions: ['.bogus'] }]);
+
+            expect(manager.getLanguage('.bogus')).toBeUndefined();
+            expect(manager.getLanguageGrammars()).toEqual({});
+            expect(manager.getGrammarIssues()).toEqual([
+                { language: 'bogus', module, version: 'unknown', error: expect.any(String) },
+            ]);
+            expect(formatGrammarIssue(manager.getGrammarIssues()[0])).toContain(`${module} unknown (bogus)`);
+        } finally {
+            fs.rmSync(dir, { recursive: true, force: true });
+        }
+    });
+});
@@ -0,0 +1,97 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { LanguageOptions, applyLanguageOptions, createLanguageExclusion } from '../src/languageOptions';
+import { CONFIG_SCHEMA, validateSchema } from '../src/schema';
+import { URLMatch } from '../src/urlFilter';
+
+function findingsOf(content: string): URLMatch[] {
+    return Array.from(content.matchAll(/https?:\/\/[^\s"'`)]+/g), match => ({
+        url: match[0],
+        start: match.index!,
+        end: match.index! + match[0].length,
+        line: 1,
+        column: 1,
+        sourceType: 'string' as const,
+    }));
+}
+
+function remaining(content: string, filePath: string, language: string, options: LanguageOptions): string[] {
+    return applyLanguageOptions(findingsOf(content), content, filePath, language, options).map(url => url.url);
+}
+
+describe('applyLanguageOptions', () => {
+    const goSource = [
+        '//go:generate curl -o schema.json https://schemas.example.com/v1.json',
+        'type Feed struct {',
+        '\tXMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`',
+        '\tLink string `json:"link"`',
+        '}',
+        'var endpoint = "https://api.example.com/feed"',
+    ].join('\n');
+
+    test('should keep every URL by default', () => {
+        expect(remaining(goSource, 'feed.go', 'go', {})).toHaveLength(3);
+        expect(createLanguageExclusion(goSource, 'feed.go', 'go', { go: { structTags: true } })).toBeNull();
+    });
+
+    test('should drop URLs in Go struct tags and go:generate directives', () => {
+        expect(remaining(goSource, 'feed.go', 'go', { go: { structTags: false } })).toEqual([
+            'https://schemas.example.com/v1.json',
+            'https://api.example.com/feed',
+        ]);
+        expect(remaining(goSource, 'feed.go', 'go', { go: { goGenerate: false } })).toEqual([
+            'http://www.w3.org/2005/Atom',
+            'https://api.example.com/feed',
+        ]);
+    });
+
+    test('should drop URLs in Markdown code', () => {
+        const markdown = [
+            'See https://docs.example.com for details.',
+            '```bash',
+            'curl https://api.example.com/v1',
+            '```',
+            'Or run `open https://localhost.example.com` locally.',
+        ].join('\n');
+
+        expect(remaining(markdown, 'README.md', 'unknown', { markdown: { codeBlocks: false } })).toEqual([
+            'https://docs.example.com',
+        ]);
+    });
+
+    test('should drop URLs in HTML data attributes', () => {
+        const html = '<a href="https://example.com" data-track="https://analytics.example.com/hit">x</a>';
+
+        expect(remaining(html, 'index.html', 'html', { html: { dataAttributes: false } })).toEqual([
+            'https://example.com',
+        ]);
+    });
+
+    test('should only apply settings to their own language', () => {
+        const html = '<div data-src="https://cdn.example.com/a.js"></div>';
+        const code = '`https://example.com`';
+
+        expect(remaining(html, 'page.php', 'php', { html: { dataAttributes: false } })).toHaveLength(1);
+        expect(remaining(code, 'notes.txt', 'unknown', { markdown: { codeBlocks: false } })).toHaveLength(1);
+    });
+});
+
+describe('languageOptions in the config schema', () => {
+    test('should accept known settings and reject unknown ones', () => {
+        expect(validateSchema({ languageOptions: { go: { structTags: false } } }, CONFIG_SCHEMA)).toEqual([]);
+        expect(validateSchema({ languageOptions: { rust: {} } }, CONFIG_SCHEMA)).not.toEqual([]);
+        expect(validateSchema({ languageOptions: { go: { codeBlocks: false } } }, CONFIG_SCHEMA)).not.toEqual([]);
+    });
+});
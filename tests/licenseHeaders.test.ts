@@ -0,0 +1,225 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as http from 'http';
+import { AddressInfo } from 'net';
+import {
+    LICENSE_CATEGORY,
+    checkLicenseLinks,
+    createLicenseUrlRule,
+    findLicenseHeader,
+    matchCanonicalLicenseUrl,
+    selectLicenseHeaderUrls,
+} from '../src/licenseHeaders';
+import { RuleEngine } from '../src/ruleEngine';
+import { URLMatch } from '../src/urlFilter';
+
+function findingsFor(content: string, urls: string[]): URLMatch[] {
+    return urls.map(url => {
+        const start = content.indexOf(url);
+        return { url, start, end: start + url.length, line: 1, column: 1, sourceType: 'comment' };
+    });
+}
+
+describe('findLicenseHeader', () => {
+    test('should find a block comment header after a shebang', () => {
+        const content = '#!/usr/bin/env node\n/*\n * Licensed under the MIT License.\n */\n\nconst x = 1;\n';
+
+        const header = findLicenseHeader(content)!;
+
+        expect(content.substring(header.start, header.end)).toBe('/*\n * Licensed under the MIT License.\n */\n');
+    });
+
+    test('should find line comment headers and the SPDX identifier', () => {
+        const content = '# SPDX-License-Identifier: Apache-2.0\n# Copyright Example Corp\n\nimport os\n';
+
+        expect(findLicenseHeader(content)).toMatchObject({ start: 0, spdxId: 'Apache-2.0' });
+    });
+
+    test('should ignore leading comments that are not license headers', () => {
+        expect(findLicenseHeader('// Utility helpers\nconst x = 1;\n')).toBeNull();
+        expect(findLicenseHeader('const x = 1; // Copyright\n')).toBeNull();
+    });
+});
+
+describe('matchCanonicalLicenseUrl', () => {
+    test.each([
+        ['http://www.apache.org/licenses/LICENSE-2.0.', 'Apache-2.0'],
+        ['https://www.apache.org/licenses/LICENSE-2.0.txt', 'Apache-2.0'],
+        ['http://opensource.org/licenses/MIT', 'MIT'],
+        ['http://mozilla.org/MPL/2.0/', 'MPL-2.0'],
+        ['https://spdx.org/licenses/BSD-3-Clause.html', 'BSD-3-Clause'],
+    ])('should match %s', (url, spdxId) => {
+        expect(matchCanonicalLicenseUrl(url)).toBe(spdxId);
+    });
+
+    test('should not match mutated URLs', () => {
+        expect(matchCanonicalLicenseUrl('http://www.apache.org/licenses/LICENSE-2.O')).toBeNull();
+    });
+});
+
+describe('license URL rule', () => {
+    function evaluate(content: string, urls: string[]): URLMatch[] {
+        const engine = new RuleEngine();
+        engine.register(createLicenseUrlRule());
+        const findings = selectLicenseHeaderUrls(findingsFor(content, urls), content);
+        engine.evaluate(findings, { file: 'app.js', language: 'javascript', content });
+        return findings;
+    }
+
+    test('should accept canonical URLs and tag the license', () => {
+        const content = [
+            '/*',
+            ' * Licensed under http://www.apache.org/licenses/LICENSE-2.0.',
+            ' */',
+            'fetch("https://api.example.com");',
+        ].join('\n');
+
+        const findings = evaluate(content, ['http://www.apache.org/licenses/LICENSE-2.0.', 'https://api.example.com']);
+
+        expect(findings).toHaveLength(1);
+        expect(findings[0].category).toBe(LICENSE_CATEGORY);
+        expect(findings[0].attributes).toEqual({ license: 'Apache-2.0' });
+        expect(findings[0].violations).toBeUndefined();
+    });
+
+    test('should flag mutated and mismatched license URLs', () => {
+        const content = [
+            '// SPDX-License-Identifier: MIT',
+            '// See http://www.apache.org/licenses/LICENSE-2.O and https://opensource.org/licenses/ISC',
+            '// Project home: https://example.com/project',
+            '',
+        ].join('\n');
+
+        const findings = evaluate(content, [
+            'http://www.apache.org/licenses/LICENSE-2.O',
+            'https://opensource.org/licenses/ISC',
+            'https://example.com/project',
+        ]);
+
+        expect(findings.map(f => (f.violations || []).map(v => v.rule))).toEqual([
+            ['license-url-mutated'],
+            ['license-url-mismatch'],
+            [],
+        ]);
+    });
+
+    test('should accept license index pages', () => {
+        const content =
+            '# This program is free software under the GNU General Public License.\n' +
+            '# See <https://www.gnu.org/licenses/>.\n';
+
+        expect(evaluate(content, ['https://www.gnu.org/licenses/'])[0].violations).toBeUndefined();
+    });
+});
+
+describe('checkLicenseLinks', () => {
+    let server: http.Server;
+    let baseUrl: string;
+    let requests: string[];
+
+    beforeAll(async () => {
+        requests = [];
+        server = http.createServer((req, res) => {
+            requests.push(req.url!);
+            res.statusCode = req.url === '/LICENSE' ? 200 : 404;
+            res.end();
+        });
+        await new Promise<void>(resolve => server.listen(0, '127.0.0.1', resolve));
+        baseUrl = `http://127.0.0.1:${(server.address() as AddressInfo).port}`;
+    });
+
+    afterAll(async () => {
+        await new Promise(resolve => server.close(resolve));
+    });
+
+    test('should flag dead license links and request each URL once', async () => {
+        const live: URLMatch = { ...findingsFor('', [`${baseUrl}/LICENSE`])[0], category: LICENSE_CATEGORY };
+        const dead: URLMatch = { ...findingsFor('', [`${baseUrl}/gone`])[0], category: LICENSE_CATEGORY };
+        const duplicate: URLMatch = { ...dead };
+
+        await checkLicenseLinks([{ file: 'a.js', urls: [live, dead] }, { file: 'b.js', urls: [duplicate] }]);
+
+        expect(live.violations).toBeUndefined();
+        expect(dead.violations).toEqual([
+            {
+                rule: 'license-url-dead',
+                severity: 'error',
+                message: `License URL ${baseUrl}/gone is unreachable: HTTP 404`,
+            },
+        ]);
+        expect(duplicate.violations).toHaveLength(1);
+        expect(requests.sort()).toEqual(['/LICENSE', '/gone']);
+    });
+
+    test('should make requests with the injected client', async () => {
+        const requested: string[] = [];
+        const client = {
+            fetch: async (url: string) => {
+                requested.push(url);
+                return new Response(null, { status: 503 });
+            },
+        };
+        const url = 'https://license.example.com/';
+        const finding: URLMatch = { ...findingsFor('', [url])[0], category: LICENSE_CATEGORY };
+
+        await checkLicenseLinks([{ file: 'a.js', urls: [finding] }], 1000, client);
+
+        expect(requested).toEqual([url]);
+        expect(finding.violations![0].message).toContain('HTTP 503');
+    });
+
+    test('should retry with GET when the server does not support HEAD', async () => {
+        const requested: string[] = [];
+        const client = {
+            fetch: async (url: string, init?: RequestInit) => {
+                requested.push(`${init!.method} ${url}`);
+                if (init!.method === 'HEAD') return new Response(null, { status: url.endsWith('/gone') ? 501 : 405 });
+                return new Response('<html></html>', { status: url.endsWith('/gone') ? 404 : 200 });
+            },
+        };
+        const live: URLMatch = { ...findingsFor('', ['https://a.example.com/'])[0], category: LICENSE_CATEGORY };
+        const dead: URLMatch = { ...findingsFor('', ['https://b.example.com/gone'])[0], category: LICENSE_CATEGORY };
+
+        await checkLicenseLinks([{ file: 'a.js', urls: [live, dead] }], 1000, client);
+
+        expect(requested.sort()).toEqual([
+            'GET https://a.example.com/',
+            'GET https://b.example.com/gone',
+            'HEAD https://a.example.com/',
+            'HEAD https://b.example.com/gone',
+        ]);
+        expect(live.violations).toBeUndefined();
+        expect(dead.violations![0].message).toContain('HTTP 404');
+    });
+
+    test('should limit the requests in flight', async () => {
+        let inFlight = 0;
+        let peak = 0;
+        const client = {
+            fetch: async () => {
+                peak = Math.max(peak, ++inFlight);
+                await new Promise(resolve => setTimeout(resolve, 5));
+                inFlight--;
+                return new Response(null, { status: 200 });
+            },
+        };
+        const urls = Array.from({ length: 12 }, (_, i) => `https://license${i}.example.com/`);
+        const findings = findingsFor('', urls).map(finding => ({ ...finding, category: LICENSE_CATEGORY }));
+
+        await checkLicenseLinks([{ file: 'a.js', urls: findings }], 1000, client, 3);
+
+        expect(peak).toBe(3);
+    });
+});
@@ -0,0 +1,131 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import {
+    LifecyclePayload,
+    WEBHOOK_SIGNATURE_HEADER,
+    WebhookConfig,
+    computeLifecycleEvents,
+    formatLifecycleCounts,
+    postLifecycleEvents,
+    recordLifecycle,
+} from '../src/lifecycleEvents';
+import { ScanStore } from '../src/scanStore';
+import { FileResult } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+function scan(...fingerprints: string[]): FileResult[] {
+    return [
+        {
+            file: 'src/app.ts',
+            urls: fingerprints.map(fingerprint => finding(`https://${fingerprint}.example.com`, { fingerprint })),
+        },
+    ];
+}
+
+function types(payload: LifecyclePayload | null): string[] {
+    return payload ? payload.events.map(event => `${event.type} ${event.fingerprint}`) : [];
+}
+
+describe('computeLifecycleEvents', () => {
+    test('should list new, resolved, and reopened findings by fingerprint', () => {
+        const reopened = (fingerprint: string) => fingerprint === 'd';
+        const events = computeLifecycleEvents(scan('a', 'c', 'd', 'd'), scan('a', 'b'), reopened);
+
+        expect(events.map(event => `${event.type} ${event.fingerprint}`)).toEqual([
+            'new c',
+            'resolved b',
+            'reopened d',
+        ]);
+        expect(events[1].finding).toMatchObject({ file: 'src/app.ts', url: 'https://b.example.com' });
+        expect(formatLifecycleCounts(events)).toBe('1 new, 1 resolved, 1 reopened');
+    });
+});
+
+describe('recordLifecycle', () => {
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-lifecycle-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should compare each scan with the previous one of its profile', async () => {
+        const store = new ScanStore(tempDir);
+
+        expect(await recordLifecycle(store, 'default', scan('a', 'b'))).toBeNull();
+        const second = await recordLifecycle(store, 'default', scan('a'));
+        expect(types(second)).toEqual(['resolved b']);
+        expect(await recordLifecycle(store, 'other', scan('x'))).toBeNull();
+        expect(types(await recordLifecycle(store, 'default', scan('a', 'b', 'c')))).toEqual(['new c', 'reopened b']);
+
+        const scans = await store.list('default');
+        expect(scans).toHaveLength(3);
+        expect(second).toMatchObject({ profile: 'default', scanId: scans[1].id, previousScanId: scans[2].id });
+    });
+});
+
+describe('postLifecycleEvents', () => {
+    const payload: LifecyclePayload = {
+        tool: 'url-detector',
+        version: '1.0.0',
+        profile: 'default',
+        scanId: 'current',
+        previousScanId: 'previous',
+        createdAt: '2026-03-02T06:00:00.000Z',
+        events: computeLifecycleEvents(scan('a', 'c'), scan('a', 'b')),
+    };
+
+    test('should post the subscribed events with a signature', async () => {
+        const requests: Array<{ url: string; init: RequestInit }> = [];
+        const client = {
+            fetch: async (url: string, init?: RequestInit) => {
+                requests.push({ url, init: init! });
+                return new Response(null, { status: 204 });
+            },
+        };
+        const webhook: WebhookConfig = {
+            url: 'https://hooks.example.com',
+            events: ['resolved'],
+            headers: { 'X-Team': 'web' },
+        };
+
+        expect(await postLifecycleEvents(payload, webhook, 'secret', client)).toBe(1);
+        expect(await postLifecycleEvents(payload, { ...webhook, events: ['reopened'] }, 'secret', client)).toBe(0);
+
+        expect(requests).toHaveLength(1);
+        const body = requests[0].init.body as string;
+        const headers = requests[0].init.headers as Record<string, string>;
+        expect(JSON.parse(body).events.map((event: { fingerprint: string }) => event.fingerprint)).toEqual(['b']);
+        expect(headers).toMatchObject({ 'X-Team': 'web', 'Content-Type': 'application/json' });
+        expect(headers[WEBHOOK_SIGNATURE_HEADER]).toBe(
+            `sha256=${crypto.createHmac('sha256', 'secret').update(body).digest('hex')}`,
+        );
+    });
+
+    test('should report rejected posts', async () => {
+        const client = { fetch: async () => new Response('nope', { status: 500 }) };
+
+        await expect(postLifecycleEvents(payload, { url: 'https://hooks.example.com' }, '', client)).rejects.toThrow(
+            'Webhook https://hooks.example.com failed: HTTP 500 nope',
+        );
+    });
+});
@@ -0,0 +1,97 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { NotificationLog, notificationState, parseDedupeWindow } from '../src/notificationDedupe';
+import { FileResult } from '../src/urlFilter';
+import { sampleResults } from './fixtures';
+
+const HOUR = 60 * 60 * 1000;
+
+function urlsOf(results: FileResult[]): string[] {
+    return results.flatMap(result => result.urls.map(urlObj => urlObj.url));
+}
+
+describe('parseDedupeWindow', () => {
+    test('should parse minutes, hours, days, and weeks', () => {
+        expect(parseDedupeWindow('30m')).toBe(HOUR / 2);
+        expect(parseDedupeWindow('24h')).toBe(24 * HOUR);
+        expect(parseDedupeWindow(' 7d ')).toBe(7 * 24 * HOUR);
+        expect(parseDedupeWindow('2w')).toBe(14 * 24 * HOUR);
+    });
+
+    test('should reject other durations', () => {
+        expect(() => parseDedupeWindow('0h')).toThrow('Invalid dedupe window: 0h');
+        expect(() => parseDedupeWindow('1.5d')).toThrow('Invalid dedupe window');
+        expect(() => parseDedupeWindow('24')).toThrow('Invalid dedupe window');
+    });
+});
+
+describe('notificationState', () => {
+    test('should combine the triage state and highest severity', () => {
+        const [withViolation, , , triaged, plain] = sampleResults()[0].urls;
+
+        expect(notificationState(withViolation)).toBe('open/error');
+        expect(notificationState(triaged)).toBe('accepted-risk/error');
+        expect(notificationState({ ...plain, attributes: { triage: 'accepted-risk' } })).toBe('accepted-risk/none');
+    });
+});
+
+describe('NotificationLog', () => {
+    let tempDir: string;
+    let log: NotificationLog;
+    const notifiedAt = new Date('2026-10-01T08:00:00Z');
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-notifications-'));
+        log = new NotificationLog(24 * HOUR, path.join(tempDir, 'nested', 'notifications.json'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should hold back notified findings until the window passes', async () => {
+        expect(urlsOf(await log.selectDue(sampleResults(), notifiedAt))).toHaveLength(6);
+        await log.record(sampleResults(), notifiedAt);
+
+        const soon = new Date(notifiedAt.getTime() + 23 * HOUR);
+        const later = new Date(notifiedAt.getTime() + 24 * HOUR);
+        expect(urlsOf(await log.selectDue(sampleResults(), soon))).toEqual(['https://unknown.example.com']);
+        expect(urlsOf(await log.selectDue(sampleResults(), later))).toHaveLength(6);
+    });
+
+    test('should notify again when the state of a finding changes', async () => {
+        await log.record(sampleResults(), notifiedAt);
+        const results = sampleResults();
+        results[0].urls[0].attributes = { triage: 'accepted-risk' };
+
+        const due = await log.selectDue(results, new Date(notifiedAt.getTime() + HOUR));
+
+        expect(urlsOf(due)).toEqual(['http://api.example.com/v1', 'https://unknown.example.com']);
+    });
+
+    test('should leave out files without due findings and prune expired entries', async () => {
+        await log.record(sampleResults(), notifiedAt);
+        const results: FileResult[] = [{ file: 'src/app.js', urls: sampleResults()[0].urls.slice(0, 2) }];
+
+        expect(await log.selectDue(results, new Date(notifiedAt.getTime() + HOUR))).toEqual([]);
+
+        const reviewed = sampleResults()[0].urls.slice(1, 2);
+        await log.record([{ file: 'src/app.js', urls: reviewed }], new Date(notifiedAt.getTime() + 48 * HOUR));
+        expect(Array.from((await log.load()).keys())).toEqual(['bbb']);
+    });
+});
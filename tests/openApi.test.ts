@@ -0,0 +1,113 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { ApiCatalog, createOpenApiRule, parseOpenApiSpec } from '../src/openApi';
+import { finding } from './fixtures';
+
+const payments = {
+    openapi: '3.0.3',
+    info: { title: 'Payments API', 'x-service-name': 'payments' },
+    servers: [
+        { url: 'https://{region}.payments.example.com/v2', variables: { region: { default: 'eu' } } },
+        { url: '/v2' },
+    ],
+    paths: { '/charges': {}, '/charges/{id}': {}, '/charges/{id}/refunds': {} },
+};
+
+const accounts = {
+    swagger: '2.0',
+    info: { title: 'Accounts' },
+    host: 'accounts.example.com',
+    basePath: '/api',
+    schemes: ['https', 'http'],
+    paths: { '/users/{userId}': {} },
+};
+
+describe('parseOpenApiSpec', () => {
+    test('should read servers and paths from OpenAPI 3 and Swagger 2 documents', () => {
+        expect(parseOpenApiSpec(payments, 'payments.json')).toEqual({
+            name: 'payments',
+            servers: ['https://eu.payments.example.com/v2'],
+            paths: ['/charges', '/charges/{id}', '/charges/{id}/refunds'],
+        });
+        expect(parseOpenApiSpec(accounts, 'accounts.json').servers).toEqual([
+            'https://accounts.example.com/api',
+            'http://accounts.example.com/api',
+        ]);
+        expect(() => parseOpenApiSpec({ name: 'package' }, 'package.json')).toThrow('not an OpenAPI document');
+    });
+});
+
+describe('ApiCatalog', () => {
+    const catalog = new ApiCatalog([parseOpenApiSpec(payments, 'a'), parseOpenApiSpec(accounts, 'b')]);
+
+    test('should match URLs to the API and path template serving them', () => {
+        expect(catalog.match('https://eu.payments.example.com/v2/charges/ch_1/refunds?limit=5')).toEqual({
+            api: 'payments',
+            endpoint: '/charges/{id}/refunds',
+        });
+        expect(catalog.match('https://accounts.example.com/api/users/42/')).toEqual({
+            api: 'Accounts',
+            endpoint: '/users/{userId}',
+        });
+    });
+
+    test('should report undeclared paths on known servers and ignore other hosts', () => {
+        const payouts = catalog.match('https://eu.payments.example.com/v2/payouts');
+
+        expect(payouts).toEqual({ api: 'payments', endpoint: null });
+        expect(catalog.match('https://eu.payments.example.com/v1/charges')).toBeNull();
+        expect(catalog.match('https://example.org/v2/charges')).toBeNull();
+    });
+
+    test('should load documents from files', async () => {
+        const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'openapi-'));
+        const file = path.join(dir, 'accounts.json');
+        fs.writeFileSync(file, JSON.stringify(accounts));
+
+        const loaded = await ApiCatalog.load([file]);
+
+        expect(loaded.match('http://accounts.example.com/api/users/7')).toEqual({
+            api: 'Accounts',
+            endpoint: '/users/{userId}',
+        });
+        fs.rmSync(dir, { recursive: true, force: true });
+    });
+});
+
+describe('createOpenApiRule', () => {
+    const rule = createOpenApiRule(new ApiCatalog([parseOpenApiSpec(payments, 'a')]));
+    const file = { file: 'src/pay.ts', language: 'typescript', content: '' };
+
+    test('should tag findings with their API and endpoint', () => {
+        const known = finding('https://eu.payments.example.com/v2/charges');
+
+        expect(rule.evaluate(known, file)).toEqual([]);
+        expect(known.attributes).toEqual({ api: 'payments', endpoint: '/charges' });
+    });
+
+    test('should flag calls to undeclared endpoints', () => {
+        const unknown = finding('https://eu.payments.example.com/v2/payouts');
+
+        const violations = rule.evaluate(unknown, file);
+
+        expect(violations).toHaveLength(1);
+        expect(violations[0].rule).toBe('openapi-unknown-endpoint');
+        expect(violations[0].severity).toBe('warning');
+        expect(unknown.attributes).toEqual({ api: 'payments' });
+    });
+});
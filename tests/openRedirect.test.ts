@@ -0,0 +1,68 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { createOpenRedirectRule, findRedirectParams } from '../src/openRedirect';
+import { finding } from './fixtures';
+
+describe('findRedirectParams', () => {
+    test.each([
+        ['https://example.com/login?next=https://evil.example/', 'next', 'https://evil.example/'],
+        ['https://example.com/sso?id=1&redirect_uri=http%3A%2F%2Fapp.example', 'redirect_uri', 'http://app.example'],
+        ['https://example.com/out?ReturnUrl=https%253A%252F%252Fevil.example', 'ReturnUrl', 'https://evil.example'],
+        ['https://example.com/go?url=//evil.example', 'url', '//evil.example'],
+        ['https://example.com/go?goto=%2F%5Cevil.example', 'goto', '/\\evil.example'],
+    ])('should find the redirect target in %s', (url, name, target) => {
+        expect(findRedirectParams(url)).toEqual([{ name, target }]);
+    });
+
+    test('should ignore relative targets, other parameters, and fragments', () => {
+        expect(findRedirectParams('https://example.com/login?next=/dashboard')).toEqual([]);
+        expect(findRedirectParams('https://example.com/share?text=https://example.org')).toEqual([]);
+        expect(findRedirectParams('https://example.com/#/login?next=https://example.org')).toEqual([]);
+        expect(findRedirectParams('https://example.com/login')).toEqual([]);
+    });
+
+    test('should check only the configured parameters', () => {
+        const url = 'https://example.com/?next=https://a.example&back=https://b.example';
+        expect(findRedirectParams(url, ['back'])).toEqual([{ name: 'back', target: 'https://b.example' }]);
+    });
+});
+
+describe('open redirect rule', () => {
+    const file = { file: 'src/app.ts', language: 'typescript', content: '' };
+
+    test('should warn once per redirect parameter carrying a full URL', () => {
+        const violations = createOpenRedirectRule().evaluate(
+            finding('https://example.com/login?next=https://evil.example&continue=http://other.example'),
+            file,
+        );
+
+        expect(violations).toEqual([
+            {
+                rule: 'open-redirect',
+                severity: 'warning',
+                message:
+                    'Query parameter next carries the full URL https://evil.example; ' +
+                    'make sure the endpoint only redirects to allowed hosts',
+            },
+            expect.objectContaining({ message: expect.stringContaining('continue carries the full URL') }),
+        ]);
+    });
+
+    test('should use the configured parameters', () => {
+        const rule = createOpenRedirectRule(['back']);
+        expect(rule.evaluate(finding('https://example.com/?next=https://evil.example'), file)).toEqual([]);
+        expect(rule.evaluate(finding('https://example.com/?back=https://evil.example'), file)).toHaveLength(1);
+    });
+});
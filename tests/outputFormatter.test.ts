@@ -0,0 +1,123 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { OutputFormatter, OutputFormatterOptions, encodeOutput, escapeNonAscii } from '../src/outputFormatter';
+import { FileResult } from '../src/urlDetector';
+import { Logger } from '../src/logger';
+import { setFindingAttribute } from '../src/urlFilter';
+
+async function render(results: FileResult[], options: OutputFormatterOptions): Promise<string> {
+    const lines: string[] = [];
+    const logger: Logger = {
+        log: (message: string) => lines.push(message),
+        info: () => {},
+        warn: () => {},
+        error: () => {},
+        debug: () => {},
+    };
+    const formatter = new OutputFormatter({ withLineNumbers: true, withFilenames: true, ...options }, logger);
+    await formatter.formatAndOutput(results);
+    return lines.join('\n');
+}
+
+function sampleResults(): FileResult[] {
+    return [
+        {
+            file: 'src/app.js',
+            urls: [
+                {
+                    url: 'https://api.example.com',
+                    start: 0,
+                    end: 23,
+                    line: 2,
+                    column: 7,
+                    sourceType: 'string',
+                    fingerprint: 'abc123',
+                },
+            ],
+        },
+    ];
+}
+
+describe('OutputFormatter', () => {
+    test('should keep the default CSV columns when no finding has attributes', async () => {
+        const csv = await render(sampleResults(), { format: 'csv' });
+
+        expect(csv.split('\n')).toEqual([
+            'FilePath,FileName,LineNumber,ColumnPosition,URL',
+            'src/app.js,app.js,2,7,https://api.example.com',
+        ]);
+    });
+
+    test('should add a Fingerprint column on request', async () => {
+        const csv = await render(sampleResults(), { format: 'csv', csvFingerprints: true });
+
+        expect(csv.split('\n')).toEqual([
+            'FilePath,FileName,LineNumber,ColumnPosition,URL,Fingerprint',
+            'src/app.js,app.js,2,7,https://api.example.com,abc123',
+        ]);
+    });
+
+    test('should add an Attributes column when findings have attributes', async () => {
+        const results = sampleResults();
+        setFindingAttribute(results[0].urls[0], 'owner', 'team-web');
+        setFindingAttribute(results[0].urls[0], 'generated', 'false');
+
+        const csv = await render(results, { format: 'csv' });
+
+        expect(csv.split('\n')).toEqual([
+            'FilePath,FileName,LineNumber,ColumnPosition,URL,Attributes',
+            'src/app.js,app.js,2,7,https://api.example.com,owner=team-web;generated=false',
+        ]);
+    });
+
+    test('should quote CSV cells that spreadsheets would run as formulas', async () => {
+        const results = sampleResults();
+        results[0].file = '=cmd|calc.js';
+        results[0].urls[0].url = '@SUM(1+1)';
+
+        const guarded = await render(results, { format: 'csv' });
+        const raw = await render(results, { format: 'csv', csvFormulaGuard: false });
+
+        expect(guarded.split('\n')[1]).toBe("'=cmd|calc.js,'=cmd|calc.js,2,7,'@SUM(1+1)");
+        expect(raw.split('\n')[1]).toBe('=cmd|calc.js,=cmd|calc.js,2,7,@SUM(1+1)');
+    });
+
+    test('should escape non-ASCII characters in JSON on request', async () => {
+        const results = sampleResults();
+        results[0].urls[0].url = 'https://bücher.example/😀';
+
+        const json = await render(results, { format: 'json', asciiJson: true });
+
+        expect(json).toContain('https://b\\u00fccher.example/\\ud83d\\ude00');
+        expect(/[^\x00-\x7f]/.test(json)).toBe(false);
+        expect(JSON.parse(json).files[0].urls[0].url).toBe('https://bücher.example/😀');
+        expect(escapeNonAscii('"é"')).toBe('"\\u00e9"');
+    });
+
+    test('should encode output files with a byte order mark on request', () => {
+        expect(encodeOutput('a', 'utf8')).toEqual(Buffer.from('a'));
+        expect(encodeOutput('a', 'utf8-bom')).toEqual(Buffer.from([0xef, 0xbb, 0xbf, 0x61]));
+        expect(encodeOutput('a', 'utf16le')).toEqual(Buffer.from([0xff, 0xfe, 0x61, 0x00]));
+    });
+
+    test('should include attributes in JSON output', async () => {
+        const results = sampleResults();
+        setFindingAttribute(results[0].urls[0], 'owner', 'team-web');
+
+        const json = JSON.parse(await render(results, { format: 'json' }));
+
+        expect(json.files[0].urls[0].attributes).toEqual({ owner: 'team-web' });
+    });
+});
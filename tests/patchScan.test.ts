@@ -0,0 +1,106 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { parsePatch, patchedContent } from '../src/patchScan';
+
+describe('parsePatch', () => {
+    test('should read added and context lines at their new-file line numbers', () => {
+        const [file] = parsePatch(
+            [
+                'diff --git a/src/app.js b/src/app.js',
+                'index 1234567..89abcde 100644',
+                '--- a/src/app.js',
+                '+++ b/src/app.js',
+                '@@ -3,3 +3,3 @@ function main() {',
+                ' const a = 1;',
+                "-fetch('http://old.example.com');",
+                "+fetch('https://new.example.com');",
+                ' return a;',
+                '@@ -20 +20,2 @@',
+                ' }',
+                '+// https://docs.example.com',
+            ].join('\n'),
+        );
+
+        expect(file.path).toBe('src/app.js');
+        expect(file.oldPath).toBe('src/app.js');
+        expect(Array.from(file.added)).toEqual([
+            [4, "fetch('https://new.example.com');"],
+            [21, '// https://docs.example.com'],
+        ]);
+        expect(Array.from(file.lines.keys())).toEqual([3, 4, 5, 20, 21]);
+    });
+
+    test('should skip mail headers, deleted files, and removed lines that look like headers', () => {
+        const files = parsePatch(
+            [
+                'From 1234567 Mon Sep 17 00:00:00 2001',
+                'Subject: [PATCH] Move endpoints',
+                '---',
+                ' 2 files changed',
+                '',
+                'diff --git a/old.txt b/old.txt',
+                'deleted file mode 100644',
+                '--- a/old.txt',
+                '+++ /dev/null',
+                '@@ -1,2 +0,0 @@',
+                '--- not a header',
+                '-https://gone.example.com',
+                'diff --git a/new.txt b/new.txt',
+                'new file mode 100644',
+                '--- /dev/null',
+                '+++ b/new.txt',
+                '@@ -0,0 +1 @@',
+                '+https://added.example.com',
+                '\\ No newline at end of file',
+                '-- ',
+                '2.43.0',
+            ].join('\n'),
+        );
+
+        expect(files).toHaveLength(1);
+        expect(files[0].path).toBe('new.txt');
+        expect(files[0].oldPath).toBeNull();
+        expect(Array.from(files[0].added)).toEqual([[1, 'https://added.example.com']]);
+    });
+
+    test('should read diff -u headers with timestamps and quoted git paths', () => {
+        const files = parsePatch(
+            [
+                '--- config.orig\t2026-01-01 10:00:00.000000000 +0000',
+                '+++ config\t2026-01-02 10:00:00.000000000 +0000',
+                '@@ -1 +1 @@',
+                '-url=http://a.example.com',
+                '+url=https://a.example.com',
+                'diff --git "a/my docs/notes.md" "b/my docs/notes.md"',
+                '--- "a/my docs/notes.md"',
+                '+++ "b/my docs/notes.md"',
+                'Binary files differ',
+            ].join('\n'),
+        );
+
+        expect(files.map(file => [file.path, file.oldPath, file.added.size])).toEqual([
+            ['config', 'config.orig', 1],
+            ['my docs/notes.md', 'my docs/notes.md', 0],
+        ]);
+    });
+});
+
+describe('patchedContent', () => {
+    test('should place the known lines at their line numbers', () => {
+        const [file] = parsePatch(['--- a/a.txt', '+++ b/a.txt', '@@ -2,1 +2,2 @@', ' two', '+three'].join('\n'));
+
+        expect(patchedContent(file)).toBe('\ntwo\nthree');
+    });
+});
@@ -13,7 +13,14 @@
  */
 
 // @ts-nocheck
-const { generatePkgConfig, getTreeSitterDependencies } = require('../scripts/generate-pkg-config.js');
+const {
+  generatePkgConfig,
+  getTreeSitterDependencies,
+  parseLanguagesYaml,
+  resolveLanguageSelection,
+  selectGrammarModules
+} = require('../scripts/generate-pkg-config.js');
+const { LanguageManager } = require('../src/languageManager');
 
 describe('PKG Configuration Validation', () => {
   test('should find all tree-sitter dependencies', () => {
@@ -45,5 +52,30 @@ describe('PKG Configuration Validation', () => {
     });
   });
 
+  test('should embed only the selected grammars in slim builds', () => {
+    const modules = selectGrammarModules(['go', 'yaml'], LanguageManager.getDefaultLanguages());
+    const config = generatePkgConfig('linux', modules);
+
+    expect(modules).toEqual(['@tree-sitter-grammars/tree-sitter-yaml', 'tree-sitter-go']);
+    expect(config.assets).toContain('node_modules/tree-sitter/index.js');
+    expect(config.assets).toContain('node_modules/tree-sitter-go/index.js');
+    expect(config.assets).not.toContain('node_modules/tree-sitter-javascript/index.js');
+    expect(config.assets).toContain('dist/embedded-grammars.json');
+    expect(generatePkgConfig('linux').assets).not.toContain('dist/embedded-grammars.json');
+  });
+
+  test('should read the language selection from the environment or languages.yaml', () => {
+    expect(parseLanguagesYaml('languages:\n  - go\n  - "Python" # backend\n')).toEqual(['go', 'python']);
+    expect(parseLanguagesYaml('languages: [go, json]')).toEqual(['go', 'json']);
+    const env = { URL_DETECTOR_LANGUAGES: 'go, bash' };
+    expect(resolveLanguageSelection(env, '/nonexistent.yaml')).toEqual(['go', 'bash']);
+    expect(resolveLanguageSelection({}, '/nonexistent.yaml')).toBeNull();
+  });
+
+  test('should reject unknown languages', () => {
+    const languages = LanguageManager.getDefaultLanguages();
+
+    expect(() => selectGrammarModules(['rust'], languages)).toThrow('Unknown language "rust"');
+  });
 
 });
@@ -0,0 +1,146 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { Logger } from '../src/logger';
+import { PluginError, loadRulePlugin } from '../src/pluginSandbox';
+import { FileContext } from '../src/ruleEngine';
+import { URLDetector } from '../src/urlDetector';
+import { URLMatch } from '../src/urlFilter';
+
+const finding: URLMatch = {
+    url: 'https://api.corp.example.com',
+    start: 0,
+    end: 28,
+    line: 1,
+    column: 1,
+    sourceType: 'string',
+};
+const file: FileContext = {
+    file: 'web/app.js',
+    language: 'javascript',
+    content: 'fetch("https://api.corp.example.com");',
+};
+
+describe('loadRulePlugin', () => {
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-plugin-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    function plugin(name: string, source: string): string {
+        const filename = path.join(tempDir, name);
+        fs.writeFileSync(filename, source);
+        return filename;
+    }
+
+    test('should evaluate plugin rules with the finding and file', () => {
+        const module = plugin(
+            'internal-hosts.js',
+            `module.exports = { rules: [{
+                id: 'internal-host',
+                description: 'No internal hosts in client code',
+                evaluate: (finding, file) => finding.url.includes('.corp.') && file.file.startsWith('web/')
+                    ? [{ severity: 'error', message: 'Internal host in ' + file.content.length + ' bytes' }]
+                    : [],
+            }] };`,
+        );
+        const [rule] = loadRulePlugin({ module });
+
+        expect(rule).toMatchObject({ id: 'internal-host', description: 'No internal hosts in client code' });
+        expect(rule.evaluate(finding, file)).toEqual([
+            { rule: 'internal-host', severity: 'error', message: `Internal host in ${file.content.length} bytes` },
+        ]);
+        expect(rule.evaluate({ ...finding, url: 'https://example.com' }, file)).toEqual([]);
+    });
+
+    test('should disable a plugin that breaks its time limit', () => {
+        const module = plugin('loop.js', `module.exports = [{ id: 'loop', evaluate: () => { for (;;) {} } }];`);
+        const [rule] = loadRulePlugin({ module, timeoutMs: 50 });
+
+        expect(() => rule.evaluate(finding, file)).toThrow(
+            `Plugin ${module} exceeded its 50ms time limit; its rules are disabled for the rest of the scan`,
+        );
+        expect(rule.evaluate(finding, file)).toEqual([]);
+    });
+
+    test('should only offer the modules of granted capabilities', () => {
+        const source = `module.exports = [{ id: 'reader', evaluate: () => {
+            require('fs');
+            return [{ severity: 'info', message: 'read' }];
+        } }];`;
+        const [denied] = loadRulePlugin({ module: plugin('denied.js', source) });
+        const [granted] = loadRulePlugin({ module: plugin('granted.js', source), capabilities: ['filesystem'] });
+
+        expect(() => denied.evaluate(finding, file)).toThrow(PluginError);
+        expect(() => denied.evaluate(finding, file)).toThrow('Module fs is not available without a capability');
+        expect(granted.evaluate(finding, file)).toEqual([{ rule: 'reader', severity: 'info', message: 'read' }]);
+    });
+
+    test('should keep plugins away from the host process', () => {
+        const module = plugin(
+            'escape.js',
+            `module.exports = [{ id: 'escape', evaluate: () => [{
+                severity: 'info',
+                message: typeof process + ' ' + typeof this.constructor.constructor('return process')(),
+            }] }];`,
+        );
+        const [rule] = loadRulePlugin({ module });
+
+        expect(() => rule.evaluate(finding, file)).toThrow('Code generation from strings disallowed');
+    });
+
+    test('should reject malformed violations and plugins without rules', () => {
+        const module = plugin('bad.js', `module.exports = [{ id: 'bad', evaluate: () => [{ severity: 'fatal' }] }];`);
+        const [rule] = loadRulePlugin({ module });
+
+        expect(() => rule.evaluate(finding, file)).toThrow(`Plugin ${module} rule bad returned a malformed violation`);
+        expect(() => loadRulePlugin({ module: plugin('empty.js', 'module.exports = {};') })).toThrow(
+            'does not export an array of rules or { rules }',
+        );
+        expect(() => loadRulePlugin({ module: path.join(tempDir, 'missing.js') })).toThrow('could not be read');
+    });
+
+    test('should report plugin failures without failing the scan', async () => {
+        const warnings: string[] = [];
+        const logger: Logger = {
+            log: () => {},
+            info: () => {},
+            warn: (message: string) => warnings.push(message),
+            error: () => {},
+            debug: () => {},
+        };
+        const throwing = plugin(
+            'throwing.js',
+            `module.exports = [{ id: 'throwing', evaluate: () => { throw new Error('boom'); } }];`,
+        );
+        const missing = path.join(tempDir, 'missing.js');
+        const detector = new URLDetector({ rulePlugins: [{ module: throwing }, { module: missing }] }, logger);
+
+        const result = await detector.scanContent('fetch("https://api.example.com");', 'src/app.js');
+
+        expect(result!.urls.map(url => url.url)).toEqual(['https://api.example.com']);
+        expect(warnings).toEqual([
+            `Plugin ${missing} could not be read: Cannot find module '${missing}'; skipping its rules`,
+            `Rule throwing failed on src/app.js: Plugin ${throwing} failed: boom`,
+        ]);
+    });
+});
@@ -0,0 +1,76 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { buildPortInventory, getNonStandardPort } from '../src/portInventory';
+import { FileResult, URLMatch } from '../src/urlFilter';
+
+function resultFor(file: string, urls: string[], extra: Partial<URLMatch> = {}): FileResult {
+    return {
+        file,
+        urls: urls.map((url, index) => ({
+            url,
+            start: 0,
+            end: url.length,
+            line: index + 1,
+            column: 1,
+            sourceType: 'string',
+            ...extra,
+        })),
+    };
+}
+
+describe('getNonStandardPort', () => {
+    test('should ignore default ports and URLs without a port', () => {
+        expect(getNonStandardPort('https://API.example.com:9443/v1')).toEqual({
+            scheme: 'https',
+            host: 'api.example.com',
+            port: 9443,
+        });
+        expect(getNonStandardPort('//cdn.example.com:8443/lib.js')).toEqual({
+            scheme: 'https',
+            host: 'cdn.example.com',
+            port: 8443,
+        });
+        expect(getNonStandardPort('https://api.example.com:443/v1')).toBeNull();
+        expect(getNonStandardPort('http://api.example.com/v1')).toBeNull();
+        expect(getNonStandardPort('not a url')).toBeNull();
+    });
+});
+
+describe('buildPortInventory', () => {
+    test('should aggregate ports by host across files', () => {
+        const results = [
+            resultFor('src/a.ts', ['http://build.example.com:8080/job', 'https://build.example.com:8080/api']),
+            resultFor('src/b.ts', ['http://build.example.com:8080/job', 'https://api.example.com:8443']),
+            resultFor('src/c.ts', ['https://api.example.com/v1']),
+        ];
+
+        expect(buildPortInventory(results)).toEqual([
+            {
+                host: 'build.example.com',
+                port: 8080,
+                schemes: ['http', 'https'],
+                count: 3,
+                files: ['src/a.ts', 'src/b.ts'],
+            },
+            { host: 'api.example.com', port: 8443, schemes: ['https'], count: 1, files: ['src/b.ts'] },
+        ]);
+    });
+
+    test('should skip findings in built-in categories', () => {
+        const results = [resultFor('web/index.html', ['http://localhost:3000/app'], { category: 'doc-link' })];
+
+        expect(buildPortInventory(results)).toEqual([]);
+    });
+});
@@ -0,0 +1,35 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { BUILT_IN_PROFILES, PROFILE_NAMES, getProfile } from '../src/profiles';
+import { CONFIG_SCHEMA, validateSchema } from '../src/schema';
+
+describe('profiles', () => {
+    test('should look up built-in profiles by name', () => {
+        expect(PROFILE_NAMES).toEqual(['security', 'link-check', 'inventory']);
+        expect(getProfile('security').options.format).toBe('sarif');
+        expect(() => getProfile('paranoid')).toThrow('Unknown profile: paranoid');
+        expect(() => getProfile('toString')).toThrow('Unknown profile');
+    });
+
+    test('should only set detector options the config schema accepts, besides report sections', () => {
+        const sections = ['reachabilityMatrix', 'duplicateEndpoints', 'portInventory', 'reportCoverage', 'groupBy'];
+        for (const name of PROFILE_NAMES) {
+            const options = Object.fromEntries(
+                Object.entries(BUILT_IN_PROFILES[name].options).filter(([key]) => !sections.includes(key)),
+            );
+            expect(validateSchema(options, CONFIG_SCHEMA)).toEqual([]);
+        }
+    });
+});
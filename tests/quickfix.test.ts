@@ -0,0 +1,171 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { createPatchset, dropSuppressed, formatUnifiedDiff, planQuickfix } from '../src/quickfix';
+import { URLDetector } from '../src/urlDetector';
+import { URLMatch } from '../src/urlFilter';
+
+function findingIn(content: string, url: string, rule?: string, attributes?: Record<string, string>): URLMatch {
+    const start = content.indexOf(url);
+    const finding: URLMatch = { url, start, end: start + url.length, line: 1, column: 1, sourceType: 'string' };
+    if (rule) finding.violations = [{ rule, severity: 'error', message: 'Not allowed' }];
+    if (attributes) finding.attributes = attributes;
+    return finding;
+}
+
+describe('planQuickfix', () => {
+    const content = 'const api = "http://api.old.example.com/v1";\n';
+
+    test('should upgrade the scheme of URLs flagged by the scheme policy', () => {
+        const fix = planQuickfix(findingIn(content, 'http://api.old.example.com/v1', 'scheme-policy'), content, 'a.ts');
+
+        expect(fix).toMatchObject({ kinds: ['https-upgrade'], edit: { text: 'https://api.old.example.com/v1' } });
+    });
+
+    test('should map hosts and combine the mapping with an upgrade', () => {
+        const options = { domainMap: { 'API.old.example.com': 'api.example.com' } };
+        const mapped = planQuickfix(findingIn(content, 'http://api.old.example.com/v1'), content, 'a.ts', options);
+        const flagged = findingIn(content, 'http://api.old.example.com/v1', 'scheme-policy');
+
+        expect(mapped).toMatchObject({ kinds: ['domain-mapping'], edit: { text: 'http://api.example.com/v1' } });
+        expect(planQuickfix(flagged, content, 'a.ts', options)).toMatchObject({
+            kinds: ['domain-mapping', 'https-upgrade'],
+            edit: { text: 'https://api.example.com/v1' },
+        });
+    });
+
+    test('should prefer the replacement of a deprecated endpoint', () => {
+        const finding = findingIn(content, 'http://api.old.example.com/v1', 'deprecated-endpoint', {
+            replacement: 'https://api.example.com/v2',
+        });
+
+        expect(planQuickfix(finding, content, 'a.ts')).toMatchObject({
+            kinds: ['deprecation-replacement'],
+            edit: { text: 'https://api.example.com/v2' },
+        });
+    });
+
+    test('should suppress other violations in the comment syntax of the file', () => {
+        const python = 'def f():\r\n    return "http://10.0.0.1/admin"\r\n';
+        const finding = findingIn(python, 'http://10.0.0.1/admin', 'obfuscated-host');
+        const start = python.indexOf('    return');
+
+        expect(planQuickfix(finding, python, 'app.py')).toEqual({
+            kinds: ['suppression'],
+            url: 'http://10.0.0.1/admin',
+            edit: { start, end: start, text: '    # url-detector-ignore-next-line\r\n' },
+        });
+        expect(planQuickfix(finding, python, 'data.json')).toBeNull();
+    });
+
+    test('should leave findings without violations or with stale offsets alone', () => {
+        expect(planQuickfix(findingIn(content, 'http://api.old.example.com/v1'), content, 'a.ts')).toBeNull();
+        expect(planQuickfix(findingIn(content, 'http://api.old.example.com/v1'), 'changed', 'a.ts')).toBeNull();
+    });
+});
+
+describe('dropSuppressed', () => {
+    test('should drop findings on the line after the marker', () => {
+        const content = [
+            '// url-detector-ignore-next-line',
+            'a = "http://one.example.com"',
+            'b = "http://two.example.com"',
+        ].join('\n');
+        const urls = ['http://one.example.com', 'http://two.example.com'].map(url => findingIn(content, url));
+
+        expect(dropSuppressed(urls, content).map(url => url.url)).toEqual(['http://two.example.com']);
+    });
+
+    test('should be honored by scans', async () => {
+        const source = [
+            'const a = "https://kept.example.com";',
+            '// url-detector-ignore-next-line',
+            'const b = "https://dropped.example.com";',
+        ].join('\n');
+        const result = await new URLDetector().scanContent(source, 'app.js');
+
+        expect(result!.urls.map(url => url.url)).toEqual(['https://kept.example.com']);
+    });
+});
+
+describe('formatUnifiedDiff', () => {
+    test('should emit one hunk per group of nearby changes', () => {
+        const content = Array.from({ length: 12 }, (_, i) => `line ${i + 1}\n`).join('');
+        const at = (text: string) => content.indexOf(text);
+        const diff = formatUnifiedDiff('src/a.txt', content, [
+            { start: at('line 2'), end: at('line 2') + 4, text: 'LINE' },
+            { start: at('line 12'), end: at('line 12'), text: '# note\n' },
+        ]);
+
+        expect(diff).toBe(
+            [
+                'diff --git a/src/a.txt b/src/a.txt',
+                '--- a/src/a.txt',
+                '+++ b/src/a.txt',
+                '@@ -1,5 +1,5 @@',
+                ' line 1',
+                '-line 2',
+                '+LINE 2',
+                ' line 3',
+                ' line 4',
+                ' line 5',
+                '@@ -9,4 +9,5 @@',
+                ' line 9',
+                ' line 10',
+                ' line 11',
+                '-line 12',
+                '+# note',
+                '+line 12',
+                '',
+            ].join('\n'),
+        );
+    });
+
+    test('should mark a last line without a line break', () => {
+        const diff = formatUnifiedDiff('a.txt', 'http://x', [{ start: 0, end: 4, text: 'https' }]);
+
+        expect(diff).toContain('-http://x\n\\ No newline at end of file\n+https://x\n\\ No newline at end of file\n');
+    });
+});
+
+describe('createPatchset', () => {
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-patchset-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should patch each file with fixes and skip the rest', async () => {
+        const file = path.join(tempDir, 'client.ts');
+        const content = 'export const API = "http://api.example.com";\n';
+        await fs.promises.writeFile(file, content);
+
+        const patchset = await createPatchset([
+            { file, urls: [findingIn(content, 'http://api.example.com', 'scheme-policy')] },
+            { file: path.join(tempDir, 'clean.ts'), urls: [] },
+            { file: path.join(tempDir, 'missing.ts'), urls: [findingIn(content, 'http://api.example.com')] },
+        ]);
+
+        expect(patchset).toContain('+export const API = "https://api.example.com";\n');
+        expect(patchset.match(/^diff --git/gm)).toHaveLength(1);
+        expect(await createPatchset([{ file, urls: [findingIn(content, 'http://api.example.com')] }])).toBe('');
+    });
+});
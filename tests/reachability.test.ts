@@ -0,0 +1,147 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as http from 'http';
+import { AddressInfo, LookupFunction } from 'net';
+import { buildReachabilityMatrix, probeUrl } from '../src/reachability';
+import { FileResult } from '../src/urlFilter';
+
+async function listen(server: http.Server): Promise<string> {
+    await new Promise<void>(resolve => server.listen(0, '127.0.0.1', resolve));
+    return `http://127.0.0.1:${(server.address() as AddressInfo).port}`;
+}
+
+describe('probeUrl', () => {
+    let target: http.Server;
+    let proxy: http.Server;
+    let targetUrl: string;
+    let proxyUrl: string;
+    let proxied: Array<{ method?: string; url?: string; authorization?: string }>;
+
+    beforeEach(async () => {
+        proxied = [];
+        target = http.createServer((req, res) => {
+            res.writeHead(req.url === '/missing' ? 404 : 200);
+            res.end();
+        });
+        // Forwards absolute-form requests to the target and refuses every CONNECT tunnel
+        proxy = http.createServer((req, res) => {
+            proxied.push({ method: req.method, url: req.url, authorization: req.headers['proxy-authorization'] });
+            const upstream = http.request(req.url!, { method: req.method }, response => {
+                res.writeHead(response.statusCode!);
+                response.pipe(res);
+            });
+            upstream.end();
+        });
+        proxy.on('connect', (req, socket) => {
+            proxied.push({ method: req.method, url: req.url });
+            socket.end('HTTP/1.1 403 Forbidden\r\n\r\n');
+        });
+        targetUrl = await listen(target);
+        proxyUrl = await listen(proxy);
+    });
+
+    afterEach(() => {
+        target.close();
+        proxy.close();
+    });
+
+    test('should treat any HTTP response as reachable', async () => {
+        expect(await probeUrl(`${targetUrl}/`)).toEqual({ reachable: true, status: 200 });
+        expect(await probeUrl(`${targetUrl}/missing`)).toEqual({ reachable: true, status: 404 });
+    });
+
+    test('should send plain HTTP requests through the proxy with credentials', async () => {
+        const withCredentials = proxyUrl.replace('http://', 'http://user:p%40ss@');
+
+        expect(await probeUrl(`${targetUrl}/missing`, withCredentials)).toEqual({ reachable: true, status: 404 });
+        expect(proxied).toEqual([
+            {
+                method: 'HEAD',
+                url: `${targetUrl}/missing`,
+                authorization: `Basic ${Buffer.from('user:p@ss').toString('base64')}`,
+            },
+        ]);
+    });
+
+    test('should resolve hosts with the injected DNS lookup', async () => {
+        const port = new URL(targetUrl).port;
+        const resolved: string[] = [];
+        const lookup: LookupFunction = (hostname, options, callback) => {
+            resolved.push(hostname);
+            if (options.all) callback(null, [{ address: '127.0.0.1', family: 4 }]);
+            else callback(null, '127.0.0.1', 4);
+        };
+
+        const probe = await probeUrl(`http://api.internal.test:${port}/`, undefined, 10000, { fetch, lookup });
+
+        expect(probe).toEqual({ reachable: true, status: 200 });
+        expect(resolved).toEqual(['api.internal.test']);
+    });
+
+    test('should report refused CONNECT tunnels for HTTPS URLs', async () => {
+        const probe = await probeUrl('https://secure.example.com/api', proxyUrl);
+
+        expect(probe).toEqual({ reachable: false, error: 'Proxy refused CONNECT: HTTP 403' });
+        expect(proxied).toEqual([{ method: 'CONNECT', url: 'secure.example.com:443' }]);
+    });
+
+    test('should report connection failures and timeouts', async () => {
+        const silent = http.createServer(() => {
+            // Never responds
+        });
+        const silentUrl = await listen(silent);
+        try {
+            expect(await probeUrl(`${silentUrl}/`, undefined, 100)).toEqual({
+                reachable: false,
+                error: 'Timed out after 100ms',
+            });
+        } finally {
+            silent.close();
+        }
+
+        target.close();
+        const probe = await probeUrl(`${targetUrl}/`);
+        expect(probe.reachable).toBe(false);
+        expect(probe.error).toContain('ECONNREFUSED');
+    });
+});
+
+describe('buildReachabilityMatrix', () => {
+    test('should probe each distinct http(s) URL through every egress', async () => {
+        const target = http.createServer((req, res) => res.end());
+        const targetUrl = await listen(target);
+        const finding = { start: 0, end: 10, line: 1, column: 1, sourceType: 'string' as const };
+        const results: FileResult[] = [
+            {
+                file: 'a.js',
+                urls: [
+                    { ...finding, url: `${targetUrl}/a` },
+                    { ...finding, url: 'ftp://files.example.com' },
+                ],
+            },
+            { file: 'b.js', urls: [{ ...finding, url: `${targetUrl}/a` }] },
+        ];
+
+        try {
+            const matrix = await buildReachabilityMatrix(results, { direct: '', blocked: 'http://127.0.0.1:1' }, 1000);
+
+            expect(matrix.map(entry => entry.url)).toEqual([`${targetUrl}/a`]);
+            expect(matrix[0].results.direct).toEqual({ reachable: true, status: 200 });
+            expect(matrix[0].results.blocked.reachable).toBe(false);
+        } finally {
+            target.close();
+        }
+    });
+});
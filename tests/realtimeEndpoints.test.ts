@@ -0,0 +1,121 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { CategoryRules } from '../src/categoryRules';
+import {
+    REALTIME_RPC_CATEGORY,
+    classifyRealtimeEndpoint,
+    extractGrpcTargets,
+    mergeGrpcTargets,
+} from '../src/realtimeEndpoints';
+import { URLDetector } from '../src/urlDetector';
+import { URLFilter, URLMatch } from '../src/urlFilter';
+
+function findingAt(content: string, url: string): URLMatch {
+    const start = content.indexOf(url);
+    return { url, start, end: start + url.length, line: 1, column: start + 1, sourceType: 'string' };
+}
+
+describe('classifyRealtimeEndpoint', () => {
+    test('should classify WebSocket URLs and gRPC DNS targets by the scheme in front of them', () => {
+        const content = 'a = "wss://live.example.com/feed"; b = "dns:///rpc.example.com:443"; c = "//cdn.example.com"';
+        const websocket = findingAt(content, '//live.example.com/feed');
+        const grpc = findingAt(content, '//rpc.example.com:443');
+        const plain = findingAt(content, '//cdn.example.com');
+
+        [websocket, grpc, plain].forEach(finding => classifyRealtimeEndpoint(finding, content));
+
+        expect(websocket).toMatchObject({ category: REALTIME_RPC_CATEGORY, attributes: { protocol: 'websocket' } });
+        expect(grpc).toMatchObject({ category: REALTIME_RPC_CATEGORY, attributes: { protocol: 'grpc' } });
+        expect(plain.category).toBeUndefined();
+    });
+
+    test('should leave categorized findings alone', () => {
+        const finding = { ...findingAt('ws://host.example.com', 'ws://host.example.com'), category: 'internal' };
+
+        classifyRealtimeEndpoint(finding, '');
+
+        expect(finding.category).toBe('internal');
+    });
+});
+
+describe('extractGrpcTargets', () => {
+    test('should report string targets of dial calls', () => {
+        const source = [
+            'conn, err := grpc.Dial("payments.example.com:443", opts...)',
+            'conn, err = grpc.DialContext(context.Background(), `dns:///ledger.example.com:8443`)',
+            'client, err := grpc.NewClient("unix:///var/run/agent.sock")',
+            '// conn, err := grpc.NewClient("legacy.example.com:50051")',
+            'conn, err = grpc.Dial(target)',
+        ].join('\n');
+
+        const targets = extractGrpcTargets(source);
+
+        expect(targets.map(target => [target.url, target.line, target.sourceType])).toEqual([
+            ['payments.example.com:443', 1, 'string'],
+            ['dns:///ledger.example.com:8443', 2, 'string'],
+            ['legacy.example.com:50051', 4, 'comment'],
+        ]);
+        expect(source.substring(targets[1].start, targets[1].end)).toBe('dns:///ledger.example.com:8443');
+        expect(targets[0].attributes).toEqual({ protocol: 'grpc' });
+    });
+});
+
+describe('mergeGrpcTargets', () => {
+    test('should drop URLs inside a target', () => {
+        const source = 'grpc.Dial("dns:///rpc.example.com:443") // see https://docs.example.com';
+        const urls = [findingAt(source, '//rpc.example.com:443'), findingAt(source, 'https://docs.example.com')];
+
+        const merged = mergeGrpcTargets(urls, extractGrpcTargets(source));
+
+        expect(merged.map(url => url.url)).toEqual(['dns:///rpc.example.com:443', 'https://docs.example.com']);
+    });
+});
+
+describe('gRPC targets in URL filtering', () => {
+    test('should extract the host of targets for domain filters', () => {
+        const filter = new URLFilter({ ignoreDomains: ['*.internal.example.com'] });
+
+        expect(filter.extractDomain('Payments.example.com:443')).toBe('payments.example.com');
+        expect(filter.extractDomain('dns:///rpc.example.com:443')).toBe('rpc.example.com');
+        expect(filter.extractDomain('dns://8.8.8.8/rpc.example.com:443')).toBe('rpc.example.com');
+        expect(filter.extractDomain('[::1]:50051')).toBe('::1');
+        expect(filter.extractDomain('https://api.example.com:8443/v1')).toBe('api.example.com');
+    });
+
+    test('should reserve the category name', () => {
+        expect(() => new CategoryRules([{ host: 'rpc.example.com', category: REALTIME_RPC_CATEGORY }])).toThrow(
+            'uses the built-in category realtime-rpc',
+        );
+    });
+});
+
+describe('URLDetector', () => {
+    test('should report gRPC targets in Go files once', async () => {
+        const source = [
+            'package main',
+            '',
+            'func connect() {',
+            '\tgrpc.Dial("dns:///rpc.example.com:443")',
+            '\tgrpc.Dial("localhost:50051")',
+            '}',
+        ].join('\n');
+
+        const result = await new URLDetector().scanContent(source, 'main.go');
+
+        expect(result!.urls.map(url => [url.url, url.category])).toEqual([
+            ['dns:///rpc.example.com:443', REALTIME_RPC_CATEGORY],
+        ]);
+    });
+});
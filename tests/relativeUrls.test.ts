@@ -0,0 +1,86 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { classifyRelativeReference, extractRelativeUrls } from '../src/relativeUrls';
+
+function urlsOf(sourceCode: string, language: string): string[] {
+    const findings = extractRelativeUrls(sourceCode, language);
+    for (const finding of findings) {
+        expect(sourceCode.substring(finding.start, finding.end)).toBe(finding.url);
+        expect(finding.category).toBe('relative-url');
+    }
+    return findings.map(finding => finding.url);
+}
+
+describe('extractRelativeUrls', () => {
+    test('should detect relative references in HTML', () => {
+        const html = [
+            '<link rel="stylesheet" href="/css/site.css">',
+            '<a href="https://example.com">external</a> <a href="#top">top</a> <a href="docs/guide.html">guide</a>',
+            '<img src="../assets/logo.png" srcset="img/a.png 1x, img/b.png 2x">',
+            '<form action="/api/v2/users"></form>',
+            '<div style="background: url(\'/img/bg.jpg\')"></div>',
+        ].join('\n');
+
+        expect(urlsOf(html, 'html')).toEqual([
+            '/css/site.css',
+            'docs/guide.html',
+            '../assets/logo.png',
+            'img/a.png',
+            'img/b.png',
+            '/api/v2/users',
+            '/img/bg.jpg',
+        ]);
+    });
+
+    test('should detect url() values and imports in CSS', () => {
+        const css = [
+            '@import "base.css";',
+            '.logo { background: url(../img/logo.svg); }',
+            '.font { src: url("data:font/woff2;base64,AA"); }',
+        ].join('\n');
+
+        expect(urlsOf(css, 'css')).toEqual(['base.css', '../img/logo.svg']);
+    });
+
+    test('should detect path strings in scripts but not module specifiers', () => {
+        const script = [
+            "import { api } from './api';",
+            "const helpers = require('../helpers');",
+            "const lazy = import('./lazy');",
+            "fetch('/api/v2/users');",
+            'const logo = `../assets/logo.png`;',
+            "const url = 'https://example.com/path';",
+            "const comment = '// not a path';",
+        ].join('\n');
+
+        expect(urlsOf(script, 'javascript')).toEqual(['/api/v2/users', '../assets/logo.png']);
+    });
+
+    test('should ignore unsupported languages', () => {
+        expect(extractRelativeUrls('path = "/api/v2/users"', 'python')).toEqual([]);
+    });
+});
+
+describe('classifyRelativeReference', () => {
+    test.each([
+        ['../assets/logo.png', 'asset'],
+        ['/fonts/inter.woff2?v=3', 'asset'],
+        ['/api/v2/users', 'route'],
+        ['/users/1.2', 'route'],
+        ['docs/guide', 'path'],
+    ])('should classify %s as %s', (reference, kind) => {
+        expect(classifyRelativeReference(reference)).toBe(kind);
+    });
+});
@@ -0,0 +1,206 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import {
+    REPORT_FORMATS,
+    ReportSections,
+    createReport,
+    detectReportFormat,
+    parseReport,
+    readReport,
+    serializeReport,
+    writeReport,
+} from '../src/report';
+import { Violation } from '../src/ruleEngine';
+import { FileResult } from '../src/urlDetector';
+import { finding } from './fixtures';
+
+function sampleResults(): FileResult[] {
+    return [
+        {
+            file: 'src/app.js',
+            urls: [
+                {
+                    url: 'https://api.example.com/v1',
+                    start: 13,
+                    end: 39,
+                    line: 1,
+                    column: 14,
+                    sourceType: 'string',
+                    context: ['const api = "https://api.example.com/v1";'],
+                    fingerprint: '0f1e2d3c4b5a69788796a5b4c3d2e1f0',
+                },
+                {
+                    url: 'http://insecure.example.com',
+                    start: 60,
+                    end: 87,
+                    line: 3,
+                    column: 5,
+                    sourceType: 'comment',
+                    violations: [{ rule: 'no-plain-http', severity: 'error', message: 'Use https' }],
+                    attributes: { owner: 'team-web', generated: 'false' },
+                },
+            ],
+        },
+        {
+            file: 'src/util.py',
+            urls: [{ url: 'https://api.example.com/v1', start: 0, end: 26, line: 7, column: 1, sourceType: 'string' }],
+        },
+        {
+            file: 'go.mod',
+            urls: [
+                {
+                    url: 'github.com/example/service',
+                    start: 7,
+                    end: 33,
+                    line: 1,
+                    column: 8,
+                    sourceType: 'unknown',
+                    category: 'go-import',
+                },
+            ],
+        },
+    ];
+}
+
+describe('Report codec', () => {
+    test('should compute summary counts', () => {
+        const report = createReport(sampleResults());
+
+        expect(report.summary).toEqual({ totalFiles: 3, totalUrls: 4, uniqueUrls: 3 });
+    });
+
+    test.each(REPORT_FORMATS)('should round-trip findings through %s', format => {
+        const report = createReport(sampleResults());
+
+        const parsed = parseReport(serializeReport(report, format), format);
+
+        expect(parsed.summary).toEqual(report.summary);
+        expect(parsed.files).toEqual(report.files);
+    });
+
+    test.each(REPORT_FORMATS)('should round-trip report sections through %s', format => {
+        const sections: ReportSections = {
+            environments: [
+                {
+                    service: 'api.example.com',
+                    environments: ['production'],
+                    missing: ['staging'],
+                    inconsistencies: [],
+                    endpoints: [
+                        { environment: 'production', url: 'https://api.example.com/v1', file: 'src/app.js', line: 1 },
+                    ],
+                },
+            ],
+        };
+        const report = createReport(sampleResults(), sections);
+
+        const parsed = parseReport(serializeReport(report, format), format);
+
+        expect(parsed.sections).toEqual(sections);
+        expect(parsed.files).toEqual(report.files);
+        expect(parseReport(serializeReport(createReport(sampleResults()), format)).sections).toBeUndefined();
+    });
+
+    test.each(REPORT_FORMATS)('should round-trip the scan manifest through %s', format => {
+        const manifest = {
+            toolVersion: '1.0.0',
+            grammars: { 'tree-sitter-javascript': '0.23.1' },
+            configHash: 'a'.repeat(64),
+            policyHash: 'b'.repeat(64),
+            fileCount: 3,
+            partial: false,
+            commit: null,
+            createdAt: '2026-01-01T00:00:00.000Z',
+        };
+        const report = createReport(sampleResults(), undefined, manifest);
+
+        const parsed = parseReport(serializeReport(report, format), format);
+
+        expect(parsed.manifest).toEqual(manifest);
+        expect(parsed.files).toEqual(report.files);
+    });
+
+    test.each(REPORT_FORMATS)('should detect the %s format', format => {
+        const text = serializeReport(createReport(sampleResults()), format);
+
+        expect(detectReportFormat(text)).toBe(format);
+    });
+
+    test('should keep files without findings in json and sarif', () => {
+        const results: FileResult[] = [...sampleResults(), { file: 'README.md', urls: [] }];
+        const report = createReport(results);
+
+        expect(parseReport(serializeReport(report, 'json')).files).toHaveLength(4);
+        expect(parseReport(serializeReport(report, 'sarif')).files).toHaveLength(4);
+    });
+
+    test('should map violation severity to SARIF levels', () => {
+        const sarif = JSON.parse(serializeReport(createReport(sampleResults()), 'sarif'));
+        const results = sarif.runs[0].results;
+
+        expect(sarif.version).toBe('2.1.0');
+        expect(results.map((r: { level: string }) => r.level)).toEqual(['note', 'error', 'note', 'note']);
+        expect(results[1].ruleId).toBe('no-plain-http');
+        expect(sarif.runs[0].tool.driver.rules.map((r: { id: string }) => r.id)).toEqual([
+            'url-detected',
+            'no-plain-http',
+        ]);
+    });
+
+    test('should take the SARIF rule from the most severe violation', () => {
+        const violations: Violation[] = [
+            { rule: 'deprecated-host', severity: 'warning', message: 'Host is deprecated' },
+            { rule: 'no-plain-http', severity: 'error', message: 'Use https' },
+            { rule: 'scheme-policy', severity: 'error', message: 'Scheme not allowed' },
+        ];
+        const results: FileResult[] = [
+            { file: 'src/app.js', urls: [finding('http://legacy.example.com', { violations })] },
+        ];
+
+        const [result] = JSON.parse(serializeReport(createReport(results), 'sarif')).runs[0].results;
+
+        expect(result).toMatchObject({ ruleId: 'no-plain-http', level: 'error' });
+    });
+
+    test('should write one ndjson finding per line followed by a summary', () => {
+        const lines = serializeReport(createReport(sampleResults()), 'ndjson').split('\n');
+
+        expect(lines).toHaveLength(5);
+        expect(JSON.parse(lines[0])).toMatchObject({ type: 'finding', file: 'src/app.js' });
+        expect(JSON.parse(lines[4])).toEqual({ type: 'summary', totalFiles: 3, totalUrls: 4, uniqueUrls: 3 });
+    });
+
+    test('should reject malformed reports', () => {
+        expect(() => parseReport('{"runs": "nope"}', 'sarif')).toThrow('Invalid SARIF report');
+        expect(() => parseReport('{}', 'json')).toThrow('Invalid JSON report');
+        expect(() => parseReport('not json', 'ndjson')).toThrow('line 1 is not valid JSON');
+    });
+
+    test('should write and read report files', async () => {
+        const dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-report-'));
+        const filePath = path.join(dir, 'report.sarif');
+        try {
+            const report = createReport(sampleResults());
+            await writeReport(filePath, report, 'sarif');
+
+            expect((await readReport(filePath)).files).toEqual(report.files);
+        } finally {
+            await fs.promises.rm(dir, { recursive: true, force: true });
+        }
+    });
+});
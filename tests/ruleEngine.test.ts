@@ -0,0 +1,132 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { RuleEngine, Rule, compareSeverity, getFindingSeverity } from '../src/ruleEngine';
+import { URLDetector } from '../src/urlDetector';
+import { Logger } from '../src/logger';
+import { finding } from './fixtures';
+
+const noPlainHttp: Rule = {
+    id: 'no-plain-http',
+    evaluate: finding =>
+        finding.url.startsWith('http://')
+            ? [{ rule: 'no-plain-http', severity: 'error', message: 'Use https instead of http' }]
+            : [],
+};
+
+describe('RuleEngine', () => {
+    let engine: RuleEngine;
+
+    beforeEach(() => {
+        engine = new RuleEngine();
+    });
+
+    test('should attach violations raised by registered rules', () => {
+        engine.register(noPlainHttp);
+        const findings = [finding('http://insecure.example.com'), finding('https://secure.example.com')];
+
+        engine.evaluate(findings, { file: 'app.js', language: 'javascript', content: '' });
+
+        expect(findings[0].violations).toEqual([
+            { rule: 'no-plain-http', severity: 'error', message: 'Use https instead of http' },
+        ]);
+        expect(findings[1].violations).toBeUndefined();
+    });
+
+    test('should pass file context to rules', () => {
+        const seen: string[] = [];
+        engine.register({
+            id: 'capture',
+            evaluate: (_finding, file) => {
+                seen.push(`${file.file}:${file.language}`);
+                return [];
+            },
+        });
+
+        engine.evaluate([finding('https://example.com')], { file: 'main.go', language: 'go', content: '' });
+
+        expect(seen).toEqual(['main.go:go']);
+    });
+
+    test('should replace rules with the same id and support unregistering', () => {
+        engine.register(noPlainHttp);
+        engine.register({ ...noPlainHttp, description: 'replacement' });
+
+        expect(engine.getRules()).toHaveLength(1);
+        expect(engine.getRules()[0].description).toBe('replacement');
+        expect(engine.unregister('no-plain-http')).toBe(true);
+        expect(engine.unregister('no-plain-http')).toBe(false);
+        expect(engine.getRules()).toHaveLength(0);
+    });
+
+    test('should reject rules without an id', () => {
+        expect(() => engine.register({ id: '', evaluate: () => [] })).toThrow('Rule must have a non-empty id');
+    });
+
+    test('should log and skip rules that throw', () => {
+        const warnings: string[] = [];
+        const logger: Logger = {
+            log: () => {},
+            info: () => {},
+            warn: (message: string) => warnings.push(message),
+            error: () => {},
+            debug: () => {},
+        };
+        engine = new RuleEngine(logger);
+        engine.register({
+            id: 'broken',
+            evaluate: () => {
+                throw new Error('boom');
+            },
+        });
+        engine.register(noPlainHttp);
+        const findings = [finding('http://insecure.example.com')];
+
+        engine.evaluate(findings, { file: 'app.js', language: 'javascript', content: '' });
+
+        expect(warnings).toEqual(['Rule broken failed on app.js: boom']);
+        expect(findings[0].violations).toHaveLength(1);
+    });
+});
+
+describe('Severity helpers', () => {
+    test('should order severities', () => {
+        expect(compareSeverity('info', 'error')).toBeLessThan(0);
+        expect(compareSeverity('error', 'warning')).toBeGreaterThan(0);
+        expect(compareSeverity('warning', 'warning')).toBe(0);
+    });
+
+    test('should compute the highest severity of a finding', () => {
+        const urlMatch = finding('http://example.com');
+        expect(getFindingSeverity(urlMatch)).toBeUndefined();
+
+        urlMatch.violations = [
+            { rule: 'a', severity: 'warning', message: '' },
+            { rule: 'b', severity: 'error', message: '' },
+            { rule: 'c', severity: 'info', message: '' },
+        ];
+        expect(getFindingSeverity(urlMatch)).toBe('error');
+    });
+});
+
+describe('URLDetector rule registration', () => {
+    test('should register and unregister rules on the detector', () => {
+        const detector = new URLDetector();
+        detector.registerRule(noPlainHttp);
+
+        expect(detector.getRuleEngine.getRules().map(rule => rule.id)).toEqual(['no-plain-http']);
+        expect(detector.unregisterRule('no-plain-http')).toBe(true);
+        expect(detector.getRuleEngine.getRules()).toHaveLength(0);
+    });
+});
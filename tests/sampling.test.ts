@@ -0,0 +1,96 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { DetectorOptions } from '../src/options';
+import { estimateTotals, formatSampleEstimate, parseSampleRate, selectSample } from '../src/sampling';
+import { FileResult } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+const FILES = Array.from({ length: 200 }, (_, i) => `src/module${i}.ts`);
+
+function results(counts: Record<string, number>): FileResult[] {
+    return Object.entries(counts).map(([file, count]) => ({
+        file,
+        urls: Array.from({ length: count }, () => finding('https://example.com')),
+    }));
+}
+
+describe('parseSampleRate', () => {
+    test('should parse percentages', () => {
+        expect(parseSampleRate('5%')).toBe(0.05);
+        expect(parseSampleRate('0.5%')).toBe(0.005);
+        expect(parseSampleRate('100%')).toBe(1);
+    });
+
+    test('should reject other sizes', () => {
+        for (const text of ['0%', '150%', '5', 'five%', '']) {
+            expect(() => parseSampleRate(text)).toThrow(`Invalid sample size: ${text}`);
+        }
+        expect(() => new DetectorOptions({ sample: '5' })).toThrow('Invalid sample size: 5');
+    });
+});
+
+describe('selectSample', () => {
+    test('should draw the same sample for the same seed', () => {
+        const sample = selectSample(FILES, 0.1);
+
+        expect(sample).toHaveLength(20);
+        expect(selectSample([...FILES].reverse(), 0.1).sort()).toEqual([...sample].sort());
+        expect(selectSample(FILES, 0.1, 'other')).not.toEqual(sample);
+    });
+
+    test('should grow a smaller sample and keep the original order', () => {
+        const small = selectSample(FILES, 0.05);
+        const large = selectSample(FILES, 0.2);
+
+        expect(small.every(file => large.includes(file))).toBe(true);
+        expect(large).toEqual(FILES.filter(file => large.includes(file)));
+    });
+
+    test('should sample at least one file', () => {
+        expect(selectSample(FILES.slice(0, 3), 0.01)).toHaveLength(1);
+        expect(selectSample([], 0.5)).toEqual([]);
+    });
+});
+
+describe('estimateTotals', () => {
+    test('should extrapolate totals with an interval around the estimate', () => {
+        const sample = FILES.slice(0, 4);
+        const estimate = estimateTotals(results({ [sample[0]]: 2, [sample[1]]: 4 }), sample, 40, '10%');
+
+        expect(estimate).toMatchObject({ population: 40, sampled: 4, rate: '10%', seed: '', confidence: 0.95 });
+        expect(estimate.findings.observed).toBe(6);
+        expect(estimate.findings.estimate).toBe(60);
+        expect(estimate.findings.low).toBeGreaterThanOrEqual(6);
+        expect(estimate.findings.low).toBeLessThan(60);
+        expect(estimate.findings.high).toBeGreaterThan(60);
+        expect(estimate.filesWithFindings).toMatchObject({ observed: 2, estimate: 20 });
+        expect(estimate.filesWithFindings.high).toBeLessThanOrEqual(40);
+        expect(estimate.violations).toEqual({ observed: 0, estimate: 0, low: 0, high: 0 });
+    });
+
+    test('should collapse the interval when every file is sampled', () => {
+        const sample = FILES.slice(0, 3);
+        const estimate = estimateTotals(results({ [sample[0]]: 1, [sample[2]]: 5 }), sample, 3, '100%');
+
+        expect(estimate.findings).toEqual({ observed: 6, estimate: 6, low: 6, high: 6 });
+    });
+
+    test('should ignore results outside the sample', () => {
+        const estimate = estimateTotals(results({ 'git:commit': 3, [FILES[0]]: 1 }), [FILES[0]], 10, '10%');
+
+        expect(estimate.findings.observed).toBe(1);
+        expect(formatSampleEstimate(estimate)).toMatch(/^Sampled 1 of 10 file\(s\) \(10%\); .*findings ~10 /);
+    });
+});
@@ -0,0 +1,138 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { FindingQuery, ScanStore, parseFindingQuery, queryFindings } from '../src/scanStore';
+import { FileResult, URLMatch } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+function scanned(url: string, severity?: 'warning' | 'error'): URLMatch {
+    const violations = severity ? [{ rule: 'scheme-policy', severity, message: 'Use https' }] : undefined;
+    return finding(url, { violations });
+}
+
+const RESULTS: FileResult[] = [
+    {
+        file: 'src/a.ts',
+        urls: [scanned('https://api.example.com/v1'), scanned('http://cdn.example.com/lib.js', 'warning')],
+    },
+    { file: 'src/b.ts', urls: [scanned('http://example.org', 'error'), scanned('https://api.example.com/v2')] },
+];
+
+describe('parseFindingQuery', () => {
+    test('should read criteria and paging', () => {
+        expect(parseFindingQuery(new URLSearchParams('host=api.example.com&severity=warning&limit=10'))).toEqual({
+            host: 'api.example.com',
+            severity: 'warning',
+            offset: 0,
+            limit: 10,
+        });
+        expect(parseFindingQuery(new URLSearchParams(''))).toEqual({ offset: 0, limit: 100 });
+    });
+
+    test('should reject paging out of range', () => {
+        expect(() => parseFindingQuery(new URLSearchParams('limit=0'))).toThrow('Invalid limit: 0');
+        expect(() => parseFindingQuery(new URLSearchParams('limit=5000'))).toThrow('Invalid limit: 5000');
+        expect(() => parseFindingQuery(new URLSearchParams('offset=-1'))).toThrow('Invalid offset: -1');
+        expect(() => parseFindingQuery(new URLSearchParams('offset=abc'))).toThrow('Invalid offset: abc');
+    });
+});
+
+describe('queryFindings', () => {
+    test('should page through matching findings in file order', () => {
+        const first = queryFindings(RESULTS, { offset: 0, limit: 3 });
+        const last = queryFindings(RESULTS, { offset: 3, limit: 3 });
+
+        expect(first.total).toBe(4);
+        expect(first.nextOffset).toBe(3);
+        expect(first.findings.map(f => [f.file, f.url])).toEqual([
+            ['src/a.ts', 'https://api.example.com/v1'],
+            ['src/a.ts', 'http://cdn.example.com/lib.js'],
+            ['src/b.ts', 'http://example.org'],
+        ]);
+        expect(last.nextOffset).toBeNull();
+        expect(last.findings.map(f => f.url)).toEqual(['https://api.example.com/v2']);
+    });
+
+    test('should filter by host, severity, file, and filter expression', () => {
+        const urls = (query: Partial<FindingQuery>) =>
+            queryFindings(RESULTS, { offset: 0, limit: 10, ...query }).findings.map(f => f.url);
+
+        expect(urls({ host: 'API.example.com' })).toEqual(['https://api.example.com/v1', 'https://api.example.com/v2']);
+        expect(urls({ host: '*.example.com' })).toEqual([
+            'https://api.example.com/v1',
+            'http://cdn.example.com/lib.js',
+            'https://api.example.com/v2',
+        ]);
+        expect(urls({ severity: 'error' })).toEqual(['http://example.org']);
+        expect(urls({ severity: 'warning', file: 'src/a.ts' })).toEqual(['http://cdn.example.com/lib.js']);
+        expect(urls({ rule: 'scheme-policy', filter: 'path startswith "/lib"' })).toEqual([
+            'http://cdn.example.com/lib.js',
+        ]);
+    });
+
+    test('should reject invalid criteria', () => {
+        expect(() => queryFindings(RESULTS, { severity: 'urgent', offset: 0, limit: 10 })).toThrow();
+        expect(() => queryFindings(RESULTS, { filter: 'host ==', offset: 0, limit: 10 })).toThrow();
+    });
+});
+
+describe('ScanStore', () => {
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-scans-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should save, list, and load scans', async () => {
+        const store = new ScanStore(tempDir);
+        const summary = await store.save('payments', RESULTS, new Date('2026-01-01T00:00:00Z'));
+
+        expect(summary).toMatchObject({
+            profile: 'payments',
+            createdAt: '2026-01-01T00:00:00.000Z',
+            totalFiles: 2,
+            totalFindings: 4,
+        });
+        expect(await store.list('payments')).toEqual([summary]);
+        expect(await store.list('web')).toEqual([]);
+        expect(await new ScanStore(tempDir).load(summary.id)).toEqual({ ...summary, results: RESULTS });
+    });
+
+    test('should return null for unknown and malformed ids', async () => {
+        const store = new ScanStore(tempDir);
+
+        expect(await store.load('00000000-0000-4000-8000-000000000000')).toBeNull();
+        expect(await store.load('../index')).toBeNull();
+    });
+
+    test('should keep only the most recent scans', async () => {
+        const store = new ScanStore(tempDir, 2);
+        const [first, second, third] = await Promise.all([
+            store.save('default', RESULTS),
+            store.save('default', RESULTS),
+            store.save('default', RESULTS),
+        ]);
+
+        expect((await store.list()).map(scan => scan.id)).toEqual([third.id, second.id]);
+        expect(await store.load(first.id)).toBeNull();
+        expect(await store.load(third.id)).not.toBeNull();
+    });
+});
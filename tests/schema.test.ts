@@ -0,0 +1,120 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { DetectorOptions } from '../src/options';
+import { createReport, serializeReport } from '../src/report';
+import { CONFIG_SCHEMA, REPORT_SCHEMA, SCHEMA_NAMES, getSchema, validateSchema } from '../src/schema';
+
+describe('Config schema', () => {
+    test('should describe every DetectorOptionsConfig property', () => {
+        const optionsSource = fs.readFileSync(path.join(__dirname, '..', 'src', 'options.ts'), 'utf8');
+        const body = optionsSource.match(/export interface DetectorOptionsConfig \{([\s\S]*?)\n\}/)![1];
+        const properties = Array.from(body.matchAll(/^\s*(\w+)\?:/gm)).map(match => match[1]);
+
+        const described = Object.keys(CONFIG_SCHEMA.properties!).filter(key => key !== '$schema');
+
+        expect(described.sort()).toEqual(properties.sort());
+    });
+
+    test('should accept valid configs and report invalid ones', () => {
+        expect(validateSchema({ scan: ['src/**/*'], format: 'json', goImportPolicy: {} }, CONFIG_SCHEMA)).toEqual([]);
+        expect(
+            validateSchema(
+                { scann: ['src'], format: 1, concurrency: 0, goImportPolicy: { allowedOwners: 'my-org' } },
+                CONFIG_SCHEMA,
+            ),
+        ).toEqual([
+            '$.scann is not a known property',
+            '$.format must be string',
+            '$.concurrency must be >= 1',
+            '$.goImportPolicy.allowedOwners must be array',
+        ]);
+    });
+
+    test('should accept formats registered by sinks', () => {
+        expect(validateSchema({ format: 'audit-xml' }, CONFIG_SCHEMA)).toEqual([]);
+    });
+});
+
+describe('Report schema', () => {
+    test('should accept reports written by the json format', () => {
+        const report = createReport(
+            [
+                {
+                    file: 'src/app.js',
+                    urls: [
+                        {
+                            url: 'http://insecure.example.com',
+                            start: 0,
+                            end: 27,
+                            line: 1,
+                            column: 1,
+                            sourceType: 'string',
+                            violations: [{ rule: 'no-plain-http', severity: 'error', message: 'Use https' }],
+                            attributes: { owner: 'team-web' },
+                        },
+                    ],
+                },
+            ],
+            { environments: [] },
+        );
+
+        expect(validateSchema(JSON.parse(serializeReport(report, 'json')), REPORT_SCHEMA)).toEqual([]);
+        expect(validateSchema({ files: [{ urls: [] }] }, REPORT_SCHEMA)).toEqual([
+            '$.summary is required',
+            '$.files[0].file is required',
+        ]);
+    });
+
+    test('should publish every schema by name', () => {
+        expect(SCHEMA_NAMES.map(name => getSchema(name).title)).toEqual([
+            'url-detector configuration',
+            'url-detector JSON report',
+        ]);
+        expect(() => getSchema('nope' as never)).toThrow('Unknown schema: nope');
+    });
+});
+
+describe('DetectorOptions.loadConfigFile', () => {
+    let dir: string;
+
+    beforeEach(async () => {
+        dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-config-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(dir, { recursive: true, force: true });
+    });
+
+    test('should load a config file without its $schema reference', async () => {
+        const filePath = path.join(dir, 'config.json');
+        await fs.promises.writeFile(filePath, JSON.stringify({ $schema: './schema.json', includeComments: true }));
+
+        expect(await DetectorOptions.loadConfigFile(filePath)).toEqual({ includeComments: true });
+    });
+
+    test('should reject invalid config files', async () => {
+        const filePath = path.join(dir, 'config.json');
+        await fs.promises.writeFile(filePath, '{ "includeComments": "yes" }');
+        await expect(DetectorOptions.loadConfigFile(filePath)).rejects.toThrow(
+            `Invalid config file ${filePath}: $.includeComments must be boolean`,
+        );
+
+        await fs.promises.writeFile(filePath, '{ nope');
+        await expect(DetectorOptions.loadConfigFile(filePath)).rejects.toThrow('Invalid config file');
+    });
+});
@@ -0,0 +1,91 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { SCHEME_POLICY_RULE, createSchemePolicyRule } from '../src/schemePolicy';
+import { URLMatch } from '../src/urlFilter';
+
+function evaluate(rule: ReturnType<typeof createSchemePolicyRule>, content: string, url: string, category?: string) {
+    const start = content.indexOf(url);
+    const finding: URLMatch = { url, start, end: start + url.length, line: 1, column: start + 1, sourceType: 'string' };
+    if (category) finding.category = category;
+    return rule.evaluate(finding, { file: 'src/app.ts', language: 'typescript', content });
+}
+
+describe('createSchemePolicyRule', () => {
+    const rule = createSchemePolicyRule([
+        { schemes: ['ws'], host: '*.prod.example.com', require: 'wss' },
+        { schemes: ['http'], hostRegex: '\\.prod\\.example\\.com$', require: 'https', severity: 'warning' },
+        { schemes: ['http'], ports: [443], message: 'TLS port with a plain-text scheme' },
+        { schemes: ['ftp'] },
+    ]);
+
+    test('should flag forbidden schemes on matching hosts', () => {
+        const url = 'http://api.prod.example.com/v1';
+        const violations = evaluate(rule, `const a = "${url}";`, url);
+        expect(violations).toEqual([
+            {
+                rule: SCHEME_POLICY_RULE,
+                severity: 'warning',
+                message: 'http:// is not allowed on api.prod.example.com; use https:// instead',
+            },
+        ]);
+        expect(evaluate(rule, 'const a = "http://dev.example.com/v1";', 'http://dev.example.com/v1')).toEqual([]);
+    });
+
+    test('should read schemes in front of protocol-relative matches', () => {
+        const ws = evaluate(rule, 'connect("ws://feed.prod.example.com/live")', '//feed.prod.example.com/live');
+        expect(ws).toHaveLength(1);
+        expect(ws[0].severity).toBe('error');
+        expect(ws[0].message).toBe('ws:// is not allowed on feed.prod.example.com; use wss:// instead');
+        const live = 'connect("ws://live.prod.example.com")';
+        expect(evaluate(rule, live, '//live.prod.example.com', 'realtime-rpc')).toHaveLength(1);
+        expect(evaluate(rule, 'connect("wss://feed.prod.example.com/live")', '//feed.prod.example.com/live')).toEqual(
+            [],
+        );
+        expect(evaluate(rule, 'get("ftp://files.example.com/a.zip")', '//files.example.com/a.zip')[0].message).toBe(
+            'ftp:// is not allowed',
+        );
+    });
+
+    test('should match ports, counting default ports', () => {
+        const explicit = evaluate(rule, '"http://example.com:443/"', 'http://example.com:443/');
+        expect(explicit[0].message).toBe('http:// is not allowed to port 443 (TLS port with a plain-text scheme)');
+        expect(evaluate(rule, '"http://example.com/"', 'http://example.com/')).toEqual([]);
+        expect(evaluate(rule, '"http://example.com:8080/"', 'http://example.com:8080/')).toEqual([]);
+    });
+
+    test('should skip categorized findings', () => {
+        const finding: URLMatch = {
+            url: 'http://api.prod.example.com',
+            start: 0,
+            end: 27,
+            line: 1,
+            column: 1,
+            sourceType: 'comment',
+            category: 'doc-link',
+        };
+        expect(rule.evaluate(finding, { file: 'README.md', language: 'markdown', content: finding.url })).toEqual([]);
+    });
+
+    test('should reject invalid entries', () => {
+        expect(() => createSchemePolicyRule([{ schemes: [] }])).toThrow('at least one scheme');
+        expect(() => createSchemePolicyRule([{ schemes: ['ws'], host: 'a.com', hostRegex: 'a' }])).toThrow(
+            'at most one of host and hostRegex',
+        );
+        expect(() => createSchemePolicyRule([{ schemes: ['ws'], hostRegex: '(' }])).toThrow('invalid hostRegex');
+        expect(() => createSchemePolicyRule([{ schemes: ['ws'], severity: 'fatal' as any }])).toThrow(
+            'unknown severity',
+        );
+    });
+});
@@ -0,0 +1,102 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { planSegments, scanSegment, stitchSegments } from '../src/segmentedScan';
+import { assignFingerprints } from '../src/fingerprint';
+import { URLMatch } from '../src/urlFilter';
+
+const URL_PATTERN = /https?:\/\/[^\s<>"'`${}]+/g;
+
+function scanWhole(content: string): URLMatch[] {
+    const findings: URLMatch[] = [];
+    for (const match of content.matchAll(URL_PATTERN)) {
+        const before = content.substring(0, match.index!).split('\n');
+        findings.push({
+            url: match[0],
+            start: match.index!,
+            end: match.index! + match[0].length,
+            line: before.length,
+            column: before[before.length - 1].length + 1,
+            sourceType: 'unknown',
+        });
+    }
+    return assignFingerprints(findings, 'dump.sql', content);
+}
+
+function scanInSegments(content: string, segmentSize: number, overlap: number): URLMatch[] {
+    const bytes = Buffer.from(content, 'utf8');
+    const scans = planSegments(bytes.length, segmentSize, overlap).map(segment =>
+        scanSegment(bytes.subarray(segment.readStart, segment.readEnd), segment, URL_PATTERN),
+    );
+    return stitchSegments(scans, 'dump.sql');
+}
+
+describe('planSegments', () => {
+    test('should cover the file with overlapping reads', () => {
+        expect(planSegments(25, 10, 3)).toEqual([
+            { start: 0, end: 10, readStart: 0, readEnd: 13 },
+            { start: 10, end: 20, readStart: 7, readEnd: 23 },
+            { start: 20, end: 25, readStart: 17, readEnd: 25 },
+        ]);
+        expect(planSegments(0, 10)).toEqual([]);
+    });
+});
+
+describe('segmented scanning', () => {
+    const lines: string[] = [];
+    for (let i = 0; i < 40; i++) {
+        lines.push(`INSERT INTO links VALUES (${i}, 'https://host${i % 7}.example.com/päth/${i}', '日本語 🚀');`);
+    }
+    const content = lines.join('\n');
+
+    test.each([
+        [64, 128],
+        [97, 256],
+        [1000, 256],
+    ])('should match a whole-file scan with %d-byte segments', (segmentSize, overlap) => {
+        const withoutFingerprint = (finding: URLMatch) => ({ ...finding, fingerprint: undefined });
+        expect(scanInSegments(content, segmentSize, overlap).map(withoutFingerprint)).toEqual(
+            scanWhole(content).map(withoutFingerprint),
+        );
+    });
+
+    test('should match whole-file fingerprints when the overlap covers the neighbouring lines', () => {
+        // Lines are 86 bytes long, so a 128-byte overlap misses the line above or below some findings
+        expect(scanInSegments(content, 64, 256)).toEqual(scanWhole(content));
+        expect(scanInSegments(content, 97, 256)).toEqual(scanWhole(content));
+    });
+
+    test('should report a URL crossing a boundary once, from the segment it starts in', () => {
+        const text = 'see https://boundary.example.com/a/long/path here';
+        for (let segmentSize = 1; segmentSize < text.length; segmentSize++) {
+            const findings = scanInSegments(text, segmentSize, 64);
+            expect(findings.map(finding => [finding.url, finding.start, finding.column])).toEqual([
+                ['https://boundary.example.com/a/long/path', 4, 5],
+            ]);
+        }
+    });
+
+    test('should carry columns across segments without line breaks', () => {
+        const text = `${'x'.repeat(50)}€ https://a.example.com`;
+        const [finding] = scanInSegments(text, 16, 64);
+        expect(finding).toMatchObject({ start: 52, line: 1, column: 53 });
+    });
+
+    test('should skip rejected matches', () => {
+        const bytes = Buffer.from('http://a.example.com http://b.example.com');
+        const [segment] = planSegments(bytes.length, bytes.length);
+        const scan = scanSegment(bytes, segment, URL_PATTERN, url => !url.includes('a.example'));
+        expect(scan.matches.map(match => match.url)).toEqual(['http://b.example.com']);
+    });
+});
@@ -0,0 +1,269 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { PROFILE_HEADER, SCAN_ID_HEADER, ScanServer, contentHash, loadServerProfiles } from '../src/server';
+
+const CONTENT = 'See https://api.example.com and https://docs.internal.example.org for details.';
+
+describe('ScanServer', () => {
+    let server: ScanServer;
+    let baseUrl: string;
+
+    beforeAll(async () => {
+        server = new ScanServer({
+            profiles: {
+                default: {},
+                payments: { ignoreDomains: ['*.internal.example.org'] },
+            },
+            maxBodyBytes: 4096,
+        });
+        const address = await server.listen();
+        baseUrl = `http://127.0.0.1:${address.port}`;
+    });
+
+    afterAll(async () => {
+        await server.close();
+    });
+
+    async function scan(route: string, body: unknown, headers: Record<string, string> = {}) {
+        return fetch(`${baseUrl}${route}`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json', ...headers },
+            body: typeof body === 'string' ? body : JSON.stringify(body),
+        });
+    }
+
+    function urlsOf(report: any): string[] {
+        return report.files.flatMap((file: any) => file.urls.map((urlObj: any) => urlObj.url));
+    }
+
+    test('should scan with the default profile when none is selected', async () => {
+        const response = await scan('/scan', { files: [{ path: 'notes.txt', content: CONTENT }] });
+
+        expect(response.status).toBe(200);
+        const report = await response.json();
+        expect(report.summary.totalFiles).toBe(1);
+        expect(urlsOf(report)).toEqual(['https://api.example.com', 'https://docs.internal.example.org']);
+    });
+
+    test('should select the profile from the path', async () => {
+        const response = await scan('/profiles/payments/scan', { files: [{ path: 'notes.txt', content: CONTENT }] });
+
+        expect(response.status).toBe(200);
+        expect(urlsOf(await response.json())).toEqual(['https://api.example.com']);
+    });
+
+    test('should select the profile from the header', async () => {
+        const response = await scan(
+            '/scan',
+            { files: [{ path: 'notes.txt', content: CONTENT }] },
+            { [PROFILE_HEADER]: 'payments' },
+        );
+
+        expect(response.status).toBe(200);
+        expect(urlsOf(await response.json())).toEqual(['https://api.example.com']);
+    });
+
+    test('should keep a separate detector per profile', () => {
+        expect(server.getDetector('payments')).toBe(server.getDetector('payments'));
+        expect(server.getDetector('payments')).not.toBe(server.getDetector('default'));
+    });
+
+    test('should reject unknown profiles', async () => {
+        const response = await scan('/profiles/unknown/scan', { files: [] });
+
+        expect(response.status).toBe(404);
+        expect((await response.json()).error).toBe('Unknown profile: unknown');
+    });
+
+    test('should reject invalid requests', async () => {
+        expect((await scan('/scan', '{not json')).status).toBe(400);
+        expect((await scan('/scan', { files: [{ path: 'notes.txt' }] })).status).toBe(400);
+        expect((await scan('/scan', { files: [{ path: 'notes.txt', content: 'x'.repeat(5000) }] })).status).toBe(413);
+        expect((await fetch(`${baseUrl}/scan`)).status).toBe(405);
+        expect((await fetch(`${baseUrl}/unknown`)).status).toBe(404);
+    });
+
+    test('should list profiles and report health', async () => {
+        expect(await (await fetch(`${baseUrl}/profiles`)).json()).toEqual({ profiles: ['default', 'payments'] });
+        expect(await (await fetch(`${baseUrl}/health`)).json()).toEqual({ status: 'ok' });
+    });
+});
+
+describe('ScanServer caching', () => {
+    let server: ScanServer;
+    let baseUrl: string;
+
+    beforeEach(async () => {
+        server = new ScanServer({
+            profiles: {
+                default: {},
+                escalated: { severityEscalation: [{ paths: ['**'], levels: 1 }] },
+            },
+            cacheSize: 2,
+        });
+        const address = await server.listen();
+        baseUrl = `http://127.0.0.1:${address.port}`;
+    });
+
+    afterEach(async () => {
+        await server.close();
+    });
+
+    async function scan(files: Array<{ path: string; content: string }>, headers: Record<string, string> = {}) {
+        return fetch(`${baseUrl}/scan`, { method: 'POST', headers, body: JSON.stringify({ files }) });
+    }
+
+    test('should not rescan unchanged files', async () => {
+        const scanContent = jest.spyOn(server.getDetector('default'), 'scanContent');
+
+        const first = await (await scan([{ path: 'a.txt', content: CONTENT }])).json();
+        const second = await (await scan([{ path: 'a.txt', content: CONTENT }])).json();
+        await scan([{ path: 'a.txt', content: `${CONTENT}\n` }]);
+
+        expect(second).toEqual(first);
+        expect(scanContent).toHaveBeenCalledTimes(2);
+    });
+
+    test('should evict the least recently used results', async () => {
+        const scanContent = jest.spyOn(server.getDetector('default'), 'scanContent');
+
+        await scan([{ path: 'a.txt', content: CONTENT }]);
+        await scan([{ path: 'b.txt', content: CONTENT }]);
+        await scan([{ path: 'a.txt', content: CONTENT }]);
+        await scan([{ path: 'c.txt', content: CONTENT }]);
+        await scan([{ path: 'a.txt', content: CONTENT }]);
+        await scan([{ path: 'b.txt', content: CONTENT }]);
+
+        expect(scanContent).toHaveBeenCalledTimes(4);
+    });
+
+    test('should answer 304 when If-None-Match matches the ETag', async () => {
+        const files = [{ path: 'a.txt', content: CONTENT }];
+        const etag = (await scan(files)).headers.get('etag')!;
+
+        expect(etag).toMatch(/^"[0-9a-f]{64}"$/);
+        expect((await scan(files, { 'If-None-Match': etag })).status).toBe(304);
+        expect((await scan(files, { 'If-None-Match': `W/${etag}` })).status).toBe(304);
+        expect((await scan([{ path: 'b.txt', content: CONTENT }], { 'If-None-Match': etag })).status).toBe(200);
+        expect((await scan(files, { 'If-None-Match': etag, [PROFILE_HEADER]: 'escalated' })).status).toBe(200);
+    });
+});
+
+describe('ScanServer scan store', () => {
+    let server: ScanServer;
+    let baseUrl: string;
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-server-scans-'));
+        server = new ScanServer({ profiles: { default: {}, web: {} }, scanStore: tempDir });
+        const address = await server.listen();
+        baseUrl = `http://127.0.0.1:${address.port}`;
+    });
+
+    afterEach(async () => {
+        await server.close();
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    async function storeScan(route: string = '/scan'): Promise<string> {
+        const response = await fetch(`${baseUrl}${route}`, {
+            method: 'POST',
+            body: JSON.stringify({ files: [{ path: 'a.txt', content: CONTENT }] }),
+        });
+        return response.headers.get(SCAN_ID_HEADER)!;
+    }
+
+    test('should store scans and list them per profile', async () => {
+        const id = await storeScan();
+
+        expect(id).toMatch(/^[0-9a-f-]{36}$/);
+        const { scans } = await (await fetch(`${baseUrl}/scans`)).json();
+        expect(scans).toEqual([expect.objectContaining({ id, profile: 'default', totalFiles: 1, totalFindings: 2 })]);
+        expect(await (await fetch(`${baseUrl}/scans/${id}`)).json()).toEqual(scans[0]);
+        expect(await (await fetch(`${baseUrl}/profiles/web/scans`)).json()).toEqual({ scans: [] });
+    });
+
+    test('should page through filtered findings', async () => {
+        const id = await storeScan();
+
+        const page = await (await fetch(`${baseUrl}/scans/${id}/findings?limit=1`)).json();
+        expect(page).toMatchObject({ total: 2, offset: 0, limit: 1, nextOffset: 1 });
+        expect(page.findings.map((f: any) => [f.file, f.url])).toEqual([['a.txt', 'https://api.example.com']]);
+
+        const filtered = await (await fetch(`${baseUrl}/scans/${id}/findings?host=*.example.org`)).json();
+        expect(filtered.findings.map((f: any) => f.url)).toEqual(['https://docs.internal.example.org']);
+        expect((await fetch(`${baseUrl}/scans/${id}/findings?limit=0`)).status).toBe(400);
+        expect((await fetch(`${baseUrl}/scans/${id}/findings?severity=urgent`)).status).toBe(400);
+    });
+
+    test('should hide scans from other profiles', async () => {
+        const id = await storeScan('/profiles/web/scan');
+
+        expect((await fetch(`${baseUrl}/scans/${id}`)).status).toBe(404);
+        expect((await fetch(`${baseUrl}/scans/${id}`, { headers: { [PROFILE_HEADER]: 'web' } })).status).toBe(200);
+        expect((await fetch(`${baseUrl}/scans/${id}`, { method: 'DELETE' })).status).toBe(405);
+    });
+
+    test('should report when scans are not stored', async () => {
+        const plain = new ScanServer({ profiles: { default: {} } });
+        const address = await plain.listen();
+        try {
+            const response = await fetch(`http://127.0.0.1:${address.port}/scans`);
+            expect(response.status).toBe(404);
+            expect(response.headers.get(SCAN_ID_HEADER)).toBeNull();
+        } finally {
+            await plain.close();
+        }
+    });
+});
+
+describe('contentHash', () => {
+    test('should depend on both path and content', () => {
+        expect(contentHash('a.txt', 'x')).toBe(contentHash('a.txt', 'x'));
+        expect(contentHash('a.txt', 'x')).not.toBe(contentHash('b.txt', 'x'));
+        expect(contentHash('a.txt', 'x')).not.toBe(contentHash('a.txt', 'y'));
+        expect(contentHash('ab', 'c')).not.toBe(contentHash('a', 'bc'));
+    });
+});
+
+describe('loadServerProfiles', () => {
+    let tempDir: string;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-server-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should load profiles', async () => {
+        const filePath = path.join(tempDir, 'profiles.json');
+        await fs.promises.writeFile(filePath, JSON.stringify({ web: { ignoreDomains: ['cdn.example.com'] } }));
+
+        expect(await loadServerProfiles(filePath)).toEqual({ web: { ignoreDomains: ['cdn.example.com'] } });
+    });
+
+    test('should validate every profile against the config schema', async () => {
+        const filePath = path.join(tempDir, 'profiles.json');
+        await fs.promises.writeFile(filePath, JSON.stringify({ web: { ignoreDomain: ['cdn.example.com'] } }));
+
+        await expect(loadServerProfiles(filePath)).rejects.toThrow('$.web.ignoreDomain is not a known property');
+    });
+});
@@ -0,0 +1,71 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { Severity } from '../src/ruleEngine';
+import {
+    SeverityEscalation,
+    applySeverityEscalation,
+    escalateSeverity,
+    getEscalationLevels,
+} from '../src/severityEscalation';
+import { FileResult, URLMatch } from '../src/urlFilter';
+import { finding } from './fixtures';
+
+const ESCALATIONS: SeverityEscalation[] = [
+    { paths: ['auth/', 'payments/**'] },
+    { paths: ['auth/legacy/'], levels: 2 },
+    { paths: ['**/*.test.ts'], levels: -1 },
+];
+
+function violating(severity?: Severity): URLMatch {
+    const violations = severity ? [{ rule: 'no-plain-http', severity, message: 'Use https' }] : undefined;
+    return finding('http://example.com', { violations });
+}
+
+describe('escalateSeverity', () => {
+    test('should shift severities within info and error', () => {
+        expect(escalateSeverity('info', 1)).toBe('warning');
+        expect(escalateSeverity('warning', 5)).toBe('error');
+        expect(escalateSeverity('warning', -3)).toBe('info');
+    });
+});
+
+describe('getEscalationLevels', () => {
+    test.each([
+        ['auth/login.ts', 1],
+        ['payments/api/client.ts', 1],
+        ['auth/legacy/sso.ts', 2],
+        ['src/auth.test.ts', -1],
+        ['src/app.ts', 0],
+    ])('should resolve %s to %d', (filePath, levels) => {
+        expect(getEscalationLevels(filePath, ESCALATIONS)).toBe(levels);
+    });
+});
+
+describe('applySeverityEscalation', () => {
+    test('should shift violations and record the shift on affected findings', () => {
+        const results: FileResult[] = [
+            { file: 'auth/login.ts', urls: [violating('warning'), violating()] },
+            { file: 'src/app.ts', urls: [violating('warning')] },
+        ];
+
+        applySeverityEscalation(results, ESCALATIONS);
+
+        expect(results[0].urls[0].violations![0].severity).toBe('error');
+        expect(results[0].urls[0].attributes).toEqual({ severityEscalation: '+1' });
+        expect(results[0].urls[1].attributes).toBeUndefined();
+        expect(results[1].urls[0].violations![0].severity).toBe('warning');
+        expect(results[1].urls[0].attributes).toBeUndefined();
+    });
+});
@@ -0,0 +1,115 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { Logger } from '../src/logger';
+import { DetectorOptions } from '../src/options';
+import { OutputFormatter } from '../src/outputFormatter';
+import { createReport } from '../src/report';
+import { Sink, createSink, getSinkFormats, registerSink, runSink, unregisterSink } from '../src/sinks';
+import { FileResult } from '../src/urlFilter';
+
+function sampleResults(): FileResult[] {
+    const finding = { start: 0, end: 10, column: 1, sourceType: 'string' as const };
+    return [
+        { file: 'a.js', urls: [{ ...finding, url: 'https://a.example.com', line: 1 }] },
+        { file: 'b.js', urls: [{ ...finding, url: 'https://b.example.com?x=<y>', line: 4 }] },
+    ];
+}
+
+function auditXmlSink(): Sink {
+    let count = 0;
+    return {
+        begin: () => '<audit>\n',
+        write: async finding => {
+            count++;
+            return `  <url file="${finding.file}" line="${finding.line}">${finding.url.replace(/</g, '&lt;')}</url>\n`;
+        },
+        end: summary => `  <summary urls="${summary.totalUrls}" written="${count}"/>\n</audit>`,
+    };
+}
+
+describe('sink registry', () => {
+    afterEach(() => {
+        unregisterSink('audit-xml');
+    });
+
+    test('should register, create, and remove sinks', () => {
+        registerSink('audit-xml', auditXmlSink);
+
+        expect(getSinkFormats()).toEqual(['audit-xml']);
+        expect(createSink('audit-xml')).not.toBeNull();
+        expect(createSink('other')).toBeNull();
+        expect(unregisterSink('audit-xml')).toBe(true);
+        expect(unregisterSink('audit-xml')).toBe(false);
+    });
+
+    test('should refuse built-in and empty format names', () => {
+        expect(() => registerSink('json', auditXmlSink)).toThrow('Cannot register a sink for the built-in format json');
+        expect(() => registerSink('', auditXmlSink)).toThrow('non-empty format name');
+    });
+
+    test('should accept registered formats in the detector options', () => {
+        expect(() => new DetectorOptions({ format: 'audit-xml' })).toThrow('Invalid output format: audit-xml');
+
+        registerSink('audit-xml', auditXmlSink);
+
+        expect(new DetectorOptions({ format: 'audit-xml' }).format).toBe('audit-xml');
+    });
+});
+
+describe('runSink', () => {
+    test('should call begin, write for every finding, and end', async () => {
+        const output = await runSink(auditXmlSink(), createReport(sampleResults()));
+
+        expect(output).toBe(
+            [
+                '<audit>',
+                '  <url file="a.js" line="1">https://a.example.com</url>',
+                '  <url file="b.js" line="4">https://b.example.com?x=&lt;y></url>',
+                '  <summary urls="2" written="2"/>',
+                '</audit>',
+            ].join('\n'),
+        );
+    });
+
+    test('should skip calls that return nothing', async () => {
+        const sink: Sink = { begin: () => undefined, write: finding => `${finding.url}\n`, end: () => undefined };
+
+        expect(await runSink(sink, createReport(sampleResults()))).toBe(
+            'https://a.example.com\nhttps://b.example.com?x=<y>\n',
+        );
+    });
+
+    test('should be used by the output formatter for registered formats', async () => {
+        const lines: string[] = [];
+        const logger: Logger = {
+            log: (message: string) => lines.push(message),
+            info: () => {},
+            warn: () => {},
+            error: () => {},
+            debug: () => {},
+        };
+        registerSink('audit-xml', auditXmlSink);
+        try {
+            await new OutputFormatter({ format: 'audit-xml' }, logger).formatAndOutput(sampleResults());
+        } finally {
+            unregisterSink('audit-xml');
+        }
+
+        expect(lines.join('\n')).toContain('<summary urls="2" written="2"/>');
+        await expect(new OutputFormatter({ format: 'audit-xml' }, logger).formatAndOutput([])).rejects.toThrow(
+            'Unknown output format: audit-xml',
+        );
+    });
+});
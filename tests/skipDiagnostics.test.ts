@@ -0,0 +1,43 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { SkippedFile, countSkipReasons, formatSkipSummary, isBinaryContent } from '../src/skipDiagnostics';
+
+describe('isBinaryContent', () => {
+    test('should treat NUL bytes near the start as binary', () => {
+        expect(isBinaryContent(Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x00, 0x01]))).toBe(true);
+        expect(isBinaryContent(Buffer.from('const url = "https://example.com"; // 日本語'))).toBe(false);
+        expect(isBinaryContent(Buffer.concat([Buffer.alloc(9000, 0x61), Buffer.from([0])]))).toBe(false);
+    });
+});
+
+describe('skip summaries', () => {
+    const skipped: SkippedFile[] = [
+        { file: 'a.png', reason: 'binary' },
+        { file: 'b.sql', reason: 'too-large', detail: '5000000 bytes' },
+        { file: 'c.bin', reason: 'binary' },
+    ];
+
+    test('should count reasons in a fixed order', () => {
+        expect(countSkipReasons(skipped)).toEqual([
+            { reason: 'too-large', files: 1 },
+            { reason: 'binary', files: 2 },
+        ]);
+    });
+
+    test('should format a one-line summary', () => {
+        expect(formatSkipSummary(skipped)).toBe('Skipped 3 file(s): 1 too-large, 2 binary');
+        expect(formatSkipSummary([])).toBeNull();
+    });
+});
@@ -0,0 +1,95 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { createSriAdvisoryRule, detectCdn } from '../src/sriAdvisory';
+import { URLMatch } from '../src/urlFilter';
+
+function findingsIn(content: string): URLMatch[] {
+    return Array.from(content.matchAll(/(?:https?:)?\/\/[^"'\s>]+/g)).map(match => ({
+        url: match[0],
+        start: match.index!,
+        end: match.index! + match[0].length,
+        line: 1,
+        column: match.index! + 1,
+        sourceType: 'string' as const,
+    }));
+}
+
+describe('detectCdn', () => {
+    test.each([
+        ['https://cdn.jsdelivr.net/npm/vue@3/dist/vue.global.js', 'jsDelivr'],
+        ['https://unpkg.com/react@18/umd/react.production.min.js', 'unpkg'],
+        ['//cdnjs.cloudflare.com/ajax/libs/lodash.js/4.17.21/lodash.min.js', 'cdnjs'],
+        ['https://cdn.example.com/app.js', 'generic CDN'],
+        ['https://static-cdn2.example.net/app.css', 'generic CDN'],
+    ])('should detect the CDN of %s', (url, cdn) => {
+        expect(detectCdn(url)).toBe(cdn);
+    });
+
+    test('should not treat other hosts as CDNs', () => {
+        expect(detectCdn('https://example.com/app.js')).toBeNull();
+        expect(detectCdn('/js/app.js')).toBeNull();
+    });
+});
+
+describe('SRI advisory rule', () => {
+    const rule = createSriAdvisoryRule();
+
+    function advisories(content: string, language: string = 'html'): string[] {
+        return findingsIn(content).flatMap(finding =>
+            rule.evaluate(finding, { file: 'index.html', language, content }).map(v => `${v.severity}:${finding.url}`),
+        );
+    }
+
+    test('should advise SRI for CDN scripts and stylesheets without integrity', () => {
+        const content = [
+            '<script src="https://cdn.jsdelivr.net/npm/vue@3/dist/vue.global.js"></script>',
+            '<link rel="stylesheet" href="https://unpkg.com/modern-css-reset/dist/reset.min.css">',
+        ].join('\n');
+
+        expect(advisories(content)).toEqual([
+            'info:https://cdn.jsdelivr.net/npm/vue@3/dist/vue.global.js',
+            'info:https://unpkg.com/modern-css-reset/dist/reset.min.css',
+        ]);
+    });
+
+    test('should record the CDN and name it in the message', () => {
+        const content = '<script src="https://code.jquery.com/jquery-3.7.1.min.js"></script>';
+        const [finding] = findingsIn(content);
+
+        const [violation] = rule.evaluate(finding, { file: 'index.html', language: 'html', content });
+
+        expect(finding.attributes).toEqual({ cdn: 'jQuery CDN' });
+        expect(violation.rule).toBe('sri-missing');
+        expect(violation.message).toContain('from jQuery CDN without an integrity attribute');
+    });
+
+    test('should not advise when integrity is present or SRI does not apply', () => {
+        const content = [
+            '<script src="https://cdn.jsdelivr.net/npm/a.js" integrity="sha384-abc" crossorigin="anonymous"></script>',
+            '<link rel="icon" href="https://cdn.jsdelivr.net/favicon.ico">',
+            '<a href="https://cdn.jsdelivr.net/npm/a.js">download</a>',
+            '<script src="https://example.com/app.js"></script>',
+            '<script>const url = "https://unpkg.com/lib.js";</script>',
+        ].join('\n');
+
+        expect(advisories(content)).toEqual([]);
+    });
+
+    test('should only inspect HTML files', () => {
+        const content = '<script src="https://unpkg.com/lib.js"></script>';
+
+        expect(advisories(content, 'javascript')).toEqual([]);
+    });
+});
@@ -0,0 +1,91 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { PassThrough, Readable } from 'stream';
+import { StreamLine, readStreamLines } from '../src/streamScan';
+import { URLDetector } from '../src/urlDetector';
+import { FileResult } from '../src/urlFilter';
+
+async function collect(chunks: Array<string | Buffer>, maxLineLength?: number): Promise<StreamLine[]> {
+    const lines: StreamLine[] = [];
+    for await (const line of readStreamLines(Readable.from(chunks), maxLineLength)) lines.push(line);
+    return lines;
+}
+
+describe('readStreamLines', () => {
+    test('should split lines across chunks and CRLF line breaks', async () => {
+        expect(await collect(['first li', 'ne\r\nsecond\n', '\nlast'])).toEqual([
+            { text: 'first line', line: 1, column: 1, offset: 0 },
+            { text: 'second', line: 2, column: 1, offset: 12 },
+            { text: '', line: 3, column: 1, offset: 19 },
+            { text: 'last', line: 4, column: 1, offset: 20 },
+        ]);
+    });
+
+    test('should decode characters split across chunks', async () => {
+        const bytes = Buffer.from('https://café.example.com\n');
+
+        expect(await collect([bytes.subarray(0, 12), bytes.subarray(12)])).toEqual([
+            { text: 'https://café.example.com', line: 1, column: 1, offset: 0 },
+        ]);
+    });
+
+    test('should pass long lines on in pieces of the maximum length', async () => {
+        expect(await collect(['abcdefghij', 'kl\nmn'], 4)).toEqual([
+            { text: 'abcd', line: 1, column: 1, offset: 0 },
+            { text: 'efgh', line: 1, column: 5, offset: 4 },
+            { text: 'ijkl', line: 1, column: 9, offset: 8 },
+            { text: 'mn', line: 2, column: 1, offset: 13 },
+        ]);
+    });
+});
+
+describe('URLDetector.scanStream', () => {
+    test('should pass on the findings of each line as it is read', async () => {
+        const input = new PassThrough();
+        const received: FileResult[] = [];
+        const scan = new URLDetector({}).scanStream(input, result => {
+            received.push(result);
+        });
+
+        input.write('10:00 GET https://api.example.com/v1/orders failed (see https://status.example.com).\n');
+        while (received.length === 0) await new Promise(resolve => setImmediate(resolve));
+        expect(received.map(result => result.urls.map(url => url.url))).toEqual([
+            ['https://api.example.com/v1/orders', 'https://status.example.com'],
+        ]);
+
+        input.end('10:01 no URLs here\n10:02 retry via https://backup.example.com\n');
+        const summary = await scan;
+
+        expect(summary).toEqual({ lines: 3, urls: 3 });
+        expect(received).toHaveLength(2);
+        expect(received[1]).toMatchObject({
+            file: '<stdin>',
+            urls: [{ url: 'https://backup.example.com', line: 3, column: 17, sourceType: 'unknown' }],
+        });
+        expect(received[1].urls[0].fingerprint).toMatch(/^[0-9a-f]{32}$/);
+    });
+
+    test('should stop reading when the signal aborts', async () => {
+        const input = new PassThrough();
+        const controller = new AbortController();
+        const detector = new URLDetector({ ignoreDomains: ['internal.example.com'] });
+        const scan = detector.scanStream(input, () => controller.abort(), { signal: controller.signal });
+
+        input.write('https://internal.example.com/health\nhttps://api.example.com\nhttps://later.example.com\n');
+
+        expect(await scan).toEqual({ lines: 2, urls: 1 });
+        expect(detector.isPartial).toBe(true);
+    });
+});
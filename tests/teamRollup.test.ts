@@ -0,0 +1,125 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { UNOWNED_TEAM, buildTeamRollup, createTeamResolver } from '../src/teamRollup';
+import { FileResult, URLMatch } from '../src/urlFilter';
+import { finding as stringFinding } from './fixtures';
+
+// Fingerprinted by URL, so the same URL is the same finding in both scans
+function finding(url: string, extra: Partial<URLMatch> = {}): URLMatch {
+    return stringFinding(url, { fingerprint: url, ...extra });
+}
+
+const error = { rule: 'no-plain-http', severity: 'error' as const, message: 'Use https' };
+const warning = { rule: 'open-redirect', severity: 'warning' as const, message: 'Check the redirect' };
+
+describe('createTeamResolver', () => {
+    test('should prefer the longest matching path prefix', () => {
+        const resolve = createTeamResolver({
+            'services/payments': 'payments',
+            './services/payments/legacy/': 'legacy',
+        });
+
+        expect(resolve('services/payments/api.ts', finding('https://a.example.com'))).toBe('payments');
+        expect(resolve('services/payments/legacy/old.ts', finding('https://a.example.com'))).toBe('legacy');
+        expect(resolve('services/payments-v2/api.ts', finding('https://a.example.com'))).toBe(UNOWNED_TEAM);
+    });
+
+    test('should fall back to the CODEOWNERS owner attribute', () => {
+        const resolve = createTeamResolver({ web: 'frontend' });
+
+        expect(resolve('api/server.py', finding('https://a.example.com', { attributes: { owner: '@org/api' } }))).toBe(
+            '@org/api',
+        );
+        expect(resolve('web/app.js', finding('https://a.example.com', { attributes: { owner: '@org/api' } }))).toBe(
+            'frontend',
+        );
+    });
+});
+
+describe('buildTeamRollup', () => {
+    const results: FileResult[] = [
+        {
+            file: 'web/app.js',
+            urls: [finding('http://a.example.com', { violations: [error] }), finding('https://b.example.com')],
+        },
+        { file: 'web/util.js', urls: [finding('https://c.example.com', { violations: [warning, error] })] },
+        { file: 'api/server.py', urls: [finding('https://d.example.com')] },
+        { file: 'README.md', urls: [finding('https://e.example.com')] },
+    ];
+    const teams = { web: 'frontend', api: 'backend' };
+
+    test('should count findings and severities and list worst offenders per team', () => {
+        expect(buildTeamRollup(results, { teams })).toEqual([
+            {
+                team: 'frontend',
+                fileCount: 2,
+                urlCount: 3,
+                violationCount: 3,
+                severities: { error: 2, warning: 1, info: 0 },
+                worstFiles: [
+                    { file: 'web/util.js', urlCount: 1, violationCount: 2 },
+                    { file: 'web/app.js', urlCount: 2, violationCount: 1 },
+                ],
+            },
+            {
+                team: UNOWNED_TEAM,
+                fileCount: 1,
+                urlCount: 1,
+                violationCount: 0,
+                severities: { error: 0, warning: 0, info: 0 },
+                worstFiles: [{ file: 'README.md', urlCount: 1, violationCount: 0 }],
+            },
+            {
+                team: 'backend',
+                fileCount: 1,
+                urlCount: 1,
+                violationCount: 0,
+                severities: { error: 0, warning: 0, info: 0 },
+                worstFiles: [{ file: 'api/server.py', urlCount: 1, violationCount: 0 }],
+            },
+        ]);
+    });
+
+    test('should limit the worst offenders', () => {
+        const [frontend] = buildTeamRollup(results, { teams, worstOffenders: 1 });
+
+        expect(frontend.worstFiles).toEqual([{ file: 'web/util.js', urlCount: 1, violationCount: 2 }]);
+    });
+
+    test('should count new and fixed findings against a baseline', () => {
+        const baseline: FileResult[] = [
+            { file: 'web/app.js', urls: [finding('http://a.example.com'), finding('https://old.example.com')] },
+            { file: 'ops/deploy.sh', urls: [finding('https://ops.example.com')] },
+        ];
+
+        const rollup = buildTeamRollup(results, { teams: { ...teams, ops: 'ops' }, baseline });
+        const counts = rollup.map(({ team, newCount, fixedCount }) => ({ team, newCount, fixedCount }));
+
+        expect(counts).toEqual([
+            { team: 'frontend', newCount: 2, fixedCount: 1 },
+            { team: UNOWNED_TEAM, newCount: 1, fixedCount: 0 },
+            { team: 'backend', newCount: 1, fixedCount: 0 },
+            { team: 'ops', newCount: 0, fixedCount: 1 },
+        ]);
+        expect(rollup[3].fileCount).toBe(0);
+    });
+
+    test('should leave out new and fixed counts without a baseline', () => {
+        const [frontend] = buildTeamRollup(results, { teams });
+
+        expect(frontend.newCount).toBeUndefined();
+        expect(frontend.fixedCount).toBeUndefined();
+    });
+});
@@ -0,0 +1,65 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { extractUrls, isSchemaIdentifier } from '../src/textExtraction';
+
+const log = [
+    '2026-10-16T09:30:00Z GET https://api.example.com/v1/orders failed (see https://status.example.com).',
+    'wiki: https://en.wikipedia.org/wiki/Foo_(bar), mirror: ftp://files.example.com/pub',
+    'assets at //cdn.example.com/app.js; health at http://localhost:8080/health!',
+].join('\n');
+
+describe('extractUrls', () => {
+    test('should leave out trailing punctuation and unbalanced brackets', () => {
+        const urls = extractUrls(log);
+
+        expect(urls.map(url => url.url)).toEqual([
+            'https://api.example.com/v1/orders',
+            'https://status.example.com',
+            'https://en.wikipedia.org/wiki/Foo_(bar)',
+        ]);
+        expect(urls.map(url => log.substring(url.start, url.end))).toEqual(urls.map(url => url.url));
+        expect(urls[2]).toMatchObject({ line: 2, column: 7, sourceType: 'unknown' });
+    });
+
+    test('should report protocol-relative URLs only on request and never inside other schemes', () => {
+        const urls = extractUrls(log, { includeProtocolRelative: true, includeNonFqdn: true });
+
+        expect(urls.map(url => url.url)).toEqual([
+            'https://api.example.com/v1/orders',
+            'https://status.example.com',
+            'https://en.wikipedia.org/wiki/Foo_(bar)',
+            '//cdn.example.com/app.js',
+            'http://localhost:8080/health',
+        ]);
+    });
+
+    test('should filter, normalize, and deduplicate URLs', () => {
+        const text = Buffer.from('https://API.example.com/v1/ https://api.example.com/v1 https://cdn.example.com/x');
+
+        expect(extractUrls(text, { ignoreDomains: ['cdn.*'] }).map(url => url.url)).toEqual([
+            'https://API.example.com/v1/',
+            'https://api.example.com/v1',
+        ]);
+        expect(extractUrls(text, { normalize: true, unique: true }).map(url => url.url)).toEqual([
+            'https://api.example.com/v1',
+            'https://cdn.example.com/x',
+        ]);
+    });
+
+    test('should skip DTD identifiers', () => {
+        expect(isSchemaIdentifier('//W3C//DTD XHTML 1.0 Strict//EN')).toBe(true);
+        expect(extractUrls('<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN">')).toEqual([]);
+    });
+});
@@ -0,0 +1,120 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { createReport } from '../src/report';
+import { TrendStore, createTrendSnapshot, formatTrendSeries } from '../src/trendStore';
+import { FileResult } from '../src/urlDetector';
+
+function sampleResults(): FileResult[] {
+    const finding = { start: 0, end: 10, line: 1, column: 1, sourceType: 'string' as const };
+    return [
+        {
+            file: 'src/app.js',
+            urls: [
+                { ...finding, url: 'https://api.example.com' },
+                {
+                    ...finding,
+                    url: 'http://insecure.example.com',
+                    violations: [{ rule: 'no-plain-http', severity: 'error', message: 'Use https' }],
+                },
+            ],
+        },
+        { file: 'go.mod', urls: [{ ...finding, url: 'github.com/example/service', category: 'go-import' }] },
+    ];
+}
+
+describe('createTrendSnapshot', () => {
+    test('should count findings by severity and category', () => {
+        const recordedAt = new Date('2026-03-01T12:00:00Z');
+
+        const snapshot = createTrendSnapshot(createReport(sampleResults()), { commit: 'abc123' }, recordedAt);
+
+        expect(snapshot).toEqual({
+            key: 'abc123',
+            commit: 'abc123',
+            recordedAt: '2026-03-01T12:00:00.000Z',
+            total: 3,
+            bySeverity: { none: 2, error: 1 },
+            byCategory: { url: 2, 'go-import': 1 },
+        });
+    });
+
+    test('should key snapshots by date when given or when no commit is known', () => {
+        const report = createReport(sampleResults());
+        const recordedAt = new Date('2026-03-01T12:00:00Z');
+
+        expect(createTrendSnapshot(report, { commit: 'abc123', date: '2026-02-28' }, recordedAt).key).toBe(
+            '2026-02-28',
+        );
+        expect(createTrendSnapshot(report, {}, recordedAt).key).toBe('2026-03-01');
+    });
+});
+
+describe('TrendStore', () => {
+    let dir: string;
+
+    beforeEach(async () => {
+        dir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-trend-'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(dir, { recursive: true, force: true });
+    });
+
+    test('should start empty and replace snapshots recorded for the same key', async () => {
+        const store = new TrendStore(path.join(dir, 'nested', 'trends.json'));
+        const report = createReport(sampleResults());
+
+        expect(await store.load()).toEqual([]);
+
+        await store.record(createTrendSnapshot(report, { commit: 'b' }, new Date('2026-03-02T00:00:00Z')));
+        await store.record(createTrendSnapshot(report, { commit: 'a' }, new Date('2026-03-01T00:00:00Z')));
+        const empty = createReport([]);
+        const snapshots = await store.record(createTrendSnapshot(empty, { commit: 'b' }, new Date('2026-03-03')));
+
+        expect(snapshots.map(s => [s.key, s.total])).toEqual([
+            ['a', 3],
+            ['b', 0],
+        ]);
+        expect(await store.load()).toEqual(snapshots);
+        expect((await store.series('2026-03-02')).map(s => s.key)).toEqual(['b']);
+    });
+
+    test('should reject files that are not trend stores', async () => {
+        const filePath = path.join(dir, 'trends.json');
+        await fs.promises.writeFile(filePath, '{}', 'utf8');
+
+        await expect(new TrendStore(filePath).load()).rejects.toThrow('missing snapshots array');
+    });
+});
+
+describe('formatTrendSeries', () => {
+    test('should write one CSV row per snapshot with severity and category columns', () => {
+        const recordedAt = new Date('2026-03-01T00:00:00Z');
+        const snapshots = [
+            createTrendSnapshot(createReport(sampleResults()), { commit: 'abc123' }, recordedAt),
+            createTrendSnapshot(createReport([]), { date: '2026-03-02' }, recordedAt),
+        ];
+
+        expect(formatTrendSeries(snapshots, 'csv').split('\n')).toEqual([
+            'Key,Commit,RecordedAt,Total,severity:error,severity:none,category:go-import,category:url',
+            'abc123,abc123,2026-03-01T00:00:00.000Z,3,1,2,1,2',
+            '2026-03-02,,2026-03-01T00:00:00.000Z,0,0,0,0,0',
+        ]);
+        expect(JSON.parse(formatTrendSeries(snapshots, 'json')).series).toHaveLength(2);
+    });
+});
@@ -0,0 +1,109 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { TriageStore, applyTriage, parseTriageState } from '../src/triage';
+import { FileResult } from '../src/urlFilter';
+
+function sampleResults(): FileResult[] {
+    const finding = { start: 0, end: 10, line: 1, column: 1, sourceType: 'string' as const };
+    return [
+        {
+            file: 'src/app.js',
+            urls: [
+                { ...finding, url: 'https://api.example.com', fingerprint: 'aaa' },
+                { ...finding, url: 'https://test.example.com', fingerprint: 'bbb' },
+                { ...finding, url: 'https://old.example.com', fingerprint: 'ccc' },
+                { ...finding, url: 'https://new.example.com', fingerprint: 'ddd' },
+            ],
+        },
+    ];
+}
+
+describe('parseTriageState', () => {
+    test('should accept known states only', () => {
+        expect(parseTriageState('accepted-risk')).toBe('accepted-risk');
+        expect(() => parseTriageState('wontfix')).toThrow('Unknown triage state: wontfix');
+    });
+});
+
+describe('TriageStore', () => {
+    let tempDir: string;
+    let store: TriageStore;
+
+    beforeEach(async () => {
+        tempDir = await fs.promises.mkdtemp(path.join(os.tmpdir(), 'url-detector-triage-'));
+        store = new TriageStore(path.join(tempDir, 'nested', 'triage.json'));
+    });
+
+    afterEach(async () => {
+        await fs.promises.rm(tempDir, { recursive: true, force: true });
+    });
+
+    test('should start empty', async () => {
+        expect((await store.load()).size).toBe(0);
+    });
+
+    test('should record states and keep notes unless replaced', async () => {
+        await store.setState(['aaa', 'bbb'], 'accepted-risk', 'Internal service');
+        await store.setState(['aaa'], 'false-positive');
+
+        const entries = await store.load();
+        expect(entries.get('aaa')).toMatchObject({ state: 'false-positive', note: 'Internal service' });
+        expect(entries.get('bbb')).toMatchObject({ state: 'accepted-risk', note: 'Internal service' });
+    });
+
+    describe('applyTriage', () => {
+        beforeEach(async () => {
+            await store.setState(['aaa'], 'accepted-risk');
+            await store.setState(['bbb'], 'false-positive');
+            await store.setState(['ccc'], 'fixed');
+        });
+
+        test('should carry states forward by fingerprint', async () => {
+            const results = sampleResults();
+
+            const summary = await applyTriage(results, store);
+
+            expect(results[0].urls.map(urlObj => urlObj.attributes!.triage)).toEqual([
+                'accepted-risk',
+                'false-positive',
+                'open',
+                'open',
+            ]);
+            expect(summary.counts).toEqual({ open: 2, 'accepted-risk': 1, 'false-positive': 1, fixed: 0 });
+        });
+
+        test('should reopen fixed findings that are found again', async () => {
+            const summary = await applyTriage(sampleResults(), store);
+
+            expect(summary.reopened).toEqual(['ccc']);
+            expect((await store.load()).get('ccc')).toMatchObject({
+                state: 'open',
+                url: 'https://old.example.com',
+                file: 'src/app.js',
+            });
+        });
+
+        test('should hide dismissed findings when requested', async () => {
+            const results = sampleResults();
+
+            await applyTriage(results, store, true);
+
+            expect(results[0].urls.map(urlObj => urlObj.fingerprint)).toEqual(['ccc', 'ddd']);
+        });
+    });
+});
@@ -12,6 +12,9 @@
  * and limitations under the License.
  */
 
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
 import { URLDetector } from '../src/urlDetector';
 
 describe('URLDetector', () => {
@@ -320,6 +323,244 @@ describe('URLDetector', () => {
                 new URLDetector({ concurrency: -1 });
             }).toThrow('Concurrency must be >= 1');
         });
+
+        test('should reject a negative chunk size', () => {
+            expect(() => new URLDetector({ chunkSize: -1 })).toThrow('Chunk size must be >= 0');
+        });
+
+        test('should reject a malformed filter before scanning', () => {
+            expect(() => new URLDetector({ filter: 'host ==' })).toThrow('Invalid filter "host ==": expected a value');
+            expect(() => new URLDetector({ filter: 'errors', filters: { errors: 'severity >= fatal' } })).toThrow(
+                "unknown severity 'fatal'",
+            );
+        });
+    });
+
+    describe('File listing', () => {
+        test('should list the files a scan would cover and how', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-ls-'));
+            fs.mkdirSync(path.join(dir, 'src'));
+            fs.writeFileSync(path.join(dir, 'src', 'app.js'), 'fetch("https://api.example.com");');
+            fs.writeFileSync(path.join(dir, 'src', 'app.min.js'), 'fetch("https://api.example.com");');
+            fs.writeFileSync(path.join(dir, 'src', 'notes.xyz'), 'https://docs.example.com');
+            fs.writeFileSync(path.join(dir, 'src', 'logo.png'), Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x00]));
+            fs.writeFileSync(path.join(dir, 'src', 'app.test.js'), '');
+
+            const cwd = process.cwd();
+            process.chdir(dir);
+            try {
+                const listings = await new URLDetector({
+                    scan: ['src/**'],
+                    exclude: ['**/*.test.js'],
+                    skipGenerated: true,
+                }).listFiles();
+
+                expect(listings).toEqual([
+                    { file: 'src/app.js', language: 'javascript', mode: 'parse' },
+                    { file: 'src/app.min.js', language: 'javascript', mode: 'skip', reason: 'generated' },
+                    { file: 'src/logo.png', language: 'unknown', mode: 'skip', reason: 'binary' },
+                    { file: 'src/notes.xyz', language: 'unknown', mode: 'regex' },
+                ]);
+            } finally {
+                process.chdir(cwd);
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
+
+        test('should list files without a parser as unsupported-language when fallbackRegex is off', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-ls-'));
+            fs.writeFileSync(path.join(dir, 'notes.xyz'), 'https://docs.example.com');
+
+            const cwd = process.cwd();
+            process.chdir(dir);
+            try {
+                const listings = await new URLDetector({ fallbackRegex: false }).listFiles();
+
+                expect(listings).toEqual([
+                    { file: 'notes.xyz', language: 'unknown', mode: 'skip', reason: 'unsupported-language' },
+                ]);
+            } finally {
+                process.chdir(cwd);
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
+    });
+
+    describe('Skipped files', () => {
+        test('should record why files were not scanned', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-skips-'));
+            fs.mkdirSync(path.join(dir, 'src'));
+            fs.writeFileSync(path.join(dir, 'src', 'app.xyz'), 'https://api.example.com');
+            fs.writeFileSync(path.join(dir, 'src', 'logo.png'), Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x00]));
+            fs.writeFileSync(path.join(dir, 'src', 'big.xyz'), 'x'.repeat(1024 * 1024 + 1));
+            fs.writeFileSync(path.join(dir, 'src', 'vendor.xyz'), 'https://cdn.example.com');
+            fs.mkdirSync(path.join(dir, 'src', 'generated', 'api'), { recursive: true });
+            fs.writeFileSync(path.join(dir, 'src', 'generated', 'one.xyz'), 'https://one.example.com');
+            fs.writeFileSync(path.join(dir, 'src', 'generated', 'api', 'two.xyz'), 'https://two.example.com');
+
+            const cwd = process.cwd();
+            process.chdir(dir);
+            try {
+                const detector = new URLDetector({
+                    scan: ['src/**'],
+                    exclude: ['**/vendor.*', '**/generated/**'],
+                    maxFileSize: 1,
+                });
+                const results = await detector.process();
+
+                expect(results.map(result => result.file)).toEqual([path.join(fs.realpathSync(dir), 'src', 'app.xyz')]);
+                expect(detector.getSkippedFiles()).toEqual([
+                    { file: '**/vendor.*', reason: 'ignored-by-pattern', detail: '1 file(s)' },
+                    { file: 'src/big.xyz', reason: 'too-large', detail: '1048577 bytes' },
+                    { file: 'src/generated/', reason: 'ignored-by-pattern', detail: 'excluded by **/generated/**' },
+                    { file: 'src/logo.png', reason: 'binary' },
+                ]);
+            } finally {
+                process.chdir(cwd);
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
+    });
+
+    describe('Rescans', () => {
+        test('should scan listed files like process() and skip the ones that are gone', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-rescan-'));
+            fs.writeFileSync(path.join(dir, 'app.xyz'), 'https://api.example.com');
+            fs.writeFileSync(path.join(dir, 'big.xyz'), 'x'.repeat(1024 * 1024 + 1));
+
+            const cwd = process.cwd();
+            process.chdir(dir);
+            try {
+                const detector = new URLDetector({ maxFileSize: 1 });
+                const results = await detector.processFiles(['deleted.xyz', 'app.xyz', 'big.xyz']);
+
+                // Results resolve against the working directory, which is the real path of a symlinked tmpdir
+                expect(results.map(result => [result.file, result.urls.map(url => url.url)])).toEqual([
+                    [path.join(fs.realpathSync(dir), 'app.xyz'), ['https://api.example.com']],
+                ]);
+                expect(detector.getSkippedFiles().map(skipped => [skipped.file, skipped.reason])).toEqual([
+                    ['big.xyz', 'too-large'],
+                    ['deleted.xyz', 'unreadable'],
+                ]);
+            } finally {
+                process.chdir(cwd);
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
+    });
+
+    describe('Binaries', () => {
+        test('should find URLs in the strings of a binary with their byte offsets', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-binary-'));
+            const file = path.join(dir, 'server');
+            const header = Buffer.from([0x7f, 0x45, 0x4c, 0x46, 0x02, 0x01, 0x01, 0x00]);
+            const ascii = Buffer.from('\0\0endpoint=https://api.example.com/v1\0\x01\x02', 'latin1');
+            const wide = Buffer.from('https://update.example.org/check', 'utf16le');
+            fs.writeFileSync(file, Buffer.concat([header, ascii, wide, Buffer.from([0, 0])]));
+
+            try {
+                const result = await new URLDetector().scanBinary(file);
+
+                expect(result.urls.map(finding => [finding.url, finding.line, finding.attributes])).toEqual([
+                    ['https://api.example.com/v1', 1, { offset: '0x13' }],
+                    ['https://update.example.org/check', 2, { offset: '0x30' }],
+                ]);
+            } finally {
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
+
+        test('should apply the finding filter to binary findings', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-binary-'));
+            const file = path.join(dir, 'server');
+            const strings = '\x7fELF\0\0https://api.example.com/v1\0\0https://update.example.org/check\0';
+            fs.writeFileSync(file, strings, 'latin1');
+
+            try {
+                const result = await new URLDetector({ filter: 'host == update.example.org' }).scanBinary(file);
+
+                expect(result.urls.map(finding => finding.url)).toEqual(['https://update.example.org/check']);
+            } finally {
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
+    });
+
+    describe('Generic tokenizer', () => {
+        test('should tell strings from comments in files without a parser', async () => {
+            const source = [
+                "// Mirror: https://mirror.example.com",
+                "maven { url 'https://repo.example.com/maven' }",
+                'see https://docs.example.com/#/setup',
+            ].join('\n');
+
+            const urls = await new URLDetector().detectURLs(source, 'unknown', 'build.gradle');
+
+            expect(urls.map(url => [url.url, url.sourceType])).toEqual([
+                ['https://mirror.example.com', 'comment'],
+                ['https://repo.example.com/maven', 'string'],
+                ['https://docs.example.com/#/setup', 'unknown'],
+            ]);
+        });
+
+        test('should keep plain regex detection for prose and when turned off', async () => {
+            const source = '# Links\nhttps://example.com';
+
+            const prose = await new URLDetector().detectURLs(source, 'unknown', 'notes.txt');
+            const off = await new URLDetector({ genericTokenizer: false }).detectURLs(source, 'unknown', 'a.gradle');
+
+            expect(prose.map(url => url.sourceType)).toEqual(['unknown']);
+            expect(off.map(url => url.sourceType)).toEqual(['unknown']);
+        });
+    });
+
+    describe('Patches', () => {
+        test('should report findings on added lines at their new-file positions', async () => {
+            const patch = [
+                'diff --git a/docs/links.txt b/docs/links.txt',
+                '--- a/docs/links.txt',
+                '+++ b/docs/links.txt',
+                '@@ -10,3 +10,4 @@ Links',
+                ' See https://context.example.com for details.',
+                '-Old home: https://old.example.com',
+                '+New home: https://new.example.com',
+                '+Mirror: https://mirror.example.com',
+                ' Contact https://support.example.com',
+            ].join('\n');
+
+            const results = await new URLDetector().scanPatch(patch);
+
+            expect(results).toHaveLength(1);
+            expect(results[0].file).toBe(path.resolve('docs/links.txt'));
+            expect(results[0].urls.map(finding => [finding.url, finding.line, finding.column])).toEqual([
+                ['https://new.example.com', 11, 11],
+                ['https://mirror.example.com', 12, 9],
+            ]);
+        });
+    });
+
+    describe('Large files', () => {
+        test('should scan files above chunkSize in segments with the same findings', async () => {
+            const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'url-detector-chunks-'));
+            const lines: string[] = [];
+            for (let i = 0; lines.join('\n').length < 1.5 * 1024 * 1024; i++) {
+                lines.push(`${i} GET https://service${i % 13}.example.com/api/${i} 200 ${'·'.repeat(i % 40)}`);
+            }
+            fs.writeFileSync(path.join(dir, 'access.log'), lines.join('\n'));
+
+            const cwd = process.cwd();
+            process.chdir(dir);
+            try {
+                const [segmented] = await new URLDetector({ scan: ['*.log'], chunkSize: 1 }).process();
+                const [whole] = await new URLDetector({ scan: ['*.log'], chunkSize: 0 }).process();
+
+                expect(segmented.urls.length).toBe(lines.length);
+                expect(segmented.urls).toEqual(whole.urls);
+            } finally {
+                process.chdir(cwd);
+                fs.rmSync(dir, { recursive: true, force: true });
+            }
+        });
     });
 
     describe('Edge cases', () => {
@@ -0,0 +1,169 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { Logger } from '../src/logger';
+import { ChangeBatcher, WatchBatch, createBatchFilter } from '../src/watchMode';
+
+function collect(batches: WatchBatch[]): (batch: WatchBatch) => Promise<void> {
+    return async batch => {
+        batches.push(batch);
+    };
+}
+
+function sleep(ms: number): Promise<void> {
+    return new Promise(resolve => setTimeout(resolve, ms));
+}
+
+describe('ChangeBatcher', () => {
+    test('should coalesce changes until the debounce period passes', async () => {
+        const batches: WatchBatch[] = [];
+        const batcher = new ChangeBatcher(collect(batches), { debounce: 20, maxWait: 1000 });
+
+        batcher.add('src/b.ts');
+        batcher.add('src/a.ts');
+        batcher.add('src/b.ts');
+        await sleep(5);
+        expect(batches).toEqual([]);
+        expect(batcher.pending).toBe(2);
+
+        await sleep(40);
+        expect(batches).toEqual([{ files: ['src/a.ts', 'src/b.ts'], directories: [] }]);
+        expect(batcher.pending).toBe(0);
+        await batcher.close();
+    });
+
+    test('should not wait longer than maxWait while changes keep arriving', async () => {
+        const batches: WatchBatch[] = [];
+        const batcher = new ChangeBatcher(collect(batches), { debounce: 30, maxWait: 60 });
+
+        for (let i = 0; i < 8; i++) {
+            batcher.add(`file${i}.txt`);
+            await sleep(15);
+        }
+        await batcher.close();
+
+        expect(batches.length).toBeGreaterThanOrEqual(1);
+        expect(batches[0].files.length).toBeLessThan(8);
+    });
+
+    test('should rescan a directory as a whole once it exceeds the event rate', async () => {
+        const batches: WatchBatch[] = [];
+        const batcher = new ChangeBatcher(collect(batches), {
+            debounce: 0,
+            directoryEventRate: 3,
+        });
+
+        batcher.add('README.md');
+        for (let i = 0; i < 5; i++) batcher.add(`src/lib/file${i}.ts`);
+        batcher.add('src/lib/nested/deep.ts');
+        batcher.add('src/main.ts');
+        await batcher.flush();
+
+        expect(batches).toEqual([{ files: ['README.md', 'src/main.ts'], directories: ['src/lib'] }]);
+        await batcher.close();
+    });
+
+    test('should count directory events per second rather than per batch', async () => {
+        const batches: WatchBatch[] = [];
+        const batcher = new ChangeBatcher(collect(batches), { debounce: 0, directoryEventRate: 3 });
+        const now = jest.spyOn(Date, 'now').mockReturnValue(0);
+        try {
+            for (let i = 0; i < 3; i++) batcher.add(`src/file${i}.ts`);
+            now.mockReturnValue(1000);
+            for (let i = 3; i < 6; i++) batcher.add(`src/file${i}.ts`);
+            await batcher.flush();
+        } finally {
+            now.mockRestore();
+        }
+
+        expect(batches[0].files).toHaveLength(6);
+        expect(batches[0].directories).toEqual([]);
+        await batcher.close();
+    });
+
+    test('should run one batch at a time and queue changes that arrive meanwhile', async () => {
+        const batches: WatchBatch[] = [];
+        let running = 0;
+        let overlapped = false;
+        const batcher = new ChangeBatcher(
+            async batch => {
+                running++;
+                overlapped = overlapped || running > 1;
+                batches.push(batch);
+                await sleep(30);
+                running--;
+            },
+            { debounce: 0 },
+        );
+
+        batcher.add('a.ts');
+        const first = batcher.flush();
+        batcher.add('b.ts');
+        await batcher.flush();
+        await first;
+        await sleep(50);
+
+        expect(overlapped).toBe(false);
+        expect(batches).toEqual([
+            { files: ['a.ts'], directories: [] },
+            { files: ['b.ts'], directories: [] },
+        ]);
+        await batcher.close();
+    });
+
+    test('should keep batching after a failed rescan', async () => {
+        const errors: string[] = [];
+        const logger: Logger = {
+            log: () => {},
+            info: () => {},
+            warn: () => {},
+            error: (message: string) => errors.push(message),
+            debug: () => {},
+        };
+        const calls: WatchBatch[] = [];
+        const batcher = new ChangeBatcher(
+            async batch => {
+                calls.push(batch);
+                if (calls.length === 1) throw new Error('disk on fire');
+            },
+            { debounce: 0 },
+            logger,
+        );
+
+        batcher.add('a.ts');
+        await batcher.flush();
+        batcher.add('b.ts');
+        await batcher.flush();
+
+        expect(errors).toEqual(['Rescan failed: disk on fire']);
+        expect(calls).toHaveLength(2);
+        await batcher.close();
+    });
+
+    test('should reject invalid settings', () => {
+        const onBatch = async () => {};
+        expect(() => new ChangeBatcher(onBatch, { debounce: -1 })).toThrow('0 or more milliseconds');
+        expect(() => new ChangeBatcher(onBatch, { directoryEventRate: 0 })).toThrow('at least 1');
+    });
+});
+
+describe('createBatchFilter', () => {
+    test('should match listed files and files under listed directories', () => {
+        const inBatch = createBatchFilter({ files: ['README.md'], directories: ['src/lib'] });
+        expect(inBatch('README.md')).toBe(true);
+        expect(inBatch('src/lib/a/b.ts')).toBe(true);
+        expect(inBatch('src/library.ts')).toBe(false);
+        expect(createBatchFilter({ files: [], directories: ['.'] })('anything')).toBe(true);
+    });
+});
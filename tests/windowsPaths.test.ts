@@ -0,0 +1,53 @@
+/*
+ * Morgan Stanley makes this available to you under the Apache License,
+ * Version 2.0 (the "License"). You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0.
+ *
+ * See the NOTICE file distributed with this work for additional information
+ * regarding copyright ownership. Unless required by applicable law or agreed
+ * to in writing, software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions
+ * and limitations under the License.
+ */
+
+import { extractWindowsPaths } from '../src/windowsPaths';
+
+describe('extractWindowsPaths', () => {
+    test('should detect raw and escaped UNC paths', () => {
+        const source = [
+            'REM copy to \\\\fileserver\\builds\\nightly.',
+            'const share = "\\\\\\\\fileserver.corp\\\\deploy$\\\\app\\\\config.xml";',
+        ].join('\n');
+
+        const findings = extractWindowsPaths(source);
+
+        expect(findings.map(finding => finding.url)).toEqual([
+            '\\\\fileserver\\builds\\nightly',
+            '\\\\fileserver.corp\\deploy$\\app\\config.xml',
+        ]);
+        expect(findings.map(finding => finding.attributes)).toEqual([
+            { pathKind: 'unc', share: '\\\\fileserver\\builds' },
+            { pathKind: 'unc', share: '\\\\fileserver.corp\\deploy$' },
+        ]);
+        expect(source.substring(findings[1].start, findings[1].end)).toBe(
+            '\\\\\\\\fileserver.corp\\\\deploy$\\\\app\\\\config.xml',
+        );
+        expect(findings[1].line).toBe(2);
+        expect(findings.every(finding => finding.category === 'windows-path')).toBe(true);
+    });
+
+    test('should detect file URLs with drive letters', () => {
+        const source = 'open("file:///C:/tools/setup.exe"); legacy = "file://d|/data"; skip = "file:///etc/hosts";';
+
+        const findings = extractWindowsPaths(source);
+
+        expect(findings.map(finding => finding.url)).toEqual(['file:///C:/tools/setup.exe', 'file://d|/data']);
+        expect(findings[0].attributes).toEqual({ pathKind: 'file-url' });
+    });
+
+    test('should ignore single backslashes and escape sequences', () => {
+        expect(extractWindowsPaths('C:\\Users\\me "line\\nbreak" regex = /\\\\d+/')).toEqual([]);
+    });
+});